@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <template>",
+	Short: "Add a gate from the template catalog",
+	Long: `Append a well-formed gate block from the template catalog to
+.gatekeeper/gates.yaml. Existing comments in the file are preserved. Templates
+in ~/.config/gatekeeper/templates/catalog/ override or extend the built-in ones.
+Run 'gatekeeper templates list' to see the available templates.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplate,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+		log.Info("add started", "template", args[0])
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		configPath := filepath.Join(projectDir, ".gatekeeper", "gates.yaml")
+		if err := addGate(ctx, configPath, args[0], cmd.OutOrStdout()); err != nil {
+			return err
+		}
+
+		log.Info("add completed", "template", args[0])
+		return nil
+	},
+}
+
+// addGate appends the named template to the config file at configPath.
+func addGate(ctx context.Context, configPath, templateName string, out io.Writer) error {
+	tmpl, ok := config.LookupTemplate(templateName)
+	if !ok {
+		return unknownTemplateError(templateName)
+	}
+
+	data, err := os.ReadFile(configPath) // #nosec G304 -- path is built from the working directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config.ErrConfigNotFound
+		}
+		return fmt.Errorf("reading gates.yaml: %w", err)
+	}
+
+	updated, err := config.AppendGate(data, tmpl.YAML)
+	if err != nil {
+		return err
+	}
+
+	if err := fileutil.WriteFileAtomic(configPath, updated, 0o644); err != nil {
+		return fmt.Errorf("writing gates.yaml: %w", err)
+	}
+
+	logger.FromContext(ctx).Debug("gate appended", "template", templateName, "path", configPath)
+	fmt.Fprintf(out, "➕ Added gate %q to %s\n", tmpl.Name, configPath)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+}
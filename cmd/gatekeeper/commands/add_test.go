@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func TestAddGate_AppendsTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gates.yaml")
+	if err := os.WriteFile(path, []byte("# keep me\nversion: 1\ngates:\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := addGate(context.Background(), path, "gosec", out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# keep me") {
+		t.Errorf("expected comment to be preserved, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "name: gosec") {
+		t.Errorf("expected gosec gate, got:\n%s", data)
+	}
+	assertContains(t, out.String(), "Added gate")
+}
+
+func TestAddGate_UnknownTemplate(t *testing.T) {
+	err := addGate(context.Background(), "/nonexistent/gates.yaml", "nope", &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "unknown template") {
+		t.Fatalf("expected unknown template error, got %v", err)
+	}
+}
+
+func TestAddGate_MissingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gates.yaml")
+	err := addGate(context.Background(), path, "gosec", &bytes.Buffer{})
+	if !errors.Is(err, config.ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+}
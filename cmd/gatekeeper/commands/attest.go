@@ -0,0 +1,323 @@
+package commands
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/attest"
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for "attest".
+var (
+	flagAttestRev         string
+	flagAttestOutput      string
+	flagAttestGenerateKey bool
+)
+
+// Flag values for "verify-attestation".
+var (
+	flagVerifyPublicKeys []string
+	flagVerifyIdentity   string
+	flagVerifyIssuer     string
+	flagVerifyInput      string
+	flagVerifyRequire    []string
+)
+
+var attestCmd = &cobra.Command{
+	Use:   "attest",
+	Short: "Sign the last run so CI can verify the gates passed",
+	Long: `Sign the result of the last run and attach it to a commit (HEAD by default)
+as a git note under refs/notes/gatekeeper. The attestation binds the run to the
+tree of the commit: it only verifies if the gates checked exactly what was
+committed. Push it with the commit:
+
+  git push origin HEAD refs/notes/gatekeeper
+
+Attestations are signed with the ed25519 key at attestation.key in the user
+config (default ~/.config/gatekeeper/attestation.key; create one with
+--generate-key and give the printed public key to the server). With
+attestation.keyless: true, they are signed with Sigstore and your OIDC identity
+instead, which needs the cosign CLI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		globalCfg, err := config.LoadGlobalConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("loading global config: %w", err)
+		}
+		if flagAttestGenerateKey {
+			return generateAttestationKey(cmd.OutOrStdout(), attestationKeyPath(globalCfg))
+		}
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		last, err := results.NewStore(results.DefaultDir(projectDir)).LoadLast()
+		if err != nil {
+			return err
+		}
+		gitSvc := git.NewExecService(projectDir)
+		tree, err := gitSvc.TreeOf(ctx, flagAttestRev)
+		if err != nil {
+			return err
+		}
+		signer, err := attestationSigner(globalCfg)
+		if err != nil {
+			return err
+		}
+
+		att, err := attestRun(ctx, last, tree, signer, time.Now())
+		if err != nil {
+			return err
+		}
+		data, err := attest.Encode(att)
+		if err != nil {
+			return err
+		}
+
+		if flagAttestOutput != "" {
+			if err := fileutil.WriteFileAtomic(flagAttestOutput, data, 0o644); err != nil { // #nosec G306 -- attestations are public
+				return fmt.Errorf("writing %s: %w", flagAttestOutput, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "🔏 Attested %d gates of %s in %s\n", len(last.Gates), flagAttestRev, flagAttestOutput)
+			return nil
+		}
+		if err := gitSvc.AddNote(ctx, attest.NotesRef, flagAttestRev, string(data)); err != nil {
+			return err
+		}
+		logger.FromContext(ctx).Info("run attested", "rev", flagAttestRev, "tree", tree)
+		fmt.Fprintf(cmd.OutOrStdout(), "🔏 Attested %d gates of %s in %s\n", len(last.Gates), flagAttestRev, attest.NotesRef)
+		fmt.Fprintf(cmd.OutOrStdout(), "   Push it with: git push <remote> %s\n", attest.NotesRef)
+		return nil
+	},
+}
+
+var verifyAttestationCmd = &cobra.Command{
+	Use:   "verify-attestation [commit]",
+	Short: "Verify that the gates passed on a commit (for CI and servers)",
+	Long: `Verify the attestation of a commit (HEAD by default), as created by
+'gatekeeper attest': it must be signed by a trusted key or identity, be for
+the tree of the commit, and record a passing run. Exits non-zero otherwise.
+
+The attestation is read from the git note under refs/notes/gatekeeper, which
+CI must fetch first:
+
+  git fetch origin refs/notes/gatekeeper:refs/notes/gatekeeper
+
+Trust ed25519 keys with --public-key (a PEM file may hold several keys), or a
+Sigstore identity with --certificate-identity and --certificate-oidc-issuer.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		rev := "HEAD"
+		if len(args) == 1 {
+			rev = args[0]
+		}
+		verifier, err := attestationVerifier(flagVerifyPublicKeys, flagVerifyIdentity, flagVerifyIssuer)
+		if err != nil {
+			return err
+		}
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		gitSvc := git.NewExecService(projectDir)
+		tree, err := gitSvc.TreeOf(ctx, rev)
+		if err != nil {
+			return err
+		}
+
+		var data []byte
+		if flagVerifyInput != "" {
+			data, err = os.ReadFile(flagVerifyInput) // #nosec G304 -- path is given by the user
+		} else {
+			var note string
+			note, err = gitSvc.ReadNote(ctx, attest.NotesRef, rev)
+			data = []byte(note)
+		}
+		if err != nil {
+			return fmt.Errorf("reading attestation: %w", err)
+		}
+
+		stmt, signer, err := verifyAttestation(ctx, data, tree, verifier, flagVerifyRequire)
+		if err != nil {
+			return err
+		}
+		if flagJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(stmt)
+		}
+		writeVerified(cmd.OutOrStdout(), rev, signer, stmt)
+		return nil
+	},
+}
+
+// attestRun signs the last run, which must have passed and checked tree.
+func attestRun(ctx context.Context, last *formatter.RunResult, tree string, signer attest.Signer, now time.Time) (*attest.Attestation, error) {
+	if !last.Passed {
+		return nil, errors.New("the last run failed; only passing runs can be attested")
+	}
+	if last.Tree == "" {
+		return nil, fmt.Errorf("%w (dry runs cannot be attested); run 'gatekeeper run' first", attest.ErrNoTree)
+	}
+	if last.Tree != tree {
+		return nil, fmt.Errorf("%w: the last run checked tree %s, but the commit has %s; run 'gatekeeper run' on it first", attest.ErrTreeMismatch, last.Tree, tree)
+	}
+	stmt, err := attest.NewStatement(*last, version, now)
+	if err != nil {
+		return nil, err
+	}
+	return attest.Seal(ctx, stmt, signer)
+}
+
+// verifyAttestation checks an encoded attestation against tree and returns its
+// statement and signer. Every gate in require must have run and passed.
+func verifyAttestation(ctx context.Context, data []byte, tree string, verifier attest.Verifier, require []string) (*attest.Statement, string, error) {
+	att, err := attest.Decode(data)
+	if err != nil {
+		return nil, "", err
+	}
+	stmt, signer, err := attest.Open(ctx, att, verifier)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := stmt.Check(tree); err != nil {
+		return nil, "", err
+	}
+	for _, name := range require {
+		if !gateRanAndPassed(stmt, name) {
+			return nil, "", fmt.Errorf("required gate %q did not run and pass", name)
+		}
+	}
+	return stmt, signer, nil
+}
+
+// gateRanAndPassed reports whether the statement records the named gate as passed, not skipped.
+func gateRanAndPassed(stmt *attest.Statement, name string) bool {
+	for _, g := range stmt.Gates {
+		if g.Name == name {
+			return g.Passed && !g.Skipped
+		}
+	}
+	return false
+}
+
+// writeVerified prints a verified statement, listing gates that did not run.
+func writeVerified(out io.Writer, rev, signer string, stmt *attest.Statement) {
+	var skipped []string
+	for _, g := range stmt.Gates {
+		if g.Skipped {
+			skipped = append(skipped, g.Name)
+		}
+	}
+	fmt.Fprintf(out, "✅ %s: %d gates passed, attested by %s at %s (gatekeeper %s)\n",
+		rev, len(stmt.Gates)-len(skipped), signer, stmt.SignedAt.Format(time.RFC3339), stmt.GatekeeperVersion)
+	if len(skipped) > 0 {
+		fmt.Fprintf(out, "   Skipped: %s\n", strings.Join(skipped, ", "))
+	}
+}
+
+// attestationKeyPath returns the configured signing key file, or the default
+// ~/.config/gatekeeper/attestation.key.
+func attestationKeyPath(globalCfg *config.GlobalConfig) string {
+	if globalCfg.Attestation.Key != "" {
+		return globalCfg.Attestation.Key
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "attestation.key"
+	}
+	return filepath.Join(home, ".config", "gatekeeper", "attestation.key")
+}
+
+// attestationSigner returns the signer configured in the user config.
+func attestationSigner(globalCfg *config.GlobalConfig) (attest.Signer, error) {
+	if globalCfg.Attestation.Keyless {
+		return attest.NewKeylessSigner(), nil
+	}
+	path := attestationKeyPath(globalCfg)
+	key, err := attest.LoadPrivateKey(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no signing key at %s; create one with 'gatekeeper attest --generate-key'", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attest.NewKeySigner(key), nil
+}
+
+// attestationVerifier returns a verifier that trusts the keys in the given PEM
+// files, or else the Sigstore identity.
+func attestationVerifier(keyFiles []string, identity, issuer string) (attest.Verifier, error) {
+	switch {
+	case len(keyFiles) > 0 && identity != "":
+		return nil, errors.New("use either --public-key or --certificate-identity, not both")
+	case len(keyFiles) > 0:
+		var keys []ed25519.PublicKey
+		for _, path := range keyFiles {
+			data, err := os.ReadFile(path) // #nosec G304 -- path is given by the user
+			if err != nil {
+				return nil, fmt.Errorf("reading public key: %w", err)
+			}
+			parsed, err := attest.ParsePublicKeys(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			keys = append(keys, parsed...)
+		}
+		return attest.NewKeyVerifier(keys), nil
+	case identity != "" && issuer != "":
+		return attest.NewKeylessVerifier(identity, issuer), nil
+	case identity != "":
+		return nil, errors.New("--certificate-identity requires --certificate-oidc-issuer")
+	default:
+		return nil, errors.New("no trusted signer: pass --public-key or --certificate-identity")
+	}
+}
+
+// generateAttestationKey creates a signing key at path and prints its public key.
+func generateAttestationKey(out io.Writer, path string) error {
+	pub, err := attest.GenerateKey(path)
+	if err != nil {
+		return err
+	}
+	pem, err := attest.EncodePublicKey(pub)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "🔑 Created signing key %s (key %s). Its public key, for verify-attestation:\n\n%s", path, attest.KeyID(pub), pem)
+	return nil
+}
+
+func init() {
+	attestCmd.Flags().StringVar(&flagAttestRev, "rev", "HEAD", "Commit to attach the attestation to")
+	attestCmd.Flags().StringVarP(&flagAttestOutput, "output", "o", "", "Write the attestation to a file instead of a git note")
+	attestCmd.Flags().BoolVar(&flagAttestGenerateKey, "generate-key", false, "Create the ed25519 signing key and print its public key")
+	rootCmd.AddCommand(attestCmd)
+
+	verifyAttestationCmd.Flags().StringSliceVar(&flagVerifyPublicKeys, "public-key", nil, "PEM file of trusted ed25519 public keys (repeatable)")
+	verifyAttestationCmd.Flags().StringVar(&flagVerifyIdentity, "certificate-identity", "", "Trusted Sigstore signer identity (e.g., an email address)")
+	verifyAttestationCmd.Flags().StringVar(&flagVerifyIssuer, "certificate-oidc-issuer", "", "OIDC issuer of the Sigstore identity")
+	verifyAttestationCmd.Flags().StringVar(&flagVerifyInput, "input", "", "Read the attestation from a file instead of the git note")
+	verifyAttestationCmd.Flags().StringSliceVar(&flagVerifyRequire, "require", nil, "Gates that must have run and passed (comma-separated)")
+	_ = verifyAttestationCmd.RegisterFlagCompletionFunc("require", completeGateList)
+	rootCmd.AddCommand(verifyAttestationCmd)
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/attest"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+func TestAttestRun(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	signer := attest.NewKeySigner(priv)
+	verifier := attest.NewKeyVerifier([]ed25519.PublicKey{pub})
+
+	last := passingRunResult()
+	last.Tree = "tree1"
+	last.Gates = append(last.Gates, formatter.GateResult{Name: "review", Passed: true, Skipped: true})
+
+	att, err := attestRun(ctx, last, "tree1", signer, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := attest.Encode(att)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt, by, err := verifyAttestation(ctx, data, "tree1", verifier, []string{"lint"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	writeVerified(&out, "HEAD", by, stmt)
+	if !strings.Contains(out.String(), "1 gates passed") || !strings.Contains(out.String(), "Skipped: review") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if _, _, err := verifyAttestation(ctx, data, "tree2", verifier, nil); !errors.Is(err, attest.ErrTreeMismatch) {
+		t.Errorf("expected ErrTreeMismatch, got %v", err)
+	}
+	if _, _, err := verifyAttestation(ctx, data, "tree1", verifier, []string{"review"}); err == nil {
+		t.Error("expected a skipped required gate to fail verification")
+	}
+}
+
+func TestAttestRun_Refuses(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := failingRunResult()
+	failed.Tree = "tree1"
+	dryRun := passingRunResult()
+	stale := passingRunResult()
+	stale.Tree = "tree0"
+
+	tests := []struct {
+		name    string
+		last    *formatter.RunResult
+		wantErr string
+	}{
+		{"failed run", failed, "only passing runs"},
+		{"no tree", dryRun, "dry runs cannot be attested"},
+		{"other tree", stale, "different tree"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := attestRun(context.Background(), tt.last, "tree1", attest.NewKeySigner(priv), time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAttestationVerifier_Flags(t *testing.T) {
+	if _, err := attestationVerifier(nil, "", ""); err == nil {
+		t.Error("expected an error without a trusted signer")
+	}
+	if _, err := attestationVerifier(nil, "dev@example.com", ""); err == nil {
+		t.Error("expected an error for an identity without an issuer")
+	}
+	if _, err := attestationVerifier([]string{"key.pem"}, "dev@example.com", "https://issuer"); err == nil {
+		t.Error("expected an error for both a key and an identity")
+	}
+	if v, err := attestationVerifier(nil, "dev@example.com", "https://issuer"); err != nil || v == nil {
+		t.Errorf("unexpected result: %v, %v", v, err)
+	}
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for "audit show".
+var (
+	flagAuditEvent string
+	flagAuditLimit int
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of runs, skips, fixes, and dismissals",
+	Long: `Gatekeeper appends an entry to an audit log for every run, every gate
+skipped through GATEKEEPER_SKIP or a commit message marker, every writable gate
+fix that was re-staged, every branch rule overridden with --allow-branch, and
+every finding dismissed or suppressed.
+
+The log is JSONL at .gatekeeper/audit.log, or at audit_log in the user config
+(GATEKEEPER_AUDIT_LOG). Entries are only ever appended.`,
+}
+
+var auditShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the most recent audit log entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		entries, err := audit.Read(projectAuditLog(cmd.Context(), projectDir).Path())
+		if err != nil {
+			return err
+		}
+		entries = filterAuditEntries(entries, flagAuditEvent, flagAuditLimit)
+
+		if flagJSON {
+			return writeAuditJSON(cmd.OutOrStdout(), entries)
+		}
+		writeAudit(cmd.OutOrStdout(), entries)
+		return nil
+	},
+}
+
+// filterAuditEntries keeps the entries of event (all if empty), then the last
+// limit of them (all if limit is 0).
+func filterAuditEntries(entries []audit.Entry, event string, limit int) []audit.Entry {
+	var kept []audit.Entry
+	for _, e := range entries {
+		if event == "" || e.Event == event {
+			kept = append(kept, e)
+		}
+	}
+	if limit > 0 && len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	return kept
+}
+
+// writeAuditJSON prints entries as a JSON array.
+func writeAuditJSON(out io.Writer, entries []audit.Entry) error {
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("encoding audit entries: %w", err)
+	}
+	return nil
+}
+
+// writeAudit prints one line per entry, oldest first.
+func writeAudit(out io.Writer, entries []audit.Entry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No audit entries recorded.")
+		return
+	}
+	for _, e := range entries {
+		fields := []string{e.Time.Local().Format("2006-01-02 15:04:05"), fmt.Sprintf("%-8s", e.Event)}
+		if e.User != "" {
+			fields = append(fields, e.User)
+		}
+		if e.Branch != "" {
+			fields = append(fields, "on "+e.Branch)
+		}
+		fields = append(fields, auditDetail(e))
+		fmt.Fprintln(out, strings.TrimSpace(strings.Join(fields, "  ")))
+	}
+}
+
+// auditDetail describes what an entry records, e.g. "failed: lint, test".
+func auditDetail(e audit.Entry) string {
+	gates := strings.Join(e.Gates, ", ")
+	switch e.Event {
+	case audit.EventRun:
+		if e.Passed != nil && *e.Passed {
+			return fmt.Sprintf("passed in %dms", e.DurationMs)
+		}
+		if gates == "" {
+			return fmt.Sprintf("failed in %dms", e.DurationMs)
+		}
+		return fmt.Sprintf("failed in %dms: %s", e.DurationMs, gates)
+	case audit.EventSkip:
+		return fmt.Sprintf("skipped %s (requested by %s)", gates, e.Source)
+	case audit.EventFix:
+		return "re-staged fixes to " + strings.Join(e.Files, ", ")
+	case audit.EventOverride:
+		return fmt.Sprintf("allowed: %s (requested by %s)", e.Reason, e.Source)
+	default:
+		detail := gates
+		if e.Finding != "" {
+			detail += ": " + e.Finding
+		}
+		if e.Reason != "" {
+			detail += " (" + e.Reason + ")"
+		}
+		return detail
+	}
+}
+
+func init() {
+	auditShowCmd.Flags().StringVar(&flagAuditEvent, "event", "", "Only show entries of this event (run, skip, fix, dismiss, suppress, override)")
+	auditShowCmd.Flags().IntVar(&flagAuditLimit, "limit", 50, "Number of most recent entries to show (0 shows all)")
+	auditCmd.AddCommand(auditShowCmd)
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
+)
+
+func auditEntries() []audit.Entry {
+	passed, failed := true, false
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []audit.Entry{
+		{Time: at, Event: audit.EventRun, User: "dev", Branch: "main", Passed: &failed, DurationMs: 1200, Gates: []string{"lint"}},
+		{Time: at, Event: audit.EventSkip, User: "dev", Gates: []string{"lint", "test"}, Source: "GATEKEEPER_SKIP"},
+		{Time: at, Event: audit.EventRun, User: "dev", Passed: &passed, DurationMs: 900},
+		{Time: at, Event: audit.EventDismiss, Gates: []string{"review"}, Finding: "possible nil dereference", Reason: "checked above"},
+	}
+}
+
+func TestFilterAuditEntries(t *testing.T) {
+	runs := filterAuditEntries(auditEntries(), audit.EventRun, 0)
+	if len(runs) != 2 {
+		t.Errorf("expected 2 run entries, got %d", len(runs))
+	}
+	last := filterAuditEntries(auditEntries(), "", 2)
+	if len(last) != 2 || last[1].Event != audit.EventDismiss {
+		t.Errorf("expected the 2 most recent entries, got %+v", last)
+	}
+}
+
+func TestWriteAudit(t *testing.T) {
+	out := &bytes.Buffer{}
+	writeAudit(out, auditEntries())
+
+	for _, want := range []string{
+		"dev  on main  failed in 1200ms: lint",
+		"skipped lint, test (requested by GATEKEEPER_SKIP)",
+		"passed in 900ms",
+		"review: possible nil dereference (checked above)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	writeAudit(out, nil)
+	if !strings.Contains(out.String(), "No audit entries") {
+		t.Errorf("unexpected output for an empty log: %q", out.String())
+	}
+}
+
+func TestWriteAuditJSON_Empty(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := writeAuditJSON(out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("expected an empty array, got %q", out.String())
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var flagCIBase string
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Run all gates on the changes since a base commit, for CI",
+	Long: `Run the gates like 'run', on the changes committed since --base rather than
+on staged changes, so CI enforces the same gates as the pre-commit hook.
+
+HEAD is soft-reset to the merge base of --base and HEAD for the run, which
+stages every change since then, and reset back afterwards. The clone must
+include the merge base (e.g., fetch-depth: 0 on GitHub, GIT_DEPTH: 0 on
+GitLab). Without --base, the base is the target branch of a GitHub pull
+request (GITHUB_BASE_REF) or the diff base of a GitLab merge request; failing
+that, or for an all-zero base, the parent of HEAD.
+
+Branch rules are not checked: they are about where commits are made, which CI
+does not decide. Exit codes are those of 'run'.
+
+'gatekeeper init --ci' generates a workflow that runs this command.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		restore, err := git.NewExecService(projectDir).StageChangesSince(ctx, ciBase(flagCIBase, os.Getenv))
+		if err != nil {
+			return err
+		}
+
+		flagAllowBranch = true
+		err = runPipeline(ctx, false)
+		if restoreErr := restore(); restoreErr != nil {
+			log.Error("could not restore HEAD", "error", restoreErr)
+			if err == nil {
+				err = restoreErr
+			}
+		}
+		if errors.Is(err, ErrGatesFailed) {
+			os.Exit(ExitCode(err))
+		}
+		return err
+	},
+}
+
+// ciBase returns the base commit to check the changes since: flag if set,
+// else the base the CI system provides, or "" for the parent of HEAD.
+func ciBase(flag string, getenv func(string) string) string {
+	switch {
+	case flag != "":
+		return flag
+	case getenv("GITHUB_BASE_REF") != "":
+		return "origin/" + getenv("GITHUB_BASE_REF")
+	default:
+		return getenv("CI_MERGE_REQUEST_DIFF_BASE_SHA")
+	}
+}
+
+func init() {
+	ciCmd.Flags().StringVar(&flagCIBase, "base", "", "Commit or ref to check the changes since (default: the pull request target, else the parent of HEAD)")
+	rootCmd.AddCommand(ciCmd)
+}
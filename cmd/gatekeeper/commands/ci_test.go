@@ -0,0 +1,27 @@
+package commands
+
+import "testing"
+
+func TestCIBase(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+	tests := []struct {
+		name string
+		flag string
+		vars map[string]string
+		want string
+	}{
+		{"flag wins", "abc123", map[string]string{"GITHUB_BASE_REF": "main"}, "abc123"},
+		{"github pull request", "", map[string]string{"GITHUB_BASE_REF": "main"}, "origin/main"},
+		{"gitlab merge request", "", map[string]string{"CI_MERGE_REQUEST_DIFF_BASE_SHA": "def456"}, "def456"},
+		{"none", "", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ciBase(tt.flag, env(tt.vars)); got != tt.want {
+				t.Errorf("ciBase = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
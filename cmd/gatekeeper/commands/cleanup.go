@@ -23,7 +23,7 @@ This is useful for cleaning up resources when you're done with gatekeeper.`,
 			return fmt.Errorf("connecting to Docker: %w", err)
 		}
 
-		p := pool.NewPool(runtime)
+		p := newDockerPool(runtime)
 		count, err := p.CleanupAll(ctx)
 		if err != nil {
 			return fmt.Errorf("cleanup failed: %w", err)
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// summaryMaxAge is how old the last run may be to still describe the commit
+// being prepared. Older results (e.g., after "git commit --no-verify") are ignored.
+const summaryMaxAge = 10 * time.Minute
+
+var commitSummaryCmd = &cobra.Command{
+	Use:    "commit-summary <message-file> [source] [sha]",
+	Short:  "Append a commented gate summary to a commit message (prepare-commit-msg hook)",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+		// Only plain and template commits open the editor with comment stripping;
+		// -m, -F, merges, squashes, and amends keep the message as given.
+		if source != "" && source != "template" {
+			return nil
+		}
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		store := results.NewStore(results.DefaultDir(projectDir))
+		at, err := store.LastRunAt()
+		if errors.Is(err, results.ErrNoResults) || (err == nil && time.Since(at) > summaryMaxAge) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		last, err := store.LoadLast()
+		if err != nil {
+			return err
+		}
+
+		commentChar := git.NewExecService(projectDir).CommentChar(ctx)
+		if commentChar == "auto" {
+			log.Info("core.commentChar is auto, skipping commit summary")
+			return nil
+		}
+
+		return appendToFile(args[0], commitSummary(*last, commentChar))
+	},
+}
+
+// appendToFile appends text to the commit message file prepared by git.
+func appendToFile(path, text string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0) // #nosec G304 -- path is passed by git to the hook
+	if err != nil {
+		return fmt.Errorf("opening commit message file: %w", err)
+	}
+	if _, err := f.WriteString(text); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing commit summary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing commit message file: %w", err)
+	}
+	return nil
+}
+
+// commitSummary renders gate results as comment lines for a commit message template,
+// e.g. "# gatekeeper: 6 passed, 1 advisory warning".
+func commitSummary(result formatter.RunResult, commentChar string) string {
+	counts, details := runSummary(result)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n%s gatekeeper: %s\n", commentChar, commentChar, counts)
+	for _, d := range details {
+		fmt.Fprintf(&b, "%s   %s\n", commentChar, d)
+	}
+	return b.String()
+}
+
+// runSummary counts gate outcomes, e.g. "6 passed, 1 advisory warning", and
+// describes each failed or advisory gate, e.g. "lint failed: 2 findings".
+func runSummary(result formatter.RunResult) (counts string, details []string) {
+	var passed, failed, advisory, skipped int
+	for _, g := range result.Gates {
+		switch {
+		case g.Skipped:
+			skipped++
+		case g.Passed:
+			passed++
+		case g.Blocks():
+			failed++
+			details = append(details, fmt.Sprintf("%s failed%s", g.Name, findingCount(g)))
+		default:
+			advisory++
+			details = append(details, fmt.Sprintf("%s (advisory)%s", g.Name, findingCount(g)))
+		}
+	}
+
+	parts := []string{fmt.Sprintf("%d passed", passed)}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+	if advisory > 0 {
+		parts = append(parts, plural(advisory, "advisory warning", "advisory warnings"))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	return strings.Join(parts, ", "), details
+}
+
+// findingCount renders ": N findings" for a gate, or "" if it has none.
+func findingCount(g formatter.GateResult) string {
+	if len(g.Errors) == 0 {
+		return ""
+	}
+	return ": " + plural(len(g.Errors), "finding", "findings")
+}
+
+// plural renders "1 finding" or "3 findings".
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+func init() {
+	rootCmd.AddCommand(commitSummaryCmd)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+func TestCommitSummary(t *testing.T) {
+	result := formatter.RunResult{
+		Passed: true,
+		Gates: []formatter.GateResult{
+			{Name: "lint", Passed: true, Blocking: true},
+			{Name: "test", Passed: true, Blocking: true},
+			{Name: "review", Passed: false, Blocking: false, Errors: []parser.StructuredError{{Message: "naming"}}},
+			{Name: "docs", Skipped: true, Passed: true},
+		},
+	}
+
+	want := "#\n# gatekeeper: 2 passed, 1 advisory warning, 1 skipped\n#   review (advisory): 1 finding\n"
+	if got := commitSummary(result, "#"); got != want {
+		t.Errorf("unexpected summary:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestCommitSummary_FailedAndCommentChar(t *testing.T) {
+	result := formatter.RunResult{
+		Gates: []formatter.GateResult{
+			{Name: "security", Blocking: true, Errors: []parser.StructuredError{{Message: "a"}, {Message: "b"}}},
+			{Name: "lint", Blocking: false},
+			{Name: "style", Blocking: false},
+		},
+	}
+
+	got := commitSummary(result, ";")
+	assertContains(t, got, "; gatekeeper: 0 passed, 1 failed, 2 advisory warnings\n")
+	assertContains(t, got, ";   security failed: 2 findings\n")
+	assertContains(t, got, ";   lint (advisory)\n")
+}
+
+func TestAppendToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(path, []byte("fix: handle nil config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendToFile(path, "# gatekeeper: 1 passed\n"); err != nil {
+		t.Fatalf("appendToFile: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "fix: handle nil config\n# gatekeeper: 1 passed\n" {
+		t.Errorf("unexpected message file: %q", data)
+	}
+
+	if err := appendToFile(filepath.Join(t.TempDir(), "missing"), "x"); err == nil {
+		t.Error("expected error for missing message file")
+	}
+}
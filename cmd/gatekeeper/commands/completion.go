@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/spf13/cobra"
+)
+
+// projectGates returns the gates of the project in the working directory, or
+// nil if its gates.yaml is missing or invalid. Completions never fail.
+func projectGates(ctx context.Context) []config.Gate {
+	projectDir, err := getwd()
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(ctx, filepath.Join(projectDir, ".gatekeeper", "gates.yaml"))
+	if err != nil {
+		return nil
+	}
+	return cfg.Gates
+}
+
+// completeGateList completes a comma-separated list of gate names, such as
+// the value of --skip, leaving out the gates already listed.
+func completeGateList(cmd *cobra.Command, _ []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return gateListCompletions(projectGates(cmd.Context()), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// gateListCompletions returns the completions of toComplete, the list typed so
+// far, each the typed list with one more gate name.
+func gateListCompletions(gates []config.Gate, toComplete string) []cobra.Completion {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	listed := strings.Split(prefix, ",")
+
+	var completions []cobra.Completion
+	for _, g := range gates {
+		if slices.Contains(listed, g.Name) {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(prefix+g.Name, string(g.Type)))
+	}
+	return completions
+}
+
+// completeGateArg completes a single gate name argument, such as that of explain.
+func completeGateArg(cmd *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []cobra.Completion
+	for _, g := range projectGates(cmd.Context()) {
+		completions = append(completions, cobra.CompletionWithDesc(g.Name, string(g.Type)))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplate completes a single catalog template name argument, such as
+// that of add. The catalog is read when completing, so templates added to the
+// override directory are offered without rebuilding.
+func completeTemplate(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var completions []cobra.Completion
+	for _, t := range config.Templates() {
+		completions = append(completions, cobra.CompletionWithDesc(t.Name, t.Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfile completes the value of --profile with the profiles the
+// project's gates are tagged with.
+func completeProfile(cmd *cobra.Command, _ []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var profiles []cobra.Completion
+	for _, g := range projectGates(cmd.Context()) {
+		for _, p := range g.Profiles {
+			if !slices.Contains(profiles, p) {
+				profiles = append(profiles, p)
+			}
+		}
+	}
+	return profiles, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/spf13/cobra"
+)
+
+func TestGateListCompletions(t *testing.T) {
+	gates := []config.Gate{
+		{Name: "go-vet", Type: config.GateTypeExec},
+		{Name: "go-test", Type: config.GateTypeExec},
+		{Name: "review", Type: config.GateTypeLLM},
+	}
+	tests := []struct {
+		toComplete string
+		want       []cobra.Completion
+	}{
+		{"", []cobra.Completion{"go-vet\texec", "go-test\texec", "review\tllm"}},
+		{"go-vet,", []cobra.Completion{"go-vet,go-test\texec", "go-vet,review\tllm"}},
+		{"go-vet,review,go", []cobra.Completion{"go-vet,review,go-test\texec"}},
+	}
+	for _, tt := range tests {
+		got := gateListCompletions(gates, tt.toComplete)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("gateListCompletions(%q) = %q, want %q", tt.toComplete, got, tt.want)
+		}
+	}
+}
+
+func TestCompletion_SkipFlag(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".gatekeeper"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "version: 1\ngates:\n  - name: lint\n    type: exec\n    command: make lint\n    container: alpine\n    profiles: [fast]\n"
+	if err := os.WriteFile(filepath.Join(dir, ".gatekeeper", "gates.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(new(bytes.Buffer))
+	rootCmd.SetArgs([]string{cobra.ShellCompRequestCmd, "run", "--skip", ""})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+	assertContains(t, buf.String(), "lint\texec\n")
+
+	buf.Reset()
+	rootCmd.SetArgs([]string{cobra.ShellCompRequestCmd, "run", "--profile", ""})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("completion failed: %v", err)
+	}
+	assertContains(t, buf.String(), "fast\n")
+}
+
+func TestCompletion_Template(t *testing.T) {
+	for _, args := range [][]string{{"add", ""}, {"templates", "show", ""}} {
+		buf := new(bytes.Buffer)
+		rootCmd.SetOut(buf)
+		rootCmd.SetErr(new(bytes.Buffer))
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("completion failed: %v", err)
+		}
+		assertContains(t, buf.String(), "gosec\t")
+	}
+}
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+	"github.com/spf13/cobra"
+)
+
+// newGlobalConfig starts a user config created by "config edit".
+const newGlobalConfig = "# Gatekeeper user configuration. Run 'gatekeeper config get' to see all settings.\n"
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change the user config",
+	Long: `Show and change the user config, ~/.config/gatekeeper/config.yaml.
+
+Settings are named by their YAML path, such as gemini_api_key, container_ttl,
+or output.color. Values are checked before they are written: durations such as
+30s or 10m, true or false for booleans. The file is created readable only by
+you (0600), since it holds API keys, and secrets are redacted when shown.`,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a value in the user config",
+	Long: `Set a value in the user config, creating the file if needed. Comments in
+the file are preserved. Lists such as local.path take comma-separated values;
+maps such as llm_http.headers can only be changed with 'gatekeeper config edit'.`,
+	Example: `  gatekeeper config set gemini_api_key AIza...
+  gatekeeper config set container_ttl 10m
+  gatekeeper config set output.color false`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.GlobalConfigPath()
+		if err != nil {
+			return fmt.Errorf("locating user config: %w", err)
+		}
+		return setGlobalConfig(path, args[0], args[1], cmd.OutOrStdout())
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Show the user config, or one of its values",
+	Long: `Show the effective user config, or one of its values, with environment
+variable overrides such as GEMINI_API_KEY applied. Secrets are redacted.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.GlobalConfigPath()
+		if err != nil {
+			return fmt.Errorf("locating user config: %w", err)
+		}
+		cfg, err := config.LoadGlobalConfigFrom(cmd.Context(), path)
+		if err != nil {
+			return &configError{err}
+		}
+		key := ""
+		if len(args) == 1 {
+			key = args[0]
+		}
+		value, err := config.GetGlobalValue(cfg, key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the user config in your editor",
+	Long: `Open the user config in $VISUAL or $EDITOR (default vi), creating it if
+needed, and check it when the editor exits.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		path, err := config.GlobalConfigPath()
+		if err != nil {
+			return fmt.Errorf("locating user config: %w", err)
+		}
+		return editGlobalConfig(path, func(path string) error {
+			return runEditor(cmd.Context(), path)
+		}, cmd.OutOrStdout())
+	},
+}
+
+// setGlobalConfig sets key to value in the user config at path.
+func setGlobalConfig(path, key, value string, out io.Writer) error {
+	data, err := readGlobalConfig(path)
+	if err != nil {
+		return err
+	}
+	updated, err := config.SetGlobalValue(data, key, value)
+	if err != nil {
+		return &configError{err}
+	}
+	if err := writeGlobalConfig(path, updated); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ Set %s in %s\n", key, path)
+	return nil
+}
+
+// editGlobalConfig creates the user config at path if needed, opens it with
+// edit, and validates the result.
+func editGlobalConfig(path string, edit func(path string) error, out io.Writer) error {
+	data, err := readGlobalConfig(path)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		if err := writeGlobalConfig(path, []byte(newGlobalConfig)); err != nil {
+			return err
+		}
+	}
+
+	if err := edit(path); err != nil {
+		return err
+	}
+
+	// Editors may replace the file rather than write to it.
+	if err := os.Chmod(path, 0o600); err != nil {
+		return fmt.Errorf("securing user config: %w", err)
+	}
+	if data, err = readGlobalConfig(path); err != nil {
+		return err
+	}
+	if err := config.ValidateGlobalConfig(data); err != nil {
+		return &configError{fmt.Errorf("%w\nrun 'gatekeeper config edit' again to fix %s", err, path)}
+	}
+	fmt.Fprintf(out, "✅ %s is valid\n", path)
+	return nil
+}
+
+// readGlobalConfig reads the user config at path, or returns nil if it does
+// not exist.
+func readGlobalConfig(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the user config path
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading user config: %w", err)
+	}
+	return data, nil
+}
+
+// writeGlobalConfig writes the user config readable only by its owner, since
+// it holds API keys.
+func writeGlobalConfig(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing user config: %w", err)
+	}
+	return nil
+}
+
+// runEditor opens path in $VISUAL, $EDITOR, or vi. The editor setting may
+// include arguments, as in "code --wait".
+func runEditor(ctx context.Context, path string) error {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	cmd := exec.CommandContext(ctx, editor[0], append(editor[1:], path)...) // #nosec G204 -- the editor is chosen by the user
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("running editor %s: %w", editor[0], err)
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(configSetCmd, configGetCmd, configEditCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetGlobalConfig_CreatesPrivateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".config", "gatekeeper", "config.yaml")
+	var out bytes.Buffer
+
+	if err := setGlobalConfig(path, "gemini_api_key", "secret-key", &out); err != nil {
+		t.Fatalf("setGlobalConfig: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("config perms = %o, want 600", perm)
+	}
+	if strings.Contains(out.String(), "secret-key") || !strings.Contains(out.String(), "Set gemini_api_key") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	err = setGlobalConfig(path, "container_ttl", "soon", &out)
+	if ExitCode(err) != ExitConfigError || !strings.Contains(err.Error(), "invalid duration") {
+		t.Errorf("expected a config error for an invalid duration, got %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "gemini_api_key: secret-key\n" {
+		t.Errorf("config changed by a rejected value: %q", data)
+	}
+}
+
+func TestEditGlobalConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gatekeeper", "config.yaml")
+	var out bytes.Buffer
+
+	var seen string
+	err := editGlobalConfig(path, func(p string) error {
+		data, _ := os.ReadFile(p)
+		seen = string(data)
+		return os.WriteFile(p, []byte("output:\n  color: false\n"), 0o644)
+	}, &out)
+	if err != nil {
+		t.Fatalf("editGlobalConfig: %v", err)
+	}
+	if seen != newGlobalConfig {
+		t.Errorf("editor opened %q, want the new config", seen)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("config perms = %o, want 600", info.Mode().Perm())
+	}
+
+	err = editGlobalConfig(path, func(p string) error {
+		return os.WriteFile(p, []byte("runtime: podman\n"), 0o600)
+	}, &out)
+	if ExitCode(err) != ExitConfigError {
+		t.Errorf("expected a config error for an invalid edit, got %v", err)
+	}
+
+	editorErr := errors.New("editor crashed")
+	if err := editGlobalConfig(path, func(string) error { return editorErr }, &out); !errors.Is(err, editorErr) {
+		t.Errorf("expected the editor error, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/spf13/cobra"
+)
+
+// flagContainersProject limits "containers list" and "containers rm" to the
+// containers of the current project.
+var flagContainersProject bool
+
+var containersCmd = &cobra.Command{
+	Use:   "containers",
+	Short: "List, inspect, and remove the warm containers gatekeeper keeps",
+	Long: `Gatekeeper keeps gate containers running between runs so the next run
+starts fast. These commands show what is kept, with the image, project, writable
+flag, age, last use, and disk size of each container, and remove containers
+selectively. "gatekeeper cleanup" removes all of them.`,
+}
+
+var containersListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List gatekeeper containers",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		p, err := dockerPool()
+		if err != nil {
+			return err
+		}
+		infos, err := p.List(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if flagContainersProject {
+			if infos, err = projectContainers(infos); err != nil {
+				return err
+			}
+		}
+		if flagJSON {
+			return writeContainersJSON(cmd.OutOrStdout(), infos)
+		}
+		writeContainers(cmd.OutOrStdout(), infos, time.Now())
+		return nil
+	},
+}
+
+var containersInspectCmd = &cobra.Command{
+	Use:   "inspect <id|name>",
+	Short: "Show the details of a gatekeeper container",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := dockerPool()
+		if err != nil {
+			return err
+		}
+		info, err := p.Find(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if flagJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(info)
+		}
+		writeContainer(cmd.OutOrStdout(), info, time.Now())
+		return nil
+	},
+}
+
+var containersRmCmd = &cobra.Command{
+	Use:   "rm <id|name>...",
+	Short: "Remove gatekeeper containers",
+	Long: `Remove gatekeeper containers by ID, unique ID prefix, or name, or all the
+containers of the current project with --project. Containers not started by
+gatekeeper are never removed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !flagContainersProject {
+			return errors.New("name the containers to remove, or use --project")
+		}
+		p, err := dockerPool()
+		if err != nil {
+			return err
+		}
+		if flagContainersProject {
+			infos, err := p.List(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if infos, err = projectContainers(infos); err != nil {
+				return err
+			}
+			for _, info := range infos {
+				args = append(args, info.ID)
+			}
+		}
+
+		var errs []error
+		for _, ref := range args {
+			info, err := p.Remove(cmd.Context(), ref)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "♻️  Removed %s (%s)\n", pool.ShortID(info.ID), info.Image)
+		}
+		return errors.Join(errs...)
+	},
+}
+
+// dockerPool connects to Docker and returns its container pool.
+func dockerPool() (*pool.Pool, error) {
+	runtime, err := pool.NewDockerRuntime()
+	if err != nil {
+		return nil, fmt.Errorf("connecting to Docker: %w", err)
+	}
+	return newDockerPool(runtime), nil
+}
+
+// projectContainers keeps the containers that mount the project in the
+// working directory, or a directory within it such as its index snapshot.
+func projectContainers(infos []pool.ContainerInfo) ([]pool.ContainerInfo, error) {
+	projectDir, err := getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working directory: %w", err)
+	}
+	var kept []pool.ContainerInfo
+	for _, info := range infos {
+		if info.Project == projectDir || strings.HasPrefix(info.Project, projectDir+string(filepath.Separator)) {
+			kept = append(kept, info)
+		}
+	}
+	return kept, nil
+}
+
+// writeContainersJSON prints infos as a JSON array.
+func writeContainersJSON(out io.Writer, infos []pool.ContainerInfo) error {
+	if infos == nil {
+		infos = []pool.ContainerInfo{}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(infos); err != nil {
+		return fmt.Errorf("encoding containers: %w", err)
+	}
+	return nil
+}
+
+// writeContainers prints infos as a table.
+func writeContainers(out io.Writer, infos []pool.ContainerInfo, now time.Time) {
+	if len(infos) == 0 {
+		fmt.Fprintln(out, "No gatekeeper containers")
+		return
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tIMAGE\tPROJECT\tWRITABLE\tSTATE\tCREATED\tLAST USED\tSIZE")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%s\t%s\n",
+			pool.ShortID(info.ID), info.Image, info.Project, info.Writable, info.State,
+			ago(now.Sub(info.Created)), lastUsed(info, now), formatSize(info.SizeRw))
+	}
+	_ = tw.Flush()
+}
+
+// writeContainer prints the details of a container.
+func writeContainer(out io.Writer, info pool.ContainerInfo, now time.Time) {
+	fmt.Fprintf(out, "ID:         %s\n", info.ID)
+	fmt.Fprintf(out, "Name:       %s\n", info.Name)
+	fmt.Fprintf(out, "Image:      %s\n", info.Image)
+	fmt.Fprintf(out, "Project:    %s\n", info.Project)
+	fmt.Fprintf(out, "Writable:   %t\n", info.Writable)
+	fmt.Fprintf(out, "State:      %s (%s)\n", info.State, info.Status)
+	fmt.Fprintf(out, "Created:    %s (%s)\n", info.Created.Local().Format(time.RFC3339), ago(now.Sub(info.Created)))
+	fmt.Fprintf(out, "Last used:  %s\n", lastUsed(info, now))
+	fmt.Fprintf(out, "Size:       %s (%s with image)\n", formatSize(info.SizeRw), formatSize(info.SizeRootFs))
+	fmt.Fprintf(out, "Pool key:   %s\n", info.PoolKey)
+	for i, m := range info.Mounts {
+		label := "Mounts:"
+		if i > 0 {
+			label = ""
+		}
+		fmt.Fprintf(out, "%-11s %s\n", label, m)
+	}
+}
+
+// lastUsed renders when a container was last used, if known.
+func lastUsed(info pool.ContainerInfo, now time.Time) string {
+	if info.LastUsed.IsZero() {
+		return "unknown"
+	}
+	return ago(now.Sub(info.LastUsed))
+}
+
+// formatSize formats a byte count for display.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+func init() {
+	containersListCmd.Flags().BoolVar(&flagContainersProject, "project", false, "Only list the containers of the current project")
+	containersRmCmd.Flags().BoolVar(&flagContainersProject, "project", false, "Remove all the containers of the current project")
+	containersCmd.AddCommand(containersListCmd, containersInspectCmd, containersRmCmd)
+	rootCmd.AddCommand(containersCmd)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+func testContainers(now time.Time) []pool.ContainerInfo {
+	return []pool.ContainerInfo{{
+		ID:       "0123456789abcdef",
+		Name:     "eager_lint",
+		Image:    "golang:1.25",
+		Project:  "/proj",
+		Writable: true,
+		State:    "running",
+		Status:   "Up 2 hours",
+		Created:  now.Add(-3 * time.Hour),
+		LastUsed: now.Add(-10 * time.Minute),
+		SizeRw:   3 << 20,
+		Mounts:   []string{"/proj:/workspace (bind, rw)", ":/tmp (tmpfs, rw)"},
+	}}
+}
+
+func TestWriteContainers(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	writeContainers(out, testContainers(now), now)
+
+	assertContains(t, out.String(), "LAST USED")
+	assertContains(t, out.String(), "0123456789ab  golang:1.25  /proj")
+	assertContains(t, out.String(), "3h ago")
+	assertContains(t, out.String(), "10m ago")
+	assertContains(t, out.String(), "3.0MB")
+
+	out.Reset()
+	writeContainers(out, nil, now)
+	assertContains(t, out.String(), "No gatekeeper containers")
+}
+
+func TestWriteContainer(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	info := testContainers(now)[0]
+	info.LastUsed = time.Time{}
+	out := &bytes.Buffer{}
+	writeContainer(out, info, now)
+
+	assertContains(t, out.String(), "ID:         0123456789abcdef")
+	assertContains(t, out.String(), "Last used:  unknown")
+	assertContains(t, out.String(), "Mounts:     /proj:/workspace (bind, rw)\n            :/tmp (tmpfs, rw)")
+}
+
+func TestWriteContainersJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := writeContainersJSON(out, nil); err != nil || out.String() != "[]\n" {
+		t.Errorf("expected an empty array, got %q (%v)", out, err)
+	}
+	out.Reset()
+	if err := writeContainersJSON(out, testContainers(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil || got[0]["image"] != "golang:1.25" || got[0]["writable"] != true {
+		t.Errorf("unexpected JSON %s (%v)", out, err)
+	}
+}
+
+func TestProjectContainers(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	infos := []pool.ContainerInfo{
+		{ID: "a", Project: dir},
+		{ID: "b", Project: filepath.Join(dir, ".git", "gatekeeper", "snapshot")},
+		{ID: "c", Project: dir + "-other"},
+	}
+	kept, err := projectContainers(infos)
+	if err != nil || len(kept) != 2 || kept[0].ID != "a" || kept[1].ID != "b" {
+		t.Errorf("projectContainers = %+v, %v", kept, err)
+	}
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+	"github.com/irahardianto/gatekeeper/internal/engine/runner"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// flagSimulate holds the --simulate outcome:gate specs.
+var flagSimulate []string
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Simulate a run without Docker to demo output and CI wiring",
+	Long: `Fabricate a realistic run of the project's gates (or a sample set if there
+is no .gatekeeper/gates.yaml) through the normal progress and output pipeline.
+Nothing is executed and no results are recorded.
+
+Gates pass unless --simulate assigns an outcome: pass, fail, timeout, error, or skip.
+The exit code matches "gatekeeper run", so CI report wiring can be tested safely.
+
+  gatekeeper demo --simulate fail:go-test,timeout:eslint
+  gatekeeper demo --json --simulate fail:secret-review`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+
+		outcomes, err := gate.ParseSimulation(flagSimulate)
+		if err != nil {
+			return err
+		}
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		gates, err := demoGates(ctx, filepath.Join(projectDir, ".gatekeeper", "gates.yaml"))
+		if err != nil {
+			return err
+		}
+		gates = filterSkippedGates(gates, flagSkip, flagSkipLLM)
+
+		globalCfg, err := config.LoadGlobalConfig(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Warn("could not load global config, using default output settings", "error", err)
+			globalCfg = nil
+		}
+
+		err = runDemo(ctx, gates, outcomes, cmd.OutOrStdout(), os.Stderr, PipelineOpts{
+			JSON:       flagJSON,
+			Verbose:    flagVerbose,
+			NoColor:    flagNoColor,
+			FailFast:   failFastFlag(),
+			Accessible: accessibleOutput(globalCfg),
+			GroupBy:    flagGroupBy,
+			Format:     flagFormat,
+		})
+		if errors.Is(err, ErrGatesFailed) {
+			os.Exit(ExitCode(err))
+		}
+		return err
+	},
+}
+
+// demoGates returns the project's gates, or sampleGates if there is no gates.yaml.
+func demoGates(ctx context.Context, path string) ([]config.Gate, error) {
+	cfg, err := config.Load(ctx, path)
+	if errors.Is(err, config.ErrConfigNotFound) {
+		return sampleGates(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Gates, nil
+}
+
+// sampleGates is a representative gate set for demos outside a configured project.
+func sampleGates() []config.Gate {
+	nonBlocking := false
+	return []config.Gate{
+		{Name: "go-vet", Type: config.GateTypeExec, Command: "go vet ./...", Container: "golang:1.23"},
+		{Name: "go-test", Type: config.GateTypeExec, Command: "go test -json ./...", Container: "golang:1.23", Parser: "go-test-json", Timeout: 120 * time.Second},
+		{Name: "golangci-lint", Type: config.GateTypeExec, Command: "golangci-lint run --out-format sarif ./...", Container: "golangci/golangci-lint:latest", Parser: "sarif"},
+		{Name: "eslint", Type: config.GateTypeExec, Command: "npx eslint --format json .", Container: "node:20", Timeout: 60 * time.Second},
+		{Name: "secret-review", Type: config.GateTypeLLM, Provider: "gemini", Mode: "diff", Prompt: "Check for hardcoded secrets", Blocking: &nonBlocking},
+	}
+}
+
+// runDemo runs simulated gates through the regular runner and formatter.
+// It returns ErrGatesFailed (or ErrGatesErrored) when a blocking gate fails,
+// like Pipeline.Execute.
+func runDemo(ctx context.Context, gates []config.Gate, outcomes map[string]gate.Outcome, stdout, stderr io.Writer, opts PipelineOpts) error {
+	if err := checkOutputOpts(opts); err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(gates))
+	for _, g := range gates {
+		known[g.Name] = true
+	}
+	var unknown []string
+	for name := range outcomes {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("--simulate: unknown gate(s) %s", strings.Join(unknown, ", "))
+	}
+
+	fmt.Fprintln(stderr, "🎭 Demo mode — results are simulated, nothing is executed")
+
+	instances := make([]gate.Gate, len(gates))
+	names := make([]string, len(gates))
+	for i, g := range gates {
+		outcome, ok := outcomes[g.Name]
+		if !ok {
+			outcome = gate.OutcomePass
+		}
+		instances[i] = gate.NewSimulatedGate(g, outcome)
+		names[i] = g.Name
+	}
+
+	progress := runner.NewProgress(stderr, opts.JSON, len(gates))
+	progress.SetAccessible(opts.Accessible)
+	engine := runner.NewEngineWithProgress(progress)
+	engine.Sequential = flagSequential
+	engine.Use(failMessages(gates))
+	result, err := engine.RunAll(ctx, instances, failFast(opts.FailFast), names)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(stdout, newFormatter(opts).Format(*result))
+
+	if !result.Passed {
+		return gatesError(result)
+	}
+	return nil
+}
+
+func init() {
+	demoCmd.Flags().StringSliceVar(&flagSimulate, "simulate", nil, "Simulated outcomes as outcome:gate (pass, fail, timeout, error, skip)")
+	rootCmd.AddCommand(demoCmd)
+}
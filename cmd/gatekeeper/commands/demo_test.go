@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+)
+
+func TestRunDemo_SimulatedFailures(t *testing.T) {
+	outcomes, err := gate.ParseSimulation([]string{"fail:go-test", "timeout:eslint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err = runDemo(context.Background(), sampleGates(), outcomes, &stdout, &stderr, PipelineOpts{JSON: true})
+	if !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed, got %v", err)
+	}
+	assertContains(t, stderr.String(), "Demo mode")
+
+	var result formatter.RunResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("expected JSON output: %v\n%s", err, stdout.String())
+	}
+	byName := map[string]formatter.GateResult{}
+	for _, g := range result.Gates {
+		byName[g.Name] = g
+	}
+	if g := byName["go-test"]; g.Passed || len(g.Errors) == 0 {
+		t.Errorf("expected go-test to fail with findings, got %+v", g)
+	}
+	if g := byName["eslint"]; g.SystemError == "" {
+		t.Errorf("expected eslint timeout, got %+v", g)
+	}
+	if g := byName["go-vet"]; !g.Passed {
+		t.Errorf("expected unlisted gates to pass, got %+v", g)
+	}
+}
+
+func TestRunDemo_AllPass(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := runDemo(context.Background(), sampleGates()[:1], nil, &stdout, &stderr, PipelineOpts{NoColor: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stdout.String(), "Gatekeeper — passed")
+}
+
+func TestRunDemo_Accessible(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	opts := PipelineOpts{NoColor: true, Accessible: true}
+	if err := runDemo(context.Background(), sampleGates()[:1], nil, &stdout, &stderr, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stderr.String(), "passed: "+sampleGates()[0].Name)
+	assertContains(t, stdout.String(), "✅ PASS")
+	assertContains(t, stdout.String(), "Summary: Gatekeeper passed. 1 gate: 1 passed.")
+}
+
+func TestRunDemo_UnknownGate(t *testing.T) {
+	err := runDemo(context.Background(), sampleGates(), map[string]gate.Outcome{"nope": gate.OutcomeFail}, &bytes.Buffer{}, &bytes.Buffer{}, PipelineOpts{})
+	if err == nil {
+		t.Fatal("expected error for unknown gate")
+	}
+	assertContains(t, err.Error(), "unknown gate(s) nope")
+}
+
+func TestRunDemo_Markdown(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := runDemo(context.Background(), sampleGates()[:1], nil, &stdout, &stderr, PipelineOpts{Format: formatMarkdown}); err != nil {
+		t.Fatalf("runDemo: %v", err)
+	}
+	assertContains(t, stdout.String(), formatter.MarkdownMarker)
+	assertContains(t, stdout.String(), "## ✅ Gatekeeper passed")
+
+	err := runDemo(context.Background(), sampleGates(), nil, &bytes.Buffer{}, &bytes.Buffer{}, PipelineOpts{Format: "html"})
+	if err == nil {
+		t.Fatal("expected error for unknown --format")
+	}
+	assertContains(t, err.Error(), `unknown --format "html"`)
+}
+
+func TestRunDemo_UnknownGroupBy(t *testing.T) {
+	err := runDemo(context.Background(), sampleGates(), nil, &bytes.Buffer{}, &bytes.Buffer{}, PipelineOpts{GroupBy: "rule"})
+	if err == nil {
+		t.Fatal("expected error for unknown --group-by")
+	}
+	assertContains(t, err.Error(), `unknown --group-by "rule"`)
+}
+
+func TestDemoGates_FallsBackToSample(t *testing.T) {
+	gates, err := demoGates(context.Background(), filepath.Join(t.TempDir(), "gates.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gates) != len(sampleGates()) {
+		t.Errorf("expected sample gates, got %d", len(gates))
+	}
+}
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// flagDismissReason is the optional note stored with a dismissal.
+var flagDismissReason string
+
+var dismissCmd = &cobra.Command{
+	Use:   "dismiss <finding-id>",
+	Short: "Dismiss an LLM finding so it stops blocking commits",
+	Long: `Dismiss a finding of an LLM gate from the last run. The finding id is the
+fingerprint listed by 'gatekeeper triage' (a unique prefix is enough).
+
+The finding is remembered together with the code it points at: the same
+finding on the same line of code is dropped from later reviews, even if the
+line moves. If the code changes, the finding is reported again.
+
+Dismissals are stored in .gatekeeper/results/dismissed.json and are not
+committed. To suppress a finding for the whole team, use 'gatekeeper triage mark'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		last, err := results.NewStore(results.DefaultDir(projectDir)).LoadLast()
+		if err != nil {
+			return err
+		}
+		path := llm.DismissalsPath(projectDir)
+		dismissals, err := llm.LoadDismissals(path)
+		if err != nil {
+			return err
+		}
+
+		dis, err := dismissFinding(dismissals, last, args[0], flagDismissReason, stagedLines(ctx, git.NewExecService(projectDir)), time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if err := dismissals.Save(path); err != nil {
+			return err
+		}
+
+		recordAudit(ctx, projectDir, audit.Entry{
+			Event:   audit.EventDismiss,
+			Gates:   []string{dis.Gate},
+			Files:   []string{dis.File},
+			Finding: dis.Message,
+			Reason:  dis.Reason,
+		})
+		logger.FromContext(ctx).Info("finding dismissed", "gate", dis.Gate, "file", dis.File, "line", dis.Line)
+		fmt.Fprintf(cmd.OutOrStdout(), "🙈 Dismissed %s%s in %s\n", findingLocation(parser.StructuredError{File: dis.File, Line: dis.Line}), dis.Message, dis.Gate)
+		return nil
+	},
+}
+
+// dismissFinding dismisses the active LLM finding of the last run matching a
+// fingerprint or unique prefix. code returns the content of a file's line.
+func dismissFinding(d *llm.Dismissals, last *formatter.RunResult, prefix, reason string, code func(file string, line int) string, now time.Time) (llm.Dismissal, error) {
+	llmGates := make(map[string]bool)
+	for _, g := range last.Gates {
+		if g.Type == "llm" {
+			llmGates[g.Name] = true
+		}
+	}
+
+	var match *triageFinding
+	for _, f := range collectFindings(last, &triage.Store{}) {
+		if f.Suppressed || !strings.HasPrefix(f.Fingerprint, prefix) {
+			continue
+		}
+		if match != nil && match.Fingerprint != f.Fingerprint {
+			return llm.Dismissal{}, fmt.Errorf("%w: %q", triage.ErrAmbiguous, prefix)
+		}
+		match = &f
+	}
+	if match == nil {
+		return llm.Dismissal{}, fmt.Errorf("no finding %q in the last run", prefix)
+	}
+	if !llmGates[match.Gate] {
+		return llm.Dismissal{}, fmt.Errorf("finding %q is from %s, not an LLM gate; use 'gatekeeper triage mark' instead", prefix, match.Gate)
+	}
+
+	dis := llm.Dismissal{
+		Key:       llm.DismissalKey(match.Gate, match.StructuredError, code(match.File, match.Line)),
+		Gate:      match.Gate,
+		File:      match.File,
+		Line:      match.Line,
+		Message:   match.Message,
+		Reason:    strings.TrimSpace(reason),
+		CreatedAt: now,
+	}
+	d.Add(dis)
+	return dis, nil
+}
+
+// stagedLines returns a lookup of staged lines, as LLM gates match dismissals against.
+func stagedLines(ctx context.Context, gitSvc git.Service) func(file string, line int) string {
+	return func(file string, line int) string {
+		content, err := gitSvc.StagedFileContent(ctx, file)
+		if err != nil {
+			logger.FromContext(ctx).Debug("could not read staged file", "file", file, "error", err)
+		}
+		return llm.LineOf(content, line)
+	}
+}
+
+func init() {
+	dismissCmd.Flags().StringVar(&flagDismissReason, "reason", "", "Why the finding does not apply (optional)")
+	rootCmd.AddCommand(dismissCmd)
+}
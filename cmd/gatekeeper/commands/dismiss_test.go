@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+)
+
+func dismissRun() *formatter.RunResult {
+	return &formatter.RunResult{
+		Gates: []formatter.GateResult{
+			{
+				Name:   "review",
+				Type:   "llm",
+				Errors: []parser.StructuredError{{File: "db.go", Line: 2, Severity: "error", Message: "SQL injection", Tool: "gemini"}},
+			},
+			{
+				Name:   "lint",
+				Type:   "exec",
+				Errors: []parser.StructuredError{{File: "a.go", Line: 1, Severity: "error", Message: "unused", Tool: "golangci-lint"}},
+			},
+		},
+	}
+}
+
+func TestDismissFinding(t *testing.T) {
+	last := dismissRun()
+	finding := last.Gates[0].Errors[0]
+	fp := triage.Fingerprint("review", finding)
+	code := func(file string, line int) string {
+		if file == "db.go" && line == 2 {
+			return `q := "SELECT " + id`
+		}
+		return ""
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := &llm.Dismissals{}
+
+	dis, err := dismissFinding(d, last, fp[:6], " reviewed ", code, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dis.Gate != "review" || dis.File != "db.go" || dis.Reason != "reviewed" || !dis.CreatedAt.Equal(now) {
+		t.Errorf("unexpected dismissal: %+v", dis)
+	}
+	if !d.Contains(llm.DismissalKey("review", finding, `q := "SELECT " + id`)) {
+		t.Error("expected dismissal keyed by finding and code")
+	}
+}
+
+func TestDismissFinding_Errors(t *testing.T) {
+	last := dismissRun()
+	code := func(string, int) string { return "" }
+	d := &llm.Dismissals{}
+
+	if _, err := dismissFinding(d, last, "zzzz", "", code, time.Now()); err == nil || !strings.Contains(err.Error(), "no finding") {
+		t.Errorf("expected unknown finding error, got %v", err)
+	}
+	if _, err := dismissFinding(d, last, "", "", code, time.Now()); !errors.Is(err, triage.ErrAmbiguous) {
+		t.Errorf("expected ambiguous prefix error, got %v", err)
+	}
+	fp := triage.Fingerprint("lint", last.Gates[1].Errors[0])
+	if _, err := dismissFinding(d, last, fp, "", code, time.Now()); err == nil || !strings.Contains(err.Error(), "triage mark") {
+		t.Errorf("expected non-LLM finding error, got %v", err)
+	}
+}
@@ -7,8 +7,12 @@ import (
 var dryRunCmd = &cobra.Command{
 	Use:   "dry-run",
 	Short: "Run all gates but always exit 0 (informational only)",
-	Long: `Execute all configured gates identically to 'run', but always exit 0
-regardless of gate results. Useful for testing configuration without blocking commits.`,
+	Long: `Execute all configured gates like 'run', but always exit 0
+regardless of gate results. Useful for testing configuration without blocking commits.
+
+LLM gates do not send the diff: they build the prompts they would send and
+report an estimated token count and cost per gate and in total. No API key
+is needed.`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		return runPipeline(cmd.Context(), true)
 	},
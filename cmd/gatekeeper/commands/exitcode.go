@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// Exit codes by failure class, so hook scripts and CI can branch on why a
+// command failed.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // usage errors and anything not classified below
+	ExitGatesFailed = 2
+	ExitSystemError = 3 // Docker, git, or a gate that could not run
+	ExitConfigError = 4
+	ExitCancelled   = 5
+)
+
+// ErrGatesErrored is returned when the blocking gates that failed all failed
+// because they could not run, such as when their container did not start.
+// It is also ErrGatesFailed.
+var ErrGatesErrored = fmt.Errorf("%w: a gate could not run", ErrGatesFailed)
+
+// configError marks an error in gates.yaml or the user config.
+type configError struct{ err error }
+
+func (e *configError) Error() string { return e.err.Error() }
+func (e *configError) Unwrap() error { return e.err }
+
+// systemError marks a failure of what a run depends on, such as Docker or
+// git, rather than of its gates.
+type systemError struct{ err error }
+
+func (e *systemError) Error() string { return e.err.Error() }
+func (e *systemError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code for an error returned by a command.
+func ExitCode(err error) int {
+	var cfgErr *configError
+	var sysErr *systemError
+	var preflightErr *pool.PreflightError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, context.Canceled):
+		return ExitCancelled
+	case errors.Is(err, ErrGatesErrored):
+		return ExitSystemError
+	case errors.Is(err, ErrGatesFailed):
+		return ExitGatesFailed
+	case errors.As(err, &cfgErr), errors.Is(err, config.ErrConfigNotFound):
+		return ExitConfigError
+	case errors.As(err, &sysErr), errors.As(err, &preflightErr):
+		return ExitSystemError
+	default:
+		return ExitError
+	}
+}
+
+// runError classifies an error that stopped a run: anything that is not a
+// gate, configuration, or cancellation error is a system error.
+func runError(err error) error {
+	if ExitCode(err) != ExitError {
+		return err
+	}
+	return &systemError{err}
+}
+
+// gatesError returns the error for a run that did not pass: ErrGatesErrored
+// if every blocking gate that failed could not run rather than failed its checks.
+func gatesError(result *formatter.RunResult) error {
+	errored := false
+	for _, g := range result.Gates {
+		if !g.Blocks() {
+			continue
+		}
+		if g.SystemError == "" {
+			return ErrGatesFailed
+		}
+		errored = true
+	}
+	if errored {
+		return ErrGatesErrored
+	}
+	return ErrGatesFailed
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, ExitOK},
+		{"other", errors.New("unknown flag: --nope"), ExitError},
+		{"gates failed", ErrGatesFailed, ExitGatesFailed},
+		{"gates errored", ErrGatesErrored, ExitSystemError},
+		{"docker", &pool.PreflightError{Hint: "Docker is not running"}, ExitSystemError},
+		{"system", runError(errors.New("stashing changes: exit status 128")), ExitSystemError},
+		{"config", &configError{errors.New("parsing gates.yaml: bad indent")}, ExitConfigError},
+		{"no config", fmt.Errorf("loading: %w", config.ErrConfigNotFound), ExitConfigError},
+		{"cancelled", fmt.Errorf("running gates: %w", context.Canceled), ExitCancelled},
+		{"classified run error", runError(&configError{errors.New("bad")}), ExitConfigError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatesError(t *testing.T) {
+	errored := formatter.GateResult{Name: "lint", Blocking: true, SystemError: "container failed to start"}
+	failed := formatter.GateResult{Name: "test", Blocking: true}
+	advisory := formatter.GateResult{Name: "review", Blocking: false}
+	cancelled := formatter.GateResult{Name: "vet", Blocking: true, Skipped: true, Cancelled: true}
+
+	if err := gatesError(&formatter.RunResult{Gates: []formatter.GateResult{errored, advisory, cancelled}}); !errors.Is(err, ErrGatesErrored) {
+		t.Errorf("expected ErrGatesErrored when gates only could not run, got %v", err)
+	}
+	err := gatesError(&formatter.RunResult{Gates: []formatter.GateResult{errored, failed}})
+	if !errors.Is(err, ErrGatesFailed) || errors.Is(err, ErrGatesErrored) {
+		t.Errorf("expected ErrGatesFailed when a gate failed its checks, got %v", err)
+	}
+}
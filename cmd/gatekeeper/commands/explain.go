@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <gate>",
+	Short: "Explain a failed gate of the last run in plain language",
+	Long: `Ask the configured LLM to explain why a gate failed in the last run and how
+to fix it. The explanation is based on the recorded findings, tool output, and
+the gate's on_fail_message; nothing is re-run.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeGateArg,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		last, err := results.NewStore(results.DefaultDir(projectDir)).LoadLast()
+		if err != nil {
+			return err
+		}
+
+		globalCfg, err := config.LoadGlobalConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("loading global config: %w", err)
+		}
+		if globalCfg.GeminiAPIKey.IsEmpty() {
+			return errors.New("explain needs an LLM — set GATEKEEPER_GEMINI_KEY (or GOOGLE_API_KEY / GEMINI_API_KEY) or add to ~/.config/gatekeeper/config.yaml")
+		}
+		client, err := newLLMClient(ctx, globalCfg)
+		if err != nil {
+			return err
+		}
+
+		return explainGate(ctx, cmd.OutOrStdout(), last, args[0], client)
+	},
+}
+
+// explainGate asks client to explain the failure of the named gate in last and
+// prints the answer under the gate's summary line.
+func explainGate(ctx context.Context, out io.Writer, last *formatter.RunResult, name string, client llm.Client) error {
+	var failed *formatter.GateResult
+	var names []string
+	for i := range last.Gates {
+		g := &last.Gates[i]
+		names = append(names, g.Name)
+		if g.Name == name {
+			failed = g
+		}
+	}
+	if failed == nil {
+		return fmt.Errorf("no gate %q in the last run (gates: %s)", name, strings.Join(names, ", "))
+	}
+	if failed.Passed && failed.SystemError == "" {
+		return fmt.Errorf("gate %q passed in the last run; nothing to explain", name)
+	}
+
+	logger.FromContext(ctx).Info("explaining gate failure", "gate", name, "findings", len(failed.Errors))
+	prompt, err := llm.BuildExplainPrompt(llm.Failure{
+		Gate:        failed.Name,
+		Type:        failed.Type,
+		Errors:      failed.Errors,
+		SystemError: failed.SystemError,
+		Output:      failed.RawOutput,
+		Guidance:    failed.OnFailMessage,
+	})
+	if err != nil {
+		return err
+	}
+	explanation, err := client.Summarize(ctx, prompt)
+	if err != nil {
+		return fmt.Errorf("explaining %s: %w", name, err)
+	}
+
+	what := plural(len(failed.Errors), "finding", "findings")
+	if failed.SystemError != "" {
+		what = "system error"
+	}
+	fmt.Fprintf(out, "\n🧠 %s — %s\n\n", failed.Name, what)
+	for _, line := range strings.Split(strings.TrimSpace(explanation), "\n") {
+		fmt.Fprintf(out, "    %s\n", line)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+func explainRun() *formatter.RunResult {
+	return &formatter.RunResult{
+		Gates: []formatter.GateResult{
+			{Name: "lint", Type: "exec", Passed: true},
+			{
+				Name:          "go-test",
+				Type:          "exec",
+				Errors:        []parser.StructuredError{{File: "auth.go", Line: 12, Severity: "error", Message: "TestLogin failed: got 401"}},
+				RawOutput:     "--- FAIL: TestLogin",
+				OnFailMessage: "See docs/testing.md",
+			},
+		},
+	}
+}
+
+// promptClient records the prompt it is asked to summarize.
+type promptClient struct {
+	llm.MockClient
+	prompt string
+}
+
+func (c *promptClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	c.prompt = prompt
+	return c.MockClient.Summarize(ctx, prompt)
+}
+
+func TestExplainGate(t *testing.T) {
+	client := &promptClient{MockClient: llm.MockClient{Summary: "The login test expects 200.\nFix: restore the session check.\n"}}
+	out := &bytes.Buffer{}
+
+	if err := explainGate(context.Background(), out, explainRun(), "go-test", client); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertContains(t, out.String(), "🧠 go-test — 1 finding")
+	assertContains(t, out.String(), "    Fix: restore the session check.")
+	for _, want := range []string{"auth.go:12", "TestLogin failed: got 401", "--- FAIL: TestLogin", "See docs/testing.md"} {
+		assertContains(t, client.prompt, want)
+	}
+}
+
+func TestExplainGate_Errors(t *testing.T) {
+	last := explainRun()
+	client := &llm.MockClient{}
+
+	if err := explainGate(context.Background(), &bytes.Buffer{}, last, "nope", client); err == nil || !strings.Contains(err.Error(), "gates: lint, go-test") {
+		t.Errorf("expected unknown gate error, got %v", err)
+	}
+	if err := explainGate(context.Background(), &bytes.Buffer{}, last, "lint", client); err == nil || !strings.Contains(err.Error(), "nothing to explain") {
+		t.Errorf("expected passed gate error, got %v", err)
+	}
+	client.Err = errors.New("quota exceeded")
+	if err := explainGate(context.Background(), &bytes.Buffer{}, last, "go-test", client); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected client error, got %v", err)
+	}
+}
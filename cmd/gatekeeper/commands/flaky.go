@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/stats"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// flakiness detects flaky gates in a run: gates that passed and failed on
+// the same input in the recorded runs. A nil flakiness detects nothing.
+type flakiness struct {
+	// hashes are the input hashes of the gates of the run, by gate name.
+	hashes  map[string]string
+	history []results.Run
+	// downgraded are the gates that warn instead of blocking in the run.
+	downgraded map[string]bool
+}
+
+// loadFlakiness hashes the input of each gate and reads the recorded runs.
+// It returns nil if the staged index cannot be read.
+func (p *Pipeline) loadFlakiness(ctx context.Context, gates []config.Gate) *flakiness {
+	log := logger.FromContext(ctx)
+	tree, err := p.Git.IndexTree(ctx)
+	if err != nil {
+		log.Debug("could not read index tree, flaky gates are not detected", "error", err)
+		return nil
+	}
+	history, err := p.History.History(0)
+	if err != nil {
+		log.Warn("could not read run history", "error", err)
+	}
+
+	f := &flakiness{hashes: make(map[string]string), history: history, downgraded: make(map[string]bool)}
+	for _, g := range gates {
+		f.hashes[g.Name] = gateInputHash(tree, g)
+	}
+	return f
+}
+
+// gateInputHash identifies what a gate checks: the tree of the staged index
+// and the gate's configuration.
+func gateInputHash(tree string, g config.Gate) string {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	h.Write([]byte(tree))
+	h.Write([]byte{0})
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// downgrade makes the blocking gates that were flaky within the last runs
+// recorded runs non-blocking, and says so on out. runs is
+// defaults.flaky_downgrade; 0 downgrades nothing.
+func (f *flakiness) downgrade(gates []config.Gate, runs int, out io.Writer) []config.Gate {
+	if f == nil || runs <= 0 {
+		return gates
+	}
+	recent := make(map[string]stats.FlakyGate)
+	for _, g := range stats.Flaky(f.history) {
+		if g.RunsSince < runs {
+			recent[g.Name] = g
+		}
+	}
+	if len(recent) == 0 {
+		return gates
+	}
+
+	gates = slices.Clone(gates)
+	for i := range gates {
+		g := &gates[i]
+		flaky, ok := recent[g.Name]
+		if !ok || !g.IsBlocking() {
+			continue
+		}
+		blocking := false
+		g.Blocking = &blocking
+		f.downgraded[g.Name] = true
+		fmt.Fprintf(out, "🎲 %s is flaky (it passed and failed on the same changes); it warns instead of blocking for %s\n",
+			g.Name, plural(runs-flaky.RunsSince, "more run", "more runs"))
+	}
+	return gates
+}
+
+// mark is result middleware that records the input hash of a gate and flags
+// it if it is flaky, counting this run, or downgraded to warn.
+func (f *flakiness) mark(_ context.Context, r *formatter.GateResult) *formatter.GateResult {
+	r.InputHash = f.hashes[r.Name]
+	if r.Skipped {
+		return r
+	}
+
+	current := results.Run{At: time.Now(), Result: formatter.RunResult{Gates: []formatter.GateResult{*r}}}
+	r.Flaky = slices.ContainsFunc(stats.Flaky(append([]results.Run{current}, f.history...)), func(g stats.FlakyGate) bool {
+		return g.Name == r.Name
+	})
+	r.Downgraded = f.downgraded[r.Name]
+	return r
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var flagHistoryLimit int
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List and show the recorded runs",
+	Long: `Every run is recorded in .gatekeeper/results/history. These commands list the
+recorded runs and show the full result of one of them.
+
+Runs are pruned after each run to the retention policy of the user config:
+the last 100 runs of the last 30 days by default (history.max_runs and
+history.max_age; 0 turns a limit off).`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the recorded runs, newest first",
+	Long: `List the recorded runs, newest first, with the ID, time, outcome, duration,
+and gate counts of each. Pass an ID (or a unique prefix of it) to
+'gatekeeper history show'. With --json, the runs are printed as JSON.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		runs, err := results.NewStore(results.DefaultDir(projectDir)).History(flagHistoryLimit)
+		if err != nil {
+			return err
+		}
+		if flagJSON {
+			return writeHistoryJSON(cmd.OutOrStdout(), runs)
+		}
+		writeHistory(cmd.OutOrStdout(), runs)
+		return nil
+	},
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the full result of a recorded run",
+	Long: `Show a recorded run as 'gatekeeper run' printed it, with every gate and
+finding. The ID can be shortened to any prefix that matches a single run.
+The output flags (--json, --format, --verbose, --no-color, --group-by) apply.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeRunID,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		run, err := results.NewStore(results.DefaultDir(projectDir)).Run(args[0])
+		if err != nil {
+			return err
+		}
+
+		globalCfg, err := config.LoadGlobalConfig(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Warn("could not load global config, using default output settings", "error", err)
+			globalCfg = nil
+		}
+		opts := PipelineOpts{
+			JSON:       flagJSON,
+			Verbose:    flagVerbose,
+			NoColor:    flagNoColor,
+			Accessible: accessibleOutput(globalCfg),
+			GroupBy:    flagGroupBy,
+			Format:     flagFormat,
+		}
+		if !opts.JSON && opts.Format != formatJSON {
+			fmt.Fprintf(cmd.OutOrStdout(), "Run %s, recorded %s\n", run.ID, run.At.Local().Format("2006-01-02 15:04:05"))
+		}
+		fmt.Fprint(cmd.OutOrStdout(), newFormatter(opts).Format(run.Result))
+		return nil
+	},
+}
+
+// historyRun is a recorded run in history list JSON output.
+type historyRun struct {
+	ID         string    `json:"id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Passed     bool      `json:"passed"`
+	DurationMs int64     `json:"duration_ms"`
+	Gates      int       `json:"gates"`
+}
+
+// writeHistoryJSON prints the runs as a JSON array.
+func writeHistoryJSON(out io.Writer, runs []results.Run) error {
+	list := []historyRun{}
+	for _, r := range runs {
+		list = append(list, historyRun{ID: r.ID, RecordedAt: r.At, Passed: r.Result.Passed, DurationMs: r.Result.DurationMs, Gates: len(r.Result.Gates)})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(list); err != nil {
+		return fmt.Errorf("encoding history: %w", err)
+	}
+	return nil
+}
+
+// writeHistory prints the runs as a table.
+func writeHistory(out io.Writer, runs []results.Run) {
+	if len(runs) == 0 {
+		fmt.Fprintln(out, "No recorded runs (run 'gatekeeper run' first)")
+		return
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID\tRECORDED\tRESULT\tDURATION\tGATES\n")
+	for _, r := range runs {
+		counts, _ := runSummary(r.Result)
+		fmt.Fprintf(tw, "%s\t%s\t%s %s\t%dms\t%s\n", r.ID, r.At.Local().Format("2006-01-02 15:04"), runIcon(r.Result), outcome(r.Result), r.Result.DurationMs, counts)
+	}
+	_ = tw.Flush()
+}
+
+// completeRunID completes the argument of history show with the IDs of the
+// recorded runs.
+func completeRunID(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	projectDir, err := getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	runs, err := results.NewStore(results.DefaultDir(projectDir)).History(0)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var ids []cobra.Completion
+	for _, r := range runs {
+		ids = append(ids, cobra.CompletionWithDesc(r.ID, outcome(r.Result)+", "+r.At.Local().Format("2006-01-02 15:04")))
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	historyListCmd.Flags().IntVar(&flagHistoryLimit, "limit", 0, "Only list the most recent N runs (0 lists all recorded runs)")
+	historyCmd.AddCommand(historyListCmd, historyShowCmd)
+	rootCmd.AddCommand(historyCmd)
+}
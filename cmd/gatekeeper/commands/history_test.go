@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+)
+
+func TestWriteHistory(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	runs := []results.Run{
+		{ID: "20260301T120000.000000000Z", At: at, Result: statusRunResult()},
+		{ID: "20260301T110000.000000000Z", At: at.Add(-time.Hour), Result: formatter.RunResult{Passed: true, DurationMs: 900, Gates: []formatter.GateResult{{Name: "lint", Passed: true}}}},
+	}
+	out := &bytes.Buffer{}
+
+	writeHistory(out, runs)
+
+	assertContains(t, out.String(), "ID")
+	assertContains(t, out.String(), "20260301T120000.000000000Z")
+	assertContains(t, out.String(), "❌ failed")
+	assertContains(t, out.String(), "4200ms")
+	assertContains(t, out.String(), "1 passed, 1 failed, 1 advisory warning")
+	assertContains(t, out.String(), "✅ passed")
+}
+
+func TestWriteHistory_Empty(t *testing.T) {
+	out := &bytes.Buffer{}
+	writeHistory(out, nil)
+	assertContains(t, out.String(), "No recorded runs")
+}
+
+func TestWriteHistoryJSON(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+
+	if err := writeHistoryJSON(out, []results.Run{{ID: "20260301T120000.000000000Z", At: at, Result: statusRunResult()}}); err != nil {
+		t.Fatalf("writeHistoryJSON: %v", err)
+	}
+
+	var got []historyRun
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(got) != 1 || got[0].ID != "20260301T120000.000000000Z" || got[0].Passed || got[0].Gates != 3 || got[0].DurationMs != 4200 {
+		t.Errorf("unexpected history: %+v", got)
+	}
+}
@@ -2,13 +2,16 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
 	"github.com/spf13/cobra"
@@ -20,14 +23,84 @@ type InitFS interface {
 	IsNotExist(err error) bool
 	MkdirAll(path string, perm fs.FileMode) error
 	ReadDir(name string) ([]fs.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
 	WriteFile(name string, data []byte, perm fs.FileMode) error
 }
 
+var (
+	// flagInitCommitSummary installs the prepare-commit-msg summary hook.
+	flagInitCommitSummary bool
+	// flagInitHookManager selects how the pre-commit hook is installed.
+	flagInitHookManager string
+	// flagInitChain wraps an existing pre-commit hook instead of refusing it.
+	flagInitChain bool
+	// flagInitCommitMsg runs gatekeeper from a commit-msg hook instead of pre-commit.
+	flagInitCommitMsg bool
+	// flagInitCI and flagInitCIMode select the CI workflow to generate.
+	flagInitCI     string
+	flagInitCIMode string
+)
+
+// hookManagerAuto and hookManagerNone are the --hook-manager values besides
+// the names of hook managers.
+const (
+	hookManagerAuto = "auto"
+	hookManagerNone = "none"
+)
+
+// ciModeDocker and ciModeLocal are the --ci-mode values: gates in Docker
+// (Docker-in-Docker where the CI job is itself a container), or on the runner.
+const (
+	ciModeDocker = "docker"
+	ciModeLocal  = "local"
+)
+
+// initOptions are the options of initProject.
+type initOptions struct {
+	// CommitSummary installs the prepare-commit-msg summary hook.
+	CommitSummary bool
+	// HookManager is the --hook-manager value: auto, none, or a hook manager.
+	HookManager string
+	// Chain wraps an existing pre-commit hook rather than failing on it.
+	Chain bool
+	// CommitMsg runs gatekeeper from a commit-msg hook instead of pre-commit.
+	CommitMsg bool
+	// CI is the CI provider to generate a workflow for, if any.
+	CI string
+	// CIMode is the --ci-mode value: docker or local.
+	CIMode string
+}
+
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize gatekeeper in the current project",
 	Long: `Detect the project's technology stack, generate a default .gatekeeper/gates.yaml,
-and install the git pre-commit hook.`,
+and install the git pre-commit hook.
+
+Projects that use a hook manager (husky, pre-commit, or lefthook) keep it:
+gatekeeper is added to the manager's pre-commit hook instead, between marker
+comments that 'gatekeeper teardown' removes again. --hook-manager picks the
+manager when several are configured; --hook-manager none installs gatekeeper's
+own hook regardless.
+
+An existing pre-commit hook that gatekeeper did not install is an error,
+unless --chain is given: the hook is then moved to pre-commit.gatekeeper-saved
+and gatekeeper's hook runs it first. 'gatekeeper teardown' puts it back.
+
+With --commit-msg, gatekeeper's own hook runs from commit-msg instead of
+pre-commit, with the commit message, so [skip gatekeeper] and
+[skip gate:<name>] in the message apply. The gates then run after the message
+is written; git keeps it in .git/COMMIT_EDITMSG if the commit is blocked.
+Hook managers and --chain are not used with --commit-msg.
+
+With --ci (github by default, or gitlab or circleci), also generate a CI
+workflow that installs gatekeeper and runs 'gatekeeper ci' on each push, with
+gate images and tool caches cached between runs. --ci-mode local runs the
+gates on the CI runner instead of in Docker. An existing workflow file is left
+alone.
+
+With --commit-summary, also install a prepare-commit-msg hook that appends a
+commented summary of the gate results to the commit message template.`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		ctx := cmd.Context()
 		log := logger.FromContext(ctx)
@@ -39,7 +112,14 @@ and install the git pre-commit hook.`,
 		}
 
 		gitSvc := git.NewExecService(projectDir)
-		if err := initProject(ctx, projectDir, &osInitFS{}, gitSvc, cmd.OutOrStdout()); err != nil {
+		if err := initProject(ctx, projectDir, &osInitFS{}, gitSvc, cmd.OutOrStdout(), initOptions{
+			CommitSummary: flagInitCommitSummary,
+			HookManager:   flagInitHookManager,
+			Chain:         flagInitChain,
+			CommitMsg:     flagInitCommitMsg,
+			CI:            flagInitCI,
+			CIMode:        flagInitCIMode,
+		}); err != nil {
 			return err
 		}
 
@@ -49,7 +129,20 @@ and install the git pre-commit hook.`,
 }
 
 // initProject performs the init workflow with injected dependencies for testability.
-func initProject(ctx context.Context, projectDir string, fsys InitFS, gitSvc git.Service, out io.Writer) error {
+func initProject(ctx context.Context, projectDir string, fsys InitFS, gitSvc git.Service, out io.Writer, opts initOptions) error {
+	// Check the CI options before changing anything.
+	var workflow *config.CIWorkflow
+	if opts.CI != "" {
+		if opts.CIMode != "" && opts.CIMode != ciModeDocker && opts.CIMode != ciModeLocal {
+			return fmt.Errorf("invalid --ci-mode %q (want docker or local)", opts.CIMode)
+		}
+		w, err := config.GenerateCIWorkflow(opts.CI, opts.CIMode == ciModeLocal)
+		if err != nil {
+			return err
+		}
+		workflow = &w
+	}
+
 	// 1. Create .gatekeeper directory if it doesn't exist.
 	gkDir := filepath.Join(projectDir, ".gatekeeper")
 	if err := fsys.MkdirAll(gkDir, 0o750); err != nil {
@@ -70,7 +163,10 @@ func initProject(ctx context.Context, projectDir string, fsys InitFS, gitSvc git
 		}
 
 		stacks := config.DetectStacks(files)
-		yamlContent := config.GenerateGatesYAML(stacks)
+		yamlContent, genErr := config.GenerateGatesYAML(stacks)
+		if genErr != nil {
+			return fmt.Errorf("generating gates.yaml: %w", genErr)
+		}
 
 		if writeErr := fsys.WriteFile(configPath, []byte(yamlContent), 0o644); writeErr != nil { // #nosec G306 -- config file, not sensitive
 			return fmt.Errorf("writing gates.yaml: %w", writeErr)
@@ -85,15 +181,181 @@ func initProject(ctx context.Context, projectDir string, fsys InitFS, gitSvc git
 		fmt.Fprintf(out, "⚡ Config already exists at %s. Skipping generation.\n", configPath)
 	}
 
-	// 3. Install git pre-commit hook.
-	if err := gitSvc.InstallHook(ctx); err != nil {
-		return fmt.Errorf("installing hook: %w", err)
+	// 3. Keep gatekeeper state and generated artifacts out of commits.
+	added, err := updateGitignore(fsys, projectDir)
+	if err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		fmt.Fprintf(out, "🙈 Added %s to .gitignore.\n", strings.Join(added, ", "))
+	}
+
+	// 4. Install git pre-commit hook, or add gatekeeper to the project's hook
+	// manager; or install the commit-msg hook.
+	if opts.CommitMsg {
+		if err := gitSvc.InstallMessageHook(ctx); err != nil {
+			return fmt.Errorf("installing commit-msg hook: %w", err)
+		}
+		fmt.Fprintln(out, "✉️  Gates run from the commit-msg hook: [skip gatekeeper] in a commit message skips them.")
+	} else if err := installPreCommit(ctx, projectDir, gitSvc, out, opts); err != nil {
+		return err
+	}
+
+	// 5. Optionally install the prepare-commit-msg summary hook.
+	if opts.CommitSummary {
+		if err := gitSvc.InstallSummaryHook(ctx); err != nil {
+			return fmt.Errorf("installing commit summary hook: %w", err)
+		}
+		fmt.Fprintln(out, "📝 Commit messages will include a gate summary (as comments).")
+	}
+
+	// 6. Optionally generate the CI workflow.
+	if workflow != nil {
+		if err := writeCIWorkflow(fsys, projectDir, *workflow, out); err != nil {
+			return err
+		}
 	}
 
 	fmt.Fprintln(out, "🔒 Gatekeeper initialized successfully!")
 	return nil
 }
 
+// installPreCommit runs gatekeeper before commits: through the hook manager
+// selected by opts.HookManager if the project uses one, else with its own
+// pre-commit hook, chained to the existing hook with opts.Chain.
+func installPreCommit(ctx context.Context, projectDir string, gitSvc git.Service, out io.Writer, opts initOptions) error {
+	mode := opts.HookManager
+	managers := git.DetectHookManagers(projectDir)
+	var manager git.HookManager
+	switch mode {
+	case hookManagerNone:
+	case "", hookManagerAuto:
+		if len(managers) > 0 {
+			manager = managers[0]
+		}
+	default:
+		m, ok := git.FindHookManager(managers, mode)
+		if !ok {
+			return fmt.Errorf("--hook-manager %s: no %s config found in %s", mode, mode, projectDir)
+		}
+		manager = m
+	}
+
+	if manager.Name == "" && opts.Chain {
+		chained, err := gitSvc.InstallChainedHook(ctx)
+		if err != nil {
+			return fmt.Errorf("installing hook: %w", err)
+		}
+		if chained {
+			fmt.Fprintln(out, "🔗 Existing pre-commit hook saved as pre-commit.gatekeeper-saved; it runs before gatekeeper.")
+		}
+		return nil
+	}
+	if manager.Name == "" {
+		err := gitSvc.InstallHook(ctx)
+		if errors.Is(err, git.ErrHookExists) {
+			return fmt.Errorf("installing hook: %w (use --chain to run it before gatekeeper)", err)
+		}
+		if err != nil {
+			return fmt.Errorf("installing hook: %w", err)
+		}
+		return nil
+	}
+
+	added, err := manager.Install(projectDir)
+	if err != nil {
+		return err
+	}
+	logger.FromContext(ctx).Info("added gatekeeper to hook manager", "manager", manager.Name, "config", manager.Config, "added", added)
+	if !added {
+		fmt.Fprintf(out, "⚡ gatekeeper already runs from %s (%s).\n", manager.Config, manager.Name)
+		return nil
+	}
+	fmt.Fprintf(out, "🪝 Added gatekeeper to %s (%s). Run '%s' if its hooks are not installed yet.\n", manager.Config, manager.Name, manager.Activate)
+	return nil
+}
+
+// writeCIWorkflow writes a generated CI workflow into the project, unless the
+// file already exists.
+func writeCIWorkflow(fsys InitFS, projectDir string, w config.CIWorkflow, out io.Writer) error {
+	path := filepath.Join(projectDir, filepath.FromSlash(w.Path))
+	if _, err := fsys.Stat(path); !fsys.IsNotExist(err) {
+		fmt.Fprintf(out, "⚡ %s already exists. Skipping CI workflow generation.\n", w.Path)
+		return nil
+	}
+	if err := fsys.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(w.Path), err)
+	}
+	if err := fsys.WriteFile(path, []byte(w.Content), 0o644); err != nil { // #nosec G306 -- CI config, not sensitive
+		return fmt.Errorf("writing %s: %w", w.Path, err)
+	}
+	fmt.Fprintf(out, "🏗️  Generated %s, which runs 'gatekeeper ci'. Commit it to enforce the gates in CI.\n", w.Path)
+	return nil
+}
+
+// gitignoreHeader introduces the entries init adds to .gitignore.
+const gitignoreHeader = "# gatekeeper"
+
+// updateGitignore adds the entries of gate.GitignoreEntries missing from the
+// project's .gitignore, creating it if needed, and returns the added entries.
+// Running it again adds nothing.
+func updateGitignore(fsys InitFS, projectDir string) ([]string, error) {
+	path := filepath.Join(projectDir, ".gitignore")
+	data, err := fsys.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("reading .gitignore: %w", err)
+	}
+
+	updated, added := appendGitignore(string(data), gate.GitignoreEntries())
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if err := fsys.WriteFile(path, []byte(updated), 0o644); err != nil { // #nosec G306 -- gitignore, not sensitive
+		return nil, fmt.Errorf("writing .gitignore: %w", err)
+	}
+	return added, nil
+}
+
+// appendGitignore appends the entries not already ignored by an identical line
+// (ignoring a leading "/" and trailing "/") under a gatekeeper header.
+func appendGitignore(existing string, entries []string) (string, []string) {
+	present := make(map[string]bool)
+	hasHeader := false
+	for _, line := range strings.Split(existing, "\n") {
+		line = strings.TrimSpace(line)
+		if line == gitignoreHeader {
+			hasHeader = true
+		}
+		present[strings.Trim(line, "/")] = true
+	}
+
+	var added []string
+	for _, e := range entries {
+		if !present[strings.Trim(e, "/")] {
+			added = append(added, e)
+		}
+	}
+	if len(added) == 0 {
+		return existing, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(existing)
+	if existing != "" && !strings.HasSuffix(existing, "\n") {
+		b.WriteString("\n")
+	}
+	if !hasHeader {
+		if existing != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(gitignoreHeader + "\n")
+	}
+	for _, e := range added {
+		b.WriteString(e + "\n")
+	}
+	return b.String(), added
+}
+
 // getwd is a variable for testability (defaults to os.Getwd).
 var getwd = os.Getwd
 
@@ -106,21 +368,19 @@ func formatStacks(stacks []config.Stack) string {
 	for _, s := range stacks {
 		stackStrings = append(stackStrings, string(s))
 	}
-	return join(stackStrings, " + ")
-}
-
-// join concatenates string slices with a separator (avoids importing strings for one call).
-func join(elems []string, sep string) string {
-	if len(elems) == 0 {
-		return ""
-	}
-	result := elems[0]
-	for _, e := range elems[1:] {
-		result += sep + e
-	}
-	return result
+	return strings.Join(stackStrings, " + ")
 }
 
 func init() {
+	initCmd.Flags().StringVar(&flagInitHookManager, "hook-manager", hookManagerAuto, "How to run gatekeeper before commits: auto (through a detected husky, pre-commit, or lefthook setup), none (gatekeeper's own hook), or husky, pre-commit, or lefthook")
+	initCmd.Flags().BoolVar(&flagInitChain, "chain", false, "Keep an existing pre-commit hook: save it as pre-commit.gatekeeper-saved and run it before gatekeeper")
+	initCmd.Flags().BoolVar(&flagInitCommitMsg, "commit-msg", false, "Run gatekeeper from a commit-msg hook instead of pre-commit, so [skip gatekeeper] markers in commit messages apply")
+	initCmd.MarkFlagsMutuallyExclusive("commit-msg", "chain")
+	initCmd.Flags().StringVar(&flagInitCI, "ci", "", "Also generate a CI workflow that runs 'gatekeeper ci': github, gitlab, or circleci")
+	initCmd.Flags().Lookup("ci").NoOptDefVal = config.CIGitHub
+	initCmd.Flags().StringVar(&flagInitCIMode, "ci-mode", ciModeDocker, "Where the CI workflow runs gates: docker or local (on the runner, with --no-docker)")
+	_ = initCmd.RegisterFlagCompletionFunc("ci", cobra.FixedCompletions(config.CIProviders(), cobra.ShellCompDirectiveNoFileComp))
+	_ = initCmd.RegisterFlagCompletionFunc("ci-mode", cobra.FixedCompletions([]string{ciModeDocker, ciModeLocal}, cobra.ShellCompDirectiveNoFileComp))
+	initCmd.Flags().BoolVar(&flagInitCommitSummary, "commit-summary", false, "Also install a prepare-commit-msg hook that adds a gate summary to the message template")
 	rootCmd.AddCommand(initCmd)
 }
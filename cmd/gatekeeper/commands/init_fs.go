@@ -3,6 +3,8 @@ package commands
 import (
 	"io/fs"
 	"os"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
 )
 
 // osInitFS implements InitFS using the os package.
@@ -24,6 +26,10 @@ func (o *osInitFS) ReadDir(name string) ([]fs.DirEntry, error) {
 	return os.ReadDir(name)
 }
 
+func (o *osInitFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name) // #nosec G304 -- name is a file in the project directory
+}
+
 func (o *osInitFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
-	return os.WriteFile(name, data, perm) // #nosec G306 -- config file, not sensitive
+	return fileutil.WriteFileAtomic(name, data, perm)
 }
@@ -5,6 +5,8 @@ import (
 	"context"
 	"errors"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -23,6 +25,8 @@ type mockInitFS struct {
 	writeErr       error
 	writtenData    []byte
 	writtenPath    string
+	// files are readable file contents; written files are added to it.
+	files map[string][]byte
 }
 
 func (m *mockInitFS) Stat(_ string) (fs.FileInfo, error) {
@@ -45,10 +49,25 @@ func (m *mockInitFS) ReadDir(_ string) ([]fs.DirEntry, error) {
 	return m.readDirEntries, m.readDirErr
 }
 
+func (m *mockInitFS) ReadFile(name string) ([]byte, error) {
+	data, ok := m.files[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return data, nil
+}
+
 func (m *mockInitFS) WriteFile(name string, data []byte, _ fs.FileMode) error {
 	m.writtenPath = name
 	m.writtenData = data
-	return m.writeErr
+	if m.writeErr != nil {
+		return m.writeErr
+	}
+	if m.files == nil {
+		m.files = make(map[string][]byte)
+	}
+	m.files[name] = data
+	return nil
 }
 
 // mockFileInfo satisfies fs.FileInfo for testing.
@@ -85,7 +104,7 @@ func TestInitProject_HappyPath_GoProject(t *testing.T) {
 	gitSvc := &git.MockService{}
 	out := &bytes.Buffer{}
 
-	err := initProject(context.Background(), "/project", fsys, gitSvc, out)
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -94,7 +113,7 @@ func TestInitProject_HappyPath_GoProject(t *testing.T) {
 	if !strings.Contains(out.String(), "Detected") {
 		t.Errorf("expected 'Detected' in output, got %q", out.String())
 	}
-	if fsys.writtenData == nil {
+	if fsys.files["/project/.gatekeeper/gates.yaml"] == nil {
 		t.Error("expected gates.yaml to be written")
 	}
 }
@@ -106,7 +125,7 @@ func TestInitProject_ConfigAlreadyExists(t *testing.T) {
 	gitSvc := &git.MockService{}
 	out := &bytes.Buffer{}
 
-	err := initProject(context.Background(), "/project", fsys, gitSvc, out)
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -122,7 +141,7 @@ func TestInitProject_MkdirError(t *testing.T) {
 	gitSvc := &git.MockService{}
 	out := &bytes.Buffer{}
 
-	err := initProject(context.Background(), "/project", fsys, gitSvc, out)
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -143,7 +162,7 @@ func TestInitProject_WriteError(t *testing.T) {
 	gitSvc := &git.MockService{}
 	out := &bytes.Buffer{}
 
-	err := initProject(context.Background(), "/project", fsys, gitSvc, out)
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -159,7 +178,7 @@ func TestInitProject_HookInstallError(t *testing.T) {
 	gitSvc := &git.MockService{HookInstErr: errors.New("not a git repo")}
 	out := &bytes.Buffer{}
 
-	err := initProject(context.Background(), "/project", fsys, gitSvc, out)
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{})
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -167,3 +186,181 @@ func TestInitProject_HookInstallError(t *testing.T) {
 		t.Errorf("unexpected error: %q", err.Error())
 	}
 }
+
+func TestInitProject_CommitSummaryHook(t *testing.T) {
+	fsys := &mockInitFS{statNotExist: false}
+	gitSvc := &git.MockService{}
+	out := &bytes.Buffer{}
+
+	if err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{CommitSummary: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !gitSvc.SummaryHook {
+		t.Error("expected prepare-commit-msg hook to be installed")
+	}
+	if !strings.Contains(out.String(), "gate summary") {
+		t.Errorf("expected summary hook message, got %q", out.String())
+	}
+}
+
+func TestInitProject_CommitMsgHook(t *testing.T) {
+	fsys := &mockInitFS{statNotExist: false}
+	gitSvc := &git.MockService{}
+	out := &bytes.Buffer{}
+
+	if err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{CommitMsg: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !gitSvc.MessageHook {
+		t.Error("expected commit-msg hook to be installed")
+	}
+	assertContains(t, out.String(), "commit-msg hook")
+}
+
+func TestInitProject_ChainHook(t *testing.T) {
+	fsys := &mockInitFS{statNotExist: false}
+	out := &bytes.Buffer{}
+
+	gitSvc := &git.MockService{Chained: true}
+	if err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{HookManager: hookManagerNone, Chain: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), "saved as pre-commit.gatekeeper-saved")
+
+	gitSvc = &git.MockService{HookInstErr: git.ErrHookExists}
+	err := initProject(context.Background(), "/project", fsys, gitSvc, out, initOptions{HookManager: hookManagerNone})
+	if !errors.Is(err, git.ErrHookExists) {
+		t.Fatalf("expected ErrHookExists, got %v", err)
+	}
+	assertContains(t, err.Error(), "--chain")
+}
+
+func TestInitProject_CIWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	out := &bytes.Buffer{}
+	opts := initOptions{CI: "gitlab", CIMode: ciModeLocal}
+
+	if err := initProject(context.Background(), dir, &osInitFS{}, &git.MockService{}, out, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), "Generated .gitlab/ci/gatekeeper.yml")
+	data, err := os.ReadFile(filepath.Join(dir, ".gitlab", "ci", "gatekeeper.yml"))
+	if err != nil {
+		t.Fatalf("reading workflow: %v", err)
+	}
+	assertContains(t, string(data), "gatekeeper ci --no-docker")
+
+	// A second init leaves the workflow alone.
+	if err := os.WriteFile(filepath.Join(dir, ".gitlab", "ci", "gatekeeper.yml"), []byte("edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := initProject(context.Background(), dir, &osInitFS{}, &git.MockService{}, out, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), ".gitlab/ci/gatekeeper.yml already exists")
+	if data, _ := os.ReadFile(filepath.Join(dir, ".gitlab", "ci", "gatekeeper.yml")); string(data) != "edited\n" {
+		t.Errorf("existing workflow was overwritten: %q", data)
+	}
+}
+
+func TestInitProject_CIWorkflowInvalid(t *testing.T) {
+	for _, opts := range []initOptions{{CI: "jenkins"}, {CI: "github", CIMode: "kubernetes"}} {
+		fsys := &mockInitFS{statNotExist: true}
+		err := initProject(context.Background(), "/project", fsys, &git.MockService{}, &bytes.Buffer{}, opts)
+		if err == nil {
+			t.Errorf("%+v: expected an error", opts)
+		}
+		if len(fsys.files) != 0 {
+			t.Errorf("%+v: expected nothing written, got %d files", opts, len(fsys.files))
+		}
+	}
+}
+
+func TestInitProject_UpdatesGitignore(t *testing.T) {
+	fsys := &mockInitFS{files: map[string][]byte{"/project/.gitignore": []byte("bin/\n/coverage.out")}}
+	out := &bytes.Buffer{}
+
+	if err := initProject(context.Background(), "/project", fsys, &git.MockService{}, out, initOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "bin/\n/coverage.out\n\n# gatekeeper\n.gatekeeper/results/\n.gatekeeper/audit.log\nreport.sarif\n"
+	if got := string(fsys.files["/project/.gitignore"]); got != want {
+		t.Errorf("unexpected .gitignore:\n%q\nwant:\n%q", got, want)
+	}
+	assertContains(t, out.String(), "Added .gatekeeper/results/, .gatekeeper/audit.log, report.sarif to .gitignore")
+
+	// A second init leaves the file alone.
+	fsys.writtenPath = ""
+	out.Reset()
+	if err := initProject(context.Background(), "/project", fsys, &git.MockService{}, out, initOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fsys.writtenPath != "" || strings.Contains(out.String(), ".gitignore") {
+		t.Errorf("expected no .gitignore change on re-run, wrote %q", fsys.writtenPath)
+	}
+}
+
+func TestAppendGitignore(t *testing.T) {
+	entries := []string{".gatekeeper/results/", "coverage.out"}
+
+	got, added := appendGitignore("", entries)
+	if got != "# gatekeeper\n.gatekeeper/results/\ncoverage.out\n" || len(added) != 2 {
+		t.Errorf("unexpected new .gitignore %q (added %v)", got, added)
+	}
+
+	// An existing gatekeeper header is not repeated.
+	got, added = appendGitignore("# gatekeeper\n.gatekeeper/results\n", entries)
+	if got != "# gatekeeper\n.gatekeeper/results\ncoverage.out\n" || len(added) != 1 {
+		t.Errorf("unexpected updated .gitignore %q (added %v)", got, added)
+	}
+}
+
+func TestInitProject_HookManager(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".pre-commit-config.yaml")
+	original := "repos:\n  - repo: https://github.com/psf/black\n    rev: 24.4.2\n    hooks:\n      - id: black\n"
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// The pre-commit framework owns .git/hooks/pre-commit.
+	gitSvc := &foreignHookService{MockService: git.MockService{HookInstErr: errors.New("pre-commit hook already exists")}}
+	out := &bytes.Buffer{}
+
+	if err := initProject(context.Background(), dir, &osInitFS{}, gitSvc, out, initOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), "Added gatekeeper to .pre-commit-config.yaml (pre-commit)")
+	data, _ := os.ReadFile(configPath)
+	assertContains(t, string(data), "entry: gatekeeper run")
+
+	err := initProject(context.Background(), dir, &osInitFS{}, gitSvc, out, initOptions{HookManager: hookManagerNone})
+	if err == nil || !strings.Contains(err.Error(), "installing hook") {
+		t.Errorf("--hook-manager none must install gatekeeper's own hook, got %v", err)
+	}
+	err = initProject(context.Background(), dir, &osInitFS{}, gitSvc, out, initOptions{HookManager: git.HookManagerLefthook})
+	if err == nil || !strings.Contains(err.Error(), "no lefthook config found") {
+		t.Errorf("expected a missing lefthook config error, got %v", err)
+	}
+
+	out.Reset()
+	if err := teardown(context.Background(), dir, gitSvc, out); err != nil {
+		t.Fatalf("teardown: %v", err)
+	}
+	assertContains(t, out.String(), "Removed gatekeeper from .pre-commit-config.yaml")
+	if data, _ := os.ReadFile(configPath); string(data) != original {
+		t.Errorf("teardown did not restore the config:\n%s", data)
+	}
+	if err := teardown(context.Background(), dir, gitSvc, out); !errors.Is(err, git.ErrForeignHook) {
+		t.Errorf("a foreign hook without a manager entry must still be reported, got %v", err)
+	}
+}
+
+// foreignHookService is a git service whose pre-commit hook belongs to another tool.
+type foreignHookService struct {
+	git.MockService
+}
+
+func (s *foreignHookService) RemoveHook(context.Context) error {
+	return git.ErrForeignHook
+}
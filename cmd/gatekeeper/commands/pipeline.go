@@ -1,25 +1,42 @@
 package commands
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/devcontainer"
 	"github.com/irahardianto/gatekeeper/internal/engine/gate"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/impact"
+	"github.com/irahardianto/gatekeeper/internal/engine/kube"
 	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/local"
+	"github.com/irahardianto/gatekeeper/internal/engine/metrics"
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
 	"github.com/irahardianto/gatekeeper/internal/engine/runner"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
 )
 
 // ErrGatesFailed is returned when one or more gates fail.
 var ErrGatesFailed = errors.New("gates failed")
 
+// traceFlushTimeout bounds how long a run waits to export its spans.
+const traceFlushTimeout = 5 * time.Second
+
 // runPipeline wires real infrastructure and delegates to Pipeline.Execute.
 // This is a composition root — it instantiates production dependencies.
 func runPipeline(ctx context.Context, dryRun bool) error {
@@ -30,63 +47,480 @@ func runPipeline(ctx context.Context, dryRun bool) error {
 		return fmt.Errorf("getting working directory: %w", err)
 	}
 
-	// Create Docker runtime and checker.
-	runtime, err := pool.NewDockerRuntime()
+	// Export spans when a collector is configured. They are flushed here, not
+	// on exit, because "run" exits the process when gates fail.
+	shutdownTracing, err := telemetry.Setup(ctx, os.Getenv(telemetry.EndpointEnv), version)
 	if err != nil {
-		return fmt.Errorf("connecting to Docker: %w", err)
+		return err
 	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), traceFlushTimeout)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Warn("could not export traces", "error", err)
+		}
+	}()
 
-	// Load global config to determine LLM availability.
+	// Load global config to determine the runtime and LLM availability.
 	globalCfg, err := config.LoadGlobalConfig(ctx)
 	if err != nil {
-		return fmt.Errorf("loading global config: %w", err)
+		return &configError{fmt.Errorf("loading global config: %w", err)}
 	}
+	notice := startUpdateNotice(ctx, globalCfg, outputFormat(flagJSON, flagFormat))
+	defer notice.print(os.Stderr)
 
-	// Build gate factory dependencies.
-	p := pool.NewPool(runtime)
-	exec := pool.NewExecutor(runtime)
+	// Create the container runtime and checker.
+	backend, closeTrace, err := newGateBackend(globalCfg, flagRecord, flagReplay)
+	if err != nil {
+		return &systemError{err}
+	}
+	defer func() {
+		if err := closeTrace(); err != nil {
+			log.Warn("could not write runtime trace", "error", err)
+		}
+	}()
+
+	pipeline, err := newPipeline(ctx, projectDir, globalCfg, backend, dryRun, os.Stderr)
+	if err != nil {
+		return err
+	}
+	pipeline.Audit = audit.NewLog(audit.Path(projectDir, globalCfg.AuditLog))
+	pipeline.Metrics = newMetricsPusher(globalCfg, projectDir)
+
+	commitMessage, err := readCommitMessage(flagMessageFile)
+	if err != nil {
+		return err
+	}
+
+	err = pipeline.Execute(ctx, PipelineOpts{
+		DryRun:     dryRun,
+		JSON:       flagJSON,
+		Verbose:    flagVerbose,
+		NoColor:    flagNoColor,
+		FailFast:   failFastFlag(),
+		Skip:       flagSkip,
+		SkipLLM:    flagSkipLLM,
+		Profile:    flagProfile,
+		Full:       flagFull,
+		Accessible: accessibleOutput(globalCfg),
+		GroupBy:    flagGroupBy,
+		Format:     flagFormat,
+		Timings:    flagTimings,
+		Fix:        flagFix,
+		Snapshot:   flagSnapshot,
+
+		CommitMessage: commitMessage,
+		SkipEnv:       os.Getenv(gate.SkipEnv),
+		AllowBranch:   allowBranchSource(flagAllowBranch, os.Getenv(allowBranchEnv)),
+	})
+	if err != nil {
+		log.Error("pipeline failed", "error", err)
+	}
+	return err
+}
 
+// newPipeline assembles a Pipeline for the project in projectDir with real
+// infrastructure. Gate progress is written to progressOut. The audit log and
+// metrics are left to the caller.
+func newPipeline(ctx context.Context, projectDir string, globalCfg *config.GlobalConfig, backend gateBackend, dryRun bool, progressOut io.Writer) (*Pipeline, error) {
+	// Build gate factory dependencies.
 	reg := parser.NewRegistry()
 	reg.Register("sarif", parser.NewSarifParser())
 	reg.Register("go-test-json", parser.NewGoTestParser())
+	reg.Register("mypy-json", parser.NewMypyParser())
+	reg.Register("tsc", parser.NewTscParser())
+	reg.Register("hadolint-json", parser.NewHadolintParser())
+	reg.Register("shellcheck-json", parser.NewShellCheckParser())
+	reg.Register("tflint-json", parser.NewTFLintParser())
+	reg.Register("buf-json", parser.NewBufParser())
+	reg.Register("squawk-json", parser.NewSquawkParser())
+	reg.Register("spectral-json", parser.NewSpectralParser())
 
-	var llmClient llm.Client
-	if !globalCfg.GeminiAPIKey.IsEmpty() {
-		llmClient = llm.NewGeminiClient(string(globalCfg.GeminiAPIKey), "", llm.DefaultClientFactory)
+	gitSvc := git.NewExecService(projectDir)
+	factory := gate.NewFactory(backend.pool, backend.executor, reg, nil, gitSvc, projectDir)
+	factory.SetLLMProviders(newLLMProviders(ctx, globalCfg, dryRun))
+	localCfg := local.Config{Path: globalCfg.Local.Path, Env: globalCfg.Local.Env}
+	factory.SetLocal(local.NewRunner(localCfg), backend.allLocal)
+	localCfg.Nix = true
+	factory.SetNix(local.NewRunner(localCfg))
+	if globalCfg.Local.Disabled {
+		factory.DisableHostExecution()
+	}
+	var (
+		images ImagePuller
+		pruner ContainerPruner
+	)
+	if backend.docker != nil {
+		factory.SetDevcontainer(devcontainer.NewPool(backend.docker, projectDir))
+		// Parallel pulls and background pruning would reorder the calls of a recorded trace.
+		if flagRecord == "" && flagReplay == "" {
+			out := progressOut
+			if flagJSON {
+				out = io.Discard
+			}
+			images = &imagePuller{pool: backend.docker, projectDir: projectDir, out: out, tty: isTerminal(out) && !accessibleOutput(globalCfg)}
+			if dir := poolCacheDir(); dir != "" && globalCfg.ContainerTTL > 0 {
+				pruner = &stalePruner{pool: backend.docker, ttl: globalCfg.ContainerTTL, lockPath: filepath.Join(dir, "prune.lock")}
+			}
+		}
 	}
 
-	gitSvc := git.NewExecService(projectDir)
-	factory := gate.NewFactory(p, exec, reg, llmClient, gitSvc, projectDir)
+	dismissals, err := llm.LoadDismissals(llm.DismissalsPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+	factory.SetDismissals(dismissals)
+
+	suppressions, err := triage.Load(triage.DefaultPath(projectDir))
+	if err != nil {
+		return nil, err
+	}
+
+	resultStore := results.NewStore(results.DefaultDir(projectDir))
+	resultStore.SetRetention(results.Retention{MaxRuns: globalCfg.History.MaxRuns, MaxAge: globalCfg.History.MaxAge})
+	factory.SetCoverageBaselines(resultStore)
 
 	// Build a progress-aware runner.
-	progress := runner.NewProgress(os.Stderr, flagJSON, 0)
+	progress := runner.NewProgress(progressOut, flagJSON, 0)
+	progress.SetAccessible(accessibleOutput(globalCfg))
 	engine := runner.NewEngineWithProgress(progress)
+	engine.Sequential = flagSequential
 
 	// Assemble the pipeline with real infrastructure.
-	pipeline := &Pipeline{
-		Git:          gitSvc,
-		Docker:       &dockerCheckerAdapter{runtime: runtime},
-		Gates:        factory,
-		Runner:       engine,
-		LoadConfig:   config.Load,
-		GlobalConfig: globalCfg,
-		ConfigPath:   filepath.Join(projectDir, ".gatekeeper", "gates.yaml"),
-		Stdout:       os.Stdout,
-		Stderr:       os.Stderr,
+	return &Pipeline{
+		Git:               gitSvc,
+		Docker:            backend.checker,
+		Gates:             factory,
+		Images:            images,
+		Pruner:            pruner,
+		Runner:            engine,
+		Impact:            impact.NewAnalyzer(projectDir),
+		Suppressions:      suppressions,
+		Results:           resultStore,
+		History:           resultStore,
+		Latency:           resultStore,
+		Snapshots:         resultStore,
+		LoadConfig:        branchConfigLoader(gitSvc),
+		LoadNestedConfigs: nestedConfigLoader(gitSvc, projectDir),
+		GlobalConfig:      globalCfg,
+		ConfigPath:        filepath.Join(projectDir, ".gatekeeper", "gates.yaml"),
+		Stdout:            os.Stdout,
+		Stderr:            os.Stderr,
+	}, nil
+}
+
+// projectAuditLog returns the audit log of projectDir at the path configured
+// in the user config. If the user config cannot be read, the default path is used.
+func projectAuditLog(ctx context.Context, projectDir string) *audit.Log {
+	configured := ""
+	globalCfg, err := config.LoadGlobalConfig(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Warn("could not load global config, using the default audit log", "error", err)
+	} else {
+		configured = globalCfg.AuditLog
 	}
+	return audit.NewLog(audit.Path(projectDir, configured))
+}
 
-	err = pipeline.Execute(ctx, PipelineOpts{
-		DryRun:   dryRun,
-		JSON:     flagJSON,
-		Verbose:  flagVerbose,
-		NoColor:  flagNoColor,
-		FailFast: flagFailFast,
-		Skip:     flagSkip,
-		SkipLLM:  flagSkipLLM,
-	})
+// recordAudit appends entry to the project's audit log, logging failures.
+// Audit logging never fails the command it records.
+func recordAudit(ctx context.Context, projectDir string, entry audit.Entry) {
+	if err := projectAuditLog(ctx, projectDir).Record(entry); err != nil {
+		logger.FromContext(ctx).Error("failed to write audit log", "error", err)
+	}
+}
+
+// readCommitMessage returns the content of the commit message file given with
+// --message-file, or "" if none was given.
+func readCommitMessage(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(filepath.Clean(path)) // #nosec G304 -- message file is passed by the user or a git hook
 	if err != nil {
-		log.Error("pipeline failed", "error", err)
+		return "", fmt.Errorf("reading commit message: %w", err)
+	}
+	return string(data), nil
+}
+
+// gateBackend is where container gates run: the pool and executor of the gate
+// factory, and the preflight check of the pipeline.
+type gateBackend struct {
+	pool     gate.PoolManager
+	executor gate.CommandExecutor
+	checker  DockerChecker
+	// allLocal runs every exec and script gate on the host (--no-docker).
+	allLocal bool
+	// docker is the container pool of the docker runtime, which also starts dev containers.
+	docker *pool.Pool
+}
+
+// newGateBackend returns the backend of the runtime configured in the user
+// config. Docker runtimes honor the record and replay traces of
+// containerRuntime; the returned function closes the trace file.
+func newGateBackend(globalCfg *config.GlobalConfig, recordPath, replayPath string) (gateBackend, func() error, error) {
+	if flagNoDocker {
+		if recordPath != "" || replayPath != "" {
+			return gateBackend{}, nil, errors.New("--record and --replay cannot be used with --no-docker")
+		}
+		return gateBackend{checker: noContainerChecker{}, allLocal: true}, func() error { return nil }, nil
+	}
+	switch globalCfg.Runtime {
+	case "", config.RuntimeDocker:
+		runtime, closeTrace, err := containerRuntime(recordPath, replayPath)
+		if err != nil {
+			return gateBackend{}, nil, err
+		}
+		return dockerBackend(globalCfg, runtime), closeTrace, nil
+	case config.RuntimeKubernetes:
+		if recordPath != "" || replayPath != "" {
+			return gateBackend{}, nil, errors.New("--record and --replay need the docker runtime")
+		}
+		k := globalCfg.Kubernetes
+		backend := kube.NewBackend(kube.Config{
+			Kubeconfig:     k.Kubeconfig,
+			Context:        k.Context,
+			Namespace:      k.Namespace,
+			ServiceAccount: k.ServiceAccount,
+			InitImage:      k.InitImage,
+			CPU:            k.CPU,
+			Memory:         k.Memory,
+		})
+		return gateBackend{pool: backend, executor: backend, checker: &kubeCheckerAdapter{backend: backend}}, func() error { return nil }, nil
+	default:
+		return gateBackend{}, nil, fmt.Errorf("unknown runtime %q", globalCfg.Runtime)
+	}
+}
+
+// dockerBackend runs container gates in warm containers of runtime.
+func dockerBackend(globalCfg *config.GlobalConfig, runtime pool.ContainerRuntime) gateBackend {
+	p := newDockerPool(runtime)
+	if globalCfg.DockerDrivePrefix != "" {
+		p.SetDrivePrefix(globalCfg.DockerDrivePrefix)
+	}
+	return gateBackend{pool: p, executor: pool.NewExecutor(runtime), checker: &dockerCheckerAdapter{runtime: runtime}, docker: p}
+}
+
+// newDockerPool creates the container pool of runtime, recording container use
+// in the user cache directory.
+func newDockerPool(runtime pool.ContainerRuntime) *pool.Pool {
+	p := pool.NewPool(runtime)
+	if dir := poolCacheDir(); dir != "" {
+		p.SetUsageDir(filepath.Join(dir, "containers"))
+	}
+	return p
+}
+
+// poolCacheDir returns the user-level directory of container pool state
+// (~/.cache/gatekeeper on Linux), or "" if there is none.
+func poolCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "gatekeeper")
+}
+
+// pruneLockStale is how long a prune lock is honored before it is assumed
+// to be left by a crashed run.
+const pruneLockStale = 10 * time.Minute
+
+// stalePruner removes containers idle for longer than ttl. A lock file keeps
+// concurrent runs from pruning at the same time; a run that finds the lock
+// taken skips pruning.
+type stalePruner struct {
+	pool     *pool.Pool
+	ttl      time.Duration
+	lockPath string
+}
+
+func (s *stalePruner) PruneStale(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.lockPath), 0o750); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	release, ok, err := fileutil.TryLock(s.lockPath, pruneLockStale)
+	if err != nil || !ok {
+		return err
+	}
+	defer release()
+	removed, err := s.pool.CleanupStale(ctx, s.ttl)
+	if removed > 0 {
+		logger.FromContext(ctx).Info("pruned stale containers", "removed", removed)
+	}
+	return err
+}
+
+// containerRuntime connects to Docker. With a record path, every Docker call is
+// also written to that JSONL trace; with a replay path, responses are served
+// from a recorded trace instead of the daemon. The returned function closes the
+// trace file.
+func containerRuntime(recordPath, replayPath string) (pool.ContainerRuntime, func() error, error) {
+	noop := func() error { return nil }
+
+	if replayPath != "" {
+		if recordPath != "" {
+			return nil, nil, errors.New("--record and --replay cannot be used together")
+		}
+		f, err := os.Open(filepath.Clean(replayPath)) // #nosec G304 -- trace path is passed by the user on the command line
+		if err != nil {
+			return nil, nil, fmt.Errorf("opening replay trace: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		replay, err := pool.NewReplayRuntime(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading replay trace %s: %w", replayPath, err)
+		}
+		return replay, noop, nil
+	}
+
+	docker, err := pool.NewDockerRuntime()
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to Docker: %w", err)
+	}
+	if recordPath == "" {
+		return docker, noop, nil
+	}
+
+	f, err := os.Create(filepath.Clean(recordPath)) // #nosec G304 -- trace path is passed by the user on the command line
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating runtime trace: %w", err)
 	}
+	rec := pool.NewRecordingRuntime(docker, f)
+	return rec, func() error {
+		closeErr := f.Close()
+		if err := rec.Err(); err != nil {
+			return err
+		}
+		return closeErr
+	}, nil
+}
+
+// newMetricsPusher returns a Pushgateway pusher if metrics.pushgateway_url is
+// configured, or nil. Series are grouped by project directory name.
+func newMetricsPusher(globalCfg *config.GlobalConfig, projectDir string) MetricsPusher {
+	if globalCfg.Metrics.PushgatewayURL == "" {
+		return nil
+	}
+	return &metrics.Pushgateway{
+		URL:     globalCfg.Metrics.PushgatewayURL,
+		Job:     globalCfg.Metrics.Job,
+		Project: filepath.Base(projectDir),
+	}
+}
+
+// accessibleOutput reports whether accessibility mode is on, by --accessible or
+// output.accessibility in the user config (which may be nil).
+func accessibleOutput(globalCfg *config.GlobalConfig) bool {
+	return flagAccessible || (globalCfg != nil && globalCfg.OutputAccessible)
+}
+
+// branchConfigLoader returns a config loader that applies the overrides for the
+// current branch. If the branch cannot be determined, no overrides are applied.
+func branchConfigLoader(gitSvc git.Service) func(ctx context.Context, path string) (*config.GatekeeperConfig, error) {
+	return func(ctx context.Context, path string) (*config.GatekeeperConfig, error) {
+		branch, err := gitSvc.CurrentBranch(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Warn("could not determine current branch, skipping branch overrides", "error", err)
+			branch = ""
+		}
+		return config.LoadForBranch(ctx, path, branch)
+	}
+}
+
+// nestedConfigLoader returns a loader for the nested configs of the staged
+// files' directories, applying the overrides for the current branch.
+func nestedConfigLoader(gitSvc git.Service, projectDir string) func(ctx context.Context, stagedFiles []string) ([]config.Gate, error) {
+	return func(ctx context.Context, stagedFiles []string) ([]config.Gate, error) {
+		branch, err := gitSvc.CurrentBranch(ctx)
+		if err != nil {
+			// Already reported when the project config was loaded.
+			branch = ""
+		}
+		return config.LoadNested(ctx, projectDir, stagedFiles, branch)
+	}
+}
+
+// containerPool is the pool of the docker runtime.
+type containerPool interface {
+	gate.SpecPoolManager
+	PullImages(ctx context.Context, images []string, report func(pool.PullProgress)) error
+	IsWarm(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (bool, error)
+}
+
+// devcontainerPool starts the project's dev container.
+type devcontainerPool interface {
+	gate.SpecPoolManager
+	Spec() (pool.ContainerSpec, error)
+}
+
+// gateContainer is a container gates run in.
+type gateContainer struct {
+	spec         pool.ContainerSpec
+	writable     bool
+	devcontainer bool
+	// ephemeral containers are started by each run and never kept warm.
+	ephemeral bool
+}
+
+// gateContainers returns the distinct containers gates run in. LLM and policy
+// gates and gates that run on the host have none.
+func gateContainers(gates []config.Gate) []gateContainer {
+	var containers []gateContainer
+	for _, g := range gates {
+		if !g.RunsCommand() || g.IsLocal() {
+			continue
+		}
+		c := gateContainer{spec: pool.ContainerSpec{Setup: g.Setup}, writable: g.Writable, ephemeral: g.Reuse == config.ReuseEphemeral}
+		if g.Container == config.ContainerDevcontainer {
+			c.devcontainer = true
+		} else {
+			c.spec.Image = g.Container
+		}
+		if !slices.ContainsFunc(containers, func(o gateContainer) bool {
+			return o.spec.Image == c.spec.Image && o.spec.Setup == c.spec.Setup && o.writable == c.writable && o.devcontainer == c.devcontainer && o.ephemeral == c.ephemeral
+		}) {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
+
+// imagePuller pulls the images of cold gate containers in parallel, with
+// progress bars on out.
+type imagePuller struct {
+	pool       containerPool
+	projectDir string
+	out        io.Writer
+	tty        bool
+}
+
+func (i *imagePuller) PullImages(ctx context.Context, gates []config.Gate, workspace string) error {
+	if workspace == "" {
+		workspace = i.projectDir
+	}
+	// Warm containers need no pull; dev containers pull as they start.
+	var images []string
+	for _, c := range gateContainers(gates) {
+		if c.devcontainer || slices.Contains(images, c.spec.Image) {
+			continue
+		}
+		warm := false
+		if !c.ephemeral {
+			var err error
+			if warm, err = i.pool.IsWarm(ctx, c.spec, workspace, c.writable); err != nil {
+				return err
+			}
+		}
+		if !warm {
+			images = append(images, c.spec.Image)
+		}
+	}
+	if len(images) == 0 {
+		return nil
+	}
+	bars := runner.NewPullBars(i.out, images, i.tty)
+	err := i.pool.PullImages(ctx, images, bars.Update)
+	bars.Finish()
 	return err
 }
 
@@ -99,6 +533,20 @@ func (d *dockerCheckerAdapter) CheckDocker(ctx context.Context) error {
 	return pool.CheckDocker(ctx, d.runtime)
 }
 
+// noContainerChecker is the pre-flight check of --no-docker runs, which need no container runtime.
+type noContainerChecker struct{}
+
+func (noContainerChecker) CheckDocker(context.Context) error { return nil }
+
+// kubeCheckerAdapter wraps kube.Backend to implement DockerChecker.
+type kubeCheckerAdapter struct {
+	backend *kube.Backend
+}
+
+func (k *kubeCheckerAdapter) CheckDocker(ctx context.Context) error {
+	return k.backend.Check(ctx)
+}
+
 // filterSkippedGates removes gates matching --skip names or --skip-llm flag.
 func filterSkippedGates(gates []config.Gate, skipNames []string, skipLLM bool) []config.Gate {
 	if len(skipNames) == 0 && !skipLLM {
@@ -122,3 +570,146 @@ func filterSkippedGates(gates []config.Gate, skipNames []string, skipLLM bool) [
 	}
 	return result
 }
+
+// runsOnlyLocally reports whether gates need no container runtime: some run
+// on the host with container: local, and none in a container.
+func runsOnlyLocally(gates []config.Gate) bool {
+	local := false
+	for _, g := range gates {
+		switch {
+		case !g.RunsCommand():
+		case g.IsLocal():
+			local = true
+		default:
+			return false
+		}
+	}
+	return local
+}
+
+// filterProfile removes gates that do not run in profile.
+func filterProfile(gates []config.Gate, profile string) []config.Gate {
+	if profile == "" {
+		return gates
+	}
+	var result []config.Gate
+	for _, g := range gates {
+		if g.InProfile(profile) {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// newLLMProviders returns the clients of LLM gate providers configured in
+// globalCfg. Each provider's client is built once and shared by the gates of
+// the run, as is the token budget. Dry runs price the prompts LLM gates would
+// send instead of sending them.
+func newLLMProviders(ctx context.Context, globalCfg *config.GlobalConfig, dryRun bool) gate.LLMProviderFunc {
+	var budget *llm.Budget
+	if globalCfg.MaxTokensPerRun > 0 {
+		budget = llm.NewBudget(globalCfg.MaxTokensPerRun)
+	}
+	clients := map[llm.Provider]llm.Client{}
+	return func(spec string) (llm.Client, error) {
+		p, err := llm.ParseProvider(spec)
+		if err != nil {
+			return nil, err
+		}
+		if client, ok := clients[p]; ok {
+			return client, nil
+		}
+		var client llm.Client
+		if dryRun {
+			client = llm.NewEstimatingClient(p.Model)
+		} else if client, err = newProviderClient(ctx, globalCfg, p, budget); err != nil {
+			return nil, err
+		}
+		clients[p] = client
+		return client, nil
+	}
+}
+
+// newLLMClient creates the default Gemini client configured in globalCfg, with
+// the review cache and token budget applied. The API key must be set.
+func newLLMClient(ctx context.Context, globalCfg *config.GlobalConfig) (llm.Client, error) {
+	var budget *llm.Budget
+	if globalCfg.MaxTokensPerRun > 0 {
+		budget = llm.NewBudget(globalCfg.MaxTokensPerRun)
+	}
+	return newProviderClient(ctx, globalCfg, llm.Provider{Name: llm.ProviderGemini, Model: llm.DefaultGeminiModel}, budget)
+}
+
+// newProviderClient creates the client of provider p configured in globalCfg,
+// with the rate limits, circuit breaker, review cache, and, if not nil, budget
+// applied. It returns an error
+// wrapping llm.ErrProviderUnavailable if the provider's API key is not set.
+func newProviderClient(ctx context.Context, globalCfg *config.GlobalConfig, p llm.Provider, budget *llm.Budget) (llm.Client, error) {
+	tc := llmTransportConfig(globalCfg)
+	var client llm.Client
+	switch p.Name {
+	case llm.ProviderGemini:
+		if globalCfg.GeminiAPIKey.IsEmpty() {
+			return nil, fmt.Errorf("%w: %s needs a Gemini API key — set GATEKEEPER_GEMINI_KEY (or GOOGLE_API_KEY / GEMINI_API_KEY) or add to ~/.config/gatekeeper/config.yaml", llm.ErrProviderUnavailable, p.Model)
+		}
+		clientFactory, err := llm.NewClientFactory(tc)
+		if err != nil {
+			return nil, fmt.Errorf("configuring LLM HTTP client: %w", err)
+		}
+		client = llm.NewGeminiClient(string(globalCfg.GeminiAPIKey), p.Model, clientFactory)
+	case llm.ProviderOpenAI:
+		if globalCfg.OpenAIAPIKey.IsEmpty() {
+			return nil, fmt.Errorf("%w: %s needs an OpenAI API key — set GATEKEEPER_OPENAI_KEY (or OPENAI_API_KEY) or add to ~/.config/gatekeeper/config.yaml", llm.ErrProviderUnavailable, p.Model)
+		}
+		httpClient, err := llm.NewHTTPClient(tc)
+		if err != nil {
+			return nil, fmt.Errorf("configuring LLM HTTP client: %w", err)
+		}
+		client = llm.NewOpenAIClient(p.Name, llm.DefaultOpenAIURL, string(globalCfg.OpenAIAPIKey), p.Model, httpClient, tc.Headers)
+	case llm.ProviderOllama:
+		// A local server: the gateway, proxy, and TLS options do not apply.
+		client = llm.NewOpenAIClient(p.Name, cmp.Or(globalCfg.OllamaURL, llm.DefaultOllamaURL)+"/v1", "", p.Model, nil, nil)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", p.Name)
+	}
+	// Inside the cache, so cached reviews are not paced or counted as failures.
+	limits := globalCfg.LLMLimits
+	client = llm.NewLimitedClient(client, llm.NewLimiter(limits.RequestsPerMinute, limits.Concurrency), llm.NewBreaker(p.Name+"/"+p.Model, llm.DefaultBreakerThreshold))
+	if budget != nil {
+		// Inside the cache too, so cached reviews are served once the budget is spent.
+		client = llm.NewBudgetClient(client, budget)
+	}
+	return withLLMCache(ctx, client, p, globalCfg.LLMCacheTTL, flagNoLLMCache), nil
+}
+
+// withLLMCache wraps the client of provider p with the on-disk review cache
+// unless it is disabled by --no-llm-cache or a zero llm_cache_ttl.
+func withLLMCache(ctx context.Context, client llm.Client, p llm.Provider, ttl time.Duration, disabled bool) llm.Client {
+	if disabled || ttl <= 0 {
+		return client
+	}
+	dir, err := llm.DefaultCacheDir()
+	if err != nil {
+		logger.FromContext(ctx).Warn("LLM cache disabled", "error", err)
+		return client
+	}
+	return llm.NewCachingClient(client, llm.NewCache(dir, ttl), p.Name, p.Model)
+}
+
+// llmTransportConfig converts the global LLM endpoint options into an llm.TransportConfig.
+func llmTransportConfig(cfg *config.GlobalConfig) llm.TransportConfig {
+	tc := llm.TransportConfig{
+		BaseURL:        cfg.LLMBaseURL,
+		ProxyURL:       cfg.LLMHTTP.Proxy,
+		CACertFile:     cfg.LLMHTTP.CACert,
+		ClientCertFile: cfg.LLMHTTP.ClientCert,
+		ClientKeyFile:  cfg.LLMHTTP.ClientKey,
+	}
+	if len(cfg.LLMHTTP.Headers) > 0 {
+		tc.Headers = make(map[string]string, len(cfg.LLMHTTP.Headers))
+		for name, value := range cfg.LLMHTTP.Headers {
+			tc.Headers[name] = string(value)
+		}
+	}
+	return tc
+}
@@ -2,10 +2,14 @@ package commands
 
 import (
 	"context"
+	"time"
 
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
 	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/runner"
 )
 
 // DockerChecker abstracts Docker pre-flight checks.
@@ -16,9 +20,72 @@ type DockerChecker interface {
 // GateCreator abstracts the creation of gate instances from configuration.
 type GateCreator interface {
 	CreateAll(gates []config.Gate) ([]gate.Gate, error)
+	// SetWorkspace mounts dir into container gates instead of the project directory.
+	SetWorkspace(dir string)
+	// SetWritableWorkspace mounts dir into writable container gates instead.
+	SetWritableWorkspace(dir string)
+	// HostWritable reports whether a writable gate runs on the host, where it
+	// modifies the working tree.
+	HostWritable() bool
+}
+
+// ImagePuller pulls the images of gates in parallel before they run, rather
+// than one by one as their containers start. workspace is the directory
+// container gates mount, or empty for the project directory.
+type ImagePuller interface {
+	PullImages(ctx context.Context, gates []config.Gate, workspace string) error
+}
+
+// ContainerPruner removes idle warm containers.
+type ContainerPruner interface {
+	PruneStale(ctx context.Context) error
 }
 
 // GateRunner abstracts parallel execution of gates.
 type GateRunner interface {
+	// Use appends middleware applied to every gate result of the next runs.
+	Use(mw ...runner.ResultMiddleware)
 	RunAll(ctx context.Context, gates []gate.Gate, failFast bool, gateNames []string) (*formatter.RunResult, error)
 }
+
+// ImpactAnalyzer narrows gate commands to the code affected by staged changes.
+type ImpactAnalyzer interface {
+	Rewrite(ctx context.Context, gates []config.Gate, stagedFiles []string) ([]config.Gate, error)
+}
+
+// FindingFilter removes triaged findings from a gate result before it is reported.
+type FindingFilter interface {
+	ApplyGate(r *formatter.GateResult)
+}
+
+// ResultRecorder persists the result of a run.
+type ResultRecorder interface {
+	SaveLast(result formatter.RunResult) error
+}
+
+// RunHistory returns up to limit recorded runs, newest first (all of them if limit is 0).
+type RunHistory interface {
+	History(limit int) ([]results.Run, error)
+}
+
+// MetricsPusher exports the timings and outcome of a run, e.g. to a Pushgateway.
+type MetricsPusher interface {
+	Push(ctx context.Context, result formatter.RunResult) error
+}
+
+// LatencyTracker records LLM provider latencies and reports their median.
+type LatencyTracker interface {
+	RecordLatency(provider string, d time.Duration) error
+	MedianLatency(provider string) (time.Duration, bool)
+}
+
+// ConfigSnapshotStore remembers the gate configuration of the last run.
+type ConfigSnapshotStore interface {
+	LoadConfigSnapshot() (*results.ConfigSnapshot, error)
+	SaveConfigSnapshot(snap results.ConfigSnapshot) error
+}
+
+// AuditRecorder appends entries to the audit log.
+type AuditRecorder interface {
+	Record(entry audit.Entry) error
+}
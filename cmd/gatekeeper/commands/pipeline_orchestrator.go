@@ -2,28 +2,68 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
 	"github.com/irahardianto/gatekeeper/internal/engine/gate"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/metrics"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/runner"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PipelineOpts holds per-invocation options for the pipeline.
 type PipelineOpts struct {
-	DryRun   bool
-	JSON     bool
-	Verbose  bool
-	NoColor  bool
-	FailFast bool
+	DryRun  bool
+	JSON    bool
+	Verbose bool
+	NoColor bool
+	// FailFast is the --fail-fast flag, or nil if it was not given; then
+	// gates.yaml's defaults.fail_fast applies, then the user config's fail_fast.
+	FailFast *bool
 	Skip     []string
 	SkipLLM  bool
+	// Profile selects the gates to run (see config.Gate.InProfile). If empty,
+	// the user's default_profile or the project's defaults.profile applies.
+	Profile string
+	// Full disables test impact analysis so affected_only gates run in full.
+	Full bool
+	// Accessible selects accessibility mode for CLI output.
+	Accessible bool
+	// GroupBy groups the findings of CLI output by gate (the default) or file.
+	GroupBy string
+	// Format selects the output: "cli" (the default), "json", or "markdown".
+	// JSON also selects "json".
+	Format string
+	// Timings prints a per-gate duration breakdown after the results.
+	Timings bool
+	// Fix re-stages writable gate modifications instead of reverting them.
+	Fix bool
+	// Snapshot runs container gates on a checkout of the staged index instead
+	// of stashing unstaged changes, as with defaults.isolation: snapshot.
+	Snapshot bool
+	// CommitMessage is scanned for [skip gatekeeper] and [skip gate:<name>] markers.
+	CommitMessage string
+	// SkipEnv is the value of GATEKEEPER_SKIP.
+	SkipEnv string
+	// AllowBranch names what allowed committing on a branch the branch rules
+	// block, "--allow-branch" or GATEKEEPER_ALLOW_BRANCH, for the audit log.
+	// If empty, the rules are enforced.
+	AllowBranch string
 }
 
 // Pipeline orchestrates the full gatekeeper pipeline with injected dependencies.
@@ -38,12 +78,47 @@ type Pipeline struct {
 	// Gates creates gate instances from configuration.
 	Gates GateCreator
 
+	// Images pulls gate images up front. If nil, images are pulled as containers start.
+	Images ImagePuller
+
+	// Pruner removes idle containers in the background of each run. If nil, they are kept.
+	Pruner ContainerPruner
+
 	// Runner executes gates in parallel.
 	Runner GateRunner
 
+	// Impact narrows affected_only gates to changed code. If nil, gates run unchanged.
+	Impact ImpactAnalyzer
+
+	// Suppressions filters findings triaged as false-positive/won't-fix. If nil, nothing is filtered.
+	Suppressions FindingFilter
+
+	// Results records the run for later triage. If nil, results are not persisted.
+	Results ResultRecorder
+
+	// History detects flaky gates from the recorded runs. If nil, input hashes
+	// are not recorded and flaky gates are not detected.
+	History RunHistory
+
+	// Latency feeds the llm_policy deferral rules. If nil, only defer_after applies.
+	Latency LatencyTracker
+
+	// Metrics exports run timings after each run. If nil, nothing is exported.
+	Metrics MetricsPusher
+
+	// Audit records run outcomes, escape-hatch skips, and applied fixes. If nil, nothing is recorded.
+	Audit AuditRecorder
+
+	// Snapshots detects gates.yaml changes since the last run. If nil, no summary is printed.
+	Snapshots ConfigSnapshotStore
+
 	// LoadConfig loads the project-level gates.yaml.
 	LoadConfig func(ctx context.Context, path string) (*config.GatekeeperConfig, error)
 
+	// LoadNestedConfigs loads the gates of nested gates.yaml files in the
+	// directories of the staged files. If nil, only the project config is used.
+	LoadNestedConfigs func(ctx context.Context, stagedFiles []string) ([]config.Gate, error)
+
 	// GlobalConfig holds the pre-loaded global configuration (~/.config/gatekeeper/).
 	GlobalConfig *config.GlobalConfig
 
@@ -61,8 +136,26 @@ type Pipeline struct {
 	stagedFiles []string
 }
 
-// Execute runs the full pipeline orchestration.
+// Execute runs the full pipeline orchestration, traced as one span. Failed
+// gates are recorded on the span but are not a span error.
 func (p *Pipeline) Execute(ctx context.Context, opts PipelineOpts) error {
+	ctx, span := telemetry.Start(ctx, "pipeline.execute", attribute.Bool("gatekeeper.dry_run", opts.DryRun))
+	err := p.execute(ctx, opts)
+	if err != nil {
+		err = runError(err)
+	}
+	if errors.Is(err, ErrGatesFailed) {
+		span.SetAttributes(attribute.Bool("gatekeeper.passed", false))
+		telemetry.End(span, nil)
+		return err
+	}
+	span.SetAttributes(attribute.Bool("gatekeeper.passed", err == nil))
+	telemetry.End(span, err)
+	return err
+}
+
+// execute runs the pipeline steps.
+func (p *Pipeline) execute(ctx context.Context, opts PipelineOpts) error {
 	log := logger.FromContext(ctx)
 	operation := "run"
 	if opts.DryRun {
@@ -70,110 +163,621 @@ func (p *Pipeline) Execute(ctx context.Context, opts PipelineOpts) error {
 	}
 	log.Info("gatekeeper pipeline started", "operation", operation)
 
+	if err := checkOutputOpts(opts); err != nil {
+		return err
+	}
+
 	// 1. Load project configuration.
 	cfg, err := p.LoadConfig(ctx, p.ConfigPath)
 	if err != nil {
-		return err
+		return &configError{err}
+	}
+
+	if len(cfg.AppliedOverrides) > 0 {
+		fmt.Fprintf(p.Stderr, "🔒 Applying overrides for branch pattern(s): %s\n", strings.Join(cfg.AppliedOverrides, ", "))
+	}
+
+	if p.Snapshots != nil {
+		p.reportConfigChanges(ctx, cfg)
 	}
 
 	// 2. Validate global configuration is available.
 	if p.GlobalConfig == nil {
-		return fmt.Errorf("global config not loaded")
+		return &configError{errors.New("global config not loaded")}
 	}
 
-	// 3. Docker pre-flight check (before stash).
-	if err := p.Docker.CheckDocker(ctx); err != nil {
+	// Enforce the branch rules before doing any work.
+	if blocked, err := p.checkBranch(ctx, cfg.Branches, opts); err != nil || blocked {
 		return err
 	}
 
-	// 4. Stash unstaged changes.
-	stashed, err := p.Git.Stash(ctx)
-	if err != nil {
-		return fmt.Errorf("stashing changes: %w", err)
+	profile := selectProfile(opts.Profile, p.GlobalConfig, cfg)
+	if profile != "" {
+		fmt.Fprintf(p.Stderr, "🎚️  Running profile %q\n", profile)
 	}
 
-	// Set up signal handler and defer stash pop.
-	if stashed {
+	// Escape hatches skip gates explicitly; skipping everything needs no Docker.
+	skips := gate.ParseSkips(opts.CommitMessage, opts.SkipEnv)
+
+	// 3. Docker pre-flight check (before stash). Gates that all run on the host need none.
+	if skips.All == "" && !runsOnlyLocally(cfg.Gates) {
+		if err := p.Docker.CheckDocker(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Prune idle containers while the gates run. It never fails the run, but
+	// the run waits for it so the process does not exit mid-removal.
+	if p.Pruner != nil && skips.All == "" && !runsOnlyLocally(cfg.Gates) {
+		pruned := make(chan struct{})
+		go func() {
+			defer close(pruned)
+			if err := p.Pruner.PruneStale(ctx); err != nil {
+				log.Warn("pruning stale containers failed", "error", err)
+			}
+		}()
+		defer func() { <-pruned }()
+	}
+
+	// 4. Isolate the staged changes: snapshot the index, or stash unstaged changes.
+	snapshot := opts.Snapshot || cfg.Defaults.Isolation == config.IsolationSnapshot
+	stashID := ""
+	workspace := ""
+	if snapshot {
+		dir, err := p.Git.SnapshotIndex(ctx)
+		if err != nil {
+			return fmt.Errorf("snapshotting staged index: %w", err)
+		}
+		defer func() {
+			if cleanErr := p.Git.CleanSnapshot(ctx); cleanErr != nil {
+				log.Warn("failed to clean index snapshot", "error", cleanErr)
+			}
+		}()
+		p.Gates.SetWorkspace(dir)
+		workspace = dir
+	} else {
+		p.warnOrphanedStashes(ctx)
+		stashID, err = p.Git.Stash(ctx)
+		if err != nil {
+			return fmt.Errorf("stashing changes: %w", err)
+		}
+	}
+
+	// Set up signal handler and defer stash pop. The stash is popped by its
+	// ID, so that a stash the user pushed meanwhile is left alone.
+	if stashID != "" {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 		go func() {
 			<-sigCh
 			log.Info("signal received, restoring stash")
-			_ = p.Git.StashPop(context.Background())
-			os.Exit(1)
+			if popErr := p.Git.StashPop(context.Background(), stashID); popErr != nil {
+				fmt.Fprintln(p.Stderr, stashRestoreFailed)
+			}
+			os.Exit(ExitCancelled)
 		}()
 
 		defer func() {
 			signal.Stop(sigCh)
-			if popErr := p.Git.StashPop(ctx); popErr != nil {
+			if popErr := p.Git.StashPop(ctx, stashID); popErr != nil {
 				log.Error("failed to restore stash", "error", popErr)
+				fmt.Fprintln(p.Stderr, stashRestoreFailed)
 			}
 		}()
 	}
 
-	// 5. Get staged files for filtering.
+	// 5. Get staged files for filtering. Gates check the files changed in
+	// submodules, per defaults.submodules, but fixes apply to stagedFiles only.
 	stagedFiles := p.stagedFiles
+	gateFiles := stagedFiles
 	if stagedFiles == nil {
 		stagedFiles, err = p.Git.StagedFiles(ctx)
 		if err != nil {
 			return fmt.Errorf("getting staged files: %w", err)
 		}
+		var submoduleFiles []string
+		stagedFiles, submoduleFiles = p.splitSubmodules(ctx, stagedFiles, cfg.Defaults.Submodules)
+		gateFiles = append(slices.Clip(stagedFiles), submoduleFiles...)
+	}
+
+	// Merge in the gates of nested configs, e.g. services/api/.gatekeeper/gates.yaml.
+	configGates := cfg.Gates
+	if p.LoadNestedConfigs != nil && len(gateFiles) > 0 {
+		nested, err := p.LoadNestedConfigs(ctx, gateFiles)
+		if err != nil {
+			return err
+		}
+		configGates = append(slices.Clip(configGates), nested...)
 	}
 
-	// 6. Apply --skip and --skip-llm filters.
-	gates := filterSkippedGates(cfg.Gates, opts.Skip, opts.SkipLLM)
+	// 6. Apply the profile and the --skip and --skip-llm filters.
+	gates := filterSkippedGates(filterProfile(configGates, profile), opts.Skip, opts.SkipLLM)
 
 	// 7. Apply file filters (only/except).
-	gates = gate.FilterGates(gates, stagedFiles)
+	gates = gate.FilterGates(gates, gateFiles)
 
 	if len(gates) == 0 {
 		fmt.Fprintln(p.Stderr, "✅ No gates to run")
 		return nil
 	}
 
+	// Narrow affected_only gates to the code touched by staged changes.
+	if p.Impact != nil && !opts.Full {
+		gates, err = p.Impact.Rewrite(ctx, gates, gateFiles)
+		if err != nil {
+			return fmt.Errorf("analyzing test impact: %w", err)
+		}
+	}
+
+	// Hash what each gate checks, then downgrade the blocking gates that were
+	// recently flaky to warn, per defaults.flaky_downgrade.
+	var flaky *flakiness
+	if p.History != nil && !opts.DryRun {
+		flaky = p.loadFlakiness(ctx, gates)
+	}
+	gates = flaky.downgrade(gates, cfg.Defaults.FlakyDowngrade, p.Stderr)
+
+	// Writable container gates modify a checkout of the staged index rather
+	// than the working tree. With snapshot isolation, the snapshot is already one.
+	hasWritable := slices.ContainsFunc(gates, func(g config.Gate) bool { return g.Writable })
+	overlay := ""
+	if hasWritable && !snapshot {
+		overlay, err = p.Git.WritableOverlay(ctx)
+		if err != nil {
+			return fmt.Errorf("creating writable overlay: %w", err)
+		}
+		defer func() {
+			if cleanErr := p.Git.CleanOverlay(ctx); cleanErr != nil {
+				log.Warn("failed to clean writable overlay", "error", cleanErr)
+			}
+		}()
+		p.Gates.SetWritableWorkspace(overlay)
+	}
+
 	// 8. Create gate instances.
 	gateInstances, err := p.Gates.CreateAll(gates)
 	if err != nil {
 		return err
 	}
 
+	gateInstances = gate.ApplySkips(gateInstances, gates, skips)
+
+	// Pull the images of the gates that run, in parallel. A failed pull is
+	// reported by the gates of that image.
+	if p.Images != nil {
+		running := slices.DeleteFunc(slices.Clone(gates), func(g config.Gate) bool {
+			_, skipped := skips.Source(g.Name)
+			return skipped
+		})
+		if err := p.Images.PullImages(ctx, running, workspace); err != nil {
+			log.Warn("pulling gate images failed", "error", err)
+		}
+	}
+
+	// Defer non-blocking LLM gates according to llm_policy.
+	var median gate.MedianLatencyFunc
+	if p.Latency != nil {
+		median = p.Latency.MedianLatency
+	}
+	gateInstances = gate.ApplyDeferPolicy(gateInstances, gates, cfg.LLMPolicy, median)
+
 	// 9. Build gate names for progress.
 	var gateNames []string
 	for _, g := range gates {
 		gateNames = append(gateNames, g.Name)
 	}
 
-	// 10. Execute gates in parallel.
-	result, err := p.Runner.RunAll(ctx, gateInstances, opts.FailFast, gateNames)
+	// Add the built-in advisory check for staged artifacts.
+	if artifactCheckEnabled(cfg, opts.Skip) {
+		var artifactGate gate.Gate = gate.NewArtifactGate(stagedFiles)
+		if source, ok := skips.Source(gate.ArtifactGateName); ok {
+			artifactGate = gate.NewSkippedGateWithReason(gate.ArtifactGateName, "builtin", "requested by "+source)
+		}
+		gateInstances = append(gateInstances, artifactGate)
+		gateNames = append(gateNames, gate.ArtifactGateName)
+	}
+
+	if !skips.Empty() {
+		p.reportSkips(ctx, skips, gateNames, opts.DryRun)
+	}
+
+	// Writable gates on the host modify the working tree: record it, so that
+	// only their modifications are reverted.
+	var worktree *git.WorktreeState
+	if hasWritable && !snapshot && p.Gates.HostWritable() {
+		worktree, err = p.Git.WorktreeState(ctx)
+		if err != nil {
+			return fmt.Errorf("recording working tree state: %w", err)
+		}
+	}
+
+	// 10. Execute gates in parallel, post-processing each result as it completes.
+	p.Runner.Use(p.resultMiddleware(gates, flaky)...)
+	result, err := p.Runner.RunAll(ctx, gateInstances, failFast(opts.FailFast, cfg.Defaults.FailFast, p.GlobalConfig.FailFast), gateNames)
 	if err != nil {
 		return err
 	}
 
-	// 11. Clean up writable file modifications.
-	for _, g := range gates {
-		if g.Writable {
-			if cleanErr := p.Git.CleanWritableFiles(ctx); cleanErr != nil {
+	if p.Latency != nil {
+		p.recordLLMLatency(ctx, gates, result)
+	}
+
+	result.CommitBudgetMs = cfg.Defaults.CommitBudget.Milliseconds()
+
+	// 11. Apply fixes to staged files from the overlay if requested; the rest
+	// of the overlay is discarded. Writable gates on the host modified the
+	// working tree: re-stage their fixes, then revert their modifications.
+	// A snapshot is discarded as a whole, and the working tree was never touched.
+	if hasWritable && snapshot && applyWritable(gates, opts) {
+		fmt.Fprintln(p.Stderr, "⚠️  Fixes are not applied with snapshot isolation; writable gate changes were discarded")
+	}
+	if hasWritable && !snapshot {
+		if applyWritable(gates, opts) {
+			fixed, fixErr := p.Git.ApplyOverlay(ctx, overlay, stagedFiles)
+			if fixErr != nil {
+				log.Error("failed to apply writable overlay", "error", fixErr)
+			}
+			result.AutoFixed = fixed
+		}
+		if p.Gates.HostWritable() {
+			if applyWritable(gates, opts) {
+				fixed, fixErr := p.Git.RestageWritableFiles(ctx, stagedFiles)
+				if fixErr != nil {
+					log.Error("failed to re-stage writable files", "error", fixErr)
+				}
+				result.AutoFixed = append(result.AutoFixed, fixed...)
+				slices.Sort(result.AutoFixed)
+				result.AutoFixed = slices.Compact(result.AutoFixed)
+			}
+			if cleanErr := p.Git.CleanWritableFiles(ctx, worktree); cleanErr != nil {
 				log.Error("failed to clean writable files", "error", cleanErr)
 			}
-			break
 		}
 	}
 
 	// 12. Format and print results.
-	var fmtr formatter.Formatter
-	if opts.JSON {
-		fmtr = formatter.NewJSONFormatter()
-	} else {
-		fmtr = formatter.NewCLIFormatter(!opts.NoColor, opts.Verbose)
+	fmt.Fprint(p.Stdout, newFormatter(opts).Format(*result))
+	if opts.Timings && !opts.JSON {
+		metrics.WriteTimings(p.Stdout, *result)
+	}
+
+	if p.Results != nil {
+		// Read the tree after re-staging fixes, so it matches the commit. Dry
+		// runs record none, so they cannot be attested.
+		if !opts.DryRun {
+			if tree, treeErr := p.Git.IndexTree(ctx); treeErr != nil {
+				log.Debug("could not read index tree", "error", treeErr)
+			} else {
+				result.Tree = tree
+			}
+		}
+		if saveErr := p.Results.SaveLast(*result); saveErr != nil {
+			log.Error("failed to record run result", "error", saveErr)
+		}
+	}
+	if p.Audit != nil && !opts.DryRun {
+		p.auditRun(ctx, result)
+	}
+	if p.Metrics != nil && !opts.DryRun {
+		if pushErr := p.Metrics.Push(ctx, *result); pushErr != nil {
+			log.Warn("could not push run metrics", "error", pushErr)
+		}
 	}
-	fmt.Fprint(p.Stdout, fmtr.Format(*result))
 
 	// 13. Determine exit code.
 	if opts.DryRun {
 		return nil
 	}
 	if !result.Passed {
-		return ErrGatesFailed
+		return gatesError(result)
 	}
 	return nil
 }
+
+// auditRun records the outcome of a run and any fixes it re-staged.
+func (p *Pipeline) auditRun(ctx context.Context, result *formatter.RunResult) {
+	log := logger.FromContext(ctx)
+	branch, _ := p.Git.CurrentBranch(ctx)
+
+	var failed []string
+	for _, g := range result.Gates {
+		if !g.Skipped && (!g.Passed || g.SystemError != "") {
+			failed = append(failed, g.Name)
+		}
+	}
+	passed := result.Passed
+	entry := audit.Entry{Event: audit.EventRun, Branch: branch, Gates: failed, Passed: &passed, DurationMs: result.DurationMs}
+	if err := p.Audit.Record(entry); err != nil {
+		log.Error("failed to record run in audit log", "error", err)
+	}
+
+	if len(result.AutoFixed) > 0 {
+		entry := audit.Entry{Event: audit.EventFix, Branch: branch, Files: result.AutoFixed}
+		if err := p.Audit.Record(entry); err != nil {
+			log.Error("failed to record fixes in audit log", "error", err)
+		}
+	}
+}
+
+// branchGateName names the branch rules in the results of a blocked run.
+const branchGateName = "branch"
+
+// checkBranch checks the current branch against rules. A blocked commit is
+// reported as a failed "branch" gate and ends the run with ErrGatesFailed,
+// unless it is allowed with --allow-branch or GATEKEEPER_ALLOW_BRANCH, which
+// is recorded in the audit log. Dry runs only warn.
+func (p *Pipeline) checkBranch(ctx context.Context, rules config.BranchRules, opts PipelineOpts) (blocked bool, err error) {
+	if len(rules.BlockCommitOn) == 0 && rules.Naming == "" {
+		return false, nil
+	}
+	branch, err := p.Git.CurrentBranch(ctx)
+	if err != nil {
+		return false, &systemError{fmt.Errorf("reading current branch: %w", err)}
+	}
+	rule, reason := rules.Violation(branch)
+	switch {
+	case rule == "":
+		return false, nil
+	case opts.DryRun:
+		fmt.Fprintf(p.Stderr, "⚠️  %s; a commit would be blocked\n", reason)
+		return false, nil
+	case opts.AllowBranch != "":
+		fmt.Fprintf(p.Stderr, "⚠️  %s; allowed by %s\n", reason, opts.AllowBranch)
+		if p.Audit != nil {
+			entry := audit.Entry{Event: audit.EventOverride, Branch: branch, Source: opts.AllowBranch, Reason: reason}
+			if err := p.Audit.Record(entry); err != nil {
+				logger.FromContext(ctx).Error("failed to record branch override in audit log", "error", err)
+			}
+		}
+		return false, nil
+	}
+
+	hint := "Commit on a feature branch (git switch -c <name>) and open a pull request, or set GATEKEEPER_ALLOW_BRANCH=1 to override."
+	if rule == "branch-naming" {
+		hint = "Rename the branch (git branch -m <name>), or set GATEKEEPER_ALLOW_BRANCH=1 to override."
+	}
+	result := &formatter.RunResult{Gates: []formatter.GateResult{{
+		Name:     branchGateName,
+		Type:     "builtin",
+		Blocking: true,
+		Errors:   []parser.StructuredError{{Severity: "error", Rule: rule, Message: reason, Hint: hint, Tool: branchGateName}},
+	}}}
+	fmt.Fprint(p.Stdout, newFormatter(opts).Format(*result))
+	if p.Audit != nil {
+		p.auditRun(ctx, result)
+	}
+	return true, ErrGatesFailed
+}
+
+// reportSkips warns about the gates skipped through escape hatches and, unless
+// this is a dry run, records them in the audit log, one entry per source.
+func (p *Pipeline) reportSkips(ctx context.Context, skips gate.Skips, gateNames []string, dryRun bool) {
+	skipped := skips.Skipped(gateNames)
+	if len(skipped) == 0 {
+		return
+	}
+
+	bySource := make(map[string][]string)
+	var sources []string
+	for _, name := range skipped {
+		source, _ := skips.Source(name)
+		if _, seen := bySource[source]; !seen {
+			sources = append(sources, source)
+		}
+		bySource[source] = append(bySource[source], name)
+	}
+
+	branch, _ := p.Git.CurrentBranch(ctx)
+	for _, source := range sources {
+		fmt.Fprintf(p.Stderr, "⚠️  Skipping %s (requested by %s)\n", strings.Join(bySource[source], ", "), source)
+		if p.Audit == nil || dryRun {
+			continue
+		}
+		entry := audit.Entry{Event: audit.EventSkip, Branch: branch, Gates: bySource[source], Source: source}
+		if err := p.Audit.Record(entry); err != nil {
+			logger.FromContext(ctx).Error("failed to record skip in audit log", "error", err)
+		}
+	}
+}
+
+// selectProfile returns the gate profile to run: the one selected on the
+// command line, else the user's default_profile, else the project's
+// defaults.profile.
+func selectProfile(flag string, globalCfg *config.GlobalConfig, cfg *config.GatekeeperConfig) string {
+	if flag != "" {
+		return flag
+	}
+	if globalCfg.DefaultProfile != "" {
+		return globalCfg.DefaultProfile
+	}
+	return cfg.Defaults.Profile
+}
+
+// applyWritable reports whether writable gate modifications are re-staged
+// rather than reverted: with --fix, or if any writable gate has
+// writable_policy: apply. Gates share the working tree, so their changes cannot
+// be told apart. Dry runs never modify the index.
+func applyWritable(gates []config.Gate, opts PipelineOpts) bool {
+	if opts.DryRun {
+		return false
+	}
+	if opts.Fix {
+		return true
+	}
+	return slices.ContainsFunc(gates, func(g config.Gate) bool {
+		return g.Writable && g.WritablePolicy == config.WritablePolicyApply
+	})
+}
+
+// resultMiddleware returns the post-processing of each gate result: triaged
+// findings are suppressed, then the gate's on_fail_message, budget and
+// flakiness are attached.
+func (p *Pipeline) resultMiddleware(gates []config.Gate, flaky *flakiness) []runner.ResultMiddleware {
+	var mw []runner.ResultMiddleware
+	if p.Suppressions != nil {
+		mw = append(mw, func(_ context.Context, r *formatter.GateResult) *formatter.GateResult {
+			p.Suppressions.ApplyGate(r)
+			return r
+		})
+	}
+	mw = append(mw, failMessages(gates), budgets(gates))
+	if flaky != nil {
+		mw = append(mw, flaky.mark)
+	}
+	return mw
+}
+
+// failMessages returns middleware that copies each gate's on_fail_message to
+// its result if the gate failed.
+func failMessages(gates []config.Gate) runner.ResultMiddleware {
+	messages := make(map[string]string)
+	for _, g := range gates {
+		if g.OnFailMessage != "" {
+			messages[g.Name] = g.OnFailMessage
+		}
+	}
+
+	return func(_ context.Context, r *formatter.GateResult) *formatter.GateResult {
+		if !r.Skipped && !r.Deferred && (!r.Passed || r.SystemError != "") {
+			r.OnFailMessage = messages[r.Name]
+		}
+		return r
+	}
+}
+
+// budgets returns middleware that copies each gate's budget to its result, so
+// the slow gates of the run are highlighted.
+func budgets(gates []config.Gate) runner.ResultMiddleware {
+	limits := make(map[string]time.Duration)
+	for _, g := range gates {
+		if g.Budget > 0 {
+			limits[g.Name] = g.Budget
+		}
+	}
+
+	return func(_ context.Context, r *formatter.GateResult) *formatter.GateResult {
+		r.BudgetMs = limits[r.Name].Milliseconds()
+		return r
+	}
+}
+
+// recordLLMLatency records the duration of every LLM gate that actually ran.
+func (p *Pipeline) recordLLMLatency(ctx context.Context, gates []config.Gate, result *formatter.RunResult) {
+	providers := make(map[string]string)
+	for _, g := range gates {
+		if g.Type == config.GateTypeLLM {
+			providers[g.Name] = g.Provider
+		}
+	}
+
+	for _, r := range result.Gates {
+		provider, ok := providers[r.Name]
+		if !ok || r.Skipped || r.SystemError != "" {
+			continue
+		}
+		if err := p.Latency.RecordLatency(provider, time.Duration(r.DurationMs)*time.Millisecond); err != nil {
+			logger.FromContext(ctx).Error("failed to record LLM latency", "error", err)
+		}
+	}
+}
+
+// reportConfigChanges prints a one-line summary when gates.yaml changed since the
+// last run, then records the current configuration. Errors are logged, not fatal.
+func (p *Pipeline) reportConfigChanges(ctx context.Context, cfg *config.GatekeeperConfig) {
+	log := logger.FromContext(ctx)
+	if cfg.SourceHash == "" {
+		return
+	}
+
+	prev, err := p.Snapshots.LoadConfigSnapshot()
+	if err != nil {
+		log.Error("failed to load config snapshot", "error", err)
+	}
+	if prev != nil && prev.Hash == cfg.SourceHash {
+		return
+	}
+
+	if prev != nil {
+		if summary := config.DiffGates(prev.Gates, cfg.Gates); summary != "" {
+			fmt.Fprintf(p.Stderr, "⚙️  gates.yaml changed since your last run: %s\n", summary)
+		}
+	}
+
+	if err := p.Snapshots.SaveConfigSnapshot(results.ConfigSnapshot{Hash: cfg.SourceHash, Gates: cfg.Gates}); err != nil {
+		log.Error("failed to save config snapshot", "error", err)
+	}
+}
+
+// newFormatter returns the formatter selected by opts.
+func newFormatter(opts PipelineOpts) formatter.Formatter {
+	switch outputFormat(opts.JSON, opts.Format) {
+	case formatJSON:
+		return formatter.NewJSONFormatter()
+	case formatMarkdown:
+		return formatter.NewMarkdownFormatter(opts.Verbose)
+	}
+	cli := formatter.NewCLIFormatter(!opts.NoColor, opts.Verbose)
+	cli.Accessible = opts.Accessible
+	cli.GroupBy = opts.GroupBy
+	return cli
+}
+
+// failFast resolves whether fail-fast is on from the first setting given: the
+// --fail-fast flag, gates.yaml, then the user config. It is off by default.
+func failFast(settings ...*bool) bool {
+	for _, s := range settings {
+		if s != nil {
+			return *s
+		}
+	}
+	return false
+}
+
+// Output formats selected with --format.
+const (
+	formatCLI      = "cli"
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+)
+
+// outputFormat resolves the output format from the --json and --format flags:
+// --json is the same as --format json, and no format is the CLI format.
+func outputFormat(jsonOutput bool, format string) string {
+	if jsonOutput {
+		return formatJSON
+	}
+	if format == "" {
+		return formatCLI
+	}
+	return format
+}
+
+// checkOutputOpts validates the --format and --group-by values.
+func checkOutputOpts(opts PipelineOpts) error {
+	switch opts.Format {
+	case "", formatCLI, formatJSON, formatMarkdown:
+	default:
+		return fmt.Errorf("unknown --format %q (expected cli, json, or markdown)", opts.Format)
+	}
+	switch opts.GroupBy {
+	case "", formatter.GroupByGate, formatter.GroupByFile:
+		return nil
+	default:
+		return fmt.Errorf("unknown --group-by %q (expected gate or file)", opts.GroupBy)
+	}
+}
+
+// artifactCheckEnabled reports whether the built-in artifact check runs: it is on
+// unless disabled in defaults, skipped with --skip artifacts, or shadowed by a
+// configured gate of the same name.
+func artifactCheckEnabled(cfg *config.GatekeeperConfig, skip []string) bool {
+	if !cfg.Defaults.ArtifactCheckEnabled() || slices.Contains(skip, gate.ArtifactGateName) {
+		return false
+	}
+	for _, g := range cfg.Gates {
+		if g.Name == gate.ArtifactGateName {
+			return false
+		}
+	}
+	return true
+}
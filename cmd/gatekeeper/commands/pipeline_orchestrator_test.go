@@ -4,12 +4,18 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
 	"github.com/irahardianto/gatekeeper/internal/engine/gate"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/runner"
 )
 
 // --- Mock implementations ---
@@ -23,32 +29,166 @@ func (m *mockDockerChecker) CheckDocker(_ context.Context) error {
 }
 
 type mockGateCreator struct {
-	gates []gate.Gate
-	err   error
+	gates             []gate.Gate
+	err               error
+	received          []config.Gate
+	workspace         string
+	writableWorkspace string
+	hostWritable      bool
+}
+
+func (m *mockGateCreator) SetWorkspace(dir string) {
+	m.workspace = dir
+}
+
+func (m *mockGateCreator) SetWritableWorkspace(dir string) {
+	m.writableWorkspace = dir
+}
+
+func (m *mockGateCreator) HostWritable() bool {
+	return m.hostWritable
 }
 
-func (m *mockGateCreator) CreateAll(_ []config.Gate) ([]gate.Gate, error) {
+func (m *mockGateCreator) CreateAll(gates []config.Gate) ([]gate.Gate, error) {
+	m.received = gates
 	return m.gates, m.err
 }
 
-type mockGateRunner struct {
-	result *formatter.RunResult
+type mockImpactAnalyzer struct {
+	called bool
+	err    error
+}
+
+func (m *mockImpactAnalyzer) Rewrite(_ context.Context, gates []config.Gate, _ []string) ([]config.Gate, error) {
+	m.called = true
+	out := make([]config.Gate, len(gates))
+	copy(out, gates)
+	for i := range out {
+		out[i].Command = "narrowed"
+	}
+	return out, m.err
+}
+
+type mockFindingFilter struct {
+	called bool
+}
+
+func (m *mockFindingFilter) ApplyGate(r *formatter.GateResult) {
+	m.called = true
+	r.Passed = true
+}
+
+type mockResultRecorder struct {
+	saved *formatter.RunResult
+	err   error
+}
+
+func (m *mockResultRecorder) SaveLast(result formatter.RunResult) error {
+	m.saved = &result
+	return m.err
+}
+
+type mockMetricsPusher struct {
+	pushed *formatter.RunResult
 	err    error
 }
 
-func (m *mockGateRunner) RunAll(_ context.Context, _ []gate.Gate, _ bool, _ []string) (*formatter.RunResult, error) {
+func (m *mockMetricsPusher) Push(_ context.Context, result formatter.RunResult) error {
+	m.pushed = &result
+	return m.err
+}
+
+type mockLatencyTracker struct {
+	recorded map[string]time.Duration
+}
+
+func (m *mockLatencyTracker) RecordLatency(provider string, d time.Duration) error {
+	if m.recorded == nil {
+		m.recorded = make(map[string]time.Duration)
+	}
+	m.recorded[provider] = d
+	return nil
+}
+
+func (m *mockLatencyTracker) MedianLatency(_ string) (time.Duration, bool) {
+	return 0, false
+}
+
+type mockSnapshotStore struct {
+	snap  *results.ConfigSnapshot
+	saved *results.ConfigSnapshot
+}
+
+func (m *mockSnapshotStore) LoadConfigSnapshot() (*results.ConfigSnapshot, error) {
+	return m.snap, nil
+}
+
+func (m *mockSnapshotStore) SaveConfigSnapshot(snap results.ConfigSnapshot) error {
+	m.saved = &snap
+	return nil
+}
+
+type mockGateRunner struct {
+	result     *formatter.RunResult
+	err        error
+	failFast   bool
+	names      []string
+	middleware []runner.ResultMiddleware
+}
+
+func (m *mockGateRunner) Use(mw ...runner.ResultMiddleware) {
+	m.middleware = append(m.middleware, mw...)
+}
+
+// RunAll returns the canned result after applying the middleware to its gates,
+// like the engine.
+func (m *mockGateRunner) RunAll(ctx context.Context, _ []gate.Gate, failFast bool, names []string) (*formatter.RunResult, error) {
+	m.failFast = failFast
+	m.names = names
+	if m.result == nil || len(m.middleware) == 0 {
+		return m.result, m.err
+	}
+	m.result.Passed = true
+	for i := range m.result.Gates {
+		r := &m.result.Gates[i]
+		for _, mw := range m.middleware {
+			*r = *mw(ctx, r)
+		}
+		if r.Blocks() {
+			m.result.Passed = false
+		}
+	}
 	return m.result, m.err
 }
 
 type mockGitService struct {
 	stagedFiles         []string
 	stagedFilesErr      error
-	stashed             bool
+	stashID             string
+	stashes             []git.StashEntry
+	poppedID            string
 	stashErr            error
 	stashPopErr         error
 	cleanWritableErr    error
 	stashPopCalled      bool
 	cleanWritableCalled bool
+	restaged            []string
+	restagePaths        []string
+	restageCalled       bool
+	stashCalled         bool
+	snapshotDir         string
+	snapshotCleaned     bool
+	worktreeRecorded    bool
+	cleanWritableState  *git.WorktreeState
+	submodules          []git.SubmoduleChange
+	submoduleFiles      map[string][]string
+	overlayDir          string
+	overlayCleaned      bool
+	applied             []string
+	applyDir            string
+	applyPaths          []string
+	applyCalled         bool
+	tree                string
 }
 
 func (m *mockGitService) StagedDiff(_ context.Context) ([]git.FileDiff, error) {
@@ -59,23 +199,100 @@ func (m *mockGitService) StagedFiles(_ context.Context) ([]string, error) {
 	return m.stagedFiles, m.stagedFilesErr
 }
 
+func (m *mockGitService) StagedFileContent(_ context.Context, _ string) (string, error) {
+	return "", nil
+}
+
+func (m *mockGitService) CurrentBranch(_ context.Context) (string, error) {
+	return "main", nil
+}
+
+func (m *mockGitService) IndexTree(_ context.Context) (string, error) {
+	return m.tree, nil
+}
+
 func (m *mockGitService) InstallHook(_ context.Context) error { return nil }
 func (m *mockGitService) RemoveHook(_ context.Context) error  { return nil }
+func (m *mockGitService) InstallChainedHook(_ context.Context) (bool, error) {
+	return false, nil
+}
+func (m *mockGitService) InstallSummaryHook(_ context.Context) error { return nil }
+func (m *mockGitService) RemoveSummaryHook(_ context.Context) error  { return nil }
+func (m *mockGitService) InstallMessageHook(_ context.Context) error { return nil }
+func (m *mockGitService) RemoveMessageHook(_ context.Context) error  { return nil }
 
-func (m *mockGitService) Stash(_ context.Context) (bool, error) {
-	return m.stashed, m.stashErr
+func (m *mockGitService) Stash(_ context.Context) (string, error) {
+	return m.stashID, m.stashErr
 }
 
-func (m *mockGitService) StashPop(_ context.Context) error {
+func (m *mockGitService) StashPop(_ context.Context, id string) error {
 	m.stashPopCalled = true
+	m.poppedID = id
 	return m.stashPopErr
 }
 
-func (m *mockGitService) CleanWritableFiles(_ context.Context) error {
+func (m *mockGitService) Stashes(_ context.Context) ([]git.StashEntry, error) {
+	return m.stashes, nil
+}
+
+func (m *mockGitService) WorktreeState(_ context.Context) (*git.WorktreeState, error) {
+	m.worktreeRecorded = true
+	return &git.WorktreeState{}, nil
+}
+
+func (m *mockGitService) CleanWritableFiles(_ context.Context, before *git.WorktreeState) error {
+	m.cleanWritableState = before
 	m.cleanWritableCalled = true
 	return m.cleanWritableErr
 }
 
+func (m *mockGitService) RestageWritableFiles(_ context.Context, paths []string) ([]string, error) {
+	m.restageCalled = true
+	m.restagePaths = paths
+	return m.restaged, nil
+}
+
+func (m *mockGitService) SnapshotIndex(_ context.Context) (string, error) {
+	return m.snapshotDir, nil
+}
+
+func (m *mockGitService) CleanSnapshot(_ context.Context) error {
+	m.snapshotCleaned = true
+	return nil
+}
+
+func (m *mockGitService) StagedSubmodules(_ context.Context) ([]git.SubmoduleChange, error) {
+	return m.submodules, nil
+}
+
+func (m *mockGitService) SubmoduleFiles(_ context.Context, c git.SubmoduleChange) ([]string, error) {
+	files, ok := m.submoduleFiles[c.Path]
+	if !ok {
+		return nil, errors.New("submodule not checked out")
+	}
+	return files, nil
+}
+
+func (m *mockGitService) LFSObject(_ context.Context, _ git.LFSPointer) (string, error) {
+	return "", git.ErrLFSObjectMissing
+}
+
+func (m *mockGitService) WritableOverlay(_ context.Context) (string, error) {
+	return m.overlayDir, nil
+}
+
+func (m *mockGitService) ApplyOverlay(_ context.Context, dir string, paths []string) ([]string, error) {
+	m.applyCalled = true
+	m.applyDir = dir
+	m.applyPaths = paths
+	return m.applied, nil
+}
+
+func (m *mockGitService) CleanOverlay(_ context.Context) error {
+	m.overlayCleaned = true
+	return nil
+}
+
 // --- stubGate implements gate.Gate ---
 
 type stubGate struct{}
@@ -203,8 +420,91 @@ func TestPipeline_DockerCheckFails(t *testing.T) {
 	}
 }
 
+func TestPipeline_LocalGatesSkipDockerCheck(t *testing.T) {
+	p, _, _ := newTestPipeline(&mockGitService{})
+	p.Docker = &mockDockerChecker{err: errors.New("docker not running")}
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates[0].Container = config.ContainerLocal
+		return cfg, nil
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("expected local gates to run without Docker, got %v", err)
+	}
+}
+
+// mockImagePuller records the gates whose images are pulled.
+type mockImagePuller struct {
+	gates     []string
+	workspace string
+}
+
+func (m *mockImagePuller) PullImages(_ context.Context, gates []config.Gate, workspace string) error {
+	for _, g := range gates {
+		m.gates = append(m.gates, g.Name)
+	}
+	m.workspace = workspace
+	return errors.New("pull failed")
+}
+
+func TestPipeline_PullsImagesOfRunningGates(t *testing.T) {
+	gitSvc := &mockGitService{}
+	gitSvc.snapshotDir = "/repo/.git/gatekeeper/snapshot"
+	p, _, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates = append(cfg.Gates, config.Gate{Name: "test", Type: config.GateTypeExec, Command: "go test ./...", Container: "golang:1.25"})
+		return cfg, nil
+	}
+	images := &mockImagePuller{}
+	p.Images = images
+
+	// A failed pull is left to the gates of the image.
+	if err := p.Execute(context.Background(), PipelineOpts{Snapshot: true, SkipEnv: "test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(images.gates, ",") != "lint" {
+		t.Errorf("pulled images of %v, want only the running gates", images.gates)
+	}
+	if images.workspace != gitSvc.snapshotDir {
+		t.Errorf("workspace = %q, want the snapshot", images.workspace)
+	}
+}
+
+// mockPruner counts background prunes.
+type mockPruner struct {
+	calls int
+}
+
+func (m *mockPruner) PruneStale(_ context.Context) error {
+	m.calls++
+	return errors.New("prune failed")
+}
+
+func TestPipeline_PrunesStaleContainers(t *testing.T) {
+	p, _, _ := newTestPipeline(&mockGitService{})
+	pruner := &mockPruner{}
+	p.Pruner = pruner
+
+	// A failed prune never fails the run.
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pruner.calls != 1 {
+		t.Errorf("pruned %d times, want 1", pruner.calls)
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{SkipEnv: "all"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pruner.calls != 1 {
+		t.Errorf("expected no prune when every gate is skipped, got %d", pruner.calls)
+	}
+}
+
 func TestPipeline_StashAndRestore(t *testing.T) {
-	gitSvc := &mockGitService{stashed: true}
+	gitSvc := &mockGitService{stashID: "abc123"}
 	p, _, _ := newTestPipeline(gitSvc)
 
 	err := p.Execute(context.Background(), PipelineOpts{})
@@ -212,8 +512,31 @@ func TestPipeline_StashAndRestore(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !gitSvc.stashPopCalled {
-		t.Error("expected StashPop to be called after execution")
+	if !gitSvc.stashPopCalled || gitSvc.poppedID != "abc123" {
+		t.Errorf("expected the run's stash to be popped after execution, got %q", gitSvc.poppedID)
+	}
+}
+
+func TestPipeline_StashRestoreFailure(t *testing.T) {
+	gitSvc := &mockGitService{stashID: "abc123", stashPopErr: errors.New("conflict")}
+	p, _, stderr := newTestPipeline(gitSvc)
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stderr.String(), "gatekeeper recover")
+}
+
+func TestPipeline_WarnsAboutOrphanedStashes(t *testing.T) {
+	gitSvc := &mockGitService{stashes: []git.StashEntry{{Ref: "stash@{0}", ID: "def456", Message: "On main: gatekeeper-stash pid 42"}}}
+	p, _, stderr := newTestPipeline(gitSvc)
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stderr.String(), "Found 1 gatekeeper stash left by a run that did not finish")
+	if gitSvc.stashPopCalled {
+		t.Error("expected orphaned stashes to be left for recover")
 	}
 }
 
@@ -231,9 +554,11 @@ func TestPipeline_NoGatesAfterFilter(t *testing.T) {
 	}
 }
 
-func TestPipeline_WritableCleanup(t *testing.T) {
-	gitSvc := &mockGitService{}
+func TestPipeline_WritableOverlay(t *testing.T) {
+	gitSvc := &mockGitService{overlayDir: "/repo/.git/gatekeeper/overlay"}
 	p, _, _ := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
 	// Override config to include a writable gate.
 	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
 		blocking := true
@@ -250,8 +575,148 @@ func TestPipeline_WritableCleanup(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !gitSvc.cleanWritableCalled {
-		t.Error("expected CleanWritableFiles to be called for writable gate")
+	if creator.writableWorkspace != "/repo/.git/gatekeeper/overlay" {
+		t.Errorf("expected writable gates to run in the overlay, got %q", creator.writableWorkspace)
+	}
+	if !gitSvc.overlayCleaned {
+		t.Error("expected the overlay to be cleaned up")
+	}
+	if gitSvc.worktreeRecorded || gitSvc.cleanWritableCalled || gitSvc.applyCalled {
+		t.Error("expected the working tree to be left untouched")
+	}
+}
+
+func TestPipeline_WritableCleanup(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.Gates = &mockGateCreator{gates: []gate.Gate{&stubGate{}}, hostWritable: true}
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		return &config.GatekeeperConfig{
+			Version: 1,
+			Gates: []config.Gate{
+				{Name: "format", Type: config.GateTypeExec, Container: config.ContainerLocal, Command: "gofmt -w .", Writable: true},
+			},
+		}, nil
+	}
+
+	err := p.Execute(context.Background(), PipelineOpts{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !gitSvc.worktreeRecorded {
+		t.Error("expected the working tree to be recorded before the gates run")
+	}
+	if !gitSvc.cleanWritableCalled || gitSvc.cleanWritableState == nil {
+		t.Error("expected CleanWritableFiles to be called with the recorded state for a writable gate on the host")
+	}
+}
+
+func TestPipeline_WritableFix(t *testing.T) {
+	writableConfig := func(policy string) func(context.Context, string) (*config.GatekeeperConfig, error) {
+		return func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+			return &config.GatekeeperConfig{
+				Version: 1,
+				Gates: []config.Gate{
+					{Name: "format", Type: config.GateTypeExec, Container: "golang", Command: "gofmt -w .", Writable: true, WritablePolicy: policy},
+				},
+			}, nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		host   bool
+		policy string
+		opts   PipelineOpts
+		apply  bool
+	}{
+		{name: "default reverts", opts: PipelineOpts{}, apply: false},
+		{name: "--fix", opts: PipelineOpts{Fix: true}, apply: true},
+		{name: "apply policy", policy: config.WritablePolicyApply, apply: true},
+		{name: "revert policy with --fix", policy: config.WritablePolicyRevert, opts: PipelineOpts{Fix: true}, apply: true},
+		{name: "dry run never applies", policy: config.WritablePolicyApply, opts: PipelineOpts{DryRun: true, Fix: true}, apply: false},
+		{name: "--fix on the host", host: true, opts: PipelineOpts{Fix: true}, apply: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitSvc := &mockGitService{overlayDir: "/overlay", applied: []string{"main.go"}, restaged: []string{"main.go"}}
+			p, stdout, _ := newTestPipeline(gitSvc)
+			p.Gates = &mockGateCreator{gates: []gate.Gate{&stubGate{}}, hostWritable: tt.host}
+			p.LoadConfig = writableConfig(tt.policy)
+
+			tt.opts.NoColor = true
+			if err := p.Execute(context.Background(), tt.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gitSvc.applyCalled != tt.apply {
+				t.Errorf("ApplyOverlay called = %v, want %v", gitSvc.applyCalled, tt.apply)
+			}
+			if gitSvc.restageCalled != (tt.apply && tt.host) {
+				t.Errorf("RestageWritableFiles called = %v, want %v", gitSvc.restageCalled, tt.apply && tt.host)
+			}
+			if gitSvc.cleanWritableCalled != tt.host {
+				t.Errorf("CleanWritableFiles called = %v, want %v", gitSvc.cleanWritableCalled, tt.host)
+			}
+			if tt.apply {
+				if gitSvc.applyDir != "/overlay" || len(gitSvc.applyPaths) != 1 || gitSvc.applyPaths[0] != "main.go" {
+					t.Errorf("expected staged files to be applied from the overlay, got %q %v", gitSvc.applyDir, gitSvc.applyPaths)
+				}
+				assertContains(t, stdout.String(), "🔧 Auto-fixed and re-staged 1 file: main.go")
+			}
+		})
+	}
+}
+
+func TestPipeline_SnapshotIsolation(t *testing.T) {
+	gitSvc := &mockGitService{snapshotDir: "/repo/.git/gatekeeper/snapshot", stashID: "abc123"}
+	p, _, stderr := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		return &config.GatekeeperConfig{
+			Version: 1,
+			Gates: []config.Gate{
+				{Name: "format", Type: config.GateTypeExec, Container: "golang", Command: "gofmt -w .", Writable: true},
+			},
+		}, nil
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{Snapshot: true, Fix: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if creator.workspace != "/repo/.git/gatekeeper/snapshot" {
+		t.Errorf("expected gates to run in the snapshot, got workspace %q", creator.workspace)
+	}
+	if gitSvc.stashCalled || gitSvc.stashPopCalled {
+		t.Error("expected no stash with snapshot isolation")
+	}
+	if gitSvc.cleanWritableCalled || gitSvc.restageCalled {
+		t.Error("expected the working tree to be left untouched")
+	}
+	if !gitSvc.snapshotCleaned {
+		t.Error("expected the snapshot to be cleaned up")
+	}
+	assertContains(t, stderr.String(), "Fixes are not applied with snapshot isolation")
+}
+
+func TestPipeline_SnapshotIsolationFromConfig(t *testing.T) {
+	gitSvc := &mockGitService{snapshotDir: "/snap"}
+	p, _, _ := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Defaults.Isolation = config.IsolationSnapshot
+		return cfg, nil
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creator.workspace != "/snap" || gitSvc.stashCalled {
+		t.Errorf("expected defaults.isolation: snapshot to snapshot instead of stash (workspace %q, stashed %v)", creator.workspace, gitSvc.stashCalled)
 	}
 }
 
@@ -323,3 +788,732 @@ func TestPipeline_JSONOutput(t *testing.T) {
 		t.Errorf("expected JSON output, got %q", stdout.String())
 	}
 }
+
+func TestPipeline_ImpactRewrite(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	analyzer := &mockImpactAnalyzer{}
+	p.Gates = creator
+	p.Impact = analyzer
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !analyzer.called {
+		t.Fatal("expected impact analyzer to be called")
+	}
+	if len(creator.received) != 1 || creator.received[0].Command != "narrowed" {
+		t.Errorf("expected rewritten gates to reach the factory, got %+v", creator.received)
+	}
+}
+
+func TestPipeline_NestedConfigs(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
+	p.stagedFiles = []string{"main.go", "services/api/main.go"}
+	var loadedFor []string
+	p.LoadNestedConfigs = func(_ context.Context, stagedFiles []string) ([]config.Gate, error) {
+		loadedFor = stagedFiles
+		return []config.Gate{
+			{Name: "services/api:vet", Type: config.GateTypeExec, Command: "go vet ./...", Dir: "services/api"},
+			{Name: "services/web:lint", Type: config.GateTypeExec, Command: "npm run lint", Dir: "services/web"},
+		}, nil
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(loadedFor) != 2 {
+		t.Errorf("expected nested configs to be discovered from the staged files, got %v", loadedFor)
+	}
+	var names []string
+	for _, g := range creator.received {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, ",") != "lint,services/api:vet" {
+		t.Errorf("expected root gate and the nested gate with staged files, got %v", names)
+	}
+}
+
+func TestPipeline_Submodules(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		files  map[string][]string
+		loaded string
+		want   string
+	}{
+		{name: "skip by default", loaded: "README.md", want: ""},
+		{name: "recurse", mode: config.SubmodulesRecurse, files: map[string][]string{"libs/lib": {"libs/lib/util.go"}}, loaded: "README.md,libs/lib/util.go", want: "go-lint,libs/lib:vet"},
+		{name: "recurse without checkout", mode: config.SubmodulesRecurse, loaded: "README.md", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitSvc := &mockGitService{
+				stagedFiles:    []string{"README.md", "libs/lib"},
+				submodules:     []git.SubmoduleChange{{Path: "libs/lib", Old: "aaa", New: "bbb"}},
+				submoduleFiles: tt.files,
+			}
+			p, _, _ := newTestPipeline(gitSvc)
+			p.stagedFiles = nil
+			creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+			p.Gates = creator
+			p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+				return &config.GatekeeperConfig{
+					Version:  1,
+					Defaults: config.Defaults{Submodules: tt.mode},
+					Gates:    []config.Gate{{Name: "go-lint", Type: config.GateTypeExec, Container: "golang", Command: "go vet ./...", Only: []string{"*.go"}}},
+				}, nil
+			}
+			var loadedFor []string
+			p.LoadNestedConfigs = func(_ context.Context, files []string) ([]config.Gate, error) {
+				loadedFor = files
+				if slices.Contains(files, "libs/lib/util.go") {
+					return []config.Gate{{Name: "libs/lib:vet", Type: config.GateTypeExec, Command: "go vet ./...", Dir: "libs/lib"}}, nil
+				}
+				return nil, nil
+			}
+
+			if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(loadedFor, ",") != tt.loaded {
+				t.Errorf("expected nested configs loaded for %q, got %v", tt.loaded, loadedFor)
+			}
+			var names []string
+			for _, g := range creator.received {
+				names = append(names, g.Name)
+			}
+			if strings.Join(names, ",") != tt.want {
+				t.Errorf("expected gates %q, got %v", tt.want, names)
+			}
+		})
+	}
+}
+
+func TestPipeline_NestedConfigError(t *testing.T) {
+	p, _, _ := newTestPipeline(&mockGitService{})
+	p.LoadNestedConfigs = func(_ context.Context, _ []string) ([]config.Gate, error) {
+		return nil, errors.New("loading svc/.gatekeeper/gates.yaml: bad")
+	}
+
+	err := p.Execute(context.Background(), PipelineOpts{})
+	if err == nil || !strings.Contains(err.Error(), "svc/.gatekeeper/gates.yaml") {
+		t.Errorf("expected nested config error, got %v", err)
+	}
+}
+
+func TestPipeline_ImpactSkippedWithFull(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	analyzer := &mockImpactAnalyzer{}
+	p.Impact = analyzer
+
+	if err := p.Execute(context.Background(), PipelineOpts{Full: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if analyzer.called {
+		t.Error("expected impact analysis to be skipped with --full")
+	}
+}
+
+func TestPipeline_ImpactError(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.Impact = &mockImpactAnalyzer{err: errors.New("scan failed")}
+
+	err := p.Execute(context.Background(), PipelineOpts{})
+	if err == nil || err.Error() != "analyzing test impact: scan failed" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestPipeline_SuppressionsFlipVerdict(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.Runner = &mockGateRunner{result: failingRunResult()}
+	filter := &mockFindingFilter{}
+	p.Suppressions = filter
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("expected suppressed failures not to block, got %v", err)
+	}
+	if !filter.called {
+		t.Error("expected finding filter to be applied")
+	}
+}
+
+func TestPipeline_OnFailMessage(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, stdout, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates[0].OnFailMessage = "Run make fix-lint\nSee docs/standards.md"
+		return cfg, nil
+	}
+
+	p.Runner = &mockGateRunner{result: passingRunResult()}
+	if err := p.Execute(context.Background(), PipelineOpts{NoColor: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(stdout.String(), "make fix-lint") {
+		t.Error("expected no guidance for a passing gate")
+	}
+
+	stdout.Reset()
+	p.Runner = &mockGateRunner{result: failingRunResult()}
+	if err := p.Execute(context.Background(), PipelineOpts{NoColor: true}); !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed, got %v", err)
+	}
+	assertContains(t, stdout.String(), "👉 Run make fix-lint\n       See docs/standards.md")
+}
+
+func TestPipeline_Budgets(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, stdout, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates[0].Budget = 50 * time.Millisecond
+		cfg.Defaults.CommitBudget = 80 * time.Millisecond
+		return cfg, nil
+	}
+	result := passingRunResult()
+	result.Gates[0].DurationMs = 60
+	p.Runner = &mockGateRunner{result: result}
+
+	if err := p.Execute(context.Background(), PipelineOpts{NoColor: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stdout.String(), "🐢 over its 50ms budget")
+	assertContains(t, stdout.String(), "🐢 The run took 100ms, over the 80ms commit budget. Consider moving slow gates (lint)")
+}
+
+func TestPipeline_RecordsResult(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.Runner = &mockGateRunner{result: failingRunResult()}
+	recorder := &mockResultRecorder{err: errors.New("disk full")}
+	p.Results = recorder
+
+	err := p.Execute(context.Background(), PipelineOpts{})
+	if !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed (recording errors are not fatal), got %v", err)
+	}
+	if recorder.saved == nil || recorder.saved.Passed {
+		t.Errorf("expected failing result to be recorded, got %+v", recorder.saved)
+	}
+}
+
+func TestPipeline_Timings(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, stdout, _ := newTestPipeline(gitSvc)
+
+	if err := p.Execute(context.Background(), PipelineOpts{NoColor: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(stdout.String(), "Timings") {
+		t.Error("expected no timings table without --timings")
+	}
+
+	stdout.Reset()
+	if err := p.Execute(context.Background(), PipelineOpts{NoColor: true, Timings: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stdout.String(), "⏱️  Timings")
+
+	stdout.Reset()
+	if err := p.Execute(context.Background(), PipelineOpts{JSON: true, Timings: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(stdout.String(), "Timings") {
+		t.Error("expected no timings table in JSON output")
+	}
+}
+
+func TestPipeline_PushesMetrics(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.Runner = &mockGateRunner{result: failingRunResult()}
+	pusher := &mockMetricsPusher{err: errors.New("gateway down")}
+	p.Metrics = pusher
+
+	err := p.Execute(context.Background(), PipelineOpts{})
+	if !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed (push errors are not fatal), got %v", err)
+	}
+	if pusher.pushed == nil || pusher.pushed.Passed {
+		t.Errorf("expected failing result to be pushed, got %+v", pusher.pushed)
+	}
+
+	pusher.pushed = nil
+	if err := p.Execute(context.Background(), PipelineOpts{DryRun: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pusher.pushed != nil {
+		t.Error("expected dry runs not to push metrics")
+	}
+}
+
+func TestPipeline_BranchOverrides(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, stderr := newTestPipeline(gitSvc)
+	runner := &mockGateRunner{result: passingRunResult()}
+	p.Runner = runner
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		enabled := true
+		cfg.Defaults.FailFast = &enabled
+		cfg.AppliedOverrides = []string{"main"}
+		return cfg, nil
+	}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !runner.failFast {
+		t.Error("expected defaults.fail_fast to enable fail-fast")
+	}
+	assertContains(t, stderr.String(), "overrides for branch pattern(s): main")
+}
+
+func TestPipeline_FailFastPrecedence(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name                  string
+		flag, project, global *bool
+		want                  bool
+	}{
+		{"default", nil, nil, nil, false},
+		{"user config", nil, nil, &on, true},
+		{"gates.yaml over user config", nil, &off, &on, false},
+		{"gates.yaml", nil, &on, nil, true},
+		{"flag over gates.yaml", &off, &on, &on, false},
+		{"flag", &on, nil, &off, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _, _ := newTestPipeline(&mockGitService{})
+			runner := &mockGateRunner{result: passingRunResult()}
+			p.Runner = runner
+			p.GlobalConfig.FailFast = tt.global
+			p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+				cfg := defaultConfig()
+				cfg.Defaults.FailFast = tt.project
+				return cfg, nil
+			}
+
+			if err := p.Execute(context.Background(), PipelineOpts{FailFast: tt.flag}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if runner.failFast != tt.want {
+				t.Errorf("fail-fast = %v, want %v", runner.failFast, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipeline_RecordsLLMLatency(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates = append(cfg.Gates,
+			config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "review"},
+			config.Gate{Name: "deferred", Type: config.GateTypeLLM, Provider: "slow", Prompt: "review"},
+		)
+		return cfg, nil
+	}
+	p.Runner = &mockGateRunner{result: &formatter.RunResult{
+		Passed: true,
+		Gates: []formatter.GateResult{
+			{Name: "lint", Passed: true, DurationMs: 10},
+			{Name: "review", Type: "llm", Passed: true, DurationMs: 1500},
+			{Name: "deferred", Type: "llm", Passed: true, Skipped: true, Deferred: true},
+		},
+	}}
+	tracker := &mockLatencyTracker{}
+	p.Latency = tracker
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tracker.recorded) != 1 || tracker.recorded["gemini"] != 1500*time.Millisecond {
+		t.Errorf("expected only the executed LLM gate to be recorded, got %v", tracker.recorded)
+	}
+}
+
+func TestPipeline_ReportsConfigChanges(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, stderr := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.SourceHash = "new"
+		cfg.Gates = append(cfg.Gates, config.Gate{Name: "trivy", Type: config.GateTypeExec, Command: "trivy fs ."})
+		return cfg, nil
+	}
+	snapshots := &mockSnapshotStore{snap: &results.ConfigSnapshot{Hash: "old", Gates: defaultConfig().Gates}}
+	p.Snapshots = snapshots
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, stderr.String(), "gates.yaml changed since your last run: 1 gate added: trivy")
+	if snapshots.saved == nil || snapshots.saved.Hash != "new" {
+		t.Errorf("expected new snapshot to be saved, got %+v", snapshots.saved)
+	}
+}
+
+func TestPipeline_NoConfigChangeSummaryOnFirstRun(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, stderr := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.SourceHash = "first"
+		return cfg, nil
+	}
+	snapshots := &mockSnapshotStore{}
+	p.Snapshots = snapshots
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(stderr.String(), "changed since") {
+		t.Errorf("expected no summary on first run, got %q", stderr.String())
+	}
+	if snapshots.saved == nil {
+		t.Error("expected snapshot to be recorded on first run")
+	}
+}
+
+func TestPipeline_ArtifactCheck(t *testing.T) {
+	disabled := false
+	tests := []struct {
+		name string
+		cfg  func(*config.GatekeeperConfig)
+		skip []string
+		want bool
+	}{
+		{name: "on by default", want: true},
+		{name: "skipped by flag", skip: []string{gate.ArtifactGateName}},
+		{name: "disabled in defaults", cfg: func(c *config.GatekeeperConfig) { c.Defaults.ArtifactCheck = &disabled }},
+		{name: "shadowed by a configured gate", cfg: func(c *config.GatekeeperConfig) {
+			c.Gates = append(c.Gates, config.Gate{Name: gate.ArtifactGateName, Type: config.GateTypeExec, Command: "true"})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _, _ := newTestPipeline(&mockGitService{})
+			cfg := defaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) { return cfg, nil }
+			r := &mockGateRunner{result: passingRunResult()}
+			p.Runner = r
+
+			if err := p.Execute(context.Background(), PipelineOpts{Skip: tt.skip}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			count := 0
+			for _, n := range r.names {
+				if n == gate.ArtifactGateName {
+					count++
+				}
+			}
+			want := 0
+			for _, g := range cfg.Gates {
+				if g.Name == gate.ArtifactGateName {
+					want++
+				}
+			}
+			if tt.want {
+				want++
+			}
+			if count != want {
+				t.Errorf("expected %d %q gate(s) in %v", want, gate.ArtifactGateName, r.names)
+			}
+		})
+	}
+}
+
+func TestPipeline_Profiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		flag          string
+		globalProfile string
+		configProfile string
+		want          string
+	}{
+		{name: "no profile runs everything", want: "lint,test,review"},
+		{name: "flag", flag: "fast", want: "lint"},
+		{name: "project default", configProfile: "full", want: "lint,test,review"},
+		{name: "user default wins over project", globalProfile: "fast", configProfile: "full", want: "lint"},
+		{name: "flag wins over defaults", flag: "full", globalProfile: "fast", want: "lint,test,review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _, stderr := newTestPipeline(&mockGitService{})
+			creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+			p.Gates = creator
+			p.GlobalConfig = &config.GlobalConfig{DefaultProfile: tt.globalProfile}
+			p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+				cfg := defaultConfig()
+				cfg.Defaults.Profile = tt.configProfile
+				cfg.Gates = append(cfg.Gates,
+					config.Gate{Name: "test", Type: config.GateTypeExec, Command: "go test ./...", Profiles: []string{"full"}},
+					config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "review", Profiles: []string{"full"}},
+				)
+				return cfg, nil
+			}
+
+			if err := p.Execute(context.Background(), PipelineOpts{Profile: tt.flag}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var names []string
+			for _, g := range creator.received {
+				names = append(names, g.Name)
+			}
+			if strings.Join(names, ",") != tt.want {
+				t.Errorf("gates = %v, want %s", names, tt.want)
+			}
+			if tt.want == "lint" && !strings.Contains(stderr.String(), `profile "fast"`) {
+				t.Errorf("expected the selected profile to be reported, got %q", stderr.String())
+			}
+		})
+	}
+}
+
+type mockAuditRecorder struct {
+	entries []audit.Entry
+}
+
+func (m *mockAuditRecorder) Record(entry audit.Entry) error {
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func TestPipeline_EscapeHatchSkips(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, _, stderr := newTestPipeline(gitSvc)
+	runner := &mockGateRunner{result: passingRunResult()}
+	p.Runner = runner
+	p.Docker = &mockDockerChecker{err: errors.New("docker down")}
+	rec := &mockAuditRecorder{}
+	p.Audit = rec
+
+	err := p.Execute(context.Background(), PipelineOpts{CommitMessage: "fix: outage\n\n[skip gatekeeper]"})
+	if err != nil {
+		t.Fatalf("skipping every gate must not need Docker, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Skipping lint, artifacts (requested by commit message [skip gatekeeper])") {
+		t.Errorf("expected skip warning, got %q", stderr.String())
+	}
+	if len(rec.entries) != 2 || rec.entries[1].Event != audit.EventRun {
+		t.Fatalf("expected a skip and a run audit entry, got %+v", rec.entries)
+	}
+	e := rec.entries[0]
+	if e.Event != audit.EventSkip || e.Branch != "main" || strings.Join(e.Gates, ",") != "lint,artifacts" {
+		t.Errorf("unexpected audit entry: %+v", e)
+	}
+}
+
+func TestPipeline_EscapeHatchSkipsOneGate(t *testing.T) {
+	p, _, stderr := newTestPipeline(&mockGitService{})
+	rec := &mockAuditRecorder{}
+	p.Audit = rec
+
+	if err := p.Execute(context.Background(), PipelineOpts{SkipEnv: "lint", DryRun: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Skipping lint (requested by GATEKEEPER_SKIP)") {
+		t.Errorf("expected skip warning, got %q", stderr.String())
+	}
+	if len(rec.entries) != 0 {
+		t.Errorf("dry runs must not write audit entries, got %+v", rec.entries)
+	}
+}
+
+func TestPipeline_AuditsRunAndFixes(t *testing.T) {
+	gitSvc := &mockGitService{applied: []string{"main.go"}}
+	p, _, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Gates[0].Writable = true
+		return cfg, nil
+	}
+	p.Runner = &mockGateRunner{result: failingRunResult()}
+	rec := &mockAuditRecorder{}
+	p.Audit = rec
+
+	err := p.Execute(context.Background(), PipelineOpts{Fix: true})
+	if !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed, got %v", err)
+	}
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected run and fix entries, got %+v", rec.entries)
+	}
+	run, fix := rec.entries[0], rec.entries[1]
+	if run.Event != audit.EventRun || run.Passed == nil || *run.Passed || strings.Join(run.Gates, ",") != "lint" || run.Branch != "main" {
+		t.Errorf("unexpected run entry: %+v", run)
+	}
+	if fix.Event != audit.EventFix || strings.Join(fix.Files, ",") != "main.go" {
+		t.Errorf("unexpected fix entry: %+v", fix)
+	}
+}
+
+func TestPipeline_BranchRulesBlockCommit(t *testing.T) {
+	gitSvc := &mockGitService{}
+	p, stdout, _ := newTestPipeline(gitSvc)
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Branches = config.BranchRules{BlockCommitOn: []string{"main", "release/*"}}
+		return cfg, nil
+	}
+	runner := &mockGateRunner{result: passingRunResult()}
+	p.Runner = runner
+	rec := &mockAuditRecorder{}
+	p.Audit = rec
+
+	err := p.Execute(context.Background(), PipelineOpts{NoColor: true})
+	if !errors.Is(err, ErrGatesFailed) {
+		t.Fatalf("expected ErrGatesFailed, got %v", err)
+	}
+	if gitSvc.stashCalled || runner.names != nil {
+		t.Error("no gate should run on a protected branch")
+	}
+	if !strings.Contains(stdout.String(), `branch "main" is protected (block_commit_on: main)`) {
+		t.Errorf("expected the branch rule in the output, got %q", stdout.String())
+	}
+	if len(rec.entries) != 1 || rec.entries[0].Event != audit.EventRun || strings.Join(rec.entries[0].Gates, ",") != "branch" {
+		t.Errorf("expected a failed run audit entry, got %+v", rec.entries)
+	}
+}
+
+func TestPipeline_BranchRulesOverride(t *testing.T) {
+	p, _, stderr := newTestPipeline(&mockGitService{})
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) {
+		cfg := defaultConfig()
+		cfg.Branches = config.BranchRules{Naming: "^feat/"}
+		return cfg, nil
+	}
+	rec := &mockAuditRecorder{}
+	p.Audit = rec
+
+	if err := p.Execute(context.Background(), PipelineOpts{AllowBranch: allowBranchEnv}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "allowed by GATEKEEPER_ALLOW_BRANCH") {
+		t.Errorf("expected an override warning, got %q", stderr.String())
+	}
+	if len(rec.entries) != 2 {
+		t.Fatalf("expected override and run entries, got %+v", rec.entries)
+	}
+	e := rec.entries[0]
+	if e.Event != audit.EventOverride || e.Branch != "main" || e.Source != allowBranchEnv || !strings.Contains(e.Reason, "does not match ^feat/") {
+		t.Errorf("unexpected override entry: %+v", e)
+	}
+}
+
+func TestAllowBranchSource(t *testing.T) {
+	tests := []struct {
+		flag bool
+		env  string
+		want string
+	}{
+		{false, "", ""},
+		{false, "0", ""},
+		{false, "1", allowBranchEnv},
+		{true, "", "--allow-branch"},
+	}
+	for _, tt := range tests {
+		if got := allowBranchSource(tt.flag, tt.env); got != tt.want {
+			t.Errorf("allowBranchSource(%v, %q) = %q, want %q", tt.flag, tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestPipeline_RecordsIndexTree(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		p, _, _ := newTestPipeline(&mockGitService{tree: "abc123"})
+		p.Runner = &mockGateRunner{result: passingRunResult()}
+		recorder := &mockResultRecorder{}
+		p.Results = recorder
+
+		if err := p.Execute(context.Background(), PipelineOpts{DryRun: dryRun}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "abc123"
+		if dryRun {
+			want = "" // dry runs cannot be attested
+		}
+		if recorder.saved == nil || recorder.saved.Tree != want {
+			t.Errorf("dryRun=%v: recorded tree = %+v, want %q", dryRun, recorder.saved, want)
+		}
+	}
+}
+
+type mockRunHistory struct {
+	runs []results.Run
+}
+
+func (m *mockRunHistory) History(_ int) ([]results.Run, error) {
+	return m.runs, nil
+}
+
+func TestPipeline_DowngradesFlakyGates(t *testing.T) {
+	gitSvc := &mockGitService{tree: "tree1"}
+	p, _, stderr := newTestPipeline(gitSvc)
+	cfg := defaultConfig()
+	cfg.Defaults.FlakyDowngrade = 3
+	p.LoadConfig = func(_ context.Context, _ string) (*config.GatekeeperConfig, error) { return cfg, nil }
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
+	recorder := &mockResultRecorder{}
+	p.Results = recorder
+
+	hash := gateInputHash("tree1", cfg.Gates[0])
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	p.History = &mockRunHistory{runs: []results.Run{
+		{At: at.Add(time.Hour), Result: formatter.RunResult{Gates: []formatter.GateResult{{Name: "lint", InputHash: hash}}}},
+		{At: at, Result: formatter.RunResult{Passed: true, Gates: []formatter.GateResult{{Name: "lint", Passed: true, InputHash: hash}}}},
+	}}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creator.received[0].IsBlocking() {
+		t.Error("expected the flaky gate to be downgraded to warn")
+	}
+	if !cfg.Gates[0].IsBlocking() {
+		t.Error("downgrading must not modify the loaded config")
+	}
+	assertContains(t, stderr.String(), "🎲 lint is flaky")
+	assertContains(t, stderr.String(), "for 3 more runs")
+
+	lint := recorder.saved.Gates[0]
+	if lint.InputHash != hash || !lint.Flaky || !lint.Downgraded {
+		t.Errorf("expected the recorded gate to be hashed and flagged, got %+v", lint)
+	}
+}
+
+func TestPipeline_FlakyGatesStayBlockingByDefault(t *testing.T) {
+	gitSvc := &mockGitService{tree: "tree1"}
+	p, _, stderr := newTestPipeline(gitSvc)
+	creator := &mockGateCreator{gates: []gate.Gate{&stubGate{}}}
+	p.Gates = creator
+	hash := gateInputHash("tree1", defaultConfig().Gates[0])
+	p.History = &mockRunHistory{runs: []results.Run{
+		{Result: formatter.RunResult{Gates: []formatter.GateResult{{Name: "lint", InputHash: hash}}}},
+		{Result: formatter.RunResult{Gates: []formatter.GateResult{{Name: "lint", Passed: true, InputHash: hash}}}},
+	}}
+
+	if err := p.Execute(context.Background(), PipelineOpts{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !creator.received[0].IsBlocking() || strings.Contains(stderr.String(), "flaky") {
+		t.Errorf("expected flaky gates to keep blocking without flaky_downgrade, stderr:\n%s", stderr)
+	}
+}
@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
 )
 
 func TestFilterSkippedGates_NoFilters(t *testing.T) {
@@ -133,3 +141,148 @@ func TestFormatStacks_Multiple(t *testing.T) {
 		t.Errorf("expected 'go + node', got %q", result)
 	}
 }
+
+func TestBranchConfigLoader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gates.yaml")
+	yml := "version: 1\noverrides:\n  - branch: main\n    defaults:\n      fail_fast: true\ngates:\n  - name: vet\n    type: exec\n    command: go vet ./...\n"
+	if err := os.WriteFile(path, []byte(yml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := branchConfigLoader(&git.MockService{Branch: "main"})(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Defaults.FailFastEnabled() {
+		t.Error("expected main override to be applied")
+	}
+
+	cfg, err = branchConfigLoader(&git.MockService{BranchErr: errors.New("not a repo")})(context.Background(), path)
+	if err != nil {
+		t.Fatalf("branch lookup errors should not fail loading: %v", err)
+	}
+	if cfg.Defaults.FailFastEnabled() {
+		t.Error("expected no overrides when the branch is unknown")
+	}
+}
+
+func TestWithLLMCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	ctx := context.Background()
+	client := &llm.MockClient{}
+	p := llm.Provider{Name: llm.ProviderGemini, Model: llm.DefaultGeminiModel}
+
+	if got := withLLMCache(ctx, client, p, time.Hour, true); got != client {
+		t.Error("expected --no-llm-cache to bypass the cache")
+	}
+	if got := withLLMCache(ctx, client, p, 0, false); got != client {
+		t.Error("expected zero TTL to bypass the cache")
+	}
+	if _, ok := withLLMCache(ctx, client, p, time.Hour, false).(*llm.CachingClient); !ok {
+		t.Error("expected a caching client")
+	}
+}
+
+func TestNewLLMProviders(t *testing.T) {
+	flagNoLLMCache = true
+	t.Cleanup(func() { flagNoLLMCache = false })
+	ctx := context.Background()
+	providers := newLLMProviders(ctx, &config.GlobalConfig{GeminiAPIKey: "key", MaxTokensPerRun: 1000}, false)
+
+	gemini, err := providers("gemini-3-pro")
+	if err != nil {
+		t.Fatalf("gemini: %v", err)
+	}
+	if again, _ := providers("gemini-3-pro"); again != gemini {
+		t.Error("expected the client of a provider to be shared")
+	}
+	if _, err := providers("ollama/llama3"); err != nil {
+		t.Errorf("ollama needs no key, got %v", err)
+	}
+	if _, err := providers("gpt-4o-mini"); !errors.Is(err, llm.ErrProviderUnavailable) {
+		t.Errorf("expected OpenAI without a key to be unavailable, got %v", err)
+	}
+	if _, err := providers("claude-3"); err == nil || errors.Is(err, llm.ErrProviderUnavailable) {
+		t.Errorf("expected an unknown provider error, got %v", err)
+	}
+
+	dry := newLLMProviders(ctx, &config.GlobalConfig{}, true)
+	if client, err := dry("gpt-4o-mini"); err != nil {
+		t.Errorf("dry runs estimate every provider, got %v", err)
+	} else if _, ok := client.(*llm.EstimatingClient); !ok {
+		t.Errorf("expected an estimating client, got %T", client)
+	}
+}
+
+func TestContainerRuntime_Record(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	rt, closeTrace, err := containerRuntime(path, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := rt.(*pool.RecordingRuntime); !ok {
+		t.Errorf("expected a recording runtime, got %T", rt)
+	}
+	if err := closeTrace(); err != nil {
+		t.Fatalf("unexpected error closing trace: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected trace file to be created: %v", err)
+	}
+}
+
+func TestContainerRuntime_Replay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	if err := os.WriteFile(path, []byte(`{"op":"ping","duration_ms":1}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rt, _, err := containerRuntime("", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := rt.Ping(context.Background()); err != nil {
+		t.Errorf("expected recorded ping to replay, got %v", err)
+	}
+
+	if _, _, err := containerRuntime("", filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("expected error for a missing trace")
+	}
+	if _, _, err := containerRuntime(path, path); err == nil {
+		t.Error("expected error when combining --record and --replay")
+	}
+}
+
+func TestNewGateBackend_Kubernetes(t *testing.T) {
+	cfg := &config.GlobalConfig{Runtime: config.RuntimeKubernetes}
+	backend, _, err := newGateBackend(cfg, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := backend.checker.(*kubeCheckerAdapter); !ok {
+		t.Errorf("expected a kubernetes checker, got %T", backend.checker)
+	}
+	if _, _, err := newGateBackend(cfg, filepath.Join(t.TempDir(), "trace.jsonl"), ""); err == nil {
+		t.Error("expected error when recording a kubernetes run")
+	}
+}
+
+func TestStalePruner_SkipsWhileLocked(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "gatekeeper", "prune.lock")
+	listErr := errors.New("daemon unavailable")
+	pruner := &stalePruner{pool: pool.NewPool(&pool.MockRuntime{ListErr: listErr}), ttl: time.Hour, lockPath: lockPath}
+
+	if err := pruner.PruneStale(context.Background()); !errors.Is(err, listErr) {
+		t.Fatalf("PruneStale = %v, want the list error of an unlocked prune", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected the lock to be released")
+	}
+
+	if err := os.WriteFile(lockPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := pruner.PruneStale(context.Background()); err != nil {
+		t.Errorf("PruneStale = %v, want the prune skipped while another run holds the lock", err)
+	}
+}
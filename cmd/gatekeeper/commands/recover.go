@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// stashRestoreFailed is printed when a run cannot restore the unstaged
+// changes it stashed.
+const stashRestoreFailed = "⚠️  Could not restore your unstaged changes; they are kept in a gatekeeper stash. Run 'gatekeeper recover' to restore them."
+
+var flagRecoverList bool
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: "Restore unstaged changes stashed by a run that did not finish",
+	Long: `Restore the unstaged changes that a gatekeeper run stashed but did not
+restore, because it crashed or was killed. Gatekeeper stashes are told apart
+from your own stashes by their message, and are restored oldest first.
+Do not run this while a gatekeeper run is in progress.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+		log.Info("recover started")
+
+		projectDir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		gitSvc := git.NewExecService(projectDir)
+		if err := recoverStashes(ctx, gitSvc, cmd.OutOrStdout(), flagRecoverList); err != nil {
+			return err
+		}
+		log.Info("recover completed")
+		return nil
+	},
+}
+
+// recoverStashes restores the gatekeeper stashes, oldest first, or only lists
+// them. It stops at the first stash that does not apply cleanly.
+func recoverStashes(ctx context.Context, gitSvc git.Service, out io.Writer, list bool) error {
+	stashes, err := gitSvc.Stashes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(stashes) == 0 {
+		fmt.Fprintln(out, "No gatekeeper stashes to recover")
+		return nil
+	}
+
+	if list {
+		for _, st := range stashes {
+			fmt.Fprintf(out, "%s  %s  %s\n", st.Ref, st.At.Local().Format("2006-01-02 15:04"), st.Message)
+		}
+		return nil
+	}
+
+	for _, st := range slices.Backward(stashes) {
+		if err := gitSvc.StashPop(ctx, st.ID); err != nil {
+			return fmt.Errorf("restoring stash from %s (resolve the conflicts, then run 'git stash drop' or 'gatekeeper recover' again): %w",
+				st.At.Local().Format("2006-01-02 15:04"), err)
+		}
+		fmt.Fprintf(out, "🩹 Restored changes stashed at %s\n", st.At.Local().Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// warnOrphanedStashes points to recover when earlier runs left gatekeeper
+// stashes behind.
+func (p *Pipeline) warnOrphanedStashes(ctx context.Context) {
+	stashes, err := p.Git.Stashes(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Debug("could not list stashes", "error", err)
+		return
+	}
+	if len(stashes) > 0 {
+		fmt.Fprintf(p.Stderr, "⚠️  Found %s left by a run that did not finish; run 'gatekeeper recover' to restore the changes\n",
+			plural(len(stashes), "gatekeeper stash", "gatekeeper stashes"))
+	}
+}
+
+func init() {
+	recoverCmd.Flags().BoolVar(&flagRecoverList, "list", false, "List the gatekeeper stashes without restoring them")
+	rootCmd.AddCommand(recoverCmd)
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+)
+
+func orphanedStashes() []git.StashEntry {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []git.StashEntry{
+		{Ref: "stash@{0}", ID: "newer", Message: "On main: gatekeeper-stash pid 43", At: at},
+		{Ref: "stash@{2}", ID: "older", Message: "On main: gatekeeper-stash pid 42", At: at.Add(-time.Hour)},
+	}
+}
+
+func TestRecoverStashes(t *testing.T) {
+	gitSvc := &mockGitService{stashes: orphanedStashes()}
+	out := &bytes.Buffer{}
+
+	if err := recoverStashes(context.Background(), gitSvc, out, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Oldest first, so the newest stash is popped last.
+	if gitSvc.poppedID != "newer" {
+		t.Errorf("expected the newest stash to be restored last, got %q", gitSvc.poppedID)
+	}
+	assertContains(t, out.String(), "🩹 Restored changes stashed at")
+}
+
+func TestRecoverStashes_List(t *testing.T) {
+	gitSvc := &mockGitService{stashes: orphanedStashes()}
+	out := &bytes.Buffer{}
+
+	if err := recoverStashes(context.Background(), gitSvc, out, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gitSvc.stashPopCalled {
+		t.Error("expected --list not to restore stashes")
+	}
+	assertContains(t, out.String(), "stash@{2}")
+	assertContains(t, out.String(), "gatekeeper-stash pid 43")
+}
+
+func TestRecoverStashes_None(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := recoverStashes(context.Background(), &mockGitService{}, out, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), "No gatekeeper stashes to recover")
+}
+
+func TestRecoverStashes_Conflict(t *testing.T) {
+	gitSvc := &mockGitService{stashes: orphanedStashes(), stashPopErr: errors.New("conflict")}
+
+	err := recoverStashes(context.Background(), gitSvc, &bytes.Buffer{}, false)
+	if err == nil {
+		t.Fatal("expected an error when a stash does not apply")
+	}
+	if gitSvc.poppedID != "older" {
+		t.Errorf("expected to stop at the oldest stash, got %q", gitSvc.poppedID)
+	}
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/report"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for the report command.
+var (
+	flagReportBranch  string
+	flagReportNoTrack bool
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Render the last run as a markdown PR comment",
+	Long: `Render the most recent run as markdown, suitable for posting as a PR comment.
+
+Findings are compared with the previous report for the same branch and marked
+as new, unchanged, or resolved, so reviewers can follow progress across pushes.
+The report starts with an HTML marker CI scripts can use to update the existing
+comment. Use --no-track to preview without recording the report.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		dir := results.DefaultDir(projectDir)
+		last, err := results.NewStore(dir).LoadLast()
+		if err != nil {
+			return err
+		}
+
+		branch := flagReportBranch
+		if branch == "" {
+			branch, err = git.NewExecService(projectDir).CurrentBranch(ctx)
+			if err != nil {
+				log.Warn("could not determine current branch", "error", err)
+			}
+		}
+
+		return writeReport(cmd.OutOrStdout(), last, report.StatePath(dir, branch), branch, !flagReportNoTrack, time.Now().UTC())
+	},
+}
+
+// writeReport renders last as markdown threaded against the branch's previous
+// report, and records the posted findings when track is set.
+func writeReport(out io.Writer, last *formatter.RunResult, statePath, branch string, track bool, now time.Time) error {
+	prev, err := report.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	findings := report.Thread(prev, *last)
+	fmt.Fprint(out, report.Markdown(*last, findings))
+
+	if !track {
+		return nil
+	}
+	return report.NextState(branch, findings, now).Save(statePath)
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&flagReportBranch, "branch", "", "Branch the report belongs to (default: current branch)")
+	reportCmd.Flags().BoolVar(&flagReportNoTrack, "no-track", false, "Do not record this report for threading")
+
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/report"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for the report github command.
+var (
+	flagReportPR   int
+	flagReportRepo string
+)
+
+var reportGitHubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "Post the last run to a GitHub pull request",
+	Long: `Post the most recent run to a GitHub pull request: a summary comment in the
+"--format markdown" layout, updated in place on later runs, and a review
+comment on the line of each finding the pull request changes. Findings already
+commented on by an earlier run are not posted again.
+
+The token is read from GITHUB_TOKEN (or GH_TOKEN) and needs write access to
+pull requests. The repository defaults to GITHUB_REPOSITORY and the API to
+GITHUB_API_URL, as set in GitHub Actions.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		last, err := results.NewStore(results.DefaultDir(projectDir)).LoadLast()
+		if err != nil {
+			return err
+		}
+
+		gh := &report.GitHub{
+			API:   os.Getenv("GITHUB_API_URL"),
+			Repo:  flagReportRepo,
+			Token: os.Getenv("GITHUB_TOKEN"),
+		}
+		if gh.Repo == "" {
+			gh.Repo = os.Getenv("GITHUB_REPOSITORY")
+		}
+		if gh.Token == "" {
+			gh.Token = os.Getenv("GH_TOKEN")
+		}
+		return postGitHubReport(cmd.Context(), cmd.OutOrStdout(), gh, flagReportPR, last)
+	},
+}
+
+// postGitHubReport posts last to pull request pr and reports what was posted.
+func postGitHubReport(ctx context.Context, out io.Writer, gh *report.GitHub, pr int, last *formatter.RunResult) error {
+	switch {
+	case pr <= 0:
+		return errors.New("--pr is required")
+	case gh.Repo == "":
+		return errors.New("no repository: pass --repo owner/name or set GITHUB_REPOSITORY")
+	case gh.Token == "":
+		return errors.New("no GitHub token: set GITHUB_TOKEN or GH_TOKEN")
+	}
+
+	body := formatter.NewMarkdownFormatter(false).Format(*last)
+	res, err := gh.PostPR(ctx, pr, body, report.Thread(nil, *last))
+	if err != nil {
+		return err
+	}
+
+	verb := "Posted"
+	if res.Updated {
+		verb = "Updated"
+	}
+	fmt.Fprintf(out, "💬 %s the report on %s#%d: %s\n", verb, gh.Repo, pr, res.CommentURL)
+	if n := res.Inline + res.Existing + res.OutsideDiff; n > 0 {
+		fmt.Fprintf(out, "📌 %d inline comment(s) posted, %d already posted, %d outside the diff\n", res.Inline, res.Existing, res.OutsideDiff)
+	}
+	return nil
+}
+
+func init() {
+	reportGitHubCmd.Flags().IntVar(&flagReportPR, "pr", 0, "Pull request number")
+	reportGitHubCmd.Flags().StringVar(&flagReportRepo, "repo", "", "Repository as owner/name (default: $GITHUB_REPOSITORY)")
+
+	reportCmd.AddCommand(reportGitHubCmd)
+}
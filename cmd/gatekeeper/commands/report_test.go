@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/report"
+)
+
+func TestWriteReport_Threading(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report-main.json")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var preview bytes.Buffer
+	if err := writeReport(&preview, triageRun(), path, "main", false, now); err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	assertContains(t, preview.String(), "🆕 1 new")
+	if s, _ := report.LoadState(path); s != nil {
+		t.Fatal("expected --no-track to leave no state")
+	}
+
+	if err := writeReport(&bytes.Buffer{}, triageRun(), path, "main", true, now); err != nil {
+		t.Fatalf("first report: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeReport(&out, triageRun(), path, "main", true, now); err != nil {
+		t.Fatalf("second report: %v", err)
+	}
+	assertContains(t, out.String(), report.Marker)
+	assertContains(t, out.String(), "🆕 0 new · 🔁 1 unchanged")
+	assertContains(t, out.String(), "| 🔁 unchanged | security | `main.go:4` |")
+}
+
+func TestPostGitHubReport_RequiresPRRepoAndToken(t *testing.T) {
+	tests := []struct {
+		pr   int
+		gh   report.GitHub
+		want string
+	}{
+		{0, report.GitHub{Repo: "o/r", Token: "t"}, "--pr is required"},
+		{7, report.GitHub{Token: "t"}, "no repository"},
+		{7, report.GitHub{Repo: "o/r"}, "no GitHub token"},
+	}
+	for _, tt := range tests {
+		err := postGitHubReport(context.Background(), &bytes.Buffer{}, &tt.gh, tt.pr, triageRun())
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expected %q, got %v", tt.want, err)
+		}
+	}
+}
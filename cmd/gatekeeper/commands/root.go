@@ -14,6 +14,17 @@ var (
 	flagFailFast bool
 	flagSkip     []string
 	flagSkipLLM  bool
+	flagFull     bool
+
+	flagNoLLMCache bool
+	flagAccessible bool
+	flagTimings    bool
+	flagSnapshot   bool
+	flagProfile    string
+	flagNoDocker   bool
+	flagSequential bool
+	flagGroupBy    string
+	flagFormat     string
 )
 
 // rootCmd is the base command for the gatekeeper CLI.
@@ -25,10 +36,21 @@ It reads a declarative configuration file (.gatekeeper/gates.yaml), executes val
 gates inside Docker containers in parallel, and blocks commits that fail any gate.
 
 Built for AI-assisted development — structured JSON output gives agents precise
-file/line locations and fix hints for fast automated remediation.`,
+file/line locations and fix hints for fast automated remediation.
+
+Exit codes:
+  0  success
+  1  usage or other error
+  2  blocking gates failed
+  3  system error: Docker, git, or blocking gates that could not run
+  4  configuration error in gates.yaml or the user config
+  5  cancelled`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if flagFormat == formatJSON {
+			flagJSON = true
+		}
 		l := logger.New(flagVerbose, flagJSON)
 		ctx := logger.WithContext(cmd.Context(), l)
 		cmd.SetContext(ctx)
@@ -37,14 +59,37 @@ file/line locations and fix hints for fast automated remediation.`,
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&flagJSON, "json", false, "Output results as JSON to stdout")
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", formatCLI, "Output format: cli, json (same as --json), or markdown (for PR comments)")
 	rootCmd.PersistentFlags().BoolVar(&flagVerbose, "verbose", false, "Include raw tool stdout/stderr in output")
 	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output")
 	rootCmd.PersistentFlags().BoolVar(&flagFailFast, "fail-fast", false, "Cancel remaining gates on first blocking failure")
+	rootCmd.PersistentFlags().BoolVar(&flagSequential, "sequential", false, "Run gates one at a time in configuration order (for debugging)")
 	rootCmd.PersistentFlags().StringSliceVar(&flagSkip, "skip", nil, "Skip specific gates by name")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Run only the gates of a profile (e.g., fast or full) plus untagged gates")
 	rootCmd.PersistentFlags().BoolVar(&flagSkipLLM, "skip-llm", false, "Skip all LLM gates")
+	rootCmd.PersistentFlags().BoolVar(&flagFull, "full", false, "Run affected_only gates in full instead of only on affected code")
+	rootCmd.PersistentFlags().BoolVar(&flagAccessible, "accessible", false, "Pair status icons with words, use high-contrast colors, and end with a plain-text summary")
+	rootCmd.PersistentFlags().StringVar(&flagGroupBy, "group-by", "gate", "Group findings in CLI output by gate or by file (merging all gates, sorted by line)")
+	rootCmd.PersistentFlags().BoolVar(&flagSnapshot, "snapshot", false, "Run gates on a checkout of the staged index, leaving the working tree untouched")
+	rootCmd.PersistentFlags().BoolVar(&flagTimings, "timings", false, "Print per-gate durations (image pull, container acquire, exec, parse, LLM) after the run")
+	rootCmd.PersistentFlags().BoolVar(&flagNoDocker, "no-docker", false, "Run exec and script gates on the host instead of in containers")
+	rootCmd.PersistentFlags().BoolVar(&flagNoLLMCache, "no-llm-cache", false, "Always call the LLM provider instead of reusing cached reviews")
+
+	// Registering completions only fails for unknown flags.
+	_ = rootCmd.RegisterFlagCompletionFunc("skip", completeGateList)
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfile)
+}
+
+// failFastFlag returns the value of --fail-fast, or nil if it was not given,
+// so the configured default applies.
+func failFastFlag() *bool {
+	if !rootCmd.PersistentFlags().Changed("fail-fast") {
+		return nil
+	}
+	return &flagFailFast
 }
 
-// Execute runs the root command. Returns an error if the command fails.
-func Execute() error {
-	return rootCmd.Execute()
+// Execute runs the root command and returns the process exit code.
+func Execute() int {
+	return ExitCode(rootCmd.Execute())
 }
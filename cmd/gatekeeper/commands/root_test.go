@@ -33,6 +33,18 @@ func TestVersionCommand(t *testing.T) {
 	}
 }
 
+func TestSchemaCommand(t *testing.T) {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOut(buf)
+	rootCmd.SetErr(buf)
+	rootCmd.SetArgs([]string{"schema"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("schema command returned error: %v", err)
+	}
+	assertContains(t, buf.String(), `"title": "Gatekeeper gates.yaml"`)
+}
+
 func TestRootCommand_HasSubcommands(t *testing.T) {
 	expected := map[string]bool{
 		"init":     false,
@@ -57,7 +69,7 @@ func TestRootCommand_HasSubcommands(t *testing.T) {
 }
 
 func TestRootCommand_GlobalFlags(t *testing.T) {
-	flags := []string{"json", "verbose", "no-color", "fail-fast", "skip", "skip-llm"}
+	flags := []string{"json", "verbose", "no-color", "fail-fast", "skip", "skip-llm", "full"}
 
 	for _, name := range flags {
 		flag := rootCmd.PersistentFlags().Lookup(name)
@@ -100,6 +112,8 @@ func TestCleanupCommand(t *testing.T) {
 }
 
 func TestInitCommand_NoGitRepo(t *testing.T) {
+	// Init writes files (gates.yaml, .gitignore) before the hook install fails.
+	t.Chdir(t.TempDir())
 	rootCmd.SetArgs([]string{"init"})
 
 	// Init requires a git repo to install hooks.
@@ -123,8 +137,8 @@ func TestExecute(t *testing.T) {
 	// Execute() is a convenience wrapper around rootCmd.Execute().
 	// With no args it prints help and succeeds.
 	rootCmd.SetArgs([]string{})
-	if err := Execute(); err != nil {
-		t.Fatalf("Execute() returned error: %v", err)
+	if code := Execute(); code != ExitOK {
+		t.Fatalf("Execute() returned exit code %d", code)
 	}
 }
 
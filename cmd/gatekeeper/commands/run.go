@@ -7,21 +7,71 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	flagRecord string
+	flagReplay string
+	flagFix    bool
+
+	flagMessageFile string
+	flagAllowBranch bool
+)
+
+// allowBranchEnv allows a commit the branch rules block, like --allow-branch,
+// for runs started by the pre-commit hook.
+const allowBranchEnv = "GATEKEEPER_ALLOW_BRANCH"
+
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run all gates and block commit on failure",
 	Long: `Execute all configured gates in parallel. Exit 0 if all blocking gates pass,
-exit 1 if any blocking gate fails. Non-blocking gate failures are reported but
-do not affect the exit code.`,
+exit 2 if any blocking gate fails (3 if they only could not run). Non-blocking
+gate failures are reported but do not affect the exit code. See "gatekeeper
+--help" for all exit codes.
+
+--fix keeps the modifications writable gates (e.g., formatters) make to staged
+files and re-stages them, instead of reverting them.
+
+Gates can be skipped in an emergency with GATEKEEPER_SKIP=all (or a
+comma-separated list of gate names), or with [skip gatekeeper] or
+[skip gate:<name>] in the commit message read from --message-file, which the
+commit-msg hook of 'gatekeeper init --commit-msg' passes. Skips are reported in
+the output and recorded in .gatekeeper/audit.log.
+
+Commits on a branch that the branches rules of gates.yaml block (block_commit_on
+or naming) fail before any gate runs. --allow-branch, or
+GATEKEEPER_ALLOW_BRANCH=1 for commits through the hook, lets the commit through
+and records the override in the audit log.
+
+--record writes every Docker call and its output to a JSONL trace, to attach to
+bug reports about daemon-specific behavior. --replay serves Docker responses
+from such a trace instead of the daemon.`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		err := runPipeline(cmd.Context(), false)
 		if errors.Is(err, ErrGatesFailed) {
-			os.Exit(1)
+			os.Exit(ExitCode(err))
 		}
 		return err
 	},
 }
 
+// allowBranchSource returns what allowed a commit the branch rules block, or
+// "" if nothing did.
+func allowBranchSource(flag bool, env string) string {
+	switch {
+	case flag:
+		return "--allow-branch"
+	case env != "" && env != "0" && env != "false":
+		return allowBranchEnv
+	default:
+		return ""
+	}
+}
+
 func init() {
+	runCmd.Flags().BoolVar(&flagFix, "fix", false, "Re-stage fixes made by writable gates instead of reverting them")
+	runCmd.Flags().BoolVar(&flagAllowBranch, "allow-branch", false, "Commit on a branch the branch rules block, recording the override in the audit log")
+	runCmd.Flags().StringVar(&flagMessageFile, "message-file", "", "Commit message file to scan for [skip gatekeeper] markers (e.g., from a commit-msg hook or CI)")
+	runCmd.Flags().StringVar(&flagRecord, "record", "", "Record Docker calls and output to a JSONL trace file")
+	runCmd.Flags().StringVar(&flagReplay, "replay", "", "Serve Docker responses from a recorded trace instead of the daemon")
 	rootCmd.AddCommand(runCmd)
 }
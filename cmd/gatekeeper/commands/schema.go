@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of gates.yaml",
+	Long: `Print the JSON Schema of .gatekeeper/gates.yaml for editor validation and
+completion. Configs generated by 'gatekeeper init' already reference the
+published schema with a yaml-language-server modeline:
+
+  # yaml-language-server: $schema=` + config.SchemaURL + `
+
+To pin the schema to the installed version instead, save it next to the config
+and point the modeline at the file:
+
+  gatekeeper schema > .gatekeeper/gates.schema.json
+  # yaml-language-server: $schema=gates.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		data, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/irahardianto/gatekeeper/internal/engine/server"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for "server".
+var (
+	flagServerAddr      string
+	flagServerWorkdir   string
+	flagServerRepos     []string
+	flagServerMaxUpload int64
+	flagServerConfig    string
+	flagServerInsecure  bool
+)
+
+// serverShutdownTimeout bounds how long in-flight checks may finish on shutdown.
+const serverShutdownTimeout = 30 * time.Second
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Serve gate checks over HTTP for pre-receive hooks and CI",
+	Long: `Run an HTTP service that runs gates in pooled containers and returns the
+RunResult, for central enforcement on self-hosted Git servers. The gates come
+from the gates.yaml given with --config, not from the checked tree, so a push
+cannot change or remove them.
+
+POST /v1/check accepts either:
+  - application/json {"repo": "<url>", "ref": "<commit>", "base": "<commit>"}:
+    the server fetches ref and checks the changes since base (all files if
+    base is empty or all zeros). The repo must match an --allow-repo prefix.
+  - multipart/form-data with the fields repo (a name, which must match an
+    --allow-repo prefix too), changed (the changed
+    paths, one per line), and archive (a tar of the tree, e.g. 'git archive'),
+    as a pre-receive hook can send before the push is accepted. LLM gates are
+    skipped for uploads, which carry no diff.
+
+The response is 200 if the gates passed and 422 if they failed, with the
+RunResult as JSON, or the CLI report with "Accept: text/plain". Set
+GATEKEEPER_SERVER_TOKEN to require "Authorization: Bearer <token>"; the server
+does not start without it unless --insecure is given. With the docker runtime,
+GET /metrics serves the container pool metrics in Prometheus format, behind
+the same token.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		log := logger.FromContext(ctx)
+
+		token := os.Getenv(server.TokenEnv)
+		if token == "" {
+			if !flagServerInsecure {
+				return fmt.Errorf("set %s to authenticate check requests (or pass --insecure to accept unauthenticated ones)", server.TokenEnv)
+			}
+			log.Warn("no " + server.TokenEnv + " set; check requests are not authenticated")
+		}
+		configPath, err := filepath.Abs(flagServerConfig)
+		if err != nil {
+			return fmt.Errorf("resolving --config: %w", err)
+		}
+		if _, err := config.Load(ctx, configPath); err != nil {
+			return fmt.Errorf("loading --config: %w", err)
+		}
+
+		globalCfg, err := config.LoadGlobalConfig(ctx)
+		if err != nil {
+			return fmt.Errorf("loading global config: %w", err)
+		}
+		// [SEC] The checked code is not trusted: gates must not run programs on the host.
+		globalCfg.Local.Disabled = true
+		backend, _, err := newGateBackend(globalCfg, "", "")
+		if err != nil {
+			return err
+		}
+
+		workdir := flagServerWorkdir
+		if workdir == "" {
+			cacheDir, err := os.UserCacheDir()
+			if err != nil {
+				return fmt.Errorf("finding cache directory (set --workdir): %w", err)
+			}
+			workdir = filepath.Join(cacheDir, "gatekeeper", "server")
+		}
+
+		opts := server.Options{
+			Token:        token,
+			AllowedRepos: flagServerRepos,
+			MaxUpload:    flagServerMaxUpload,
+		}
+		if backend.docker != nil {
+			opts.Metrics = pool.MetricsHandler(backend.docker)
+		}
+		srv := server.New(workdir, serverRunFunc(globalCfg, backend, configPath), opts)
+
+		httpServer := &http.Server{
+			Addr:              flagServerAddr,
+			Handler:           srv.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			BaseContext:       func(_ net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), serverShutdownTimeout)
+			defer cancel()
+			_ = httpServer.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "🛡️  Serving gate checks on http://%s (workspaces in %s)\n", flagServerAddr, workdir)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("serving: %w", err)
+		}
+		return nil
+	},
+}
+
+// serverRunFunc runs the gates of configPath on a server workspace through the
+// regular pipeline, capturing the result instead of persisting it.
+func serverRunFunc(globalCfg *config.GlobalConfig, backend gateBackend, configPath string) server.RunFunc {
+	return func(ctx context.Context, check server.Check, out io.Writer) (*formatter.RunResult, error) {
+		pipeline, err := newPipeline(ctx, check.Dir, globalCfg, backend, false, io.Discard)
+		if err != nil {
+			return nil, err
+		}
+		untrusted(pipeline, configPath)
+		captured := &capturedResult{}
+		pipeline.Results = captured
+		pipeline.Snapshots = nil
+		pipeline.Stdout, pipeline.Stderr = out, out
+		if check.Changed != nil {
+			pipeline.stagedFiles = check.Changed
+		}
+
+		err = pipeline.Execute(ctx, PipelineOpts{NoColor: true, SkipLLM: check.Archive})
+		if err != nil && !errors.Is(err, ErrGatesFailed) {
+			return nil, err
+		}
+		if captured.result == nil {
+			// No gates applied to the changes.
+			return &formatter.RunResult{Passed: true}, nil
+		}
+		return captured.result, nil
+	}
+}
+
+// untrusted makes a pipeline take nothing from the checked tree that decides
+// what is checked: the gates come from configPath instead of the tree's
+// gates.yaml and nested configs, prompt files are read next to configPath, and
+// the tree's suppressions and LLM dismissals are not applied.
+func untrusted(pipeline *Pipeline, configPath string) {
+	pipeline.ConfigPath = configPath
+	if g, ok := pipeline.Git.(*git.ExecService); ok {
+		// [SEC] The tree's git configuration must not run commands on the host.
+		g.Untrusted = true
+	}
+	pipeline.LoadNestedConfigs = nil
+	pipeline.Suppressions = nil
+	if f, ok := pipeline.Gates.(*gate.Factory); ok {
+		f.SetDismissals(nil)
+		f.SetPromptDir(filepath.Dir(configPath))
+	}
+}
+
+// capturedResult keeps the result of a run in memory.
+type capturedResult struct {
+	result *formatter.RunResult
+}
+
+func (c *capturedResult) SaveLast(result formatter.RunResult) error {
+	c.result = &result
+	return nil
+}
+
+func init() {
+	serverCmd.Flags().StringVar(&flagServerAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serverCmd.Flags().StringVar(&flagServerWorkdir, "workdir", "", "Directory for repository workspaces (default: user cache dir)")
+	serverCmd.Flags().StringSliceVar(&flagServerRepos, "allow-repo", nil, "URL prefix of repositories the server may clone (repeatable)")
+	serverCmd.Flags().Int64Var(&flagServerMaxUpload, "max-upload", server.DefaultMaxUpload, "Maximum size in bytes of an uploaded archive (it may extract to 10 times this)")
+	serverCmd.Flags().StringVar(&flagServerConfig, "config", "", "gates.yaml of the gates to run, in place of the checked tree's")
+	serverCmd.Flags().BoolVar(&flagServerInsecure, "insecure", false, "Accept unauthenticated check requests when "+server.TokenEnv+" is not set")
+	_ = serverCmd.MarkFlagRequired("config")
+	rootCmd.AddCommand(serverCmd)
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/server"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+)
+
+func TestServerCmd_RequiresToken(t *testing.T) {
+	t.Setenv(server.TokenEnv, "")
+	defer func(insecure bool) { flagServerInsecure = insecure }(flagServerInsecure)
+	flagServerInsecure = false
+
+	serverCmd.SetContext(context.Background())
+	err := serverCmd.RunE(serverCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--insecure") {
+		t.Fatalf("expected the server to refuse to start without a token, got %v", err)
+	}
+}
+
+func TestUntrusted(t *testing.T) {
+	p := &Pipeline{
+		Gates:             gate.NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/workspace"),
+		Suppressions:      &triage.Store{},
+		ConfigPath:        "/workspace/.gatekeeper/gates.yaml",
+		LoadNestedConfigs: func(context.Context, []string) ([]config.Gate, error) { return nil, nil },
+	}
+	untrusted(p, "/etc/gatekeeper/gates.yaml")
+
+	if p.ConfigPath != "/etc/gatekeeper/gates.yaml" {
+		t.Errorf("ConfigPath = %q, want the server config", p.ConfigPath)
+	}
+	if p.LoadNestedConfigs != nil || p.Suppressions != nil {
+		t.Error("expected the tree's nested configs and suppressions to be ignored")
+	}
+}
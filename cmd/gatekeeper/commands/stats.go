@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/stats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStatsLast  int
+	flagStatsFlaky bool
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the recorded runs: durations, slow gates, failing rules, LLM spend",
+	Long: `Aggregate the run history in .gatekeeper/results/history: the average and
+longest run, each gate's duration, failures, and skips (slowest gates first),
+the rules that fail most often, and the tokens and cost of LLM gates. Use it to
+decide which gates are worth their latency before each commit.
+
+With --flaky, the report lists the flaky gates instead: gates that both passed
+and failed on the same staged changes and gate configuration. Set
+defaults.flaky_downgrade in gates.yaml to make them warn instead of blocking
+for a number of runs.
+
+Nothing is sent anywhere; the history stays in the project. --last limits the
+report to the most recent runs. With --json, the report is printed as JSON.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		runs, err := results.NewStore(results.DefaultDir(projectDir)).History(flagStatsLast)
+		if err != nil {
+			return err
+		}
+
+		if flagStatsFlaky {
+			return writeStats(cmd.OutOrStdout(), stats.Flaky(runs), stats.WriteFlakyTable)
+		}
+		return writeStats(cmd.OutOrStdout(), stats.Compute(runs), stats.WriteTable)
+	},
+}
+
+// writeStats prints a report as JSON with --json, else with writeTable.
+func writeStats[T any](out io.Writer, report T, writeTable func(io.Writer, T)) error {
+	if !flagJSON {
+		writeTable(out, report)
+		return nil
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("encoding stats: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	statsCmd.Flags().IntVar(&flagStatsLast, "last", 0, "Only include the most recent N runs (0 includes all recorded runs)")
+	statsCmd.Flags().BoolVar(&flagStatsFlaky, "flaky", false, "List the gates that passed and failed on the same changes")
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/spf13/cobra"
+)
+
+var flagStatusHistory int
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the result of the last run without re-running",
+	Long: `Show a summary of the most recent run recorded in .gatekeeper/results/last.json,
+followed by the outcomes of recent runs. Nothing is re-run, so this is cheap
+enough for editor plugins and other hooks. With --json, the recorded run is
+printed as JSON.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+
+		store := results.NewStore(results.DefaultDir(projectDir))
+		last, err := store.LoadLast()
+		if err != nil {
+			return err
+		}
+		at, err := store.LastRunAt()
+		if err != nil {
+			return err
+		}
+		history, err := store.History(flagStatusHistory)
+		if err != nil {
+			return err
+		}
+
+		if flagJSON {
+			return writeStatusJSON(cmd.OutOrStdout(), *last, at, history)
+		}
+		writeStatus(cmd.OutOrStdout(), *last, at, history, time.Now())
+		return nil
+	},
+}
+
+// statusRun is a recent run in status JSON output.
+type statusRun struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	Passed     bool      `json:"passed"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// statusOutput is the JSON form of gatekeeper status.
+type statusOutput struct {
+	RecordedAt time.Time           `json:"recorded_at"`
+	Result     formatter.RunResult `json:"result"`
+	History    []statusRun         `json:"history"`
+}
+
+// writeStatusJSON prints the last run, when it was recorded, and recent run outcomes.
+func writeStatusJSON(out io.Writer, last formatter.RunResult, at time.Time, history []results.Run) error {
+	status := statusOutput{RecordedAt: at.UTC(), Result: last, History: []statusRun{}}
+	for _, r := range history {
+		status.History = append(status.History, statusRun{RecordedAt: r.At, Passed: r.Result.Passed, DurationMs: r.Result.DurationMs})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(status); err != nil {
+		return fmt.Errorf("encoding status: %w", err)
+	}
+	return nil
+}
+
+// writeStatus prints the last run as a summary line with the failed and
+// advisory gates, followed by one line per recent run.
+func writeStatus(out io.Writer, last formatter.RunResult, at time.Time, history []results.Run, now time.Time) {
+	counts, details := runSummary(last)
+	fmt.Fprintf(out, "\n%s Last run %s — %s in %dms\n", runIcon(last), ago(now.Sub(at)), outcome(last), last.DurationMs)
+	fmt.Fprintf(out, "   %s\n", counts)
+	for _, d := range details {
+		fmt.Fprintf(out, "   %s\n", d)
+	}
+
+	if len(history) > 1 {
+		fmt.Fprintf(out, "\nRecent runs:\n")
+		for _, r := range history {
+			c, _ := runSummary(r.Result)
+			fmt.Fprintf(out, "   %s %s  %s (%s)\n", runIcon(r.Result), r.At.Local().Format("2006-01-02 15:04"), outcome(r.Result), c)
+		}
+	}
+	fmt.Fprintln(out)
+}
+
+// runIcon is the status icon of a run.
+func runIcon(r formatter.RunResult) string {
+	if r.Passed {
+		return "✅"
+	}
+	return "❌"
+}
+
+// outcome is the status word of a run, shown next to its icon.
+func outcome(r formatter.RunResult) string {
+	if r.Passed {
+		return "passed"
+	}
+	return "failed"
+}
+
+// ago renders a duration as a coarse relative time, e.g. "5m ago".
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func init() {
+	statusCmd.Flags().IntVar(&flagStatusHistory, "history", 5, "Number of recent runs to list (0 lists all recorded runs)")
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+)
+
+func statusRunResult() formatter.RunResult {
+	return formatter.RunResult{
+		DurationMs: 4200,
+		Gates: []formatter.GateResult{
+			{Name: "lint", Passed: true},
+			{Name: "gosec", Blocking: true, Errors: []parser.StructuredError{{Message: "G101"}, {Message: "G304"}}},
+			{Name: "review", Errors: []parser.StructuredError{{Message: "naming"}}},
+		},
+	}
+}
+
+func TestWriteStatus(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	last := statusRunResult()
+	history := []results.Run{
+		{At: now.Add(-5 * time.Minute), Result: last},
+		{At: now.Add(-time.Hour), Result: formatter.RunResult{Passed: true, Gates: []formatter.GateResult{{Name: "lint", Passed: true}}}},
+	}
+	out := &bytes.Buffer{}
+
+	writeStatus(out, last, now.Add(-5*time.Minute), history, now)
+
+	assertContains(t, out.String(), "❌ Last run 5m ago — failed in 4200ms")
+	assertContains(t, out.String(), "1 passed, 1 failed, 1 advisory warning")
+	assertContains(t, out.String(), "gosec failed: 2 findings")
+	assertContains(t, out.String(), "review (advisory): 1 finding")
+	assertContains(t, out.String(), "Recent runs:")
+	assertContains(t, out.String(), "passed (1 passed)")
+}
+
+func TestWriteStatusJSON(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+
+	if err := writeStatusJSON(out, statusRunResult(), at, []results.Run{{At: at, Result: statusRunResult()}}); err != nil {
+		t.Fatalf("writeStatusJSON: %v", err)
+	}
+
+	var got statusOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if !got.RecordedAt.Equal(at) || len(got.Result.Gates) != 3 || len(got.History) != 1 || got.History[0].DurationMs != 4200 {
+		t.Errorf("unexpected status: %+v", got)
+	}
+}
+
+func TestAgo(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Second: "just now",
+		5 * time.Minute:  "5m ago",
+		3 * time.Hour:    "3h ago",
+		72 * time.Hour:   "3d ago",
+	}
+	for d, want := range cases {
+		if got := ago(d); got != want {
+			t.Errorf("ago(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// splitSubmodules removes the paths of changed submodules from stagedFiles:
+// their staged change is a commit, not content gates can check. With
+// submodules: recurse, it returns the files the new commits change, relative
+// to the project root, for gates to check instead.
+func (p *Pipeline) splitSubmodules(ctx context.Context, stagedFiles []string, mode string) (files, submoduleFiles []string) {
+	log := logger.FromContext(ctx)
+	changes, err := p.Git.StagedSubmodules(ctx)
+	if err != nil {
+		log.Warn("could not list staged submodules", "error", err)
+		return stagedFiles, nil
+	}
+	if len(changes) == 0 {
+		return stagedFiles, nil
+	}
+
+	pointers := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		pointers[c.Path] = true
+	}
+	files = slices.DeleteFunc(slices.Clone(stagedFiles), func(f string) bool { return pointers[f] })
+	if mode != config.SubmodulesRecurse {
+		log.Debug("skipping staged submodule changes", "count", len(changes))
+		return files, nil
+	}
+
+	for _, c := range changes {
+		changed, err := p.Git.SubmoduleFiles(ctx, c)
+		if err != nil {
+			fmt.Fprintf(p.Stderr, "⚠️  Could not list the changes in submodule %s; gates do not check it: %v\n", c.Path, err)
+			continue
+		}
+		submoduleFiles = append(submoduleFiles, changed...)
+	}
+	return files, submoduleFiles
+}
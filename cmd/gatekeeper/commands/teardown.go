@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
@@ -12,7 +15,10 @@ import (
 var teardownCmd = &cobra.Command{
 	Use:   "teardown",
 	Short: "Remove the git pre-commit hook",
-	Long: `Remove the gatekeeper git pre-commit hook.
+	Long: `Remove the gatekeeper git pre-commit hook (and the commit-msg and
+prepare-commit-msg hooks, if installed), or the gatekeeper entry that init added to a
+husky, pre-commit, or lefthook config. A pre-commit hook saved by
+'gatekeeper init --chain' is put back.
 The .gatekeeper/ directory and configuration are preserved.`,
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		ctx := cmd.Context()
@@ -25,16 +31,44 @@ The .gatekeeper/ directory and configuration are preserved.`,
 		}
 
 		gitSvc := git.NewExecService(projectDir)
-		if err := gitSvc.RemoveHook(ctx); err != nil {
+		if err := teardown(ctx, projectDir, gitSvc, cmd.OutOrStdout()); err != nil {
 			return err
 		}
-
-		fmt.Fprintln(cmd.OutOrStdout(), "🔓 Gatekeeper pre-commit hook removed")
 		log.Info("teardown completed")
 		return nil
 	},
 }
 
+// teardown removes what init installed: gatekeeper's entries in hook manager
+// configs and its git hooks. A foreign pre-commit hook is expected when
+// gatekeeper ran through a hook manager, which owns that hook.
+func teardown(ctx context.Context, projectDir string, gitSvc git.Service, out io.Writer) error {
+	var fromManager bool
+	for _, m := range git.DetectHookManagers(projectDir) {
+		removed, err := m.Remove(projectDir)
+		if err != nil {
+			return err
+		}
+		if removed {
+			fromManager = true
+			fmt.Fprintf(out, "🔓 Removed gatekeeper from %s (%s)\n", m.Config, m.Name)
+		}
+	}
+
+	err := gitSvc.RemoveHook(ctx)
+	switch {
+	case errors.Is(err, git.ErrForeignHook) && fromManager:
+	case err != nil:
+		return err
+	case !fromManager:
+		fmt.Fprintln(out, "🔓 Gatekeeper pre-commit hook removed")
+	}
+	if err := gitSvc.RemoveMessageHook(ctx); err != nil {
+		return err
+	}
+	return gitSvc.RemoveSummaryHook(ctx)
+}
+
 func init() {
 	rootCmd.AddCommand(teardownCmd)
 }
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Browse the gate template catalog",
+	Long: `The template catalog holds ready-made gates for common tools, each with
+the container, parser, and file filters the tool needs. These commands list
+the templates and show the gate a template adds; 'gatekeeper add <template>'
+appends it to .gatekeeper/gates.yaml. Templates in
+~/.config/gatekeeper/templates/catalog/ override or extend the built-in ones.`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the gate templates",
+	Long: `List the gate templates with the type and a description of each. With
+--json, the templates are printed as JSON, including their gate YAML.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		templates := config.Templates()
+		if flagJSON {
+			return writeTemplatesJSON(cmd.OutOrStdout(), templates)
+		}
+		writeTemplates(cmd.OutOrStdout(), templates)
+		return nil
+	},
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:               "show <template>",
+	Short:             "Show the gate a template adds",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplate,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showTemplate(cmd.OutOrStdout(), args[0])
+	},
+}
+
+// templateInfo is a catalog template in templates list JSON output.
+type templateInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	YAML        string `json:"yaml"`
+}
+
+// templateType returns the gate type of a template, or "?" if its YAML does
+// not parse.
+func templateType(t config.GateTemplate) string {
+	g, err := t.Gate()
+	if err != nil {
+		return "?"
+	}
+	return string(g.Type)
+}
+
+// writeTemplates prints the templates as a table.
+func writeTemplates(out io.Writer, templates []config.GateTemplate) {
+	if len(templates) == 0 {
+		fmt.Fprintln(out, "No gate templates found")
+		return
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAME\tTYPE\tDESCRIPTION\n")
+	for _, t := range templates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Name, templateType(t), t.Description)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(out, "\nRun 'gatekeeper templates show <name>' to see a template, 'gatekeeper add <name>' to add it.")
+}
+
+// writeTemplatesJSON prints the templates as a JSON array.
+func writeTemplatesJSON(out io.Writer, templates []config.GateTemplate) error {
+	list := []templateInfo{}
+	for _, t := range templates {
+		list = append(list, templateInfo{Name: t.Name, Type: templateType(t), Description: t.Description, YAML: t.YAML})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(list); err != nil {
+		return fmt.Errorf("encoding templates: %w", err)
+	}
+	return nil
+}
+
+// showTemplate prints the description and gate YAML of the named template.
+func showTemplate(out io.Writer, name string) error {
+	t, ok := config.LookupTemplate(name)
+	if !ok {
+		return unknownTemplateError(name)
+	}
+	if t.Description != "" {
+		fmt.Fprintf(out, "# %s\n", t.Description)
+	}
+	fmt.Fprint(out, t.YAML)
+	return nil
+}
+
+// unknownTemplateError reports a template name that is not in the catalog.
+func unknownTemplateError(name string) error {
+	return fmt.Errorf("unknown template %q (run 'gatekeeper templates list' to see the available ones)", name)
+}
+
+func init() {
+	templatesCmd.AddCommand(templatesListCmd, templatesShowCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
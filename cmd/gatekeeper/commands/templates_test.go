@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func TestWriteTemplates(t *testing.T) {
+	out := &bytes.Buffer{}
+	writeTemplates(out, config.Templates())
+
+	assertContains(t, out.String(), "NAME")
+	assertContains(t, out.String(), "gosec")
+	assertContains(t, out.String(), "Go security scanner")
+	assertContains(t, out.String(), "tflint")
+	assertContains(t, out.String(), "gatekeeper add <name>")
+}
+
+func TestWriteTemplates_Empty(t *testing.T) {
+	out := &bytes.Buffer{}
+	writeTemplates(out, nil)
+	assertContains(t, out.String(), "No gate templates found")
+}
+
+func TestWriteTemplatesJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	templates := []config.GateTemplate{{Name: "lint", Description: "Lint it", YAML: "name: lint\ntype: exec\ncommand: lint\n"}}
+
+	if err := writeTemplatesJSON(out, templates); err != nil {
+		t.Fatalf("writeTemplatesJSON: %v", err)
+	}
+
+	var got []templateInfo
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(got) != 1 || got[0].Name != "lint" || got[0].Type != "exec" || got[0].Description != "Lint it" {
+		t.Errorf("unexpected templates: %+v", got)
+	}
+}
+
+func TestShowTemplate(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := showTemplate(out, "semgrep"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertContains(t, out.String(), "# Multi-language static analysis")
+	assertContains(t, out.String(), "name: semgrep")
+	assertContains(t, out.String(), "parser: sarif")
+}
+
+func TestShowTemplate_Unknown(t *testing.T) {
+	err := showTemplate(&bytes.Buffer{}, "nope")
+	if err == nil || !strings.Contains(err.Error(), "templates list") {
+		t.Fatalf("expected unknown template error, got %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/audit"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+// Flag values for the triage subcommands.
+var (
+	flagTriageStatus string
+	flagTriageReason string
+	flagTriageFormat string
+)
+
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "List findings from the last run and manage suppressions",
+	Long: `List the findings of the most recent run with their fingerprints.
+
+Findings marked as false-positive or won't-fix are stored in
+.gatekeeper/suppressions.json, filtered from future runs, and reported in a
+separate "suppressed" section. Commit the file so suppressions can be reviewed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		log := logger.FromContext(ctx)
+		log.Info("triage started")
+
+		last, store, _, err := loadTriageState()
+		if err != nil {
+			return err
+		}
+
+		if flagJSON {
+			err = writeFindingsJSON(cmd.OutOrStdout(), last, store)
+		} else {
+			listFindings(cmd.OutOrStdout(), last, store)
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Info("triage completed")
+		return nil
+	},
+}
+
+var triageMarkCmd = &cobra.Command{
+	Use:   "mark <fingerprint>",
+	Short: "Mark a finding as false-positive or won't-fix",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		last, store, path, err := loadTriageState()
+		if err != nil {
+			return err
+		}
+
+		sup, err := markFinding(store, last, args[0], flagTriageStatus, flagTriageReason, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if err := store.Save(path); err != nil {
+			return err
+		}
+
+		if projectDir, err := getwd(); err == nil {
+			reason := sup.Status
+			if sup.Reason != "" {
+				reason += ": " + sup.Reason
+			}
+			recordAudit(cmd.Context(), projectDir, audit.Entry{
+				Event:   audit.EventSuppress,
+				Gates:   []string{sup.Gate},
+				Files:   nonEmpty(sup.File),
+				Finding: sup.Fingerprint,
+				Reason:  reason,
+			})
+		}
+		logger.FromContext(cmd.Context()).Info("finding suppressed", "fingerprint", sup.Fingerprint, "status", sup.Status)
+		fmt.Fprintf(cmd.OutOrStdout(), "🔕 Suppressed %s (%s) in %s\n", sup.Fingerprint, sup.Status, sup.Gate)
+		return nil
+	},
+}
+
+var triageUnmarkCmd = &cobra.Command{
+	Use:   "unmark <fingerprint>",
+	Short: "Remove a suppression so the finding is reported again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		path := triage.DefaultPath(projectDir)
+
+		store, err := triage.Load(path)
+		if err != nil {
+			return err
+		}
+		sup, err := store.Remove(args[0])
+		if err != nil {
+			return err
+		}
+		if err := store.Save(path); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "🔔 Removed suppression %s\n", sup.Fingerprint)
+		return nil
+	},
+}
+
+var triageExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export suppressions for review (json or csv)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		store, err := triage.Load(triage.DefaultPath(projectDir))
+		if err != nil {
+			return err
+		}
+		return exportSuppressions(cmd.OutOrStdout(), store, flagTriageFormat)
+	},
+}
+
+// loadTriageState loads the last recorded run and the project's suppressions.
+func loadTriageState() (*formatter.RunResult, *triage.Store, string, error) {
+	projectDir, err := getwd()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("getting working directory: %w", err)
+	}
+
+	last, err := results.NewStore(results.DefaultDir(projectDir)).LoadLast()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	path := triage.DefaultPath(projectDir)
+	store, err := triage.Load(path)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return last, store, path, nil
+}
+
+// nonEmpty returns a one-element slice of s, or nil if s is empty.
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// triageFinding is a finding of the last run as listed by "triage --json".
+type triageFinding struct {
+	Fingerprint string `json:"fingerprint"`
+	Gate        string `json:"gate"`
+	parser.StructuredError
+	Suppressed bool   `json:"suppressed"`
+	Status     string `json:"status,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// collectFindings flattens the findings of a run, active ones first.
+func collectFindings(last *formatter.RunResult, store *triage.Store) []triageFinding {
+	var active, suppressed []triageFinding
+	for _, g := range last.Gates {
+		for _, e := range g.Errors {
+			active = append(active, triageFinding{Fingerprint: triage.Fingerprint(g.Name, e), Gate: g.Name, StructuredError: e})
+		}
+		for _, e := range g.Suppressed {
+			f := triageFinding{Fingerprint: triage.Fingerprint(g.Name, e), Gate: g.Name, StructuredError: e, Suppressed: true}
+			if sup, ok := store.Lookup(f.Fingerprint); ok {
+				f.Status, f.Reason = sup.Status, sup.Reason
+			}
+			suppressed = append(suppressed, f)
+		}
+	}
+	return append(active, suppressed...)
+}
+
+// listFindings prints the findings of the last run with their fingerprints.
+func listFindings(out io.Writer, last *formatter.RunResult, store *triage.Store) {
+	findings := collectFindings(last, store)
+	if len(findings) == 0 {
+		fmt.Fprintln(out, "✅ No findings in the last run")
+		return
+	}
+
+	header := false
+	for _, f := range findings {
+		if f.Suppressed && !header {
+			fmt.Fprintln(out, "\n🔕 Suppressed:")
+			header = true
+		}
+		fmt.Fprintf(out, "  %s  %-12s %s%s\n", f.Fingerprint, f.Gate, findingLocation(f.StructuredError), f.Message)
+		if f.Suppressed && f.Status != "" {
+			fmt.Fprintf(out, "                %s: %s\n", f.Status, f.Reason)
+		}
+	}
+}
+
+// writeFindingsJSON prints the findings of the last run as a JSON array.
+func writeFindingsJSON(out io.Writer, last *formatter.RunResult, store *triage.Store) error {
+	findings := collectFindings(last, store)
+	if findings == nil {
+		findings = []triageFinding{}
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(findings); err != nil {
+		return fmt.Errorf("encoding findings: %w", err)
+	}
+	return nil
+}
+
+// markFinding suppresses the finding of the last run matching a fingerprint or unique prefix.
+func markFinding(store *triage.Store, last *formatter.RunResult, prefix, status, reason string, now time.Time) (triage.Suppression, error) {
+	if !triage.ValidStatus(status) {
+		return triage.Suppression{}, fmt.Errorf("invalid status %q (expected %s or %s)", status, triage.StatusFalsePositive, triage.StatusWontFix)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return triage.Suppression{}, errors.New("a --reason is required")
+	}
+
+	var match *triageFinding
+	findings := collectFindings(last, store)
+	for i := range findings {
+		if !strings.HasPrefix(findings[i].Fingerprint, prefix) {
+			continue
+		}
+		if match != nil && match.Fingerprint != findings[i].Fingerprint {
+			return triage.Suppression{}, fmt.Errorf("%w: %q", triage.ErrAmbiguous, prefix)
+		}
+		match = &findings[i]
+	}
+	if match == nil {
+		return triage.Suppression{}, fmt.Errorf("no finding %q in the last run", prefix)
+	}
+
+	sup := triage.Suppression{
+		Fingerprint: match.Fingerprint,
+		Gate:        match.Gate,
+		File:        match.File,
+		Rule:        match.Rule,
+		Message:     match.Message,
+		Status:      status,
+		Reason:      strings.TrimSpace(reason),
+		CreatedAt:   now,
+	}
+	store.Add(sup)
+	return sup, nil
+}
+
+// exportSuppressions writes all suppressions in the given format.
+func exportSuppressions(out io.Writer, store *triage.Store, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(store); err != nil {
+			return fmt.Errorf("encoding suppressions: %w", err)
+		}
+		return nil
+	case "csv":
+		w := csv.NewWriter(out)
+		_ = w.Write([]string{"fingerprint", "gate", "file", "rule", "message", "status", "reason", "created_at"})
+		for _, s := range store.Suppressions {
+			_ = w.Write([]string{s.Fingerprint, s.Gate, s.File, s.Rule, s.Message, s.Status, s.Reason, s.CreatedAt.Format(time.RFC3339)})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("writing csv: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown export format %q (expected json or csv)", format)
+	}
+}
+
+// findingLocation renders "file:line " for a finding, or "" if it has no file.
+func findingLocation(e parser.StructuredError) string {
+	if e.File == "" {
+		return ""
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d ", e.File, e.Line)
+	}
+	return e.File + " "
+}
+
+func init() {
+	triageMarkCmd.Flags().StringVar(&flagTriageStatus, "status", triage.StatusFalsePositive, "Suppression status: false-positive or wont-fix")
+	triageMarkCmd.Flags().StringVar(&flagTriageReason, "reason", "", "Why the finding does not need a fix (required)")
+	triageExportCmd.Flags().StringVar(&flagTriageFormat, "format", "json", "Export format: json or csv")
+
+	triageCmd.AddCommand(triageMarkCmd, triageUnmarkCmd, triageExportCmd)
+	rootCmd.AddCommand(triageCmd)
+}
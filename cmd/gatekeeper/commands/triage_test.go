@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+)
+
+func triageRun() *formatter.RunResult {
+	return &formatter.RunResult{
+		Gates: []formatter.GateResult{
+			{
+				Name:   "security",
+				Errors: []parser.StructuredError{{File: "main.go", Line: 4, Severity: "error", Rule: "G101", Message: "hardcoded credential", Tool: "gosec"}},
+			},
+			{
+				Name:       "lint",
+				Suppressed: []parser.StructuredError{{File: "a.go", Severity: "warning", Message: "unused", Tool: "golangci-lint"}},
+			},
+		},
+	}
+}
+
+func TestMarkFinding(t *testing.T) {
+	last := triageRun()
+	store := &triage.Store{Version: 1}
+	fp := triage.Fingerprint("security", last.Gates[0].Errors[0])
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	sup, err := markFinding(store, last, fp[:6], triage.StatusWontFix, " legacy code ", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sup.Fingerprint != fp || sup.Gate != "security" || sup.Reason != "legacy code" || !sup.CreatedAt.Equal(now) {
+		t.Errorf("unexpected suppression: %+v", sup)
+	}
+	if _, ok := store.Lookup(fp); !ok {
+		t.Error("expected suppression to be stored")
+	}
+}
+
+func TestMarkFinding_Errors(t *testing.T) {
+	last := triageRun()
+	store := &triage.Store{}
+
+	if _, err := markFinding(store, last, "abc", "ignore", "why", time.Now()); err == nil || !strings.Contains(err.Error(), "invalid status") {
+		t.Errorf("expected invalid status error, got %v", err)
+	}
+	if _, err := markFinding(store, last, "abc", triage.StatusFalsePositive, "  ", time.Now()); err == nil || !strings.Contains(err.Error(), "reason") {
+		t.Errorf("expected missing reason error, got %v", err)
+	}
+	if _, err := markFinding(store, last, "zzzz", triage.StatusFalsePositive, "why", time.Now()); err == nil || !strings.Contains(err.Error(), "no finding") {
+		t.Errorf("expected unknown finding error, got %v", err)
+	}
+	if _, err := markFinding(store, last, "", triage.StatusFalsePositive, "why", time.Now()); !errors.Is(err, triage.ErrAmbiguous) {
+		t.Errorf("expected ambiguous prefix error, got %v", err)
+	}
+}
+
+func TestListFindings(t *testing.T) {
+	last := triageRun()
+	fp := triage.Fingerprint("lint", last.Gates[1].Suppressed[0])
+	store := &triage.Store{Suppressions: []triage.Suppression{{Fingerprint: fp, Status: triage.StatusFalsePositive, Reason: "generated file"}}}
+
+	out := &bytes.Buffer{}
+	listFindings(out, last, store)
+
+	assertContains(t, out.String(), "main.go:4 hardcoded credential")
+	assertContains(t, out.String(), "Suppressed:")
+	assertContains(t, out.String(), "false-positive: generated file")
+
+	out.Reset()
+	listFindings(out, &formatter.RunResult{}, store)
+	assertContains(t, out.String(), "No findings")
+}
+
+func TestWriteFindingsJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := writeFindingsJSON(out, triageRun(), &triage.Store{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var findings []triageFinding
+	if err := json.Unmarshal(out.Bytes(), &findings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(findings) != 2 || findings[0].Suppressed || !findings[1].Suppressed {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+	if findings[0].File != "main.go" || findings[0].Fingerprint == "" {
+		t.Errorf("expected flattened finding fields, got %+v", findings[0])
+	}
+}
+
+func TestExportSuppressions(t *testing.T) {
+	store := &triage.Store{Version: 1, Suppressions: []triage.Suppression{
+		{Fingerprint: "abc", Gate: "lint", Message: "says \"hi\", twice", Status: triage.StatusWontFix, Reason: "ok"},
+	}}
+
+	out := &bytes.Buffer{}
+	if err := exportSuppressions(out, store, "csv"); err != nil {
+		t.Fatalf("csv export: %v", err)
+	}
+	assertContains(t, out.String(), "fingerprint,gate,file")
+	assertContains(t, out.String(), `"says ""hi"", twice"`)
+
+	out.Reset()
+	if err := exportSuppressions(out, store, "json"); err != nil {
+		t.Fatalf("json export: %v", err)
+	}
+	assertContains(t, out.String(), `"fingerprint": "abc"`)
+
+	if err := exportSuppressions(out, store, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/update"
+	"github.com/spf13/cobra"
+)
+
+// releaseKey is the base64 ed25519 public key that signs release checksums.
+// It is set at build time via -ldflags, like version:
+//
+//	-X github.com/irahardianto/gatekeeper/cmd/gatekeeper/commands.releaseKey=<key>
+//
+// Without it, upgrade refuses to install releases it cannot verify.
+var releaseKey = ""
+
+// updateCheckTimeout bounds the background release check of a run.
+const updateCheckTimeout = 3 * time.Second
+
+var (
+	flagUpgradeCheck bool
+	flagUpgradeForce bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Replace gatekeeper with the latest release",
+	Long: `Check GitHub for the latest gatekeeper release and, if it is newer, download
+the binary for this platform, verify it against the release checksums and
+their signature, and atomically replace the running executable. An
+interrupted upgrade leaves the old binary in place. Builds made without the
+release signing key cannot verify releases and refuse to install them.
+
+--check only reports whether a newer release exists. Development builds have
+no version to compare; --force replaces them with the latest release.
+
+Runs announce a newer release at most once a week. Set update_notice: false in
+the user config to turn the notice off; it is also off in CI.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		client, err := newUpdateClient()
+		if err != nil {
+			return err
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("locating the gatekeeper binary: %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		return upgrade(cmd.Context(), cmd.OutOrStdout(), client, currentVersion(), exe, flagUpgradeCheck, flagUpgradeForce)
+	},
+}
+
+// upgrade replaces the binary at exe, of version current, with the latest
+// release. With checkOnly, it only reports the latest release.
+func upgrade(ctx context.Context, out io.Writer, client *update.Client, current, exe string, checkOnly, force bool) error {
+	log := logger.FromContext(ctx)
+
+	latest, err := client.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	saveUpdateCheck(ctx, latest.Version)
+
+	switch {
+	case current == "" && !force:
+		fmt.Fprintf(out, "This is a development build. The latest release is %s; run 'gatekeeper upgrade --force' to install it.\n", latest.Version)
+		return nil
+	case current != "" && !update.Newer(latest.Version, current) && !force:
+		fmt.Fprintf(out, "✅ gatekeeper %s is the latest release\n", current)
+		return nil
+	case checkOnly:
+		fmt.Fprintf(out, "gatekeeper %s is available (you have %s). Run 'gatekeeper upgrade' to install it.\n", latest.Version, versionLabel(current))
+		return nil
+	}
+
+	binary, err := client.Download(ctx, latest, runtime.GOOS, runtime.GOARCH)
+	if errors.Is(err, update.ErrNoPublicKey) {
+		return fmt.Errorf("this build has no release signing key, so %s cannot be verified; download it from https://github.com/irahardianto/gatekeeper/releases", latest.Version)
+	}
+	if err != nil {
+		return err
+	}
+	if err := update.Replace(exe, binary, runtime.GOOS == "windows"); err != nil {
+		return fmt.Errorf("replacing %s: %w", exe, err)
+	}
+	log.Info("gatekeeper upgraded", "from", current, "to", latest.Version, "path", exe)
+	fmt.Fprintf(out, "✅ Upgraded gatekeeper %s → %s (checksum and signature verified)\n", versionLabel(current), latest.Version)
+	return nil
+}
+
+// newUpdateClient creates the release client, with the release key of
+// release builds.
+func newUpdateClient() (*update.Client, error) {
+	client := &update.Client{HTTP: &http.Client{Timeout: 2 * time.Minute}}
+	if releaseKey != "" {
+		key, err := update.ParsePublicKey(releaseKey)
+		if err != nil {
+			return nil, err
+		}
+		client.PublicKey = key
+	}
+	return client, nil
+}
+
+// currentVersion returns the version of this binary: the version set at
+// build time for releases, the module version for 'go install' builds, or ""
+// for development builds.
+func currentVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// versionLabel names a version returned by currentVersion.
+func versionLabel(v string) string {
+	if v == "" {
+		return "a development build"
+	}
+	return v
+}
+
+// saveUpdateCheck records the latest release in the update state.
+func saveUpdateCheck(ctx context.Context, latest string) {
+	path, err := update.StatePath()
+	if err != nil {
+		return
+	}
+	state := update.LoadState(path)
+	state.CheckedAt, state.Latest = time.Now(), latest
+	if err := state.Save(path); err != nil {
+		logger.FromContext(ctx).Debug("could not save update state", "error", err)
+	}
+}
+
+// updateNotice announces newer releases after runs without delaying them.
+type updateNotice struct {
+	path    string
+	current string
+	done    chan struct{}
+}
+
+// startUpdateNotice starts a background check for a newer release if the last
+// one is a week old. It returns nil when notices are off: in the user config,
+// in CI, for output other than the CLI format, which tools read, and for
+// development builds.
+func startUpdateNotice(ctx context.Context, globalCfg *config.GlobalConfig, format string) *updateNotice {
+	current := currentVersion()
+	if (globalCfg.UpdateNotice != nil && !*globalCfg.UpdateNotice) || os.Getenv("CI") != "" || format != formatCLI || current == "" {
+		return nil
+	}
+	path, err := update.StatePath()
+	if err != nil {
+		return nil
+	}
+	n := &updateNotice{path: path, current: current, done: make(chan struct{})}
+	if !update.LoadState(path).CheckDue(time.Now()) {
+		close(n.done)
+		return n
+	}
+
+	client, err := newUpdateClient()
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), updateCheckTimeout)
+	go func() {
+		defer close(n.done)
+		defer cancel()
+		latest, err := client.Latest(ctx)
+		if err != nil {
+			logger.FromContext(ctx).Debug("update check failed", "error", err)
+			return
+		}
+		saveUpdateCheck(ctx, latest.Version)
+	}()
+	return n
+}
+
+// print writes the notice to w if the check has finished and found a release
+// not announced in the last week. An unfinished check is left for the next run.
+func (n *updateNotice) print(w io.Writer) {
+	if n == nil {
+		return
+	}
+	select {
+	case <-n.done:
+	default:
+		return
+	}
+	state := update.LoadState(n.path)
+	latest := state.Notice(n.current, time.Now())
+	if latest == "" {
+		return
+	}
+	fmt.Fprintf(w, "\n💡 gatekeeper %s is available (you have %s). Run 'gatekeeper upgrade' to update.\n", latest, n.current)
+	state.NotifiedAt = time.Now()
+	_ = state.Save(n.path)
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&flagUpgradeCheck, "check", false, "Only report whether a newer release is available")
+	upgradeCmd.Flags().BoolVar(&flagUpgradeForce, "force", false, "Install the latest release even if it is not newer (e.g., over a development build)")
+	rootCmd.AddCommand(upgradeCmd)
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/update"
+)
+
+// upgradeServer serves a signed v1.2.0 release for this platform.
+func upgradeServer(t *testing.T) *update.Client {
+	t.Helper()
+	binary := []byte("gatekeeper v1.2.0")
+	sum := sha256.Sum256(binary)
+	name := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	checksums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"tag_name":"v1.2.0","assets":[{"name":%q,"browser_download_url":"%[2]s/bin"},{"name":"checksums.txt","browser_download_url":"%[2]s/sums"},{"name":"checksums.txt.sig","browser_download_url":"%[2]s/sig"}]}`, name, srv.URL)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(checksums)) })
+	mux.HandleFunc("/sig", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(checksums)))))
+	})
+	return &update.Client{APIURL: srv.URL + "/latest", HTTP: srv.Client(), PublicKey: pub}
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	client := upgradeServer(t)
+	exe := filepath.Join(t.TempDir(), "gatekeeper")
+	if err := os.WriteFile(exe, []byte("gatekeeper v1.1.0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	out := &bytes.Buffer{}
+
+	if err := upgrade(ctx, out, client, "v1.2.0", exe, false, false); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out.String(), "v1.2.0 is the latest release")
+
+	out.Reset()
+	if err := upgrade(ctx, out, client, "", exe, false, false); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out.String(), "development build")
+
+	out.Reset()
+	if err := upgrade(ctx, out, client, "v1.1.0", exe, true, false); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out.String(), "v1.2.0 is available (you have v1.1.0)")
+	if data, _ := os.ReadFile(exe); string(data) != "gatekeeper v1.1.0" {
+		t.Errorf("--check must not replace the binary, got %q", data)
+	}
+
+	out.Reset()
+	unsigned := *client
+	unsigned.PublicKey = nil
+	err := upgrade(ctx, out, &unsigned, "v1.1.0", exe, false, false)
+	if err == nil || !strings.Contains(err.Error(), "no release signing key") {
+		t.Errorf("expected a build without the release key to refuse to install, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "gatekeeper v1.1.0" {
+		t.Errorf("an unverified release must not replace the binary, got %q", data)
+	}
+
+	out.Reset()
+	if err := upgrade(ctx, out, client, "v1.1.0", exe, false, false); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, out.String(), "Upgraded gatekeeper v1.1.0 → v1.2.0 (checksum and signature verified)")
+	if data, _ := os.ReadFile(exe); string(data) != "gatekeeper v1.2.0" {
+		t.Errorf("binary = %q, want the release", data)
+	}
+}
+
+func TestUpdateNotice_Print(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "update.json")
+	if err := (update.State{CheckedAt: time.Now(), Latest: "v1.2.0"}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	n := &updateNotice{path: path, current: "v1.1.0", done: make(chan struct{})}
+
+	out := &bytes.Buffer{}
+	n.print(out)
+	if out.Len() != 0 {
+		t.Errorf("an unfinished check must not print, got %q", out.String())
+	}
+
+	close(n.done)
+	n.print(out)
+	assertContains(t, out.String(), "gatekeeper v1.2.0 is available (you have v1.1.0)")
+
+	out.Reset()
+	n.print(out)
+	if out.Len() != 0 {
+		t.Errorf("the notice must be shown once a week, got %q", out.String())
+	}
+
+	var nilNotice *updateNotice
+	nilNotice.print(out)
+}
+
+func TestStartUpdateNotice_OffForMachineOutput(t *testing.T) {
+	t.Setenv("CI", "")
+	old := version
+	version = "v1.1.0"
+	t.Cleanup(func() { version = old })
+
+	for _, format := range []string{outputFormat(true, formatCLI), outputFormat(false, formatJSON), outputFormat(false, formatMarkdown)} {
+		if n := startUpdateNotice(context.Background(), &config.GlobalConfig{}, format); n != nil {
+			t.Errorf("format %q: expected no update notice", format)
+		}
+	}
+}
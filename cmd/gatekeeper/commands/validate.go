@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check gates.yaml for errors and performance anti-patterns",
+	Long: `Load .gatekeeper/gates.yaml and report configuration errors, then list
+advisory performance warnings: unfiltered gates in large repositories, missing
+timeouts, test caches lost with their container, LLM gates without max_file_size,
+and gates that could share a warm container.
+
+Warnings never fail the command; only configuration errors do.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+
+		projectDir, err := getwd()
+		if err != nil {
+			return fmt.Errorf("getting working directory: %w", err)
+		}
+		configPath := filepath.Join(projectDir, ".gatekeeper", "gates.yaml")
+
+		cfg, err := config.Load(ctx, configPath)
+		if err != nil {
+			return &configError{err}
+		}
+
+		var opts config.LintOptions
+		if n, err := git.NewExecService(projectDir).TrackedFileCount(ctx); err != nil {
+			logger.FromContext(ctx).Warn("counting tracked files failed, skipping repository size checks", "error", err)
+		} else {
+			opts.TrackedFiles = n
+		}
+
+		writeValidation(cmd.OutOrStdout(), cfg, config.Lint(cfg, opts))
+		return nil
+	},
+}
+
+// writeValidation prints the validation verdict followed by any lint warnings.
+func writeValidation(w io.Writer, cfg *config.GatekeeperConfig, warnings []config.LintWarning) {
+	fmt.Fprintf(w, "✅ gates.yaml is valid (%s)\n", plural(len(cfg.Gates), "gate", "gates"))
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n⚠️  %s:\n", plural(len(warnings), "performance warning", "performance warnings"))
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "  • %s\n", warning)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+}
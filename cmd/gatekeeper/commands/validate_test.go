@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func TestWriteValidation(t *testing.T) {
+	cfg := &config.GatekeeperConfig{Gates: []config.Gate{{Name: "lint"}, {Name: "test"}}}
+
+	out := &bytes.Buffer{}
+	writeValidation(out, cfg, nil)
+	if got := out.String(); got != "✅ gates.yaml is valid (2 gates)\n" {
+		t.Errorf("unexpected output for a clean config: %q", got)
+	}
+
+	out.Reset()
+	writeValidation(out, cfg, []config.LintWarning{
+		{Gate: "test", Message: "no `timeout`"},
+		{Message: "golang:1.22, golang:1.23 start separate containers"},
+	})
+	assertContains(t, out.String(), "⚠️  2 performance warnings:")
+	assertContains(t, out.String(), "  • test: no `timeout`\n")
+	assertContains(t, out.String(), "  • golang:1.22, golang:1.23 start separate containers\n")
+	if strings.Count(out.String(), "•") != 2 {
+		t.Errorf("expected 2 warnings, got:\n%s", out.String())
+	}
+}
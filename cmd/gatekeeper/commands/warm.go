@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/devcontainer"
+	"github.com/irahardianto/gatekeeper/internal/engine/gate"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/runner"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/spf13/cobra"
+)
+
+var warmCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Pull gate images and start their containers ahead of the first run",
+	Long: `Read .gatekeeper/gates.yaml (and nested configs), pull the images of all
+container gates in parallel, and start the warm containers the next run will
+reuse, including their setup commands. Run it from a direnv .envrc or a login
+hook so the first commit of the day is not slow.
+
+The gates of the selected --profile are warmed. With --snapshot or
+defaults.isolation: snapshot, the containers mount the index snapshot as runs do.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return runWarm(cmd.Context(), cmd.ErrOrStderr())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(warmCmd)
+}
+
+// runWarm wires real infrastructure and warms the containers of the project
+// in the working directory. This is a composition root.
+func runWarm(ctx context.Context, out io.Writer) error {
+	log := logger.FromContext(ctx)
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting working directory: %w", err)
+	}
+	globalCfg, err := config.LoadGlobalConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("loading global config: %w", err)
+	}
+	if flagNoDocker {
+		return errors.New("nothing to warm: --no-docker runs every gate on the host")
+	}
+	if globalCfg.Runtime == config.RuntimeKubernetes {
+		return errors.New("warm needs the docker runtime; the kubernetes runtime starts a new pod for every gate")
+	}
+
+	backend, _, err := newGateBackend(globalCfg, "", "")
+	if err != nil {
+		return err
+	}
+	if err := backend.checker.CheckDocker(ctx); err != nil {
+		return err
+	}
+
+	gitSvc := git.NewExecService(projectDir)
+	cfg, err := branchConfigLoader(gitSvc)(ctx, filepath.Join(projectDir, ".gatekeeper", "gates.yaml"))
+	if err != nil {
+		return err
+	}
+	gates := cfg.Gates
+	tracked, err := gitSvc.TrackedFiles(ctx)
+	if err != nil {
+		return err
+	}
+	nested, err := nestedConfigLoader(gitSvc, projectDir)(ctx, tracked)
+	if err != nil {
+		return err
+	}
+	gates = filterProfile(append(slices.Clip(gates), nested...), selectProfile(flagProfile, globalCfg, cfg))
+
+	// Containers are keyed by the directory they mount, so warm the one runs use.
+	workspace := projectDir
+	if flagSnapshot || cfg.Defaults.Isolation == config.IsolationSnapshot {
+		workspace, err = gitSvc.SnapshotIndex(ctx)
+		if err != nil {
+			return fmt.Errorf("snapshotting staged index: %w", err)
+		}
+		defer func() {
+			if err := gitSvc.CleanSnapshot(ctx); err != nil {
+				log.Warn("failed to clean index snapshot", "error", err)
+			}
+		}()
+	}
+
+	w := &warmer{
+		containers:   backend.docker,
+		devcontainer: devcontainer.NewPool(backend.docker, projectDir),
+		out:          out,
+		tty:          isTerminal(out) && !accessibleOutput(globalCfg),
+	}
+	return w.warm(ctx, gates, workspace)
+}
+
+// warmer pulls the images of gates and starts their containers.
+type warmer struct {
+	containers   containerPool
+	devcontainer devcontainerPool
+	out          io.Writer
+	// tty draws pull progress bars in place.
+	tty bool
+}
+
+// warm pulls the images of gates in parallel and starts their containers with
+// workspace mounted.
+func (w *warmer) warm(ctx context.Context, gates []config.Gate, workspace string) error {
+	targets := gateContainers(gates)
+	if len(targets) == 0 {
+		fmt.Fprintln(w.out, "✅ No container gates to warm")
+		return nil
+	}
+
+	var images []string
+	for _, t := range targets {
+		img := t.spec.Image
+		if t.devcontainer {
+			dev, err := w.devcontainer.Spec()
+			if err != nil {
+				return err
+			}
+			img = dev.Image
+		}
+		if !slices.Contains(images, img) {
+			images = append(images, img)
+		}
+	}
+
+	bars := runner.NewPullBars(w.out, images, w.tty)
+	err := w.containers.PullImages(ctx, images, bars.Update)
+	bars.Finish()
+	if err != nil {
+		return err
+	}
+
+	// Setup commands run as each container starts, one at a time. Ephemeral
+	// containers only have their image pulled: each run starts its own.
+	started := 0
+	for _, t := range targets {
+		if t.ephemeral {
+			continue
+		}
+		var containers gate.SpecPoolManager = w.containers
+		name := t.spec.Image
+		if t.devcontainer {
+			containers, name = w.devcontainer, config.ContainerDevcontainer
+		}
+		if t.spec.Setup != "" {
+			fmt.Fprintf(w.out, "🔧 Starting %s and running its setup...\n", name)
+		}
+		if _, err := containers.GetOrCreateSpec(ctx, t.spec, workspace, t.writable); err != nil {
+			return fmt.Errorf("starting container for %s: %w", name, err)
+		}
+		started++
+	}
+	fmt.Fprintf(w.out, "🔥 %d container(s) warm\n", started)
+	return nil
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
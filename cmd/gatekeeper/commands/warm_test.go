@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// recordingPool records the containers it is asked to start.
+type recordingPool struct {
+	started []string
+	spec    pool.ContainerSpec
+	pulled  []string
+	warm    []string
+}
+
+func (r *recordingPool) GetOrCreateSpec(_ context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error) {
+	desc := spec.Image + " " + projectPath
+	if spec.Setup != "" {
+		desc += " setup=" + spec.Setup
+	}
+	if writable {
+		desc += " writable"
+	}
+	r.started = append(r.started, desc)
+	return "id", nil
+}
+
+func (r *recordingPool) Spec() (pool.ContainerSpec, error) {
+	return r.spec, nil
+}
+
+func (r *recordingPool) PullImages(_ context.Context, images []string, report func(pool.PullProgress)) error {
+	r.pulled = append(r.pulled, images...)
+	for _, img := range images {
+		report(pool.PullProgress{Image: img, Done: true})
+	}
+	return nil
+}
+
+func (r *recordingPool) IsWarm(_ context.Context, spec pool.ContainerSpec, _ string, _ bool) (bool, error) {
+	return slices.Contains(r.warm, spec.Image), nil
+}
+
+func TestWarmer_Warm(t *testing.T) {
+	containers := &recordingPool{}
+	dev := &recordingPool{spec: pool.ContainerSpec{Image: "mcr.microsoft.com/devcontainers/go:1"}}
+	var out bytes.Buffer
+	w := &warmer{containers: containers, devcontainer: dev, out: &out}
+
+	gates := []config.Gate{
+		{Name: "lint", Type: config.GateTypeExec, Container: "golangci/golangci-lint:v1.62"},
+		{Name: "vet", Type: config.GateTypeExec, Container: "golangci/golangci-lint:v1.62"},
+		{Name: "fmt", Type: config.GateTypeExec, Container: "golang:1.25", Writable: true},
+		{Name: "test", Type: config.GateTypeScript, Container: "golang:1.25", Setup: "go mod download"},
+		{Name: "dev", Type: config.GateTypeExec, Container: config.ContainerDevcontainer},
+		{Name: "host", Type: config.GateTypeExec, Container: config.ContainerLocal},
+		{Name: "review", Type: config.GateTypeLLM},
+		{Name: "scan", Type: config.GateTypeExec, Container: "aquasec/trivy:0.58", Reuse: config.ReuseEphemeral},
+	}
+	if err := w.warm(context.Background(), gates, "/proj"); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+
+	want := []string{
+		"golangci/golangci-lint:v1.62 /proj",
+		"golang:1.25 /proj writable",
+		"golang:1.25 /proj setup=go mod download",
+	}
+	if strings.Join(containers.started, "\n") != strings.Join(want, "\n") {
+		t.Errorf("started %q, want %q", containers.started, want)
+	}
+	if len(dev.started) != 1 {
+		t.Errorf("expected the dev container to be started, got %q", dev.started)
+	}
+	for _, s := range []string{"Pulling 4 image(s)", "aquasec/trivy:0.58", "mcr.microsoft.com/devcontainers/go:1", "🔥 4 container(s) warm"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestWarmer_NoContainerGates(t *testing.T) {
+	var out bytes.Buffer
+	w := &warmer{containers: &recordingPool{}, out: &out}
+	gates := []config.Gate{{Name: "review", Type: config.GateTypeLLM}}
+	if err := w.warm(context.Background(), gates, "/proj"); err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	if !strings.Contains(out.String(), "No container gates") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestImagePuller_PullsColdImages(t *testing.T) {
+	containers := &recordingPool{warm: []string{"golang:1.25"}}
+	var out bytes.Buffer
+	puller := &imagePuller{pool: containers, projectDir: "/proj", out: &out}
+
+	gates := []config.Gate{
+		{Name: "lint", Type: config.GateTypeExec, Container: "golangci/golangci-lint:v1.62"},
+		{Name: "test", Type: config.GateTypeExec, Container: "golang:1.25"},
+		{Name: "node", Type: config.GateTypeExec, Container: "node:22"},
+		{Name: "dev", Type: config.GateTypeExec, Container: config.ContainerDevcontainer},
+		{Name: "race", Type: config.GateTypeExec, Container: "golang:1.25", Reuse: config.ReuseEphemeral},
+	}
+	if err := puller.PullImages(context.Background(), gates, ""); err != nil {
+		t.Fatalf("PullImages: %v", err)
+	}
+	// Ephemeral containers always start cold, even if a warm one shares the image.
+	if got := strings.Join(containers.pulled, " "); got != "golangci/golangci-lint:v1.62 node:22 golang:1.25" {
+		t.Errorf("pulled %q, want the cold images", got)
+	}
+
+	out.Reset()
+	containers.pulled = nil
+	if err := puller.PullImages(context.Background(), gates[1:2], ""); err != nil || len(containers.pulled) > 0 || out.Len() > 0 {
+		t.Errorf("expected nothing to pull for warm containers, got %q (%v)", containers.pulled, err)
+	}
+}
@@ -8,7 +8,5 @@ import (
 )
 
 func main() {
-	if err := commands.Execute(); err != nil {
-		os.Exit(1)
-	}
+	os.Exit(commands.Execute())
 }
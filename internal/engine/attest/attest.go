@@ -0,0 +1,173 @@
+// Package attest signs run results so that a server or CI job can verify that
+// the gates ran and passed on a developer's machine before accepting a push.
+//
+// An attestation binds a passing run to the git tree it checked. It is stored
+// as a git note on the commit (NotesRef), which is pushed alongside it.
+package attest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+// StatementType identifies the format of a Statement.
+const StatementType = "https://github.com/irahardianto/gatekeeper/attestation/v1"
+
+// NotesRef is the git notes ref attestations are stored under.
+const NotesRef = "refs/notes/gatekeeper"
+
+var (
+	// ErrNoTree is returned when a run did not record the tree it checked.
+	ErrNoTree = errors.New("the run did not record the git tree it checked")
+	// ErrTreeMismatch is returned when an attestation is for a different tree.
+	ErrTreeMismatch = errors.New("attestation is for a different tree")
+	// ErrFailed is returned when the attested run did not pass.
+	ErrFailed = errors.New("attested run did not pass")
+	// ErrBadSignature is returned when no trusted key or identity signed the statement.
+	ErrBadSignature = errors.New("attestation signature does not verify")
+)
+
+// Statement is the signed claim about a run.
+type Statement struct {
+	Type string `json:"_type"`
+	// Tree is the git tree the gates checked.
+	Tree   string        `json:"tree"`
+	Passed bool          `json:"passed"`
+	Gates  []GateOutcome `json:"gates"`
+	// ResultSHA256 is the digest of the full recorded run result.
+	ResultSHA256 string `json:"result_sha256"`
+	// GatekeeperVersion is the version of gatekeeper that ran the gates.
+	GatekeeperVersion string    `json:"gatekeeper_version"`
+	SignedAt          time.Time `json:"signed_at"`
+}
+
+// GateOutcome is the result of one gate, as attested.
+type GateOutcome struct {
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	Blocking   bool   `json:"blocking"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+}
+
+// Attestation is a signed Statement. Statement holds the exact signed bytes.
+type Attestation struct {
+	Statement []byte `json:"statement"`
+	// KeyID and Signature are set for ed25519 key signatures.
+	KeyID     string `json:"key_id,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+	// Bundle is the Sigstore bundle of a keyless signature.
+	Bundle json.RawMessage `json:"sigstore_bundle,omitempty"`
+}
+
+// Signer signs the statement of an attestation, setting its signature fields.
+type Signer interface {
+	Sign(ctx context.Context, att *Attestation) error
+}
+
+// Verifier checks that a trusted key or identity signed an attestation.
+// It returns a description of the signer.
+type Verifier interface {
+	Verify(ctx context.Context, att *Attestation) (string, error)
+}
+
+// NewStatement describes a recorded run.
+func NewStatement(result formatter.RunResult, version string, now time.Time) (Statement, error) {
+	if result.Tree == "" {
+		return Statement{}, ErrNoTree
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return Statement{}, fmt.Errorf("encoding run result: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	stmt := Statement{
+		Type:              StatementType,
+		Tree:              result.Tree,
+		Passed:            result.Passed,
+		ResultSHA256:      hex.EncodeToString(sum[:]),
+		GatekeeperVersion: version,
+		SignedAt:          now.UTC(),
+	}
+	for _, g := range result.Gates {
+		stmt.Gates = append(stmt.Gates, GateOutcome{
+			Name:       g.Name,
+			Passed:     g.Passed && g.SystemError == "",
+			Blocking:   g.Blocking,
+			Skipped:    g.Skipped,
+			SkipReason: g.SkipReason,
+		})
+	}
+	return stmt, nil
+}
+
+// Seal signs a statement.
+func Seal(ctx context.Context, stmt Statement, signer Signer) (*Attestation, error) {
+	data, err := json.Marshal(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("encoding statement: %w", err)
+	}
+	att := &Attestation{Statement: data}
+	if err := signer.Sign(ctx, att); err != nil {
+		return nil, fmt.Errorf("signing attestation: %w", err)
+	}
+	return att, nil
+}
+
+// Open verifies the signature of an attestation and returns its statement and
+// a description of the signer. It does not check what the statement claims;
+// see Statement.Check.
+func Open(ctx context.Context, att *Attestation, verifier Verifier) (*Statement, string, error) {
+	signer, err := verifier.Verify(ctx, att)
+	if err != nil {
+		return nil, "", err
+	}
+	var stmt Statement
+	if err := json.Unmarshal(att.Statement, &stmt); err != nil {
+		return nil, "", fmt.Errorf("decoding statement: %w", err)
+	}
+	if stmt.Type != StatementType {
+		return nil, "", fmt.Errorf("unsupported statement type %q", stmt.Type)
+	}
+	return &stmt, signer, nil
+}
+
+// Check verifies that the statement attests a passing run of tree.
+func (s *Statement) Check(tree string) error {
+	if s.Tree != tree {
+		return fmt.Errorf("%w: attested %s, commit has %s", ErrTreeMismatch, s.Tree, tree)
+	}
+	if !s.Passed {
+		return ErrFailed
+	}
+	return nil
+}
+
+// Encode returns the JSON encoding of an attestation.
+func Encode(att *Attestation) ([]byte, error) {
+	data, err := json.Marshal(att)
+	if err != nil {
+		return nil, fmt.Errorf("encoding attestation: %w", err)
+	}
+	return data, nil
+}
+
+// Decode parses an encoded attestation.
+func Decode(data []byte) (*Attestation, error) {
+	var att Attestation
+	if err := json.Unmarshal(data, &att); err != nil {
+		return nil, fmt.Errorf("decoding attestation: %w", err)
+	}
+	if len(att.Statement) == 0 {
+		return nil, errors.New("decoding attestation: no statement")
+	}
+	return &att, nil
+}
@@ -0,0 +1,179 @@
+package attest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+func passingRun() formatter.RunResult {
+	return formatter.RunResult{
+		Passed: true,
+		Tree:   "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		Gates: []formatter.GateResult{
+			{Name: "lint", Passed: true, Blocking: true},
+			{Name: "review", Passed: true, Skipped: true, SkipReason: "requested by GATEKEEPER_SKIP"},
+		},
+	}
+}
+
+func newKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+func TestSealAndOpen(t *testing.T) {
+	ctx := context.Background()
+	pub, priv := newKey(t)
+	other, _ := newKey(t)
+
+	stmt, err := NewStatement(passingRun(), "v1.0.0", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := Seal(ctx, stmt, NewKeySigner(priv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Encode(att)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, signer, err := Open(ctx, decoded, NewKeyVerifier([]ed25519.PublicKey{other, pub}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if signer != "key "+KeyID(pub) {
+		t.Errorf("signer = %q", signer)
+	}
+	if got.Tree != stmt.Tree || len(got.Gates) != 2 || !got.Gates[1].Skipped || got.ResultSHA256 == "" {
+		t.Errorf("unexpected statement: %+v", got)
+	}
+
+	if _, _, err := Open(ctx, decoded, NewKeyVerifier([]ed25519.PublicKey{other})); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("expected ErrBadSignature for an untrusted key, got %v", err)
+	}
+	decoded.Statement = []byte(strings.Replace(string(decoded.Statement), `"passed":true`, `"passed":false`, 1))
+	if _, _, err := Open(ctx, decoded, NewKeyVerifier([]ed25519.PublicKey{pub})); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("expected ErrBadSignature for a tampered statement, got %v", err)
+	}
+}
+
+func TestStatement_Check(t *testing.T) {
+	stmt, err := NewStatement(passingRun(), "dev", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stmt.Check(stmt.Tree); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := stmt.Check("other"); !errors.Is(err, ErrTreeMismatch) {
+		t.Errorf("expected ErrTreeMismatch, got %v", err)
+	}
+	stmt.Passed = false
+	if err := stmt.Check(stmt.Tree); !errors.Is(err, ErrFailed) {
+		t.Errorf("expected ErrFailed, got %v", err)
+	}
+
+	run := passingRun()
+	run.Tree = ""
+	if _, err := NewStatement(run, "dev", time.Now()); !errors.Is(err, ErrNoTree) {
+		t.Errorf("expected ErrNoTree, got %v", err)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "attestation.key")
+	pub, err := GenerateKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("key mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := GenerateKey(path); err == nil {
+		t.Error("expected an existing key not to be replaced")
+	}
+
+	priv, err := LoadPrivateKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(priv.Public()) {
+		t.Error("loaded key does not match the generated one")
+	}
+
+	other, _ := newKey(t)
+	pemA, _ := EncodePublicKey(pub)
+	pemB, _ := EncodePublicKey(other)
+	keys, err := ParsePublicKeys(append(pemA, pemB...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || !keys[0].Equal(pub) || !keys[1].Equal(other) {
+		t.Errorf("parsed %d keys", len(keys))
+	}
+	if _, err := ParsePublicKeys([]byte("not a key")); err == nil {
+		t.Error("expected an error without PEM blocks")
+	}
+}
+
+func TestKeyless(t *testing.T) {
+	ctx := context.Background()
+	var calls [][]string
+	fake := func(_ context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if args[0] == "sign-blob" {
+			bundle := args[slices.Index(args, "--bundle")+1]
+			return nil, os.WriteFile(bundle, []byte(`{"mediaType":"bundle"}`), 0o600)
+		}
+		return nil, nil
+	}
+
+	stmt, err := NewStatement(passingRun(), "dev", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	att, err := Seal(ctx, stmt, &KeylessSigner{run: fake})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(att.Bundle) != `{"mediaType":"bundle"}` || att.Signature != nil {
+		t.Fatalf("unexpected attestation: %+v", att)
+	}
+
+	verifier := &KeylessVerifier{identity: "dev@example.com", issuer: "https://accounts.google.com", run: fake}
+	if _, signer, err := Open(ctx, att, verifier); err != nil || signer != "dev@example.com" {
+		t.Fatalf("Open = %q, %v", signer, err)
+	}
+	verify := strings.Join(calls[1], " ")
+	if !strings.Contains(verify, "--certificate-identity dev@example.com --certificate-oidc-issuer https://accounts.google.com") {
+		t.Errorf("unexpected verify-blob arguments: %s", verify)
+	}
+
+	if _, err := (&KeylessVerifier{run: fake}).Verify(ctx, &Attestation{Statement: att.Statement}); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("expected ErrBadSignature without a bundle, got %v", err)
+	}
+}
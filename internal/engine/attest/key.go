@@ -0,0 +1,156 @@
+package attest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KeyID returns a short identifier of a public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeySigner signs attestations with an ed25519 private key.
+type KeySigner struct {
+	key ed25519.PrivateKey
+}
+
+// NewKeySigner creates a signer for key.
+func NewKeySigner(key ed25519.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+// Sign sets the key id and signature of att.
+func (s *KeySigner) Sign(_ context.Context, att *Attestation) error {
+	pub, ok := s.key.Public().(ed25519.PublicKey)
+	if !ok {
+		return errors.New("invalid ed25519 key")
+	}
+	att.KeyID = KeyID(pub)
+	att.Signature = ed25519.Sign(s.key, att.Statement)
+	att.Bundle = nil
+	return nil
+}
+
+// KeyVerifier accepts attestations signed by any of a set of ed25519 keys.
+type KeyVerifier struct {
+	keys []ed25519.PublicKey
+}
+
+// NewKeyVerifier creates a verifier that trusts keys.
+func NewKeyVerifier(keys []ed25519.PublicKey) *KeyVerifier {
+	return &KeyVerifier{keys: keys}
+}
+
+// Verify checks the signature of att against the trusted keys and returns
+// the id of the key that signed it.
+func (v *KeyVerifier) Verify(_ context.Context, att *Attestation) (string, error) {
+	if len(att.Signature) == 0 {
+		return "", fmt.Errorf("%w: not signed with a key", ErrBadSignature)
+	}
+	for _, pub := range v.keys {
+		if ed25519.Verify(pub, att.Statement, att.Signature) {
+			return "key " + KeyID(pub), nil
+		}
+	}
+	return "", fmt.Errorf("%w: no trusted key matches (signed by key %s)", ErrBadSignature, att.KeyID)
+}
+
+// GenerateKey creates an ed25519 key pair, writes the private key to path as
+// PKCS #8 PEM, and returns the public key. An existing file is never replaced.
+func GenerateKey(path string) (ed25519.PublicKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, fmt.Errorf("encoding key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("creating key directory: %w", err)
+	}
+	// [SEC] The private key is created owner-only, and O_EXCL keeps an existing key.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- path is the user's configured key file
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", path, err)
+	}
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return pub, nil
+}
+
+// LoadPrivateKey reads a PKCS #8 PEM ed25519 private key.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is the user's configured key file
+	if err != nil {
+		return nil, fmt.Errorf("reading signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: expected a PEM \"PRIVATE KEY\" block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an ed25519 key", path)
+	}
+	return priv, nil
+}
+
+// EncodePublicKey returns the PKIX PEM encoding of a public key.
+func EncodePublicKey(pub ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("encoding public key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParsePublicKeys parses every PEM "PUBLIC KEY" block of data, so one file
+// can list all trusted developer keys.
+func ParsePublicKeys(data []byte) ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing public key: %w", err)
+		}
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("parsing public key: not an ed25519 key")
+		}
+		keys = append(keys, pub)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM \"PUBLIC KEY\" block found")
+	}
+	return keys, nil
+}
@@ -0,0 +1,94 @@
+package attest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// runCosign runs the cosign CLI and returns its combined output.
+func runCosign(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "cosign", args...) // #nosec G204 -- fixed subcommands; file arguments are temporary paths
+	cmd.Stdin = os.Stdin                               // keyless signing may prompt to open a browser
+	out, err := cmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return out, errors.New("keyless attestations need the cosign CLI on PATH (https://docs.sigstore.dev)")
+	}
+	return out, err
+}
+
+// KeylessSigner signs attestations with Sigstore through the cosign CLI: the
+// signature is bound to the signer's OIDC identity and logged in Rekor.
+type KeylessSigner struct {
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// NewKeylessSigner creates a Sigstore keyless signer.
+func NewKeylessSigner() *KeylessSigner {
+	return &KeylessSigner{run: runCosign}
+}
+
+// Sign sets the Sigstore bundle of att.
+func (s *KeylessSigner) Sign(ctx context.Context, att *Attestation) error {
+	dir, err := os.MkdirTemp("", "gatekeeper-attest-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	payload, bundle := filepath.Join(dir, "statement.json"), filepath.Join(dir, "bundle.json")
+	if err := os.WriteFile(payload, att.Statement, 0o600); err != nil {
+		return err
+	}
+	if out, err := s.run(ctx, "sign-blob", "--yes", "--bundle", bundle, payload); err != nil {
+		return fmt.Errorf("cosign sign-blob: %w\n%s", err, out)
+	}
+	data, err := os.ReadFile(bundle) // #nosec G304 -- path inside our temporary directory
+	if err != nil {
+		return fmt.Errorf("reading sigstore bundle: %w", err)
+	}
+	att.Bundle, att.KeyID, att.Signature = data, "", nil
+	return nil
+}
+
+// KeylessVerifier accepts attestations signed keylessly by one OIDC identity.
+type KeylessVerifier struct {
+	identity string
+	issuer   string
+	run      func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// NewKeylessVerifier creates a verifier that trusts the certificate identity
+// (e.g., an email address) issued by the OIDC issuer.
+func NewKeylessVerifier(identity, issuer string) *KeylessVerifier {
+	return &KeylessVerifier{identity: identity, issuer: issuer, run: runCosign}
+}
+
+// Verify checks the Sigstore bundle of att and returns the trusted identity.
+func (v *KeylessVerifier) Verify(ctx context.Context, att *Attestation) (string, error) {
+	if len(att.Bundle) == 0 {
+		return "", fmt.Errorf("%w: not signed keylessly", ErrBadSignature)
+	}
+	dir, err := os.MkdirTemp("", "gatekeeper-verify-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	payload, bundle := filepath.Join(dir, "statement.json"), filepath.Join(dir, "bundle.json")
+	if err := os.WriteFile(payload, att.Statement, 0o600); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(bundle, att.Bundle, 0o600); err != nil {
+		return "", err
+	}
+	out, err := v.run(ctx, "verify-blob", "--bundle", bundle,
+		"--certificate-identity", v.identity, "--certificate-oidc-issuer", v.issuer, payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v\n%s", ErrBadSignature, err, out)
+	}
+	return v.identity, nil
+}
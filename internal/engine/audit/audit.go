@@ -0,0 +1,164 @@
+// Package audit keeps an append-only log of security-relevant gatekeeper events.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Event types recorded in the audit log.
+const (
+	// EventRun records the outcome of a run.
+	EventRun = "run"
+	// EventSkip records gates skipped through an escape hatch.
+	EventSkip = "skip"
+	// EventFix records files that writable gates modified and that were re-staged.
+	EventFix = "fix"
+	// EventDismiss records an LLM finding dismissed with "gatekeeper dismiss".
+	EventDismiss = "dismiss"
+	// EventSuppress records a finding suppressed with "gatekeeper triage mark".
+	EventSuppress = "suppress"
+	// EventOverride records a commit allowed on a branch the branch rules
+	// block, with "gatekeeper run --allow-branch".
+	EventOverride = "override"
+)
+
+// maxLineSize bounds the length of a line read from the log.
+const maxLineSize = 1 << 20
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`
+	User   string    `json:"user,omitempty"`
+	Branch string    `json:"branch,omitempty"`
+	// Gates are the gates the event applies to.
+	Gates []string `json:"gates,omitempty"`
+	// Source is where the event was requested, e.g. "GATEKEEPER_SKIP".
+	Source string `json:"source,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// Passed is the outcome of a run.
+	Passed *bool `json:"passed,omitempty"`
+	// DurationMs is the duration of a run.
+	DurationMs int64 `json:"duration_ms,omitempty"`
+	// Files are the files of a fix, or the file of a dismissed finding.
+	Files []string `json:"files,omitempty"`
+	// Finding is the fingerprint or message of a dismissed or suppressed finding.
+	Finding string `json:"finding,omitempty"`
+}
+
+// Log appends entries to a JSONL file. Entries are never rewritten.
+type Log struct {
+	path string
+	now  func() time.Time
+	user func() string
+
+	mu sync.Mutex
+}
+
+// NewLog creates a Log writing to path.
+func NewLog(path string) *Log {
+	return &Log{path: path, now: time.Now, user: currentUser}
+}
+
+// DefaultPath returns the audit log path for a project.
+func DefaultPath(projectDir string) string {
+	return filepath.Join(projectDir, ".gatekeeper", "audit.log")
+}
+
+// Path returns the audit log path for a project: configured if set (relative
+// paths are resolved against the project), else DefaultPath.
+func Path(projectDir, configured string) string {
+	switch {
+	case configured == "":
+		return DefaultPath(projectDir)
+	case filepath.IsAbs(configured):
+		return filepath.Clean(configured)
+	default:
+		return filepath.Join(projectDir, configured)
+	}
+}
+
+// Path returns the file the log writes to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends entry, filling in its time and user if unset.
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = l.now().UTC()
+	}
+	if entry.User == "" {
+		entry.User = l.user()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("creating audit log directory: %w", err)
+	}
+	// A single O_APPEND write keeps concurrent writers from interleaving lines.
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 -- path is the project's audit log
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// currentUser returns the login name of the current user, or "" if unknown.
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
+
+// Read returns the entries of the audit log at path, oldest first. A missing
+// log has no entries. Lines that are not valid entries (e.g., a write torn by
+// a crash) are skipped.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path) // #nosec G304 -- path is the project's audit log
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Event == "" {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	return entries, nil
+}
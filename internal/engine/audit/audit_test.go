@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_RecordAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gatekeeper", "audit.log")
+	log := NewLog(path)
+	log.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	log.user = func() string { return "dev" }
+
+	if err := log.Record(Entry{Event: EventSkip, Gates: []string{"lint"}, Source: "GATEKEEPER_SKIP"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Record(Entry{Event: EventSkip, User: "ci", Gates: []string{"test"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].User != "dev" || entries[0].Time.IsZero() || entries[0].Source != "GATEKEEPER_SKIP" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].User != "ci" {
+		t.Errorf("explicit user must be kept, got %q", entries[1].User)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("audit log mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	entries, err := Read(path)
+	if err != nil || entries != nil {
+		t.Fatalf("missing log: got %v, %v", entries, err)
+	}
+
+	data := `{"time":"2026-03-01T12:00:00Z","event":"skip","gates":["lint"]}
+{"time":"2026-03-01T12:01:00Z","event":"run","passed":true}
+{"time":"2026-03-01T12:02:00Z","ev`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Event != EventSkip || entries[1].Passed == nil || !*entries[1].Passed {
+		t.Errorf("unexpected entries (torn last line must be skipped): %+v", entries)
+	}
+}
+
+func TestPath(t *testing.T) {
+	project := filepath.Join("/", "repo")
+	tests := []struct {
+		configured string
+		want       string
+	}{
+		{"", filepath.Join(project, ".gatekeeper", "audit.log")},
+		{"logs/audit.jsonl", filepath.Join(project, "logs", "audit.jsonl")},
+		{filepath.Join("/", "var", "log", "gatekeeper.log"), filepath.Join("/", "var", "log", "gatekeeper.log")},
+	}
+	for _, tt := range tests {
+		if got := Path(project, tt.configured); got != tt.want {
+			t.Errorf("Path(%q) = %q, want %q", tt.configured, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
+)
+
+// CI providers that init can generate a workflow for.
+const (
+	CIGitHub   = "github"
+	CIGitLab   = "gitlab"
+	CICircleCI = "circleci"
+)
+
+// ciPaths maps each CI provider to the workflow file generated for it,
+// relative to the project directory.
+var ciPaths = map[string]string{
+	CIGitHub:   ".github/workflows/gatekeeper.yml",
+	CIGitLab:   ".gitlab/ci/gatekeeper.yml",
+	CICircleCI: ".circleci/config.yml",
+}
+
+// CIProviders returns the names of the supported CI providers.
+func CIProviders() []string {
+	return []string{CIGitHub, CIGitLab, CICircleCI}
+}
+
+// CIWorkflow is a generated CI workflow file.
+type CIWorkflow struct {
+	// Path is the file to write, slash-separated and relative to the project directory.
+	Path    string
+	Content string
+}
+
+// GenerateCIWorkflow produces the workflow of a CI provider that runs
+// 'gatekeeper ci'. With local, the gates run on the CI runner (--no-docker)
+// instead of in Docker. The workflows come from the ci/ assets, which users
+// can override; they are templates with [[ ]] delimiters, so the ${{ }} and
+// {{ }} syntax of the CI providers passes through.
+func GenerateCIWorkflow(provider string, local bool) (CIWorkflow, error) {
+	path, ok := ciPaths[provider]
+	if !ok {
+		return CIWorkflow{}, fmt.Errorf("unknown CI provider %q (want %s)", provider, strings.Join(CIProviders(), ", "))
+	}
+	text, err := assets.Read("ci/" + provider + ".yaml")
+	if err != nil {
+		return CIWorkflow{}, err
+	}
+	tmpl, err := template.New(provider).Delims("[[", "]]").Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return CIWorkflow{}, fmt.Errorf("parsing %s workflow template: %w", provider, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, struct{ Local bool }{local}); err != nil {
+		return CIWorkflow{}, fmt.Errorf("rendering %s workflow template: %w", provider, err)
+	}
+	content := b.String()
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return CIWorkflow{Path: path, Content: content}, nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateCIWorkflow(t *testing.T) {
+	for _, provider := range CIProviders() {
+		for _, local := range []bool{false, true} {
+			w, err := GenerateCIWorkflow(provider, local)
+			if err != nil {
+				t.Fatalf("%s (local=%v): %v", provider, local, err)
+			}
+			if w.Path != ciPaths[provider] {
+				t.Errorf("%s: path %q, want %q", provider, w.Path, ciPaths[provider])
+			}
+			var doc map[string]any
+			if err := yaml.Unmarshal([]byte(w.Content), &doc); err != nil {
+				t.Errorf("%s (local=%v): generated workflow is not valid YAML: %v\n%s", provider, local, err, w.Content)
+			}
+			if !strings.Contains(w.Content, "gatekeeper ci") {
+				t.Errorf("%s: workflow does not run gatekeeper ci:\n%s", provider, w.Content)
+			}
+			if got := strings.Contains(w.Content, "--no-docker"); got != local {
+				t.Errorf("%s (local=%v): --no-docker present = %v", provider, local, got)
+			}
+			if got := strings.Contains(w.Content, "docker save"); got == local {
+				t.Errorf("%s (local=%v): image caching present = %v", provider, local, got)
+			}
+		}
+	}
+}
+
+func TestGenerateCIWorkflow_KeepsProviderSyntax(t *testing.T) {
+	w, err := GenerateCIWorkflow(CIGitHub, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"${{ runner.os }}", "{{.Repository}}:{{.Tag}}"} {
+		if !strings.Contains(w.Content, want) {
+			t.Errorf("expected %q to pass through the template:\n%s", want, w.Content)
+		}
+	}
+}
+
+func TestGenerateCIWorkflow_UnknownProvider(t *testing.T) {
+	if _, err := GenerateCIWorkflow("jenkins", false); err == nil || !strings.Contains(err.Error(), "github, gitlab, circleci") {
+		t.Errorf("expected an error listing the providers, got %v", err)
+	}
+}
@@ -3,14 +3,22 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/secrets"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,6 +29,86 @@ const (
 	GateTypeExec   GateType = "exec"
 	GateTypeScript GateType = "script"
 	GateTypeLLM    GateType = "llm"
+	// GateTypeCoverage runs a test command with coverage and checks the coverage
+	// of the changed files.
+	GateTypeCoverage GateType = "coverage"
+	// GateTypePolicy checks the staged diff against built-in rules, such as a
+	// size limit and forbidden files, on the host without a container.
+	GateTypePolicy GateType = "policy"
+)
+
+// LLM review modes for the "mode" field of llm gates.
+const (
+	// LLMModeDiff reviews the staged hunks only (the default).
+	LLMModeDiff = "diff"
+	// LLMModeFullContext reviews the diff alongside the full staged content of changed files.
+	LLMModeFullContext = "full_context"
+	// LLMModeDocDrift checks that the doc comments of changed code still describe
+	// it, with a built-in prompt; prompt adds optional rules.
+	LLMModeDocDrift = "doc_drift"
+)
+
+// Handling of LLM findings below min_confidence, for the "low_confidence" field.
+const (
+	// LowConfidenceWarn downgrades low-confidence errors to warnings (the default).
+	LowConfidenceWarn = "warn"
+	// LowConfidenceDrop discards low-confidence findings.
+	LowConfidenceDrop = "drop"
+)
+
+// Ways to isolate the staged changes for gates, for the "defaults.isolation" field.
+const (
+	// IsolationStash stashes unstaged changes in the working tree during the run (the default).
+	IsolationStash = "stash"
+	// IsolationSnapshot checks the staged index out into a separate directory
+	// and runs container gates there, leaving the working tree untouched.
+	IsolationSnapshot = "snapshot"
+)
+
+// Handling of staged submodule changes, for the "defaults.submodules" field.
+const (
+	// SubmodulesSkip leaves submodule changes out of the files gates check (the default).
+	SubmodulesSkip = "skip"
+	// SubmodulesRecurse has gates check the files changed by the new commits
+	// of changed submodules, including the gates of their own gates.yaml.
+	SubmodulesRecurse = "recurse"
+)
+
+// Handling of files modified by writable gates, for the "writable_policy" field.
+const (
+	// WritablePolicyRevert reverts the modifications after the run (the default).
+	WritablePolicyRevert = "revert"
+	// WritablePolicyApply re-stages modified files that were staged for the commit.
+	WritablePolicyApply = "apply"
+)
+
+// Container reuse policies of exec and script gates, for the "reuse" field.
+const (
+	// ReuseWarm runs the gate in a warm container kept between runs (the default).
+	ReuseWarm = "warm"
+	// ReuseEphemeral runs the gate in a fresh container that is removed after the run.
+	ReuseEphemeral = "ephemeral"
+)
+
+// Input streamed to the commands of exec and script gates, for the "stdin" field.
+const (
+	// StdinNone gives the command no input (the default).
+	StdinNone = "none"
+	// StdinDiff streams the staged diff of the files the gate checks.
+	StdinDiff = "diff"
+	// StdinStagedFiles streams the paths of the staged files the gate checks,
+	// one per line.
+	StdinStagedFiles = "staged_files"
+)
+
+// Special "container" values of exec and script gates, in place of an image.
+const (
+	// ContainerLocal runs the gate on the host, without Docker.
+	ContainerLocal = "local"
+	// ContainerNix runs the gate on the host in the project's Nix development shell.
+	ContainerNix = "nix"
+	// ContainerDevcontainer runs the gate in the project's dev container, per its devcontainer.json.
+	ContainerDevcontainer = "devcontainer"
 )
 
 // OnErrorPolicy defines behavior when a system error occurs.
@@ -36,9 +124,79 @@ var ErrConfigNotFound = errors.New("no .gatekeeper/gates.yaml found. Run 'gateke
 
 // GatekeeperConfig is the top-level project configuration.
 type GatekeeperConfig struct {
-	Version  int      `yaml:"version"`
+	Version int `yaml:"version"`
+	// Extends lists presets merged under this config, in order: files relative
+	// to the config's directory, https URLs, or github.com/<owner>/<repo>//<file>.
+	Extends   []string         `yaml:"extends,omitempty"`
+	Defaults  Defaults         `yaml:"defaults"`
+	Overrides []BranchOverride `yaml:"overrides,omitempty"`
+	LLMPolicy LLMPolicy        `yaml:"llm_policy,omitempty"`
+	Branches  BranchRules      `yaml:"branches,omitempty"`
+	Gates     []Gate           `yaml:"gates"`
+
+	// AppliedOverrides lists the branch patterns whose overrides were applied at load time.
+	AppliedOverrides []string `yaml:"-"`
+
+	// SourceHash is the SHA-256 of the gates.yaml contents the config was loaded
+	// from, including the presets it extends.
+	SourceHash string `yaml:"-"`
+}
+
+// LLMPolicy controls when non-blocking LLM gates are deferred instead of run,
+// keeping hook latency predictable during provider slowdowns. Zero values disable a rule.
+type LLMPolicy struct {
+	// DeferAfter defers non-blocking LLM gates when the other gates took longer than this.
+	DeferAfter time.Duration `yaml:"defer_after,omitempty"`
+	// MaxMedianLatency defers them when the provider's recorded median latency exceeds this.
+	MaxMedianLatency time.Duration `yaml:"max_median_latency,omitempty"`
+}
+
+// BranchRules restricts the branches commits are made on. They are checked
+// before any gate runs.
+type BranchRules struct {
+	// BlockCommitOn lists branches that must not be committed to directly, as
+	// exact names or glob patterns (e.g., "release/*").
+	BlockCommitOn []string `yaml:"block_commit_on,omitempty"`
+	// Naming is a regular expression the names of the other branches must
+	// match, e.g. "^(feat|fix|chore)/[a-z0-9-]+$".
+	Naming string `yaml:"naming,omitempty"`
+}
+
+// Violation returns the rule committing on branch breaks and why, or empty
+// strings if it breaks none. A detached HEAD (empty branch) breaks none.
+func (r BranchRules) Violation(branch string) (rule, reason string) {
+	if branch == "" {
+		return "", ""
+	}
+	for _, pattern := range r.BlockCommitOn {
+		if ok, err := path.Match(pattern, branch); err == nil && ok {
+			return "protected-branch", fmt.Sprintf("branch %q is protected (block_commit_on: %s)", branch, pattern)
+		}
+	}
+	if r.Naming != "" {
+		if re, err := regexp.Compile(r.Naming); err == nil && !re.MatchString(branch) {
+			return "branch-naming", fmt.Sprintf("branch name %q does not match %s", branch, r.Naming)
+		}
+	}
+	return "", ""
+}
+
+// BranchOverride replaces defaults when committing on a matching branch, so
+// protected branches can run stricter gates than feature branches.
+type BranchOverride struct {
+	// Branch is an exact branch name or a glob pattern (e.g., "release/*").
+	Branch string `yaml:"branch"`
+	// Defaults holds the values that replace the top-level defaults. Unset fields are inherited.
 	Defaults Defaults `yaml:"defaults"`
-	Gates    []Gate   `yaml:"gates"`
+}
+
+// Matches reports whether the override applies to branch.
+func (o BranchOverride) Matches(branch string) bool {
+	if branch == "" {
+		return false
+	}
+	ok, err := path.Match(o.Branch, branch)
+	return err == nil && ok
 }
 
 // Defaults holds default values that are applied to gates missing optional fields.
@@ -47,27 +205,254 @@ type Defaults struct {
 	Timeout   time.Duration `yaml:"timeout"`
 	Blocking  *bool         `yaml:"blocking"`
 	OnError   OnErrorPolicy `yaml:"on_error"`
-	FailFast  bool          `yaml:"fail_fast"`
+	// FailFast cancels the remaining gates on the first blocking failure.
+	// --fail-fast takes precedence; if unset, the user config's fail_fast applies.
+	FailFast *bool `yaml:"fail_fast"`
+	// ArtifactCheck enables the built-in advisory gate that warns about staged
+	// gatekeeper state and generated junk (default true).
+	ArtifactCheck *bool `yaml:"artifact_check"`
+	// Isolation is how gates see only the staged changes: "stash" (the default)
+	// or "snapshot".
+	Isolation string `yaml:"isolation"`
+	// Submodules is how gates treat staged submodule changes: "skip" (the
+	// default) or "recurse".
+	Submodules string `yaml:"submodules"`
+	// Profile is the gate profile run when none is selected with --profile or
+	// the user config (empty runs every gate).
+	Profile string `yaml:"profile"`
+	// FlakyDowngrade is the number of runs a blocking gate found flaky (it
+	// passed and failed on the same changes) warns instead of blocking. 0
+	// keeps flaky gates blocking.
+	FlakyDowngrade int `yaml:"flaky_downgrade"`
+	// CommitBudget is how long a whole run is expected to take. A slower run
+	// ends with a warning suggesting to move slow gates to another profile
+	// (0 means no budget).
+	CommitBudget time.Duration `yaml:"commit_budget"`
+}
+
+// FailFastEnabled reports whether fail_fast is set to true.
+func (d Defaults) FailFastEnabled() bool {
+	return d.FailFast != nil && *d.FailFast
+}
+
+// ArtifactCheckEnabled reports whether the built-in artifact check runs.
+func (d Defaults) ArtifactCheckEnabled() bool {
+	return d.ArtifactCheck == nil || *d.ArtifactCheck
 }
 
 // Gate represents a single validation gate configuration.
 type Gate struct {
-	Name        string        `yaml:"name"`
-	Type        GateType      `yaml:"type"`
-	Command     string        `yaml:"command,omitempty"`
-	Path        string        `yaml:"path,omitempty"`
-	Container   string        `yaml:"container,omitempty"`
-	Parser      string        `yaml:"parser,omitempty"`
-	Timeout     time.Duration `yaml:"timeout,omitempty"`
-	Blocking    *bool         `yaml:"blocking,omitempty"`
-	OnError     OnErrorPolicy `yaml:"on_error,omitempty"`
-	Only        []string      `yaml:"only,omitempty"`
-	Except      []string      `yaml:"except,omitempty"`
-	Writable    bool          `yaml:"writable,omitempty"`
-	Provider    string        `yaml:"provider,omitempty"`
-	Mode        string        `yaml:"mode,omitempty"`
-	Prompt      string        `yaml:"prompt,omitempty"`
-	MaxFileSize string        `yaml:"max_file_size,omitempty"`
+	Name      string        `yaml:"name"`
+	Type      GateType      `yaml:"type"`
+	Command   string        `yaml:"command,omitempty"`
+	Path      string        `yaml:"path,omitempty"`
+	Container string        `yaml:"container,omitempty"`
+	Parser    string        `yaml:"parser,omitempty"`
+	Timeout   time.Duration `yaml:"timeout,omitempty"`
+	Blocking  *bool         `yaml:"blocking,omitempty"`
+	OnError   OnErrorPolicy `yaml:"on_error,omitempty"`
+	Only      []string      `yaml:"only,omitempty"`
+	Except    []string      `yaml:"except,omitempty"`
+	Writable  bool          `yaml:"writable,omitempty"`
+	// Budget is how long the gate is expected to take. A slower run is
+	// highlighted in the results but does not fail (0 means no budget).
+	Budget time.Duration `yaml:"budget,omitempty"`
+	// Setup is a command run once in each new container of an exec or script
+	// gate, e.g. to install tools. The pool keys containers by it, so gates
+	// share a warm container only if their setup is the same.
+	Setup string `yaml:"setup,omitempty"`
+	// Reuse is "warm" (the default) to run in a warm container kept between
+	// runs, or "ephemeral" to run in a fresh container removed afterwards.
+	Reuse string `yaml:"reuse,omitempty"`
+	// Workdir is the directory, relative to the project root (or the nested
+	// config's directory), that exec and script gates run in.
+	Workdir string `yaml:"workdir,omitempty"`
+	// Against is the git ref that a breaking-change check, such as buf
+	// breaking, compares with. The command sees it as $GATEKEEPER_AGAINST.
+	Against string `yaml:"against,omitempty"`
+	// Stdin is what the command reads on its standard input: "none" (the
+	// default), "diff" for the staged diff, or "staged_files" for the staged
+	// file paths, limited to the files the gate checks.
+	Stdin string `yaml:"stdin,omitempty"`
+	// WritablePolicy is "revert" (the default) to discard a writable gate's
+	// modifications after the run, or "apply" to re-stage them.
+	WritablePolicy string `yaml:"writable_policy,omitempty"`
+	Provider       string `yaml:"provider,omitempty"`
+	Mode           string `yaml:"mode,omitempty"`
+	Prompt         string `yaml:"prompt,omitempty"`
+	MaxFileSize    string `yaml:"max_file_size,omitempty"`
+	// FallbackProviders are tried in order when the provider of an LLM gate
+	// fails or is rate limited, e.g. [gpt-4o-mini, ollama/llama3].
+	FallbackProviders []string `yaml:"fallback_providers,omitempty"`
+	// PromptFile is a file of review rules, relative to the project root, used
+	// instead of an inline prompt. Like prompt, it may use template variables.
+	PromptFile string `yaml:"prompt_file,omitempty"`
+	// Language names the language of the files an LLM gate reviews, e.g. "Go",
+	// instead of inferring it per file from extensions. Files are then reviewed
+	// together rather than in one request per language.
+	Language string `yaml:"language,omitempty"`
+	// ReviewVendored turns off the built-in exclusion of vendored, generated,
+	// and lock files from the diff an LLM gate reviews.
+	ReviewVendored bool `yaml:"review_vendored,omitempty"`
+	// MaxChunks caps the extra reviews spent on files over max_file_size, which
+	// are reviewed in hunk-aligned chunks (0 means the default).
+	MaxChunks int `yaml:"max_chunks,omitempty"`
+	// MaxContextSize caps the full files plus diff sent by modes full_context and
+	// doc_drift; larger changes fall back to a diff review (empty means 200KB).
+	MaxContextSize string `yaml:"max_context_size,omitempty"`
+	// MaxLFSSize is the size up to which files stored in git-lfs are reviewed
+	// by an LLM gate in full, read from the local LFS store. Larger files, and
+	// all of them if unset, are left out of the review.
+	MaxLFSSize string `yaml:"max_lfs_size,omitempty"`
+	// MinConfidence is the confidence (0-1) below which LLM findings are handled
+	// per LowConfidence (0 keeps every finding as reported).
+	MinConfidence float64 `yaml:"min_confidence,omitempty"`
+	// LowConfidence is "warn" (downgrade to warning, the default) or "drop".
+	LowConfidence string `yaml:"low_confidence,omitempty"`
+
+	// OnFailMessage is remediation guidance shown under the gate's findings when it fails.
+	OnFailMessage string `yaml:"on_fail_message,omitempty"`
+
+	// AffectedOnly narrows test commands to the code affected by staged changes.
+	AffectedOnly bool `yaml:"affected_only,omitempty"`
+
+	// Profiles lists the profiles (e.g., "full") the gate runs in. A gate
+	// without profiles runs in every profile.
+	Profiles []string `yaml:"profiles,omitempty"`
+
+	ParserConfig *ParserConfig `yaml:"parser_config,omitempty"`
+
+	// Coverage configures a coverage gate.
+	Coverage *CoverageConfig `yaml:"coverage,omitempty"`
+
+	// Policy configures the checks of a policy gate.
+	Policy *PolicyConfig `yaml:"policy,omitempty"`
+
+	// Dir is the directory, relative to the project root and slash-separated,
+	// of the nested gates.yaml the gate was loaded from; empty for the root
+	// config. The gate's file filters apply within Dir, and it runs there.
+	Dir string `yaml:"-"`
+}
+
+// ScopeFiles returns the files inside the gate's Dir, relative to it. Files are
+// returned unchanged for gates of the root config.
+func (g *Gate) ScopeFiles(files []string) []string {
+	return FilesUnder(g.Dir, files)
+}
+
+// ExecDir returns the directory the gate runs in, relative to the project root
+// and slash-separated: its workdir within its Dir. It is empty for the root.
+func (g *Gate) ExecDir() string {
+	dir := path.Join(g.Dir, filepath.ToSlash(g.Workdir))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// FilesUnder returns the files inside dir, relative to it. An empty dir is the
+// project root, for which files are returned unchanged.
+func FilesUnder(dir string, files []string) []string {
+	if dir == "" {
+		return files
+	}
+	prefix := dir + "/"
+	var scoped []string
+	for _, f := range files {
+		if rel, ok := strings.CutPrefix(filepath.ToSlash(f), prefix); ok {
+			scoped = append(scoped, rel)
+		}
+	}
+	return scoped
+}
+
+// CoverageConfig holds the settings of a coverage gate.
+type CoverageConfig struct {
+	// Report is the coverage report the command writes, relative to the gate's
+	// working directory, e.g. "coverage.out" or "/tmp/coverage.xml".
+	Report string `yaml:"report"`
+	// Format is "go", "cobertura", or "lcov"; empty detects it from the report.
+	Format string `yaml:"format,omitempty"`
+	// MinCoverage is the percentage (0-100) of lines of the changed files that
+	// tests must cover; 0 turns the check off.
+	MinCoverage float64 `yaml:"min_coverage,omitempty"`
+	// MaxDrop is how many percentage points the coverage of a changed file may
+	// fall below its baseline, the coverage at the last passing run.
+	MaxDrop float64 `yaml:"max_drop,omitempty"`
+}
+
+// coverageFormats are the report formats of coverage gates.
+var coverageFormats = []string{"go", "cobertura", "lcov"}
+
+// PolicyConfig holds the checks of a policy gate. Unset checks are off.
+type PolicyConfig struct {
+	// MaxChangedLines caps the lines added plus removed by the commit, not
+	// counting vendored, generated, and lock files.
+	MaxChangedLines int `yaml:"max_changed_lines,omitempty"`
+	// ForbiddenFiles are globs of files that must not be committed, e.g. "*.pem".
+	ForbiddenFiles []string `yaml:"forbidden_files,omitempty"`
+	// RequiredPairs are files that must be changed together.
+	RequiredPairs []FilePair `yaml:"required_pairs,omitempty"`
+	// ForbiddenMarkers are words, e.g. "TODO" or "FIXME", that added lines must not contain.
+	ForbiddenMarkers []string `yaml:"forbidden_markers,omitempty"`
+	// ConflictMarkers rejects added merge conflict markers (<<<<<<<, |||||||, >>>>>>>).
+	ConflictMarkers bool `yaml:"conflict_markers,omitempty"`
+	// MaxBinarySize caps the size of staged binary files, e.g. "1MB".
+	MaxBinarySize string `yaml:"max_binary_size,omitempty"`
+	// RequireUTF8 rejects added lines of text files that are not valid UTF-8.
+	RequireUTF8 bool `yaml:"require_utf8,omitempty"`
+	// AppendOnly are globs of files, e.g. "migrations/*.sql", that commits may
+	// add but not modify, rename, or delete once they are committed.
+	AppendOnly []string `yaml:"append_only,omitempty"`
+}
+
+// sizePattern matches sizes such as "512KB", "1MB", or a number of bytes.
+var sizePattern = regexp.MustCompile(`(?i)^\s*[1-9][0-9]*\s*(KB|MB)?\s*$`)
+
+// FilePair requires a file to be changed whenever a matching file is.
+type FilePair struct {
+	// Changed are globs of the files the pair applies to, e.g. "internal/handler/*.go".
+	Changed []string `yaml:"changed"`
+	// Except are globs of files the pair does not apply to, e.g. "*_test.go".
+	Except []string `yaml:"except,omitempty"`
+	// Requires is the path of the file that must be changed too. {dir}, {name},
+	// and {ext} stand for the changed file's directory, base name without
+	// extension, and extension, e.g. "{dir}/{name}_test{ext}".
+	Requires string `yaml:"requires"`
+}
+
+// ParserConfig holds per-gate settings for configurable parsers ("regex" and "trivy-json").
+// Group fields are 1-based capture group indexes; 0 means the field is not captured.
+type ParserConfig struct {
+	Pattern       string `yaml:"pattern"`
+	FileGroup     int    `yaml:"file_group,omitempty"`
+	LineGroup     int    `yaml:"line_group,omitempty"`
+	ColumnGroup   int    `yaml:"column_group,omitempty"`
+	SeverityGroup int    `yaml:"severity_group,omitempty"`
+	RuleGroup     int    `yaml:"rule_group,omitempty"`
+	MessageGroup  int    `yaml:"message_group,omitempty"`
+	// Severity is used when no severity group is configured or it does not match (default "error").
+	Severity string `yaml:"severity,omitempty"`
+
+	// FailOn is the lowest Trivy severity that fails a trivy-json gate (default "HIGH").
+	FailOn string `yaml:"fail_on,omitempty"`
+}
+
+// RunsCommand reports whether the gate runs a command, in a container or on
+// the host. LLM and policy gates do not.
+func (g *Gate) RunsCommand() bool {
+	return g.Type != GateTypeLLM && g.Type != GateTypePolicy
+}
+
+// IsLocal reports whether the gate runs on the host instead of in a container.
+func (g *Gate) IsLocal() bool {
+	return g.Container == ContainerLocal || g.Container == ContainerNix
+}
+
+// InProfile reports whether the gate runs in profile. Every gate runs when
+// profile is empty, and untagged gates run in every profile.
+func (g *Gate) InProfile(profile string) bool {
+	return profile == "" || len(g.Profiles) == 0 || slices.Contains(g.Profiles, profile)
 }
 
 // IsBlocking returns whether this gate blocks commits on failure.
@@ -91,23 +476,35 @@ func (g *Gate) GetOnError() OnErrorPolicy {
 type Loader struct {
 	fs     FileSystem
 	getenv func(string) string
+	// fetch downloads remote presets.
+	fetch func(ctx context.Context, url string) ([]byte, error)
+	// presetCacheDir caches remote presets; empty disables the cache.
+	presetCacheDir string
+	// secret reads an API key from its secret source in the user config.
+	secret func(ctx context.Context, source, name string) (string, error)
 }
 
 // NewLoader creates a new Loader with the given file system.
 // Uses os.Getenv for environment variable lookups by default.
 func NewLoader(fs FileSystem) *Loader {
-	return &Loader{fs: fs, getenv: os.Getenv}
+	return NewLoaderWithEnv(fs, os.Getenv)
 }
 
 // NewLoaderWithEnv creates a Loader with a custom getenv function for testability.
 func NewLoaderWithEnv(fs FileSystem, getenv func(string) string) *Loader {
-	return &Loader{fs: fs, getenv: getenv}
+	return &Loader{fs: fs, getenv: getenv, fetch: httpFetch, presetCacheDir: defaultPresetCacheDir(), secret: secrets.NewResolver().Resolve}
 }
 
 // Load reads and parses a gates.yaml configuration file from the given path.
 // Returns ErrConfigNotFound if the file does not exist.
 func (l *Loader) Load(ctx context.Context, path string) (*GatekeeperConfig, error) {
-	logger.FromContext(ctx).Debug("loading config file", "path", path)
+	return l.LoadForBranch(ctx, path, "")
+}
+
+// LoadForBranch is like Load but first applies the overrides matching branch
+// to the defaults. An empty branch (e.g., detached HEAD) applies no overrides.
+func (l *Loader) LoadForBranch(ctx context.Context, path, branch string) (*GatekeeperConfig, error) {
+	logger.FromContext(ctx).Debug("loading config file", "path", path, "branch", branch)
 	// [SEC] Prevent path traversal
 	path = filepath.Clean(path)
 
@@ -124,6 +521,14 @@ func (l *Loader) Load(ctx context.Context, path string) (*GatekeeperConfig, erro
 		return nil, fmt.Errorf("parsing gates.yaml: %w", err)
 	}
 
+	presets, err := l.resolveExtends(ctx, &cfg, filepath.Dir(path), []string{path})
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(append(presets, data...))
+	cfg.SourceHash = hex.EncodeToString(sum[:])
+
+	applyBranchOverrides(&cfg, branch)
 	applyDefaults(&cfg)
 
 	if err := validate(&cfg); err != nil {
@@ -139,6 +544,63 @@ func Load(ctx context.Context, path string) (*GatekeeperConfig, error) {
 	return NewLoader(&RealFileSystem{}).Load(ctx, path)
 }
 
+// LoadForBranch reads gates.yaml from the real file system, applying the overrides for branch.
+func LoadForBranch(ctx context.Context, path, branch string) (*GatekeeperConfig, error) {
+	return NewLoader(&RealFileSystem{}).LoadForBranch(ctx, path, branch)
+}
+
+// applyBranchOverrides merges the defaults of every override matching branch,
+// in file order, into cfg.Defaults.
+func applyBranchOverrides(cfg *GatekeeperConfig, branch string) {
+	for _, o := range cfg.Overrides {
+		if !o.Matches(branch) {
+			continue
+		}
+		mergeDefaults(&cfg.Defaults, o.Defaults)
+		cfg.AppliedOverrides = append(cfg.AppliedOverrides, o.Branch)
+	}
+}
+
+// mergeDefaults copies the fields set in src over dst.
+func mergeDefaults(dst *Defaults, src Defaults) {
+	if src.Container != "" {
+		dst.Container = src.Container
+	}
+	if src.Timeout > 0 {
+		dst.Timeout = src.Timeout
+	}
+	if src.Blocking != nil {
+		val := *src.Blocking
+		dst.Blocking = &val
+	}
+	if src.OnError != "" {
+		dst.OnError = src.OnError
+	}
+	if src.FailFast != nil {
+		val := *src.FailFast
+		dst.FailFast = &val
+	}
+	if src.ArtifactCheck != nil {
+		val := *src.ArtifactCheck
+		dst.ArtifactCheck = &val
+	}
+	if src.Isolation != "" {
+		dst.Isolation = src.Isolation
+	}
+	if src.Submodules != "" {
+		dst.Submodules = src.Submodules
+	}
+	if src.Profile != "" {
+		dst.Profile = src.Profile
+	}
+	if src.FlakyDowngrade > 0 {
+		dst.FlakyDowngrade = src.FlakyDowngrade
+	}
+	if src.CommitBudget > 0 {
+		dst.CommitBudget = src.CommitBudget
+	}
+}
+
 // applyDefaults applies values from the defaults section to gates missing optional fields.
 func applyDefaults(cfg *GatekeeperConfig) {
 	for i := range cfg.Gates {
@@ -164,12 +626,126 @@ func applyDefaults(cfg *GatekeeperConfig) {
 // Returns a joined error if multiple gates have issues, so users can fix all at once.
 func validate(cfg *GatekeeperConfig) error {
 	var errs []error
+	for i, o := range cfg.Overrides {
+		if o.Branch == "" {
+			errs = append(errs, fmt.Errorf("override %d: missing required field 'branch'", i+1))
+		} else if _, err := path.Match(o.Branch, ""); err != nil {
+			errs = append(errs, fmt.Errorf("override %d: invalid branch pattern %q: %w", i+1, o.Branch, err))
+		}
+		if !validIsolation(o.Defaults.Isolation) {
+			errs = append(errs, fmt.Errorf("override %d: unknown isolation %q (valid: %s, %s)", i+1, o.Defaults.Isolation, IsolationStash, IsolationSnapshot))
+		}
+		if !validSubmodules(o.Defaults.Submodules) {
+			errs = append(errs, fmt.Errorf("override %d: unknown submodules %q (valid: %s, %s)", i+1, o.Defaults.Submodules, SubmodulesSkip, SubmodulesRecurse))
+		}
+	}
+
+	if !validIsolation(cfg.Defaults.Isolation) {
+		errs = append(errs, fmt.Errorf("defaults: unknown isolation %q (valid: %s, %s)", cfg.Defaults.Isolation, IsolationStash, IsolationSnapshot))
+	}
+	if !validSubmodules(cfg.Defaults.Submodules) {
+		errs = append(errs, fmt.Errorf("defaults: unknown submodules %q (valid: %s, %s)", cfg.Defaults.Submodules, SubmodulesSkip, SubmodulesRecurse))
+	}
+
+	if cfg.Defaults.FlakyDowngrade < 0 {
+		errs = append(errs, fmt.Errorf("defaults: flaky_downgrade must not be negative"))
+	}
+	if cfg.Defaults.CommitBudget < 0 {
+		errs = append(errs, fmt.Errorf("defaults: commit_budget must not be negative"))
+	}
+
+	if cfg.LLMPolicy.DeferAfter < 0 || cfg.LLMPolicy.MaxMedianLatency < 0 {
+		errs = append(errs, fmt.Errorf("llm_policy: durations must not be negative"))
+	}
+
+	for _, pattern := range cfg.Branches.BlockCommitOn {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			errs = append(errs, fmt.Errorf("branches: invalid block_commit_on pattern %q", pattern))
+		}
+	}
+	if cfg.Branches.Naming != "" {
+		if _, err := regexp.Compile(cfg.Branches.Naming); err != nil {
+			errs = append(errs, fmt.Errorf("branches: invalid naming pattern: %w", err))
+		}
+	}
+
 	for _, g := range cfg.Gates {
 		if g.Name == "" {
 			errs = append(errs, fmt.Errorf("gate at position has missing required field 'name'"))
 			continue
 		}
 
+		if strings.HasPrefix(g.Parser, "exec:") && strings.TrimSpace(strings.TrimPrefix(g.Parser, "exec:")) == "" {
+			errs = append(errs, fmt.Errorf("gate %q: parser plugin 'exec:' requires a program path", g.Name))
+		}
+
+		if g.WritablePolicy != "" {
+			if g.WritablePolicy != WritablePolicyRevert && g.WritablePolicy != WritablePolicyApply {
+				errs = append(errs, fmt.Errorf("gate %q: unknown writable_policy %q (valid: %s, %s)", g.Name, g.WritablePolicy, WritablePolicyRevert, WritablePolicyApply))
+			} else if !g.Writable {
+				errs = append(errs, fmt.Errorf("gate %q: writable_policy requires writable: true", g.Name))
+			}
+		}
+
+		if g.Budget < 0 {
+			errs = append(errs, fmt.Errorf("gate %q: budget must not be negative", g.Name))
+		}
+
+		if slices.Contains(g.Profiles, "") {
+			errs = append(errs, fmt.Errorf("gate %q: profiles must not contain an empty name", g.Name))
+		}
+
+		if g.Setup != "" && (!g.RunsCommand() || g.IsLocal()) {
+			errs = append(errs, fmt.Errorf("gate %q: setup is only supported by gates that run in a container", g.Name))
+		}
+
+		if g.OnError != "" && g.OnError != OnErrorBlock && g.OnError != OnErrorWarn {
+			errs = append(errs, fmt.Errorf("gate %q: unknown on_error %q (valid: %s, %s)", g.Name, g.OnError, OnErrorBlock, OnErrorWarn))
+		}
+
+		switch g.Reuse {
+		case "", ReuseWarm:
+		case ReuseEphemeral:
+			if !g.RunsCommand() || g.IsLocal() {
+				errs = append(errs, fmt.Errorf("gate %q: reuse is only supported by gates that run in a container", g.Name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("gate %q: unknown reuse %q (valid: %s, %s)", g.Name, g.Reuse, ReuseWarm, ReuseEphemeral))
+		}
+
+		if g.Workdir != "" {
+			if !g.RunsCommand() {
+				errs = append(errs, fmt.Errorf("gate %q: workdir is only supported by exec, script, and coverage gates", g.Name))
+			} else if err := validateWorkdir(g.Workdir); err != nil {
+				errs = append(errs, fmt.Errorf("gate %q: %w", g.Name, err))
+			}
+		}
+
+		if g.Against != "" {
+			if !g.RunsCommand() {
+				errs = append(errs, fmt.Errorf("gate %q: against is only supported by exec, script, and coverage gates", g.Name))
+			} else if !validRef(g.Against) {
+				errs = append(errs, fmt.Errorf("gate %q: against %q is not a valid git ref", g.Name, g.Against))
+			}
+		}
+
+		switch g.Stdin {
+		case "", StdinNone:
+		case StdinDiff, StdinStagedFiles:
+			if !g.RunsCommand() {
+				errs = append(errs, fmt.Errorf("gate %q: stdin is only supported by exec, script, and coverage gates", g.Name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("gate %q: unknown stdin %q (valid: %s, %s, %s)", g.Name, g.Stdin, StdinNone, StdinDiff, StdinStagedFiles))
+		}
+
+		if g.Parser == "regex" {
+			errs = append(errs, validateRegexParser(g)...)
+		}
+		if g.ParserConfig != nil && g.ParserConfig.FailOn != "" {
+			errs = append(errs, validateFailOn(g)...)
+		}
+
 		switch g.Type {
 		case GateTypeExec:
 			if g.Command == "" {
@@ -185,15 +761,185 @@ func validate(cfg *GatekeeperConfig) error {
 			if g.Provider == "" {
 				errs = append(errs, fmt.Errorf("gate %q: missing required field 'provider' for type 'llm'", g.Name))
 			}
-			if g.Prompt == "" {
+			if slices.Contains(g.FallbackProviders, "") {
+				errs = append(errs, fmt.Errorf("gate %q: fallback_providers must not contain empty entries", g.Name))
+			}
+			switch {
+			case g.Prompt == "" && g.PromptFile == "" && g.Mode != LLMModeDocDrift:
 				errs = append(errs, fmt.Errorf("gate %q: missing required field 'prompt' for type 'llm'", g.Name))
+			case g.Prompt != "" && g.PromptFile != "":
+				errs = append(errs, fmt.Errorf("gate %q: set either 'prompt' or 'prompt_file', not both", g.Name))
+			}
+			if g.Mode != "" && g.Mode != LLMModeDiff && g.Mode != LLMModeFullContext && g.Mode != LLMModeDocDrift {
+				errs = append(errs, fmt.Errorf("gate %q: unknown mode %q (valid: %s, %s, %s)", g.Name, g.Mode, LLMModeDiff, LLMModeFullContext, LLMModeDocDrift))
+			}
+			if g.MinConfidence < 0 || g.MinConfidence > 1 {
+				errs = append(errs, fmt.Errorf("gate %q: min_confidence must be between 0 and 1", g.Name))
+			}
+			if g.LowConfidence != "" && g.LowConfidence != LowConfidenceWarn && g.LowConfidence != LowConfidenceDrop {
+				errs = append(errs, fmt.Errorf("gate %q: unknown low_confidence %q (valid: %s, %s)", g.Name, g.LowConfidence, LowConfidenceWarn, LowConfidenceDrop))
+			}
+			if g.MaxChunks < 0 {
+				errs = append(errs, fmt.Errorf("gate %q: max_chunks must not be negative", g.Name))
 			}
+		case GateTypeCoverage:
+			if g.Command == "" {
+				errs = append(errs, fmt.Errorf("gate %q: missing required field 'command' for type 'coverage'", g.Name))
+			}
+			errs = append(errs, validateCoverage(g)...)
+		case GateTypePolicy:
+			errs = append(errs, validatePolicy(g)...)
 		case "":
 			errs = append(errs, fmt.Errorf("gate %q: missing required field 'type'", g.Name))
 		default:
-			errs = append(errs, fmt.Errorf("gate %q: unknown gate type %q (valid: exec, script, llm)", g.Name, g.Type))
+			errs = append(errs, fmt.Errorf("gate %q: unknown gate type %q (valid: exec, script, llm, coverage, policy)", g.Name, g.Type))
+		}
+		if g.Coverage != nil && g.Type != GateTypeCoverage {
+			errs = append(errs, fmt.Errorf("gate %q: coverage is only supported by coverage gates", g.Name))
+		}
+		if g.Policy != nil && g.Type != GateTypePolicy {
+			errs = append(errs, fmt.Errorf("gate %q: policy is only supported by policy gates", g.Name))
 		}
 	}
 
 	return errors.Join(errs...)
 }
+
+// validateWorkdir checks that workdir is a relative path that stays within the
+// directory mounted at /workspace.
+func validateWorkdir(workdir string) error {
+	slashed := filepath.ToSlash(workdir)
+	if path.IsAbs(slashed) || filepath.VolumeName(workdir) != "" {
+		return fmt.Errorf("workdir %q must be relative to the project root", workdir)
+	}
+	if clean := path.Clean(slashed); clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("workdir %q must stay within the project", workdir)
+	}
+	return nil
+}
+
+// validRef reports whether ref can name a git revision: it must not look like
+// an option or contain whitespace or control characters.
+func validRef(ref string) bool {
+	return !strings.HasPrefix(ref, "-") && !strings.ContainsFunc(ref, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// validIsolation reports whether isolation is empty or a known isolation mode.
+func validIsolation(isolation string) bool {
+	return isolation == "" || isolation == IsolationStash || isolation == IsolationSnapshot
+}
+
+// validSubmodules reports whether submodules is empty or a known submodule mode.
+func validSubmodules(submodules string) bool {
+	return submodules == "" || submodules == SubmodulesSkip || submodules == SubmodulesRecurse
+}
+
+// validateFailOn checks the parser_config.fail_on threshold of a gate.
+func validateFailOn(g Gate) []error {
+	if g.Parser != "trivy-json" {
+		return []error{fmt.Errorf("gate %q: parser_config.fail_on is only supported by parser 'trivy-json'", g.Name)}
+	}
+	for _, sev := range parser.TrivySeverities {
+		if strings.EqualFold(g.ParserConfig.FailOn, sev) {
+			return nil
+		}
+	}
+	return []error{fmt.Errorf("gate %q: invalid parser_config.fail_on %q (valid: %s)", g.Name, g.ParserConfig.FailOn, strings.Join(parser.TrivySeverities, ", "))}
+}
+
+// validateCoverage checks the coverage settings of a coverage gate.
+func validateCoverage(g Gate) []error {
+	c := g.Coverage
+	if c == nil || c.Report == "" {
+		return []error{fmt.Errorf("gate %q: missing required field 'coverage.report' for type 'coverage'", g.Name)}
+	}
+	var errs []error
+	if c.Format != "" && !slices.Contains(coverageFormats, c.Format) {
+		errs = append(errs, fmt.Errorf("gate %q: unknown coverage.format %q (valid: %s)", g.Name, c.Format, strings.Join(coverageFormats, ", ")))
+	}
+	if c.MinCoverage < 0 || c.MinCoverage > 100 {
+		errs = append(errs, fmt.Errorf("gate %q: coverage.min_coverage must be between 0 and 100", g.Name))
+	}
+	if c.MaxDrop < 0 {
+		errs = append(errs, fmt.Errorf("gate %q: coverage.max_drop must not be negative", g.Name))
+	}
+	return errs
+}
+
+// validatePolicy checks the checks of a policy gate.
+func validatePolicy(g Gate) []error {
+	p := g.Policy
+	if p == nil || (p.MaxChangedLines == 0 && len(p.ForbiddenFiles) == 0 && len(p.RequiredPairs) == 0 && len(p.ForbiddenMarkers) == 0 &&
+		!p.ConflictMarkers && p.MaxBinarySize == "" && !p.RequireUTF8 && len(p.AppendOnly) == 0) {
+		return []error{fmt.Errorf("gate %q: policy gate has no checks (set policy.max_changed_lines, forbidden_files, required_pairs, forbidden_markers, conflict_markers, max_binary_size, require_utf8, or append_only)", g.Name)}
+	}
+	var errs []error
+	if p.MaxBinarySize != "" && !sizePattern.MatchString(p.MaxBinarySize) {
+		errs = append(errs, fmt.Errorf("gate %q: invalid policy.max_binary_size %q (e.g. 512KB or 1MB)", g.Name, p.MaxBinarySize))
+	}
+	if p.MaxChangedLines < 0 {
+		errs = append(errs, fmt.Errorf("gate %q: policy.max_changed_lines must not be negative", g.Name))
+	}
+	errs = append(errs, validateGlobs(g.Name, "policy.forbidden_files", p.ForbiddenFiles)...)
+	errs = append(errs, validateGlobs(g.Name, "policy.append_only", p.AppendOnly)...)
+	for i, pair := range p.RequiredPairs {
+		field := fmt.Sprintf("policy.required_pairs[%d]", i)
+		if len(pair.Changed) == 0 || pair.Requires == "" {
+			errs = append(errs, fmt.Errorf("gate %q: %s needs both 'changed' and 'requires'", g.Name, field))
+		}
+		errs = append(errs, validateGlobs(g.Name, field+".changed", pair.Changed)...)
+		errs = append(errs, validateGlobs(g.Name, field+".except", pair.Except)...)
+	}
+	for _, marker := range p.ForbiddenMarkers {
+		if strings.TrimSpace(marker) == "" {
+			errs = append(errs, fmt.Errorf("gate %q: policy.forbidden_markers must not contain empty entries", g.Name))
+			break
+		}
+	}
+	return errs
+}
+
+// validateGlobs checks that patterns are valid globs.
+func validateGlobs(gate, field string, patterns []string) []error {
+	var errs []error
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			errs = append(errs, fmt.Errorf("gate %q: invalid %s pattern %q: %w", gate, field, p, err))
+		}
+	}
+	return errs
+}
+
+// validateRegexParser checks the parser_config of a gate using the regex parser.
+func validateRegexParser(g Gate) []error {
+	pc := g.ParserConfig
+	if pc == nil || pc.Pattern == "" {
+		return []error{fmt.Errorf("gate %q: parser 'regex' requires parser_config.pattern", g.Name)}
+	}
+
+	re, err := regexp.Compile(pc.Pattern)
+	if err != nil {
+		return []error{fmt.Errorf("gate %q: invalid parser_config.pattern: %w", g.Name, err)}
+	}
+
+	var errs []error
+	groups := []struct {
+		field string
+		index int
+	}{
+		{"file_group", pc.FileGroup},
+		{"line_group", pc.LineGroup},
+		{"column_group", pc.ColumnGroup},
+		{"severity_group", pc.SeverityGroup},
+		{"rule_group", pc.RuleGroup},
+		{"message_group", pc.MessageGroup},
+	}
+	for _, grp := range groups {
+		if grp.index < 0 || grp.index > re.NumSubexp() {
+			errs = append(errs, fmt.Errorf("gate %q: parser_config.%s %d out of range (pattern has %d groups)", g.Name, grp.field, grp.index, re.NumSubexp()))
+		}
+	}
+	return errs
+}
@@ -209,7 +209,7 @@ func TestLoad_UnknownType(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected validation error for unknown gate type, got nil")
 	}
-	expected := "gate \"check\": unknown gate type \"magic\" (valid: exec, script, llm)"
+	expected := "gate \"check\": unknown gate type \"magic\" (valid: exec, script, llm, coverage, policy)"
 	if err.Error() != expected {
 		t.Errorf("expected error %q, got %q", expected, err.Error())
 	}
@@ -325,3 +325,594 @@ func TestValidate_PathContainsSingleQuote(t *testing.T) {
 		t.Errorf("expected single-quote error, got: %v", err)
 	}
 }
+
+func TestValidate_NegativeMaxChunks(t *testing.T) {
+	cfg := &GatekeeperConfig{
+		Gates: []Gate{
+			{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", MaxChunks: -1},
+		},
+	}
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "max_chunks must not be negative") {
+		t.Errorf("expected max_chunks error, got: %v", err)
+	}
+}
+
+func TestValidate_LLMMode(t *testing.T) {
+	for _, mode := range []string{"", LLMModeDiff, LLMModeFullContext, LLMModeDocDrift} {
+		cfg := &GatekeeperConfig{Gates: []Gate{{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Mode: mode}}}
+		if err := validate(cfg); err != nil {
+			t.Errorf("mode %q: unexpected error: %v", mode, err)
+		}
+	}
+
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Mode: "files"}}}
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), `unknown mode "files"`) {
+		t.Errorf("expected unknown mode error, got: %v", err)
+	}
+}
+
+func TestValidate_DocDriftPromptOptional(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "docs", Type: GateTypeLLM, Provider: "gemini", Mode: LLMModeDocDrift}}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Gates[0].Mode = LLMModeDiff
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "missing required field 'prompt'") {
+		t.Errorf("expected missing prompt error, got: %v", err)
+	}
+}
+
+func TestValidate_CoverageGate(t *testing.T) {
+	valid := Gate{Name: "coverage", Type: GateTypeCoverage, Command: "go test -coverprofile=coverage.out ./...", Coverage: &CoverageConfig{Report: "coverage.out", Format: "go", MinCoverage: 80, MaxDrop: 1}}
+	if err := validate(&GatekeeperConfig{Gates: []Gate{valid}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(g *Gate)
+		want string
+	}{
+		{"no command", func(g *Gate) { g.Command = "" }, "missing required field 'command' for type 'coverage'"},
+		{"no report", func(g *Gate) { g.Coverage = nil }, "missing required field 'coverage.report'"},
+		{"format", func(g *Gate) { g.Coverage.Format = "jacoco" }, `unknown coverage.format "jacoco"`},
+		{"min_coverage", func(g *Gate) { g.Coverage.MinCoverage = 120 }, "min_coverage must be between 0 and 100"},
+		{"max_drop", func(g *Gate) { g.Coverage.MaxDrop = -1 }, "max_drop must not be negative"},
+		{"other type", func(g *Gate) { g.Type = GateTypeExec }, "coverage is only supported by coverage gates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid
+			c := *valid.Coverage
+			g.Coverage = &c
+			tt.edit(&g)
+			if err := validate(&GatekeeperConfig{Gates: []Gate{g}}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_PolicyGate(t *testing.T) {
+	valid := Gate{Name: "policy", Type: GateTypePolicy, Policy: &PolicyConfig{
+		MaxChangedLines:  400,
+		ForbiddenFiles:   []string{"*.pem", ".env*"},
+		RequiredPairs:    []FilePair{{Changed: []string{"internal/handler/*.go"}, Except: []string{"*_test.go"}, Requires: "{dir}/{name}_test{ext}"}},
+		ForbiddenMarkers: []string{"TODO", "FIXME"},
+		ConflictMarkers:  true,
+		MaxBinarySize:    "512KB",
+		RequireUTF8:      true,
+		AppendOnly:       []string{"migrations/*.sql"},
+	}}
+	if err := validate(&GatekeeperConfig{Gates: []Gate{valid}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(g *Gate)
+		want string
+	}{
+		{"no checks", func(g *Gate) { g.Policy = &PolicyConfig{} }, "policy gate has no checks"},
+		{"no policy", func(g *Gate) { g.Policy = nil }, "policy gate has no checks"},
+		{"max_changed_lines", func(g *Gate) { g.Policy.MaxChangedLines = -1 }, "max_changed_lines must not be negative"},
+		{"forbidden_files", func(g *Gate) { g.Policy.ForbiddenFiles = []string{"[x"} }, `invalid policy.forbidden_files pattern "[x"`},
+		{"pair", func(g *Gate) { g.Policy.RequiredPairs = []FilePair{{Changed: []string{"*.go"}}} }, "policy.required_pairs[0] needs both 'changed' and 'requires'"},
+		{"marker", func(g *Gate) { g.Policy.ForbiddenMarkers = []string{" "} }, "forbidden_markers must not contain empty entries"},
+		{"max_binary_size", func(g *Gate) { g.Policy.MaxBinarySize = "1GB" }, `invalid policy.max_binary_size "1GB"`},
+		{"append_only", func(g *Gate) { g.Policy.AppendOnly = []string{"[x"} }, `invalid policy.append_only pattern "[x"`},
+		{"setup", func(g *Gate) { g.Setup = "apk add git" }, "setup is only supported by gates that run in a container"},
+		{"workdir", func(g *Gate) { g.Workdir = "api" }, "workdir is only supported by exec, script, and coverage gates"},
+		{"other type", func(g *Gate) { g.Type = GateTypeExec; g.Command = "true" }, "policy is only supported by policy gates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid
+			p := *valid.Policy
+			g.Policy = &p
+			tt.edit(&g)
+			if err := validate(&GatekeeperConfig{Gates: []Gate{g}}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate_FallbackProviders(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "review", Type: GateTypeLLM, Provider: "gemini-3-pro", FallbackProviders: []string{"gpt-4o-mini", "ollama/llama3"}, Prompt: "Review"}}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Gates[0].FallbackProviders = []string{""}
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "fallback_providers must not contain empty entries") {
+		t.Errorf("expected empty entry error, got: %v", err)
+	}
+}
+
+func TestValidate_Confidence(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", MinConfidence: 0.7, LowConfidence: LowConfidenceDrop}}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Gates[0].MinConfidence = 1.5
+	cfg.Gates[0].LowConfidence = "hide"
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "min_confidence must be between 0 and 1") || !strings.Contains(err.Error(), `unknown low_confidence "hide"`) {
+		t.Errorf("expected confidence errors, got: %v", err)
+	}
+}
+
+func TestValidate_WritablePolicy(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "fmt", Type: GateTypeExec, Command: "gofmt -w .", Writable: true, WritablePolicy: WritablePolicyApply}}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Gates[0].WritablePolicy = "keep"
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), `unknown writable_policy "keep"`) {
+		t.Errorf("expected unknown writable_policy error, got: %v", err)
+	}
+
+	cfg.Gates[0].WritablePolicy = WritablePolicyApply
+	cfg.Gates[0].Writable = false
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "writable_policy requires writable: true") {
+		t.Errorf("expected writable requirement error, got: %v", err)
+	}
+}
+
+func TestValidate_Workdir(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    Gate
+		wantErr string
+	}{
+		{"subdirectory", Gate{Workdir: "services/api"}, ""},
+		{"dot segments inside", Gate{Workdir: "services/../api/./cmd"}, ""},
+		{"absolute", Gate{Workdir: "/etc"}, "must be relative"},
+		{"parent", Gate{Workdir: ".."}, "must stay within the project"},
+		{"escapes", Gate{Workdir: "services/../../other"}, "must stay within the project"},
+		{"llm gate", Gate{Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Workdir: "api"}, "only supported by exec, script, and coverage gates"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.gate
+			g.Name = "test"
+			if g.Type == "" {
+				g.Type, g.Command = GateTypeExec, "go test ./..."
+			}
+			err := validate(&GatekeeperConfig{Gates: []Gate{g}})
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Against(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    Gate
+		wantErr string
+	}{
+		{"branch", Gate{Type: GateTypeExec, Command: "buf breaking", Against: "main"}, ""},
+		{"revision", Gate{Type: GateTypeExec, Command: "buf breaking", Against: "origin/main~1"}, ""},
+		{"option", Gate{Type: GateTypeExec, Command: "buf breaking", Against: "--all"}, "not a valid git ref"},
+		{"whitespace", Gate{Type: GateTypeExec, Command: "buf breaking", Against: "main; rm -rf /"}, "not a valid git ref"},
+		{"llm gate", Gate{Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Against: "main"}, "only supported by exec, script, and coverage gates"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.gate
+			g.Name = "test"
+			err := validate(&GatekeeperConfig{Gates: []Gate{g}})
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Stdin(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    Gate
+		wantErr string
+	}{
+		{"diff", Gate{Type: GateTypeExec, Command: "commitlint", Stdin: StdinDiff}, ""},
+		{"staged files", Gate{Type: GateTypeScript, Path: "check.sh", Stdin: StdinStagedFiles}, ""},
+		{"none", Gate{Type: GateTypeExec, Command: "lint", Stdin: StdinNone}, ""},
+		{"unknown", Gate{Type: GateTypeExec, Command: "lint", Stdin: "files"}, "unknown stdin"},
+		{"llm gate", Gate{Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Stdin: StdinDiff}, "only supported by exec, script, and coverage gates"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.gate
+			g.Name = "test"
+			err := validate(&GatekeeperConfig{Gates: []Gate{g}})
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Setup(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    Gate
+		wantErr bool
+	}{
+		{"container gate", Gate{Type: GateTypeExec, Command: "go test ./...", Container: "golang:1.25", Setup: "go mod download"}, false},
+		{"local gate", Gate{Type: GateTypeExec, Command: "go test ./...", Container: ContainerLocal, Setup: "go mod download"}, true},
+		{"llm gate", Gate{Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Setup: "true"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.gate
+			g.Name = "test"
+			err := validate(&GatekeeperConfig{Gates: []Gate{g}})
+			if tc.wantErr != (err != nil && strings.Contains(err.Error(), "setup is only supported")) {
+				t.Errorf("wantErr = %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_Reuse(t *testing.T) {
+	tests := []struct {
+		name    string
+		gate    Gate
+		wantErr string
+	}{
+		{"warm", Gate{Type: GateTypeExec, Command: "go test ./...", Container: "golang:1.25", Reuse: ReuseWarm}, ""},
+		{"ephemeral", Gate{Type: GateTypeScript, Path: "scan.sh", Container: "alpine", Reuse: ReuseEphemeral}, ""},
+		{"unknown", Gate{Type: GateTypeExec, Command: "go test ./...", Container: "golang:1.25", Reuse: "fresh"}, "unknown reuse"},
+		{"unknown on_error", Gate{Type: GateTypeExec, Command: "go test ./...", Container: "golang:1.25", OnError: "ignore"}, "unknown on_error"},
+		{"local gate", Gate{Type: GateTypeExec, Command: "go test ./...", Container: ContainerLocal, Reuse: ReuseEphemeral}, "reuse is only supported"},
+		{"llm gate", Gate{Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", Reuse: ReuseEphemeral}, "reuse is only supported"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := tc.gate
+			g.Name = "test"
+			err := validate(&GatekeeperConfig{Gates: []Gate{g}})
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGate_ExecDir(t *testing.T) {
+	tests := []struct {
+		gate Gate
+		want string
+	}{
+		{Gate{}, ""},
+		{Gate{Workdir: "."}, ""},
+		{Gate{Workdir: "services/api/"}, "services/api"},
+		{Gate{Dir: "services/api"}, "services/api"},
+		{Gate{Dir: "services/api", Workdir: "cmd"}, "services/api/cmd"},
+	}
+	for _, tc := range tests {
+		if got := tc.gate.ExecDir(); got != tc.want {
+			t.Errorf("ExecDir() of %+v = %q, want %q", tc.gate, got, tc.want)
+		}
+	}
+}
+
+func TestValidate_PromptFile(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "review", Type: GateTypeLLM, Provider: "gemini", PromptFile: ".gatekeeper/prompts/security.md"}}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Gates[0].Prompt = "Review"
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "either 'prompt' or 'prompt_file'") {
+		t.Errorf("expected prompt conflict error, got: %v", err)
+	}
+}
+
+func TestValidate_EmptyParserPlugin(t *testing.T) {
+	cfg := &GatekeeperConfig{
+		Gates: []Gate{
+			{Name: "custom", Type: GateTypeExec, Command: "tool", Parser: "exec:"},
+		},
+	}
+	err := validate(cfg)
+	if err == nil {
+		t.Fatal("expected validation error for empty parser plugin path")
+	}
+	if !strings.Contains(err.Error(), "requires a program path") {
+		t.Errorf("expected plugin path error, got: %v", err)
+	}
+}
+
+func TestValidate_RegexParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		pc      *ParserConfig
+		wantErr string
+	}{
+		{"missing config", nil, "requires parser_config.pattern"},
+		{"invalid pattern", &ParserConfig{Pattern: "("}, "invalid parser_config.pattern"},
+		{"group out of range", &ParserConfig{Pattern: `(\S+): (.*)`, LineGroup: 3}, "line_group 3 out of range"},
+		{"valid", &ParserConfig{Pattern: `(\S+):(\d+): (.*)`, FileGroup: 1, LineGroup: 2, MessageGroup: 3}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &GatekeeperConfig{
+				Gates: []Gate{{Name: "tool", Type: GateTypeExec, Command: "tool", Parser: "regex", ParserConfig: tt.pc}},
+			}
+			err := validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_FailOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  string
+		failOn  string
+		wantErr string
+	}{
+		{"valid", "trivy-json", "high", ""},
+		{"unknown severity", "trivy-json", "SEVERE", "invalid parser_config.fail_on"},
+		{"unsupported parser", "sarif", "HIGH", "only supported by parser 'trivy-json'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &GatekeeperConfig{
+				Gates: []Gate{{Name: "scan", Type: GateTypeExec, Command: "trivy fs .", Parser: tt.parser, ParserConfig: &ParserConfig{FailOn: tt.failOn}}},
+			}
+			err := validate(cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadForBranch_Overrides(t *testing.T) {
+	const yml = `version: 1
+defaults:
+  blocking: false
+  timeout: 30s
+overrides:
+  - branch: main
+    defaults:
+      blocking: true
+      fail_fast: true
+  - branch: "release/*"
+    defaults:
+      timeout: 2m
+gates:
+  - name: lint
+    type: exec
+    command: golangci-lint run
+  - name: advisory
+    type: exec
+    command: echo hi
+    blocking: false
+`
+	mockFS := NewMockFileSystem()
+	mockFS.Files["gates.yaml"] = []byte(yml)
+	loader := NewLoader(mockFS)
+
+	tests := []struct {
+		branch       string
+		wantBlocking bool
+		wantFailFast bool
+		wantTimeout  time.Duration
+		wantApplied  int
+	}{
+		{"feature/x", false, false, 30 * time.Second, 0},
+		{"main", true, true, 30 * time.Second, 1},
+		{"release/1.2", false, false, 2 * time.Minute, 1},
+		{"", false, false, 30 * time.Second, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			cfg, err := loader.LoadForBranch(context.Background(), "gates.yaml", tt.branch)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Gates[0].IsBlocking() != tt.wantBlocking {
+				t.Errorf("lint blocking = %v, want %v", cfg.Gates[0].IsBlocking(), tt.wantBlocking)
+			}
+			if cfg.Gates[1].IsBlocking() {
+				t.Error("explicit gate setting must win over branch overrides")
+			}
+			if cfg.Defaults.FailFastEnabled() != tt.wantFailFast {
+				t.Errorf("fail_fast = %v, want %v", cfg.Defaults.FailFastEnabled(), tt.wantFailFast)
+			}
+			if cfg.Gates[0].Timeout != tt.wantTimeout {
+				t.Errorf("timeout = %v, want %v", cfg.Gates[0].Timeout, tt.wantTimeout)
+			}
+			if len(cfg.AppliedOverrides) != tt.wantApplied {
+				t.Errorf("applied overrides = %v, want %d", cfg.AppliedOverrides, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestValidate_Overrides(t *testing.T) {
+	cfg := &GatekeeperConfig{
+		Overrides: []BranchOverride{{Branch: ""}, {Branch: "release/["}},
+	}
+	err := validate(cfg)
+	if err == nil {
+		t.Fatal("expected validation errors for overrides")
+	}
+	if !strings.Contains(err.Error(), "missing required field 'branch'") || !strings.Contains(err.Error(), "invalid branch pattern") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBranchRules_Violation(t *testing.T) {
+	rules := BranchRules{BlockCommitOn: []string{"main", "release/*"}, Naming: "^(feat|fix)/[a-z0-9-]+$"}
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"main", "protected-branch"},
+		{"release/1.2", "protected-branch"},
+		{"feat/login", ""},
+		{"Feature/Login", "branch-naming"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		rule, reason := rules.Violation(tt.branch)
+		if rule != tt.want {
+			t.Errorf("Violation(%q) rule = %q, want %q", tt.branch, rule, tt.want)
+		}
+		if (rule == "") != (reason == "") {
+			t.Errorf("Violation(%q) = %q, %q", tt.branch, rule, reason)
+		}
+	}
+
+	if rule, _ := (BranchRules{}).Violation("main"); rule != "" {
+		t.Errorf("empty rules should allow every branch, got %q", rule)
+	}
+}
+
+func TestValidate_BranchRules(t *testing.T) {
+	cfg := &GatekeeperConfig{Branches: BranchRules{BlockCommitOn: []string{"release/["}, Naming: "feat/("}}
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), `invalid block_commit_on pattern "release/["`) || !strings.Contains(err.Error(), "invalid naming pattern") {
+		t.Errorf("expected branch rule errors, got: %v", err)
+	}
+}
+
+func TestValidate_Isolation(t *testing.T) {
+	cfg := &GatekeeperConfig{Defaults: Defaults{Isolation: IsolationSnapshot}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Defaults.Isolation = "worktree"
+	cfg.Overrides = []BranchOverride{{Branch: "main", Defaults: Defaults{Isolation: "copy"}}}
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), `defaults: unknown isolation "worktree"`) || !strings.Contains(err.Error(), `override 1: unknown isolation "copy"`) {
+		t.Errorf("expected isolation errors, got: %v", err)
+	}
+}
+
+func TestValidate_Submodules(t *testing.T) {
+	cfg := &GatekeeperConfig{Defaults: Defaults{Submodules: SubmodulesRecurse}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Defaults.Submodules = "ignore"
+	cfg.Overrides = []BranchOverride{{Branch: "main", Defaults: Defaults{Submodules: "all"}}}
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), `defaults: unknown submodules "ignore"`) || !strings.Contains(err.Error(), `override 1: unknown submodules "all"`) {
+		t.Errorf("expected submodules errors, got: %v", err)
+	}
+}
+
+func TestValidate_FlakyDowngrade(t *testing.T) {
+	cfg := &GatekeeperConfig{Defaults: Defaults{FlakyDowngrade: 5}}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Defaults.FlakyDowngrade = -1
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "flaky_downgrade must not be negative") {
+		t.Errorf("expected flaky_downgrade error, got: %v", err)
+	}
+}
+
+func TestValidate_Budgets(t *testing.T) {
+	cfg := &GatekeeperConfig{
+		Defaults: Defaults{CommitBudget: time.Minute},
+		Gates:    []Gate{{Name: "lint", Type: GateTypeExec, Command: "golangci-lint run", Budget: 10 * time.Second}},
+	}
+	if err := validate(cfg); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Defaults.CommitBudget = -time.Second
+	cfg.Gates[0].Budget = -time.Second
+	err := validate(cfg)
+	if err == nil || !strings.Contains(err.Error(), "defaults: commit_budget must not be negative") || !strings.Contains(err.Error(), `gate "lint": budget must not be negative`) {
+		t.Errorf("expected budget errors, got: %v", err)
+	}
+}
+
+func TestGate_InProfile(t *testing.T) {
+	untagged := Gate{Name: "vet"}
+	full := Gate{Name: "test", Profiles: []string{"full", "ci"}}
+
+	if !untagged.InProfile("fast") || !untagged.InProfile("") {
+		t.Error("untagged gates must run in every profile")
+	}
+	if full.InProfile("fast") {
+		t.Error("gate tagged full must not run in fast")
+	}
+	if !full.InProfile("ci") || !full.InProfile("") {
+		t.Error("gate tagged ci must run in ci and when no profile is selected")
+	}
+}
+
+func TestValidate_EmptyProfile(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{{Name: "vet", Type: GateTypeExec, Command: "go vet", Profiles: []string{""}}}}
+	if err := validate(cfg); err == nil || !strings.Contains(err.Error(), "profiles") {
+		t.Errorf("expected empty profile error, got %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package config
 
-import "strings"
+import (
+	"path"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
+)
 
 // Stack represents a detected technology stack.
 type Stack string
@@ -12,6 +17,19 @@ const (
 	StackNode Stack = "node"
 	// StackPython indicates a Python project (detected by requirements.txt or pyproject.toml).
 	StackPython Stack = "python"
+	// StackDocker indicates a project with Dockerfiles (detected by Dockerfile, Containerfile, *.Dockerfile).
+	StackDocker Stack = "docker"
+	// StackShell indicates a project with shell scripts (detected by *.sh, *.bash).
+	StackShell Stack = "shell"
+	// StackTerraform indicates an infrastructure-as-code project (detected by *.tf or terragrunt.hcl).
+	StackTerraform Stack = "terraform"
+	// StackProtobuf indicates a Protobuf project (detected by buf.yaml or *.proto).
+	StackProtobuf Stack = "protobuf"
+	// StackMigrations indicates a project with SQL migrations (detected by a
+	// migrations directory, alembic.ini, or a Flyway config).
+	StackMigrations Stack = "migrations"
+	// StackOpenAPI indicates a project with an API spec (detected by openapi.yaml or swagger.json and their variants).
+	StackOpenAPI Stack = "openapi"
 )
 
 // markerFiles maps file names to their corresponding stack.
@@ -20,6 +38,50 @@ var markerFiles = map[string]Stack{
 	"package.json":     StackNode,
 	"requirements.txt": StackPython,
 	"pyproject.toml":   StackPython,
+	"Dockerfile":       StackDocker,
+	"Containerfile":    StackDocker,
+	"terragrunt.hcl":   StackTerraform,
+	"buf.yaml":         StackProtobuf,
+	"buf.work.yaml":    StackProtobuf,
+	"migrations":       StackMigrations,
+	"alembic.ini":      StackMigrations,
+	"flyway.conf":      StackMigrations,
+	"flyway.toml":      StackMigrations,
+	"openapi.yaml":     StackOpenAPI,
+	"openapi.yml":      StackOpenAPI,
+	"openapi.json":     StackOpenAPI,
+	"swagger.yaml":     StackOpenAPI,
+	"swagger.yml":      StackOpenAPI,
+	"swagger.json":     StackOpenAPI,
+}
+
+// markerPatterns maps glob patterns (path.Match syntax) to their stack, for
+// markers without a fixed name. Checked after markerFiles. Like markerFiles,
+// they match names at the project root only: init does not walk subdirectories.
+var markerPatterns = []struct {
+	pattern string
+	stack   Stack
+}{
+	{"Dockerfile.*", StackDocker},
+	{"*.Dockerfile", StackDocker},
+	{"*.dockerfile", StackDocker},
+	{"*.sh", StackShell},
+	{"*.bash", StackShell},
+	{"*.tf", StackTerraform},
+	{"*.proto", StackProtobuf},
+}
+
+// stackOf returns the stack a marker file name belongs to.
+func stackOf(name string) (Stack, bool) {
+	if stack, ok := markerFiles[name]; ok {
+		return stack, true
+	}
+	for _, m := range markerPatterns {
+		if ok, _ := path.Match(m.pattern, name); ok {
+			return m.stack, true
+		}
+	}
+	return "", false
 }
 
 // DetectStacks scans file names for well-known marker files and returns
@@ -30,7 +92,7 @@ func DetectStacks(files []string) []Stack {
 	var stacks []Stack
 
 	for _, f := range files {
-		if stack, ok := markerFiles[f]; ok && !seen[stack] {
+		if stack, ok := stackOf(f); ok && !seen[stack] {
 			seen[stack] = true
 			stacks = append(stacks, stack)
 		}
@@ -42,121 +104,25 @@ func DetectStacks(files []string) []Stack {
 // GenerateGatesYAML produces a gates.yaml configuration string for the given stacks.
 // If no stacks are provided, a minimal example config with commented gates is returned.
 // Generated commands use diff/check mode for read-only, AI-friendly output.
-func GenerateGatesYAML(stacks []Stack) string {
+// The sections come from the init/ assets, which users can override. The
+// first line points editors at the JSON Schema for validation and completion.
+func GenerateGatesYAML(stacks []Stack) (string, error) {
+	sections := []string{"init/header.yaml"}
 	if len(stacks) == 0 {
-		return fallbackYAML
+		sections = []string{"init/fallback.yaml"}
+	}
+	for _, s := range stacks {
+		sections = append(sections, "init/"+string(s)+".yaml")
 	}
 
 	var b strings.Builder
-	b.WriteString(yamlHeader)
-
-	for _, s := range stacks {
-		switch s {
-		case StackGo:
-			b.WriteString(goGates)
-		case StackNode:
-			b.WriteString(nodeGates)
-		case StackPython:
-			b.WriteString(pythonGates)
+	b.WriteString(schemaModeline)
+	for _, name := range sections {
+		data, err := assets.Read(name)
+		if err != nil {
+			return "", err
 		}
+		b.Write(data)
 	}
-
-	return b.String()
+	return b.String(), nil
 }
-
-const yamlHeader = `# Gatekeeper configuration — auto-generated
-# Customize gates to match your project's needs.
-# Docs: https://github.com/irahardianto/gatekeeper
-version: 1
-
-defaults:
-  timeout: 60s
-  blocking: true
-  on_error: block
-
-gates:
-`
-
-const goGates = `  # --- Go ---
-  - name: go-vet
-    type: exec
-    command: "go vet ./..."
-    container: "golang:1.23"
-    only: ["*.go"]
-
-  - name: go-test
-    type: exec
-    command: "go test -race ./..."
-    container: "golang:1.23"
-    timeout: 120s
-    only: ["*.go"]
-
-  # - name: golangci-lint
-  #   type: exec
-  #   command: "golangci-lint run --out-format sarif ./..."
-  #   container: "golangci/golangci-lint:latest"
-  #   parser: sarif
-  #   only: ["*.go"]
-
-`
-
-const nodeGates = `  # --- Node.js ---
-  - name: eslint
-    type: exec
-    command: "npx eslint --format json ."
-    container: "node:20"
-    only: ["*.js", "*.ts", "*.jsx", "*.tsx"]
-
-  # - name: vitest
-  #   type: exec
-  #   command: "npx vitest run"
-  #   container: "node:20"
-  #   timeout: 120s
-  #   only: ["*.js", "*.ts", "*.jsx", "*.tsx"]
-
-  # - name: prettier-check
-  #   type: exec
-  #   command: "npx prettier --check ."
-  #   container: "node:20"
-
-`
-
-const pythonGates = `  # --- Python ---
-  - name: ruff
-    type: exec
-    command: "ruff check --output-format sarif ."
-    container: "python:3.12"
-    parser: sarif
-    only: ["*.py"]
-
-  # - name: pytest
-  #   type: exec
-  #   command: "pytest"
-  #   container: "python:3.12"
-  #   timeout: 120s
-  #   only: ["*.py"]
-
-  # - name: ruff-format-check
-  #   type: exec
-  #   command: "ruff format --check ."
-  #   container: "python:3.12"
-
-`
-
-const fallbackYAML = `# Gatekeeper configuration
-# No technology stack detected. Add gates below to get started.
-# Docs: https://github.com/irahardianto/gatekeeper
-version: 1
-
-defaults:
-  timeout: 60s
-  blocking: true
-  on_error: block
-
-gates:
-  # Example gate — uncomment and customize:
-  # - name: lint
-  #   type: exec
-  #   command: "echo 'Add your linter command here'"
-  #   container: "alpine:latest"
-`
@@ -98,7 +98,7 @@ func TestDetectStacks_PythonNoDuplicates(t *testing.T) {
 }
 
 func TestDetectStacks_None(t *testing.T) {
-	files := []string{"README.md", "Makefile", "Dockerfile"}
+	files := []string{"README.md", "Makefile", "LICENSE"}
 	stacks := DetectStacks(files)
 
 	if len(stacks) != 0 {
@@ -106,6 +106,77 @@ func TestDetectStacks_None(t *testing.T) {
 	}
 }
 
+func TestDetectStacks_DockerAndShell(t *testing.T) {
+	tests := []struct {
+		files []string
+		want  []Stack
+	}{
+		{[]string{"Dockerfile"}, []Stack{StackDocker}},
+		{[]string{"api.Dockerfile", "Dockerfile.dev"}, []Stack{StackDocker}},
+		{[]string{"Containerfile"}, []Stack{StackDocker}},
+		{[]string{"install.sh", "deploy.bash"}, []Stack{StackShell}},
+		{[]string{"go.mod", "Dockerfile", "build.sh"}, []Stack{StackGo, StackDocker, StackShell}},
+		{[]string{"Dockerfiles", "shell.txt"}, nil},
+	}
+
+	for _, tt := range tests {
+		got := DetectStacks(tt.files)
+		if len(got) != len(tt.want) {
+			t.Errorf("DetectStacks(%v) = %v, want %v", tt.files, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("DetectStacks(%v) = %v, want %v", tt.files, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDetectStacks_Terraform(t *testing.T) {
+	for _, files := range [][]string{
+		{"main.tf", "variables.tf"},
+		{"terragrunt.hcl"},
+		{"README.md", "outputs.tf"},
+	} {
+		got := DetectStacks(files)
+		if len(got) != 1 || got[0] != StackTerraform {
+			t.Errorf("DetectStacks(%v) = %v, want [terraform]", files, got)
+		}
+	}
+	if got := DetectStacks([]string{"main.tfvars", "tf.md"}); len(got) != 0 {
+		t.Errorf("expected no stack without .tf files, got %v", got)
+	}
+}
+
+func TestDetectStacks_Protobuf(t *testing.T) {
+	for _, files := range [][]string{{"buf.yaml"}, {"buf.work.yaml"}, {"api.proto"}} {
+		got := DetectStacks(files)
+		if len(got) != 1 || got[0] != StackProtobuf {
+			t.Errorf("DetectStacks(%v) = %v, want [protobuf]", files, got)
+		}
+	}
+}
+
+func TestDetectStacks_Migrations(t *testing.T) {
+	for _, files := range [][]string{{"migrations"}, {"alembic.ini"}, {"flyway.conf"}, {"flyway.toml"}} {
+		got := DetectStacks(files)
+		if len(got) != 1 || got[0] != StackMigrations {
+			t.Errorf("DetectStacks(%v) = %v, want [migrations]", files, got)
+		}
+	}
+}
+
+func TestDetectStacks_OpenAPI(t *testing.T) {
+	for _, files := range [][]string{{"openapi.yaml"}, {"openapi.json"}, {"swagger.json"}, {"swagger.yml"}} {
+		got := DetectStacks(files)
+		if len(got) != 1 || got[0] != StackOpenAPI {
+			t.Errorf("DetectStacks(%v) = %v, want [openapi]", files, got)
+		}
+	}
+}
+
 func TestDetectStacks_Empty(t *testing.T) {
 	stacks := DetectStacks(nil)
 
@@ -117,16 +188,17 @@ func TestDetectStacks_Empty(t *testing.T) {
 // --- GenerateGatesYAML Tests ---
 
 func TestGenerateGatesYAML_Go(t *testing.T) {
-	yaml := GenerateGatesYAML([]Stack{StackGo})
+	yaml := generateGatesYAML(t, []Stack{StackGo})
 
 	assertYAMLContains(t, yaml, "version: 1")
 	assertYAMLContains(t, yaml, "go vet")
 	assertYAMLContains(t, yaml, "go test")
 	assertYAMLContains(t, yaml, "golang")
+	assertYAMLContains(t, yaml, "type: policy")
 }
 
 func TestGenerateGatesYAML_Node(t *testing.T) {
-	yaml := GenerateGatesYAML([]Stack{StackNode})
+	yaml := generateGatesYAML(t, []Stack{StackNode})
 
 	assertYAMLContains(t, yaml, "version: 1")
 	assertYAMLContains(t, yaml, "eslint")
@@ -134,7 +206,7 @@ func TestGenerateGatesYAML_Node(t *testing.T) {
 }
 
 func TestGenerateGatesYAML_Python(t *testing.T) {
-	yaml := GenerateGatesYAML([]Stack{StackPython})
+	yaml := generateGatesYAML(t, []Stack{StackPython})
 
 	assertYAMLContains(t, yaml, "version: 1")
 	assertYAMLContains(t, yaml, "ruff")
@@ -142,9 +214,10 @@ func TestGenerateGatesYAML_Python(t *testing.T) {
 }
 
 func TestGenerateGatesYAML_NoStack(t *testing.T) {
-	yaml := GenerateGatesYAML(nil)
+	yaml := generateGatesYAML(t, nil)
 
 	assertYAMLContains(t, yaml, "version: 1")
+	assertYAMLContains(t, yaml, "name: hygiene")
 	// Should contain a commented example gate
 	if !strings.Contains(yaml, "#") {
 		t.Error("expected commented example in fallback config")
@@ -152,22 +225,68 @@ func TestGenerateGatesYAML_NoStack(t *testing.T) {
 }
 
 func TestGenerateGatesYAML_Monorepo(t *testing.T) {
-	yaml := GenerateGatesYAML([]Stack{StackGo, StackNode})
+	yaml := generateGatesYAML(t, []Stack{StackGo, StackNode})
 
 	assertYAMLContains(t, yaml, "go vet")
 	assertYAMLContains(t, yaml, "eslint")
 }
 
+func TestGenerateGatesYAML_DockerAndShell(t *testing.T) {
+	yaml := generateGatesYAML(t, []Stack{StackDocker, StackShell})
+
+	assertYAMLContains(t, yaml, "parser: hadolint-json")
+	assertYAMLContains(t, yaml, "parser: shellcheck-json")
+}
+
+func TestGenerateGatesYAML_Terraform(t *testing.T) {
+	yaml := generateGatesYAML(t, []Stack{StackTerraform})
+
+	assertYAMLContains(t, yaml, "terraform fmt -check")
+	assertYAMLContains(t, yaml, "terraform -chdir=")
+	assertYAMLContains(t, yaml, "parser: tflint-json")
+}
+
+func TestGenerateGatesYAML_Protobuf(t *testing.T) {
+	yaml := generateGatesYAML(t, []Stack{StackProtobuf})
+
+	assertYAMLContains(t, yaml, "buf lint --error-format json")
+	assertYAMLContains(t, yaml, "buf breaking")
+	assertYAMLContains(t, yaml, "against: main")
+	assertYAMLContains(t, yaml, "parser: buf-json")
+}
+
+func TestGenerateGatesYAML_Migrations(t *testing.T) {
+	yaml := generateGatesYAML(t, []Stack{StackMigrations})
+
+	assertYAMLContains(t, yaml, "name: migrations-append-only")
+	assertYAMLContains(t, yaml, "append_only:")
+	assertYAMLContains(t, yaml, "parser: squawk-json")
+}
+
+func TestGenerateGatesYAML_OpenAPI(t *testing.T) {
+	yaml := generateGatesYAML(t, []Stack{StackOpenAPI})
+
+	assertYAMLContains(t, yaml, "spectral lint --format json")
+	assertYAMLContains(t, yaml, "parser: spectral-json")
+	assertYAMLContains(t, yaml, "# - name: api-breaking-review")
+}
+
 func TestGenerateGatesYAML_Parseable(t *testing.T) {
 	// Verify generated YAML can be parsed back by our config types.
 	for _, stacks := range [][]Stack{
+		nil,
 		{StackGo},
 		{StackNode},
 		{StackPython},
 		{StackGo, StackNode},
 		{StackGo, StackNode, StackPython},
+		{StackDocker, StackShell},
+		{StackTerraform},
+		{StackProtobuf},
+		{StackMigrations},
+		{StackOpenAPI},
 	} {
-		yamlStr := GenerateGatesYAML(stacks)
+		yamlStr := generateGatesYAML(t, stacks)
 		var cfg GatekeeperConfig
 		err := yaml.Unmarshal([]byte(yamlStr), &cfg)
 		if err != nil {
@@ -179,7 +298,22 @@ func TestGenerateGatesYAML_Parseable(t *testing.T) {
 		if len(cfg.Gates) == 0 {
 			t.Errorf("GenerateGatesYAML(%v) produced no gates", stacks)
 		}
+		if err := validate(&cfg); err != nil {
+			t.Errorf("GenerateGatesYAML(%v) produced an invalid config: %v", stacks, err)
+		}
+		if !strings.HasPrefix(yamlStr, "# yaml-language-server: $schema="+SchemaURL+"\n") {
+			t.Errorf("GenerateGatesYAML(%v) does not start with the schema modeline", stacks)
+		}
+	}
+}
+
+func generateGatesYAML(t *testing.T, stacks []Stack) string {
+	t.Helper()
+	out, err := GenerateGatesYAML(stacks)
+	if err != nil {
+		t.Fatal(err)
 	}
+	return out
 }
 
 func assertYAMLContains(t *testing.T, yaml, substr string) {
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// gateField describes a gate setting compared by DiffGates.
+type gateField struct {
+	name string
+	get  func(g Gate) any
+	// verbose fields are reported as "changed" instead of old→new.
+	verbose bool
+}
+
+var diffFields = []gateField{
+	{name: "type", get: func(g Gate) any { return g.Type }},
+	{name: "command", get: func(g Gate) any { return g.Command }, verbose: true},
+	{name: "path", get: func(g Gate) any { return g.Path }},
+	{name: "container", get: func(g Gate) any { return g.Container }},
+	{name: "setup", get: func(g Gate) any { return g.Setup }, verbose: true},
+	{name: "reuse", get: func(g Gate) any { return g.Reuse }, verbose: true},
+	{name: "parser", get: func(g Gate) any { return g.Parser }},
+	{name: "timeout", get: func(g Gate) any { return g.Timeout }},
+	{name: "blocking", get: func(g Gate) any { return g.IsBlocking() }},
+	{name: "on_error", get: func(g Gate) any { return g.GetOnError() }},
+	{name: "only", get: func(g Gate) any { return g.Only }},
+	{name: "except", get: func(g Gate) any { return g.Except }},
+	{name: "writable", get: func(g Gate) any { return g.Writable }},
+	{name: "writable_policy", get: func(g Gate) any { return g.WritablePolicy }},
+	{name: "workdir", get: func(g Gate) any { return g.Workdir }},
+	{name: "against", get: func(g Gate) any { return g.Against }},
+	{name: "stdin", get: func(g Gate) any { return g.Stdin }},
+	{name: "provider", get: func(g Gate) any { return g.Provider }},
+	{name: "fallback_providers", get: func(g Gate) any { return g.FallbackProviders }},
+	{name: "prompt", get: func(g Gate) any { return g.Prompt }, verbose: true},
+	{name: "prompt_file", get: func(g Gate) any { return g.PromptFile }},
+	{name: "language", get: func(g Gate) any { return g.Language }},
+	{name: "review_vendored", get: func(g Gate) any { return g.ReviewVendored }},
+	{name: "min_confidence", get: func(g Gate) any { return g.MinConfidence }},
+	{name: "low_confidence", get: func(g Gate) any { return g.LowConfidence }},
+	{name: "affected_only", get: func(g Gate) any { return g.AffectedOnly }},
+	{name: "profiles", get: func(g Gate) any { return g.Profiles }},
+	{name: "parser_config", get: func(g Gate) any { return g.ParserConfig }, verbose: true},
+	{name: "coverage", get: func(g Gate) any { return g.Coverage }, verbose: true},
+	{name: "policy", get: func(g Gate) any { return g.Policy }, verbose: true},
+}
+
+// DiffGates returns a one-line, human-readable summary of how the gates changed,
+// e.g. "2 gates added: trivy, license-check; go-test timeout 1m0s→2m0s".
+// Returns "" if nothing relevant changed.
+func DiffGates(old, updated []Gate) string {
+	oldByName := make(map[string]Gate, len(old))
+	for _, g := range old {
+		oldByName[g.Name] = g
+	}
+	newNames := make(map[string]bool, len(updated))
+
+	var added, removed, changes []string
+	for _, g := range updated {
+		newNames[g.Name] = true
+		prev, ok := oldByName[g.Name]
+		if !ok {
+			added = append(added, g.Name)
+			continue
+		}
+		for _, f := range diffFields {
+			before, after := f.get(prev), f.get(g)
+			if reflect.DeepEqual(before, after) {
+				continue
+			}
+			if f.verbose {
+				changes = append(changes, fmt.Sprintf("%s %s changed", g.Name, f.name))
+			} else {
+				changes = append(changes, fmt.Sprintf("%s %s %s→%s", g.Name, f.name, formatDiffValue(before), formatDiffValue(after)))
+			}
+		}
+	}
+	for _, g := range old {
+		if !newNames[g.Name] {
+			removed = append(removed, g.Name)
+		}
+	}
+
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("%s added: %s", pluralGates(len(added)), strings.Join(added, ", ")))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%s removed: %s", pluralGates(len(removed)), strings.Join(removed, ", ")))
+	}
+	parts = append(parts, changes...)
+	return strings.Join(parts, "; ")
+}
+
+func pluralGates(n int) string {
+	if n == 1 {
+		return "1 gate"
+	}
+	return fmt.Sprintf("%d gates", n)
+}
+
+func formatDiffValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		if val == "" {
+			return "(none)"
+		}
+		return val
+	case GateType:
+		if val == "" {
+			return "(none)"
+		}
+		return string(val)
+	case []string:
+		if len(val) == 0 {
+			return "(none)"
+		}
+		return "[" + strings.Join(val, ", ") + "]"
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffGates(t *testing.T) {
+	old := []Gate{
+		{Name: "go-test", Type: GateTypeExec, Command: "go test ./...", Timeout: 60 * time.Second},
+		{Name: "lint", Type: GateTypeExec, Command: "golangci-lint run"},
+		{Name: "legacy", Type: GateTypeExec, Command: "make check"},
+	}
+	updated := []Gate{
+		{Name: "go-test", Type: GateTypeExec, Command: "go test -race ./...", Timeout: 120 * time.Second},
+		{Name: "lint", Type: GateTypeExec, Command: "golangci-lint run", Blocking: boolPtr(false)},
+		{Name: "trivy", Type: GateTypeExec, Command: "trivy fs ."},
+		{Name: "license-check", Type: GateTypeExec, Command: "licensed status"},
+	}
+
+	got := DiffGates(old, updated)
+	want := "2 gates added: trivy, license-check; 1 gate removed: legacy; go-test command changed; go-test timeout 1m0s→2m0s; lint blocking true→false"
+	if got != want {
+		t.Errorf("DiffGates() =\n  %q\nwant\n  %q", got, want)
+	}
+}
+
+func TestDiffGates_NoChanges(t *testing.T) {
+	gates := []Gate{{Name: "lint", Type: GateTypeExec, Command: "golangci-lint run", Only: []string{"*.go"}}}
+	if got := DiffGates(gates, gates); got != "" {
+		t.Errorf("expected no changes, got %q", got)
+	}
+}
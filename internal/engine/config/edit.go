@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrGateExists is returned when adding a gate whose name is already configured.
+var ErrGateExists = errors.New("gate already exists")
+
+// AppendGate adds a gate mapping (given as YAML) to the gates list of an existing
+// gates.yaml document and returns the updated document.
+//
+// The document is edited as a YAML node tree rather than as text, so comments and
+// formatting of the existing configuration are preserved. The result is validated
+// the same way Load validates, so a successful call always yields a loadable config.
+func AppendGate(data []byte, gateYAML string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing gates.yaml: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing gates.yaml: expected a mapping at the document root")
+	}
+	root := doc.Content[0]
+
+	gateNode, name, err := parseGateNode(gateYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	gates := mappingValue(root, "gates")
+	if gates == nil {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "gates"},
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"},
+		)
+		gates = root.Content[len(root.Content)-1]
+	}
+
+	switch gates.Kind {
+	case yaml.SequenceNode:
+		for _, existing := range gates.Content {
+			if n := mappingValue(existing, "name"); n != nil && n.Value == name {
+				return nil, fmt.Errorf("%w: %q", ErrGateExists, name)
+			}
+		}
+	case yaml.ScalarNode:
+		// "gates:" with no entries decodes as a null scalar; turn it into a sequence,
+		// keeping any comments (e.g., commented-out example gates) attached to it.
+		if gates.Tag != "!!null" {
+			return nil, fmt.Errorf("parsing gates.yaml: 'gates' must be a list")
+		}
+		gates.Kind = yaml.SequenceNode
+		gates.Tag = "!!seq"
+		gates.Value = ""
+	default:
+		return nil, fmt.Errorf("parsing gates.yaml: 'gates' must be a list")
+	}
+
+	gates.Style = 0
+	gates.Content = append(gates.Content, gateNode)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding gates.yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding gates.yaml: %w", err)
+	}
+
+	if err := validateDocument(buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseGateNode parses a single gate mapping and returns its node and name.
+func parseGateNode(gateYAML string) (*yaml.Node, string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(gateYAML), &doc); err != nil {
+		return nil, "", fmt.Errorf("parsing gate: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, "", fmt.Errorf("parsing gate: expected a mapping")
+	}
+
+	node := doc.Content[0]
+	name := mappingValue(node, "name")
+	if name == nil || name.Value == "" {
+		return nil, "", fmt.Errorf("parsing gate: missing required field 'name'")
+	}
+	return node, name.Value, nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// validateDocument decodes a full gates.yaml document and runs the standard validation.
+func validateDocument(data []byte) error {
+	var cfg GatekeeperConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parsing gates.yaml: %w", err)
+	}
+	applyDefaults(&cfg)
+	return validate(&cfg)
+}
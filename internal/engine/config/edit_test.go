@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const editBaseYAML = `# Project gates
+version: 1
+
+defaults:
+  timeout: 60s
+
+gates:
+  # Go checks
+  - name: go-vet
+    type: exec
+    command: "go vet ./..."
+    only: ["*.go"] # only Go files
+`
+
+const editGateYAML = `name: gosec
+type: exec
+command: "gosec ./..."
+container: "securego/gosec:latest"
+`
+
+func TestAppendGate_PreservesComments(t *testing.T) {
+	out, err := AppendGate([]byte(editBaseYAML), editGateYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := string(out)
+	for _, want := range []string{"# Project gates", "# Go checks", "# only Go files", "- name: gosec"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, s)
+		}
+	}
+
+	var cfg GatekeeperConfig
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatalf("output is not valid YAML: %v", err)
+	}
+	if len(cfg.Gates) != 2 || cfg.Gates[1].Name != "gosec" {
+		t.Errorf("expected gosec appended as second gate, got %+v", cfg.Gates)
+	}
+}
+
+func TestAppendGate_Duplicate(t *testing.T) {
+	_, err := AppendGate([]byte(editBaseYAML), "name: go-vet\ntype: exec\ncommand: go vet\n")
+	if !errors.Is(err, ErrGateExists) {
+		t.Fatalf("expected ErrGateExists, got %v", err)
+	}
+}
+
+func TestAppendGate_EmptyGates(t *testing.T) {
+	empty := "version: 1\n\ngates:\n  # Example gate — uncomment and customize:\n  # - name: lint\n"
+	out, err := AppendGate([]byte(empty), editGateYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var cfg GatekeeperConfig
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatalf("output is not valid YAML: %v", err)
+	}
+	if len(cfg.Gates) != 1 {
+		t.Fatalf("expected 1 gate, got %d", len(cfg.Gates))
+	}
+	if !strings.Contains(string(out), "# Example gate") {
+		t.Errorf("expected commented example to be preserved, got:\n%s", out)
+	}
+}
+
+func TestAppendGate_MissingGatesKey(t *testing.T) {
+	out, err := AppendGate([]byte("version: 1\n"), editGateYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), "gates:") {
+		t.Errorf("expected gates key to be created, got:\n%s", out)
+	}
+}
+
+func TestAppendGate_InvalidGate(t *testing.T) {
+	_, err := AppendGate([]byte(editBaseYAML), "name: broken\ntype: exec\n")
+	if err == nil || !strings.Contains(err.Error(), "command") {
+		t.Fatalf("expected validation error for missing command, got %v", err)
+	}
+}
+
+func TestAppendGate_NotAList(t *testing.T) {
+	_, err := AppendGate([]byte("gates: foo\n"), editGateYAML)
+	if err == nil {
+		t.Fatal("expected error when gates is not a list")
+	}
+}
+
+func TestTemplates_AllValid(t *testing.T) {
+	for _, tmpl := range Templates() {
+		g, err := tmpl.Gate()
+		if err != nil {
+			t.Errorf("template %q: %v", tmpl.Name, err)
+			continue
+		}
+		if g.Name != tmpl.Name {
+			t.Errorf("template %q: gate name %q does not match catalog key", tmpl.Name, g.Name)
+		}
+		cfg := &GatekeeperConfig{Gates: []Gate{g}}
+		if err := validate(cfg); err != nil {
+			t.Errorf("template %q: %v", tmpl.Name, err)
+		}
+	}
+}
+
+func TestLookupTemplate(t *testing.T) {
+	if _, ok := LookupTemplate("gosec"); !ok {
+		t.Error("expected gosec template to exist")
+	}
+	if _, ok := LookupTemplate("nope"); ok {
+		t.Error("expected unknown template lookup to fail")
+	}
+}
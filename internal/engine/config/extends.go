@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// presetCacheTTL is how long an unpinned remote preset is reused before it is fetched again.
+	presetCacheTTL = 24 * time.Hour
+	// maxExtendsDepth bounds chains of presets extending other presets.
+	maxExtendsDepth = 8
+	// maxPresetSize bounds the size of a fetched preset.
+	maxPresetSize = 1 << 20
+	// presetFetchTimeout bounds a single remote preset download.
+	presetFetchTimeout = 30 * time.Second
+)
+
+// presetSource is a parsed entry of the "extends" list.
+type presetSource struct {
+	// raw is the entry as written, without the checksum pin.
+	raw string
+	// url is the download URL of a remote preset; empty for local files.
+	url string
+	// path is the file path of a local preset.
+	path string
+	// sha256 is the pinned hex checksum, if any.
+	sha256 string
+}
+
+// parsePresetSource parses an extends entry, resolving local paths against dir.
+// Remote presets are "https://..." URLs or "github.com/<owner>/<repo>//<file>"
+// with an optional "@<ref>" (default HEAD). Any entry may end in
+// "#sha256:<hex>" to pin its content.
+func parsePresetSource(entry, dir string) (presetSource, error) {
+	src := presetSource{raw: entry}
+	if before, pin, ok := strings.Cut(entry, "#sha256:"); ok {
+		pin = strings.ToLower(strings.TrimSpace(pin))
+		if _, err := hex.DecodeString(pin); err != nil || len(pin) != sha256.Size*2 {
+			return src, fmt.Errorf("extends %q: checksum must be a 64-character hex SHA-256", entry)
+		}
+		src.raw, src.sha256 = before, pin
+	}
+
+	switch {
+	case strings.HasPrefix(src.raw, "https://"):
+		src.url = src.raw
+	case strings.HasPrefix(src.raw, "http://"):
+		// [SEC] Presets define the commands gates run; never fetch them in the clear.
+		return src, fmt.Errorf("extends %q: remote presets must use https", entry)
+	case strings.HasPrefix(src.raw, "github.com/"):
+		u, err := githubRawURL(strings.TrimPrefix(src.raw, "github.com/"))
+		if err != nil {
+			return src, fmt.Errorf("extends %q: %w", entry, err)
+		}
+		src.url = u
+	case dir == "":
+		return src, fmt.Errorf("extends %q: remote presets can only extend other remote presets", entry)
+	default:
+		src.path = filepath.Join(dir, filepath.FromSlash(src.raw))
+	}
+	return src, nil
+}
+
+// githubRawURL converts "<owner>/<repo>//<file>[@<ref>]" to its raw content URL.
+func githubRawURL(spec string) (string, error) {
+	repo, file, ok := strings.Cut(spec, "//")
+	if !ok || strings.Count(repo, "/") != 1 || file == "" {
+		return "", errors.New("expected github.com/<owner>/<repo>//<file>[@<ref>]")
+	}
+	ref := "HEAD"
+	if i := strings.LastIndex(file, "@"); i >= 0 {
+		file, ref = file[:i], file[i+1:]
+	}
+	if ref == "" || strings.Contains(path.Clean("/"+file), "..") {
+		return "", errors.New("invalid preset file or ref")
+	}
+	return "https://raw.githubusercontent.com/" + repo + "/" + ref + "/" + strings.TrimPrefix(file, "/"), nil
+}
+
+// resolveExtends loads the presets cfg extends and merges cfg over them. dir
+// resolves relative entries ("" for remote configs); chain holds the sources
+// being loaded, to detect cycles. The returned bytes are the preset contents,
+// for the config's SourceHash.
+func (l *Loader) resolveExtends(ctx context.Context, cfg *GatekeeperConfig, dir string, chain []string) ([]byte, error) {
+	if len(cfg.Extends) == 0 {
+		return nil, nil
+	}
+	if len(chain) > maxExtendsDepth {
+		return nil, fmt.Errorf("extends: presets nested more than %d levels deep", maxExtendsDepth)
+	}
+
+	var base GatekeeperConfig
+	var contents []byte
+	for _, entry := range cfg.Extends {
+		src, err := parsePresetSource(entry, dir)
+		if err != nil {
+			return nil, err
+		}
+		id := src.url + src.path
+		for _, seen := range chain {
+			if seen == id {
+				return nil, fmt.Errorf("extends %q: cycle detected", entry)
+			}
+		}
+
+		data, err := l.readPreset(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		if src.sha256 != "" && sha256Hex(data) != src.sha256 {
+			return nil, fmt.Errorf("extends %q: checksum mismatch (got sha256:%s)", src.raw, sha256Hex(data))
+		}
+
+		var preset GatekeeperConfig
+		if err := yaml.Unmarshal(data, &preset); err != nil {
+			return nil, fmt.Errorf("parsing preset %q: %w", src.raw, err)
+		}
+		presetDir := ""
+		if src.path != "" {
+			presetDir = filepath.Dir(src.path)
+		}
+		nested, err := l.resolveExtends(ctx, &preset, presetDir, append(chain, id))
+		if err != nil {
+			return nil, err
+		}
+		contents = append(append(contents, nested...), data...)
+		mergeConfig(&base, preset)
+	}
+
+	mergeConfig(&base, *cfg)
+	base.Extends = cfg.Extends
+	*cfg = base
+	return contents, nil
+}
+
+// readPreset returns the content of a local or remote preset.
+func (l *Loader) readPreset(ctx context.Context, src presetSource) ([]byte, error) {
+	if src.path != "" {
+		data, err := l.fs.ReadFile(src.path)
+		if err != nil {
+			return nil, fmt.Errorf("reading preset %q: %w", src.raw, err)
+		}
+		return data, nil
+	}
+	return l.fetchPreset(ctx, src)
+}
+
+// fetchPreset downloads a remote preset through the on-disk cache. Pinned
+// presets are served from the cache while its content matches the pin;
+// unpinned ones are refetched after presetCacheTTL, falling back to the stale
+// copy if the download fails.
+func (l *Loader) fetchPreset(ctx context.Context, src presetSource) ([]byte, error) {
+	log := logger.FromContext(ctx)
+	cachePath := ""
+	var cached []byte
+	if l.presetCacheDir != "" {
+		cachePath = filepath.Join(l.presetCacheDir, sha256Hex([]byte(src.url))+".yaml")
+		if info, err := os.Stat(cachePath); err == nil {
+			cached, _ = os.ReadFile(cachePath) // #nosec G304 -- path is a hex digest inside the cache directory
+			fresh := time.Since(info.ModTime()) < presetCacheTTL
+			if src.sha256 != "" {
+				fresh = sha256Hex(cached) == src.sha256
+			}
+			if cached != nil && fresh {
+				log.Debug("using cached preset", "source", src.raw)
+				return cached, nil
+			}
+		}
+	}
+
+	log.Debug("fetching preset", "url", src.url)
+	data, err := l.fetch(ctx, src.url)
+	if err != nil {
+		if cached != nil && src.sha256 == "" {
+			log.Warn("could not refresh preset, using cached copy", "source", src.raw, "error", err)
+			return cached, nil
+		}
+		return nil, fmt.Errorf("fetching preset %q: %w", src.raw, err)
+	}
+
+	if cachePath != "" {
+		if err := os.MkdirAll(l.presetCacheDir, 0o750); err != nil {
+			log.Warn("could not cache preset", "source", src.raw, "error", err)
+		} else if err := fileutil.WriteFileAtomic(cachePath, data, 0o600); err != nil {
+			log.Warn("could not cache preset", "source", src.raw, "error", err)
+		}
+	}
+	return data, nil
+}
+
+// httpFetch downloads url, failing on non-2xx responses.
+func httpFetch(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, presetFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPresetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxPresetSize {
+		return nil, fmt.Errorf("preset larger than %d bytes", maxPresetSize)
+	}
+	return data, nil
+}
+
+// defaultPresetCacheDir returns the user-level preset cache directory
+// (~/.cache/gatekeeper/presets on Linux), or "" if there is none.
+func defaultPresetCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "gatekeeper", "presets")
+}
+
+// mergeConfig merges over into base: set defaults, llm_policy, and branches
+// fields win, branch overrides are appended, and gates replace base gates of the same name
+// in place or are appended.
+func mergeConfig(base *GatekeeperConfig, over GatekeeperConfig) {
+	if over.Version != 0 {
+		base.Version = over.Version
+	}
+	mergeDefaults(&base.Defaults, over.Defaults)
+	base.Overrides = append(base.Overrides, over.Overrides...)
+	if over.LLMPolicy.DeferAfter != 0 {
+		base.LLMPolicy.DeferAfter = over.LLMPolicy.DeferAfter
+	}
+	if over.LLMPolicy.MaxMedianLatency != 0 {
+		base.LLMPolicy.MaxMedianLatency = over.LLMPolicy.MaxMedianLatency
+	}
+	if over.Branches.BlockCommitOn != nil {
+		base.Branches.BlockCommitOn = over.Branches.BlockCommitOn
+	}
+	if over.Branches.Naming != "" {
+		base.Branches.Naming = over.Branches.Naming
+	}
+
+	index := make(map[string]int, len(base.Gates))
+	for i, g := range base.Gates {
+		index[g.Name] = i
+	}
+	for _, g := range over.Gates {
+		if i, ok := index[g.Name]; ok && g.Name != "" {
+			base.Gates[i] = g
+			continue
+		}
+		index[g.Name] = len(base.Gates)
+		base.Gates = append(base.Gates, g)
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
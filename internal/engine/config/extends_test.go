@@ -0,0 +1,203 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const basePreset = `version: 1
+defaults:
+  container: golang:1.25
+  timeout: 1m
+gates:
+  - name: vet
+    type: exec
+    command: go vet ./...
+  - name: test
+    type: exec
+    command: go test ./...
+`
+
+func TestLoad_ExtendsLocal(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files[filepath.Join(".gatekeeper", "base-gates.yaml")] = []byte(basePreset)
+	mockFS.Files[filepath.Join(".gatekeeper", "gates.yaml")] = []byte(`version: 1
+extends: ["./base-gates.yaml"]
+defaults:
+  timeout: 2m
+gates:
+  - name: test
+    type: exec
+    command: go test -race ./...
+  - name: lint
+    type: exec
+    command: golangci-lint run
+`)
+
+	cfg, err := NewLoader(mockFS).Load(context.Background(), filepath.Join(".gatekeeper", "gates.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, g := range cfg.Gates {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, ",") != "vet,test,lint" {
+		t.Fatalf("gates = %v, want vet,test,lint", names)
+	}
+	if cfg.Gates[1].Command != "go test -race ./..." {
+		t.Errorf("local gate must replace the preset gate, got command %q", cfg.Gates[1].Command)
+	}
+	if cfg.Gates[0].Container != "golang:1.25" || cfg.Gates[0].Timeout != 2*time.Minute {
+		t.Errorf("vet = %s/%s, want preset container and local timeout", cfg.Gates[0].Container, cfg.Gates[0].Timeout)
+	}
+}
+
+func TestLoad_ExtendsRemote(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["gates.yaml"] = []byte(`version: 1
+extends: ["github.com/org/presets//go.yaml@v1"]
+`)
+	loader := NewLoader(mockFS)
+	loader.presetCacheDir = t.TempDir()
+	var fetched []string
+	loader.fetch = func(_ context.Context, url string) ([]byte, error) {
+		fetched = append(fetched, url)
+		return []byte(basePreset), nil
+	}
+
+	for range 2 {
+		cfg, err := loader.Load(context.Background(), "gates.yaml")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(cfg.Gates) != 2 {
+			t.Fatalf("got %d gates, want 2", len(cfg.Gates))
+		}
+	}
+	if len(fetched) != 1 {
+		t.Fatalf("fetched %d times, want 1 (second load served from cache)", len(fetched))
+	}
+	if fetched[0] != "https://raw.githubusercontent.com/org/presets/v1/go.yaml" {
+		t.Errorf("fetched %q", fetched[0])
+	}
+
+	// A failed refresh of a stale entry falls back to the cached copy.
+	loader.presetCacheDir = t.TempDir()
+	if _, err := loader.Load(context.Background(), "gates.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	loader.fetch = func(context.Context, string) ([]byte, error) { return nil, errors.New("offline") }
+	stale := time.Now().Add(-2 * presetCacheTTL)
+	entries, _ := filepath.Glob(filepath.Join(loader.presetCacheDir, "*.yaml"))
+	for _, e := range entries {
+		if err := os.Chtimes(e, stale, stale); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := loader.Load(context.Background(), "gates.yaml"); err != nil {
+		t.Errorf("expected stale cache fallback, got %v", err)
+	}
+}
+
+func TestLoad_ExtendsChecksum(t *testing.T) {
+	sum := sha256Hex([]byte(basePreset))
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr string
+	}{
+		{"match", sum, ""},
+		{"mismatch", strings.Repeat("0", 64), "checksum mismatch"},
+		{"malformed", "abc", "64-character hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := NewMockFileSystem()
+			mockFS.Files["gates.yaml"] = []byte("version: 1\nextends: [\"https://example.com/go.yaml#sha256:" + tt.pin + "\"]\n")
+			loader := NewLoader(mockFS)
+			loader.presetCacheDir = ""
+			loader.fetch = func(context.Context, string) ([]byte, error) { return []byte(basePreset), nil }
+
+			_, err := loader.Load(context.Background(), "gates.yaml")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.yaml": "extends: [\"./b.yaml\"]\n",
+				"b.yaml": "extends: [\"./a.yaml\"]\n",
+			},
+			wantErr: "cycle detected",
+		},
+		{
+			name:    "insecure",
+			files:   map[string]string{"a.yaml": "extends: [\"http://example.com/go.yaml\"]\n"},
+			wantErr: "must use https",
+		},
+		{
+			name:    "missing",
+			files:   map[string]string{"a.yaml": "extends: [\"./nope.yaml\"]\n"},
+			wantErr: "reading preset",
+		},
+		{
+			name: "invalid combined",
+			files: map[string]string{
+				"a.yaml":    "extends: [\"./base.yaml\"]\ngates:\n  - name: vet\n    type: script\n",
+				"base.yaml": basePreset,
+			},
+			wantErr: "missing required field 'path'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := NewMockFileSystem()
+			for name, content := range tt.files {
+				mockFS.Files[name] = []byte(content)
+			}
+			_, err := NewLoader(mockFS).Load(context.Background(), "a.yaml")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePresetSource_GitHub(t *testing.T) {
+	src, err := parsePresetSource("github.com/org/presets//lang/go.yaml", ".gatekeeper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.url != "https://raw.githubusercontent.com/org/presets/HEAD/lang/go.yaml" {
+		t.Errorf("url = %q", src.url)
+	}
+	if _, err := parsePresetSource("github.com/org/go.yaml", ".gatekeeper"); err == nil {
+		t.Error("expected error for a source without //")
+	}
+	if _, err := parsePresetSource("./local.yaml", ""); err == nil {
+		t.Error("expected error for a local preset extended by a remote one")
+	}
+}
@@ -2,13 +2,17 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/secrets"
 	"gopkg.in/yaml.v3"
 )
 
@@ -19,7 +23,11 @@ func (s SecretString) String() string {
 	return "[REDACTED]"
 }
 
+// MarshalYAML redacts the secret; an unset secret stays empty.
 func (s SecretString) MarshalYAML() (interface{}, error) {
+	if s.IsEmpty() {
+		return "", nil
+	}
 	return s.String(), nil
 }
 
@@ -30,26 +38,171 @@ func (s SecretString) IsEmpty() bool {
 
 // GlobalConfig holds user-level settings that persist across projects.
 type GlobalConfig struct {
-	GeminiAPIKey  SecretString  `yaml:"gemini_api_key"`
-	ContainerTTL  time.Duration `yaml:"container_ttl"`
-	OutputColor   bool          `yaml:"-"` // derived from Output.Color
-	OutputVerbose bool          `yaml:"-"` // derived from Output.Verbose
-	Output        OutputConfig  `yaml:"output"`
+	GeminiAPIKey     SecretString  `yaml:"gemini_api_key"`
+	GeminiAPIKeyFrom string        `yaml:"gemini_api_key_from"` // "keychain" or "command: <cmd>" instead of a plaintext key
+	ContainerTTL     time.Duration `yaml:"container_ttl"`
+	OutputColor      bool          `yaml:"-"` // derived from Output.Color
+	OutputVerbose    bool          `yaml:"-"` // derived from Output.Verbose
+	OutputAccessible bool          `yaml:"-"` // derived from Output.Accessibility
+	Output           OutputConfig  `yaml:"output"`
+
+	// OpenAIAPIKey authenticates LLM gates using OpenAI models.
+	OpenAIAPIKey     SecretString `yaml:"openai_api_key"`
+	OpenAIAPIKeyFrom string       `yaml:"openai_api_key_from"`
+	// OllamaURL is the Ollama server of ollama/<model> providers (default
+	// http://localhost:11434).
+	OllamaURL string `yaml:"ollama_url"`
+
+	// LLMBaseURL routes all LLM provider traffic through a custom endpoint (e.g., an internal gateway).
+	LLMBaseURL string `yaml:"llm_base_url"`
+	// LLMHTTP customizes the HTTP client used for all LLM providers.
+	LLMHTTP LLMHTTPConfig `yaml:"llm_http"`
+	// LLMCacheTTL is how long LLM review results are reused for an identical prompt (0 disables the cache).
+	LLMCacheTTL time.Duration `yaml:"llm_cache_ttl"`
+	// MaxTokensPerRun caps the LLM tokens spent by a run (0 means unlimited).
+	// LLM gates that would exceed it are skipped with a warning.
+	MaxTokensPerRun int `yaml:"max_tokens_per_run"`
+	// LLMLimits paces the calls of parallel LLM gates to each provider.
+	LLMLimits LLMLimitsConfig `yaml:"llm_limits"`
+	// DockerDrivePrefix is where the Docker daemon sees Windows drives when
+	// bind-mounting the project: "/mnt" (WSL2, the default) or "/" (Docker
+	// Desktop). Ignored on other platforms.
+	DockerDrivePrefix string `yaml:"docker_drive_prefix"`
+	// FailFast is the fail-fast default of projects whose gates.yaml does not
+	// set defaults.fail_fast. --fail-fast takes precedence over both.
+	FailFast *bool `yaml:"fail_fast"`
+	// DefaultProfile is the gate profile run when --profile is not given. It
+	// takes precedence over the project's defaults.profile.
+	DefaultProfile string `yaml:"default_profile"`
+	// UpdateNotice announces a newer gatekeeper release after runs, at most
+	// once a week (default true).
+	UpdateNotice *bool `yaml:"update_notice"`
+	// AuditLog is the path of the audit log. Relative paths are resolved
+	// against the project directory (default ".gatekeeper/audit.log").
+	AuditLog string `yaml:"audit_log"`
+	// History limits the runs kept in .gatekeeper/results/history.
+	History HistoryConfig `yaml:"history"`
+	// Metrics configures pushing run timings to a Prometheus Pushgateway.
+	Metrics MetricsConfig `yaml:"metrics"`
+	// Attestation configures how 'gatekeeper attest' signs run results.
+	Attestation AttestationConfig `yaml:"attestation"`
+	// Runtime is where container gates run: "docker" (the default) or
+	// "kubernetes", which runs each gate execution as a pod.
+	Runtime string `yaml:"runtime"`
+	// Kubernetes configures the kubernetes runtime.
+	Kubernetes KubernetesConfig `yaml:"kubernetes"`
+	// Local restricts the environment of gates run on the host, with
+	// container: local or --no-docker.
+	Local LocalConfig `yaml:"local"`
+}
+
+// LocalConfig holds the environment of gates run on the host.
+type LocalConfig struct {
+	// Path lists the directories local gates may run programs from. If empty,
+	// the host PATH is used.
+	Path []string `yaml:"path"`
+	// Env lists additional environment variables passed to local gates.
+	Env []string `yaml:"env"`
+	// Disabled refuses gates that run programs on the host: container: local
+	// and nix gates, --no-docker, and exec: parser plugins.
+	Disabled bool `yaml:"disabled"`
+}
+
+// Container gate runtimes.
+const (
+	RuntimeDocker     = "docker"
+	RuntimeKubernetes = "kubernetes"
+)
+
+// KubernetesConfig holds options for running gates as Kubernetes pods.
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig file (default: kubectl's own resolution).
+	Kubeconfig string `yaml:"kubeconfig"`
+	// Context is the kubeconfig context (default: the current context).
+	Context string `yaml:"context"`
+	// Namespace is where gate pods are created (default: the context's namespace).
+	Namespace string `yaml:"namespace"`
+	// ServiceAccount is the service account of gate pods.
+	ServiceAccount string `yaml:"service_account"`
+	// InitImage is the image that unpacks the workspace into gate pods.
+	InitImage string `yaml:"init_image"`
+	// CPU is the CPU request and limit of gate pods, e.g. "500m".
+	CPU string `yaml:"cpu"`
+	// Memory is the memory request and limit of gate pods, e.g. "1Gi".
+	Memory string `yaml:"memory"`
+}
+
+// AttestationConfig holds options for signing run results.
+type AttestationConfig struct {
+	// Key is the PEM ed25519 private key file (default
+	// ~/.config/gatekeeper/attestation.key).
+	Key string `yaml:"key"`
+	// Keyless signs with Sigstore and the user's OIDC identity instead of a key.
+	Keyless bool `yaml:"keyless"`
+}
+
+// HistoryConfig holds the retention of recorded runs, applied after each run.
+// Zero values leave a limit off.
+type HistoryConfig struct {
+	// MaxRuns is the number of runs kept (default 100).
+	MaxRuns int `yaml:"max_runs"`
+	// MaxAge is how long a run is kept (default 720h, 30 days).
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// MetricsConfig holds options for exporting run metrics.
+type MetricsConfig struct {
+	// PushgatewayURL is the Pushgateway base URL. If empty, metrics are not pushed.
+	PushgatewayURL string `yaml:"pushgateway_url"`
+	// Job is the job label of pushed metrics (default "gatekeeper").
+	Job string `yaml:"job"`
+}
+
+// LLMLimitsConfig holds client-side limits on the calls to each LLM provider,
+// shared by the gates of a run. Zero values leave a limit off.
+type LLMLimitsConfig struct {
+	// RequestsPerMinute spaces the starts of calls evenly over a minute.
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Concurrency caps the calls in flight at once.
+	Concurrency int `yaml:"concurrency"`
+}
+
+// LLMHTTPConfig holds HTTP client options for LLM gateways and proxies.
+// Header values may reference environment variables as $VAR or ${VAR}.
+type LLMHTTPConfig struct {
+	Headers map[string]SecretString `yaml:"headers"`
+	// Proxy is the HTTP(S) proxy URL. If empty, the standard proxy environment variables apply.
+	Proxy string `yaml:"proxy"`
+	// CACert is a PEM file with additional root CAs (e.g., a corporate gateway CA).
+	CACert string `yaml:"ca_cert"`
+	// ClientCert and ClientKey are PEM files for mTLS.
+	ClientCert string `yaml:"client_cert"`
+	ClientKey  string `yaml:"client_key"`
 }
 
 // OutputConfig holds output-related user preferences.
 type OutputConfig struct {
 	Color   *bool `yaml:"color"`
 	Verbose *bool `yaml:"verbose"`
+	// Accessibility pairs status icons with words, uses high-contrast colors, and
+	// ends reports with a plain-text summary for screen readers.
+	Accessibility *bool `yaml:"accessibility"`
 }
 
 const defaultContainerTTL = 5 * time.Minute
 
+const defaultLLMCacheTTL = 24 * time.Hour
+
+const (
+	defaultHistoryMaxRuns = 100
+	defaultHistoryMaxAge  = 30 * 24 * time.Hour
+)
+
 // LoadGlobalConfig reads user-level configuration from ~/.config/gatekeeper/config.yaml.
 // If the file does not exist, default values are returned (not an error).
 // Environment variables override file values.
 func (l *Loader) LoadGlobalConfig(ctx context.Context) (*GlobalConfig, error) {
-	home, err := l.fs.UserHomeDir()
+	path, err := l.globalConfigPath()
 	if err != nil {
 		// Cannot determine home directory — use defaults.
 		cfg := defaultGlobalConfig()
@@ -57,10 +210,18 @@ func (l *Loader) LoadGlobalConfig(ctx context.Context) (*GlobalConfig, error) {
 		applyEnvOverrides(cfg, l.getenv, log)
 		return cfg, nil
 	}
-	path := filepath.Join(home, ".config", "gatekeeper", "config.yaml")
 	return l.LoadGlobalConfigFrom(ctx, path)
 }
 
+// globalConfigPath returns the path of the user config.
+func (l *Loader) globalConfigPath() (string, error) {
+	home, err := l.fs.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gatekeeper", "config.yaml"), nil
+}
+
 // LoadGlobalConfigFrom reads user-level configuration from a specific path.
 // If the file does not exist, default values are returned (not an error).
 // Environment variables override file values.
@@ -91,17 +252,99 @@ func (l *Loader) LoadGlobalConfigFrom(ctx context.Context, path string) (*Global
 	if cfg.Output.Verbose != nil {
 		cfg.OutputVerbose = *cfg.Output.Verbose
 	}
+	if cfg.Output.Accessibility != nil {
+		cfg.OutputAccessible = *cfg.Output.Accessibility
+	}
+
+	for name, value := range cfg.LLMHTTP.Headers {
+		cfg.LLMHTTP.Headers[name] = SecretString(os.Expand(string(value), l.getenv))
+	}
 
+	if err := checkAPIKeySources(cfg); err != nil {
+		return nil, fmt.Errorf("validating global config: %w", err)
+	}
+	l.resolveAPIKeys(ctx, cfg, log)
 	applyEnvOverrides(cfg, l.getenv, log)
 
+	if err := validateGlobal(cfg); err != nil {
+		return nil, fmt.Errorf("validating global config: %w", err)
+	}
+
 	return cfg, nil
 }
 
+// validateGlobal checks the values of a user config.
+func validateGlobal(cfg *GlobalConfig) error {
+	if err := validateLLMHTTP(cfg); err != nil {
+		return err
+	}
+	for _, e := range apiKeyEnvs {
+		if source := e.from(cfg); source != "" {
+			if err := secrets.Validate(source); err != nil {
+				return fmt.Errorf("%s_from: %w", e.name, err)
+			}
+		}
+	}
+	if cfg.History.MaxRuns < 0 {
+		return fmt.Errorf("history.max_runs: must not be negative, got %d", cfg.History.MaxRuns)
+	}
+	if cfg.History.MaxAge < 0 {
+		return fmt.Errorf("history.max_age: must not be negative, got %s", cfg.History.MaxAge)
+	}
+	switch cfg.Runtime {
+	case "", RuntimeDocker, RuntimeKubernetes:
+	default:
+		return fmt.Errorf("runtime: must be %q or %q, got %q", RuntimeDocker, RuntimeKubernetes, cfg.Runtime)
+	}
+	return nil
+}
+
+// validateLLMHTTP checks the LLM endpoint, HTTP client, cache, and budget options.
+func validateLLMHTTP(cfg *GlobalConfig) error {
+	var errs []error
+	if cfg.LLMCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("llm_cache_ttl: must not be negative, got %s", cfg.LLMCacheTTL))
+	}
+	if cfg.MaxTokensPerRun < 0 {
+		errs = append(errs, fmt.Errorf("max_tokens_per_run: must not be negative, got %d", cfg.MaxTokensPerRun))
+	}
+	if cfg.LLMLimits.RequestsPerMinute < 0 {
+		errs = append(errs, fmt.Errorf("llm_limits.requests_per_minute: must not be negative, got %d", cfg.LLMLimits.RequestsPerMinute))
+	}
+	if cfg.LLMLimits.Concurrency < 0 {
+		errs = append(errs, fmt.Errorf("llm_limits.concurrency: must not be negative, got %d", cfg.LLMLimits.Concurrency))
+	}
+	if cfg.LLMBaseURL != "" {
+		if u, err := url.Parse(cfg.LLMBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("llm_base_url: invalid URL %q", cfg.LLMBaseURL))
+		}
+	}
+	if cfg.OllamaURL != "" {
+		if u, err := url.Parse(cfg.OllamaURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("ollama_url: invalid URL %q", cfg.OllamaURL))
+		}
+	}
+	if cfg.LLMHTTP.Proxy != "" {
+		if u, err := url.Parse(cfg.LLMHTTP.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("llm_http.proxy: invalid URL %q", cfg.LLMHTTP.Proxy))
+		}
+	}
+	if (cfg.LLMHTTP.ClientCert == "") != (cfg.LLMHTTP.ClientKey == "") {
+		errs = append(errs, fmt.Errorf("llm_http: client_cert and client_key must be set together"))
+	}
+	return errors.Join(errs...)
+}
+
 // LoadGlobalConfig reads user-level configuration using the real file system.
 func LoadGlobalConfig(ctx context.Context) (*GlobalConfig, error) {
 	return NewLoader(&RealFileSystem{}).LoadGlobalConfig(ctx)
 }
 
+// GlobalConfigPath returns the path of the user config, ~/.config/gatekeeper/config.yaml.
+func GlobalConfigPath() (string, error) {
+	return NewLoader(&RealFileSystem{}).globalConfigPath()
+}
+
 // LoadGlobalConfigFrom reads user-level configuration from a specific path using the real file system.
 func LoadGlobalConfigFrom(ctx context.Context, path string) (*GlobalConfig, error) {
 	return NewLoader(&RealFileSystem{}).LoadGlobalConfigFrom(ctx, path)
@@ -111,6 +354,91 @@ func defaultGlobalConfig() *GlobalConfig {
 	return &GlobalConfig{
 		ContainerTTL: defaultContainerTTL,
 		OutputColor:  true,
+		LLMCacheTTL:  defaultLLMCacheTTL,
+		History:      HistoryConfig{MaxRuns: defaultHistoryMaxRuns, MaxAge: defaultHistoryMaxAge},
+	}
+}
+
+// apiKeyEnv lists the environment variables that supply a provider's API key.
+// The gatekeeper-specific variable overrides the config file; the ecosystem-standard
+// variables, in order of precedence, are used only if no key is configured.
+type apiKeyEnv struct {
+	name     string
+	override string
+	standard []string
+	key      func(cfg *GlobalConfig) *SecretString
+	// from is the secret source of the key, read if override is not set.
+	from func(cfg *GlobalConfig) string
+}
+
+// apiKeyEnvs has one entry per LLM provider. Standard variables follow the
+// precedence of the provider's own SDK (the genai SDK prefers GOOGLE_API_KEY).
+var apiKeyEnvs = []apiKeyEnv{
+	{
+		name:     "gemini_api_key",
+		override: "GATEKEEPER_GEMINI_KEY",
+		standard: []string{"GOOGLE_API_KEY", "GEMINI_API_KEY"},
+		key:      func(cfg *GlobalConfig) *SecretString { return &cfg.GeminiAPIKey },
+		from:     func(cfg *GlobalConfig) string { return cfg.GeminiAPIKeyFrom },
+	},
+	{
+		name:     "openai_api_key",
+		override: "GATEKEEPER_OPENAI_KEY",
+		standard: []string{"OPENAI_API_KEY"},
+		key:      func(cfg *GlobalConfig) *SecretString { return &cfg.OpenAIAPIKey },
+		from:     func(cfg *GlobalConfig) string { return cfg.OpenAIAPIKeyFrom },
+	},
+}
+
+// checkAPIKeySources rejects API keys set both in plaintext and from a
+// secret source.
+func checkAPIKeySources(cfg *GlobalConfig) error {
+	var errs []error
+	for _, e := range apiKeyEnvs {
+		if e.from(cfg) != "" && !e.key(cfg).IsEmpty() {
+			errs = append(errs, fmt.Errorf("%s: set %s or %s_from, not both", e.name, e.name, e.name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveAPIKeys reads the API keys configured with a secret source, unless
+// the gatekeeper-specific variable overrides them. A key that cannot be read
+// is left unset with a warning, so runs without LLM gates are not blocked by
+// a locked keychain.
+func (l *Loader) resolveAPIKeys(ctx context.Context, cfg *GlobalConfig, log *slog.Logger) {
+	for _, e := range apiKeyEnvs {
+		source := e.from(cfg)
+		if source == "" || l.getenv(e.override) != "" {
+			continue
+		}
+		v, err := l.secret(ctx, source, e.name)
+		if err != nil {
+			log.Warn("could not read API key from its secret source", "setting", e.name+"_from", "error", err)
+			continue
+		}
+		*e.key(cfg) = SecretString(v)
+	}
+}
+
+// applyAPIKeyEnv sets provider API keys from the environment per apiKeyEnvs.
+func applyAPIKeyEnv(cfg *GlobalConfig, getenv func(string) string, log *slog.Logger) {
+	for _, e := range apiKeyEnvs {
+		key := e.key(cfg)
+		if v := getenv(e.override); v != "" {
+			*key = SecretString(v)
+			continue
+		}
+		if *key != "" {
+			continue
+		}
+		for _, name := range e.standard {
+			if v := getenv(name); v != "" {
+				*key = SecretString(v)
+				log.Debug("using API key from environment", "variable", name)
+				break
+			}
+		}
 	}
 }
 
@@ -118,9 +446,7 @@ func defaultGlobalConfig() *GlobalConfig {
 // The getenv parameter abstracts os.Getenv for testability.
 // The log parameter provides structured logging consistent with the rest of the codebase.
 func applyEnvOverrides(cfg *GlobalConfig, getenv func(string) string, log *slog.Logger) {
-	if key := getenv("GATEKEEPER_GEMINI_KEY"); key != "" {
-		cfg.GeminiAPIKey = SecretString(key)
-	}
+	applyAPIKeyEnv(cfg, getenv, log)
 
 	if ttlStr := getenv("GATEKEEPER_TTL"); ttlStr != "" {
 		d, err := time.ParseDuration(ttlStr)
@@ -131,6 +457,42 @@ func applyEnvOverrides(cfg *GlobalConfig, getenv func(string) string, log *slog.
 		}
 	}
 
+	if baseURL := getenv("GATEKEEPER_LLM_BASE_URL"); baseURL != "" {
+		cfg.LLMBaseURL = baseURL
+	}
+
+	if host := getenv("OLLAMA_HOST"); host != "" && cfg.OllamaURL == "" {
+		// Ollama's own variable is commonly host:port without a scheme.
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		cfg.OllamaURL = host
+	}
+
+	if prefix := getenv("GATEKEEPER_DOCKER_DRIVE_PREFIX"); prefix != "" {
+		cfg.DockerDrivePrefix = prefix
+	}
+
+	if profile := getenv("GATEKEEPER_PROFILE"); profile != "" {
+		cfg.DefaultProfile = profile
+	}
+
+	if auditLog := getenv("GATEKEEPER_AUDIT_LOG"); auditLog != "" {
+		cfg.AuditLog = auditLog
+	}
+
+	if key := getenv("GATEKEEPER_ATTESTATION_KEY"); key != "" {
+		cfg.Attestation.Key = key
+	}
+
+	if runtime := getenv("GATEKEEPER_RUNTIME"); runtime != "" {
+		cfg.Runtime = runtime
+	}
+
+	if pushgateway := getenv("GATEKEEPER_PUSHGATEWAY_URL"); pushgateway != "" {
+		cfg.Metrics.PushgatewayURL = pushgateway
+	}
+
 	if noColor := getenv("GATEKEEPER_NO_COLOR"); noColor != "" {
 		// Any truthy value disables color.
 		noColor = strings.ToLower(noColor)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
 	"testing"
 	"time"
 )
@@ -17,6 +18,7 @@ container_ttl: 10m
 output:
   color: false
   verbose: true
+  accessibility: true
 `)
 
 	loader := NewLoader(mockFS)
@@ -37,6 +39,9 @@ output:
 	if !cfg.OutputVerbose {
 		t.Error("expected OutputVerbose true")
 	}
+	if !cfg.OutputAccessible {
+		t.Error("expected OutputAccessible true")
+	}
 }
 
 func TestLoadGlobalConfig_MissingFile(t *testing.T) {
@@ -87,6 +92,114 @@ container_ttl: 10m
 	}
 }
 
+func TestLoadGlobalConfig_Metrics(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	path := "/config.yaml"
+	mockFS.Files[path] = []byte(`
+metrics:
+  pushgateway_url: http://file:9091
+  job: ci
+`)
+
+	loader := NewLoader(mockFS)
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Metrics.PushgatewayURL != "http://file:9091" || cfg.Metrics.Job != "ci" {
+		t.Errorf("unexpected metrics config: %+v", cfg.Metrics)
+	}
+
+	t.Setenv("GATEKEEPER_PUSHGATEWAY_URL", "http://env:9091")
+	t.Setenv("GATEKEEPER_DOCKER_DRIVE_PREFIX", "/")
+	cfg, err = loader.LoadGlobalConfigFrom(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Metrics.PushgatewayURL != "http://env:9091" {
+		t.Errorf("expected env-overridden pushgateway URL, got %q", cfg.Metrics.PushgatewayURL)
+	}
+	if cfg.DockerDrivePrefix != "/" {
+		t.Errorf("expected env-overridden drive prefix, got %q", cfg.DockerDrivePrefix)
+	}
+}
+
+func TestLoadGlobalConfig_DefaultProfile(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("default_profile: full\n")
+	env := map[string]string{}
+	loader := NewLoaderWithEnv(mockFS, func(k string) string { return env[k] })
+
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DefaultProfile != "full" {
+		t.Errorf("DefaultProfile = %q, want full", cfg.DefaultProfile)
+	}
+
+	env["GATEKEEPER_PROFILE"] = "fast"
+	cfg, err = loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DefaultProfile != "fast" {
+		t.Errorf("expected GATEKEEPER_PROFILE to override the file, got %q", cfg.DefaultProfile)
+	}
+}
+
+func TestLoadGlobalConfig_Attestation(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("attestation:\n  key: /keys/attest.key\n  keyless: true\n")
+	env := map[string]string{}
+	loader := NewLoaderWithEnv(mockFS, func(k string) string { return env[k] })
+
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Attestation.Key != "/keys/attest.key" || !cfg.Attestation.Keyless {
+		t.Errorf("Attestation = %+v", cfg.Attestation)
+	}
+
+	env["GATEKEEPER_ATTESTATION_KEY"] = "/ci/attest.key"
+	cfg, err = loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Attestation.Key != "/ci/attest.key" {
+		t.Errorf("expected GATEKEEPER_ATTESTATION_KEY to override the file, got %q", cfg.Attestation.Key)
+	}
+}
+
+func TestLoadGlobalConfig_Runtime(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("runtime: kubernetes\nkubernetes:\n  context: ci\n  namespace: gates\n  service_account: runner\nlocal:\n  path: [/usr/bin, /bin]\n  env: [GOFLAGS]\n  disabled: true\n")
+	env := map[string]string{}
+	loader := NewLoaderWithEnv(mockFS, func(k string) string { return env[k] })
+
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Runtime != RuntimeKubernetes || cfg.Kubernetes.Context != "ci" || cfg.Kubernetes.Namespace != "gates" || cfg.Kubernetes.ServiceAccount != "runner" {
+		t.Errorf("unexpected config: %q %+v", cfg.Runtime, cfg.Kubernetes)
+	}
+	if len(cfg.Local.Path) != 2 || cfg.Local.Env[0] != "GOFLAGS" || !cfg.Local.Disabled {
+		t.Errorf("Local = %+v", cfg.Local)
+	}
+
+	env["GATEKEEPER_RUNTIME"] = "docker"
+	if cfg, err = loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err != nil || cfg.Runtime != RuntimeDocker {
+		t.Errorf("expected GATEKEEPER_RUNTIME to override the file, got %q, %v", cfg.Runtime, err)
+	}
+
+	env["GATEKEEPER_RUNTIME"] = "podman"
+	if _, err := loader.LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err == nil {
+		t.Error("expected an error for an unknown runtime")
+	}
+}
+
 func TestLoadGlobalConfig_EnvOverridesNoFile(t *testing.T) {
 	t.Setenv("GATEKEEPER_GEMINI_KEY", "only-env-key")
 
@@ -239,6 +352,31 @@ func TestApplyEnvOverrides_CustomGetenv(t *testing.T) {
 	}
 }
 
+func TestApplyEnvOverrides_StandardGeminiKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		configKey SecretString
+		env       map[string]string
+		want      SecretString
+	}{
+		{"gemini api key", "", map[string]string{"GEMINI_API_KEY": "gemini"}, "gemini"},
+		{"google key preferred", "", map[string]string{"GEMINI_API_KEY": "gemini", "GOOGLE_API_KEY": "google"}, "google"},
+		{"gatekeeper key preferred", "", map[string]string{"GATEKEEPER_GEMINI_KEY": "gk", "GOOGLE_API_KEY": "google"}, "gk"},
+		{"config key preferred over standard", "file", map[string]string{"GEMINI_API_KEY": "gemini"}, "file"},
+		{"gatekeeper key overrides config", "file", map[string]string{"GATEKEEPER_GEMINI_KEY": "gk"}, "gk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultGlobalConfig()
+			cfg.GeminiAPIKey = tt.configKey
+			applyEnvOverrides(cfg, func(k string) string { return tt.env[k] }, slog.Default())
+			if cfg.GeminiAPIKey != tt.want {
+				t.Errorf("expected key %q, got %q", tt.want, cfg.GeminiAPIKey)
+			}
+		})
+	}
+}
+
 func TestLoadGlobalConfigConvenience(t *testing.T) {
 	// Convenience function uses RealFileSystem — just ensure it runs without panic.
 	cfg, err := LoadGlobalConfig(context.Background())
@@ -260,3 +398,224 @@ func TestLoadGlobalConfigFromConvenience(t *testing.T) {
 		t.Errorf("expected default TTL, got %v", cfg.ContainerTTL)
 	}
 }
+
+func TestLoadGlobalConfig_LLMHTTP(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	path := "/config.yaml"
+	mockFS.Files[path] = []byte(`
+llm_base_url: https://llm-gateway.internal
+llm_http:
+  headers:
+    X-Gateway-Token: ${GATEWAY_TOKEN}
+    X-Team: platform
+  proxy: http://proxy.internal:3128
+  ca_cert: /etc/ssl/gateway-ca.pem
+  client_cert: /etc/ssl/client.pem
+  client_key: /etc/ssl/client-key.pem
+`)
+	env := map[string]string{"GATEWAY_TOKEN": "s3cret"}
+
+	loader := NewLoaderWithEnv(mockFS, func(k string) string { return env[k] })
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.LLMBaseURL != "https://llm-gateway.internal" {
+		t.Errorf("unexpected LLMBaseURL %q", cfg.LLMBaseURL)
+	}
+	if got := cfg.LLMHTTP.Headers["X-Gateway-Token"]; got != "s3cret" {
+		t.Errorf("expected header expanded from env, got %q", string(got))
+	}
+	if got := cfg.LLMHTTP.Headers["X-Team"]; got != "platform" {
+		t.Errorf("unexpected X-Team header %q", string(got))
+	}
+	if cfg.LLMHTTP.Proxy != "http://proxy.internal:3128" || cfg.LLMHTTP.CACert != "/etc/ssl/gateway-ca.pem" ||
+		cfg.LLMHTTP.ClientCert != "/etc/ssl/client.pem" || cfg.LLMHTTP.ClientKey != "/etc/ssl/client-key.pem" {
+		t.Errorf("unexpected LLMHTTP: %+v", cfg.LLMHTTP)
+	}
+}
+
+func TestLoadGlobalConfig_LLMBaseURLEnv(t *testing.T) {
+	env := map[string]string{"GATEKEEPER_LLM_BASE_URL": "https://from-env.internal"}
+	loader := NewLoaderWithEnv(NewMockFileSystem(), func(k string) string { return env[k] })
+
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), "/missing.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLMBaseURL != "https://from-env.internal" {
+		t.Errorf("expected env-overridden LLMBaseURL, got %q", cfg.LLMBaseURL)
+	}
+}
+
+func TestLoadGlobalConfig_LLMHTTPInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"bad base url", "llm_base_url: not-a-url\n", "llm_base_url"},
+		{"bad proxy", "llm_http:\n  proxy: proxy.internal\n", "llm_http.proxy"},
+		{"cert without key", "llm_http:\n  client_cert: /c.pem\n", "client_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := NewMockFileSystem()
+			mockFS.Files["/config.yaml"] = []byte(tt.yaml)
+
+			_, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadGlobalConfig_LLMCacheTTL(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("llm_cache_ttl: 0s\n")
+
+	cfg, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLMCacheTTL != 0 {
+		t.Errorf("expected explicit 0s to disable the cache, got %v", cfg.LLMCacheTTL)
+	}
+
+	cfg, err = NewLoader(NewMockFileSystem()).LoadGlobalConfigFrom(context.Background(), "/missing.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LLMCacheTTL != defaultLLMCacheTTL {
+		t.Errorf("expected default TTL %v, got %v", defaultLLMCacheTTL, cfg.LLMCacheTTL)
+	}
+
+	mockFS.Files["/config.yaml"] = []byte("llm_cache_ttl: -1h\n")
+	if _, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err == nil || !strings.Contains(err.Error(), "llm_cache_ttl") {
+		t.Errorf("expected negative TTL error, got %v", err)
+	}
+}
+
+func TestLoadGlobalConfig_MaxTokensPerRun(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("max_tokens_per_run: 50000\n")
+
+	cfg, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxTokensPerRun != 50000 {
+		t.Errorf("expected 50000, got %d", cfg.MaxTokensPerRun)
+	}
+
+	mockFS.Files["/config.yaml"] = []byte("max_tokens_per_run: -1\n")
+	if _, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err == nil || !strings.Contains(err.Error(), "max_tokens_per_run") {
+		t.Errorf("expected negative budget error, got %v", err)
+	}
+}
+
+func TestLoadGlobalConfig_History(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("llm_cache_ttl: 1h\n")
+
+	cfg, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.History.MaxRuns != 100 || cfg.History.MaxAge != 30*24*time.Hour {
+		t.Errorf("expected default retention, got %+v", cfg.History)
+	}
+
+	mockFS.Files["/config.yaml"] = []byte("history:\n  max_runs: 0\n  max_age: 168h\n")
+	if cfg, err = NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.History.MaxRuns != 0 || cfg.History.MaxAge != 7*24*time.Hour {
+		t.Errorf("expected max_runs 0 and max_age 168h, got %+v", cfg.History)
+	}
+
+	for _, bad := range []string{"history:\n  max_runs: -1\n", "history:\n  max_age: -1h\n"} {
+		mockFS.Files["/config.yaml"] = []byte(bad)
+		if _, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err == nil || !strings.Contains(err.Error(), "history.max_") {
+			t.Errorf("%q: expected a negative retention error, got %v", bad, err)
+		}
+	}
+}
+
+func TestLoadGlobalConfig_LLMLimits(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	mockFS.Files["/config.yaml"] = []byte("llm_limits:\n  requests_per_minute: 60\n  concurrency: 2\n")
+
+	cfg, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LLMLimits.RequestsPerMinute != 60 || cfg.LLMLimits.Concurrency != 2 {
+		t.Errorf("unexpected limits: %+v", cfg.LLMLimits)
+	}
+
+	mockFS.Files["/config.yaml"] = []byte("llm_limits:\n  concurrency: -1\n")
+	if _, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml"); err == nil || !strings.Contains(err.Error(), "llm_limits.concurrency") {
+		t.Errorf("expected negative concurrency error, got %v", err)
+	}
+}
+
+func TestLoadGlobalConfig_APIKeyFrom(t *testing.T) {
+	mockFS := NewMockFileSystem()
+	path := "/config.yaml"
+	mockFS.Files[path] = []byte("gemini_api_key_from: keychain\n")
+
+	env := map[string]string{}
+	loader := NewLoaderWithEnv(mockFS, func(k string) string { return env[k] })
+	var calls []string
+	loader.secret = func(_ context.Context, source, name string) (string, error) {
+		calls = append(calls, source+" "+name)
+		return "from-keychain", nil
+	}
+
+	cfg, err := loader.LoadGlobalConfigFrom(context.Background(), path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GeminiAPIKey != "from-keychain" || len(calls) != 1 || calls[0] != "keychain gemini_api_key" {
+		t.Errorf("got key %q after calls %q", string(cfg.GeminiAPIKey), calls)
+	}
+
+	// The gatekeeper-specific variable wins without reading the keychain.
+	env["GATEKEEPER_GEMINI_KEY"] = "from-env"
+	calls = nil
+	if cfg, err = loader.LoadGlobalConfigFrom(context.Background(), path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GeminiAPIKey != "from-env" || len(calls) != 0 {
+		t.Errorf("got key %q after calls %q", string(cfg.GeminiAPIKey), calls)
+	}
+
+	// A key that cannot be read falls back to the standard variables.
+	env = map[string]string{"GEMINI_API_KEY": "standard"}
+	loader.secret = func(context.Context, string, string) (string, error) { return "", errors.New("locked") }
+	if cfg, err = loader.LoadGlobalConfigFrom(context.Background(), path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GeminiAPIKey != "standard" {
+		t.Errorf("expected the standard variable, got %q", string(cfg.GeminiAPIKey))
+	}
+}
+
+func TestLoadGlobalConfig_APIKeyFromInvalid(t *testing.T) {
+	tests := map[string]string{
+		"gemini_api_key: k\ngemini_api_key_from: keychain\n": "not both",
+		"gemini_api_key_from: vault\n":                       "gemini_api_key_from: must be",
+		"gemini_api_key_from: 'command: '\n":                 "needs a command",
+	}
+	for data, want := range tests {
+		mockFS := NewMockFileSystem()
+		mockFS.Files["/config.yaml"] = []byte(data)
+		_, err := NewLoader(mockFS).LoadGlobalConfigFrom(context.Background(), "/config.yaml")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error containing %q, got %v", data, want, err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// SetGlobalValue sets key, a dotted YAML path such as "output.color", to
+// value in a user config document and returns the updated document. An
+// empty document starts a new config.
+//
+// The value is parsed by the type of the setting, so "10m" must be a
+// duration and "yes" is not a boolean. As with AppendGate, the document is
+// edited as a YAML node tree so comments are preserved, and the result is
+// validated the same way LoadGlobalConfig validates.
+func SetGlobalValue(data []byte, key, value string) ([]byte, error) {
+	field, err := globalField(reflect.ValueOf(defaultGlobalConfig()).Elem(), key)
+	if err != nil {
+		return nil, err
+	}
+	valueNode, err := globalValueNode(key, field.Type(), value)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing global config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing global config: expected a mapping at the document root")
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		child := mappingValue(node, part)
+		if i == len(parts)-1 {
+			if child == nil {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, valueNode)
+			} else {
+				// Keep the comments of the replaced value.
+				valueNode.LineComment, valueNode.HeadComment, valueNode.FootComment = child.LineComment, child.HeadComment, child.FootComment
+				*child = *valueNode
+			}
+			break
+		}
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		}
+		if child.Kind == yaml.ScalarNode && child.Tag == "!!null" {
+			// "output:" with no entries decodes as a null scalar.
+			child.Kind, child.Tag, child.Value = yaml.MappingNode, "!!map", ""
+		}
+		if child.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("parsing global config: %q must be a mapping", strings.Join(parts[:i+1], "."))
+		}
+		node = child
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding global config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding global config: %w", err)
+	}
+
+	if err := ValidateGlobalConfig(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ValidateGlobalConfig parses a user config document and checks its values
+// without applying environment overrides.
+func ValidateGlobalConfig(data []byte) error {
+	cfg := defaultGlobalConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parsing global config: %w", err)
+	}
+	if err := errors.Join(checkAPIKeySources(cfg), validateGlobal(cfg)); err != nil {
+		return fmt.Errorf("validating global config: %w", err)
+	}
+	return nil
+}
+
+// GetGlobalValue returns the value of key, a dotted YAML path such as
+// "output.color", in cfg as YAML, or all of cfg if key is empty. Secrets are
+// redacted and unset optional values are empty.
+func GetGlobalValue(cfg *GlobalConfig, key string) (string, error) {
+	if key == "" {
+		out, err := yaml.Marshal(cfg)
+		if err != nil {
+			return "", fmt.Errorf("encoding global config: %w", err)
+		}
+		return strings.TrimSuffix(string(out), "\n"), nil
+	}
+	field, err := globalField(reflect.ValueOf(cfg).Elem(), key)
+	if err != nil {
+		return "", err
+	}
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return "", nil
+		}
+		field = field.Elem()
+	}
+	switch v := field.Interface().(type) {
+	case time.Duration:
+		return v.String(), nil
+	case SecretString:
+		if v.IsEmpty() {
+			return "", nil
+		}
+		return v.String(), nil
+	case string, bool, int:
+		return fmt.Sprint(v), nil
+	}
+	out, err := yaml.Marshal(field.Interface())
+	if err != nil {
+		return "", fmt.Errorf("encoding %s: %w", key, err)
+	}
+	if s := strings.TrimSuffix(string(out), "\n"); s != "{}" && s != "[]" {
+		return s, nil
+	}
+	return "", nil
+}
+
+// globalField returns the field of a GlobalConfig value at a dotted YAML path.
+func globalField(v reflect.Value, key string) (reflect.Value, error) {
+	for part := range strings.SplitSeq(key, ".") {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, fmt.Errorf("unknown setting %q", key)
+		}
+		found := false
+		for i := range v.NumField() {
+			name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if name != "" && name != "-" && name == part {
+				v, found = v.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return reflect.Value{}, fmt.Errorf("unknown setting %q", key)
+		}
+	}
+	return v, nil
+}
+
+// globalValueNode parses value as a setting of type t and returns its node.
+func globalValueNode(key string, t reflect.Type, value string) (*yaml.Node, error) {
+	scalar := func(tag, v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v}
+	}
+	switch {
+	case t == durationType:
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, fmt.Errorf("%s: invalid duration %q (e.g. 30s, 10m, 24h)", key, value)
+		}
+		return scalar("!!str", value), nil
+	case t.Kind() == reflect.String:
+		return scalar("!!str", value), nil
+	case t.Kind() == reflect.Bool, t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid boolean %q (use true or false)", key, value)
+		}
+		return scalar("!!bool", strconv.FormatBool(b)), nil
+	case t.Kind() == reflect.Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%s: invalid integer %q", key, value)
+		}
+		return scalar("!!int", value), nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				seq.Content = append(seq.Content, scalar("!!str", item))
+			}
+		}
+		return seq, nil
+	default:
+		return nil, fmt.Errorf("%s cannot be set from the command line; use 'gatekeeper config edit'", key)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetGlobalValue(t *testing.T) {
+	data := []byte("# my settings\ncontainer_ttl: 5m # keep warm\noutput:\n  verbose: true\n")
+
+	updated, err := SetGlobalValue(data, "container_ttl", "10m")
+	if err != nil {
+		t.Fatalf("SetGlobalValue: %v", err)
+	}
+	if updated, err = SetGlobalValue(updated, "output.color", "false"); err != nil {
+		t.Fatalf("SetGlobalValue: %v", err)
+	}
+	if updated, err = SetGlobalValue(updated, "gemini_api_key", "secret-key"); err != nil {
+		t.Fatalf("SetGlobalValue: %v", err)
+	}
+	if updated, err = SetGlobalValue(updated, "local.path", "/usr/bin, /bin"); err != nil {
+		t.Fatalf("SetGlobalValue: %v", err)
+	}
+
+	for _, want := range []string{"# my settings", "container_ttl: 10m # keep warm", "  verbose: true", "  color: false", "gemini_api_key: secret-key", "    - /usr/bin\n    - /bin"} {
+		if !strings.Contains(string(updated), want) {
+			t.Errorf("missing %q in:\n%s", want, updated)
+		}
+	}
+}
+
+func TestSetGlobalValue_EmptyDocument(t *testing.T) {
+	updated, err := SetGlobalValue(nil, "attestation.keyless", "true")
+	if err != nil {
+		t.Fatalf("SetGlobalValue: %v", err)
+	}
+	if got := string(updated); got != "attestation:\n  keyless: true\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSetGlobalValue_Invalid(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"container_ttl", "ten minutes", "invalid duration"},
+		{"output.color", "yes", "invalid boolean"},
+		{"max_tokens_per_run", "lots", "invalid integer"},
+		{"max_tokens_per_run", "-1", "must not be negative"},
+		{"runtime", "podman", "runtime: must be"},
+		{"llm_base_url", "not a url", "invalid URL"},
+		{"gemini_key", "x", `unknown setting "gemini_key"`},
+		{"output.color.dark", "x", "unknown setting"},
+		{"llm_http.headers", "x", "use 'gatekeeper config edit'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			_, err := SetGlobalValue(nil, tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetGlobalValue(t *testing.T) {
+	cfg := defaultGlobalConfig()
+	cfg.GeminiAPIKey = "secret-key"
+	cfg.ContainerTTL = 10 * time.Minute
+	cfg.Local.Path = []string{"/bin"}
+
+	tests := map[string]string{
+		"gemini_api_key": "[REDACTED]",
+		"container_ttl":  "10m0s",
+		"output.color":   "",
+		"runtime":        "",
+		"local.path":     "- /bin",
+	}
+	for key, want := range tests {
+		got, err := GetGlobalValue(cfg, key)
+		if err != nil || got != want {
+			t.Errorf("GetGlobalValue(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	all, err := GetGlobalValue(cfg, "")
+	if err != nil {
+		t.Fatalf("GetGlobalValue: %v", err)
+	}
+	if strings.Contains(all, "secret-key") || !strings.Contains(all, "container_ttl: 10m0s") {
+		t.Errorf("unexpected config:\n%s", all)
+	}
+	if _, err := GetGlobalValue(cfg, "nope"); err == nil {
+		t.Error("expected an error for an unknown setting")
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LintWarning is advisory feedback about a valid configuration that is likely to be slow.
+type LintWarning struct {
+	// Gate is the gate the warning applies to, or "" for configuration-wide warnings.
+	Gate    string
+	Message string
+}
+
+// String renders the warning as "gate: message".
+func (w LintWarning) String() string {
+	if w.Gate == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s: %s", w.Gate, w.Message)
+}
+
+// LintOptions describes the repository a configuration is linted for.
+type LintOptions struct {
+	// TrackedFiles is the number of files tracked by git (0 if unknown).
+	TrackedFiles int
+}
+
+// largeRepoFiles is the tracked file count above which unfiltered gates are flagged.
+const largeRepoFiles = 5000
+
+// defaultGateTimeout mirrors the timeout applied to container gates without one.
+const defaultGateTimeout = "30s"
+
+// testCacheHints maps test runners to an example of keeping their cache in the
+// project mount, which survives container recycling.
+var testCacheHints = []struct {
+	marker, hint string
+}{
+	{"go test", "GOCACHE=/workspace/.cache/go-build GOMODCACHE=/workspace/.cache/go-mod go test ..."},
+	{"pytest", "pytest -o cache_dir=/workspace/.cache/pytest with PIP_CACHE_DIR=/workspace/.cache/pip"},
+	{"npm test", "npm_config_cache=/workspace/.cache/npm npm test"},
+	{"npx jest", "npx jest --cacheDirectory /workspace/.cache/jest"},
+	{"npx vitest", "npx vitest --cache.dir /workspace/.cache/vitest"},
+	{"cargo test", "CARGO_HOME=/workspace/.cache/cargo cargo test"},
+	{"mvn test", "mvn -Dmaven.repo.local=/workspace/.cache/m2 test"},
+	{"gradle test", "GRADLE_USER_HOME=/workspace/.cache/gradle gradle test"},
+}
+
+// Lint returns performance warnings for cfg. It assumes cfg passed validation.
+func Lint(cfg *GatekeeperConfig, opts LintOptions) []LintWarning {
+	var warnings []LintWarning
+	for _, g := range cfg.Gates {
+		switch g.Type {
+		case GateTypeExec, GateTypeScript, GateTypeCoverage:
+			if opts.TrackedFiles >= largeRepoFiles && len(g.Only) == 0 {
+				warnings = append(warnings, LintWarning{g.Name, fmt.Sprintf(
+					"runs on every commit in a large repository (%d tracked files); add `only` globs so it runs when relevant files change", opts.TrackedFiles)})
+			}
+			if g.Timeout == 0 {
+				warnings = append(warnings, LintWarning{g.Name,
+					"no `timeout`; the " + defaultGateTimeout + " default may cut off slow runs or keep a commit waiting on a hung tool"})
+			}
+			if hint, ok := missingTestCache(g.Command); ok {
+				warnings = append(warnings, LintWarning{g.Name,
+					"test cache lives inside the container and is lost when it is recycled; keep it in the project mount, e.g. " + hint})
+			}
+		case GateTypeLLM:
+			if g.MaxFileSize == "" {
+				warnings = append(warnings, LintWarning{g.Name,
+					"no `max_file_size`; large generated or vendored files are sent to the LLM in full"})
+			}
+		}
+	}
+	return append(warnings, lintContainers(cfg.Gates)...)
+}
+
+// missingTestCache reports whether command runs a known test runner without
+// mentioning a cache location, with an example of configuring one.
+func missingTestCache(command string) (hint string, ok bool) {
+	lower := strings.ToLower(command)
+	if strings.Contains(lower, "cache") {
+		return "", false
+	}
+	for _, t := range testCacheHints {
+		if strings.Contains(lower, t.marker) {
+			return t.hint, true
+		}
+	}
+	return "", false
+}
+
+// lintContainers flags gates that start separate warm containers but could share
+// one: the pool keys containers by image and writability.
+func lintContainers(gates []Gate) []LintWarning {
+	type usage struct {
+		tags     map[string][]string // tag -> gate names
+		writable map[bool][]string   // writability -> gate names
+	}
+	byRepo := make(map[string]*usage)
+	for _, g := range gates {
+		if g.Container == "" || g.IsLocal() || g.Container == ContainerDevcontainer || !g.RunsCommand() {
+			continue
+		}
+		repo, tag := splitImage(g.Container)
+		u, ok := byRepo[repo]
+		if !ok {
+			u = &usage{tags: make(map[string][]string), writable: make(map[bool][]string)}
+			byRepo[repo] = u
+		}
+		u.tags[tag] = append(u.tags[tag], g.Name)
+		u.writable[g.Writable] = append(u.writable[g.Writable], g.Name)
+	}
+
+	repos := make([]string, 0, len(byRepo))
+	for repo := range byRepo {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var warnings []LintWarning
+	for _, repo := range repos {
+		u := byRepo[repo]
+		if len(u.tags) > 1 {
+			tags := make([]string, 0, len(u.tags))
+			for tag := range u.tags {
+				tags = append(tags, repo+":"+tag)
+			}
+			sort.Strings(tags)
+			warnings = append(warnings, LintWarning{"", fmt.Sprintf(
+				"%s start separate containers; use one tag so gates share a warm container", strings.Join(tags, ", "))})
+		} else if len(u.writable[true]) > 0 && len(u.writable[false]) > 0 {
+			warnings = append(warnings, LintWarning{"", fmt.Sprintf(
+				"%s runs read-only (%s) and writable (%s) gates in separate containers; drop `writable` where it is not needed",
+				repo, strings.Join(u.writable[false], ", "), strings.Join(u.writable[true], ", "))})
+		}
+	}
+	return warnings
+}
+
+// splitImage splits an image reference into repository and tag ("latest" if omitted).
+// Digest references keep their digest as the tag.
+func splitImage(image string) (repo, tag string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], image[i+1:]
+	}
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port.
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
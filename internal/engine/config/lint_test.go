@@ -0,0 +1,97 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// lintMessages returns the rendered warnings for cfg.
+func lintMessages(cfg *GatekeeperConfig, opts LintOptions) []string {
+	var out []string
+	for _, w := range Lint(cfg, opts) {
+		out = append(out, w.String())
+	}
+	return out
+}
+
+// hasWarning reports whether any warning starts with prefix and contains sub.
+func hasWarning(warnings []string, prefix, sub string) bool {
+	for _, w := range warnings {
+		if strings.HasPrefix(w, prefix) && strings.Contains(w, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLint_CleanConfig(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{
+		{Name: "lint", Type: GateTypeExec, Command: "golangci-lint run", Container: "golangci/golangci-lint:v1.61", Timeout: time.Minute, Only: []string{"**/*.go"}},
+		{Name: "test", Type: GateTypeExec, Command: "GOCACHE=/workspace/.cache/go go test ./...", Container: "golang:1.23", Timeout: 2 * time.Minute, Only: []string{"**/*.go"}},
+		{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review", MaxFileSize: "100KB"},
+	}}
+
+	if warnings := lintMessages(cfg, LintOptions{TrackedFiles: 20000}); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestLint_GateWarnings(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{
+		{Name: "test", Type: GateTypeExec, Command: "go test ./...", Container: "golang:1.23"},
+		{Name: "review", Type: GateTypeLLM, Provider: "gemini", Prompt: "Review"},
+	}}
+
+	warnings := lintMessages(cfg, LintOptions{TrackedFiles: 12000})
+	for _, want := range []struct{ prefix, sub string }{
+		{"test: ", "12000 tracked files"},
+		{"test: ", "no `timeout`"},
+		{"test: ", "GOCACHE=/workspace/.cache/go-build"},
+		{"review: ", "no `max_file_size`"},
+	} {
+		if !hasWarning(warnings, want.prefix, want.sub) {
+			t.Errorf("expected warning %q...%q, got %v", want.prefix, want.sub, warnings)
+		}
+	}
+	if hasWarning(warnings, "review: ", "timeout") {
+		t.Error("expected no timeout warning for LLM gates")
+	}
+
+	if hasWarning(lintMessages(cfg, LintOptions{TrackedFiles: 100}), "test: ", "tracked files") {
+		t.Error("expected no `only` warning for a small repository")
+	}
+}
+
+func TestLint_SharedContainers(t *testing.T) {
+	cfg := &GatekeeperConfig{Gates: []Gate{
+		{Name: "vet", Type: GateTypeExec, Command: "go vet ./...", Container: "golang:1.23", Timeout: time.Minute},
+		{Name: "build", Type: GateTypeExec, Command: "go build ./...", Container: "golang:1.22", Timeout: time.Minute},
+		{Name: "fmt", Type: GateTypeExec, Command: "prettier --check .", Container: "registry:5000/node", Timeout: time.Minute},
+		{Name: "fix", Type: GateTypeExec, Command: "prettier --write .", Container: "registry:5000/node:latest", Timeout: time.Minute, Writable: true},
+	}}
+
+	warnings := lintMessages(cfg, LintOptions{})
+	if !hasWarning(warnings, "golang:1.22, golang:1.23", "use one tag") {
+		t.Errorf("expected tag warning, got %v", warnings)
+	}
+	if !hasWarning(warnings, "registry:5000/node runs read-only (fmt) and writable (fix)", "separate containers") {
+		t.Errorf("expected writable warning, got %v", warnings)
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct{ image, repo, tag string }{
+		{"golang", "golang", "latest"},
+		{"golang:1.23", "golang", "1.23"},
+		{"localhost:5000/tools/lint", "localhost:5000/tools/lint", "latest"},
+		{"localhost:5000/tools/lint:v2", "localhost:5000/tools/lint", "v2"},
+		{"alpine@sha256:abc", "alpine", "sha256:abc"},
+	}
+	for _, tt := range tests {
+		repo, tag := splitImage(tt.image)
+		if repo != tt.repo || tag != tt.tag {
+			t.Errorf("splitImage(%q) = %q, %q; want %q, %q", tt.image, repo, tag, tt.repo, tt.tag)
+		}
+	}
+}
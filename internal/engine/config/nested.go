@@ -0,0 +1,60 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// NestedDirs returns the directories, relative to root and slash-separated, that
+// hold a .gatekeeper/gates.yaml and contain at least one of files. They are found
+// by walking up from each file; the root config itself is not included.
+func (l *Loader) NestedDirs(root string, files []string) []string {
+	checked := make(map[string]bool)
+	var dirs []string
+	for _, f := range files {
+		for dir := path.Dir(filepath.ToSlash(f)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if _, seen := checked[dir]; seen {
+				break // Its parents were checked with it.
+			}
+			_, err := l.fs.Stat(nestedConfigPath(root, dir))
+			checked[dir] = err == nil
+			if err == nil {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// LoadNested loads the nested configs of the directories containing files (see
+// NestedDirs), applying the overrides for branch and each file's own defaults.
+// Gates are scoped to their directory and named "<dir>:<name>" so they stay
+// distinct from root gates of the same name.
+func (l *Loader) LoadNested(ctx context.Context, root string, files []string, branch string) ([]Gate, error) {
+	var gates []Gate
+	for _, dir := range l.NestedDirs(root, files) {
+		cfg, err := l.LoadForBranch(ctx, nestedConfigPath(root, dir), branch)
+		if err != nil {
+			return nil, fmt.Errorf("loading %s/.gatekeeper/gates.yaml: %w", dir, err)
+		}
+		for _, g := range cfg.Gates {
+			g.Dir = dir
+			g.Name = dir + ":" + g.Name
+			gates = append(gates, g)
+		}
+	}
+	return gates, nil
+}
+
+// LoadNested loads nested configs from the real file system.
+func LoadNested(ctx context.Context, root string, files []string, branch string) ([]Gate, error) {
+	return NewLoader(&RealFileSystem{}).LoadNested(ctx, root, files, branch)
+}
+
+func nestedConfigPath(root, dir string) string {
+	return filepath.Join(root, filepath.FromSlash(dir), ".gatekeeper", "gates.yaml")
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func nestedFS() *MockFileSystem {
+	fs := NewMockFileSystem()
+	fs.Files[filepath.Join("/repo", "services", "api", ".gatekeeper", "gates.yaml")] = []byte(`
+version: 1
+defaults:
+  container: golang:1.25
+gates:
+  - name: lint
+    type: exec
+    command: go vet ./...
+    only: ["*.go"]
+`)
+	fs.Files[filepath.Join("/repo", "services", "web", ".gatekeeper", "gates.yaml")] = []byte(`
+version: 1
+gates:
+  - name: lint
+    type: exec
+    container: node:22
+    command: npm run lint
+`)
+	return fs
+}
+
+func TestNestedDirs(t *testing.T) {
+	loader := NewLoader(nestedFS())
+	files := []string{"README.md", "services/api/cmd/main.go", "services/api/go.mod", "services/web/src/app.ts", "services/worker/main.go"}
+
+	got := loader.NestedDirs("/repo", files)
+	want := []string{"services/api", "services/web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLoadNested(t *testing.T) {
+	loader := NewLoader(nestedFS())
+
+	gates, err := loader.LoadNested(context.Background(), "/repo", []string{"services/api/main.go", "services/web/app.ts"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gates) != 2 {
+		t.Fatalf("expected 2 gates, got %+v", gates)
+	}
+	api, web := gates[0], gates[1]
+	if api.Name != "services/api:lint" || api.Dir != "services/api" || api.Container != "golang:1.25" {
+		t.Errorf("unexpected api gate: %+v", api)
+	}
+	if web.Name != "services/web:lint" || web.Dir != "services/web" {
+		t.Errorf("unexpected web gate: %+v", web)
+	}
+}
+
+func TestLoadNested_InvalidConfig(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.Files[filepath.Join("/repo", "svc", ".gatekeeper", "gates.yaml")] = []byte("gates:\n  - name: broken\n    type: exec\n")
+
+	_, err := NewLoader(fs).LoadNested(context.Background(), "/repo", []string{"svc/main.go"}, "")
+	if err == nil || !strings.Contains(err.Error(), "svc/.gatekeeper/gates.yaml") || !strings.Contains(err.Error(), "command") {
+		t.Errorf("expected validation error naming the nested config, got %v", err)
+	}
+}
+
+func TestGate_ScopeFiles(t *testing.T) {
+	files := []string{"main.go", "services/api/main.go", "services/api/internal/db.go", "services/apigw/main.go"}
+
+	root := Gate{}
+	if got := root.ScopeFiles(files); !reflect.DeepEqual(got, files) {
+		t.Errorf("expected root gate to see every file, got %v", got)
+	}
+
+	nested := Gate{Dir: "services/api"}
+	want := []string{"main.go", "internal/db.go"}
+	if got := nested.ScopeFiles(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// SchemaURL is where the JSON Schema of gates.yaml is published. Generated
+// configs point editors at it with a yaml-language-server modeline.
+const SchemaURL = "https://raw.githubusercontent.com/irahardianto/gatekeeper/main/schema/gates.schema.json"
+
+// schemaModeline is the first line of generated configs. The YAML language
+// server, used by the VS Code, JetBrains, and Neovim YAML plugins, reads it to
+// validate and complete the file.
+const schemaModeline = "# yaml-language-server: $schema=" + SchemaURL + "\n"
+
+// durationPattern matches the Go durations accepted by timeout fields, such as "90s" or "1m30s".
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// schemaRequired lists the fields a config must set, by struct.
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeFor[GatekeeperConfig](): {"version", "gates"},
+	reflect.TypeFor[BranchOverride]():   {"branch"},
+	reflect.TypeFor[Gate]():             {"name", "type"},
+	reflect.TypeFor[CoverageConfig]():   {"report"},
+	reflect.TypeFor[FilePair]():         {"changed", "requires"},
+	reflect.TypeFor[ParserConfig]():     {"pattern"},
+}
+
+// schemaEnums lists the valid values of string fields, by struct and field.
+var schemaEnums = map[reflect.Type]map[string][]string{
+	reflect.TypeFor[Defaults](): {
+		"isolation":  {IsolationStash, IsolationSnapshot},
+		"submodules": {SubmodulesSkip, SubmodulesRecurse},
+	},
+	reflect.TypeFor[Gate](): {
+		"reuse":           {ReuseWarm, ReuseEphemeral},
+		"stdin":           {StdinNone, StdinDiff, StdinStagedFiles},
+		"writable_policy": {WritablePolicyRevert, WritablePolicyApply},
+		"mode":            {LLMModeDiff, LLMModeFullContext, LLMModeDocDrift},
+		"low_confidence":  {LowConfidenceWarn, LowConfidenceDrop},
+	},
+	reflect.TypeFor[CoverageConfig](): {
+		"format": coverageFormats,
+	},
+	reflect.TypeFor[ParserConfig](): {
+		"fail_on": parser.TrivySeverities,
+	},
+}
+
+// schemaTypeEnums lists the valid values of named string types.
+var schemaTypeEnums = map[reflect.Type][]string{
+	reflect.TypeFor[GateType]():      {string(GateTypeExec), string(GateTypeScript), string(GateTypeLLM), string(GateTypeCoverage), string(GateTypePolicy)},
+	reflect.TypeFor[OnErrorPolicy](): {string(OnErrorBlock), string(OnErrorWarn)},
+}
+
+// JSONSchema returns the JSON Schema of gates.yaml, generated from
+// GatekeeperConfig and its yaml tags. Unknown fields are rejected, so editors
+// flag misspelled options that the loader would silently ignore.
+func JSONSchema() ([]byte, error) {
+	defs := make(map[string]any)
+	root := structSchema(reflect.TypeFor[GatekeeperConfig](), defs)
+	root["$schema"] = "http://json-schema.org/draft-07/schema#"
+	root["$id"] = SchemaURL
+	root["title"] = "Gatekeeper gates.yaml"
+	root["definitions"] = defs
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// structSchema returns the object schema of struct type t, adding the structs
+// it refers to to defs.
+func structSchema(t reflect.Type, defs map[string]any) map[string]any {
+	props := make(map[string]any)
+	enums := schemaEnums[t]
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		s := typeSchema(f.Type, defs)
+		if values, ok := enums[name]; ok {
+			s["enum"] = values
+		}
+		props[name] = s
+	}
+	s := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if required := schemaRequired[t]; len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// typeSchema returns the schema of a field of type t.
+func typeSchema(t reflect.Type, defs map[string]any) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if values, ok := schemaTypeEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	if t == reflect.TypeFor[time.Duration]() {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			defs[t.Name()] = nil // placeholder for recursive types
+			defs[t.Name()] = structSchema(t, defs)
+		}
+		return map[string]any{"$ref": "#/definitions/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "rewrite schema/gates.schema.json")
+
+// schemaPath is the published schema, served at SchemaURL.
+var schemaPath = filepath.Join("..", "..", "..", "schema", "gates.schema.json")
+
+func TestJSONSchema_Golden(t *testing.T) {
+	got, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error: %v", err)
+	}
+	if *updateSchema {
+		if err := os.WriteFile(schemaPath, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date with the config structs; run: go test ./internal/engine/config -run TestJSONSchema_Golden -update", schemaPath)
+	}
+}
+
+func TestJSONSchema_CoversConfig(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema() error: %v", err)
+	}
+	var schema struct {
+		Required    []string `json:"required"`
+		Definitions map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+			Required   []string                  `json:"required"`
+		} `json:"definitions"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	gate := schema.Definitions["Gate"]
+	if _, ok := gate.Properties["Dir"]; ok {
+		t.Error("fields without a yaml name must not be in the schema")
+	}
+	if got := gate.Properties["type"]["enum"]; len(got.([]any)) != 5 {
+		t.Errorf("gate type enum = %v", got)
+	}
+	if got := gate.Properties["timeout"]["pattern"]; got != durationPattern {
+		t.Errorf("timeout pattern = %v", got)
+	}
+	if got := gate.Properties["policy"]["$ref"]; got != "#/definitions/PolicyConfig" {
+		t.Errorf("policy ref = %v", got)
+	}
+	if _, ok := schema.Definitions["FilePair"].Properties["requires"]; !ok {
+		t.Error("nested structs must be defined")
+	}
+	if len(schema.Required) != 2 || len(gate.Required) != 2 {
+		t.Errorf("required = %v, gate required = %v", schema.Required, gate.Required)
+	}
+}
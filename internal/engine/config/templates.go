@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
+	"gopkg.in/yaml.v3"
+)
+
+// GateTemplate is a ready-to-use gate definition from the built-in catalog.
+type GateTemplate struct {
+	// Name is the catalog key used on the command line (e.g., "gosec").
+	Name string
+	// Description is a one-line summary shown in listings.
+	Description string
+	// YAML is a single gate mapping, formatted as it should appear in gates.yaml.
+	YAML string
+}
+
+// Gate decodes the template into a Gate.
+func (t GateTemplate) Gate() (Gate, error) {
+	var g Gate
+	if err := yaml.Unmarshal([]byte(t.YAML), &g); err != nil {
+		return Gate{}, fmt.Errorf("parsing template %q: %w", t.Name, err)
+	}
+	return g, nil
+}
+
+// LookupTemplate returns the catalog template with the given name. Templates are
+// catalog/<name>.yaml assets: a "# description" line followed by the gate mapping.
+// Users can override them or add their own.
+func LookupTemplate(name string) (GateTemplate, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return GateTemplate{}, false
+	}
+	data, err := assets.Read("catalog/" + name + ".yaml")
+	if err != nil {
+		return GateTemplate{}, false
+	}
+	return parseTemplate(name, string(data)), true
+}
+
+// parseTemplate splits a catalog asset into its description comment and gate YAML.
+func parseTemplate(name, data string) GateTemplate {
+	t := GateTemplate{Name: name, YAML: data}
+	if first, rest, ok := strings.Cut(data, "\n"); ok && strings.HasPrefix(first, "#") {
+		t.Description = strings.TrimSpace(strings.TrimPrefix(first, "#"))
+		t.YAML = rest
+	}
+	return t
+}
+
+// Templates returns all catalog templates sorted by name. Unreadable templates are skipped.
+func Templates() []GateTemplate {
+	names, err := assets.List("catalog", ".yaml")
+	if err != nil {
+		return nil
+	}
+	result := make([]GateTemplate, 0, len(names))
+	for _, name := range names {
+		if t, ok := LookupTemplate(name); ok {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,258 @@
+// Package coverage reads test coverage reports and compares the coverage of
+// changed files with a stored baseline.
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Report formats.
+const (
+	// FormatGo is a Go coverage profile, as written by go test -coverprofile.
+	FormatGo = "go"
+	// FormatCobertura is Cobertura XML, as written by coverage.py, Jest, and JaCoCo converters.
+	FormatCobertura = "cobertura"
+	// FormatLcov is an lcov tracefile, as written by c8, nyc, and cargo-llvm-cov.
+	FormatLcov = "lcov"
+)
+
+// Formats lists the supported report formats.
+var Formats = []string{FormatGo, FormatCobertura, FormatLcov}
+
+// Profile is the line coverage of a report: for each file as named in the
+// report, whether each measured line was run by a test.
+type Profile map[string]map[int]bool
+
+// Summary counts the measured and covered lines of a file.
+type Summary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered share of lines, from 0 to 100. A file without
+// measured lines is fully covered.
+func (s Summary) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return 100 * float64(s.Covered) / float64(s.Total)
+}
+
+// Add returns the combined counts of s and o.
+func (s Summary) Add(o Summary) Summary {
+	return Summary{Covered: s.Covered + o.Covered, Total: s.Total + o.Total}
+}
+
+// Summarize counts the measured and covered lines of lines.
+func Summarize(lines map[int]bool) Summary {
+	var s Summary
+	for _, covered := range lines {
+		s.Total++
+		if covered {
+			s.Covered++
+		}
+	}
+	return s
+}
+
+// Lookup returns the coverage of a file by its path relative to the project
+// root. Reports name files differently — Go by import path, lcov often by
+// absolute path — so a report file matches if its path ends with file. Of
+// several matches, the shortest wins: reports share one prefix, so the others
+// are files of the same name in deeper directories.
+func (p Profile) Lookup(file string) (map[int]bool, bool) {
+	if lines, ok := p[file]; ok {
+		return lines, true
+	}
+	best := ""
+	for name := range p {
+		slashed := strings.ReplaceAll(name, `\`, "/")
+		if !strings.HasSuffix(path.Clean(slashed), "/"+file) {
+			continue
+		}
+		if best == "" || len(name) < len(best) || (len(name) == len(best) && name < best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return p[best], true
+}
+
+// Parse reads a coverage report in format, or detects the format if it is "".
+func Parse(format string, data []byte) (Profile, error) {
+	if format == "" {
+		format = Detect(data)
+	}
+	switch format {
+	case FormatGo:
+		return parseGo(data)
+	case FormatCobertura:
+		return parseCobertura(data)
+	case FormatLcov:
+		return parseLcov(data)
+	case "":
+		return nil, fmt.Errorf("unrecognized coverage report format (valid: %s)", strings.Join(Formats, ", "))
+	default:
+		return nil, fmt.Errorf("unknown coverage format %q (valid: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Detect returns the format of a coverage report, or "" if it is not recognized.
+func Detect(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return FormatGo
+	case bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(trimmed, []byte("<coverage")):
+		return FormatCobertura
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")):
+		return FormatLcov
+	default:
+		return ""
+	}
+}
+
+// parseGo reads a Go coverage profile. A line is covered if any block that
+// spans it ran.
+func parseGo(data []byte) (Profile, error) {
+	p := Profile{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// file.go:startLine.startCol,endLine.endCol statements count
+		file, rest, ok := strings.Cut(line, ":")
+		fields := strings.Fields(rest)
+		if !ok || len(fields) != 3 {
+			return nil, fmt.Errorf("go coverage profile line %d: malformed block %q", n, line)
+		}
+		span, count := fields[0], fields[2]
+		from, to, ok := strings.Cut(span, ",")
+		start, errStart := strconv.Atoi(strings.Split(from, ".")[0])
+		end, errEnd := strconv.Atoi(strings.Split(to, ".")[0])
+		hits, errHits := strconv.Atoi(count)
+		if !ok || errStart != nil || errEnd != nil || errHits != nil {
+			return nil, fmt.Errorf("go coverage profile line %d: malformed block %q", n, line)
+		}
+		lines := p.file(file)
+		for l := start; l <= end; l++ {
+			lines[l] = lines[l] || hits > 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading go coverage profile: %w", err)
+	}
+	return p, nil
+}
+
+// parseCobertura reads Cobertura XML. Class file names are relative to one of
+// the report's sources; they are kept relative, which Lookup matches by suffix.
+func parseCobertura(data []byte) (Profile, error) {
+	var report struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int `xml:"number,attr"`
+				Hits   int `xml:"hits,attr"`
+			} `xml:"lines>line"`
+		} `xml:"packages>package>classes>class"`
+	}
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing cobertura report: %w", err)
+	}
+	p := Profile{}
+	for _, c := range report.Classes {
+		lines := p.file(c.Filename)
+		for _, l := range c.Lines {
+			lines[l.Number] = lines[l.Number] || l.Hits > 0
+		}
+	}
+	return p, nil
+}
+
+// parseLcov reads an lcov tracefile's line records (SF and DA).
+func parseLcov(data []byte) (Profile, error) {
+	p := Profile{}
+	var lines map[int]bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			lines = p.file(strings.TrimPrefix(line, "SF:"))
+		case strings.HasPrefix(line, "DA:"):
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if lines == nil || len(fields) < 2 {
+				return nil, fmt.Errorf("lcov line %d: malformed record %q", n, line)
+			}
+			number, errNumber := strconv.Atoi(fields[0])
+			hits, errHits := strconv.ParseFloat(fields[1], 64)
+			if errNumber != nil || errHits != nil {
+				return nil, fmt.Errorf("lcov line %d: malformed record %q", n, line)
+			}
+			lines[number] = lines[number] || hits > 0
+		case line == "end_of_record":
+			lines = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading lcov report: %w", err)
+	}
+	return p, nil
+}
+
+// file returns the lines of name, adding it if needed.
+func (p Profile) file(name string) map[int]bool {
+	lines, ok := p[name]
+	if !ok {
+		lines = map[int]bool{}
+		p[name] = lines
+	}
+	return lines
+}
+
+// Uncovered returns the runs of consecutive added lines that were measured but
+// not run by any test, as [first, last] line pairs. Added lines that were not
+// measured, such as comments and blank lines, neither start nor break a run.
+func Uncovered(lines map[int]bool, added []int) [][2]int {
+	sorted := append([]int(nil), added...)
+	sort.Ints(sorted)
+
+	var ranges [][2]int
+	open, prev := false, 0
+	for _, l := range sorted {
+		if l != prev+1 {
+			open = false
+		}
+		prev = l
+		covered, measured := lines[l]
+		switch {
+		case !measured:
+		case covered:
+			open = false
+		case open:
+			ranges[len(ranges)-1][1] = l
+		default:
+			ranges = append(ranges, [2]int{l, l})
+			open = true
+		}
+	}
+	return ranges
+}
+
+// Baseline is the coverage of files at the last passing run of a gate, keyed
+// by path relative to the project root.
+type Baseline map[string]Summary
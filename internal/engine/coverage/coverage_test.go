@@ -0,0 +1,105 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_Go(t *testing.T) {
+	data := "mode: atomic\n" +
+		"example.com/m/api/api.go:3.20,5.2 2 4\n" +
+		"example.com/m/api/api.go:5.2,7.3 1 0\n" +
+		"example.com/m/main.go:1.1,1.20 1 0\n"
+	p, err := Parse("", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := map[int]bool{3: true, 4: true, 5: true, 6: false, 7: false}
+	if got := p["example.com/m/api/api.go"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("api.go lines = %v, want %v", got, want)
+	}
+
+	if _, err := Parse(FormatGo, []byte("mode: set\nbad line\n")); err == nil {
+		t.Error("expected an error for a malformed block")
+	}
+}
+
+func TestParse_Cobertura(t *testing.T) {
+	data := `<?xml version="1.0" ?>
+<coverage line-rate="0.5">
+  <sources><source>/workspace/src</source></sources>
+  <packages><package name="app"><classes>
+    <class name="util.py" filename="app/util.py">
+      <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+    </class>
+  </classes></package></packages>
+</coverage>`
+	p, err := Parse("", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := p["app/util.py"]; !reflect.DeepEqual(got, map[int]bool{1: true, 2: false}) {
+		t.Errorf("util.py lines = %v", got)
+	}
+}
+
+func TestParse_Lcov(t *testing.T) {
+	data := "TN:\nSF:/workspace/web/src/app.ts\nDA:1,3\nDA:2,0\nLF:2\nLH:1\nend_of_record\n"
+	p, err := Parse("", []byte(data))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	lines, ok := p.Lookup("web/src/app.ts")
+	if !ok || !reflect.DeepEqual(lines, map[int]bool{1: true, 2: false}) {
+		t.Errorf("Lookup(web/src/app.ts) = %v, %v", lines, ok)
+	}
+}
+
+func TestParse_Unknown(t *testing.T) {
+	if _, err := Parse("", []byte("PASS\n")); err == nil {
+		t.Error("expected an error for an unrecognized report")
+	}
+	if _, err := Parse("jacoco", []byte("<report/>")); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestProfile_LookupPrefersShortestMatch(t *testing.T) {
+	p := Profile{
+		"example.com/m/cmd/tool/main.go": {1: false},
+		"example.com/m/main.go":          {1: true},
+	}
+	lines, ok := p.Lookup("main.go")
+	if !ok || !lines[1] {
+		t.Errorf("expected the root main.go, got %v, %v", lines, ok)
+	}
+	if _, ok := p.Lookup("other.go"); ok {
+		t.Error("expected no match for an unmeasured file")
+	}
+	if _, ok := p.Lookup("in.go"); ok {
+		t.Error("expected a match on whole path components only")
+	}
+}
+
+func TestUncovered(t *testing.T) {
+	lines := map[int]bool{10: false, 12: false, 13: true, 14: false, 30: false, 31: false}
+	// 11 is an added comment, 20 an added line far away, 31 is not added.
+	got := Uncovered(lines, []int{14, 10, 11, 12, 13, 20, 30})
+	want := [][2]int{{10, 12}, {14, 14}, {30, 30}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Uncovered = %v, want %v", got, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	s := Summarize(map[int]bool{1: true, 2: false, 3: true, 4: true})
+	if s != (Summary{Covered: 3, Total: 4}) || s.Percent() != 75 {
+		t.Errorf("unexpected summary %+v (%.1f%%)", s, s.Percent())
+	}
+	if (Summary{}).Percent() != 100 {
+		t.Error("expected a file without measured lines to count as covered")
+	}
+	if got := s.Add(Summary{Covered: 1, Total: 4}); got != (Summary{Covered: 4, Total: 8}) {
+		t.Errorf("Add = %+v", got)
+	}
+}
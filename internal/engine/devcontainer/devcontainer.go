@@ -0,0 +1,287 @@
+// Package devcontainer runs gates in a project's dev container, as described
+// by its devcontainer.json, so gates use the exact toolchain developers do.
+package devcontainer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// Paths lists the locations of devcontainer.json, relative to the project root.
+var Paths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// ErrNotFound is returned when the project has no devcontainer.json.
+var ErrNotFound = errors.New("no .devcontainer/devcontainer.json or .devcontainer.json found")
+
+// Config is the subset of devcontainer.json that gates use.
+type Config struct {
+	Image         string            `json:"image"`
+	Build         *Build            `json:"build"`
+	ContainerEnv  map[string]string `json:"containerEnv"`
+	RemoteEnv     map[string]string `json:"remoteEnv"`
+	ContainerUser string            `json:"containerUser"`
+	RemoteUser    string            `json:"remoteUser"`
+	// Lifecycle commands are a string (run by a shell), an array (a command
+	// and its arguments), or an object of named commands.
+	OnCreateCommand      json.RawMessage `json:"onCreateCommand"`
+	UpdateContentCommand json.RawMessage `json:"updateContentCommand"`
+	PostCreateCommand    json.RawMessage `json:"postCreateCommand"`
+}
+
+// Build is the Dockerfile build of a dev container.
+type Build struct {
+	Dockerfile string `json:"dockerfile"`
+}
+
+// Load reads the devcontainer.json of the project in dir.
+func Load(dir string) (*Config, error) {
+	for _, rel := range Paths {
+		path := filepath.Join(dir, rel)
+		data, err := os.ReadFile(path) // #nosec G304 -- fixed paths within the project
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+		var cfg Config
+		if err := json.Unmarshal(standardJSON(data), &cfg); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", rel, err)
+		}
+		return &cfg, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Spec returns how to start the dev container in the pool. Environment values
+// may reference the host environment as ${localEnv:NAME} or
+// ${localEnv:NAME:default}, which getenv resolves.
+func (c *Config) Spec(getenv func(string) string) (pool.ContainerSpec, error) {
+	if c.Image == "" {
+		if c.Build != nil {
+			return pool.ContainerSpec{}, errors.New(`devcontainer.json builds its image from a Dockerfile; build it first (e.g., "devcontainer build --image-name") and set "image"`)
+		}
+		return pool.ContainerSpec{}, errors.New(`devcontainer.json has no "image"`)
+	}
+
+	env := make(map[string]string, len(c.ContainerEnv)+len(c.RemoteEnv))
+	for k, v := range c.ContainerEnv {
+		env[k] = expandLocalEnv(v, getenv)
+	}
+	for k, v := range c.RemoteEnv {
+		env[k] = expandLocalEnv(v, getenv)
+	}
+	spec := pool.ContainerSpec{Image: c.Image, User: c.RemoteUser}
+	if spec.User == "" {
+		spec.User = c.ContainerUser
+	}
+	for k, v := range env {
+		spec.Env = append(spec.Env, k+"="+v)
+	}
+	sort.Strings(spec.Env)
+
+	var setup []string
+	for _, raw := range []json.RawMessage{c.OnCreateCommand, c.UpdateContentCommand, c.PostCreateCommand} {
+		cmds, err := lifecycleCommands(raw)
+		if err != nil {
+			return pool.ContainerSpec{}, err
+		}
+		setup = append(setup, cmds...)
+	}
+	spec.Setup = strings.Join(setup, " && ")
+	return spec, nil
+}
+
+// lifecycleCommands returns the shell commands of a lifecycle command.
+func lifecycleCommands(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var (
+		str  string
+		args []string
+		obj  map[string]json.RawMessage
+	)
+	switch {
+	case json.Unmarshal(raw, &str) == nil:
+		if str == "" {
+			return nil, nil
+		}
+		return []string{"(" + str + ")"}, nil
+	case json.Unmarshal(raw, &args) == nil:
+		if len(args) == 0 {
+			return nil, nil
+		}
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		return []string{strings.Join(quoted, " ")}, nil
+	case json.Unmarshal(raw, &obj) == nil:
+		// Named commands run in parallel in dev containers; here they run in name order.
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var cmds []string
+		for _, name := range names {
+			sub, err := lifecycleCommands(obj[name])
+			if err != nil {
+				return nil, err
+			}
+			cmds = append(cmds, sub...)
+		}
+		return cmds, nil
+	default:
+		return nil, fmt.Errorf("devcontainer.json: unsupported lifecycle command %s", raw)
+	}
+}
+
+// localEnvRef matches ${localEnv:NAME} and ${localEnv:NAME:default}.
+var localEnvRef = regexp.MustCompile(`\$\{localEnv:([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+func expandLocalEnv(s string, getenv func(string) string) string {
+	return localEnvRef.ReplaceAllStringFunc(s, func(ref string) string {
+		m := localEnvRef.FindStringSubmatch(ref)
+		if v := getenv(m[1]); v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// standardJSON turns JSON with comments and trailing commas, as
+// devcontainer.json allows, into standard JSON.
+func standardJSON(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing bracket.
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Pool starts gates in the dev container of a project. It implements the pool
+// role of container gates: the image a gate asks for is replaced by the dev
+// container's. devcontainer.json is read on first use.
+type Pool struct {
+	pool       *pool.Pool
+	projectDir string
+	getenv     func(string) string
+
+	once sync.Once
+	spec pool.ContainerSpec
+	err  error
+}
+
+// NewPool creates a Pool for the project in projectDir.
+func NewPool(p *pool.Pool, projectDir string) *Pool {
+	return &Pool{pool: p, projectDir: projectDir, getenv: os.Getenv}
+}
+
+// GetOrCreate returns a warm dev container with projectPath mounted.
+func (d *Pool) GetOrCreate(ctx context.Context, _, projectPath string, writable bool) (string, error) {
+	return d.GetOrCreateSpec(ctx, pool.ContainerSpec{}, projectPath, writable)
+}
+
+// GetOrCreateSpec returns a warm dev container that also ran the setup command
+// of spec after the dev container's own. The image of spec is ignored.
+func (d *Pool) GetOrCreateSpec(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error) {
+	dev, err := d.devSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	return d.pool.GetOrCreateSpec(ctx, dev, projectPath, writable)
+}
+
+// CreateEphemeral starts a fresh dev container for one run, with the setup
+// command of spec run after the dev container's own. The image of spec is ignored.
+func (d *Pool) CreateEphemeral(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error) {
+	dev, err := d.devSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	return d.pool.CreateEphemeral(ctx, dev, projectPath, writable)
+}
+
+// RemoveEphemeral removes a dev container started by CreateEphemeral.
+func (d *Pool) RemoveEphemeral(ctx context.Context, containerID string) error {
+	return d.pool.RemoveEphemeral(ctx, containerID)
+}
+
+// devSpec returns the spec of the dev container with the setup command of spec.
+func (d *Pool) devSpec(spec pool.ContainerSpec) (pool.ContainerSpec, error) {
+	dev, err := d.Spec()
+	if err != nil {
+		return pool.ContainerSpec{}, err
+	}
+	if spec.Setup != "" {
+		dev.Setup = strings.Join(slices.DeleteFunc([]string{dev.Setup, spec.Setup}, func(s string) bool { return s == "" }), " && ")
+	}
+	return dev, nil
+}
+
+// Spec returns the container spec of the project's dev container. The
+// project's devcontainer.json is read on first use.
+func (d *Pool) Spec() (pool.ContainerSpec, error) {
+	d.once.Do(func() {
+		cfg, err := Load(d.projectDir)
+		if err != nil {
+			d.err = err
+			return
+		}
+		d.spec, d.err = cfg.Spec(d.getenv)
+	})
+	return d.spec, d.err
+}
@@ -0,0 +1,79 @@
+package devcontainer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_Spec(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, ".devcontainer/devcontainer.json", `{
+	// The Go toolchain developers use.
+	"image": "mcr.microsoft.com/devcontainers/go:1-1.25", /* pinned */
+	"containerEnv": {"GOFLAGS": "-mod=readonly", "TOKEN": "${localEnv:CI_TOKEN:none}"},
+	"remoteEnv": {"URL": "http://example.com/a"},
+	"remoteUser": "vscode",
+	"onCreateCommand": ["go", "install", "it's"],
+	"postCreateCommand": {"b": "make tools", "a": "go mod download",},
+}`)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	spec, err := cfg.Spec(func(string) string { return "" })
+	if err != nil {
+		t.Fatalf("Spec: %v", err)
+	}
+	if spec.Image != "mcr.microsoft.com/devcontainers/go:1-1.25" || spec.User != "vscode" {
+		t.Errorf("unexpected spec %+v", spec)
+	}
+	if got := strings.Join(spec.Env, " "); got != "GOFLAGS=-mod=readonly TOKEN=none URL=http://example.com/a" {
+		t.Errorf("env = %q", got)
+	}
+	want := `'go' 'install' 'it'\''s' && (go mod download) && (make tools)`
+	if spec.Setup != want {
+		t.Errorf("setup = %q, want %q", spec.Setup, want)
+	}
+
+	spec, _ = cfg.Spec(func(name string) string { return map[string]string{"CI_TOKEN": "abc"}[name] })
+	if !strings.Contains(strings.Join(spec.Env, " "), "TOKEN=abc") {
+		t.Errorf("expected ${localEnv:CI_TOKEN} to be expanded, got %v", spec.Env)
+	}
+}
+
+func TestLoad_RootFile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, ".devcontainer.json", `{"image": "node:22"}`)
+	cfg, err := Load(dir)
+	if err != nil || cfg.Image != "node:22" {
+		t.Fatalf("Load = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	if _, err := Load(t.TempDir()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSpec_Dockerfile(t *testing.T) {
+	cfg := &Config{Build: &Build{Dockerfile: "Dockerfile"}}
+	if _, err := cfg.Spec(os.Getenv); err == nil || !strings.Contains(err.Error(), "Dockerfile") {
+		t.Errorf("expected an error for a Dockerfile build, got %v", err)
+	}
+}
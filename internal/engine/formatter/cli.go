@@ -1,7 +1,10 @@
 package formatter
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
@@ -18,10 +21,33 @@ const (
 	ansiDim    = "\033[2m"
 )
 
+// highContrast replaces colors in accessibility mode: bold, bright colors, and no dim text.
+var highContrast = map[string]string{
+	ansiBold:   ansiBold,
+	ansiRed:    "\033[1;91m",
+	ansiGreen:  "\033[1;92m",
+	ansiYellow: "\033[1;93m",
+	ansiCyan:   "\033[1;96m",
+	ansiDim:    "",
+}
+
+// Groupings of findings in the CLI report.
+const (
+	// GroupByGate lists findings under the gate that reported them.
+	GroupByGate = "gate"
+	// GroupByFile merges the findings of all gates per file, sorted by line.
+	GroupByFile = "file"
+)
+
 // CLIFormatter outputs RunResult as a human-readable CLI report.
 type CLIFormatter struct {
 	Color   bool
 	Verbose bool
+	// Accessible pairs every icon with a status word, uses a high-contrast palette,
+	// and ends the report with a plain-text summary for screen readers.
+	Accessible bool
+	// GroupBy is GroupByGate (the default if empty) or GroupByFile.
+	GroupBy string
 }
 
 // NewCLIFormatter creates a new CLIFormatter.
@@ -49,21 +75,61 @@ func (f *CLIFormatter) Format(result RunResult) string {
 	// Gate summary
 	for _, g := range result.Gates {
 		gateIcon := f.gateIcon(g)
-		duration := fmt.Sprintf("%dms", g.DurationMs)
+		duration := f.colorize(fmt.Sprintf("%dms", g.DurationMs), ansiDim)
+		if g.OverBudget() {
+			duration = f.colorize(fmt.Sprintf("%dms", g.DurationMs), ansiYellow)
+		}
 
 		b.WriteString(fmt.Sprintf("  %s %s %s\n",
 			gateIcon,
 			f.colorize(g.Name, ansiBold),
-			f.colorize(duration, ansiDim)))
+			duration))
+
+		if g.OverBudget() {
+			b.WriteString(fmt.Sprintf("    🐢 %s\n", f.colorize("over its "+budgetString(g.BudgetMs)+" budget", ansiYellow)))
+		}
+
+		if g.Flaky {
+			b.WriteString(fmt.Sprintf("    🎲 %s\n", f.colorize(flakyNote(g), ansiYellow)))
+		}
+		if g.Deferred {
+			b.WriteString(fmt.Sprintf("    ⏳ %s\n", f.colorize("deferred: "+g.DeferReason, ansiDim)))
+		}
+		if g.Cancelled {
+			b.WriteString(fmt.Sprintf("    ⏹️  %s\n", f.colorize(g.SkipReason, ansiDim)))
+		} else if g.SkipReason != "" {
+			b.WriteString(fmt.Sprintf("    ⏭️  %s\n", f.colorize("skipped: "+g.SkipReason, ansiDim)))
+		}
+		for _, t := range g.Truncated {
+			b.WriteString(fmt.Sprintf("    ✂️  %s\n", f.colorize("truncated: "+t, ansiDim)))
+		}
+		if g.Usage != nil {
+			b.WriteString(fmt.Sprintf("    🪙 %s\n", f.colorize(usageSummary(*g.Usage), ansiDim)))
+		}
 
 		// System error
-		if g.SystemError != "" {
+		if g.SystemError != "" && g.WarnOnError {
+			b.WriteString(fmt.Sprintf("    ⚠️  %s\n", f.colorize(g.SystemError+" (on_error: warn, not blocking)", ansiYellow)))
+		} else if g.SystemError != "" {
 			b.WriteString(fmt.Sprintf("    💥 %s\n", f.colorize(g.SystemError, ansiRed)))
 		}
 
 		// Structured errors
-		for _, e := range g.Errors {
-			f.writeError(&b, e)
+		if f.GroupBy != GroupByFile {
+			for _, e := range g.Errors {
+				f.writeError(&b, e)
+			}
+		}
+
+		// Team remediation guidance
+		if g.OnFailMessage != "" {
+			for i, line := range strings.Split(strings.TrimRight(g.OnFailMessage, "\n"), "\n") {
+				prefix := "  "
+				if i == 0 {
+					prefix = "👉"
+				}
+				b.WriteString(fmt.Sprintf("    %s %s\n", prefix, f.colorize(line, ansiYellow)))
+			}
 		}
 
 		// Raw output in verbose mode
@@ -75,20 +141,227 @@ func (f *CLIFormatter) Format(result RunResult) string {
 		}
 	}
 
+	if f.GroupBy == GroupByFile {
+		f.writeByFile(&b, result.Gates)
+	}
+
+	f.writeSuppressed(&b, result.Gates)
+
+	if result.Usage != nil {
+		b.WriteString(fmt.Sprintf("\n  🪙 %s\n", f.colorize("LLM usage: "+usageSummary(*result.Usage), ansiDim)))
+	}
+
+	if len(result.AutoFixed) > 0 {
+		b.WriteString(fmt.Sprintf("\n  🔧 %s %s\n",
+			f.colorize(fmt.Sprintf("Auto-fixed and re-staged %s:", count(len(result.AutoFixed), "file", "files")), ansiBold),
+			strings.Join(result.AutoFixed, ", ")))
+	}
+
+	if result.OverBudget() {
+		b.WriteString(fmt.Sprintf("\n  🐢 %s\n", f.colorize(budgetWarning(result), ansiYellow)))
+	}
+
+	if f.Accessible {
+		b.WriteString("\n" + plainSummary(result) + "\n")
+	}
+
 	return b.String()
 }
 
+// plainSummary describes a run in one plain sentence without icons or color,
+// e.g. "Summary: Gatekeeper failed. 3 gates: 1 passed, 1 failed (gosec), 1 skipped. 2 findings."
+func plainSummary(result RunResult) string {
+	var passed, skipped, cancelled, deferred, findings int
+	var failed, errored []string
+	for _, g := range result.Gates {
+		findings += len(g.Errors)
+		switch {
+		case g.Deferred:
+			deferred++
+		case g.Cancelled:
+			cancelled++
+		case g.Skipped:
+			skipped++
+		case g.SystemError != "":
+			errored = append(errored, g.Name)
+		case g.Passed:
+			passed++
+		default:
+			failed = append(failed, g.Name)
+		}
+	}
+
+	status := "passed"
+	if !result.Passed {
+		status = "failed"
+	}
+	parts := []string{fmt.Sprintf("%d passed", passed)}
+	if len(failed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed (%s)", len(failed), strings.Join(failed, ", ")))
+	}
+	if len(errored) > 0 {
+		parts = append(parts, fmt.Sprintf("%d could not run (%s)", len(errored), strings.Join(errored, ", ")))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", skipped))
+	}
+	if cancelled > 0 {
+		parts = append(parts, fmt.Sprintf("%d cancelled", cancelled))
+	}
+	if deferred > 0 {
+		parts = append(parts, fmt.Sprintf("%d deferred", deferred))
+	}
+
+	s := fmt.Sprintf("Summary: Gatekeeper %s. %s: %s.", status, count(len(result.Gates), "gate", "gates"), strings.Join(parts, ", "))
+	if findings > 0 {
+		s += " " + count(findings, "finding", "findings") + "."
+	}
+	return s
+}
+
+// count renders "1 gate" or "3 gates".
+func count(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// flakyNote describes a flaky gate, e.g. "flaky: passed and failed on the
+// same changes in recent runs".
+func flakyNote(g GateResult) string {
+	note := "flaky: passed and failed on the same changes in recent runs"
+	if g.Downgraded {
+		note += "; warning instead of blocking"
+	}
+	return note
+}
+
+// usageSummary renders token usage, e.g. "1,234 tokens (1,000 in / 234 out, ~$0.0042)",
+// prefixed with "estimated" for dry runs.
+func usageSummary(u TokenUsage) string {
+	s := fmt.Sprintf("%s tokens (%s in / %s out", groupDigits(u.Total()), groupDigits(u.PromptTokens), groupDigits(u.ResponseTokens))
+	if u.CostUSD > 0 {
+		s += fmt.Sprintf(", ~$%.4f", u.CostUSD)
+	}
+	s += ")"
+	if u.Estimated {
+		s = "estimated " + s
+	}
+	return s
+}
+
+// groupDigits formats n with thousands separators.
+func groupDigits(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// writeSuppressed lists findings filtered out by triage in a separate section.
+func (f *CLIFormatter) writeSuppressed(b *strings.Builder, gates []GateResult) {
+	total := 0
+	for _, g := range gates {
+		total += len(g.Suppressed)
+	}
+	if total == 0 {
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("\n  🔕 %s\n", f.colorize(fmt.Sprintf("Suppressed (%d)", total), ansiDim)))
+	for _, g := range gates {
+		if len(g.Suppressed) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("  %s\n", f.colorize(g.Name, ansiDim)))
+		for _, e := range g.Suppressed {
+			f.writeError(b, e)
+		}
+	}
+}
+
+// fileFinding is a finding with the gate that reported it.
+type fileFinding struct {
+	gate string
+	parser.StructuredError
+}
+
+// writeByFile lists the findings of all gates per file, files in name order
+// and findings by line, each annotated with its gate and tool. Findings
+// without a file come last.
+func (f *CLIFormatter) writeByFile(b *strings.Builder, gates []GateResult) {
+	byFile := map[string][]fileFinding{}
+	for _, g := range gates {
+		for _, e := range g.Errors {
+			byFile[e.File] = append(byFile[e.File], fileFinding{gate: g.Name, StructuredError: e})
+		}
+	}
+	if len(byFile) == 0 {
+		return
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(a, b string) int {
+		if (a == "") != (b == "") {
+			return strings.Compare(b, a) // "" last
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, file := range files {
+		findings := byFile[file]
+		slices.SortStableFunc(findings, func(a, b fileFinding) int {
+			return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+		})
+		name := file
+		if name == "" {
+			name = "(no file)"
+		}
+		b.WriteString(fmt.Sprintf("\n  📄 %s\n", f.colorize(name, ansiBold)))
+		for _, e := range findings {
+			origin := e.gate
+			if e.Tool != "" && e.Tool != e.gate {
+				origin += "/" + e.Tool
+			}
+			f.writeFinding(b, e.StructuredError, lineColumn(e.StructuredError), origin)
+		}
+	}
+}
+
 func (f *CLIFormatter) writeError(b *strings.Builder, e parser.StructuredError) {
-	// Location
 	loc := ""
 	if e.File != "" {
 		loc = e.File
-		if e.Line > 0 {
-			loc = fmt.Sprintf("%s:%d", loc, e.Line)
-			if e.Column > 0 {
-				loc = fmt.Sprintf("%s:%d", loc, e.Column)
-			}
+		if lc := lineColumn(e); lc != "" {
+			loc += ":" + lc
 		}
+	}
+	f.writeFinding(b, e, loc, "")
+}
+
+// lineColumn renders the line and column of a finding, e.g. "45:12", or "" if
+// it has no line.
+func lineColumn(e parser.StructuredError) string {
+	switch {
+	case e.Line <= 0:
+		return ""
+	case e.Column > 0:
+		return fmt.Sprintf("%d:%d", e.Line, e.Column)
+	default:
+		return strconv.Itoa(e.Line)
+	}
+}
+
+// writeFinding prints a finding at location loc, followed by the gate and
+// tool that reported it if origin is set.
+func (f *CLIFormatter) writeFinding(b *strings.Builder, e parser.StructuredError, loc, origin string) {
+	// Location
+	if loc != "" {
 		loc = f.colorize(loc, ansiCyan) + " "
 	}
 
@@ -102,6 +375,11 @@ func (f *CLIFormatter) writeError(b *strings.Builder, e parser.StructuredError)
 	case "warning":
 		sevIcon = "⚠️"
 		sevColor = ansiYellow
+	case "suggestion":
+		sevIcon = "💬"
+	}
+	if f.Accessible {
+		sevIcon = fmt.Sprintf("%s %s:", sevIcon, severityWord(e.Severity))
 	}
 
 	// Rule
@@ -110,7 +388,11 @@ func (f *CLIFormatter) writeError(b *strings.Builder, e parser.StructuredError)
 		rule = f.colorize("["+e.Rule+"]", ansiDim) + " "
 	}
 
-	b.WriteString(fmt.Sprintf("    %s %s%s%s\n", sevIcon, loc, rule, f.colorize(e.Message, sevColor)))
+	if origin != "" {
+		origin = " " + f.colorize("("+origin+")", ansiDim)
+	}
+
+	b.WriteString(fmt.Sprintf("    %s %s%s%s%s\n", sevIcon, loc, rule, f.colorize(e.Message, sevColor), origin))
 
 	// Hint
 	if e.Hint != "" {
@@ -118,10 +400,30 @@ func (f *CLIFormatter) writeError(b *strings.Builder, e parser.StructuredError)
 	}
 }
 
+// severityWord names a finding severity for accessibility mode.
+func severityWord(severity string) string {
+	if severity == "" {
+		return "info"
+	}
+	return severity
+}
+
 func (f *CLIFormatter) gateIcon(g GateResult) string {
+	if f.Accessible {
+		return f.gateIconWithWord(g)
+	}
+	if g.Deferred {
+		return "⏳"
+	}
+	if g.Cancelled {
+		return "⏹️"
+	}
 	if g.Skipped {
 		return "⏭️"
 	}
+	if g.SystemError != "" && g.WarnOnError {
+		return f.colorize("⚠️", ansiYellow)
+	}
 	if g.SystemError != "" {
 		return "💥"
 	}
@@ -131,9 +433,36 @@ func (f *CLIFormatter) gateIcon(g GateResult) string {
 	return f.colorize("❌", ansiRed)
 }
 
+// gateIconWithWord pairs the gate icon with a status word, so status never
+// depends on telling emoji or colors apart.
+func (f *CLIFormatter) gateIconWithWord(g GateResult) string {
+	switch {
+	case g.Deferred:
+		return "⏳ DEFERRED"
+	case g.Cancelled:
+		return "⏹️ CANCELLED"
+	case g.Skipped:
+		return "⏭️ SKIPPED"
+	case g.SystemError != "" && g.WarnOnError:
+		return f.colorize("⚠️ WARN", ansiYellow)
+	case g.SystemError != "":
+		return f.colorize("💥 ERROR", ansiRed)
+	case g.Passed:
+		return f.colorize("✅ PASS", ansiGreen)
+	default:
+		return f.colorize("❌ FAIL", ansiRed)
+	}
+}
+
 func (f *CLIFormatter) colorize(s, code string) string {
 	if !f.Color {
 		return s
 	}
+	if f.Accessible {
+		code = highContrast[code]
+		if code == "" {
+			return s
+		}
+	}
 	return code + s + ansiReset
 }
@@ -2,20 +2,119 @@
 package formatter
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 )
 
 // GateResult holds the result of executing a single gate.
 type GateResult struct {
-	Name        string                   `json:"name"`
-	Type        string                   `json:"type"`
-	Passed      bool                     `json:"passed"`
-	Blocking    bool                     `json:"blocking"`
-	Skipped     bool                     `json:"skipped,omitempty"`
-	DurationMs  int64                    `json:"duration_ms"`
-	Errors      []parser.StructuredError `json:"errors,omitempty"`
-	SystemError string                   `json:"system_error,omitempty"`
-	RawOutput   string                   `json:"raw_output,omitempty"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Passed      bool     `json:"passed"`
+	Blocking    bool     `json:"blocking"`
+	Skipped     bool     `json:"skipped,omitempty"`
+	Deferred    bool     `json:"deferred,omitempty"`
+	DeferReason string   `json:"defer_reason,omitempty"`
+	SkipReason  string   `json:"skip_reason,omitempty"`
+	Truncated   []string `json:"truncated,omitempty"`
+	// Cancelled marks the placeholder result of a gate that fail-fast stopped
+	// or never started; it is also Skipped.
+	Cancelled bool `json:"cancelled,omitempty"`
+	// WarnOnError is set for gates with on_error: warn, whose system errors
+	// are reported as warnings instead of failing the run.
+	WarnOnError bool `json:"warn_on_error,omitempty"`
+	// OnFailMessage is the gate's configured remediation guidance, set only when it failed.
+	OnFailMessage string                   `json:"on_fail_message,omitempty"`
+	DurationMs    int64                    `json:"duration_ms"`
+	Errors        []parser.StructuredError `json:"errors,omitempty"`
+	Suppressed    []parser.StructuredError `json:"suppressed,omitempty"`
+	SystemError   string                   `json:"system_error,omitempty"`
+	RawOutput     string                   `json:"raw_output,omitempty"`
+	Usage         *TokenUsage              `json:"usage,omitempty"`
+	// Timings breaks the duration of container gates down by phase.
+	Timings *PhaseTimings `json:"timings,omitempty"`
+	// InputHash identifies what the gate checked: the staged changes and the
+	// gate's configuration. Runs with the same hash should agree.
+	InputHash string `json:"input_hash,omitempty"`
+	// BudgetMs is how long the gate was expected to take (its budget); 0 if
+	// it has none.
+	BudgetMs int64 `json:"budget_ms,omitempty"`
+	// Flaky marks a gate that both passed and failed on the same input in
+	// recent runs.
+	Flaky bool `json:"flaky,omitempty"`
+	// Downgraded marks a flaky blocking gate that warns instead of blocking
+	// in this run (defaults.flaky_downgrade).
+	Downgraded bool `json:"downgraded,omitempty"`
+}
+
+// OverBudget reports whether the gate took longer than its budget.
+func (g GateResult) OverBudget() bool {
+	return g.BudgetMs > 0 && g.DurationMs > g.BudgetMs
+}
+
+// Blocks reports whether the result fails the run: a blocking gate that
+// failed its checks, or could not run unless its on_error policy is warn.
+func (g GateResult) Blocks() bool {
+	if !g.Blocking || g.Cancelled {
+		return false
+	}
+	if g.SystemError != "" {
+		return !g.WarnOnError
+	}
+	return !g.Passed
+}
+
+// PhaseTimings are the durations of the phases of a gate, so slowness can be
+// traced to Docker, the tool, or the LLM provider. A phase that did not run
+// (e.g., parsing after a failed exec, or any container phase of an LLM gate) is 0.
+type PhaseTimings struct {
+	// AcquireMs is the time to get a warm container or create one, excluding
+	// the image pull.
+	AcquireMs int64 `json:"container_acquire_ms"`
+	// ImagePullMs is the time to pull the image of a new container.
+	ImagePullMs int64 `json:"image_pull_ms"`
+	// ExecMs is the time to run the command in the container.
+	ExecMs int64 `json:"exec_ms"`
+	// ParseMs is the time to parse the tool output.
+	ParseMs int64 `json:"parse_ms"`
+	// LLMMs is the time an LLM gate spent on provider requests.
+	LLMMs int64 `json:"llm_ms"`
+}
+
+// TokenUsage is the LLM token consumption of a gate or run.
+// CostUSD is an estimate from list prices; 0 if the model's price is unknown.
+type TokenUsage struct {
+	PromptTokens   int     `json:"prompt_tokens"`
+	ResponseTokens int     `json:"response_tokens"`
+	CostUSD        float64 `json:"cost_usd,omitempty"`
+	// Estimated is set for dry runs, which price the prompts without sending them.
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// Total returns prompt plus response tokens.
+func (u TokenUsage) Total() int {
+	return u.PromptTokens + u.ResponseTokens
+}
+
+// SumUsage totals the token usage of gates, or returns nil if none used an LLM.
+func SumUsage(gates []GateResult) *TokenUsage {
+	var total *TokenUsage
+	for _, g := range gates {
+		if g.Usage == nil {
+			continue
+		}
+		if total == nil {
+			total = &TokenUsage{}
+		}
+		total.PromptTokens += g.Usage.PromptTokens
+		total.ResponseTokens += g.Usage.ResponseTokens
+		total.CostUSD += g.Usage.CostUSD
+		total.Estimated = total.Estimated || g.Usage.Estimated
+	}
+	return total
 }
 
 // RunResult holds the aggregated result of all gates in a run.
@@ -23,6 +122,56 @@ type RunResult struct {
 	Passed     bool         `json:"passed"`
 	DurationMs int64        `json:"duration_ms"`
 	Gates      []GateResult `json:"gates"`
+	Usage      *TokenUsage  `json:"usage,omitempty"`
+	// AutoFixed lists the staged files that writable gates modified and that
+	// were re-staged (run --fix or writable_policy: apply).
+	AutoFixed []string `json:"auto_fixed,omitempty"`
+	// Tree is the git tree of the staged index the gates checked, which is the
+	// tree of the commit they gate. Attestations bind a run to it.
+	Tree string `json:"tree,omitempty"`
+	// CommitBudgetMs is how long the run was expected to take
+	// (defaults.commit_budget); 0 if there is no budget.
+	CommitBudgetMs int64 `json:"commit_budget_ms,omitempty"`
+}
+
+// OverBudget reports whether the run took longer than the commit budget.
+func (r RunResult) OverBudget() bool {
+	return r.CommitBudgetMs > 0 && r.DurationMs > r.CommitBudgetMs
+}
+
+// budgetWarning describes a run over the commit budget and suggests moving
+// its slow gates to another profile: the gates over their own budget, or
+// else the slowest gate.
+func budgetWarning(r RunResult) string {
+	var slow []string
+	var slowest *GateResult
+	for i, g := range r.Gates {
+		if g.OverBudget() {
+			slow = append(slow, g.Name)
+		}
+		if slowest == nil || g.DurationMs > slowest.DurationMs {
+			slowest = &r.Gates[i]
+		}
+	}
+	if len(slow) == 0 && slowest != nil {
+		slow = []string{slowest.Name}
+	}
+	return fmt.Sprintf("The run took %dms, over the %s commit budget. Consider moving slow gates (%s) to a profile run pre-push or in CI.",
+		r.DurationMs, budgetString(r.CommitBudgetMs), strings.Join(slow, ", "))
+}
+
+// budgetString renders a budget in milliseconds as configured, e.g. "10s".
+func budgetString(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// DurationString renders a duration in milliseconds for tables, switching to
+// seconds above one second, e.g. "850ms" or "2.3s".
+func DurationString(ms int64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
 }
 
 // Formatter formats a RunResult into a human-readable or machine-readable string.
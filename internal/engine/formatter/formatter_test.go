@@ -246,3 +246,270 @@ func TestCLIFormatter_WarningAndInfoSeverity(t *testing.T) {
 		t.Error("expected info icon")
 	}
 }
+
+func TestCLIFormatter_SuppressedSection(t *testing.T) {
+	result := RunResult{
+		Passed: true,
+		Gates: []GateResult{
+			{
+				Name:   "security",
+				Passed: true,
+				Suppressed: []parser.StructuredError{
+					{File: "main.go", Line: 3, Severity: "error", Message: "false alarm"},
+				},
+			},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+
+	if !strings.Contains(output, "Suppressed (1)") {
+		t.Errorf("expected suppressed section, got:\n%s", output)
+	}
+	if !strings.Contains(output, "false alarm") {
+		t.Error("expected suppressed finding to be listed")
+	}
+	if strings.Contains(NewCLIFormatter(false, false).Format(sampleResult()), "Suppressed") {
+		t.Error("expected no suppressed section without suppressed findings")
+	}
+}
+
+func TestCLIFormatter_DeferredGate(t *testing.T) {
+	result := RunResult{
+		Passed: true,
+		Gates: []GateResult{
+			{Name: "review", Type: "llm", Passed: true, Skipped: true, Deferred: true, DeferReason: "provider slow"},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	if !strings.Contains(output, "⏳ review") || !strings.Contains(output, "deferred: provider slow") {
+		t.Errorf("expected deferred gate rendering, got:\n%s", output)
+	}
+}
+
+func TestCLIFormatter_FlakyGate(t *testing.T) {
+	result := RunResult{
+		Passed: true,
+		Gates: []GateResult{
+			{Name: "test", Type: "exec", Flaky: true, Downgraded: true},
+			{Name: "lint", Type: "exec", Passed: true, Flaky: true},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	if !strings.Contains(output, "🎲 flaky: passed and failed on the same changes in recent runs; warning instead of blocking") {
+		t.Errorf("expected downgraded flaky gate rendering, got:\n%s", output)
+	}
+	if strings.Count(output, "🎲") != 2 {
+		t.Errorf("expected a flaky badge per flaky gate, got:\n%s", output)
+	}
+}
+
+func TestCLIFormatter_Budgets(t *testing.T) {
+	result := RunResult{
+		Passed:         true,
+		DurationMs:     75000,
+		CommitBudgetMs: 60000,
+		Gates: []GateResult{
+			{Name: "lint", Passed: true, DurationMs: 4000, BudgetMs: 10000},
+			{Name: "test", Passed: true, DurationMs: 70000, BudgetMs: 30000},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	if strings.Count(output, "over its") != 1 || !strings.Contains(output, "🐢 over its 30s budget") {
+		t.Errorf("expected only test to be over budget, got:\n%s", output)
+	}
+	if !strings.Contains(output, "🐢 The run took 75000ms, over the 1m0s commit budget. Consider moving slow gates (test) to a profile run pre-push or in CI.") {
+		t.Errorf("expected commit budget warning, got:\n%s", output)
+	}
+
+	result.Gates[1].BudgetMs = 0
+	output = NewCLIFormatter(false, false).Format(result)
+	if !strings.Contains(output, "slow gates (test)") {
+		t.Errorf("expected the slowest gate to be named without gate budgets, got:\n%s", output)
+	}
+
+	result.CommitBudgetMs = 0
+	if output = NewCLIFormatter(false, false).Format(result); strings.Contains(output, "🐢") {
+		t.Errorf("expected no budget warnings without budgets, got:\n%s", output)
+	}
+}
+
+func TestCLIFormatter_CancelledGate(t *testing.T) {
+	result := RunResult{
+		Gates: []GateResult{
+			{Name: "lint", Type: "exec", Blocking: true},
+			{Name: "test", Skipped: true, Cancelled: true, SkipReason: "cancelled after lint failed (fail-fast)"},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	if !strings.Contains(output, "⏹️ test") || !strings.Contains(output, "⏹️  cancelled after lint failed (fail-fast)") {
+		t.Errorf("expected cancelled gate rendering, got:\n%s", output)
+	}
+
+	f := NewCLIFormatter(false, false)
+	f.Accessible = true
+	output = f.Format(result)
+	summary := "Summary: Gatekeeper failed. 2 gates: 0 passed, 1 failed (lint), 1 cancelled."
+	if !strings.Contains(output, "⏹️ CANCELLED") || !strings.HasSuffix(output, "\n"+summary+"\n") {
+		t.Errorf("expected cancelled status word and summary %q, got:\n%s", summary, output)
+	}
+}
+
+func TestCLIFormatter_GroupByFile(t *testing.T) {
+	result := RunResult{
+		Gates: []GateResult{
+			{Name: "lint", Blocking: true, Errors: []parser.StructuredError{
+				{File: "main.go", Line: 30, Severity: "warning", Message: "unused variable", Tool: "golangci-lint"},
+				{File: "api/db.go", Line: 23, Column: 5, Severity: "error", Message: "SQL string formatting", Tool: "golangci-lint"},
+			}},
+			{Name: "security", Blocking: true, Errors: []parser.StructuredError{
+				{File: "main.go", Line: 12, Column: 3, Severity: "error", Rule: "G101", Message: "hardcoded credential", Tool: "gosec"},
+				{Severity: "warning", Message: "go.sum is out of date", Tool: "security"},
+			}},
+		},
+	}
+
+	f := NewCLIFormatter(false, false)
+	f.GroupBy = GroupByFile
+	output := f.Format(result)
+
+	want := []string{
+		"📄 api/db.go",
+		"❌ 23:5 SQL string formatting (lint/golangci-lint)",
+		"📄 main.go",
+		"❌ 12:3 [G101] hardcoded credential (security/gosec)",
+		"⚠️ 30 unused variable (lint/golangci-lint)",
+		"📄 (no file)",
+		"⚠️ go.sum is out of date (security)",
+	}
+	last := -1
+	for _, w := range want {
+		i := strings.Index(output, w)
+		if i < 0 || i < last {
+			t.Fatalf("expected %q after the previous lines, got:\n%s", w, output)
+		}
+		last = i
+	}
+	if strings.Contains(output, "main.go:12") {
+		t.Errorf("expected findings not to be repeated under their gates, got:\n%s", output)
+	}
+}
+
+func TestCLIFormatter_OnErrorWarn(t *testing.T) {
+	result := RunResult{
+		Passed: true,
+		Gates: []GateResult{
+			{Name: "review", Type: "llm", Blocking: true, WarnOnError: true, SystemError: "LLM review failed: timeout"},
+		},
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	if !strings.Contains(output, "⚠️ review") || !strings.Contains(output, "LLM review failed: timeout (on_error: warn, not blocking)") {
+		t.Errorf("expected a warning notice, got:\n%s", output)
+	}
+	if strings.Contains(output, "💥") {
+		t.Errorf("expected no error icon for on_error: warn, got:\n%s", output)
+	}
+}
+
+func TestCLIFormatter_TokenUsage(t *testing.T) {
+	usage := &TokenUsage{PromptTokens: 12000, ResponseTokens: 345, CostUSD: 0.0123}
+	result := RunResult{
+		Passed: true,
+		Gates: []GateResult{
+			{Name: "review", Type: "llm", Passed: true, Usage: usage},
+			{Name: "secrets", Type: "llm", Passed: true, Skipped: true, SkipReason: "LLM token budget exceeded"},
+		},
+		Usage: usage,
+	}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	assertContains := func(sub string) {
+		t.Helper()
+		if !strings.Contains(output, sub) {
+			t.Errorf("expected output to contain %q, got:\n%s", sub, output)
+		}
+	}
+	assertContains("12,345 tokens (12,000 in / 345 out, ~$0.0123)")
+	assertContains("LLM usage: 12,345 tokens")
+	assertContains("skipped: LLM token budget exceeded")
+}
+
+func TestCLIFormatter_EstimatedUsage(t *testing.T) {
+	gates := []GateResult{
+		{Name: "review", Type: "llm", Passed: true, Skipped: true, SkipReason: "dry run: estimated cost only, diff not sent",
+			Usage: &TokenUsage{PromptTokens: 1000, ResponseTokens: 400, CostUSD: 0.0068, Estimated: true}},
+		{Name: "lint", Type: "exec", Passed: true},
+	}
+	result := RunResult{Passed: true, Gates: gates, Usage: SumUsage(gates)}
+
+	output := NewCLIFormatter(false, false).Format(result)
+	for _, want := range []string{
+		"🪙 estimated 1,400 tokens (1,000 in / 400 out, ~$0.0068)",
+		"LLM usage: estimated 1,400 tokens",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestJSONFormatter_TokenUsage(t *testing.T) {
+	gates := []GateResult{
+		{Name: "a", Usage: &TokenUsage{PromptTokens: 10, ResponseTokens: 5, CostUSD: 0.5}},
+		{Name: "b"},
+		{Name: "c", Usage: &TokenUsage{PromptTokens: 1, ResponseTokens: 1}},
+	}
+	result := RunResult{Passed: true, Gates: gates, Usage: SumUsage(gates)}
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(NewJSONFormatter().Format(result)), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	usage, ok := decoded["usage"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected usage object, got %v", decoded["usage"])
+	}
+	if usage["prompt_tokens"] != float64(11) || usage["response_tokens"] != float64(6) || usage["cost_usd"] != 0.5 {
+		t.Errorf("unexpected usage totals: %v", usage)
+	}
+
+	if SumUsage([]GateResult{{Name: "exec"}}) != nil {
+		t.Error("expected nil usage when no gate used an LLM")
+	}
+}
+
+func TestCLIFormatter_Accessible(t *testing.T) {
+	f := NewCLIFormatter(true, false)
+	f.Accessible = true
+	output := f.Format(sampleResult())
+
+	for _, want := range []string{"✅ PASS", "❌ FAIL", "💥 ERROR", "⏭️ SKIPPED", "❌ error:"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected status word %q in output, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, ansiDim) {
+		t.Error("expected no dim text in accessibility mode")
+	}
+	if !strings.Contains(output, "\033[1;91m") {
+		t.Error("expected high-contrast colors in accessibility mode")
+	}
+
+	summary := "Summary: Gatekeeper failed. 4 gates: 1 passed, 1 failed (security), 1 could not run (format), 1 skipped. 1 finding."
+	if !strings.HasSuffix(output, "\n"+summary+"\n") {
+		t.Errorf("expected plain summary %q at the end, got:\n%s", summary, output)
+	}
+}
+
+func TestDurationString(t *testing.T) {
+	for ms, want := range map[int64]string{0: "0ms", 850: "850ms", 1000: "1.0s", 2345: "2.3s"} {
+		if got := DurationString(ms); got != want {
+			t.Errorf("DurationString(%d) = %q, want %q", ms, got, want)
+		}
+	}
+}
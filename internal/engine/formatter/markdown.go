@@ -0,0 +1,164 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// MarkdownMarker is embedded in markdown output so a CI script can find and
+// update the comment it posted before instead of adding a new one.
+const MarkdownMarker = "<!-- gatekeeper-report -->"
+
+// MarkdownFormatter outputs RunResult as markdown for a PR comment: a summary
+// table of the gates, then a collapsible section with a table of findings per
+// gate that reported any.
+type MarkdownFormatter struct {
+	Verbose bool
+}
+
+// NewMarkdownFormatter creates a new MarkdownFormatter.
+func NewMarkdownFormatter(verbose bool) *MarkdownFormatter {
+	return &MarkdownFormatter{Verbose: verbose}
+}
+
+// Format returns the RunResult as markdown.
+func (f *MarkdownFormatter) Format(result RunResult) string {
+	var b strings.Builder
+	b.WriteString(MarkdownMarker + "\n")
+
+	if result.Passed {
+		fmt.Fprintf(&b, "## ✅ Gatekeeper passed in %dms\n\n", result.DurationMs)
+	} else {
+		fmt.Fprintf(&b, "## ❌ Gatekeeper failed in %dms\n\n", result.DurationMs)
+	}
+
+	b.WriteString("| Gate | Result | Findings | Duration |\n|---|---|---|---|\n")
+	for _, g := range result.Gates {
+		status := MarkdownStatus(g)
+		if g.Flaky {
+			status += " 🎲 flaky"
+		}
+		duration := fmt.Sprintf("%dms", g.DurationMs)
+		if g.OverBudget() {
+			duration += " 🐢"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", MarkdownCell(g.Name), status, len(g.Errors), duration)
+	}
+	if result.OverBudget() {
+		fmt.Fprintf(&b, "\n🐢 %s\n", budgetWarning(result))
+	}
+
+	for _, g := range result.Gates {
+		f.writeGate(&b, g)
+	}
+
+	if result.Usage != nil {
+		fmt.Fprintf(&b, "\n🪙 LLM usage: %s\n", usageSummary(*result.Usage))
+	}
+	if len(result.AutoFixed) > 0 {
+		fmt.Fprintf(&b, "\n🔧 Auto-fixed and re-staged %s: %s\n",
+			count(len(result.AutoFixed), "file", "files"), MarkdownCell(strings.Join(result.AutoFixed, ", ")))
+	}
+	return b.String()
+}
+
+// writeGate writes the collapsible section of a gate, open if the gate
+// failed. Gates with nothing to show beyond their status get no section.
+func (f *MarkdownFormatter) writeGate(b *strings.Builder, g GateResult) {
+	raw := f.Verbose && g.RawOutput != ""
+	if len(g.Errors) == 0 && g.SystemError == "" && g.OnFailMessage == "" && !raw {
+		return
+	}
+
+	open := ""
+	if !g.Passed && !g.Skipped {
+		open = " open"
+	}
+	fmt.Fprintf(b, "\n<details%s>\n<summary>%s <b>%s</b> — %s</summary>\n\n",
+		open, MarkdownStatus(g), MarkdownCell(g.Name), count(len(g.Errors), "finding", "findings"))
+
+	if g.SystemError != "" && g.WarnOnError {
+		fmt.Fprintf(b, "⚠️ %s (on_error: warn, not blocking)\n\n", MarkdownCell(g.SystemError))
+	} else if g.SystemError != "" {
+		fmt.Fprintf(b, "💥 %s\n\n", MarkdownCell(g.SystemError))
+	}
+	if len(g.Errors) > 0 {
+		b.WriteString("| Severity | Location | Rule | Message |\n|---|---|---|---|\n")
+		for _, e := range g.Errors {
+			msg := MarkdownCell(e.Message)
+			if e.Hint != "" {
+				msg += "<br>💡 " + MarkdownCell(e.Hint)
+			}
+			rule := ""
+			if e.Rule != "" {
+				rule = "`" + MarkdownCell(e.Rule) + "`"
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", markdownSeverity(e.Severity), markdownLocation(e), rule, msg)
+		}
+		b.WriteString("\n")
+	}
+	if g.OnFailMessage != "" {
+		fmt.Fprintf(b, "👉 %s\n\n", strings.ReplaceAll(strings.TrimRight(g.OnFailMessage, "\n"), "\n", "<br>"))
+	}
+	if raw {
+		fmt.Fprintf(b, "```\n%s\n```\n\n", strings.TrimRight(strings.ReplaceAll(g.RawOutput, "```", "'''"), "\n"))
+	}
+	b.WriteString("</details>\n")
+}
+
+// MarkdownStatus renders the status of a gate for a markdown table.
+func MarkdownStatus(g GateResult) string {
+	switch {
+	case g.Deferred:
+		return "⏳ deferred"
+	case g.Cancelled:
+		return "⏹️ cancelled"
+	case g.Skipped:
+		return "⏭️ skipped"
+	case g.SystemError != "" && g.WarnOnError:
+		return "⚠️ error (warn)"
+	case g.SystemError != "":
+		return "💥 error"
+	case g.Passed:
+		return "✅ passed"
+	case !g.Blocking:
+		return "⚠️ advisory"
+	default:
+		return "❌ failed"
+	}
+}
+
+// markdownSeverity renders the severity of a finding.
+func markdownSeverity(severity string) string {
+	switch severity {
+	case "error":
+		return "❌ error"
+	case "warning":
+		return "⚠️ warning"
+	case "suggestion":
+		return "💬 suggestion"
+	default:
+		return "ℹ️ " + severityWord(severity)
+	}
+}
+
+// markdownLocation renders "`file:line:column`" for a finding, or "" if it
+// has no file.
+func markdownLocation(e parser.StructuredError) string {
+	if e.File == "" {
+		return ""
+	}
+	loc := e.File
+	if lc := lineColumn(e); lc != "" {
+		loc += ":" + lc
+	}
+	return "`" + MarkdownCell(loc) + "`"
+}
+
+// MarkdownCell escapes s for a markdown table cell and keeps it on one row.
+func MarkdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
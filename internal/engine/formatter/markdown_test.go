@@ -0,0 +1,45 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownFormatter(t *testing.T) {
+	result := sampleResult()
+	result.Gates[1].OnFailMessage = "Move secrets to Vault.\nAsk #security for help."
+	result.Gates[0].Flaky = true
+	result.Gates[0].BudgetMs = 500
+	result.CommitBudgetMs = 1000
+	output := NewMarkdownFormatter(false).Format(result)
+
+	if !strings.HasPrefix(output, MarkdownMarker+"\n## ❌ Gatekeeper failed in 1200ms") {
+		t.Errorf("expected the marker and a failed heading first, got:\n%s", output)
+	}
+	for _, want := range []string{
+		"| lint | ✅ passed 🎲 flaky | 0 | 800ms 🐢 |",
+		"🐢 The run took 1200ms, over the 1s commit budget. Consider moving slow gates (lint)",
+		"| security | ❌ failed | 1 | 400ms |",
+		"| style | ⏭️ skipped | 0 | 0ms |",
+		"<details open>\n<summary>❌ failed <b>security</b> — 1 finding</summary>",
+		"| ❌ error | `main.go:42:10` | `G101` | hardcoded credential<br>💡 Use environment variables instead. |",
+		"👉 Move secrets to Vault.<br>Ask #security for help.",
+		"<summary>💥 error <b>format</b> — 0 findings</summary>",
+		"💥 container timeout",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, output)
+		}
+	}
+	if strings.Count(output, "<details") != 2 {
+		t.Errorf("expected sections only for gates with something to show, got:\n%s", output)
+	}
+}
+
+func TestMarkdownFormatter_EscapesCells(t *testing.T) {
+	result := RunResult{Passed: true, Gates: []GateResult{{Name: "a|b", Passed: true}}}
+	output := NewMarkdownFormatter(false).Format(result)
+	if !strings.Contains(output, `| a\|b | ✅ passed |`) {
+		t.Errorf("expected pipes to be escaped, got:\n%s", output)
+	}
+}
@@ -0,0 +1,98 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// ArtifactGateName is the name of the built-in artifact check.
+const ArtifactGateName = "artifacts"
+
+// artifact is a path that should never be committed. A pattern ending in "/"
+// matches everything under that directory, another pattern with a "/" matches
+// that path, and any other pattern matches a file name in any directory.
+type artifact struct {
+	pattern string
+	what    string
+}
+
+// artifacts are the gatekeeper state paths and common tool output that the
+// artifact gate warns about and "gatekeeper init" adds to .gitignore.
+var artifacts = []artifact{
+	{pattern: ".gatekeeper/results/", what: "gatekeeper run state (results, history, dismissals)"},
+	{pattern: ".gatekeeper/audit.log", what: "the local gatekeeper audit log"},
+	{pattern: "coverage.out", what: "a Go coverage profile"},
+	{pattern: "report.sarif", what: "a generated SARIF report"},
+}
+
+// GitignoreEntries returns the .gitignore entries that keep artifacts out of commits.
+func GitignoreEntries() []string {
+	entries := make([]string, len(artifacts))
+	for i, a := range artifacts {
+		entries[i] = a.pattern
+	}
+	return entries
+}
+
+// ArtifactGate is a built-in, advisory gate that warns when staged files include
+// gatekeeper state or common generated junk. It runs on the host and needs no container.
+type ArtifactGate struct {
+	files []string
+}
+
+// NewArtifactGate creates an ArtifactGate checking the given staged files.
+func NewArtifactGate(stagedFiles []string) *ArtifactGate {
+	return &ArtifactGate{files: stagedFiles}
+}
+
+// Execute reports each staged artifact as a warning. The gate never blocks.
+func (g *ArtifactGate) Execute(_ context.Context) (*formatter.GateResult, error) {
+	start := time.Now()
+	result := &formatter.GateResult{Name: ArtifactGateName, Type: "builtin"}
+
+	for _, f := range g.files {
+		a, ok := matchArtifact(f)
+		if !ok {
+			continue
+		}
+		result.Errors = append(result.Errors, parser.StructuredError{
+			File:     f,
+			Severity: "warning",
+			Message:  fmt.Sprintf("staged file looks like %s and should not be committed", a.what),
+			Hint:     fmt.Sprintf("Unstage it with 'git rm --cached %s' and add %q to .gitignore (gatekeeper init does this).", f, a.pattern),
+			Tool:     ArtifactGateName,
+		})
+	}
+
+	result.Passed = len(result.Errors) == 0
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// matchArtifact returns the artifact a slash-separated repository path matches.
+func matchArtifact(file string) (artifact, bool) {
+	for _, a := range artifacts {
+		if strings.HasSuffix(a.pattern, "/") {
+			if strings.HasPrefix(file, a.pattern) {
+				return a, true
+			}
+			continue
+		}
+		if strings.Contains(a.pattern, "/") {
+			if file == a.pattern {
+				return a, true
+			}
+			continue
+		}
+		if path.Base(file) == a.pattern {
+			return a, true
+		}
+	}
+	return artifact{}, false
+}
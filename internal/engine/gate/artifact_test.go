@@ -0,0 +1,52 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestArtifactGate_WarnsOnStagedArtifacts(t *testing.T) {
+	g := NewArtifactGate([]string{
+		"main.go",
+		".gatekeeper/gates.yaml",
+		".gatekeeper/results/last.json",
+		".gatekeeper/audit.log",
+		"services/audit.log",
+		"pkg/api/coverage.out",
+		"report.sarif",
+		"docs/report.sarif.md",
+	})
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed || result.Blocking {
+		t.Errorf("expected an advisory failure, got passed=%v blocking=%v", result.Passed, result.Blocking)
+	}
+
+	var files []string
+	for _, e := range result.Errors {
+		files = append(files, e.File)
+		if e.Severity != "warning" {
+			t.Errorf("expected warning severity, got %q", e.Severity)
+		}
+	}
+	if got := strings.Join(files, ","); got != ".gatekeeper/results/last.json,.gatekeeper/audit.log,pkg/api/coverage.out,report.sarif" {
+		t.Errorf("unexpected flagged files: %s", got)
+	}
+	if !strings.Contains(result.Errors[0].Hint, "git rm --cached .gatekeeper/results/last.json") {
+		t.Errorf("expected unstage hint, got %q", result.Errors[0].Hint)
+	}
+}
+
+func TestArtifactGate_PassesOnCleanChanges(t *testing.T) {
+	result, err := NewArtifactGate([]string{"main.go", ".gatekeeper/suppressions.json"}).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed || len(result.Errors) != 0 {
+		t.Errorf("expected pass, got %+v", result)
+	}
+}
@@ -2,17 +2,25 @@ package gate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 	"github.com/irahardianto/gatekeeper/internal/engine/pool"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
 )
 
+// AgainstEnv is the environment variable that holds a gate's against ref, the
+// baseline its breaking-change check compares with.
+const AgainstEnv = "GATEKEEPER_AGAINST"
+
 // PoolManager abstracts container pool operations for testability.
 type PoolManager interface {
 	GetOrCreate(ctx context.Context, img, projectPath string, writable bool) (string, error)
@@ -23,6 +31,38 @@ type CommandExecutor interface {
 	Run(ctx context.Context, containerID, command string, timeout time.Duration) (*pool.ExecResult, error)
 }
 
+// StdinExecutor is a CommandExecutor that can also stream input to a
+// command's stdin, for gates with stdin.
+type StdinExecutor interface {
+	RunWithStdin(ctx context.Context, containerID, command string, stdin []byte, timeout time.Duration) (*pool.ExecResult, error)
+}
+
+// SpecPoolManager is a PoolManager that can also start containers with a
+// setup command, for gates with setup.
+type SpecPoolManager interface {
+	GetOrCreateSpec(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error)
+}
+
+// EphemeralPoolManager is a PoolManager that can also start a fresh container
+// for a single run, for gates with reuse: ephemeral.
+type EphemeralPoolManager interface {
+	CreateEphemeral(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error)
+	RemoveEphemeral(ctx context.Context, containerID string) error
+}
+
+// ReleasingPoolManager is a PoolManager whose reused containers must be
+// released after each run, such as the Kubernetes runtime, which keeps a slot
+// per GetOrCreate call.
+type ReleasingPoolManager interface {
+	Release(ctx context.Context, containerID string) error
+}
+
+// LocalRunner runs gates on the host: its "containers" are workspace directories.
+type LocalRunner interface {
+	PoolManager
+	CommandExecutor
+}
+
 // ContainerGate executes a command or script inside a Docker container and parses the output.
 // It handles both "exec" gates (direct command execution) and "script" gates (shell script execution).
 type ContainerGate struct {
@@ -31,6 +71,10 @@ type ContainerGate struct {
 	executor CommandExecutor
 	parser   parser.Parser
 	project  string
+	// gitSvc reads the staged changes streamed to gates with stdin.
+	gitSvc git.Service
+	// root is the workspace path the command sees; empty means /workspace.
+	root string
 }
 
 // NewContainerGate creates a new ContainerGate.
@@ -44,6 +88,20 @@ func NewContainerGate(cfg config.Gate, p PoolManager, exec CommandExecutor, prs
 	}
 }
 
+// WithRoot sets the path of the workspace as the command sees it, for gates
+// that run on the host instead of with the workspace mounted at /workspace.
+func (g *ContainerGate) WithRoot(root string) *ContainerGate {
+	g.root = root
+	return g
+}
+
+// WithGit sets the git service that reads the staged diff or files streamed
+// to the command of a gate with stdin.
+func (g *ContainerGate) WithGit(svc git.Service) *ContainerGate {
+	g.gitSvc = svc
+	return g
+}
+
 // Execute runs the command or script in a container, parses the output, and returns the result.
 func (g *ContainerGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
 	log := logger.FromContext(ctx)
@@ -51,29 +109,42 @@ func (g *ContainerGate) Execute(ctx context.Context) (*formatter.GateResult, err
 	start := time.Now()
 
 	result := &formatter.GateResult{
-		Name:     g.cfg.Name,
-		Type:     string(g.cfg.Type),
-		Blocking: g.cfg.IsBlocking(),
+		Name:        g.cfg.Name,
+		Type:        string(g.cfg.Type),
+		Blocking:    g.cfg.IsBlocking(),
+		WarnOnError: g.cfg.GetOnError() == config.OnErrorWarn,
 	}
 
+	timings := &formatter.PhaseTimings{}
+	result.Timings = timings
+
 	// 1. Get or create container
-	containerID, err := g.pool.GetOrCreate(ctx, g.cfg.Container, g.project, g.cfg.Writable)
+	phase := time.Now()
+	var pull time.Duration
+	containerID, release, err := g.acquire(pool.WithPullTime(ctx, &pull))
+	timings.ImagePullMs = pull.Milliseconds()
+	timings.AcquireMs = (time.Since(phase) - pull).Milliseconds()
 	if err != nil {
 		result.SystemError = fmt.Sprintf("container setup failed: %v", err)
 		result.DurationMs = time.Since(start).Milliseconds()
 		return result, nil
 	}
+	defer release()
 
-	// 2. Build command based on gate type
-	command := g.buildCommand()
-
-	// 3. Execute command
+	// 2. Execute command
 	timeout := g.cfg.Timeout
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
-	execResult, err := g.executor.Run(ctx, containerID, command, timeout)
+	phase = time.Now()
+	execResult, err := g.runCommand(ctx, containerID, timeout)
+	timings.ExecMs = time.Since(phase).Milliseconds()
+	if errors.Is(err, pool.ErrKilled) {
+		result.SystemError = fmt.Sprintf("killed after timeout (%s)", timeout)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
 	if err != nil {
 		result.SystemError = fmt.Sprintf("execution failed: %v", err)
 		result.DurationMs = time.Since(start).Milliseconds()
@@ -82,8 +153,10 @@ func (g *ContainerGate) Execute(ctx context.Context) (*formatter.GateResult, err
 
 	result.RawOutput = string(execResult.Stdout)
 
-	// 4. Parse output
+	// 3. Parse output
+	phase = time.Now()
 	parsed, err := g.parser.Parse(ctx, execResult.Stdout, execResult.Stderr, execResult.ExitCode)
+	timings.ParseMs = time.Since(phase).Milliseconds()
 	if err != nil {
 		result.SystemError = fmt.Sprintf("parser error: %v", err)
 		result.DurationMs = time.Since(start).Milliseconds()
@@ -92,26 +165,167 @@ func (g *ContainerGate) Execute(ctx context.Context) (*formatter.GateResult, err
 
 	result.Passed = parsed.Passed
 	result.Errors = parsed.Errors
+	g.rootRelative(result.Errors)
 
-	// 5. Enrich hints
-	parser.EnrichHints(result.Errors)
+	// 4. Enrich hints
+	if _, err := parser.EnrichHints(result.Errors); err != nil {
+		log.Warn("fix hints unavailable", "gate", g.cfg.Name, "error", err)
+	}
 
 	result.DurationMs = time.Since(start).Milliseconds()
 	log.Info("ContainerGate.Execute completed", "gate", g.cfg.Name, "passed", result.Passed, "duration_ms", result.DurationMs)
 	return result, nil
 }
 
+// acquire returns the container to run in, started with the gate's setup
+// command, and a function that releases it after the run. Warm containers stay
+// in the pool, unless it is a ReleasingPoolManager; ephemeral ones are removed.
+func (g *ContainerGate) acquire(ctx context.Context) (string, func(), error) {
+	spec := pool.ContainerSpec{Image: g.cfg.Container, Setup: g.cfg.Setup}
+	if g.cfg.Reuse == config.ReuseEphemeral {
+		ep, ok := g.pool.(EphemeralPoolManager)
+		if !ok {
+			return "", nil, fmt.Errorf("ephemeral containers are not supported by this runtime")
+		}
+		id, err := ep.CreateEphemeral(ctx, spec, g.project, g.cfg.Writable)
+		if err != nil {
+			return "", nil, err
+		}
+		return id, func() {
+			// Remove the container even if the run was cancelled.
+			if err := ep.RemoveEphemeral(context.WithoutCancel(ctx), id); err != nil {
+				logger.FromContext(ctx).Warn("failed to remove ephemeral container", "gate", g.cfg.Name, "container_id", id, "error", err)
+			}
+		}, nil
+	}
+
+	var id string
+	var err error
+	if g.cfg.Setup == "" {
+		id, err = g.pool.GetOrCreate(ctx, g.cfg.Container, g.project, g.cfg.Writable)
+	} else {
+		sp, ok := g.pool.(SpecPoolManager)
+		if !ok {
+			return "", nil, fmt.Errorf("setup is not supported by this runtime")
+		}
+		id, err = sp.GetOrCreateSpec(ctx, spec, g.project, g.cfg.Writable)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	rp, ok := g.pool.(ReleasingPoolManager)
+	if !ok {
+		return id, func() {}, nil
+	}
+	return id, func() {
+		if err := rp.Release(context.WithoutCancel(ctx), id); err != nil {
+			logger.FromContext(ctx).Warn("failed to release container", "gate", g.cfg.Name, "container_id", id, "error", err)
+		}
+	}, nil
+}
+
+// runCommand runs the gate's command in the container, with its stdin input
+// if it has one.
+func (g *ContainerGate) runCommand(ctx context.Context, containerID string, timeout time.Duration) (*pool.ExecResult, error) {
+	command := g.buildCommand()
+	if g.cfg.Stdin == "" || g.cfg.Stdin == config.StdinNone {
+		return g.executor.Run(ctx, containerID, command, timeout)
+	}
+	se, ok := g.executor.(StdinExecutor)
+	if !ok {
+		return nil, fmt.Errorf("stdin is not supported by this runtime")
+	}
+	input, err := g.stdin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return se.RunWithStdin(ctx, containerID, command, input, timeout)
+}
+
+// stdin returns the input of a gate with stdin: the staged diff of the files
+// the gate checks, or their paths relative to the gate's directory, one per
+// line. Deleted files and submodule commits are not in the list of paths.
+func (g *ContainerGate) stdin(ctx context.Context) ([]byte, error) {
+	if g.gitSvc == nil {
+		return nil, fmt.Errorf("stdin needs a git repository")
+	}
+	diffs, err := g.gitSvc.StagedDiff(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading staged diff for stdin: %w", err)
+	}
+	// Non-nil, so the command reads an empty input rather than none.
+	input := []byte{}
+	for _, d := range diffs {
+		file, ok := inScope(g.cfg, d.Path)
+		if !ok {
+			continue
+		}
+		switch {
+		case g.cfg.Stdin == config.StdinDiff:
+			input = append(append(input, d.Content...), '\n')
+		case d.Change != git.ChangeDeleted && !d.Submodule:
+			input = append(append(input, file...), '\n')
+		}
+	}
+	return input, nil
+}
+
 // shellQuote wraps a string in single quotes with proper escaping.
 // Single quotes within the string are escaped as '\” (end quote, escaped quote, start quote).
 func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
+// rootRelative rewrites finding paths to be relative to the project root:
+// absolute paths within the workspace, which some tools report, and paths of
+// a gate that runs in a subdirectory, which are relative to it.
+func (g *ContainerGate) rootRelative(errs []parser.StructuredError) {
+	workspace := "/workspace/"
+	if g.root != "" {
+		workspace = strings.TrimSuffix(filepath.ToSlash(g.root), "/") + "/"
+	}
+	dir := g.cfg.ExecDir()
+	for i := range errs {
+		f := errs[i].File
+		if rel, ok := strings.CutPrefix(f, workspace); ok {
+			errs[i].File = rel
+		} else if f != "" && dir != "" && !path.IsAbs(f) {
+			errs[i].File = path.Join(dir, f)
+		}
+	}
+}
+
 // buildCommand constructs the command string based on gate type.
 // For "exec" gates, uses cfg.Command directly.
 // For "script" gates, constructs a shell invocation of cfg.Path.
 // The project root is mounted at /workspace, so scripts are accessible at /workspace/<path>.
+// Gates with a workdir or from a nested config run in /workspace/<dir>.
+// Gates with against see it as $GATEKEEPER_AGAINST.
 func (g *ContainerGate) buildCommand() string {
+	command := g.command()
+	if g.cfg.Against != "" {
+		command = "export " + AgainstEnv + "=" + shellQuote(g.cfg.Against) + " && " + command
+	}
+	return g.inDir(command)
+}
+
+// inDir prefixes command with a change to the gate's working directory, if it
+// has one.
+func (g *ContainerGate) inDir(command string) string {
+	dir := g.cfg.ExecDir()
+	switch {
+	case dir == "":
+		return command
+	case g.root != "":
+		return "cd " + shellQuote(filepath.Join(g.root, filepath.FromSlash(dir))) + " && " + command
+	default:
+		return "cd " + shellQuote(path.Join("/workspace", dir)) + " && " + command
+	}
+}
+
+// command returns the gate's command, relative to its working directory.
+func (g *ContainerGate) command() string {
 	switch g.cfg.Type {
 	case config.GateTypeScript:
 		// Security: Properly shell-quote the path to prevent injection.
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 	"github.com/irahardianto/gatekeeper/internal/engine/pool"
 )
@@ -50,6 +51,12 @@ func TestContainerGate_ExecSuccess(t *testing.T) {
 	if result.DurationMs < 0 {
 		t.Errorf("expected non-negative duration, got %d", result.DurationMs)
 	}
+	if result.Timings == nil {
+		t.Fatal("expected phase timings for a container gate")
+	}
+	if phases := result.Timings.AcquireMs + result.Timings.ExecMs + result.Timings.ParseMs; phases > result.DurationMs {
+		t.Errorf("phases (%dms) exceed gate duration (%dms)", phases, result.DurationMs)
+	}
 }
 
 // TestContainerGate_ScriptSuccess verifies successful execution of a script gate.
@@ -115,6 +122,135 @@ func TestContainerGate_ContainerSetupFailure(t *testing.T) {
 	}
 }
 
+// specPool is a MockPool that also starts containers from a spec.
+type specPool struct {
+	pool.MockPool
+	spec pool.ContainerSpec
+}
+
+func (s *specPool) GetOrCreateSpec(_ context.Context, spec pool.ContainerSpec, _ string, _ bool) (string, error) {
+	s.spec = spec
+	return "setup-container", nil
+}
+
+// TestContainerGate_Setup verifies that gates with setup start their container from a spec.
+func TestContainerGate_Setup(t *testing.T) {
+	cfg := config.Gate{
+		Name:      "test",
+		Type:      config.GateTypeExec,
+		Command:   "go test ./...",
+		Container: "golang:1.25",
+		Setup:     "go mod download",
+	}
+	executor := &pool.MockExecutor{Result: &pool.ExecResult{ExitCode: 0}}
+	prs := &parser.MockParser{Result: &parser.ParseResult{Passed: true}}
+
+	sp := &specPool{}
+	result, err := NewContainerGate(cfg, sp, executor, prs, "/project").Execute(context.Background())
+	if err != nil || !result.Passed {
+		t.Fatalf("Execute = %+v, %v", result, err)
+	}
+	if sp.spec.Image != "golang:1.25" || sp.spec.Setup != "go mod download" {
+		t.Errorf("unexpected spec %+v", sp.spec)
+	}
+
+	result, _ = NewContainerGate(cfg, &pool.MockPool{}, executor, prs, "/project").Execute(context.Background())
+	if !contains(result.SystemError, "setup is not supported") {
+		t.Errorf("expected an unsupported setup error, got %q", result.SystemError)
+	}
+}
+
+// releasingPool is a MockPool that records the containers released to it.
+type releasingPool struct {
+	pool.MockPool
+	released []string
+}
+
+func (r *releasingPool) Release(_ context.Context, id string) error {
+	r.released = append(r.released, id)
+	return nil
+}
+
+// TestContainerGate_Release verifies that reused containers are released
+// after the run by pools that need it.
+func TestContainerGate_Release(t *testing.T) {
+	cfg := config.Gate{Name: "lint", Type: config.GateTypeExec, Command: "go vet ./...", Container: "golang:1.25"}
+	prs := &parser.MockParser{Result: &parser.ParseResult{Passed: true}}
+
+	rp := &releasingPool{MockPool: pool.MockPool{ContainerID: "slot-1"}}
+	executor := &pool.MockExecutor{Err: errors.New("exec failed")}
+	if _, err := NewContainerGate(cfg, rp, executor, prs, "/project").Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rp.released) != 1 || rp.released[0] != "slot-1" {
+		t.Errorf("expected slot-1 to be released after a failed run, got %v", rp.released)
+	}
+}
+
+// ephemeralPool records the ephemeral containers it creates and removes.
+type ephemeralPool struct {
+	pool.MockPool
+	created []pool.ContainerSpec
+	removed []string
+}
+
+func (e *ephemeralPool) CreateEphemeral(_ context.Context, spec pool.ContainerSpec, _ string, _ bool) (string, error) {
+	e.created = append(e.created, spec)
+	return "fresh-container", nil
+}
+
+func (e *ephemeralPool) RemoveEphemeral(_ context.Context, id string) error {
+	e.removed = append(e.removed, id)
+	return nil
+}
+
+// TestContainerGate_Ephemeral verifies that ephemeral gates run in a fresh
+// container that is removed afterwards, even when the run fails.
+func TestContainerGate_Ephemeral(t *testing.T) {
+	cfg := config.Gate{
+		Name:      "scan",
+		Type:      config.GateTypeExec,
+		Command:   "trivy fs .",
+		Container: "aquasec/trivy",
+		Reuse:     config.ReuseEphemeral,
+	}
+	prs := &parser.MockParser{Result: &parser.ParseResult{Passed: true}}
+
+	ep := &ephemeralPool{MockPool: pool.MockPool{ContainerID: "warm-container"}}
+	executor := &pool.MockExecutor{Err: errors.New("exec failed")}
+	result, err := NewContainerGate(cfg, ep, executor, prs, "/project").Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SystemError == "" {
+		t.Error("expected the exec error")
+	}
+	if len(ep.created) != 1 || ep.created[0].Image != "aquasec/trivy" {
+		t.Errorf("unexpected ephemeral containers %+v", ep.created)
+	}
+	if len(ep.removed) != 1 || ep.removed[0] != "fresh-container" {
+		t.Errorf("expected the fresh container to be removed, got %v", ep.removed)
+	}
+
+	result, _ = NewContainerGate(cfg, &pool.MockPool{}, executor, prs, "/project").Execute(context.Background())
+	if !contains(result.SystemError, "ephemeral containers are not supported") {
+		t.Errorf("expected an unsupported reuse error, got %q", result.SystemError)
+	}
+}
+
+// TestContainerGate_Killed verifies that a command killed after its timeout is reported as such.
+func TestContainerGate_Killed(t *testing.T) {
+	cfg := config.Gate{Name: "test", Type: config.GateTypeExec, Command: "npm test", Timeout: 5 * time.Second}
+	executor := &pool.MockExecutor{Err: pool.ErrKilled}
+	result, err := NewContainerGate(cfg, &pool.MockPool{ContainerID: "c"}, executor, nil, "/project").Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SystemError != "killed after timeout (5s)" {
+		t.Errorf("SystemError = %q", result.SystemError)
+	}
+}
+
 // TestContainerGate_ExecutionFailure verifies error handling when command execution fails.
 func TestContainerGate_ExecutionFailure(t *testing.T) {
 	mockPool := &pool.MockPool{
@@ -145,6 +281,9 @@ func TestContainerGate_ExecutionFailure(t *testing.T) {
 	if !contains(result.SystemError, "execution failed") {
 		t.Errorf("expected 'execution failed' in error, got %q", result.SystemError)
 	}
+	if result.Timings == nil || result.Timings.ParseMs != 0 {
+		t.Errorf("expected timings without a parse phase, got %+v", result.Timings)
+	}
 }
 
 // TestContainerGate_ParserError verifies error handling when parser fails.
@@ -228,6 +367,9 @@ func TestContainerGate_BuildCommand(t *testing.T) {
 		gateType config.GateType
 		command  string
 		path     string
+		dir      string
+		workdir  string
+		against  string
 		expected string
 	}{
 		{
@@ -254,6 +396,36 @@ func TestContainerGate_BuildCommand(t *testing.T) {
 			path:     "./scripts/my'script.sh",
 			expected: `sh './scripts/my'\''script.sh'`,
 		},
+		{
+			name:     "nested config gate runs in its directory",
+			gateType: config.GateTypeScript,
+			path:     "./check.sh",
+			dir:      "services/api",
+			expected: "cd '/workspace/services/api' && sh './check.sh'",
+		},
+		{
+			name:     "workdir",
+			gateType: config.GateTypeExec,
+			command:  "go test ./...",
+			workdir:  "services/api",
+			expected: "cd '/workspace/services/api' && go test ./...",
+		},
+		{
+			name:     "workdir within nested config",
+			gateType: config.GateTypeExec,
+			command:  "go test ./...",
+			dir:      "services",
+			workdir:  "api",
+			expected: "cd '/workspace/services/api' && go test ./...",
+		},
+		{
+			name:     "against is exported",
+			gateType: config.GateTypeExec,
+			command:  `buf breaking --against ".git#ref=$GATEKEEPER_AGAINST"`,
+			workdir:  "proto",
+			against:  "main",
+			expected: `cd '/workspace/proto' && export GATEKEEPER_AGAINST='main' && buf breaking --against ".git#ref=$GATEKEEPER_AGAINST"`,
+		},
 	}
 
 	for _, tc := range tests {
@@ -263,6 +435,9 @@ func TestContainerGate_BuildCommand(t *testing.T) {
 				Type:    tc.gateType,
 				Command: tc.command,
 				Path:    tc.path,
+				Dir:     tc.dir,
+				Workdir: tc.workdir,
+				Against: tc.against,
 			}
 
 			gate := NewContainerGate(cfg, nil, nil, nil, "/project")
@@ -301,6 +476,82 @@ func TestContainerGate_BlockingFlag(t *testing.T) {
 	}
 }
 
+// TestContainerGate_NestedFindingPaths verifies findings of nested config gates are reported from the project root.
+func TestContainerGate_NestedFindingPaths(t *testing.T) {
+	mockPool := &pool.MockPool{ContainerID: "test"}
+	mockExecutor := &pool.MockExecutor{
+		Result: &pool.ExecResult{ExitCode: 1},
+	}
+	mockParser := &parser.MockParser{
+		Result: &parser.ParseResult{Errors: []parser.StructuredError{
+			{File: "internal/db.go", Line: 3},
+			{File: "/usr/lib/go/src/fmt.go"},
+			{Message: "no file"},
+			{File: "/workspace/services/api/openapi.yaml"},
+		}},
+	}
+	cfg := config.Gate{Name: "services/api:vet", Type: config.GateTypeExec, Command: "go vet ./...", Dir: "services/api"}
+
+	result, _ := NewContainerGate(cfg, mockPool, mockExecutor, mockParser, "/project").Execute(context.Background())
+
+	files := []string{result.Errors[0].File, result.Errors[1].File, result.Errors[2].File, result.Errors[3].File}
+	want := []string{"services/api/internal/db.go", "/usr/lib/go/src/fmt.go", "", "services/api/openapi.yaml"}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("finding %d: expected file %q, got %q", i, want[i], files[i])
+		}
+	}
+}
+
+// runOnly is a CommandExecutor without stdin support.
+type runOnly struct{ CommandExecutor }
+
+// TestContainerGate_Stdin verifies that gates with stdin get the staged diff
+// or the paths of the staged files they check.
+func TestContainerGate_Stdin(t *testing.T) {
+	svc := &git.MockService{Diffs: []git.FileDiff{
+		{Path: "services/api/main.go", Content: "diff --git a/services/api/main.go b/services/api/main.go", Change: git.ChangeModified},
+		{Path: "services/api/README.md", Content: "diff --git a/services/api/README.md b/services/api/README.md", Change: git.ChangeModified},
+		{Path: "services/api/old.go", Content: "diff --git a/services/api/old.go b/services/api/old.go", Change: git.ChangeDeleted},
+		{Path: "web/app.go", Content: "diff --git a/web/app.go b/web/app.go", Change: git.ChangeAdded},
+	}}
+	prs := &parser.MockParser{Result: &parser.ParseResult{Passed: true}}
+	cfg := config.Gate{Name: "fmt", Type: config.GateTypeExec, Command: "xargs gofmt -l", Dir: "services/api", Only: []string{"*.go"}}
+
+	tests := []struct {
+		stdin string
+		want  string
+	}{
+		{config.StdinStagedFiles, "main.go\n"},
+		{config.StdinDiff, "diff --git a/services/api/main.go b/services/api/main.go\ndiff --git a/services/api/old.go b/services/api/old.go\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.stdin, func(t *testing.T) {
+			cfg.Stdin = tc.stdin
+			executor := &pool.MockExecutor{Result: &pool.ExecResult{}}
+			result, _ := NewContainerGate(cfg, &pool.MockPool{ContainerID: "c"}, executor, prs, "/project").WithGit(svc).Execute(context.Background())
+			if !result.Passed {
+				t.Fatalf("expected the gate to pass, got %+v", result)
+			}
+			if string(executor.LastStdin) != tc.want {
+				t.Errorf("stdin = %q, want %q", executor.LastStdin, tc.want)
+			}
+		})
+	}
+
+	executor := &pool.MockExecutor{Result: &pool.ExecResult{}}
+	result, _ := NewContainerGate(cfg, &pool.MockPool{ContainerID: "c"}, runOnly{executor}, prs, "/project").WithGit(svc).Execute(context.Background())
+	if !contains(result.SystemError, "stdin is not supported") {
+		t.Errorf("expected an unsupported stdin error, got %q", result.SystemError)
+	}
+
+	cfg.Stdin = config.StdinNone
+	result, _ = NewContainerGate(cfg, &pool.MockPool{ContainerID: "c"}, executor, prs, "/project").WithGit(svc).Execute(context.Background())
+	if !result.Passed || executor.LastStdin != nil {
+		t.Errorf("expected no stdin, got %q (%+v)", executor.LastStdin, result)
+	}
+}
+
 // Helper function to check if a string contains a substring.
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && containsHelper(s, substr))
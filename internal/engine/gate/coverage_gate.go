@@ -0,0 +1,220 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/coverage"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// reportTimeout bounds reading the coverage report after the tests ran.
+const reportTimeout = 30 * time.Second
+
+// CoverageBaselines stores the coverage of the changed files at the last
+// passing run of each coverage gate.
+type CoverageBaselines interface {
+	LoadCoverageBaseline(gate string) (coverage.Baseline, error)
+	SaveCoverageBaseline(gate string, b coverage.Baseline) error
+}
+
+// CoverageGate runs a test command with coverage enabled, in a container like
+// an exec gate, and checks the coverage of the staged changes: uncovered added
+// lines are reported as warnings, and the gate fails if the changed files are
+// covered less than min_coverage or their coverage fell more than max_drop
+// below the baseline.
+type CoverageGate struct {
+	run       *ContainerGate
+	gitSvc    git.Service
+	baselines CoverageBaselines
+}
+
+// NewCoverageGate creates a CoverageGate running its command with run.
+// baselines may be nil, in which case coverage is not compared between runs.
+func NewCoverageGate(run *ContainerGate, gitSvc git.Service, baselines CoverageBaselines) *CoverageGate {
+	return &CoverageGate{run: run, gitSvc: gitSvc, baselines: baselines}
+}
+
+// Execute runs the tests, reads the coverage report, and checks the coverage
+// of the changed files.
+func (g *CoverageGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
+	cfg := g.run.cfg
+	log := logger.FromContext(ctx)
+	log.Info("CoverageGate.Execute started", "gate", cfg.Name)
+	start := time.Now()
+
+	result := &formatter.GateResult{
+		Name:        cfg.Name,
+		Type:        string(cfg.Type),
+		Blocking:    cfg.IsBlocking(),
+		WarnOnError: cfg.GetOnError() == config.OnErrorWarn,
+	}
+	timings := &formatter.PhaseTimings{}
+	result.Timings = timings
+	fail := func(format string, args ...any) (*formatter.GateResult, error) {
+		result.SystemError = fmt.Sprintf(format, args...)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+
+	// 1. Find the changed files in the gate's scope.
+	diffs, err := g.gitSvc.StagedDiff(ctx)
+	if err != nil {
+		return fail("failed to get staged diffs: %v", err)
+	}
+	diffs, _ = ReviewDiffs(cfg, diffs)
+
+	// 2. Run the tests.
+	phase := time.Now()
+	var pull time.Duration
+	containerID, release, err := g.run.acquire(pool.WithPullTime(ctx, &pull))
+	timings.ImagePullMs = pull.Milliseconds()
+	timings.AcquireMs = (time.Since(phase) - pull).Milliseconds()
+	if err != nil {
+		return fail("container setup failed: %v", err)
+	}
+	defer release()
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	phase = time.Now()
+	execResult, err := g.run.runCommand(ctx, containerID, timeout)
+	timings.ExecMs = time.Since(phase).Milliseconds()
+	if errors.Is(err, pool.ErrKilled) {
+		return fail("killed after timeout (%s)", timeout)
+	}
+	if err != nil {
+		return fail("execution failed: %v", err)
+	}
+	result.RawOutput = string(execResult.Stdout)
+	if execResult.ExitCode != 0 {
+		result.Errors = []parser.StructuredError{{
+			Severity: "error",
+			Message:  fmt.Sprintf("test command exited with status %d", execResult.ExitCode),
+			Hint:     "Fix the failing tests; coverage is checked once they pass.",
+			Tool:     cfg.Name,
+		}}
+		result.DurationMs = time.Since(start).Milliseconds()
+		log.Info("CoverageGate.Execute completed — tests failed", "gate", cfg.Name, "exit_code", execResult.ExitCode)
+		return result, nil
+	}
+
+	// 3. Read the report the tests wrote.
+	phase = time.Now()
+	report, err := g.run.executor.Run(ctx, containerID, g.run.inDir("cat "+shellQuote(cfg.Coverage.Report)), reportTimeout)
+	if err == nil && report.ExitCode != 0 {
+		err = errors.New(strings.TrimSpace(string(report.Stderr)))
+	}
+	if err != nil {
+		return fail("reading coverage report %s: %v", cfg.Coverage.Report, err)
+	}
+	profile, err := coverage.Parse(cfg.Coverage.Format, report.Stdout)
+	timings.ParseMs = time.Since(phase).Milliseconds()
+	if err != nil {
+		return fail("coverage report %s: %v", cfg.Coverage.Report, err)
+	}
+
+	// 4. Check the changed files against the thresholds and the baseline.
+	var baseline coverage.Baseline
+	if g.baselines != nil {
+		if baseline, err = g.baselines.LoadCoverageBaseline(cfg.Name); err != nil {
+			log.Warn("CoverageGate.Execute could not read the coverage baseline", "gate", cfg.Name, "error", err)
+		}
+	}
+	findings, current := checkCoverage(cfg, profile, diffs, baseline)
+	result.Errors = findings
+	result.Passed = !hasErrors(findings)
+
+	if result.Passed && g.baselines != nil && len(current) > 0 {
+		updated := maps.Clone(baseline)
+		if updated == nil {
+			updated = coverage.Baseline{}
+		}
+		maps.Copy(updated, current)
+		if err := g.baselines.SaveCoverageBaseline(cfg.Name, updated); err != nil {
+			log.Warn("CoverageGate.Execute could not save the coverage baseline", "gate", cfg.Name, "error", err)
+		}
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	log.Info("CoverageGate.Execute completed", "gate", cfg.Name, "passed", result.Passed, "files", len(current), "duration_ms", result.DurationMs)
+	return result, nil
+}
+
+// checkCoverage checks the coverage of the files changed by diffs, as measured
+// in profile, against the gate's thresholds and baseline. It returns the
+// findings and the coverage of each changed file the report measured.
+func checkCoverage(cfg config.Gate, profile coverage.Profile, diffs []git.FileDiff, baseline coverage.Baseline) ([]parser.StructuredError, coverage.Baseline) {
+	var findings []parser.StructuredError
+	current := coverage.Baseline{}
+	var total coverage.Summary
+	for _, d := range diffs {
+		if git.IsDeletion(d) {
+			continue
+		}
+		lines, ok := profile.Lookup(reportPath(cfg, d.Path))
+		if !ok {
+			// Not measured: tests, docs, or code of another language.
+			continue
+		}
+		summary := coverage.Summarize(lines)
+		current[d.Path] = summary
+		total = total.Add(summary)
+
+		for _, r := range coverage.Uncovered(lines, git.AddedLines(d)) {
+			msg := fmt.Sprintf("line %d is not covered by tests", r[0])
+			if r[1] > r[0] {
+				msg = fmt.Sprintf("lines %d-%d are not covered by tests", r[0], r[1])
+			}
+			findings = append(findings, parser.StructuredError{File: d.Path, Line: r[0], Severity: "warning", Rule: "uncovered", Message: msg, Tool: cfg.Name})
+		}
+
+		if base, ok := baseline[d.Path]; ok && base.Percent()-summary.Percent() > cfg.Coverage.MaxDrop+1e-9 {
+			findings = append(findings, parser.StructuredError{
+				File:     d.Path,
+				Severity: "error",
+				Rule:     "coverage-drop",
+				Message:  fmt.Sprintf("coverage fell from %.1f%% to %.1f%% (max_drop %g)", base.Percent(), summary.Percent(), cfg.Coverage.MaxDrop),
+				Hint:     "Add tests for the changed code, or raise coverage.max_drop.",
+				Tool:     cfg.Name,
+			})
+		}
+	}
+
+	if minimum := cfg.Coverage.MinCoverage; minimum > 0 && total.Total > 0 && total.Percent() < minimum {
+		findings = append(findings, parser.StructuredError{
+			Severity: "error",
+			Rule:     "min-coverage",
+			Message:  fmt.Sprintf("changed files are %.1f%% covered, below min_coverage %g%%", total.Percent(), minimum),
+			Hint:     "Add tests for the uncovered lines reported for this gate.",
+			Tool:     cfg.Name,
+		})
+	}
+	return findings, current
+}
+
+// reportPath returns the path of a changed file as a coverage report of the
+// gate names it: relative to the gate's working directory, in which the tests
+// ran. Lookup matches report paths by suffix, so a shorter path still matches.
+func reportPath(cfg config.Gate, file string) string {
+	if dir := cfg.ExecDir(); dir != "" {
+		if rel, ok := strings.CutPrefix(file, dir+"/"); ok {
+			return rel
+		}
+	}
+	return file
+}
+
+// Ensure CoverageGate implements Gate at compile time.
+var _ Gate = (*CoverageGate)(nil)
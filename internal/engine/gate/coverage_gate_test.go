@@ -0,0 +1,168 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/coverage"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// commandExecutor returns a result per command, and exit status 1 for others.
+type commandExecutor struct {
+	results  map[string]*pool.ExecResult
+	commands []string
+}
+
+func (e *commandExecutor) Run(_ context.Context, _, command string, _ time.Duration) (*pool.ExecResult, error) {
+	e.commands = append(e.commands, command)
+	if r, ok := e.results[command]; ok {
+		return r, nil
+	}
+	return &pool.ExecResult{ExitCode: 1, Stderr: []byte("no such file")}, nil
+}
+
+// memoryBaselines keeps coverage baselines in memory.
+type memoryBaselines map[string]coverage.Baseline
+
+func (m memoryBaselines) LoadCoverageBaseline(gate string) (coverage.Baseline, error) {
+	return m[gate], nil
+}
+
+func (m memoryBaselines) SaveCoverageBaseline(gate string, b coverage.Baseline) error {
+	m[gate] = b
+	return nil
+}
+
+// coverageDiff adds lines 3-5 of api.go.
+var coverageDiff = git.FileDiff{Path: "api/api.go", Content: "diff --git a/api/api.go b/api/api.go\n@@ -1,2 +1,5 @@\n package api\n \n+func Load() error {\n+\treturn nil\n+}\n"}
+
+func newTestCoverageGate(cfg config.Gate, profile string, baselines CoverageBaselines) (*CoverageGate, *commandExecutor) {
+	exec := &commandExecutor{results: map[string]*pool.ExecResult{
+		cfg.Command:          {Stdout: []byte("ok  \texample.com/m/api\n")},
+		"cat 'coverage.out'": {Stdout: []byte(profile)},
+	}}
+	run := NewContainerGate(cfg, &pool.MockPool{ContainerID: "c"}, exec, &parser.MockParser{}, "/project")
+	return NewCoverageGate(run, &git.MockService{Diffs: []git.FileDiff{coverageDiff}}, baselines), exec
+}
+
+func coverageGateConfig(c config.CoverageConfig) config.Gate {
+	c.Report = "coverage.out"
+	return config.Gate{Name: "coverage", Type: config.GateTypeCoverage, Command: "go test -coverprofile=coverage.out ./...", Coverage: &c}
+}
+
+func TestCoverageGate_ReportsUncoveredLines(t *testing.T) {
+	// Lines 1-2 and 3-5 are blocks; only the first ran.
+	profile := "mode: set\nexample.com/m/api/api.go:1.1,2.10 1 1\nexample.com/m/api/api.go:3.20,5.2 1 0\n"
+	baselines := memoryBaselines{}
+	g, exec := newTestCoverageGate(coverageGateConfig(config.CoverageConfig{}), profile, baselines)
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SystemError != "" {
+		t.Fatalf("unexpected system error: %s", result.SystemError)
+	}
+	if !result.Passed || len(result.Errors) != 1 {
+		t.Fatalf("expected a passing gate with one warning, got %+v", result)
+	}
+	if e := result.Errors[0]; e.File != "api/api.go" || e.Line != 3 || e.Severity != "warning" || e.Message != "lines 3-5 are not covered by tests" {
+		t.Errorf("unexpected finding: %+v", e)
+	}
+	if len(exec.commands) != 2 {
+		t.Errorf("expected the tests and the report read, got %q", exec.commands)
+	}
+	if got := baselines["coverage"]["api/api.go"]; got != (coverage.Summary{Covered: 2, Total: 5}) {
+		t.Errorf("expected the baseline to be saved, got %+v", baselines)
+	}
+}
+
+func TestCoverageGate_MinCoverage(t *testing.T) {
+	profile := "mode: set\nexample.com/m/api/api.go:1.1,2.10 1 1\nexample.com/m/api/api.go:3.20,5.2 1 0\n"
+	baselines := memoryBaselines{}
+	g, _ := newTestCoverageGate(coverageGateConfig(config.CoverageConfig{MinCoverage: 80}), profile, baselines)
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed {
+		t.Fatal("expected the gate to fail below min_coverage")
+	}
+	last := result.Errors[len(result.Errors)-1]
+	if last.Rule != "min-coverage" || !strings.Contains(last.Message, "40.0% covered, below min_coverage 80%") {
+		t.Errorf("unexpected finding: %+v", last)
+	}
+	if len(baselines) != 0 {
+		t.Errorf("expected no baseline from a failing run, got %+v", baselines)
+	}
+}
+
+func TestCoverageGate_BaselineDrop(t *testing.T) {
+	profile := "mode: set\nexample.com/m/api/api.go:1.1,2.10 1 1\nexample.com/m/api/api.go:3.20,5.2 1 0\n"
+	baselines := memoryBaselines{"coverage": {"api/api.go": {Covered: 9, Total: 10}}}
+	g, _ := newTestCoverageGate(coverageGateConfig(config.CoverageConfig{MaxDrop: 5}), profile, baselines)
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed {
+		t.Fatal("expected the gate to fail when coverage dropped")
+	}
+	var drop *parser.StructuredError
+	for i := range result.Errors {
+		if result.Errors[i].Rule == "coverage-drop" {
+			drop = &result.Errors[i]
+		}
+	}
+	if drop == nil || drop.File != "api/api.go" || !strings.Contains(drop.Message, "from 90.0% to 40.0%") {
+		t.Errorf("expected a coverage drop finding, got %+v", result.Errors)
+	}
+}
+
+func TestCoverageGate_TestsFail(t *testing.T) {
+	g, exec := newTestCoverageGate(coverageGateConfig(config.CoverageConfig{}), "", nil)
+	exec.results[g.run.cfg.Command] = &pool.ExecResult{ExitCode: 1, Stdout: []byte("--- FAIL: TestLoad")}
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "exited with status 1") {
+		t.Errorf("expected a failure for the failing tests, got %+v", result)
+	}
+	if len(exec.commands) != 1 {
+		t.Errorf("expected the report not to be read, got %q", exec.commands)
+	}
+}
+
+func TestCoverageGate_MissingReport(t *testing.T) {
+	g, exec := newTestCoverageGate(coverageGateConfig(config.CoverageConfig{}), "", nil)
+	delete(exec.results, "cat 'coverage.out'")
+
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.SystemError, "reading coverage report coverage.out: no such file") {
+		t.Errorf("expected a system error for the missing report, got %+v", result)
+	}
+}
+
+func TestFactory_CreateCoverageGate(t *testing.T) {
+	f := NewFactory(&pool.MockPool{}, &pool.MockExecutor{}, parser.NewRegistry(), nil, &git.MockService{}, "/project")
+	g, err := f.Create(coverageGateConfig(config.CoverageConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := g.(*CoverageGate); !ok {
+		t.Errorf("expected a CoverageGate, got %T", g)
+	}
+}
@@ -0,0 +1,139 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// MedianLatencyFunc returns the median recorded latency of an LLM provider.
+// ok is false when there is not enough history to judge.
+type MedianLatencyFunc func(provider string) (median time.Duration, ok bool)
+
+// ApplyDeferPolicy wraps the non-blocking LLM gates of a run so they are reported
+// as deferred instead of executed when:
+//   - the provider's median latency is above policy.MaxMedianLatency, or
+//   - the other gates of the run took longer than policy.DeferAfter.
+//
+// With DeferAfter set, deferrable gates wait for the other gates to finish before
+// deciding. gates and cfgs must be parallel slices. The input slice is not modified.
+func ApplyDeferPolicy(gates []Gate, cfgs []config.Gate, policy config.LLMPolicy, median MedianLatencyFunc) []Gate {
+	if policy.DeferAfter <= 0 && policy.MaxMedianLatency <= 0 {
+		return gates
+	}
+
+	deferrable := make([]bool, len(gates))
+	found := false
+	for i, cfg := range cfgs {
+		if cfg.Type == config.GateTypeLLM && !cfg.IsBlocking() {
+			deferrable[i] = true
+			found = true
+		}
+	}
+	if !found {
+		return gates
+	}
+
+	others := &sync.WaitGroup{}
+	start := time.Now()
+	result := make([]Gate, len(gates))
+	for i, g := range gates {
+		if !deferrable[i] {
+			if policy.DeferAfter > 0 {
+				others.Add(1)
+				result[i] = &trackedGate{inner: g, done: others}
+			} else {
+				result[i] = g
+			}
+			continue
+		}
+
+		dg := &deferrableGate{inner: g, cfg: cfgs[i], start: start}
+		if policy.DeferAfter > 0 {
+			dg.after = policy.DeferAfter
+			dg.others = others
+		}
+		if policy.MaxMedianLatency > 0 && median != nil {
+			if m, ok := median(cfgs[i].Provider); ok && m > policy.MaxMedianLatency {
+				dg.reason = fmt.Sprintf("provider %s median latency %s exceeds %s", cfgs[i].Provider, m.Round(time.Millisecond), policy.MaxMedianLatency)
+			}
+		}
+		result[i] = dg
+	}
+	return result
+}
+
+// LastGate is a Gate that waits for the other gates of the run before it
+// decides anything, so a sequential run must start it after them.
+type LastGate interface {
+	Gate
+	RunsLast() bool
+}
+
+// trackedGate signals done when its inner gate finishes.
+type trackedGate struct {
+	inner Gate
+	done  *sync.WaitGroup
+}
+
+// Execute runs the inner gate and marks it as finished.
+func (g *trackedGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
+	defer g.done.Done()
+	return g.inner.Execute(ctx)
+}
+
+// deferrableGate runs a non-blocking LLM gate only if the defer policy allows it.
+type deferrableGate struct {
+	inner  Gate
+	cfg    config.Gate
+	start  time.Time
+	after  time.Duration
+	others *sync.WaitGroup
+	// reason is set up front when the gate is deferred regardless of run time.
+	reason string
+}
+
+// RunsLast reports whether the gate waits for the other gates, to compare
+// their run time with defer_after.
+func (g *deferrableGate) RunsLast() bool {
+	return g.others != nil
+}
+
+// Execute waits for the other gates (if a run-time threshold is set) and then
+// either runs the inner gate or reports it as deferred.
+func (g *deferrableGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
+	if g.reason == "" && g.others != nil {
+		done := make(chan struct{})
+		go func() {
+			g.others.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if elapsed := time.Since(g.start); elapsed > g.after {
+			g.reason = fmt.Sprintf("run already took %s (defer_after %s)", elapsed.Round(time.Millisecond), g.after)
+		}
+	}
+
+	if g.reason != "" {
+		logger.FromContext(ctx).Info("LLM gate deferred", "gate", g.cfg.Name, "reason", g.reason)
+		return &formatter.GateResult{
+			Name:        g.cfg.Name,
+			Type:        string(g.cfg.Type),
+			Passed:      true,
+			Blocking:    false,
+			Skipped:     true,
+			Deferred:    true,
+			DeferReason: g.reason,
+		}, nil
+	}
+	return g.inner.Execute(ctx)
+}
@@ -0,0 +1,124 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+// sleepGate is a Gate that sleeps before passing.
+type sleepGate struct {
+	name  string
+	sleep time.Duration
+	ran   bool
+}
+
+func (g *sleepGate) Execute(_ context.Context) (*formatter.GateResult, error) {
+	time.Sleep(g.sleep)
+	g.ran = true
+	return &formatter.GateResult{Name: g.name, Passed: true}, nil
+}
+
+func deferCfgs() []config.Gate {
+	nonBlocking := false
+	return []config.Gate{
+		{Name: "test", Type: config.GateTypeExec},
+		{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Blocking: &nonBlocking},
+		{Name: "secrets", Type: config.GateTypeLLM, Provider: "gemini"},
+	}
+}
+
+// runAll executes gates concurrently, as the runner does.
+func runAll(t *testing.T, gates []Gate) []*formatter.GateResult {
+	t.Helper()
+	results := make([]*formatter.GateResult, len(gates))
+	done := make(chan struct{})
+	for i, g := range gates {
+		go func(i int, g Gate) {
+			r, err := g.Execute(context.Background())
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = r
+			done <- struct{}{}
+		}(i, g)
+	}
+	for range gates {
+		<-done
+	}
+	return results
+}
+
+func TestApplyDeferPolicy_Disabled(t *testing.T) {
+	gates := []Gate{&sleepGate{}, &sleepGate{}, &sleepGate{}}
+	out := ApplyDeferPolicy(gates, deferCfgs(), config.LLMPolicy{}, nil)
+	for i := range gates {
+		if out[i] != gates[i] {
+			t.Errorf("gate %d should not be wrapped without a policy", i)
+		}
+	}
+}
+
+func TestApplyDeferPolicy_DeferAfter(t *testing.T) {
+	review := &sleepGate{name: "review"}
+	secrets := &sleepGate{name: "secrets"}
+	gates := []Gate{&sleepGate{name: "test", sleep: 30 * time.Millisecond}, review, secrets}
+
+	out := ApplyDeferPolicy(gates, deferCfgs(), config.LLMPolicy{DeferAfter: 10 * time.Millisecond}, nil)
+	results := runAll(t, out)
+
+	if !results[1].Deferred || !results[1].Skipped || !results[1].Passed || review.ran {
+		t.Errorf("expected non-blocking LLM gate to be deferred, got %+v", results[1])
+	}
+	if !strings.Contains(results[1].DeferReason, "defer_after") {
+		t.Errorf("unexpected defer reason %q", results[1].DeferReason)
+	}
+	if results[2].Deferred || !secrets.ran {
+		t.Error("blocking LLM gates must never be deferred")
+	}
+}
+
+func TestApplyDeferPolicy_FastRunNotDeferred(t *testing.T) {
+	review := &sleepGate{name: "review"}
+	gates := []Gate{&sleepGate{name: "test"}, review, &sleepGate{name: "secrets"}}
+
+	out := ApplyDeferPolicy(gates, deferCfgs(), config.LLMPolicy{DeferAfter: time.Minute}, nil)
+	results := runAll(t, out)
+
+	if results[1].Deferred || !review.ran {
+		t.Errorf("expected LLM gate to run, got %+v", results[1])
+	}
+}
+
+func TestApplyDeferPolicy_MedianLatency(t *testing.T) {
+	review := &sleepGate{name: "review"}
+	gates := []Gate{&sleepGate{name: "test"}, review, &sleepGate{name: "secrets"}}
+	median := func(provider string) (time.Duration, bool) {
+		return 40 * time.Second, provider == "gemini"
+	}
+
+	out := ApplyDeferPolicy(gates, deferCfgs(), config.LLMPolicy{MaxMedianLatency: 10 * time.Second}, median)
+	results := runAll(t, out)
+
+	if !results[1].Deferred || review.ran {
+		t.Errorf("expected deferral on slow provider, got %+v", results[1])
+	}
+	if !strings.Contains(results[1].DeferReason, "median latency") {
+		t.Errorf("unexpected defer reason %q", results[1].DeferReason)
+	}
+}
+
+func TestDeferrableGate_CancelledWhileWaiting(t *testing.T) {
+	gates := []Gate{&sleepGate{name: "test", sleep: time.Second}, &sleepGate{name: "review"}, &sleepGate{name: "secrets"}}
+	out := ApplyDeferPolicy(gates, deferCfgs(), config.LLMPolicy{DeferAfter: time.Millisecond}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := out[1].Execute(ctx); err == nil {
+		t.Error("expected context error when cancelled while waiting")
+	}
+}
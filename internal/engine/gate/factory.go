@@ -1,7 +1,11 @@
 package gate
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
@@ -11,14 +15,32 @@ import (
 
 // Factory creates Gate instances from configuration.
 type Factory struct {
-	pool        PoolManager
-	executor    CommandExecutor
-	registry    *parser.Registry
-	llmClient   llm.Client
-	gitService  git.Service
-	projectPath string
+	pool         PoolManager
+	executor     CommandExecutor
+	registry     *parser.Registry
+	llmClient    llm.Client
+	llmProviders LLMProviderFunc
+	gitService   git.Service
+	projectPath  string
+	workspace    string
+	overlay      string
+	hostWritable bool
+	dismissals   *llm.Dismissals
+	local        LocalRunner
+	allLocal     bool
+	nix          LocalRunner
+	noHost       bool
+	promptDir    string
+	devcontainer PoolManager
+	baselines    CoverageBaselines
 }
 
+// LLMProviderFunc returns the client of an LLM provider as configured by a
+// gate, such as "gemini-3-pro" or "ollama/llama3". It returns an error wrapping
+// llm.ErrProviderUnavailable for a provider that is not set up, such as one
+// without an API key.
+type LLMProviderFunc func(provider string) (llm.Client, error)
+
 // NewFactory creates a new Factory with the given dependencies.
 // llmClient may be nil if no LLM gates are configured.
 func NewFactory(
@@ -39,14 +61,91 @@ func NewFactory(
 	}
 }
 
+// SetLLMProviders sets how the clients of LLM gates are built: each gate gets
+// a chain of its provider and fallback_providers. Without it, every LLM gate
+// uses the client given to NewFactory.
+func (f *Factory) SetLLMProviders(fn LLMProviderFunc) {
+	f.llmProviders = fn
+}
+
+// SetDismissals sets the dismissed findings dropped by LLM gates.
+func (f *Factory) SetDismissals(d *llm.Dismissals) {
+	f.dismissals = d
+}
+
+// SetWorkspace sets the directory mounted into containers at /workspace in place
+// of the project directory, e.g. a snapshot of the staged index.
+func (f *Factory) SetWorkspace(dir string) {
+	f.workspace = dir
+}
+
+// SetWritableWorkspace sets the directory mounted into writable container
+// gates, e.g. a writable overlay of the staged index, so that they do not
+// modify the working tree. Writable gates that run on the host still do.
+func (f *Factory) SetWritableWorkspace(dir string) {
+	f.overlay = dir
+}
+
+// HostWritable reports whether a writable gate created so far runs on the
+// host, in the workspace rather than the writable workspace.
+func (f *Factory) HostWritable() bool {
+	return f.hostWritable
+}
+
+// SetLocal sets the runner of gates with container: local. With all, every
+// exec and script gate runs on the host, as with --no-docker.
+func (f *Factory) SetLocal(runner LocalRunner, all bool) {
+	f.local = runner
+	f.allLocal = all
+}
+
+// SetNix sets the runner of gates with container: nix.
+func (f *Factory) SetNix(runner LocalRunner) {
+	f.nix = runner
+}
+
+// DisableHostExecution makes gates that would run programs on the host fail
+// to build: container: local and nix gates, and exec: parser plugins. Use it
+// when the checked code is not trusted, as the server does.
+func (f *Factory) DisableHostExecution() {
+	f.noHost = true
+}
+
+// SetPromptDir sets the directory relative prompt_file paths are resolved
+// against, in place of the project directory, for a gates.yaml kept outside
+// the project.
+func (f *Factory) SetPromptDir(dir string) {
+	f.promptDir = dir
+}
+
+// SetDevcontainer sets the pool of gates with container: devcontainer, which
+// starts the project's dev container.
+func (f *Factory) SetDevcontainer(p PoolManager) {
+	f.devcontainer = p
+}
+
+// SetCoverageBaselines sets where coverage gates keep the coverage they
+// compare changes against.
+func (f *Factory) SetCoverageBaselines(b CoverageBaselines) {
+	f.baselines = b
+}
+
 // Create builds a Gate from a gate config entry.
 // Returns an error if the gate type is unknown or dependencies are missing.
 func (f *Factory) Create(cfg config.Gate) (Gate, error) {
 	switch cfg.Type {
 	case config.GateTypeExec, config.GateTypeScript:
-		return f.createContainerGate(cfg), nil
+		return f.createContainerGate(cfg)
 	case config.GateTypeLLM:
 		return f.createLLMGate(cfg)
+	case config.GateTypeCoverage:
+		run, err := f.containerGate(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewCoverageGate(run, f.gitService, f.baselines), nil
+	case config.GateTypePolicy:
+		return NewPolicyGate(cfg, f.gitService), nil
 	default:
 		return nil, fmt.Errorf("unknown gate type %q for gate %q", cfg.Type, cfg.Name)
 	}
@@ -54,17 +153,163 @@ func (f *Factory) Create(cfg config.Gate) (Gate, error) {
 
 // createContainerGate builds a ContainerGate with the appropriate parser.
 // Handles both "exec" and "script" gate types.
-func (f *Factory) createContainerGate(cfg config.Gate) Gate {
-	prs := f.registry.GetOrDefault(cfg.Parser)
-	return NewContainerGate(cfg, f.pool, f.executor, prs, f.projectPath)
+func (f *Factory) createContainerGate(cfg config.Gate) (Gate, error) {
+	g, err := f.containerGate(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// containerGate builds the ContainerGate that runs cfg's command in its
+// container, on the host, or in the dev container.
+func (f *Factory) containerGate(cfg config.Gate) (*ContainerGate, error) {
+	prs, err := f.resolveParser(cfg)
+	if err != nil {
+		return nil, err
+	}
+	workspace := f.projectPath
+	if f.workspace != "" {
+		workspace = f.workspace
+	}
+	if f.noHost && (cfg.IsLocal() || f.allLocal) {
+		return nil, fmt.Errorf("gate %q: host execution is disabled", cfg.Name)
+	}
+	switch {
+	case cfg.Container == config.ContainerNix:
+		if f.nix == nil {
+			return nil, fmt.Errorf("gate %q: nix execution is not available", cfg.Name)
+		}
+		f.hostWritable = f.hostWritable || cfg.Writable
+		return NewContainerGate(cfg, f.nix, f.nix, prs, workspace).WithRoot(workspace).WithGit(f.gitService), nil
+	case cfg.IsLocal() || f.allLocal:
+		if f.local == nil {
+			return nil, fmt.Errorf("gate %q: local execution is not available", cfg.Name)
+		}
+		// Host runs have no containers to reuse or discard.
+		cfg.Reuse = ""
+		f.hostWritable = f.hostWritable || cfg.Writable
+		return NewContainerGate(cfg, f.local, f.local, prs, workspace).WithRoot(workspace).WithGit(f.gitService), nil
+	}
+
+	if cfg.Writable && f.overlay != "" {
+		workspace = f.overlay
+	}
+	if cfg.Container == config.ContainerDevcontainer {
+		if f.devcontainer == nil {
+			return nil, fmt.Errorf("gate %q: devcontainer gates need the docker runtime", cfg.Name)
+		}
+		return NewContainerGate(cfg, f.devcontainer, f.executor, prs, workspace).WithGit(f.gitService), nil
+	}
+	return NewContainerGate(cfg, f.pool, f.executor, prs, workspace).WithGit(f.gitService), nil
+}
+
+// resolveParser selects the parser for a gate: an external plugin, a configurable
+// regex or trivy parser, or a registered parser (falling back to generic).
+func (f *Factory) resolveParser(cfg config.Gate) (parser.Parser, error) {
+	switch {
+	case parser.IsPlugin(cfg.Parser):
+		if f.noHost {
+			return nil, fmt.Errorf("gate %q: parser plugins are disabled with host execution", cfg.Name)
+		}
+		return parser.NewExecParser(cfg.Parser, f.projectPath), nil
+	case cfg.Parser == "regex":
+		if cfg.ParserConfig == nil {
+			return nil, fmt.Errorf("gate %q: parser 'regex' requires parser_config", cfg.Name)
+		}
+		pc := cfg.ParserConfig
+		return parser.NewRegexParser(parser.RegexConfig{
+			Pattern:       pc.Pattern,
+			FileGroup:     pc.FileGroup,
+			LineGroup:     pc.LineGroup,
+			ColumnGroup:   pc.ColumnGroup,
+			SeverityGroup: pc.SeverityGroup,
+			RuleGroup:     pc.RuleGroup,
+			MessageGroup:  pc.MessageGroup,
+			Severity:      pc.Severity,
+		})
+	case cfg.Parser == "trivy-json":
+		failOn := ""
+		if cfg.ParserConfig != nil {
+			failOn = cfg.ParserConfig.FailOn
+		}
+		prs, err := parser.NewTrivyParser(failOn)
+		if err != nil {
+			return nil, fmt.Errorf("gate %q: %w", cfg.Name, err)
+		}
+		return prs, nil
+	default:
+		return f.registry.GetOrDefault(cfg.Parser), nil
+	}
 }
 
 // createLLMGate builds an LLMGate, returning an error if no LLM client is configured.
 func (f *Factory) createLLMGate(cfg config.Gate) (Gate, error) {
-	if f.llmClient == nil {
-		return nil, fmt.Errorf("gate %q requires an LLM client but none is configured — set GATEKEEPER_GEMINI_KEY or add to ~/.config/gatekeeper/config.yaml", cfg.Name)
+	client, err := f.llmChain(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PromptFile != "" {
+		rules, err := f.readPromptFile(cfg.Dir, cfg.PromptFile)
+		if err != nil {
+			return nil, fmt.Errorf("gate %q: %w", cfg.Name, err)
+		}
+		cfg.Prompt = rules
+	}
+	if err := llm.CheckPromptTemplate(cfg.Prompt); err != nil {
+		return nil, fmt.Errorf("gate %q: %w", cfg.Name, err)
+	}
+	return NewLLMGate(cfg, client, f.gitService).WithDismissals(f.dismissals), nil
+}
+
+// llmChain builds the client of an LLM gate: its provider followed by its
+// fallback providers. Providers that are not set up are left out of the chain;
+// the gate needs at least one.
+func (f *Factory) llmChain(cfg config.Gate) (llm.Client, error) {
+	if f.llmProviders == nil {
+		if f.llmClient == nil {
+			return nil, fmt.Errorf("gate %q requires an LLM client but none is configured — set GATEKEEPER_GEMINI_KEY (or GOOGLE_API_KEY / GEMINI_API_KEY) or add to ~/.config/gatekeeper/config.yaml", cfg.Name)
+		}
+		return f.llmClient, nil
+	}
+
+	var links []llm.ChainLink
+	var unavailable []error
+	for _, provider := range append([]string{cfg.Provider}, cfg.FallbackProviders...) {
+		client, err := f.llmProviders(provider)
+		switch {
+		case errors.Is(err, llm.ErrProviderUnavailable):
+			unavailable = append(unavailable, err)
+		case err != nil:
+			return nil, fmt.Errorf("gate %q: %w", cfg.Name, err)
+		default:
+			links = append(links, llm.ChainLink{Name: provider, Client: client})
+		}
+	}
+	if len(links) == 0 {
+		return nil, fmt.Errorf("gate %q requires an LLM client but none is configured: %w", cfg.Name, errors.Join(unavailable...))
+	}
+	return llm.NewChainClient(links...), nil
+}
+
+// readPromptFile reads review rules from path, relative to dir in the project
+// root (the directory of the gate's config) or the prompt directory.
+func (f *Factory) readPromptFile(dir, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		root := f.projectPath
+		if f.promptDir != "" {
+			root = f.promptDir
+		}
+		path = filepath.Join(root, filepath.FromSlash(dir), path)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- prompt_file is configured by the repository owner
+	if err != nil {
+		return "", fmt.Errorf("reading prompt_file: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("prompt_file %s is empty", path)
 	}
-	return NewLLMGate(cfg, f.llmClient, f.gitService), nil
+	return string(data), nil
 }
 
 // CreateAll builds Gates from a list of gate configs.
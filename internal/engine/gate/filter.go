@@ -2,8 +2,11 @@ package gate
 
 import (
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
 )
 
 // ShouldRun determines whether a gate should run based on its only/except patterns
@@ -14,7 +17,13 @@ import (
 //   - If only is set, the gate runs only if at least one staged file matches.
 //   - If except is set, files matching except patterns are excluded first.
 //   - Patterns use filepath.Match glob syntax (e.g., "*.go", "cmd/**").
+//   - Gates from a nested config see only the files in their directory, with
+//     paths relative to it, and do not run if none are staged.
 func ShouldRun(cfg config.Gate, stagedFiles []string) bool {
+	stagedFiles = cfg.ScopeFiles(stagedFiles)
+	if cfg.Dir != "" && len(stagedFiles) == 0 {
+		return false
+	}
 	if len(cfg.Only) == 0 && len(cfg.Except) == 0 {
 		return true
 	}
@@ -34,6 +43,90 @@ func ShouldRun(cfg config.Gate, stagedFiles []string) bool {
 	return len(filtered) > 0
 }
 
+// vendoredDirs are directories of third-party code, left out of LLM reviews
+// wherever they appear in a path.
+var vendoredDirs = []string{"vendor", "node_modules", "third_party", "bower_components"}
+
+// generatedFiles are base-name patterns of generated and lock files, left out
+// of LLM reviews.
+var generatedFiles = []string{
+	"*.pb.go", "*.pb.gw.go", "*_pb2.py", "*_pb2_grpc.py", "*.pb.cc", "*.pb.h",
+	"*_generated.go", "*.gen.go", "zz_generated.*", "*.min.js", "*.min.css",
+	"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock",
+	"poetry.lock", "Pipfile.lock", "composer.lock", "Gemfile.lock", "uv.lock",
+}
+
+// ReviewDiffs returns the diffs an LLM gate reviews: those of the files in the
+// gate's directory that pass its only/except patterns, less vendored,
+// generated, and lock files unless the gate sets review_vendored. Binary
+// files, deletions, renames without changes, submodule commits, and git-lfs
+// pointers have no lines to review and are left out too. excluded counts the
+// diffs left out.
+func ReviewDiffs(cfg config.Gate, diffs []git.FileDiff) (kept []git.FileDiff, excluded int) {
+	for _, d := range diffs {
+		if hasReviewableLines(d) && reviews(cfg, d.Path) {
+			kept = append(kept, d)
+		}
+	}
+	return kept, len(diffs) - len(kept)
+}
+
+// hasReviewableLines reports whether a diff adds or changes lines of a file
+// that exists after the commit.
+func hasReviewableLines(d git.FileDiff) bool {
+	switch {
+	case d.Binary, d.Submodule, d.LFS, d.Change == git.ChangeDeleted:
+		return false
+	case d.Change == git.ChangeRenamed:
+		return git.HasHunks(d)
+	default:
+		return true
+	}
+}
+
+// reviews reports whether an LLM gate reviews the file at path, relative to
+// the project root.
+func reviews(cfg config.Gate, path string) bool {
+	file, ok := inScope(cfg, path)
+	return ok && (cfg.ReviewVendored || !vendoredOrGenerated(file))
+}
+
+// inScope reports whether the file at path, relative to the project root, is
+// in the gate's directory and passes its only/except patterns. file is the
+// path relative to the gate's directory.
+func inScope(cfg config.Gate, path string) (file string, ok bool) {
+	scoped := cfg.ScopeFiles([]string{path})
+	if len(scoped) == 0 {
+		return "", false
+	}
+	file = scoped[0]
+	if len(cfg.Except) > 0 && matchesPattern(file, cfg.Except) {
+		return "", false
+	}
+	if len(cfg.Only) > 0 && !matchesPattern(file, cfg.Only) {
+		return "", false
+	}
+	return file, true
+}
+
+// vendoredOrGenerated reports whether file is in a vendored directory or
+// matches a generated or lock file pattern.
+func vendoredOrGenerated(file string) bool {
+	dirs := strings.Split(filepath.ToSlash(filepath.Dir(file)), "/")
+	for _, dir := range vendoredDirs {
+		if slices.Contains(dirs, dir) {
+			return true
+		}
+	}
+	base := filepath.Base(file)
+	for _, p := range generatedFiles {
+		if matched, _ := filepath.Match(p, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
 // excludeFiles returns files that do NOT match any of the given patterns.
 func excludeFiles(files, patterns []string) []string {
 	var result []string
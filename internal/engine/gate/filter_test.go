@@ -1,9 +1,11 @@
 package gate
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
 )
 
 func TestShouldRun_NoFilters(t *testing.T) {
@@ -150,3 +152,79 @@ func TestFilterGates_NoStagedFiles(t *testing.T) {
 		t.Errorf("expected 1 gate when no staged files, got %d", len(result))
 	}
 }
+
+func TestShouldRun_NestedConfig(t *testing.T) {
+	cfg := config.Gate{Name: "services/api:lint", Only: []string{"*.go", "cmd/*"}, Dir: "services/api"}
+
+	if ShouldRun(cfg, []string{"main.go", "services/web/app.go"}) {
+		t.Error("expected gate not to run without staged files in its directory")
+	}
+	if !ShouldRun(cfg, []string{"services/api/cmd/serve"}) {
+		t.Error("expected only patterns to match paths relative to the gate's directory")
+	}
+	if ShouldRun(cfg, []string{"services/api/README.md"}) {
+		t.Error("expected only filter to apply within the gate's directory")
+	}
+	if !ShouldRun(config.Gate{Dir: "services/api"}, []string{"services/api/README.md"}) {
+		t.Error("expected unfiltered gate to run for any staged file in its directory")
+	}
+}
+
+func TestReviewDiffs(t *testing.T) {
+	diffs := []git.FileDiff{
+		{Path: "main.go"},
+		{Path: "api/v1/api.pb.go"},
+		{Path: "vendor/github.com/x/y.go"},
+		{Path: "web/node_modules/lib/index.js"},
+		{Path: "web/package-lock.json"},
+		{Path: "docs/guide.md"},
+		{Path: "internal/db/db.go"},
+	}
+	paths := func(diffs []git.FileDiff) []string {
+		var out []string
+		for _, d := range diffs {
+			out = append(out, d.Path)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.Gate
+		want     []string
+		excluded int
+	}{
+		{"defaults", config.Gate{}, []string{"main.go", "docs/guide.md", "internal/db/db.go"}, 4},
+		{"only", config.Gate{Only: []string{"*.go"}}, []string{"main.go", "internal/db/db.go"}, 5},
+		{"except", config.Gate{Except: []string{"docs/*"}}, []string{"main.go", "internal/db/db.go"}, 5},
+		{"review vendored", config.Gate{Only: []string{"*.go"}, ReviewVendored: true}, []string{"main.go", "api/v1/api.pb.go", "vendor/github.com/x/y.go", "internal/db/db.go"}, 3},
+		{"nested", config.Gate{Dir: "internal", Only: []string{"db/*"}}, []string{"internal/db/db.go"}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, excluded := ReviewDiffs(tt.cfg, diffs)
+			if !reflect.DeepEqual(paths(kept), tt.want) || excluded != tt.excluded {
+				t.Errorf("ReviewDiffs = %v, %d excluded; want %v, %d", paths(kept), excluded, tt.want, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestReviewDiffs_LeavesOutDiffsWithoutLines(t *testing.T) {
+	diffs := []git.FileDiff{
+		{Path: "main.go", Change: git.ChangeModified, Content: "diff --git a/main.go b/main.go\n@@ -1 +1 @@\n-a\n+b"},
+		{Path: "moved.go", OldPath: "old.go", Change: git.ChangeRenamed, Content: "diff --git a/old.go b/moved.go\nrename from old.go\nrename to moved.go"},
+		{Path: "edited.go", OldPath: "orig.go", Change: git.ChangeRenamed, Content: "diff --git a/orig.go b/edited.go\nrename from orig.go\nrename to edited.go\n@@ -1 +1 @@\n-a\n+b"},
+		{Path: "gone.go", Change: git.ChangeDeleted, Content: "diff --git a/gone.go b/gone.go\ndeleted file mode 100644\n@@ -1 +0,0 @@\n-a"},
+		{Path: "logo.png", Change: git.ChangeAdded, Binary: true, Content: "diff --git a/logo.png b/logo.png\nBinary files /dev/null and b/logo.png differ"},
+	}
+
+	kept, excluded := ReviewDiffs(config.Gate{}, diffs)
+	var paths []string
+	for _, d := range kept {
+		paths = append(paths, d.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"main.go", "edited.go"}) || excluded != 3 {
+		t.Errorf("ReviewDiffs = %v, %d excluded; want [main.go edited.go], 3", paths, excluded)
+	}
+}
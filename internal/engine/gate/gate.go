@@ -1,5 +1,5 @@
 // Package gate defines the unified gate interface and implementations for
-// exec, script, and LLM gate types.
+// exec, script, LLM, coverage, and policy gate types.
 package gate
 
 import (
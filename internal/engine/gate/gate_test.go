@@ -3,6 +3,10 @@ package gate
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +14,8 @@ import (
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/engine/llm"
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"google.golang.org/genai"
 )
 
 // --- Tests ---
@@ -63,6 +69,90 @@ func TestLLMGate_Success(t *testing.T) {
 	}
 }
 
+func TestLLMGate_LowConfidenceDowngraded(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "main.go", Content: "diff content here\n@@ -1,5 +1,10 @@\n+query := \"SELECT \" + id"},
+		},
+	}
+	low := 0.5
+	llmClient := &llm.MockClient{
+		Result: []parser.StructuredError{
+			{File: "main.go", Line: 2, Severity: "error", Message: "possible SQL injection", Confidence: &low},
+			{File: "main.go", Line: 2, Severity: "suggestion", Message: "use a named constant"},
+		},
+	}
+	cfg := config.Gate{
+		Name:          "review",
+		Type:          config.GateTypeLLM,
+		Provider:      "gemini-3-pro",
+		Prompt:        "Check for injection",
+		MinConfidence: 0.7,
+	}
+
+	result, err := NewLLMGate(cfg, llmClient, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed {
+		t.Error("expected gate to pass with only a downgraded warning and a suggestion")
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Severity != "warning" {
+		t.Fatalf("expected downgraded finding kept as warning, got %+v", result.Errors)
+	}
+
+	cfg.LowConfidence = config.LowConfidenceDrop
+	result, err = NewLLMGate(cfg, llmClient, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Severity != "suggestion" {
+		t.Errorf("expected low-confidence finding dropped, got %+v", result.Errors)
+	}
+}
+
+func TestLLMGate_EstimatedCostOnly(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{{Path: "main.go", Content: "diff --git a/main.go b/main.go\n@@ -1 +1 @@\n+x"}},
+	}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review code"}
+
+	result, err := NewLLMGate(cfg, llm.NewEstimatingClient("gemini-3-pro"), gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed || !result.Skipped || !strings.Contains(result.SkipReason, "estimated cost only") {
+		t.Errorf("expected estimate-only skip, got %+v", result)
+	}
+	if result.Usage == nil || !result.Usage.Estimated || result.Usage.PromptTokens == 0 || result.Usage.CostUSD == 0 {
+		t.Errorf("expected estimated usage with cost, got %+v", result.Usage)
+	}
+}
+
+func TestLLMGate_DropsDismissedFindings(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs:    []git.FileDiff{{Path: "main.go", Content: "diff content here\n@@ -1,5 +1,10 @@\n+var password = \"secret\""}},
+		Contents: map[string]string{"main.go": "package main\n\nvar password = \"secret\"\n"},
+	}
+	finding := parser.StructuredError{File: "main.go", Line: 3, Severity: "error", Message: "hardcoded secret"}
+	llmClient := &llm.MockClient{Result: []parser.StructuredError{finding}}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review code"}
+
+	dismissals := &llm.Dismissals{}
+	dismissals.Add(llm.Dismissal{Key: llm.DismissalKey("review", finding, `var password = "secret"`)})
+
+	result, err := NewLLMGate(cfg, llmClient, gitSvc).WithDismissals(dismissals).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed || len(result.Errors) != 0 {
+		t.Errorf("expected dismissed finding not to fail the gate, got %+v", result.Errors)
+	}
+	if len(result.Suppressed) != 1 {
+		t.Errorf("expected dismissed finding listed as suppressed, got %+v", result.Suppressed)
+	}
+}
+
 func TestLLMGate_NoDiffs(t *testing.T) {
 	gitSvc := &git.MockService{
 		Diffs: nil,
@@ -85,6 +175,63 @@ func TestLLMGate_NoDiffs(t *testing.T) {
 	}
 }
 
+func TestLLMGate_NoFilesInScope(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "go.sum", Content: "diff\n@@ -1 +1 @@\n+x"},
+			{Path: "vendor/lib/lib.go", Content: "diff\n@@ -1 +1 @@\n+y"},
+		},
+	}
+	client := &countingClient{}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review code"}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Passed || !result.Skipped || !strings.Contains(result.SkipReason, "2 staged excluded") {
+		t.Errorf("expected a skip with no files in scope, got %+v", result)
+	}
+	if len(client.prompts) != 0 {
+		t.Errorf("expected no review, got %d", len(client.prompts))
+	}
+}
+
+func TestLLMGate_ReviewsSmallLFSFiles(t *testing.T) {
+	oid := strings.Repeat("ab", 32)
+	pointer := func(size int) string {
+		return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, size)
+	}
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "data/rules.json", LFS: true, Change: git.ChangeAdded, Content: "diff --git a/data/rules.json b/data/rules.json\n@@ -0,0 +1,3 @@\n+version https://git-lfs.github.com/spec/v1"},
+			{Path: "assets/huge.json", LFS: true, Change: git.ChangeAdded, Content: "diff --git a/assets/huge.json b/assets/huge.json\n@@ -0,0 +1,3 @@\n+version https://git-lfs.github.com/spec/v1"},
+			{Path: "main.go", Change: git.ChangeModified, Content: "diff --git a/main.go b/main.go\n@@ -1 +1 @@\n+x"},
+		},
+		Contents:   map[string]string{"data/rules.json": pointer(20), "assets/huge.json": pointer(5 << 20)},
+		LFSObjects: map[string]string{oid: "{\"allow\": [\"*\"]}\n"},
+	}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review", Language: "any"}
+
+	// Without max_lfs_size, pointers are not reviewed.
+	client := &countingClient{}
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 || strings.Contains(client.prompts[0], "git-lfs") || strings.Contains(client.prompts[0], "rules.json") {
+		t.Errorf("expected LFS pointers left out of the review, got %q", client.prompts)
+	}
+
+	client = &countingClient{}
+	cfg.MaxLFSSize = "1KB"
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], `+{"allow": ["*"]}`) || strings.Contains(client.prompts[0], "huge.json") {
+		t.Errorf("expected the small LFS file reviewed with its content, got %q", client.prompts)
+	}
+}
+
 func TestLLMGate_DiffError(t *testing.T) {
 	gitSvc := &git.MockService{
 		DiffErr: errors.New("git error"),
@@ -133,19 +280,176 @@ func TestLLMGate_ReviewError(t *testing.T) {
 	}
 }
 
-func TestLLMGate_AllFilesExceedSizeLimit(t *testing.T) {
-	// Large content that exceeds 1KB
-	largeContent := make([]byte, 2048)
-	for i := range largeContent {
-		largeContent[i] = 'x'
+func TestLLMGate_CircuitOpenReportedOnce(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "main.go", Content: "diff\n@@ -1,5 +1,10 @@\n+foo"},
+		},
 	}
+	// A breaker that opens on the first overloaded call, shared by both gates.
+	client := llm.NewLimitedClient(&llm.MockClient{Err: genai.APIError{Code: 429, Message: "quota exceeded"}}, nil, llm.NewBreaker("gemini/gemini-3-pro", 1))
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review code"}
+
+	first, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.SystemError == "" || !first.WarnOnError {
+		t.Errorf("expected a warn-level system error for the open circuit, got %+v", first)
+	}
+
+	cfg.Name = "security"
+	second, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.SystemError != "" || !second.Skipped || !second.Passed {
+		t.Errorf("expected later gates to be skipped, got %+v", second)
+	}
+}
 
+func TestLLMGate_RecordsTokenUsage(t *testing.T) {
 	gitSvc := &git.MockService{
 		Diffs: []git.FileDiff{
-			{Path: "big.go", Content: string(largeContent)},
+			{Path: "main.go", Content: "diff\n@@ -1,5 +1,10 @@\n+foo"},
 		},
 	}
-	llmClient := &llm.MockClient{}
+	llmClient := &llm.MockClient{
+		Usage: llm.Usage{Model: "gemini-2.5-flash", PromptTokens: 1000, ResponseTokens: 200},
+	}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "Review code"}
+
+	result, err := NewLLMGate(cfg, llmClient, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Usage == nil {
+		t.Fatal("expected token usage on result")
+	}
+	if result.Usage.PromptTokens != 1000 || result.Usage.ResponseTokens != 200 {
+		t.Errorf("unexpected usage %+v", *result.Usage)
+	}
+	if result.Usage.CostUSD <= 0 {
+		t.Errorf("expected a cost estimate for a priced model, got %v", result.Usage.CostUSD)
+	}
+}
+
+func TestLLMGate_SkipsOverTokenBudget(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "main.go", Content: "diff\n@@ -1,5 +1,10 @@\n+foo"},
+		},
+	}
+	client := llm.NewBudgetClient(&llm.MockClient{}, llm.NewBudget(1))
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "Review code"}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Skipped || !result.Passed {
+		t.Errorf("expected passed, skipped result, got passed=%v skipped=%v", result.Passed, result.Skipped)
+	}
+	if result.SystemError != "" {
+		t.Errorf("expected no system error, got %q", result.SystemError)
+	}
+	if !strings.Contains(result.SkipReason, "token budget") {
+		t.Errorf("expected budget skip reason, got %q", result.SkipReason)
+	}
+}
+
+// countingClient records the prompts it reviews.
+type countingClient struct {
+	prompts []string
+}
+
+func (c *countingClient) Review(_ context.Context, prompt string) ([]parser.StructuredError, error) {
+	c.prompts = append(c.prompts, prompt)
+	return nil, nil
+}
+
+func (c *countingClient) Summarize(_ context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return "", nil
+}
+
+// largeDiff returns a diff of n hunks of roughly 300 bytes each.
+func largeDiff(path string, n int) git.FileDiff {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n", path, path)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "@@ -%d,1 +%d,2 @@\n+%s\n", i*10+1, i*10+1, strings.Repeat("x", 280))
+	}
+	return git.FileDiff{Path: path, Content: b.String()}
+}
+
+func TestLLMGate_RendersPromptVariables(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs:  []git.FileDiff{{Path: "api/handler.go", Content: "diff --git a/api/handler.go b/api/handler.go\n@@ -1 +1 @@\n+x"}},
+		Branch: "feature/auth",
+	}
+	client := &countingClient{}
+	cfg := config.Gate{
+		Name:     "review",
+		Type:     config.GateTypeLLM,
+		Provider: "gemini-3-pro",
+		Prompt:   "Review {{.Language}} in {{join .Files \", \"}} on {{.Branch}}",
+	}
+
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 {
+		t.Fatalf("expected 1 review, got %d", len(client.prompts))
+	}
+	if !strings.Contains(client.prompts[0], "Review Go in api/handler.go on feature/auth") {
+		t.Errorf("expected rendered rules, got:\n%s", client.prompts[0])
+	}
+}
+
+func TestLLMGate_ReviewsEachLanguageSeparately(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "api/handler.go", Content: "diff --git a/api/handler.go b/api/handler.go\n@@ -1 +1 @@\n+x"},
+			{Path: "web/app.ts", Content: "diff --git a/web/app.ts b/web/app.ts\n@@ -1 +1 @@\n+y"},
+			{Path: "api/util.go", Content: "diff --git a/api/util.go b/api/util.go\n@@ -1 +1 @@\n+z"},
+		},
+	}
+	client := &countingClient{}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review {{.Language}}: {{join .Files \", \"}}"}
+
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 2 {
+		t.Fatalf("expected one review per language, got %d", len(client.prompts))
+	}
+	if !strings.Contains(client.prompts[0], "Review Go: api/handler.go, api/util.go") || !strings.Contains(client.prompts[0], "Language: Go\n") {
+		t.Errorf("expected the Go files in the first review, got:\n%s", client.prompts[0])
+	}
+	if !strings.Contains(client.prompts[1], "Review TypeScript: web/app.ts") {
+		t.Errorf("expected the TypeScript file in the second review, got:\n%s", client.prompts[1])
+	}
+
+	// language: overrides detection and keeps the files in one review.
+	client = &countingClient{}
+	cfg.Language = "Go templates"
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "Review Go templates: api/handler.go, web/app.ts, api/util.go") {
+		t.Errorf("expected one review with the configured language, got %q", client.prompts)
+	}
+}
+
+func TestLLMGate_ChunksFilesOverSizeLimit(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "small.go", Content: "diff --git a/small.go b/small.go\n@@ -1 +1 @@\n+x"},
+			largeDiff("big.go", 8),
+		},
+	}
+	client := &countingClient{}
 	cfg := config.Gate{
 		Name:        "review",
 		Type:        config.GateTypeLLM,
@@ -154,13 +458,135 @@ func TestLLMGate_AllFilesExceedSizeLimit(t *testing.T) {
 		MaxFileSize: "1KB",
 	}
 
-	gate := NewLLMGate(cfg, llmClient, gitSvc)
-	result, err := gate.Execute(context.Background())
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if !result.Passed {
-		t.Error("expected gate to pass when all files skipped")
+		t.Error("expected gate to pass")
+	}
+	// One review for small.go, then 3 chunks of 3, 3, and 2 hunks for big.go.
+	if len(client.prompts) != 4 {
+		t.Fatalf("expected 4 reviews, got %d", len(client.prompts))
+	}
+	if strings.Contains(client.prompts[0], "big.go") {
+		t.Error("expected oversized file to be kept out of the shared review")
+	}
+	for _, p := range client.prompts[1:] {
+		if !strings.Contains(p, "diff --git a/big.go b/big.go") {
+			t.Errorf("expected every chunk to carry the file header, got:\n%s", p)
+		}
+	}
+	if len(result.Truncated) != 0 {
+		t.Errorf("expected nothing truncated, got %v", result.Truncated)
+	}
+}
+
+func TestLLMGate_ReportsChunksOverMaxChunks(t *testing.T) {
+	gitSvc := &git.MockService{Diffs: []git.FileDiff{largeDiff("big.go", 8)}}
+	client := &countingClient{}
+	cfg := config.Gate{
+		Name:        "review",
+		Type:        config.GateTypeLLM,
+		Provider:    "gemini-3-pro",
+		Prompt:      "Review code",
+		MaxFileSize: "1KB",
+		MaxChunks:   2,
+	}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 2 {
+		t.Errorf("expected 2 chunk reviews, got %d", len(client.prompts))
+	}
+	if len(result.Truncated) != 1 || result.Truncated[0] != "big.go: 1 of 3 chunks not reviewed (max_chunks 2 reached)" {
+		t.Errorf("unexpected truncation report: %v", result.Truncated)
+	}
+}
+
+func TestLLMGate_FullContextTwoPass(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs: []git.FileDiff{
+			{Path: "api.go", Content: "diff --git a/api.go b/api.go\n@@ -3 +3 @@\n-func Get() {}\n+func Load() {}"},
+			{Path: "old.go", Content: "diff --git a/old.go b/old.go\ndeleted file mode 100644\n@@ -1 +0,0 @@\n-package api"},
+		},
+		Contents: map[string]string{"api.go": "package api\n\nfunc Load() {}\n\nfunc use() { Get() }\n"},
+	}
+	client := &llm.MockClient{
+		Summary: "Renames Get to Load.",
+		Result: []parser.StructuredError{
+			{File: "api.go", Line: 5, Severity: "error", Message: "Get no longer exists"},
+			{File: "api.go", Line: 50, Severity: "error", Message: "hallucinated"},
+		},
+	}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "Review", Mode: config.LLMModeFullContext}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SystemError != "" {
+		t.Fatalf("unexpected system error: %s", result.SystemError)
+	}
+	// Line 5 is outside the hunk but inside the full file, so it is kept.
+	if len(result.Errors) != 1 || result.Errors[0].Message != "Get no longer exists" {
+		t.Errorf("expected the cross-reference finding only, got %+v", result.Errors)
+	}
+	if len(result.Truncated) != 0 {
+		t.Errorf("expected full context to be used, got %v", result.Truncated)
+	}
+}
+
+func TestLLMGate_FullContextFallsBackWhenTooLarge(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs:    []git.FileDiff{{Path: "big.go", Content: "diff --git a/big.go b/big.go\n@@ -1 +1 @@\n+x"}},
+		Contents: map[string]string{"big.go": strings.Repeat("x", 2048)},
+	}
+	client := &countingClient{}
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini", Prompt: "Review", Mode: config.LLMModeFullContext, MaxContextSize: "1KB"}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 || strings.Contains(client.prompts[0], "Full files") {
+		t.Errorf("expected a single diff review, got %d prompts", len(client.prompts))
+	}
+	if len(result.Truncated) != 1 || !strings.Contains(result.Truncated[0], "max_context_size (1KB)") {
+		t.Errorf("expected fallback to be reported, got %v", result.Truncated)
+	}
+}
+
+func TestLLMGate_DocDrift(t *testing.T) {
+	gitSvc := &git.MockService{
+		Diffs:    []git.FileDiff{{Path: "api.go", Content: "diff --git a/api.go b/api.go\n@@ -4 +4 @@\n-func Load() error { return nil }\n+func Load() error { return errFail }"}},
+		Contents: map[string]string{"api.go": "package api\n\n// Load never fails.\nfunc Load() error { return errFail }\n"},
+	}
+	client := &countingClient{}
+	cfg := config.Gate{Name: "doc-drift", Type: config.GateTypeLLM, Provider: "gemini", Mode: config.LLMModeDocDrift}
+
+	result, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SystemError != "" {
+		t.Fatalf("unexpected system error: %s", result.SystemError)
+	}
+	if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "doc comment") || !strings.Contains(client.prompts[0], "// Load never fails.") {
+		t.Errorf("expected one doc drift review with the full file, got %q", client.prompts)
+	}
+
+	// Too large for full context: the diff is still checked with the doc drift prompt.
+	client = &countingClient{}
+	cfg.MaxContextSize = "1KB"
+	gitSvc.Contents["api.go"] = strings.Repeat("x", 2048)
+	if _, err := NewLLMGate(cfg, client, gitSvc).Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.prompts) != 1 || !strings.Contains(client.prompts[0], "doc comment") || strings.Contains(client.prompts[0], "Full files") {
+		t.Errorf("expected a doc drift review of the diff, got %q", client.prompts)
 	}
 }
 
@@ -254,6 +680,153 @@ func TestFactory_CreateExecGate(t *testing.T) {
 	}
 }
 
+func TestFactory_SetWorkspace(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/project")
+	cfg := config.Gate{Name: "lint", Type: config.GateTypeExec, Command: "make lint"}
+
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.(*ContainerGate).project; got != "/project" {
+		t.Errorf("expected project directory mount, got %q", got)
+	}
+
+	f.SetWorkspace("/project/.git/gatekeeper/snapshot")
+	g, err = f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.(*ContainerGate).project; got != "/project/.git/gatekeeper/snapshot" {
+		t.Errorf("expected snapshot mount, got %q", got)
+	}
+}
+
+func TestFactory_SetWritableWorkspace(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/project")
+	f.SetWritableWorkspace("/project/.git/gatekeeper/overlay")
+	readOnly := config.Gate{Name: "lint", Type: config.GateTypeExec, Command: "make lint"}
+	writable := config.Gate{Name: "fmt", Type: config.GateTypeExec, Command: "gofmt -w .", Writable: true}
+	local := config.Gate{Name: "prettier", Type: config.GateTypeExec, Command: "prettier -w .", Container: config.ContainerLocal, Writable: true}
+
+	if g, _ := f.Create(readOnly); g.(*ContainerGate).project != "/project" {
+		t.Errorf("expected a read-only gate to mount the project, got %q", g.(*ContainerGate).project)
+	}
+	if g, _ := f.Create(writable); g.(*ContainerGate).project != "/project/.git/gatekeeper/overlay" {
+		t.Errorf("expected a writable gate to mount the overlay, got %q", g.(*ContainerGate).project)
+	}
+	if f.HostWritable() {
+		t.Error("expected no host writable gate yet")
+	}
+
+	f.SetLocal(&struct {
+		pool.MockPool
+		pool.MockExecutor
+	}{}, false)
+	if g, _ := f.Create(local); g.(*ContainerGate).project != "/project" {
+		t.Errorf("expected a host gate to run in the project, got %q", g.(*ContainerGate).project)
+	}
+	if !f.HostWritable() {
+		t.Error("expected a host writable gate")
+	}
+}
+
+func TestFactory_SetLocal(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/project")
+	local := config.Gate{Name: "lint", Type: config.GateTypeExec, Command: "make lint", Container: config.ContainerLocal, Workdir: "api"}
+	docker := config.Gate{Name: "test", Type: config.GateTypeExec, Command: "go test ./...", Container: "golang:1.25"}
+
+	if _, err := f.Create(local); err == nil {
+		t.Error("expected an error for a local gate without a local runner")
+	}
+
+	runner := &struct {
+		pool.MockPool
+		pool.MockExecutor
+	}{}
+	f.SetLocal(runner, false)
+	g, err := f.Create(local)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cg := g.(*ContainerGate); cg.executor != runner || cg.buildCommand() != "cd '/project/api' && make lint" {
+		t.Errorf("expected a host gate, got command %q", cg.buildCommand())
+	}
+	if g, _ := f.Create(docker); g.(*ContainerGate).executor == runner {
+		t.Error("expected a container gate to keep its executor")
+	}
+
+	f.SetLocal(runner, true)
+	if g, _ := f.Create(docker); g.(*ContainerGate).executor != runner {
+		t.Error("expected every gate to run on the host")
+	}
+}
+
+func TestFactory_DisableHostExecution(t *testing.T) {
+	runner := &struct {
+		pool.MockPool
+		pool.MockExecutor
+	}{}
+	f := NewFactory(&pool.MockPool{}, &pool.MockExecutor{}, parser.NewRegistry(), nil, nil, "/project")
+	f.SetLocal(runner, false)
+	f.SetNix(runner)
+	f.DisableHostExecution()
+
+	for _, cfg := range []config.Gate{
+		{Name: "local", Type: config.GateTypeExec, Command: "make lint", Container: config.ContainerLocal},
+		{Name: "nix", Type: config.GateTypeExec, Command: "make", Container: config.ContainerNix},
+		{Name: "plugin", Type: config.GateTypeExec, Command: "lint", Container: "alpine", Parser: "exec:./tools/parse.sh"},
+	} {
+		if _, err := f.Create(cfg); err == nil || !strings.Contains(err.Error(), "disabled") {
+			t.Errorf("%s: expected host execution to be refused, got %v", cfg.Name, err)
+		}
+	}
+	if _, err := f.Create(config.Gate{Name: "test", Type: config.GateTypeExec, Command: "go test ./...", Container: "golang:1.25"}); err != nil {
+		t.Errorf("expected container gates to be built, got %v", err)
+	}
+
+	// --no-docker runs every gate on the host.
+	f.SetLocal(runner, true)
+	if _, err := f.Create(config.Gate{Name: "test", Type: config.GateTypeExec, Command: "go test ./...", Container: "golang:1.25"}); err == nil {
+		t.Error("expected --no-docker to be refused")
+	}
+}
+
+func TestFactory_NixAndDevcontainer(t *testing.T) {
+	f := NewFactory(&pool.MockPool{}, &pool.MockExecutor{}, parser.NewRegistry(), nil, nil, "/project")
+	nixGate := config.Gate{Name: "nix", Type: config.GateTypeExec, Command: "make", Container: config.ContainerNix}
+	devGate := config.Gate{Name: "dev", Type: config.GateTypeExec, Command: "make", Container: config.ContainerDevcontainer}
+
+	for _, cfg := range []config.Gate{nixGate, devGate} {
+		if _, err := f.Create(cfg); err == nil {
+			t.Errorf("%s: expected an error without a runner", cfg.Name)
+		}
+	}
+
+	nix := &struct {
+		pool.MockPool
+		pool.MockExecutor
+	}{}
+	dev := &pool.MockPool{}
+	f.SetNix(nix)
+	f.SetDevcontainer(dev)
+
+	g, err := f.Create(nixGate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cg := g.(*ContainerGate); cg.executor != nix || cg.root != "/project" {
+		t.Errorf("expected a nix gate on the host, got %+v", cg)
+	}
+	g, err = f.Create(devGate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cg := g.(*ContainerGate); cg.pool != dev || cg.root != "" {
+		t.Errorf("expected a dev container gate, got %+v", cg)
+	}
+}
+
 func TestFactory_CreateScriptGate(t *testing.T) {
 	reg := parser.NewRegistry()
 	f := NewFactory(nil, nil, reg, nil, nil, "/project")
@@ -273,6 +846,73 @@ func TestFactory_CreateScriptGate(t *testing.T) {
 	}
 }
 
+func TestFactory_CreatePluginParser(t *testing.T) {
+	reg := parser.NewRegistry()
+	f := NewFactory(nil, nil, reg, nil, nil, "/project")
+
+	cfg := config.Gate{
+		Name:    "custom",
+		Type:    config.GateTypeExec,
+		Command: "custom-tool",
+		Parser:  "exec:./tools/parse.sh",
+	}
+
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cg, ok := g.(*ContainerGate)
+	if !ok {
+		t.Fatalf("expected ContainerGate, got %T", g)
+	}
+	if _, ok := cg.parser.(*parser.ExecParser); !ok {
+		t.Errorf("expected ExecParser, got %T", cg.parser)
+	}
+}
+
+func TestFactory_CreateRegexParser(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/project")
+
+	cfg := config.Gate{
+		Name:         "custom",
+		Type:         config.GateTypeExec,
+		Command:      "custom-tool",
+		Parser:       "regex",
+		ParserConfig: &config.ParserConfig{Pattern: `^(\S+):(\d+): (.*)$`, FileGroup: 1, LineGroup: 2, MessageGroup: 3},
+	}
+
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := g.(*ContainerGate).parser.(*parser.RegexParser); !ok {
+		t.Errorf("expected RegexParser, got %T", g.(*ContainerGate).parser)
+	}
+
+	cfg.ParserConfig = &config.ParserConfig{Pattern: "("}
+	if _, err := f.Create(cfg); err == nil {
+		t.Error("expected error for invalid regex pattern")
+	}
+}
+
+func TestFactory_CreateTrivyParser(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, nil, "/project")
+
+	cfg := config.Gate{Name: "trivy", Type: config.GateTypeExec, Command: "trivy fs --format json /workspace", Parser: "trivy-json"}
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := g.(*ContainerGate).parser.(*parser.TrivyParser); !ok {
+		t.Errorf("expected TrivyParser, got %T", g.(*ContainerGate).parser)
+	}
+
+	cfg.ParserConfig = &config.ParserConfig{FailOn: "SEVERE"}
+	if _, err := f.Create(cfg); err == nil {
+		t.Error("expected error for unknown fail_on severity")
+	}
+}
+
 func TestFactory_CreateLLMGate(t *testing.T) {
 	reg := parser.NewRegistry()
 	llmClient := &llm.MockClient{}
@@ -295,6 +935,47 @@ func TestFactory_CreateLLMGate(t *testing.T) {
 	}
 }
 
+func TestFactory_CreateLLMGate_PromptFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".gatekeeper", "prompts"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gatekeeper", "prompts", "security.md"), []byte("Check {{.Language}} for injection"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := NewFactory(nil, nil, parser.NewRegistry(), &llm.MockClient{}, &git.MockService{}, dir)
+
+	cfg := config.Gate{Name: "security", Type: config.GateTypeLLM, Provider: "gemini-3-pro", PromptFile: ".gatekeeper/prompts/security.md"}
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := g.(*LLMGate).cfg.Prompt; got != "Check {{.Language}} for injection" {
+		t.Errorf("expected rules from prompt_file, got %q", got)
+	}
+
+	cfg.PromptFile = "missing.md"
+	if _, err := f.Create(cfg); err == nil || !strings.Contains(err.Error(), "reading prompt_file") {
+		t.Errorf("expected read error, got: %v", err)
+	}
+	// With a prompt directory, the project's files are not read.
+	f = NewFactory(nil, nil, parser.NewRegistry(), &llm.MockClient{}, &git.MockService{}, t.TempDir())
+	f.SetPromptDir(dir)
+	cfg.PromptFile = ".gatekeeper/prompts/security.md"
+	if _, err := f.Create(cfg); err != nil {
+		t.Errorf("expected prompt_file to be read from the prompt directory, got %v", err)
+	}
+}
+
+func TestFactory_CreateLLMGate_InvalidTemplate(t *testing.T) {
+	f := NewFactory(nil, nil, parser.NewRegistry(), &llm.MockClient{}, &git.MockService{}, "/project")
+
+	cfg := config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini-3-pro", Prompt: "Review {{.Author}}"}
+	if _, err := f.Create(cfg); err == nil || !strings.Contains(err.Error(), "Author") {
+		t.Errorf("expected unknown variable error, got: %v", err)
+	}
+}
+
 func TestFactory_CreateLLMGate_NoClient(t *testing.T) {
 	reg := parser.NewRegistry()
 	f := NewFactory(nil, nil, reg, nil, nil, "/project")
@@ -312,6 +993,65 @@ func TestFactory_CreateLLMGate_NoClient(t *testing.T) {
 	}
 }
 
+func TestFactory_CreateLLMGate_FallbackChain(t *testing.T) {
+	gitSvc := &git.MockService{Diffs: []git.FileDiff{{Path: "main.go", Content: "@@ -1,1 +1,2 @@\n+x := 1"}}}
+	var asked []string
+	f := NewFactory(nil, nil, parser.NewRegistry(), nil, gitSvc, "/project")
+	f.SetLLMProviders(func(provider string) (llm.Client, error) {
+		asked = append(asked, provider)
+		switch provider {
+		case "gemini-3-pro":
+			return &llm.MockClient{Err: errors.New("rate limited")}, nil
+		case "gpt-4o-mini":
+			return nil, fmt.Errorf("%w: no OpenAI key", llm.ErrProviderUnavailable)
+		default:
+			return &llm.MockClient{Result: []parser.StructuredError{{File: "main.go", Line: 2, Severity: "warning", Message: "unused"}}}, nil
+		}
+	})
+
+	cfg := config.Gate{
+		Name:              "review",
+		Type:              config.GateTypeLLM,
+		Provider:          "gemini-3-pro",
+		FallbackProviders: []string{"gpt-4o-mini", "ollama/llama3"},
+		Prompt:            "Review code",
+	}
+	g, err := f.Create(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Tool != "ollama/llama3" {
+		t.Errorf("expected the finding of the fallback provider, got %+v (system error %q)", result.Errors, result.SystemError)
+	}
+	if strings.Join(asked, " ") != "gemini-3-pro gpt-4o-mini ollama/llama3" {
+		t.Errorf("asked for %q", asked)
+	}
+
+	// Without any available provider the gate cannot be created.
+	f.SetLLMProviders(func(provider string) (llm.Client, error) {
+		return nil, fmt.Errorf("%w: %s", llm.ErrProviderUnavailable, provider)
+	})
+	if _, err := f.Create(cfg); err == nil || !strings.Contains(err.Error(), "none is configured") {
+		t.Errorf("expected a missing client error, got %v", err)
+	}
+
+	// An unknown provider is a configuration error, even as a fallback.
+	f.SetLLMProviders(func(provider string) (llm.Client, error) {
+		if _, err := llm.ParseProvider(provider); err != nil {
+			return nil, err
+		}
+		return &llm.MockClient{}, nil
+	})
+	cfg.FallbackProviders = []string{"claude-3"}
+	if _, err := f.Create(cfg); err == nil || !strings.Contains(err.Error(), "unknown LLM provider") {
+		t.Errorf("expected an unknown provider error, got %v", err)
+	}
+}
+
 func TestFactory_CreateUnknownType(t *testing.T) {
 	reg := parser.NewRegistry()
 	f := NewFactory(nil, nil, reg, nil, nil, "/project")
@@ -2,7 +2,9 @@ package gate
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,14 +13,16 @@ import (
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/engine/llm"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
 )
 
 // LLMGate sends staged diffs to an LLM for semantic code review.
 type LLMGate struct {
-	cfg    config.Gate
-	client llm.Client
-	gitSvc git.Service
+	cfg       config.Gate
+	client    llm.Client
+	gitSvc    git.Service
+	dismissed *llm.Dismissals
 }
 
 // NewLLMGate creates a new LLMGate.
@@ -30,6 +34,12 @@ func NewLLMGate(cfg config.Gate, client llm.Client, gitSvc git.Service) *LLMGate
 	}
 }
 
+// WithDismissals drops findings the developer dismissed from the gate's results.
+func (g *LLMGate) WithDismissals(d *llm.Dismissals) *LLMGate {
+	g.dismissed = d
+	return g
+}
+
 // Execute extracts staged diffs, sends them to the LLM, and returns structured results.
 func (g *LLMGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
 	log := logger.FromContext(ctx)
@@ -37,9 +47,10 @@ func (g *LLMGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
 	start := time.Now()
 
 	result := &formatter.GateResult{
-		Name:     g.cfg.Name,
-		Type:     string(g.cfg.Type),
-		Blocking: g.cfg.IsBlocking(),
+		Name:        g.cfg.Name,
+		Type:        string(g.cfg.Type),
+		Blocking:    g.cfg.IsBlocking(),
+		WarnOnError: g.cfg.GetOnError() == config.OnErrorWarn,
 	}
 
 	// 1. Get staged diffs
@@ -57,42 +68,379 @@ func (g *LLMGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
 		return result, nil
 	}
 
-	// 2. Filter by size
-	maxSize := parseMaxFileSize(g.cfg.MaxFileSize)
-	filtered, _ := git.FilterBySize(diffs, maxSize)
+	// Review only the files in scope: only/except, minus vendored and generated code.
+	diffs, excluded := ReviewDiffs(g.cfg, g.withLFSContent(ctx, diffs))
+	if excluded > 0 {
+		log.Debug("LLMGate.Execute excluded files from review", "gate", g.cfg.Name, "excluded", excluded)
+	}
+	if len(diffs) == 0 {
+		result.Passed = true
+		result.Skipped = true
+		result.SkipReason = fmt.Sprintf("no files to review: %d staged excluded by only/except, as vendored or generated, or without lines to review", excluded)
+		result.DurationMs = time.Since(start).Milliseconds()
+		log.Info("LLMGate.Execute skipped — no files in scope", "gate", g.cfg.Name, "excluded", excluded)
+		return result, nil
+	}
 
-	if len(filtered) == 0 {
+	// 2. Review: full files plus diff in two passes for full_context or one doc
+	// comment check for doc_drift, else the diff in size-limited batches.
+	reviewCtx, tracker := llm.WithUsageTracking(ctx)
+	vars := g.promptVars(ctx, diffs)
+	phase := time.Now()
+	var findings []parser.StructuredError
+	fullContext := false
+	if g.cfg.Mode == config.LLMModeFullContext || g.cfg.Mode == config.LLMModeDocDrift {
+		files, reason := g.loadContext(ctx, diffs)
+		switch {
+		case reason != "":
+			log.Warn("LLMGate.Execute falling back to diff review", "gate", g.cfg.Name, "reason", reason)
+			result.Truncated = append(result.Truncated, "full context not sent: "+reason+"; reviewed the diff only")
+		case g.cfg.Mode == config.LLMModeDocDrift:
+			fullContext = true
+			findings, err = g.reviewDocDrift(reviewCtx, vars, diffs, files)
+		default:
+			fullContext = true
+			findings, err = g.reviewFullContext(reviewCtx, vars, diffs, files)
+		}
+	}
+	if !fullContext {
+		findings, err = g.reviewDiff(reviewCtx, vars, diffs, result)
+	}
+	result.Timings = &formatter.PhaseTimings{LLMMs: time.Since(phase).Milliseconds()}
+	usage := tracker.Usage()
+	result.Usage = tokenUsage(usage)
+	if usage.Estimated && err == nil {
+		log.Info("LLMGate.Execute estimated cost only", "gate", g.cfg.Name, "prompt_tokens", usage.PromptTokens)
 		result.Passed = true
+		result.Skipped = true
+		result.SkipReason = "dry run: estimated cost only, diff not sent"
 		result.DurationMs = time.Since(start).Milliseconds()
-		log.Info("LLMGate.Execute skipped — all files exceed size limit", "gate", g.cfg.Name)
 		return result, nil
 	}
 
-	// 3. Build prompt and review
-	prompt := llm.BuildPrompt(g.cfg.Prompt, "", filtered)
-	errors, err := g.client.Review(ctx, prompt)
+	if errors.Is(err, llm.ErrTokenBudgetExceeded) {
+		log.Warn("LLMGate.Execute skipped — token budget exceeded", "gate", g.cfg.Name, "error", err)
+		result.Passed = true
+		result.Skipped = true
+		result.SkipReason = err.Error()
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+	// Once a provider's circuit opens, one gate reports it as a warning and the
+	// others are skipped, rather than every LLM gate failing the same way.
+	var circuitErr *llm.CircuitOpenError
+	if errors.As(err, &circuitErr) && !circuitErr.FirstReport() {
+		log.Warn("LLMGate.Execute skipped — provider circuit open", "gate", g.cfg.Name, "provider", circuitErr.Provider)
+		result.Passed = true
+		result.Skipped = true
+		result.SkipReason = fmt.Sprintf("%s unavailable this run (see the LLM error reported once)", circuitErr.Provider)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
 	if err != nil {
 		result.SystemError = fmt.Sprintf("LLM review failed: %v", err)
+		result.WarnOnError = result.WarnOnError || circuitErr != nil
 		result.DurationMs = time.Since(start).Milliseconds()
 		return result, nil
 	}
 
-	// 4. Validate line numbers against actual diffs (hallucination mitigation)
-	validated := llm.ValidateLineNumbers(errors, filtered)
-
-	// 5. Set tool field
-	for i := range validated {
-		validated[i].Tool = g.cfg.Provider
+	// 3. Drop dismissed findings, downgrade or drop low-confidence ones, then set the
+	// tool field unless the client recorded the provider that answered
+	findings, result.Suppressed = llm.FilterDismissed(findings, g.cfg.Name, g.dismissed, g.stagedLine(ctx))
+	findings = llm.ApplyConfidence(findings, g.cfg.MinConfidence, g.cfg.LowConfidence == config.LowConfidenceDrop)
+	for i := range findings {
+		if findings[i].Tool == "" {
+			findings[i].Tool = g.cfg.Provider
+		}
 	}
 
-	result.Errors = validated
-	result.Passed = len(validated) == 0
+	// Like parsed tools, the gate fails on errors; warnings, info, and suggestions are advisory.
+	result.Errors = findings
+	result.Passed = !hasErrors(findings)
+	if len(result.Truncated) > 0 {
+		log.Warn("LLMGate.Execute reviewed part of the diff", "gate", g.cfg.Name, "truncated", len(result.Truncated))
+	}
 
 	result.DurationMs = time.Since(start).Milliseconds()
-	log.Info("LLMGate.Execute completed", "gate", g.cfg.Name, "passed", result.Passed, "issues", len(validated), "duration_ms", result.DurationMs)
+	log.Info("LLMGate.Execute completed", "gate", g.cfg.Name, "passed", result.Passed, "issues", len(findings), "dismissed", len(result.Suppressed), "full_context", fullContext, "duration_ms", result.DurationMs)
 	return result, nil
 }
 
+// reviewDiff reviews diffs in size-limited batches and validates findings against
+// the hunks. Portions left unreviewed are added to result.Truncated. It returns
+// ErrTokenBudgetExceeded only if the budget ran out before the first batch.
+func (g *LLMGate) reviewDiff(ctx context.Context, vars llm.PromptVars, diffs []git.FileDiff, result *formatter.GateResult) ([]parser.StructuredError, error) {
+	// Files within the limit share one review, larger files are reviewed in hunk-aligned chunks.
+	batches, truncated := g.reviewBatches(diffs)
+	result.Truncated = append(result.Truncated, truncated...)
+
+	var findings []parser.StructuredError
+	for i, batch := range batches {
+		batchVars := vars
+		if g.cfg.Language == "" {
+			batchVars.Language = llm.DetectLanguage(diffPaths(batch))
+		}
+		prompt, err := g.diffPrompt(batchVars, batch)
+		if err != nil {
+			return nil, err
+		}
+		errs, err := g.client.Review(ctx, prompt)
+		if errors.Is(err, llm.ErrTokenBudgetExceeded) && i > 0 {
+			for _, rest := range batches[i:] {
+				result.Truncated = append(result.Truncated, fmt.Sprintf("%s: not reviewed, token budget exceeded", batchPaths(rest)))
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Validate line numbers against actual diffs (hallucination mitigation)
+		findings = append(findings, llm.ValidateLineNumbers(errs, batch)...)
+	}
+	return findings, nil
+}
+
+// diffPrompt builds the review prompt of a batch of diffs for the gate's mode.
+func (g *LLMGate) diffPrompt(vars llm.PromptVars, batch []git.FileDiff) (string, error) {
+	if g.cfg.Mode == config.LLMModeDocDrift {
+		return llm.BuildDocDriftPrompt(g.cfg.Prompt, vars, nil, batch)
+	}
+	return llm.BuildPrompt(g.cfg.Prompt, vars, batch)
+}
+
+// reviewDocDrift reviews the doc comments of the changed code against the full
+// staged content of the changed files in one pass. Findings must point at lines
+// of the changed files.
+func (g *LLMGate) reviewDocDrift(ctx context.Context, vars llm.PromptVars, diffs []git.FileDiff, files []llm.SourceFile) ([]parser.StructuredError, error) {
+	prompt, err := llm.BuildDocDriftPrompt(g.cfg.Prompt, vars, files, diffs)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := g.client.Review(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return llm.ValidateFileLines(errs, files), nil
+}
+
+// reviewFullContext reviews the diff with the full staged content of the changed
+// files: a first pass summarizes the change, the second reports issues given that
+// summary. Findings must point at lines of the changed files.
+func (g *LLMGate) reviewFullContext(ctx context.Context, vars llm.PromptVars, diffs []git.FileDiff, files []llm.SourceFile) ([]parser.StructuredError, error) {
+	summaryPrompt, err := llm.BuildSummaryPrompt(files, diffs)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := g.client.Summarize(ctx, summaryPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("summary pass: %w", err)
+	}
+	prompt, err := llm.BuildContextPrompt(g.cfg.Prompt, vars, summary, files, diffs)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := g.client.Review(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("review pass: %w", err)
+	}
+	return llm.ValidateFileLines(errs, files), nil
+}
+
+// promptVars resolves the template variables of the review rules for diffs.
+// Files are left to the prompt builders, which list the files of each request.
+func (g *LLMGate) promptVars(ctx context.Context, diffs []git.FileDiff) llm.PromptVars {
+	branch, err := g.gitSvc.CurrentBranch(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Debug("LLMGate.promptVars could not resolve branch", "gate", g.cfg.Name, "error", err)
+	}
+	language := g.cfg.Language
+	if language == "" {
+		language = llm.DetectLanguage(diffPaths(diffs))
+	}
+	return llm.PromptVars{Language: language, Branch: branch}
+}
+
+// stagedLine returns a lookup of staged lines for matching dismissals. Files are
+// read once; unreadable files yield empty lines.
+func (g *LLMGate) stagedLine(ctx context.Context) func(file string, line int) string {
+	contents := make(map[string]string)
+	return func(file string, line int) string {
+		content, ok := contents[file]
+		if !ok {
+			var err error
+			content, err = g.gitSvc.StagedFileContent(ctx, file)
+			if err != nil {
+				logger.FromContext(ctx).Debug("LLMGate.stagedLine could not read file", "gate", g.cfg.Name, "file", file, "error", err)
+			}
+			contents[file] = content
+		}
+		return llm.LineOf(content, line)
+	}
+}
+
+// defaultMaxContextSize bounds the full-context prompt when max_context_size is unset.
+const defaultMaxContextSize = 200 * 1024
+
+// loadContext reads the staged content of every changed file for a full_context
+// review. reason explains why full context cannot be used (too large or unreadable).
+func (g *LLMGate) loadContext(ctx context.Context, diffs []git.FileDiff) (files []llm.SourceFile, reason string) {
+	limit := parseMaxFileSize(g.cfg.MaxContextSize)
+	if limit == 0 {
+		limit = defaultMaxContextSize
+	}
+
+	tooLarge := fmt.Sprintf("changed files and diff exceed max_context_size (%dKB)", limit/1024)
+	size := 0
+	for _, d := range diffs {
+		size += len(d.Content)
+		if git.IsDeletion(d) {
+			continue
+		}
+		content, err := g.gitSvc.StagedFileContent(ctx, d.Path)
+		if err != nil {
+			return nil, err.Error()
+		}
+		if _, ok := git.ParseLFSPointer(content); ok {
+			continue // Its content is in the diff, see withLFSContent.
+		}
+		size += len(content)
+		if size > limit {
+			return nil, tooLarge
+		}
+		files = append(files, llm.SourceFile{Path: d.Path, Content: content})
+	}
+	if size > limit {
+		return nil, tooLarge
+	}
+	return files, ""
+}
+
+// withLFSContent replaces the diffs of git-lfs pointers to files within
+// max_lfs_size with diffs adding the files' content, read from the local LFS
+// store, so that they are reviewed in full. Other pointers are kept, and left
+// out of the review by ReviewDiffs.
+func (g *LLMGate) withLFSContent(ctx context.Context, diffs []git.FileDiff) []git.FileDiff {
+	limit := parseMaxFileSize(g.cfg.MaxLFSSize)
+	if limit == 0 {
+		return diffs
+	}
+	log := logger.FromContext(ctx)
+	diffs = slices.Clone(diffs)
+	for i, d := range diffs {
+		if !d.LFS || d.Change == git.ChangeDeleted {
+			continue
+		}
+		staged, err := g.gitSvc.StagedFileContent(ctx, d.Path)
+		if err != nil {
+			log.Debug("LLMGate.withLFSContent could not read pointer", "gate", g.cfg.Name, "file", d.Path, "error", err)
+			continue
+		}
+		pointer, ok := git.ParseLFSPointer(staged)
+		if !ok || pointer.Size > int64(limit) {
+			continue
+		}
+		content, err := g.gitSvc.LFSObject(ctx, pointer)
+		if err != nil {
+			log.Debug("LLMGate.withLFSContent could not read LFS object", "gate", g.cfg.Name, "file", d.Path, "error", err)
+			continue
+		}
+		if strings.ContainsRune(content, 0) {
+			continue // Binary content.
+		}
+		diffs[i] = git.FileDiff{Path: d.Path, OldPath: d.OldPath, Change: d.Change, Content: fullFileDiff(d.Path, content)}
+	}
+	return diffs
+}
+
+// fullFileDiff renders content as a diff adding every line of the file at path.
+func fullFileDiff(path, content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -0,0 +1,%d @@\n", path, path, path, path, len(lines))
+	for _, line := range lines {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
+
+// defaultMaxChunks is the number of chunk reviews per gate when max_chunks is unset.
+const defaultMaxChunks = 8
+
+// reviewBatches groups diffs into review requests: one per language for the files
+// within max_file_size (one for all of them if the gate sets language), then one
+// per chunk of each larger file, up to max_chunks chunks. truncated describes the
+// portions that will not be reviewed in full.
+func (g *LLMGate) reviewBatches(diffs []git.FileDiff) (batches [][]git.FileDiff, truncated []string) {
+	maxSize := parseMaxFileSize(g.cfg.MaxFileSize)
+	included, oversized := git.FilterBySize(diffs, maxSize)
+	switch {
+	case len(included) == 0:
+	case g.cfg.Language != "":
+		batches = append(batches, included)
+	default:
+		batches = append(batches, llm.GroupByLanguage(included)...)
+	}
+
+	maxChunks := g.cfg.MaxChunks
+	if maxChunks == 0 {
+		maxChunks = defaultMaxChunks
+	}
+	remaining := maxChunks
+	for _, d := range oversized {
+		chunks, cut := git.ChunkDiff(d, maxSize)
+		truncated = append(truncated, cut...)
+		if len(chunks) > remaining {
+			truncated = append(truncated, fmt.Sprintf("%s: %d of %d chunks not reviewed (max_chunks %d reached)", d.Path, len(chunks)-remaining, len(chunks), maxChunks))
+			chunks = chunks[:remaining]
+		}
+		for _, c := range chunks {
+			batches = append(batches, []git.FileDiff{c})
+		}
+		remaining -= len(chunks)
+	}
+	return batches, truncated
+}
+
+// hasErrors reports whether any finding has error severity.
+func hasErrors(findings []parser.StructuredError) bool {
+	for _, e := range findings {
+		if e.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+// batchPaths lists the files of a review batch, e.g. "a.go, b.go".
+func batchPaths(batch []git.FileDiff) string {
+	return strings.Join(diffPaths(batch), ", ")
+}
+
+// diffPaths returns the paths of diffs.
+func diffPaths(diffs []git.FileDiff) []string {
+	paths := make([]string, len(diffs))
+	for i, d := range diffs {
+		paths[i] = d.Path
+	}
+	return paths
+}
+
+// tokenUsage converts recorded LLM usage to its result form, or nil if no tokens were used
+// (e.g., the review was served from cache).
+func tokenUsage(u llm.Usage) *formatter.TokenUsage {
+	if u.Total() == 0 {
+		return nil
+	}
+	cost, _ := llm.EstimateCost(u)
+	return &formatter.TokenUsage{
+		PromptTokens:   u.PromptTokens,
+		ResponseTokens: u.ResponseTokens,
+		CostUSD:        cost,
+		Estimated:      u.Estimated,
+	}
+}
+
 // parseMaxFileSize converts a size string like "100KB" to bytes.
 // Supports KB, MB suffixes (case-insensitive). Returns 0 (no limit) on empty or invalid input.
 func parseMaxFileSize(s string) int {
@@ -137,6 +485,7 @@ var (
 type skippedGate struct {
 	name     string
 	gateType string
+	reason   string
 }
 
 // Ensure skippedGate implements Gate at compile time.
@@ -147,12 +496,18 @@ func NewSkippedGate(name, gateType string) Gate {
 	return &skippedGate{name: name, gateType: gateType}
 }
 
+// NewSkippedGateWithReason is like NewSkippedGate but reports why the gate was skipped.
+func NewSkippedGateWithReason(name, gateType, reason string) Gate {
+	return &skippedGate{name: name, gateType: gateType, reason: reason}
+}
+
 // Execute returns a passed, skipped result immediately.
 func (g *skippedGate) Execute(_ context.Context) (*formatter.GateResult, error) {
 	return &formatter.GateResult{
-		Name:    g.name,
-		Type:    g.gateType,
-		Passed:  true,
-		Skipped: true,
+		Name:       g.name,
+		Type:       g.gateType,
+		Passed:     true,
+		Skipped:    true,
+		SkipReason: g.reason,
 	}, nil
 }
@@ -0,0 +1,299 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// PolicyGate checks the staged diff against the built-in rules of its policy:
+// a limit on changed lines, forbidden files, files that must change together,
+// forbidden markers such as TODO in added lines, merge conflict markers, large
+// binaries, text that is not UTF-8, and changes to committed append-only files
+// such as migrations. It runs on the host and needs no container.
+type PolicyGate struct {
+	cfg    config.Gate
+	gitSvc git.Service
+}
+
+// NewPolicyGate creates a PolicyGate.
+func NewPolicyGate(cfg config.Gate, gitSvc git.Service) *PolicyGate {
+	return &PolicyGate{cfg: cfg, gitSvc: gitSvc}
+}
+
+// Execute checks the staged changes in the gate's scope.
+func (g *PolicyGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
+	log := logger.FromContext(ctx)
+	start := time.Now()
+
+	result := &formatter.GateResult{
+		Name:        g.cfg.Name,
+		Type:        string(g.cfg.Type),
+		Blocking:    g.cfg.IsBlocking(),
+		WarnOnError: g.cfg.GetOnError() == config.OnErrorWarn,
+	}
+
+	diffs, err := g.gitSvc.StagedDiff(ctx)
+	if err != nil {
+		result.SystemError = fmt.Sprintf("failed to get staged diffs: %v", err)
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+
+	size := func(file string) (int, error) {
+		content, err := g.gitSvc.StagedFileContent(ctx, file)
+		return len(content), err
+	}
+	result.Errors, err = checkPolicy(g.cfg, diffs, size)
+	if err != nil {
+		result.SystemError = err.Error()
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+	result.Passed = !hasErrors(result.Errors)
+	result.DurationMs = time.Since(start).Milliseconds()
+	log.Info("PolicyGate.Execute completed", "gate", g.cfg.Name, "passed", result.Passed, "findings", len(result.Errors))
+	return result, nil
+}
+
+// scopedDiff is a staged file diff in a gate's scope.
+type scopedDiff struct {
+	git.FileDiff
+	// rel is the path relative to the gate's directory.
+	rel     string
+	deleted bool
+}
+
+// conflictMarkers are the line prefixes git writes around a merge conflict.
+// "=======" is left out: it also underlines Markdown and reStructuredText headings.
+var conflictMarkers = []string{"<<<<<<<", "|||||||", ">>>>>>>"}
+
+// checkPolicy checks the diffs of the files in the gate's scope against its
+// policy. size returns the staged size of a file, for binary files.
+func checkPolicy(cfg config.Gate, diffs []git.FileDiff, size func(file string) (int, error)) ([]parser.StructuredError, error) {
+	policy := cfg.Policy
+	if policy == nil {
+		return nil, nil
+	}
+	var scoped []scopedDiff
+	changed := make(map[string]bool)
+	for _, d := range diffs {
+		rel, ok := inScope(cfg, d.Path)
+		if !ok {
+			continue
+		}
+		scoped = append(scoped, scopedDiff{FileDiff: d, rel: rel, deleted: git.IsDeletion(d)})
+		changed[rel] = true
+	}
+
+	var findings []parser.StructuredError
+	add := func(e parser.StructuredError) {
+		e.Severity = "error"
+		e.Tool = cfg.Name
+		findings = append(findings, e)
+	}
+
+	if policy.MaxChangedLines > 0 {
+		total := 0
+		for _, d := range scoped {
+			if vendoredOrGenerated(d.rel) {
+				continue
+			}
+			added, removed := git.ChangeCount(d.FileDiff)
+			total += added + removed
+		}
+		if total > policy.MaxChangedLines {
+			add(parser.StructuredError{
+				Rule:    "max-changed-lines",
+				Message: fmt.Sprintf("commit changes %d lines, over max_changed_lines %d", total, policy.MaxChangedLines),
+				Hint:    "Split the change into smaller commits.",
+			})
+		}
+	}
+
+	if len(policy.AppendOnly) > 0 {
+		for _, e := range appendOnlyViolations(cfg, diffs) {
+			add(e)
+		}
+	}
+
+	markers := markerPattern(policy.ForbiddenMarkers)
+	maxBinary := parseMaxFileSize(policy.MaxBinarySize)
+	for _, d := range scoped {
+		if d.deleted {
+			continue
+		}
+		if len(policy.ForbiddenFiles) > 0 && matchesPattern(d.rel, policy.ForbiddenFiles) {
+			add(parser.StructuredError{
+				File:    d.Path,
+				Rule:    "forbidden-file",
+				Message: "file matches policy.forbidden_files and must not be committed",
+				Hint:    fmt.Sprintf("Unstage it with 'git rm --cached %s' and add it to .gitignore.", d.Path),
+			})
+		}
+		for _, pair := range policy.RequiredPairs {
+			if !matchesPattern(d.rel, pair.Changed) || (len(pair.Except) > 0 && matchesPattern(d.rel, pair.Except)) {
+				continue
+			}
+			required := pairedFile(pair.Requires, d.rel)
+			if required == d.rel || changed[required] {
+				continue
+			}
+			add(parser.StructuredError{
+				File:    d.Path,
+				Rule:    "required-pair",
+				Message: fmt.Sprintf("changed without %s", path.Join(cfg.Dir, required)),
+				Hint:    "Stage a change to the paired file as well, e.g. a test for the changed code.",
+			})
+		}
+		if git.IsBinary(d.FileDiff) {
+			if maxBinary == 0 {
+				continue
+			}
+			n, err := size(d.Path)
+			if err != nil {
+				return nil, fmt.Errorf("reading staged %s: %w", d.Path, err)
+			}
+			if n > maxBinary {
+				add(parser.StructuredError{
+					File:    d.Path,
+					Rule:    "large-binary",
+					Message: fmt.Sprintf("binary file is %s, over max_binary_size %s", sizeString(n), policy.MaxBinarySize),
+					Hint:    fmt.Sprintf("Unstage it with 'git rm --cached %s'; keep large files out of the repository or in Git LFS.", d.Path),
+				})
+			}
+			continue
+		}
+		checkMarkers := markers != nil && !vendoredOrGenerated(d.rel)
+		if !policy.ConflictMarkers && !policy.RequireUTF8 && !checkMarkers {
+			continue
+		}
+		reportedUTF8 := false
+		for _, line := range git.Additions(d.FileDiff) {
+			if policy.ConflictMarkers && isConflictMarker(line.Text) {
+				add(parser.StructuredError{
+					File:    d.Path,
+					Line:    line.Number,
+					Rule:    "conflict-marker",
+					Message: "adds a merge conflict marker",
+					Hint:    "Resolve the conflict and remove the markers.",
+				})
+			}
+			if policy.RequireUTF8 && !reportedUTF8 && !utf8.ValidString(line.Text) {
+				// One finding per file: an encoding problem affects all of it.
+				reportedUTF8 = true
+				add(parser.StructuredError{
+					File:    d.Path,
+					Line:    line.Number,
+					Rule:    "invalid-utf8",
+					Message: "adds text that is not valid UTF-8",
+					Hint:    "Convert the file to UTF-8, e.g. with iconv.",
+				})
+			}
+			if !checkMarkers {
+				continue
+			}
+			if marker := markers.FindString(line.Text); marker != "" {
+				add(parser.StructuredError{
+					File:    d.Path,
+					Line:    line.Number,
+					Rule:    "forbidden-marker",
+					Message: fmt.Sprintf("adds %s", marker),
+					Hint:    "Resolve it before committing, or track it in an issue.",
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// appendOnlyViolations reports the diffs that modify, rename, or delete a
+// file matching policy.append_only. Such files, e.g. migrations, may only be
+// added: a diff against HEAD that is not an addition changes a committed one.
+func appendOnlyViolations(cfg config.Gate, diffs []git.FileDiff) []parser.StructuredError {
+	var findings []parser.StructuredError
+	for _, d := range diffs {
+		committed, verb := d.Path, ""
+		switch {
+		case d.Change == git.ChangeDeleted || git.IsDeletion(d):
+			verb = "deletes"
+		case d.Change == git.ChangeRenamed:
+			committed, verb = d.OldPath, "renames"
+		case d.Change == git.ChangeModified:
+			verb = "modifies"
+		default:
+			continue
+		}
+		rel, ok := inScope(cfg, committed)
+		if !ok || !matchesPattern(rel, cfg.Policy.AppendOnly) {
+			continue
+		}
+		findings = append(findings, parser.StructuredError{
+			File:    d.Path,
+			Rule:    "append-only",
+			Message: fmt.Sprintf("%s committed %s, which policy.append_only allows only to be added", verb, committed),
+			Hint:    fmt.Sprintf("A committed migration may already have run. Restore it with 'git restore --staged --worktree -- %s' and make the change in a new file.", committed),
+		})
+	}
+	return findings
+}
+
+// isConflictMarker reports whether line is a merge conflict marker line.
+func isConflictMarker(line string) bool {
+	for _, m := range conflictMarkers {
+		if rest, ok := strings.CutPrefix(line, m); ok && (rest == "" || rest[0] == ' ') {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeString formats a size in bytes, e.g. "3.4MB".
+func sizeString(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
+
+// markerPattern returns a pattern matching any of markers as a whole word, or
+// nil if there are none.
+func markerPattern(markers []string) *regexp.Regexp {
+	if len(markers) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(markers))
+	for i, m := range markers {
+		quoted[i] = regexp.QuoteMeta(strings.TrimSpace(m))
+	}
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// pairedFile expands the {dir}, {name}, and {ext} placeholders of a
+// required_pairs "requires" path for the changed file.
+func pairedFile(requires, file string) string {
+	ext := path.Ext(file)
+	r := strings.NewReplacer(
+		"{dir}", path.Dir(file),
+		"{name}", strings.TrimSuffix(path.Base(file), ext),
+		"{ext}", ext,
+	)
+	return path.Clean(r.Replace(requires))
+}
+
+// Ensure PolicyGate implements Gate at compile time.
+var _ Gate = (*PolicyGate)(nil)
@@ -0,0 +1,182 @@
+package gate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// policyDiff returns the diff of a file adding lines.
+func policyDiff(file string, lines ...string) git.FileDiff {
+	content := "diff --git a/" + file + " b/" + file + "\n--- a/" + file + "\n+++ b/" + file + fmt.Sprintf("\n@@ -1,0 +1,%d @@\n", len(lines))
+	for _, l := range lines {
+		content += "+" + l + "\n"
+	}
+	return git.FileDiff{Path: file, Content: content}
+}
+
+func runPolicyGate(t *testing.T, policy config.PolicyConfig, diffs ...git.FileDiff) []parser.StructuredError {
+	t.Helper()
+	g := NewPolicyGate(config.Gate{Name: "policy", Type: config.GateTypePolicy, Policy: &policy}, &git.MockService{Diffs: diffs})
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed != (len(result.Errors) == 0) {
+		t.Errorf("Passed = %v with findings %+v", result.Passed, result.Errors)
+	}
+	return result.Errors
+}
+
+func TestPolicyGate_MaxChangedLines(t *testing.T) {
+	diffs := []git.FileDiff{
+		policyDiff("main.go", "a", "b", "c"),
+		policyDiff("go.sum", "x", "y", "z"),
+	}
+	if findings := runPolicyGate(t, config.PolicyConfig{MaxChangedLines: 3}, diffs...); len(findings) != 0 {
+		t.Errorf("lock files should not count, got %+v", findings)
+	}
+	findings := runPolicyGate(t, config.PolicyConfig{MaxChangedLines: 2}, diffs...)
+	if len(findings) != 1 || findings[0].Rule != "max-changed-lines" || !strings.Contains(findings[0].Message, "changes 3 lines") {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestPolicyGate_ForbiddenFiles(t *testing.T) {
+	deleted := git.FileDiff{Path: "old.pem", Content: "diff --git a/old.pem b/old.pem\ndeleted file mode 100644\n--- a/old.pem\n+++ /dev/null\n@@ -1 +0,0 @@\n-key\n"}
+	findings := runPolicyGate(t, config.PolicyConfig{ForbiddenFiles: []string{"*.pem", ".env*"}},
+		policyDiff("certs/server.pem", "key"), policyDiff(".env.local", "TOKEN=x"), policyDiff("main.go", "package main"), deleted)
+	if len(findings) != 2 || findings[0].File != "certs/server.pem" || findings[1].File != ".env.local" || findings[0].Rule != "forbidden-file" {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestPolicyGate_RequiredPairs(t *testing.T) {
+	policy := config.PolicyConfig{RequiredPairs: []config.FilePair{{
+		Changed:  []string{"handler/*.go"},
+		Except:   []string{"*_test.go"},
+		Requires: "{dir}/{name}_test{ext}",
+	}}}
+
+	findings := runPolicyGate(t, policy, policyDiff("handler/user.go", "package handler"), policyDiff("handler/user_test.go", "package handler"))
+	if len(findings) != 0 {
+		t.Errorf("pair changed together, got %+v", findings)
+	}
+
+	findings = runPolicyGate(t, policy, policyDiff("handler/order.go", "package handler"), policyDiff("handler/user_test.go", "package handler"))
+	if len(findings) != 1 || findings[0].File != "handler/order.go" || findings[0].Message != "changed without handler/order_test.go" {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestPolicyGate_ForbiddenMarkers(t *testing.T) {
+	findings := runPolicyGate(t, config.PolicyConfig{ForbiddenMarkers: []string{"TODO", "FIXME"}},
+		policyDiff("main.go", "package main", "// TODO: remove", "var todoList []string", "x := 1 // FIXME"),
+		policyDiff("vendor/lib/lib.go", "// TODO upstream"))
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Line != 2 || findings[0].Message != "adds TODO" || findings[1].Line != 4 || findings[1].Message != "adds FIXME" {
+		t.Errorf("unexpected findings: %+v", findings)
+	}
+}
+
+func TestPolicyGate_ConflictMarkersAndUTF8(t *testing.T) {
+	findings := runPolicyGate(t, config.PolicyConfig{ConflictMarkers: true, RequireUTF8: true},
+		policyDiff("main.go", "<<<<<<< HEAD", "a := 1", "=======", "a := 2", ">>>>>>> feature"),
+		policyDiff("README.md", "Title", "=======", "<<<<<<<< not a marker"),
+		policyDiff("notes.txt", "caf\xe9", "na\xefve"))
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%s:%d %s", f.File, f.Line, f.Rule))
+	}
+	want := "main.go:1 conflict-marker, main.go:5 conflict-marker, notes.txt:1 invalid-utf8"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("findings = %v, want %s", got, want)
+	}
+}
+
+func TestPolicyGate_MaxBinarySize(t *testing.T) {
+	binary := func(file string) git.FileDiff {
+		return git.FileDiff{Path: file, Content: "diff --git a/" + file + " b/" + file + "\nnew file mode 100644\nindex 0000000..e69de29\nBinary files /dev/null and b/" + file + " differ\n"}
+	}
+	gitSvc := &git.MockService{
+		Diffs:    []git.FileDiff{binary("assets/logo.png"), binary("dump.bin")},
+		Contents: map[string]string{"assets/logo.png": strings.Repeat("x", 10), "dump.bin": strings.Repeat("x", 3<<20)},
+	}
+	g := NewPolicyGate(config.Gate{Name: "policy", Type: config.GateTypePolicy, Policy: &config.PolicyConfig{MaxBinarySize: "1MB"}}, gitSvc)
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Passed || len(result.Errors) != 1 || result.Errors[0].File != "dump.bin" || result.Errors[0].Message != "binary file is 3.0MB, over max_binary_size 1MB" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestPolicyGate_OnlyExcept(t *testing.T) {
+	g := NewPolicyGate(config.Gate{
+		Name: "policy", Type: config.GateTypePolicy, Except: []string{"docs/*"},
+		Policy: &config.PolicyConfig{ForbiddenMarkers: []string{"TODO"}},
+	}, &git.MockService{Diffs: []git.FileDiff{policyDiff("docs/plan.md", "TODO: write")}})
+	result, err := g.Execute(context.Background())
+	if err != nil || !result.Passed {
+		t.Errorf("excluded file should not be checked: %+v, %v", result, err)
+	}
+}
+
+func TestPolicyGate_AppendOnly(t *testing.T) {
+	policy := config.PolicyConfig{AppendOnly: []string{"migrations/*.sql"}}
+	added := policyDiff("migrations/0003_add_index.up.sql", "CREATE INDEX users_email ON users (email);")
+	added.Change = git.ChangeAdded
+	modified := policyDiff("migrations/0001_init.up.sql", "ALTER TABLE users ADD COLUMN name text;")
+	modified.Change = git.ChangeModified
+	renamed := git.FileDiff{Path: "archive/0002_users.up.sql", OldPath: "migrations/0002_users.up.sql", Change: git.ChangeRenamed,
+		Content: "diff --git a/migrations/0002_users.up.sql b/archive/0002_users.up.sql\nsimilarity index 100%\nrename from migrations/0002_users.up.sql\nrename to archive/0002_users.up.sql\n"}
+	deleted := git.FileDiff{Path: "migrations/0000_seed.up.sql", Change: git.ChangeDeleted,
+		Content: "diff --git a/migrations/0000_seed.up.sql b/migrations/0000_seed.up.sql\ndeleted file mode 100644\n--- a/migrations/0000_seed.up.sql\n+++ /dev/null\n@@ -1 +0,0 @@\n-INSERT INTO users VALUES (1);\n"}
+	other := policyDiff("main.go", "package main")
+	other.Change = git.ChangeModified
+
+	findings := runPolicyGate(t, policy, added, modified, renamed, deleted, other)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	for i, want := range []string{"modifies committed migrations/0001_init.up.sql", "renames committed migrations/0002_users.up.sql", "deletes committed migrations/0000_seed.up.sql"} {
+		if findings[i].Rule != "append-only" || !strings.HasPrefix(findings[i].Message, want) {
+			t.Errorf("finding %d = %+v, want message starting with %q", i, findings[i], want)
+		}
+	}
+	if findings[1].File != "archive/0002_users.up.sql" {
+		t.Errorf("expected the rename to be reported at its new path, got %q", findings[1].File)
+	}
+}
+
+func TestPolicyGate_DiffError(t *testing.T) {
+	g := NewPolicyGate(config.Gate{Name: "policy", Type: config.GateTypePolicy, Policy: &config.PolicyConfig{MaxChangedLines: 1}}, &git.MockService{DiffErr: errors.New("git broke")})
+	result, err := g.Execute(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.SystemError, "git broke") {
+		t.Errorf("expected a system error, got %+v", result)
+	}
+}
+
+func TestFactory_CreatePolicyGate(t *testing.T) {
+	f := NewFactory(&pool.MockPool{}, &pool.MockExecutor{}, parser.NewRegistry(), nil, &git.MockService{}, "/project")
+	g, err := f.Create(config.Gate{Name: "policy", Type: config.GateTypePolicy, Policy: &config.PolicyConfig{MaxChangedLines: 100}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := g.(*PolicyGate); !ok {
+		t.Errorf("expected a PolicyGate, got %T", g)
+	}
+}
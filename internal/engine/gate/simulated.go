@@ -0,0 +1,156 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// Outcome is the simulated result of a gate in demo mode.
+type Outcome string
+
+const (
+	OutcomePass    Outcome = "pass"
+	OutcomeFail    Outcome = "fail"
+	OutcomeTimeout Outcome = "timeout"
+	OutcomeError   Outcome = "error"
+	OutcomeSkip    Outcome = "skip"
+)
+
+// ParseSimulation parses "outcome:gate" specs such as "fail:go-test" or
+// "timeout:eslint" into outcomes keyed by gate name.
+func ParseSimulation(specs []string) (map[string]Outcome, error) {
+	outcomes := make(map[string]Outcome, len(specs))
+	for _, spec := range specs {
+		kind, name, ok := strings.Cut(spec, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid simulation %q (expected outcome:gate, e.g. fail:go-test)", spec)
+		}
+		switch o := Outcome(kind); o {
+		case OutcomePass, OutcomeFail, OutcomeTimeout, OutcomeError, OutcomeSkip:
+			outcomes[name] = o
+		default:
+			return nil, fmt.Errorf("unknown simulated outcome %q (valid: pass, fail, timeout, error, skip)", kind)
+		}
+	}
+	return outcomes, nil
+}
+
+// SimulatedGate fabricates a realistic result for a gate without running it.
+// It is used by "gatekeeper demo" to exercise output formats and CI wiring.
+type SimulatedGate struct {
+	cfg     config.Gate
+	outcome Outcome
+	delay   time.Duration
+}
+
+// NewSimulatedGate creates a SimulatedGate. The simulated run time is derived from
+// the gate name, so repeated demos look the same.
+func NewSimulatedGate(cfg config.Gate, outcome Outcome) *SimulatedGate {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cfg.Name))
+	delay := 150*time.Millisecond + time.Duration(h.Sum32()%600)*time.Millisecond
+	return &SimulatedGate{cfg: cfg, outcome: outcome, delay: delay}
+}
+
+// Execute waits for the simulated run time and returns the fabricated result.
+func (g *SimulatedGate) Execute(ctx context.Context) (*formatter.GateResult, error) {
+	result := &formatter.GateResult{
+		Name:        g.cfg.Name,
+		Type:        string(g.cfg.Type),
+		Blocking:    g.cfg.IsBlocking(),
+		WarnOnError: g.cfg.GetOnError() == config.OnErrorWarn,
+	}
+
+	if g.outcome == OutcomeSkip {
+		result.Passed = true
+		result.Skipped = true
+		return result, nil
+	}
+
+	select {
+	case <-time.After(g.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	result.DurationMs = g.delay.Milliseconds()
+
+	switch g.outcome {
+	case OutcomeFail:
+		result.Errors = sampleFindings(g.cfg)
+		if _, err := parser.EnrichHints(result.Errors); err != nil {
+			logger.FromContext(ctx).Warn("fix hints unavailable", "gate", g.cfg.Name, "error", err)
+		}
+		result.RawOutput = simulatedRawOutput(result.Errors)
+	case OutcomeTimeout:
+		timeout := g.cfg.Timeout
+		if timeout == 0 {
+			timeout = 30 * time.Second
+		}
+		result.DurationMs = timeout.Milliseconds()
+		result.SystemError = fmt.Sprintf("killed after timeout (%s)", timeout)
+	case OutcomeError:
+		result.SystemError = fmt.Sprintf("container setup failed: pulling image %q: simulated registry outage", g.cfg.Container)
+	default:
+		result.Passed = true
+	}
+	return result, nil
+}
+
+// sampleFindings returns plausible findings for the kind of tool a gate runs.
+func sampleFindings(cfg config.Gate) []parser.StructuredError {
+	switch {
+	case cfg.Type == config.GateTypeLLM:
+		return []parser.StructuredError{{
+			File: "internal/auth/session.go", Line: 88, Severity: "warning",
+			Message: "Error from store.Delete is ignored; a failed logout leaves the session valid.",
+			Hint:    "Return or log the error so callers can retry.",
+			Tool:    cfg.Provider,
+		}}
+	case cfg.Parser == "go-test-json":
+		return []parser.StructuredError{{
+			File: "internal/auth/token_test.go", Line: 42, Severity: "error", Rule: "TestValidateToken/expired",
+			Message: "expected expired token to be rejected, got nil error",
+			Tool:    "go-test",
+		}}
+	case cfg.Parser == "sarif":
+		return []parser.StructuredError{
+			{File: "internal/auth/handler.go", Line: 45, Column: 12, Severity: "error", Rule: "G101", Message: "Potential hardcoded credentials", Tool: cfg.Name},
+			{File: "cmd/server/main.go", Line: 17, Column: 2, Severity: "warning", Rule: "errcheck", Message: "Error return value of `srv.Shutdown` is not checked", Tool: cfg.Name},
+		}
+	default:
+		return []parser.StructuredError{{
+			Severity: "error",
+			Message:  fmt.Sprintf("%s exited with status 1 (simulated failure)", firstWord(cfg.Command, cfg.Name)),
+			Tool:     "generic",
+		}}
+	}
+}
+
+// simulatedRawOutput renders findings the way a tool would print them, for --verbose.
+func simulatedRawOutput(errs []parser.StructuredError) string {
+	var lines []string
+	for _, e := range errs {
+		if e.File != "" {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message))
+		} else {
+			lines = append(lines, e.Message)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// firstWord returns the program name of a command, or fallback if it is empty.
+func firstWord(command, fallback string) string {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		return fields[0]
+	}
+	return fallback
+}
@@ -0,0 +1,92 @@
+package gate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func TestParseSimulation(t *testing.T) {
+	got, err := ParseSimulation([]string{"fail:go-test", "timeout:eslint"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["go-test"] != OutcomeFail || got["eslint"] != OutcomeTimeout {
+		t.Errorf("unexpected outcomes: %v", got)
+	}
+
+	for _, bad := range []string{"fail", "fail:", "explode:go-test"} {
+		if _, err := ParseSimulation([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSimulatedGate_Outcomes(t *testing.T) {
+	tests := []struct {
+		outcome  Outcome
+		cfg      config.Gate
+		passed   bool
+		sysErr   string
+		findings bool
+		skipped  bool
+	}{
+		{OutcomePass, config.Gate{Name: "go-vet", Type: config.GateTypeExec}, true, "", false, false},
+		{OutcomeFail, config.Gate{Name: "go-test", Type: config.GateTypeExec, Parser: "go-test-json"}, false, "", true, false},
+		{OutcomeFail, config.Gate{Name: "lint", Type: config.GateTypeExec, Parser: "sarif"}, false, "", true, false},
+		{OutcomeFail, config.Gate{Name: "review", Type: config.GateTypeLLM, Provider: "gemini"}, false, "", true, false},
+		{OutcomeTimeout, config.Gate{Name: "eslint", Type: config.GateTypeExec, Timeout: time.Minute}, false, "killed after timeout (1m0s)", false, false},
+		{OutcomeError, config.Gate{Name: "ruff", Type: config.GateTypeExec, Container: "python:3.12"}, false, "container setup failed", false, false},
+		{OutcomeSkip, config.Gate{Name: "docs", Type: config.GateTypeExec}, true, "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.outcome)+"/"+tt.cfg.Name, func(t *testing.T) {
+			g := NewSimulatedGate(tt.cfg, tt.outcome)
+			g.delay = 0
+
+			res, err := g.Execute(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Name != tt.cfg.Name || res.Passed != tt.passed || res.Skipped != tt.skipped {
+				t.Errorf("unexpected result: %+v", res)
+			}
+			if !strings.Contains(res.SystemError, tt.sysErr) || (tt.sysErr == "" && res.SystemError != "") {
+				t.Errorf("expected system error %q, got %q", tt.sysErr, res.SystemError)
+			}
+			if (len(res.Errors) > 0) != tt.findings {
+				t.Errorf("expected findings=%v, got %+v", tt.findings, res.Errors)
+			}
+		})
+	}
+}
+
+func TestSimulatedGate_TimeoutReportsConfiguredDuration(t *testing.T) {
+	g := NewSimulatedGate(config.Gate{Name: "eslint", Type: config.GateTypeExec, Timeout: 90 * time.Second}, OutcomeTimeout)
+	g.delay = 0
+	res, _ := g.Execute(context.Background())
+	if res.DurationMs != 90000 {
+		t.Errorf("expected timeout duration, got %dms", res.DurationMs)
+	}
+}
+
+func TestSimulatedGate_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewSimulatedGate(config.Gate{Name: "slow"}, OutcomePass).Execute(ctx); err == nil {
+		t.Error("expected cancellation error")
+	}
+}
+
+func TestSimulatedGate_HintsEnriched(t *testing.T) {
+	g := NewSimulatedGate(config.Gate{Name: "gosec", Type: config.GateTypeExec, Parser: "sarif"}, OutcomeFail)
+	g.delay = 0
+	res, _ := g.Execute(context.Background())
+	if res.Errors[0].Hint == "" {
+		t.Errorf("expected hint for %s, got %+v", res.Errors[0].Rule, res.Errors[0])
+	}
+}
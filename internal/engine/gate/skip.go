@@ -0,0 +1,105 @@
+package gate
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+// SkipEnv is the environment variable that skips gates: "all" (or "*") skips
+// every gate, otherwise it is a comma-separated list of gate names.
+const SkipEnv = "GATEKEEPER_SKIP"
+
+// skipMarker matches "[skip gatekeeper]" and "[skip gate:<names>]" in a commit message.
+var skipMarker = regexp.MustCompile(`(?i)\[skip\s+(gatekeeper|gate:\s*([^\]]+))\]`)
+
+// Skips are gates skipped through an escape hatch (GATEKEEPER_SKIP or a commit
+// message marker) rather than by --skip. They are reported as skipped, with
+// their source, so they stay visible.
+type Skips struct {
+	// All is the source that skips every gate, if any.
+	All string
+	// Gates maps a skipped gate name to its source.
+	Gates map[string]string
+}
+
+// ParseSkips reads skip requests from a commit message and the value of SkipEnv.
+// The environment variable takes precedence for gates named in both.
+func ParseSkips(commitMessage, env string) Skips {
+	s := Skips{Gates: make(map[string]string)}
+
+	for _, m := range skipMarker.FindAllStringSubmatch(commitMessage, -1) {
+		if m[2] == "" {
+			s.All = "commit message [skip gatekeeper]"
+			continue
+		}
+		for _, name := range splitNames(m[2]) {
+			s.Gates[name] = "commit message [skip gate:" + name + "]"
+		}
+	}
+
+	env = strings.TrimSpace(env)
+	if env == "all" || env == "*" {
+		s.All = SkipEnv
+	} else {
+		for _, name := range splitNames(env) {
+			s.Gates[name] = SkipEnv
+		}
+	}
+	return s
+}
+
+// splitNames splits a comma-separated list of gate names, dropping blanks.
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Empty reports whether nothing is skipped.
+func (s Skips) Empty() bool {
+	return s.All == "" && len(s.Gates) == 0
+}
+
+// Source returns where the skip of the named gate was requested, if it is skipped.
+func (s Skips) Source(name string) (string, bool) {
+	if s.All != "" {
+		return s.All, true
+	}
+	source, ok := s.Gates[name]
+	return source, ok
+}
+
+// Skipped returns the names, of those given, that are skipped, in order.
+func (s Skips) Skipped(names []string) []string {
+	var skipped []string
+	for _, name := range names {
+		if _, ok := s.Source(name); ok && !slices.Contains(skipped, name) {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped
+}
+
+// ApplySkips replaces the skipped gates with gates that report an explicit skip
+// instead of running. gates and cfgs must be parallel slices. The input slice
+// is not modified.
+func ApplySkips(gates []Gate, cfgs []config.Gate, skips Skips) []Gate {
+	if skips.Empty() {
+		return gates
+	}
+	result := make([]Gate, len(gates))
+	for i, g := range gates {
+		result[i] = g
+		if source, ok := skips.Source(cfgs[i].Name); ok {
+			result[i] = NewSkippedGateWithReason(cfgs[i].Name, string(cfgs[i].Type), "requested by "+source)
+		}
+	}
+	return result
+}
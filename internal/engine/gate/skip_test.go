@@ -0,0 +1,73 @@
+package gate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func TestParseSkips(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		env     string
+		gate    string
+		want    string
+		skipped bool
+	}{
+		{name: "nothing", message: "fix: typo [skip ci]", gate: "lint"},
+		{name: "all by message", message: "hotfix: outage [skip gatekeeper]", gate: "lint", want: "commit message [skip gatekeeper]", skipped: true},
+		{name: "case insensitive", message: "[Skip Gatekeeper]", gate: "lint", want: "commit message [skip gatekeeper]", skipped: true},
+		{name: "one gate by message", message: "wip [skip gate:lint]", gate: "lint", want: "commit message [skip gate:lint]", skipped: true},
+		{name: "list by message", message: "wip [skip gate: test, lint]", gate: "lint", want: "commit message [skip gate:lint]", skipped: true},
+		{name: "other gate by message", message: "wip [skip gate:test]", gate: "lint"},
+		{name: "all by env", env: "all", gate: "lint", want: SkipEnv, skipped: true},
+		{name: "list by env", env: "test, lint", gate: "lint", want: SkipEnv, skipped: true},
+		{name: "env wins", message: "[skip gate:lint]", env: "lint", gate: "lint", want: SkipEnv, skipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skips := ParseSkips(tt.message, tt.env)
+			got, ok := skips.Source(tt.gate)
+			if ok != tt.skipped || got != tt.want {
+				t.Errorf("Source(%q) = %q, %v; want %q, %v", tt.gate, got, ok, tt.want, tt.skipped)
+			}
+		})
+	}
+}
+
+func TestParseSkips_Empty(t *testing.T) {
+	if !ParseSkips("feat: add [skip ci]", " ").Empty() {
+		t.Error("expected no skips")
+	}
+	if ParseSkips("", "lint").Empty() {
+		t.Error("expected a skip")
+	}
+}
+
+func TestApplySkips(t *testing.T) {
+	cfgs := []config.Gate{{Name: "lint", Type: config.GateTypeExec}, {Name: "test", Type: config.GateTypeExec}}
+	test := &sleepGate{name: "test"}
+	gates := []Gate{NewSkippedGate("lint", "exec"), test}
+	skips := ParseSkips("", "test")
+
+	got := ApplySkips(gates, cfgs, skips)
+	if got[0] != gates[0] {
+		t.Error("gates that are not skipped must be kept")
+	}
+	result, err := got[1].Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.ran {
+		t.Error("skipped gate must not run")
+	}
+	if !result.Skipped || !result.Passed || result.SkipReason != "requested by GATEKEEPER_SKIP" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if !reflect.DeepEqual(skips.Skipped([]string{"lint", "test", "test"}), []string{"test"}) {
+		t.Errorf("Skipped = %v", skips.Skipped([]string{"lint", "test"}))
+	}
+}
@@ -1,39 +1,155 @@
 package git
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 )
 
+// ChangeType classifies the change a file diff makes.
+type ChangeType string
+
+// Change types of file diffs. A copy is classified as added, with the path it
+// was copied from as the old path.
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeModified ChangeType = "modified"
+	ChangeRenamed  ChangeType = "renamed"
+	ChangeDeleted  ChangeType = "deleted"
+)
+
+// diffPrefix starts the header line of each file in a unified git diff.
+const diffPrefix = "diff --git "
+
 // SplitDiffs splits a unified diff into per-file FileDiff entries.
-// Each entry begins with "diff --git a/..." header.
+// Each entry begins with "diff --git a/..." header, and is classified from its
+// extended header lines (new, deleted, rename, and binary).
 func SplitDiffs(rawDiff string) []FileDiff {
 	if strings.TrimSpace(rawDiff) == "" {
 		return nil
 	}
 
-	const diffPrefix = "diff --git "
-	var diffs []FileDiff
+	// Split at lines starting with "diff --git ". Lines of hunks start with
+	// " ", "+", or "-", so content never starts a file.
+	var blocks []string
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(rawDiff, "\n") {
+		if strings.HasPrefix(line, diffPrefix) && b.Len() > 0 {
+			blocks = append(blocks, b.String())
+			b.Reset()
+		}
+		b.WriteString(line)
+	}
+	blocks = append(blocks, b.String())
 
-	// Split on "diff --git " boundaries
-	parts := strings.Split(rawDiff, diffPrefix)
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
+	var diffs []FileDiff
+	for _, block := range blocks {
+		block = strings.TrimSpace(block)
+		if block == "" {
 			continue
 		}
-
-		// Extract file path from "a/<path> b/<path>" header
-		path := extractFilePath(part)
-
+		if !strings.HasPrefix(block, diffPrefix) {
+			block = diffPrefix + block
+		}
+		h := parseHeader(block)
 		diffs = append(diffs, FileDiff{
-			Path:    path,
-			Content: diffPrefix + part,
+			Path:      h.path,
+			Content:   block,
+			OldPath:   h.oldPath,
+			Change:    h.change,
+			Binary:    h.binary,
+			Submodule: h.submodule,
+			LFS:       isLFSPointerDiff(block),
 		})
 	}
 
 	return diffs
 }
 
+// fileHeader is what the header of a file diff says about the file.
+type fileHeader struct {
+	path, oldPath string
+	change        ChangeType
+	binary        bool
+	submodule     bool
+}
+
+// parseHeader classifies a file diff from its header lines, up to the first
+// hunk. The path comes from "rename to", "+++", or, for deletions, "---" lines,
+// which unlike the "diff --git" line are unambiguous when paths contain spaces.
+func parseHeader(block string) fileHeader {
+	h := fileHeader{change: ChangeModified}
+	var from, to, renamedFrom, renamedTo string
+	for i, line := range strings.Split(block, "\n") {
+		switch {
+		case i == 0:
+			h.path = extractFilePath(strings.TrimPrefix(line, diffPrefix))
+		case strings.HasPrefix(line, "@@ "):
+			return h.resolve(from, to, renamedFrom, renamedTo)
+		case strings.HasPrefix(line, "new file mode "):
+			h.change = ChangeAdded
+			h.submodule = strings.HasSuffix(line, " "+gitlinkMode)
+		case strings.HasPrefix(line, "deleted file mode "):
+			h.change = ChangeDeleted
+			h.submodule = strings.HasSuffix(line, " "+gitlinkMode)
+		case strings.HasPrefix(line, "index "):
+			h.submodule = h.submodule || strings.HasSuffix(line, " "+gitlinkMode)
+		case strings.HasPrefix(line, "rename from "):
+			h.change = ChangeRenamed
+			renamedFrom = unquotePath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			renamedTo = unquotePath(strings.TrimPrefix(line, "rename to "))
+		case strings.HasPrefix(line, "copy from "):
+			h.change = ChangeAdded
+			renamedFrom = unquotePath(strings.TrimPrefix(line, "copy from "))
+		case strings.HasPrefix(line, "copy to "):
+			renamedTo = unquotePath(strings.TrimPrefix(line, "copy to "))
+		case strings.HasPrefix(line, "--- "):
+			from = diffSidePath(strings.TrimPrefix(line, "--- "), "a/")
+		case line == "+++ /dev/null":
+			h.change = ChangeDeleted
+		case strings.HasPrefix(line, "+++ "):
+			to = diffSidePath(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "Binary files "), line == "GIT binary patch":
+			h.binary = true
+		}
+	}
+	return h.resolve(from, to, renamedFrom, renamedTo)
+}
+
+// resolve settles the paths of a header from the paths its lines named.
+func (h fileHeader) resolve(from, to, renamedFrom, renamedTo string) fileHeader {
+	switch {
+	case renamedTo != "":
+		h.path, h.oldPath = renamedTo, renamedFrom
+	case to != "":
+		h.path = to
+	case from != "":
+		h.path = from
+	}
+	return h
+}
+
+// diffSidePath returns the path of a "---" or "+++" line without its a/ or b/
+// prefix, or "" for /dev/null.
+func diffSidePath(path, prefix string) string {
+	path = unquotePath(strings.TrimSuffix(path, "\t"))
+	if path == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// unquotePath decodes a path git quoted because of special characters.
+func unquotePath(path string) string {
+	if strings.HasPrefix(path, `"`) {
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			return unquoted
+		}
+	}
+	return path
+}
+
 // extractFilePath parses the file path from a diff header line.
 // Format: "a/<path> b/<path>\n..."
 func extractFilePath(diffBlock string) string {
@@ -43,6 +159,12 @@ func extractFilePath(diffBlock string) string {
 		firstLine = diffBlock[:idx]
 	}
 
+	// Both sides name the same path unless the file was renamed, so a path
+	// with spaces is the first half of the line.
+	if n := (len(firstLine) - 5) / 2; n > 0 && firstLine == "a/"+firstLine[2:2+n]+" b/"+firstLine[2:2+n] {
+		return firstLine[2 : 2+n]
+	}
+
 	// Parse "a/<path> b/<path>" — take the b/ path (destination)
 	parts := strings.SplitN(firstLine, " ", 2)
 	if len(parts) == 2 {
@@ -60,6 +182,96 @@ func extractFilePath(diffBlock string) string {
 	return parts[0]
 }
 
+// IsDeletion reports whether a file diff deletes the file.
+func IsDeletion(d FileDiff) bool {
+	return d.Change == ChangeDeleted || parseHeader(d.Content).change == ChangeDeleted
+}
+
+// IsBinary reports whether git diffed the file as binary, without hunks.
+func IsBinary(d FileDiff) bool {
+	return d.Binary || parseHeader(d.Content).binary
+}
+
+// HasHunks reports whether a file diff changes lines. Binary files and
+// renames without changes have no hunks.
+func HasHunks(d FileDiff) bool {
+	return strings.Contains(d.Content, "\n@@ ")
+}
+
+// AddedLine is a line added by a file diff.
+type AddedLine struct {
+	// Number is the line's number in the staged file.
+	Number int
+	// Text is the line without its "+" prefix.
+	Text string
+}
+
+// Additions returns the lines a file diff adds, in ascending order.
+func Additions(d FileDiff) []AddedLine {
+	var lines []AddedLine
+	next := 0
+	for _, line := range strings.Split(d.Content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			next = hunkStart(line)
+		case next == 0, strings.HasPrefix(line, "\\"):
+			// Header lines before the first hunk, and "\ No newline at end of file".
+		case strings.HasPrefix(line, "+"):
+			lines = append(lines, AddedLine{Number: next, Text: line[1:]})
+			next++
+		case strings.HasPrefix(line, "-"):
+		default:
+			next++
+		}
+	}
+	return lines
+}
+
+// AddedLines returns the line numbers, in the staged file, of the lines a file
+// diff adds, in ascending order.
+func AddedLines(d FileDiff) []int {
+	var numbers []int
+	for _, line := range Additions(d) {
+		numbers = append(numbers, line.Number)
+	}
+	return numbers
+}
+
+// ChangeCount returns the number of lines a file diff adds and removes.
+func ChangeCount(d FileDiff) (added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(d.Content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// hunkStart returns the first staged line of a hunk from its "@@ -a,b +c,d @@"
+// header, or 0 if it cannot be parsed.
+func hunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 0
+	}
+	start, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return start
+}
+
 // FilterBySize separates diffs by a maximum content size in bytes.
 // Returns included diffs (within limit) and skipped diffs (exceeding limit).
 func FilterBySize(diffs []FileDiff, maxSize int) (included, skipped []FileDiff) {
@@ -78,3 +290,99 @@ func FilterBySize(diffs []FileDiff, maxSize int) (included, skipped []FileDiff)
 
 	return included, skipped
 }
+
+// ChunkDiff splits a diff larger than maxSize into hunk-aligned chunks of at most
+// maxSize bytes. Every chunk repeats the file header so it can be reviewed on its
+// own. A hunk that does not fit in a chunk by itself is cut at a line boundary;
+// truncated describes each cut.
+func ChunkDiff(d FileDiff, maxSize int) (chunks []FileDiff, truncated []string) {
+	if maxSize <= 0 || len(d.Content) <= maxSize {
+		return []FileDiff{d}, nil
+	}
+
+	header, hunks := splitHunks(d.Content)
+	var current []string
+	size := len(header)
+	flush := func() {
+		if len(current) > 0 {
+			chunk := d
+			chunk.Content = header + strings.Join(current, "")
+			chunks = append(chunks, chunk)
+		}
+		current, size = nil, len(header)
+	}
+
+	for _, hunk := range hunks {
+		if size+len(hunk) > maxSize {
+			flush()
+		}
+		if size+len(hunk) > maxSize {
+			var kept, total int
+			hunk, kept, total = cutHunk(hunk, maxSize-len(header))
+			truncated = append(truncated, fmt.Sprintf("%s: %s cut to fit max_file_size (%d of %d lines reviewed)", d.Path, hunkHeader(hunk), kept, total))
+		}
+		current = append(current, hunk)
+		size += len(hunk)
+	}
+	flush()
+	return chunks, truncated
+}
+
+// splitHunks separates a file diff into its header and its "@@" hunks. Each
+// returned part ends with a newline.
+func splitHunks(content string) (header string, hunks []string) {
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	var b strings.Builder
+	inHunks := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.HasPrefix(line, "@@ ") {
+			if inHunks {
+				hunks = append(hunks, b.String())
+			} else {
+				header = b.String()
+			}
+			b.Reset()
+			inHunks = true
+		}
+		b.WriteString(line)
+	}
+	if !inHunks {
+		// No hunks (e.g., a binary or malformed diff): treat the body as one hunk.
+		return "", []string{b.String()}
+	}
+	return header, append(hunks, b.String())
+}
+
+// cutHunk shortens a hunk to whole lines within limit bytes, always keeping its
+// first line. kept and total count the hunk's lines, excluding its "@@" header.
+func cutHunk(hunk string, limit int) (cut string, kept, total int) {
+	lines := strings.SplitAfter(strings.TrimSuffix(hunk, "\n"), "\n")
+	lines[len(lines)-1] += "\n"
+	size, n := len(lines[0]), 1
+	for n < len(lines) && size+len(lines[n]) <= limit {
+		size += len(lines[n])
+		n++
+	}
+
+	kept, total = n, len(lines)
+	if strings.HasPrefix(lines[0], "@@ ") {
+		kept--
+		total--
+	}
+	return strings.Join(lines[:n], ""), kept, total
+}
+
+// hunkHeader returns the "@@ ... @@" range of a hunk, or "content" if it has none.
+func hunkHeader(hunk string) string {
+	if !strings.HasPrefix(hunk, "@@ ") {
+		return "content"
+	}
+	first, _, _ := strings.Cut(hunk, "\n")
+	if end := strings.Index(first[3:], " @@"); end >= 0 {
+		return first[:end+6]
+	}
+	return first
+}
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -50,6 +51,87 @@ func TestSplitDiffs_SingleFile(t *testing.T) {
 	}
 }
 
+func TestSplitDiffs_Classification(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		path    string
+		oldPath string
+		change  ChangeType
+		binary  bool
+	}{
+		{
+			name:   "added",
+			raw:    "diff --git a/new.go b/new.go\nnew file mode 100644\nindex 0000000..1111111\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package main\n",
+			path:   "new.go",
+			change: ChangeAdded,
+		},
+		{
+			name:   "deleted",
+			raw:    "diff --git a/old.go b/old.go\ndeleted file mode 100644\nindex 1111111..0000000\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package main\n",
+			path:   "old.go",
+			change: ChangeDeleted,
+		},
+		{
+			name:    "renamed with changes",
+			raw:     "diff --git a/pkg/a.go b/pkg/b.go\nsimilarity index 90%\nrename from pkg/a.go\nrename to pkg/b.go\nindex 1111111..2222222 100644\n--- a/pkg/a.go\n+++ b/pkg/b.go\n@@ -1 +1,2 @@\n package pkg\n+// moved\n",
+			path:    "pkg/b.go",
+			oldPath: "pkg/a.go",
+			change:  ChangeRenamed,
+		},
+		{
+			name:    "pure rename",
+			raw:     "diff --git a/a.go b/b.go\nsimilarity index 100%\nrename from a.go\nrename to b.go\n",
+			path:    "b.go",
+			oldPath: "a.go",
+			change:  ChangeRenamed,
+		},
+		{
+			name:   "binary",
+			raw:    "diff --git a/logo.png b/logo.png\nindex 1111111..2222222 100644\nBinary files a/logo.png and b/logo.png differ\n",
+			path:   "logo.png",
+			change: ChangeModified,
+			binary: true,
+		},
+		{
+			name:   "path with spaces",
+			raw:    "diff --git a/my file.go b/my file.go\nindex 1111111..2222222 100644\n--- a/my file.go\n+++ b/my file.go\n@@ -1 +1 @@\n-a\n+b\n",
+			path:   "my file.go",
+			change: ChangeModified,
+		},
+		{
+			name:   "quoted path",
+			raw:    "diff --git \"a/caf\\303\\251.go\" \"b/caf\\303\\251.go\"\nindex 1111111..2222222 100644\n--- \"a/caf\\303\\251.go\"\n+++ \"b/caf\\303\\251.go\"\n@@ -1 +1 @@\n-a\n+b\n",
+			path:   "café.go",
+			change: ChangeModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := SplitDiffs(tt.raw)
+			if len(diffs) != 1 {
+				t.Fatalf("expected 1 diff, got %d", len(diffs))
+			}
+			d := diffs[0]
+			if d.Path != tt.path || d.OldPath != tt.oldPath || d.Change != tt.change || d.Binary != tt.binary {
+				t.Errorf("got path %q, old path %q, change %q, binary %v", d.Path, d.OldPath, d.Change, d.Binary)
+			}
+		})
+	}
+}
+
+func TestSplitDiffs_DiffHeaderInContent(t *testing.T) {
+	raw := "diff --git a/notes.md b/notes.md\n--- a/notes.md\n+++ b/notes.md\n@@ -1 +1,2 @@\n # Notes\n+Run diff --git a/x b/x to compare.\ndiff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n"
+
+	diffs := SplitDiffs(raw)
+	if len(diffs) != 2 || diffs[0].Path != "notes.md" || diffs[1].Path != "main.go" {
+		t.Fatalf("expected notes.md and main.go, got %+v", diffs)
+	}
+	if !HasHunks(diffs[0]) {
+		t.Error("expected notes.md to have hunks")
+	}
+}
+
 func TestSplitDiffs_Empty(t *testing.T) {
 	diffs := SplitDiffs("")
 	if len(diffs) != 0 {
@@ -152,3 +234,97 @@ func TestExtractFilePath_SinglePartNoAPrefix(t *testing.T) {
 		t.Errorf("expected 'rawfile.go', got %q", result)
 	}
 }
+
+func TestChunkDiff_HunkAligned(t *testing.T) {
+	header := "diff --git a/big.go b/big.go\n--- a/big.go\n+++ b/big.go\n"
+	hunk1 := "@@ -1,2 +1,3 @@\n package big\n+var a = 1\n"
+	hunk2 := "@@ -10,2 +11,3 @@\n func f() {\n+\treturn\n"
+	hunk3 := "@@ -20,2 +22,3 @@\n func g() {\n+\tpanic(1)\n"
+	d := FileDiff{Path: "big.go", Content: header + hunk1 + hunk2 + hunk3}
+
+	chunks, truncated := ChunkDiff(d, len(header)+len(hunk1)+len(hunk2))
+	if len(truncated) != 0 {
+		t.Errorf("expected no truncation, got %v", truncated)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].Content != header+hunk1+hunk2 {
+		t.Errorf("unexpected first chunk:\n%s", chunks[0].Content)
+	}
+	if chunks[1].Content != header+hunk3 {
+		t.Errorf("expected second chunk to repeat the header, got:\n%s", chunks[1].Content)
+	}
+	for _, c := range chunks {
+		if c.Path != "big.go" {
+			t.Errorf("expected chunk path big.go, got %q", c.Path)
+		}
+	}
+}
+
+func TestChunkDiff_CutsOversizedHunk(t *testing.T) {
+	header := "diff --git a/big.go b/big.go\n"
+	hunk := "@@ -1,0 +1,4 @@\n+line one\n+line two\n+line three\n+line four\n"
+	d := FileDiff{Path: "big.go", Content: header + hunk}
+
+	chunks, truncated := ChunkDiff(d, len(header)+len("@@ -1,0 +1,4 @@\n+line one\n+line two\n"))
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(chunks))
+	}
+	if want := header + "@@ -1,0 +1,4 @@\n+line one\n+line two\n"; chunks[0].Content != want {
+		t.Errorf("expected cut chunk %q, got %q", want, chunks[0].Content)
+	}
+	if len(truncated) != 1 || truncated[0] != "big.go: @@ -1,0 +1,4 @@ cut to fit max_file_size (2 of 4 lines reviewed)" {
+		t.Errorf("unexpected truncation report: %v", truncated)
+	}
+}
+
+func TestChunkDiff_WithinLimit(t *testing.T) {
+	d := FileDiff{Path: "small.go", Content: "diff --git a/small.go b/small.go\n@@ -1 +1 @@\n+x"}
+	chunks, truncated := ChunkDiff(d, 1024)
+	if len(chunks) != 1 || chunks[0] != d || truncated != nil {
+		t.Errorf("expected diff unchanged, got %v %v", chunks, truncated)
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	d := FileDiff{Path: "a.go", Content: "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n" +
+		"@@ -1,4 +1,5 @@\n package a\n-func Old() {}\n+func New() {}\n+func Extra() {}\n \n func Keep() {}\n" +
+		"@@ -20 +21,2 @@\n-x\n+y\n+z\n\\ No newline at end of file\n"}
+
+	got := AddedLines(d)
+	want := []int{2, 3, 21, 22}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("AddedLines = %v, want %v", got, want)
+	}
+
+	created := FileDiff{Path: "new.go", Content: "diff --git a/new.go b/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1,2 @@\n+package a\n+var x = 1\n"}
+	if got := AddedLines(created); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("AddedLines of a new file = %v, want [1 2]", got)
+	}
+}
+
+func TestAdditions(t *testing.T) {
+	d := FileDiff{Path: "a.go", Content: "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -3,2 +3,2 @@\n x := 1\n-y := 2\n+y := 3 // TODO\n"}
+	got := Additions(d)
+	if len(got) != 1 || got[0] != (AddedLine{Number: 4, Text: "y := 3 // TODO"}) {
+		t.Errorf("Additions = %+v", got)
+	}
+}
+
+func TestChangeCount(t *testing.T) {
+	d := FileDiff{Path: "a.go", Content: "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n" +
+		"@@ -1,3 +1,3 @@\n package a\n-func Old() {}\n+func New() {}\n+func Extra() {}\n@@ -20 +21 @@\n--- x\n++++ y\n"}
+	added, removed := ChangeCount(d)
+	if added != 3 || removed != 2 {
+		t.Errorf("ChangeCount = %d, %d; want 3, 2", added, removed)
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	binary := FileDiff{Path: "logo.png", Content: "diff --git a/logo.png b/logo.png\nindex 1..2 100644\nBinary files a/logo.png and b/logo.png differ\n"}
+	text := FileDiff{Path: "a.go", Content: "diff --git a/a.go b/a.go\n@@ -1 +1 @@\n-Binary files\n+x\n"}
+	if !IsBinary(binary) || IsBinary(text) {
+		t.Errorf("IsBinary = %v, %v; want true, false", IsBinary(binary), IsBinary(text))
+	}
+}
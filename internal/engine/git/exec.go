@@ -3,7 +3,9 @@ package git
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -15,6 +17,15 @@ type ExecService struct {
 	// WorkDir is the working directory for git commands.
 	// If empty, the current directory is used.
 	WorkDir string
+	// Untrusted runs every command with UntrustedArgs, for repositories whose
+	// configuration comes from someone else, such as the workspaces of the server.
+	Untrusted bool
+}
+
+// UntrustedArgs are git options that keep a repository's configuration from
+// running commands: no hooks and no fsmonitor command.
+func UntrustedArgs() []string {
+	return []string{"-c", "core.fsmonitor=", "-c", "core.hooksPath=" + os.DevNull}
 }
 
 // NewExecService creates a new ExecService with the given working directory.
@@ -22,11 +33,12 @@ func NewExecService(workDir string) *ExecService {
 	return &ExecService{WorkDir: workDir}
 }
 
-// StagedDiff returns per-file diffs of staged changes.
+// StagedDiff returns per-file diffs of staged changes. Renames are detected
+// whatever the user's diff.renames setting.
 func (s *ExecService) StagedDiff(ctx context.Context) ([]FileDiff, error) {
 	logger.FromContext(ctx).Debug("getting staged diffs")
 
-	out, err := s.runGit(ctx, "diff", "--cached")
+	out, err := s.runGit(ctx, "diff", "--cached", "--find-renames")
 	if err != nil {
 		return nil, fmt.Errorf("getting staged diff: %w", err)
 	}
@@ -51,10 +63,50 @@ func (s *ExecService) StagedFiles(ctx context.Context) ([]string, error) {
 	return strings.Split(out, "\n"), nil
 }
 
+// StagedFileContent returns the staged (post-image) content of a file.
+func (s *ExecService) StagedFileContent(ctx context.Context, path string) (string, error) {
+	out, err := s.runGit(ctx, "show", ":"+path)
+	if err != nil {
+		return "", fmt.Errorf("reading staged %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// CurrentBranch returns the short name of the checked-out branch, or "" on a detached HEAD.
+func (s *ExecService) CurrentBranch(ctx context.Context) (string, error) {
+	logger.FromContext(ctx).Debug("getting current branch")
+
+	// symbolic-ref also works on an unborn branch (before the first commit), unlike rev-parse.
+	out, err := s.command(ctx, "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil // detached HEAD
+		}
+		return "", fmt.Errorf("getting current branch: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// TrackedFileCount returns the number of files tracked by git.
+func (s *ExecService) TrackedFileCount(ctx context.Context) (int, error) {
+	files, err := s.TrackedFiles(ctx)
+	return len(files), err
+}
+
+// TrackedFiles returns the paths of the files tracked in the index, relative
+// to the repository root.
+func (s *ExecService) TrackedFiles(ctx context.Context) ([]string, error) {
+	out, err := s.runGit(ctx, "ls-files", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("listing tracked files: %w", err)
+	}
+	return strings.FieldsFunc(out, func(r rune) bool { return r == 0 }), nil
+}
+
 // runGit executes a git command and returns the combined stdout.
 func (s *ExecService) runGit(ctx context.Context, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", args...) // #nosec G204 -- args are controlled by the application, not user input
-	cmd.Dir = s.WorkDir
+	cmd := s.command(ctx, args...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -66,3 +118,13 @@ func (s *ExecService) runGit(ctx context.Context, args ...string) (string, error
 
 	return stdout.String(), nil
 }
+
+// command returns the git command with args in the working directory.
+func (s *ExecService) command(ctx context.Context, args ...string) *exec.Cmd {
+	if s.Untrusted {
+		args = append(UntrustedArgs(), args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...) // #nosec G204 -- args are controlled by the application, not user input
+	cmd.Dir = s.WorkDir
+	return cmd
+}
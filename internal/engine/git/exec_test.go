@@ -62,6 +62,61 @@ func TestExecService_StagedDiff(t *testing.T) {
 	}
 }
 
+func TestExecService_StagedDiff_ClassifiesChanges(t *testing.T) {
+	dir := setupGitRepo(t)
+	body := "package main\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\n"
+	for name, content := range map[string]string{"old name.go": body, "gone.go": "package main\n", "edit.go": "package main\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, dir, "git", "add", ".")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	run(t, dir, "git", "mv", "old name.go", "new name.go")
+	run(t, dir, "git", "rm", "-q", "gone.go")
+	if err := os.WriteFile(filepath.Join(dir, "edit.go"), []byte("package main\n// edited\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logo.png"), []byte{0x89, 'P', 'N', 'G', 0, 0, 1, 2}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "edit.go", "logo.png")
+
+	diffs, err := NewExecService(dir).StagedDiff(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]FileDiff)
+	for _, d := range diffs {
+		got[d.Path] = d
+	}
+	want := map[string]struct {
+		change  ChangeType
+		oldPath string
+		binary  bool
+	}{
+		"new name.go": {ChangeRenamed, "old name.go", false},
+		"gone.go":     {ChangeDeleted, "", false},
+		"edit.go":     {ChangeModified, "", false},
+		"logo.png":    {ChangeAdded, "", true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d diffs, got %+v", len(want), diffs)
+	}
+	for path, w := range want {
+		d, ok := got[path]
+		if !ok {
+			t.Errorf("missing diff for %q", path)
+			continue
+		}
+		if d.Change != w.change || d.OldPath != w.oldPath || d.Binary != w.binary {
+			t.Errorf("%s: got change %q, old path %q, binary %v", path, d.Change, d.OldPath, d.Binary)
+		}
+	}
+}
+
 func TestExecService_StagedDiff_MultipleStagedFiles(t *testing.T) {
 	dir := setupGitRepo(t)
 
@@ -169,3 +224,97 @@ func TestExecService_StagedFiles_InvalidWorkDir(t *testing.T) {
 		t.Fatal("expected error for invalid work dir, got nil")
 	}
 }
+
+func TestExecService_CurrentBranch(t *testing.T) {
+	dir := setupGitRepo(t)
+	run(t, dir, "git", "checkout", "-q", "-b", "release/1.0")
+
+	svc := NewExecService(dir)
+	branch, err := svc.CurrentBranch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "release/1.0" {
+		t.Errorf("expected 'release/1.0', got %q", branch)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "a.txt")
+	run(t, dir, "git", "commit", "-q", "-m", "init")
+	run(t, dir, "git", "checkout", "-q", "--detach")
+
+	branch, err = svc.CurrentBranch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error on detached HEAD: %v", err)
+	}
+	if branch != "" {
+		t.Errorf("expected empty branch on detached HEAD, got %q", branch)
+	}
+}
+
+func TestExecService_TrackedFileCount(t *testing.T) {
+	dir := setupGitRepo(t)
+	for _, name := range []string{"a.go", "b.go", "c file.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package main\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, dir, "git", "add", ".")
+
+	n, err := NewExecService(dir).TrackedFileCount(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 tracked files, got %d", n)
+	}
+}
+
+func TestExecService_StagedFileContent(t *testing.T) {
+	dir := setupGitRepo(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+	// Unstaged edits are not part of the post-image under review.
+	if err := os.WriteFile(path, []byte("package changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	content, err := svc.StagedFileContent(context.Background(), "main.go")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("expected staged content, got %q", content)
+	}
+
+	if _, err := svc.StagedFileContent(context.Background(), "missing.go"); err == nil {
+		t.Error("expected error for a file that is not staged")
+	}
+}
+
+func TestExecService_UntrustedIgnoresFsmonitor(t *testing.T) {
+	dir := setupGitRepo(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	run(t, dir, "git", "config", "core.fsmonitor", "touch "+marker+"; false")
+	if err := os.WriteFile(filepath.Join(dir, "hello.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	svc.Untrusted = true
+	if _, err := svc.CurrentBranch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.StagedDiff(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the fsmonitor command of the repository ran")
+	}
+}
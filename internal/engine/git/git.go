@@ -7,8 +7,19 @@ import (
 
 // FileDiff holds a per-file diff from staged changes.
 type FileDiff struct {
+	// Path is the file's path after the change, or the deleted file's path.
 	Path    string
 	Content string
+	// OldPath is the path a renamed or copied file had; empty otherwise.
+	OldPath string
+	// Change classifies the change, and Binary is set for binary files,
+	// which have no hunks. Both are set by SplitDiffs.
+	Change ChangeType
+	Binary bool
+	// Submodule is set for a change of the commit a submodule points to, and
+	// LFS for a change of a git-lfs pointer, whose content is stored elsewhere.
+	Submodule bool
+	LFS       bool
 }
 
 // Service abstracts git operations for testability.
@@ -17,17 +28,64 @@ type Service interface {
 	StagedDiff(ctx context.Context) ([]FileDiff, error)
 	// StagedFiles returns the list of staged file paths.
 	StagedFiles(ctx context.Context) ([]string, error)
+	// StagedFileContent returns the staged (post-image) content of a file.
+	StagedFileContent(ctx context.Context, path string) (string, error)
+	// CurrentBranch returns the short name of the checked-out branch, or "" on a detached HEAD.
+	CurrentBranch(ctx context.Context) (string, error)
+	// IndexTree returns the id of the tree object of the staged index.
+	IndexTree(ctx context.Context) (string, error)
 
-	// InstallHook creates a pre-commit hook script in .git/hooks/.
+	// InstallHook creates a pre-commit hook script in the hooks directory (core.hooksPath or .git/hooks).
 	InstallHook(ctx context.Context) error
-	// RemoveHook removes the gatekeeper pre-commit hook.
+	// InstallChainedHook installs a pre-commit hook that runs the existing hook, saved aside, before gatekeeper.
+	InstallChainedHook(ctx context.Context) (chained bool, err error)
+	// RemoveHook removes the gatekeeper pre-commit hook, restoring a hook saved by InstallChainedHook.
 	RemoveHook(ctx context.Context) error
+	// InstallSummaryHook creates a prepare-commit-msg hook that adds a gate summary to the message template.
+	InstallSummaryHook(ctx context.Context) error
+	// RemoveSummaryHook removes the gatekeeper prepare-commit-msg hook, if any.
+	RemoveSummaryHook(ctx context.Context) error
+	// InstallMessageHook creates a commit-msg hook that runs gatekeeper with the commit message.
+	InstallMessageHook(ctx context.Context) error
+	// RemoveMessageHook removes the gatekeeper commit-msg hook, if any.
+	RemoveMessageHook(ctx context.Context) error
 
 	// Stash saves unstaged/untracked changes so only staged changes remain.
-	// Returns true if a stash was actually created (i.e., there was something to stash).
-	Stash(ctx context.Context) (bool, error)
-	// StashPop restores previously stashed changes.
-	StashPop(ctx context.Context) error
-	// CleanWritableFiles reverts writable gate modifications in the working tree.
-	CleanWritableFiles(ctx context.Context) error
+	// Returns the ID of the stash, or "" if there was nothing to stash.
+	Stash(ctx context.Context) (string, error)
+	// StashPop restores the stash with the given ID.
+	StashPop(ctx context.Context, id string) error
+	// Stashes lists the stashes gatekeeper created, newest first.
+	Stashes(ctx context.Context) ([]StashEntry, error)
+	// WorktreeState records the working tree before writable gates run on the host.
+	WorktreeState(ctx context.Context) (*WorktreeState, error)
+	// CleanWritableFiles reverts the writable gate modifications in the
+	// working tree made since before was recorded.
+	CleanWritableFiles(ctx context.Context, before *WorktreeState) error
+	// RestageWritableFiles stages writable gate modifications to the given
+	// (staged) paths and returns the paths it re-staged.
+	RestageWritableFiles(ctx context.Context, paths []string) ([]string, error)
+
+	// StagedSubmodules returns the staged changes of submodule commits.
+	StagedSubmodules(ctx context.Context) ([]SubmoduleChange, error)
+	// SubmoduleFiles returns the files a submodule change changes, relative
+	// to the project root.
+	SubmoduleFiles(ctx context.Context, c SubmoduleChange) ([]string, error)
+	// LFSObject reads the content of an LFS pointer from the local LFS store.
+	LFSObject(ctx context.Context, p LFSPointer) (string, error)
+
+	// SnapshotIndex checks the staged index out into a directory outside the
+	// working tree and returns its path.
+	SnapshotIndex(ctx context.Context) (string, error)
+	// CleanSnapshot removes the contents of the index snapshot.
+	CleanSnapshot(ctx context.Context) error
+
+	// WritableOverlay checks the staged index out into a directory outside the
+	// working tree for writable gates to modify, and returns its path.
+	WritableOverlay(ctx context.Context) (string, error)
+	// ApplyOverlay stages and writes to the working tree the overlay
+	// modifications to the given (staged) paths, and returns the paths it applied.
+	ApplyOverlay(ctx context.Context, dir string, paths []string) ([]string, error)
+	// CleanOverlay removes the contents of the writable overlay.
+	CleanOverlay(ctx context.Context) error
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
 )
 
@@ -18,31 +20,162 @@ const (
 # This hook was installed by gatekeeper. Do not edit manually.
 # Run 'gatekeeper teardown' to remove.
 exec gatekeeper run "$@"
+`
+	summaryHookScript = `#!/bin/sh
+# gatekeeper-managed
+# This hook was installed by gatekeeper. Do not edit manually.
+# Run 'gatekeeper teardown' to remove.
+exec gatekeeper commit-summary "$@"
+`
+	messageHookScript = `#!/bin/sh
+# gatekeeper-managed
+# This hook was installed by gatekeeper. Do not edit manually.
+# Run 'gatekeeper teardown' to remove.
+exec gatekeeper run --message-file "$1"
+`
+	// savedHookSuffix names the backup of a hook that a chained hook wraps.
+	savedHookSuffix   = ".gatekeeper-saved"
+	chainedHookScript = `#!/bin/sh
+# gatekeeper-managed
+# This hook was installed by gatekeeper. Do not edit manually.
+# It runs the previous pre-commit hook, saved as pre-commit.gatekeeper-saved,
+# then gatekeeper. Run 'gatekeeper teardown' to restore the previous hook.
+saved="$(dirname "$0")/pre-commit.gatekeeper-saved"
+if [ -x "$saved" ]; then
+	"$saved" "$@" || exit $?
+fi
+exec gatekeeper run "$@"
 `
 )
 
+// goos is a variable to allow testing Windows code paths on any platform.
+var goos = runtime.GOOS
+
+// executable is a variable for testability (defaults to os.Executable).
+var executable = os.Executable
+
+// ErrHookExists is returned when installing a hook over one that gatekeeper
+// does not manage.
+var ErrHookExists = errors.New("hook already exists")
+
 // InstallHook creates a pre-commit hook that invokes gatekeeper.
 // If the hook already exists and is not managed by gatekeeper, it returns an error.
 func (s *ExecService) InstallHook(ctx context.Context) error {
+	return s.installHook(ctx, "pre-commit", hookScript)
+}
+
+// InstallChainedHook creates a pre-commit hook that runs the existing one,
+// if any, and then gatekeeper. The existing hook is moved to
+// pre-commit.gatekeeper-saved, which RemoveHook restores. chained is false if
+// there was no hook to save.
+func (s *ExecService) InstallChainedHook(ctx context.Context) (chained bool, err error) {
 	log := logger.FromContext(ctx)
-	log.Info("installing pre-commit hook")
 
-	gitDir, err := s.findGitDir(ctx)
+	hooksDir, err := s.findHooksDir(ctx)
 	if err != nil {
-		return fmt.Errorf("finding .git directory: %w", err)
+		return false, fmt.Errorf("finding hooks directory: %w", err)
 	}
-
-	hooksDir := filepath.Join(gitDir, "hooks")
 	hookPath := filepath.Join(hooksDir, "pre-commit")
+	savedPath := hookPath + savedHookSuffix
+
+	data, err := os.ReadFile(hookPath) // #nosec G304 -- path is the repository's hooks dir, not user input
+	if errors.Is(err, os.ErrNotExist) || (err == nil && strings.Contains(string(data), hookMarker)) {
+		return false, s.installHook(ctx, "pre-commit", hookScript)
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading hook: %w", err)
+	}
+	if _, err := os.Lstat(savedPath); err == nil {
+		return false, fmt.Errorf("%s already exists — restore or remove it before chaining the pre-commit hook", savedPath)
+	}
+
+	if err := os.Rename(hookPath, savedPath); err != nil {
+		return false, fmt.Errorf("saving existing hook: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(hookPath, []byte(platformScript(chainedHookScript)), 0o755); err != nil {
+		_ = os.Rename(savedPath, hookPath)
+		return false, fmt.Errorf("writing hook script: %w", err)
+	}
+
+	log.Info("hook installed, chained to the existing hook", "hook", "pre-commit", "path", hookPath, "saved", savedPath)
+	return true, nil
+}
+
+// RemoveHook removes the gatekeeper-managed pre-commit hook, restoring the
+// hook saved by InstallChainedHook, if any.
+// Returns nil if no hook exists; returns ErrForeignHook if the hook is not managed by gatekeeper.
+func (s *ExecService) RemoveHook(ctx context.Context) error {
+	removed, err := s.removeHook(ctx, "pre-commit")
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrForeignHook
+	}
+	return nil
+}
+
+// InstallSummaryHook creates a prepare-commit-msg hook that appends a commented
+// gate summary to the commit message template.
+// If the hook already exists and is not managed by gatekeeper, it returns an error.
+func (s *ExecService) InstallSummaryHook(ctx context.Context) error {
+	return s.installHook(ctx, "prepare-commit-msg", summaryHookScript)
+}
+
+// RemoveSummaryHook removes the gatekeeper-managed prepare-commit-msg hook.
+// A missing or foreign hook is left alone.
+func (s *ExecService) RemoveSummaryHook(ctx context.Context) error {
+	removed, err := s.removeHook(ctx, "prepare-commit-msg")
+	if err != nil {
+		return err
+	}
+	if !removed {
+		logger.FromContext(ctx).Info("prepare-commit-msg hook is not managed by gatekeeper, leaving it")
+	}
+	return nil
+}
+
+// InstallMessageHook creates a commit-msg hook that runs gatekeeper on the
+// commit message, so [skip gatekeeper] markers apply. It replaces the
+// pre-commit hook: gates that failed before commit-msg would block the commit
+// regardless of the message.
+// If the hook already exists and is not managed by gatekeeper, it returns an error.
+func (s *ExecService) InstallMessageHook(ctx context.Context) error {
+	return s.installHook(ctx, "commit-msg", messageHookScript)
+}
+
+// RemoveMessageHook removes the gatekeeper-managed commit-msg hook.
+// A missing or foreign hook is left alone.
+func (s *ExecService) RemoveMessageHook(ctx context.Context) error {
+	removed, err := s.removeHook(ctx, "commit-msg")
+	if err != nil {
+		return err
+	}
+	if !removed {
+		logger.FromContext(ctx).Info("commit-msg hook is not managed by gatekeeper, leaving it")
+	}
+	return nil
+}
+
+// installHook writes a gatekeeper-managed hook script to <hooks dir>/<name>.
+func (s *ExecService) installHook(ctx context.Context, name, script string) error {
+	log := logger.FromContext(ctx)
+	log.Info("installing hook", "hook", name)
+
+	hooksDir, err := s.findHooksDir(ctx)
+	if err != nil {
+		return fmt.Errorf("finding hooks directory: %w", err)
+	}
+	hookPath := filepath.Join(hooksDir, name)
 
 	// Check if hook already exists.
-	if data, err := os.ReadFile(hookPath); err == nil { // #nosec G304 -- path is constructed from .git dir, not user input
+	if data, err := os.ReadFile(hookPath); err == nil { // #nosec G304 -- path is the repository's hooks dir, not user input
 		content := string(data)
 		if strings.Contains(content, hookMarker) {
-			log.Info("hook already installed, skipping")
+			log.Info("hook already installed, skipping", "hook", name)
 			return nil // Already managed by gatekeeper.
 		}
-		return fmt.Errorf("pre-commit hook already exists at %s — remove it first or back it up", hookPath)
+		return fmt.Errorf("%w: %s — remove it first or back it up", ErrHookExists, hookPath)
 	}
 
 	// Create hooks directory if it doesn't exist.
@@ -51,47 +184,99 @@ func (s *ExecService) InstallHook(ctx context.Context) error {
 	}
 
 	// Write hook script.
-	if err := os.WriteFile(hookPath, []byte(hookScript), 0o755); err != nil { // #nosec G306 -- hook must be executable
+	if err := fileutil.WriteFileAtomic(hookPath, []byte(platformScript(script)), 0o755); err != nil {
 		return fmt.Errorf("writing hook script: %w", err)
 	}
 
-	log.Info("pre-commit hook installed", "path", hookPath)
+	log.Info("hook installed", "hook", name, "path", hookPath)
 	return nil
 }
 
-// RemoveHook removes the gatekeeper-managed pre-commit hook.
-// Returns nil if no hook exists or the hook is not managed by gatekeeper.
-func (s *ExecService) RemoveHook(ctx context.Context) error {
+// platformScript returns the variant of a hook script for the platform. Git for
+// Windows runs the same sh scripts with its bundled shell, but GUI clients and
+// IDEs often start git without the user's PATH, so on Windows the script falls
+// back to the gatekeeper binary that installed it.
+func platformScript(script string) string {
+	if goos != "windows" {
+		return script
+	}
+	exe, err := executable()
+	if err != nil {
+		return script
+	}
+	exe = strings.ReplaceAll(exe, `\`, "/")
+	fallback := "gatekeeper=gatekeeper\ncommand -v gatekeeper >/dev/null 2>&1 || gatekeeper='" + strings.ReplaceAll(exe, "'", `'\''`) + "'\n"
+	return strings.Replace(script, "exec gatekeeper ", fallback+`exec "$gatekeeper" `, 1)
+}
+
+// removeHook deletes <hooks dir>/<name> if gatekeeper manages it, and moves
+// back the hook it was chained to. removed is false if the hook exists but is not managed by gatekeeper; a
+// missing hook counts as removed.
+func (s *ExecService) removeHook(ctx context.Context, name string) (removed bool, err error) {
 	log := logger.FromContext(ctx)
-	log.Info("removing pre-commit hook")
+	log.Info("removing hook", "hook", name)
 
-	gitDir, err := s.findGitDir(ctx)
+	hooksDir, err := s.findHooksDir(ctx)
 	if err != nil {
-		return fmt.Errorf("finding .git directory: %w", err)
+		return false, fmt.Errorf("finding hooks directory: %w", err)
 	}
 
-	hookPath := filepath.Join(gitDir, "hooks", "pre-commit")
+	hookPath := filepath.Join(hooksDir, name)
 
-	data, err := os.ReadFile(hookPath) // #nosec G304 -- path is constructed from .git dir, not user input
+	data, err := os.ReadFile(hookPath) // #nosec G304 -- path is the repository's hooks dir, not user input
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			log.Info("no pre-commit hook found, nothing to remove")
-			return nil
+			log.Info("no hook found, nothing to remove", "hook", name)
+			return true, nil
 		}
-		return fmt.Errorf("reading hook: %w", err)
+		return false, fmt.Errorf("reading hook: %w", err)
 	}
 
 	// Only remove if it's managed by gatekeeper.
 	if !strings.Contains(string(data), hookMarker) {
-		return fmt.Errorf("pre-commit hook exists but is not managed by gatekeeper — will not remove")
+		return false, nil
 	}
 
 	if err := os.Remove(hookPath); err != nil {
-		return fmt.Errorf("removing hook: %w", err)
+		return false, fmt.Errorf("removing hook: %w", err)
+	}
+	savedPath := hookPath + savedHookSuffix
+	if _, err := os.Lstat(savedPath); err == nil {
+		if err := os.Rename(savedPath, hookPath); err != nil {
+			return false, fmt.Errorf("restoring saved hook: %w", err)
+		}
+		log.Info("saved hook restored", "hook", name, "path", hookPath)
 	}
 
-	log.Info("pre-commit hook removed", "path", hookPath)
-	return nil
+	log.Info("hook removed", "hook", name, "path", hookPath)
+	return true, nil
+}
+
+// CommentChar returns the character git uses for comment lines in commit messages
+// (core.commentChar, "#" by default).
+func (s *ExecService) CommentChar(ctx context.Context) string {
+	out, err := s.runGit(ctx, "config", "--get", "core.commentChar")
+	if err != nil || strings.TrimSpace(out) == "" {
+		return "#"
+	}
+	return strings.TrimSpace(out)
+}
+
+// findHooksDir locates the directory git runs hooks from, with `git rev-parse
+// --git-path hooks`: core.hooksPath if set, else the hooks directory of the
+// main repository, which linked worktrees share.
+func (s *ExecService) findHooksDir(ctx context.Context) (string, error) {
+	out, err := s.runGit(ctx, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+
+	hooksDir := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooksDir) && s.WorkDir != "" {
+		hooksDir = filepath.Join(s.WorkDir, hooksDir)
+	}
+
+	return hooksDir, nil
 }
 
 // findGitDir locates the .git directory by running `git rev-parse --git-dir`.
@@ -2,8 +2,10 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -63,8 +65,154 @@ func TestInstallHook_ConflictsWithExistingHook(t *testing.T) {
 	}
 
 	err := svc.InstallHook(context.Background())
-	if err == nil {
-		t.Fatal("expected error when existing non-gatekeeper hook present")
+	if !errors.Is(err, ErrHookExists) {
+		t.Fatalf("expected ErrHookExists when existing non-gatekeeper hook present, got %v", err)
+	}
+}
+
+func TestInstallChainedHook(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	ctx := context.Background()
+
+	hooksDir := filepath.Join(dir, ".git", "hooks")
+	if err := os.MkdirAll(hooksDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	hookPath := filepath.Join(hooksDir, "pre-commit")
+	original := "#!/bin/sh\necho custom >> \"$LOG\"\n"
+	if err := os.WriteFile(hookPath, []byte(original), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	chained, err := svc.InstallChainedHook(ctx)
+	if err != nil || !chained {
+		t.Fatalf("InstallChainedHook = %v, %v; want true, nil", chained, err)
+	}
+	if data, _ := os.ReadFile(hookPath + savedHookSuffix); string(data) != original {
+		t.Errorf("existing hook not saved, got %q", data)
+	}
+	if chained, err := svc.InstallChainedHook(ctx); err != nil || chained {
+		t.Errorf("second InstallChainedHook = %v, %v; want false, nil", chained, err)
+	}
+
+	// The wrapper runs the saved hook, then gatekeeper.
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "gatekeeper"), []byte("#!/bin/sh\necho gatekeeper >> \"$LOG\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(t.TempDir(), "log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("LOG", logPath)
+	run(t, dir, hookPath)
+	if data, _ := os.ReadFile(logPath); string(data) != "custom\ngatekeeper\n" {
+		t.Errorf("unexpected hook runs: %q", data)
+	}
+
+	if err := svc.RemoveHook(ctx); err != nil {
+		t.Fatalf("RemoveHook: %v", err)
+	}
+	if data, _ := os.ReadFile(hookPath); string(data) != original {
+		t.Errorf("original hook not restored, got %q", data)
+	}
+	if _, err := os.Stat(hookPath + savedHookSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected saved hook to be moved back, stat err = %v", err)
+	}
+}
+
+func TestInstallChainedHook_NoExistingHook(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+
+	chained, err := svc.InstallChainedHook(context.Background())
+	if err != nil || chained {
+		t.Fatalf("InstallChainedHook = %v, %v; want false, nil", chained, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "hooks", "pre-commit"))
+	if err != nil || string(data) != hookScript {
+		t.Errorf("expected the plain gatekeeper hook, got %q (%v)", data, err)
+	}
+}
+
+func TestInstallHook_CoreHooksPath(t *testing.T) {
+	dir := setupGitRepo(t)
+	run(t, dir, "git", "config", "core.hooksPath", ".githooks")
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	// Run from a subdirectory: the relative hooks path is resolved against the repository root.
+	svc := NewExecService(filepath.Join(dir, "src"))
+
+	if err := svc.InstallHook(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hookPath := filepath.Join(dir, ".githooks", "pre-commit")
+	if data, err := os.ReadFile(hookPath); err != nil || !strings.Contains(string(data), hookMarker) {
+		t.Fatalf("expected the hook in core.hooksPath: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", "hooks", "pre-commit")); !os.IsNotExist(err) {
+		t.Error("the hook must not be written to .git/hooks")
+	}
+
+	if err := svc.RemoveHook(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("expected the hook to be removed from core.hooksPath")
+	}
+}
+
+func TestInstallHook_LinkedWorktree(t *testing.T) {
+	dir := setupGitRepo(t)
+	run(t, dir, "git", "commit", "--allow-empty", "-m", "initial")
+	worktree := filepath.Join(t.TempDir(), "feature")
+	run(t, dir, "git", "worktree", "add", "-b", "feature", worktree)
+
+	// In a linked worktree .git is a file, and hooks are shared with the main repository.
+	svc := NewExecService(worktree)
+	if err := svc.InstallHook(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hookPath := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	if _, err := os.Stat(hookPath); err != nil {
+		t.Fatalf("expected the hook in the main repository's hooks: %v", err)
+	}
+
+	if err := NewExecService(dir).InstallHook(context.Background()); err != nil {
+		t.Errorf("the main worktree must see the hook as installed: %v", err)
+	}
+	if err := svc.RemoveHook(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("expected the shared hook to be removed")
+	}
+}
+
+func TestInstallHook_Windows(t *testing.T) {
+	originalOS, originalExe := goos, executable
+	defer func() { goos, executable = originalOS, originalExe }()
+	goos = "windows"
+	executable = func() (string, error) { return `C:\Users\me\bin\gatekeeper.exe`, nil }
+
+	dir := setupGitRepo(t)
+	if err := NewExecService(dir).InstallHook(context.Background()); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "hooks", "pre-commit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(data)
+	for _, want := range []string{
+		"#!/bin/sh\n",
+		hookMarker,
+		"command -v gatekeeper >/dev/null 2>&1 || gatekeeper='C:/Users/me/bin/gatekeeper.exe'\n",
+		`exec "$gatekeeper" run "$@"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q in the Windows hook, got:\n%s", want, script)
+		}
 	}
 }
 
@@ -117,3 +265,77 @@ func TestRemoveHook_NonGatekeeperHook(t *testing.T) {
 		t.Fatal("expected error when removing non-gatekeeper hook")
 	}
 }
+
+func TestSummaryHook_InstallAndRemove(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	hookPath := filepath.Join(dir, ".git", "hooks", "prepare-commit-msg")
+
+	if err := svc.InstallSummaryHook(context.Background()); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	data, err := os.ReadFile(hookPath)
+	if err != nil || !strings.Contains(string(data), "gatekeeper commit-summary") {
+		t.Fatalf("expected summary hook script, got %q (%v)", data, err)
+	}
+
+	if err := svc.RemoveSummaryHook(context.Background()); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("expected hook to be removed")
+	}
+}
+
+func TestMessageHook_InstallAndRemove(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	hookPath := filepath.Join(dir, ".git", "hooks", "commit-msg")
+
+	if err := svc.InstallMessageHook(context.Background()); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	data, err := os.ReadFile(hookPath)
+	if err != nil || !strings.Contains(string(data), `gatekeeper run --message-file "$1"`) {
+		t.Fatalf("expected commit-msg hook script, got %q (%v)", data, err)
+	}
+
+	if err := svc.RemoveMessageHook(context.Background()); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := os.Stat(hookPath); !os.IsNotExist(err) {
+		t.Error("expected hook to be removed")
+	}
+}
+
+func TestRemoveSummaryHook_LeavesForeignHook(t *testing.T) {
+	dir := setupGitRepo(t)
+	hookPath := filepath.Join(dir, ".git", "hooks", "prepare-commit-msg")
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hookPath, []byte("#!/bin/sh\necho custom\n"), 0o755); err != nil { // #nosec G306 -- test hook
+		t.Fatal(err)
+	}
+
+	if err := NewExecService(dir).RemoveSummaryHook(context.Background()); err != nil {
+		t.Fatalf("expected foreign hook to be left alone without error, got %v", err)
+	}
+	if _, err := os.Stat(hookPath); err != nil {
+		t.Errorf("expected foreign hook to remain: %v", err)
+	}
+}
+
+func TestCommentChar(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	if got := svc.CommentChar(context.Background()); got != "#" {
+		t.Errorf("expected default comment char, got %q", got)
+	}
+	if _, err := svc.runGit(context.Background(), "config", "core.commentChar", ";"); err != nil {
+		t.Fatal(err)
+	}
+	if got := svc.CommentChar(context.Background()); got != ";" {
+		t.Errorf("expected configured comment char, got %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lfsSpec is the first line of every git-lfs pointer file.
+const lfsSpec = "version https://git-lfs.github.com/spec/v1"
+
+// ErrLFSObjectMissing is returned by LFSObject when the object of a pointer is
+// not in the local LFS store.
+var ErrLFSObjectMissing = errors.New("LFS object not in the local store")
+
+// LFSPointer is a git-lfs pointer file, which git stores in place of the
+// content of a file tracked by LFS.
+type LFSPointer struct {
+	// OID is the SHA-256 of the content.
+	OID string
+	// Size is the size of the content in bytes.
+	Size int64
+}
+
+// ParseLFSPointer parses the content of a git-lfs pointer file. ok is false
+// if content is not one.
+func ParseLFSPointer(content string) (p LFSPointer, ok bool) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) < 3 || lines[0] != lfsSpec {
+		return LFSPointer{}, false
+	}
+	for _, line := range lines[1:] {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			p.OID = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return LFSPointer{}, false
+			}
+			p.Size = size
+		}
+	}
+	if _, err := hex.DecodeString(p.OID); err != nil || len(p.OID) != 64 {
+		return LFSPointer{}, false
+	}
+	return p, true
+}
+
+// isLFSPointerDiff reports whether a file diff changes a git-lfs pointer
+// rather than the file's content.
+func isLFSPointerDiff(block string) bool {
+	for _, prefix := range []string{"\n+", "\n-", "\n "} {
+		if strings.Contains(block, prefix+lfsSpec) {
+			return true
+		}
+	}
+	return false
+}
+
+// LFSObject reads the content of an LFS pointer from the local LFS store,
+// where git-lfs puts the content of the files it stages.
+func (s *ExecService) LFSObject(ctx context.Context, p LFSPointer) (string, error) {
+	out, err := s.runGit(ctx, "rev-parse", "--git-path", "lfs/objects")
+	if err != nil {
+		return "", fmt.Errorf("finding LFS store: %w", err)
+	}
+	store := strings.TrimSpace(out)
+	if !filepath.IsAbs(store) && s.WorkDir != "" {
+		store = filepath.Join(s.WorkDir, store)
+	}
+
+	data, err := os.ReadFile(filepath.Join(store, p.OID[:2], p.OID[2:4], p.OID)) // #nosec G304 -- the OID is validated by ParseLFSPointer
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrLFSObjectMissing, p.OID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading LFS object %s: %w", p.OID, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOID = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+const testPointer = "version https://git-lfs.github.com/spec/v1\noid sha256:" + testOID + "\nsize 12\n"
+
+func TestParseLFSPointer(t *testing.T) {
+	p, ok := ParseLFSPointer(testPointer)
+	if !ok || p.OID != testOID || p.Size != 12 {
+		t.Errorf("ParseLFSPointer = %+v, %v", p, ok)
+	}
+
+	for _, content := range []string{
+		"package main\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:../../etc/passwd\nsize 12\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + testOID + "\nsize twelve\n",
+	} {
+		if _, ok := ParseLFSPointer(content); ok {
+			t.Errorf("expected %q not to parse as a pointer", content)
+		}
+	}
+}
+
+func TestSplitDiffs_LFSPointer(t *testing.T) {
+	raw := "diff --git a/data.bin b/data.bin\nnew file mode 100644\n--- /dev/null\n+++ b/data.bin\n@@ -0,0 +1,3 @@\n+" + strings.ReplaceAll(strings.TrimSuffix(testPointer, "\n"), "\n", "\n+") + "\n"
+	diffs := SplitDiffs(raw)
+	if len(diffs) != 1 || !diffs[0].LFS {
+		t.Errorf("expected an LFS pointer diff, got %+v", diffs)
+	}
+	if SplitDiffs("diff --git a/a.go b/a.go\n@@ -1 +1 @@\n-a\n+b\n")[0].LFS {
+		t.Error("expected an ordinary diff not to be an LFS pointer")
+	}
+}
+
+func TestLFSObject(t *testing.T) {
+	dir := setupGitRepo(t)
+	store := filepath.Join(dir, ".git", "lfs", "objects", testOID[:2], testOID[2:4])
+	if err := os.MkdirAll(store, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store, testOID), []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	p, _ := ParseLFSPointer(testPointer)
+	content, err := svc.LFSObject(context.Background(), p)
+	if err != nil || content != "hello world\n" {
+		t.Errorf("LFSObject = %q, %v", content, err)
+	}
+
+	p.OID = strings.Repeat("0", 64)
+	if _, err := svc.LFSObject(context.Background(), p); !errors.Is(err, ErrLFSObjectMissing) {
+		t.Errorf("expected ErrLFSObjectMissing, got %v", err)
+	}
+}
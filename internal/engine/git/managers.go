@@ -0,0 +1,318 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// Hook managers that gatekeeper can be added to instead of installing its own
+// pre-commit hook.
+const (
+	HookManagerHusky     = "husky"
+	HookManagerPreCommit = "pre-commit"
+	HookManagerLefthook  = "lefthook"
+)
+
+// Markers around the lines gatekeeper adds to a hook manager's config, so
+// removing them restores the file exactly.
+const (
+	blockBegin = "# >>> gatekeeper"
+	blockEnd   = "# <<< gatekeeper"
+)
+
+// ErrForeignHook is returned by RemoveHook when the pre-commit hook belongs to
+// something else, such as a hook manager.
+var ErrForeignHook = errors.New("pre-commit hook exists but is not managed by gatekeeper — will not remove")
+
+// HookManager is a hook manager used by a project, such as husky.
+type HookManager struct {
+	Name string
+	// Config is the file gatekeeper is added to, relative to the project directory.
+	Config string
+	// Activate is the command that installs the manager's own git hooks.
+	Activate string
+}
+
+// lefthookConfigs are the config file names lefthook reads, in its order.
+var lefthookConfigs = []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"}
+
+// DetectHookManagers returns the hook managers configured in dir: a .husky/
+// directory, a .pre-commit-config.yaml, or a lefthook.yml.
+func DetectHookManagers(dir string) []HookManager {
+	var managers []HookManager
+	if info, err := os.Stat(filepath.Join(dir, ".husky")); err == nil && info.IsDir() {
+		managers = append(managers, HookManager{Name: HookManagerHusky, Config: filepath.Join(".husky", "pre-commit"), Activate: "npx husky"})
+	}
+	if fileExists(filepath.Join(dir, ".pre-commit-config.yaml")) {
+		managers = append(managers, HookManager{Name: HookManagerPreCommit, Config: ".pre-commit-config.yaml", Activate: "pre-commit install"})
+	}
+	for _, name := range lefthookConfigs {
+		if fileExists(filepath.Join(dir, name)) {
+			managers = append(managers, HookManager{Name: HookManagerLefthook, Config: name, Activate: "lefthook install"})
+			break
+		}
+	}
+	return managers
+}
+
+// FindHookManager returns the manager called name among managers.
+func FindHookManager(managers []HookManager, name string) (HookManager, bool) {
+	for _, m := range managers {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return HookManager{}, false
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// Install adds a gatekeeper run to the manager's pre-commit hook in dir.
+// added is false if it is already there.
+func (m HookManager) Install(dir string) (added bool, err error) {
+	path := filepath.Join(dir, m.Config)
+	data, readErr := os.ReadFile(path) // #nosec G304 -- path is a known hook manager config in the project
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return false, fmt.Errorf("reading %s: %w", m.Config, readErr)
+	}
+	content := string(data)
+	if hasBlock(content) {
+		return false, nil
+	}
+
+	var updated string
+	perm := os.FileMode(0o644)
+	switch m.Name {
+	case HookManagerHusky:
+		perm = 0o755
+		if data == nil {
+			updated = huskyHook(dir)
+		} else {
+			updated = appendBlock(content, []string{blockBegin, "gatekeeper run", blockEnd})
+		}
+	case HookManagerPreCommit:
+		updated, err = addPreCommitRepo(content)
+	case HookManagerLefthook:
+		updated, err = addLefthookCommand(content)
+	default:
+		return false, fmt.Errorf("unknown hook manager %q", m.Name)
+	}
+	if err != nil {
+		return false, fmt.Errorf("adding gatekeeper to %s: %w", m.Config, err)
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(updated), perm); err != nil {
+		return false, fmt.Errorf("writing %s: %w", m.Config, err)
+	}
+	return true, nil
+}
+
+// Remove undoes Install: it deletes the lines gatekeeper added to the
+// manager's config, or the whole hook file if gatekeeper created it. removed
+// is false if there was nothing to remove.
+func (m HookManager) Remove(dir string) (removed bool, err error) {
+	path := filepath.Join(dir, m.Config)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is a known hook manager config in the project
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading %s: %w", m.Config, err)
+	}
+	content := string(data)
+
+	if m.Name == HookManagerHusky && hasLine(content, hookMarker) {
+		if err := os.Remove(path); err != nil {
+			return false, fmt.Errorf("removing %s: %w", m.Config, err)
+		}
+		return true, nil
+	}
+	if !hasBlock(content) {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if err := fileutil.WriteFileAtomic(path, []byte(removeBlock(content)), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("writing %s: %w", m.Config, err)
+	}
+	return true, nil
+}
+
+// huskyHook returns a new husky pre-commit hook running gatekeeper. Husky v8
+// hooks must source its husky.sh; v9 hooks are plain commands.
+func huskyHook(dir string) string {
+	hook := "# gatekeeper-managed\n# This hook was added by gatekeeper init. Run 'gatekeeper teardown' to remove.\ngatekeeper run\n"
+	if fileExists(filepath.Join(dir, ".husky", "_", "husky.sh")) && !fileExists(filepath.Join(dir, ".husky", "_", "h")) {
+		hook = "#!/usr/bin/env sh\n. \"$(dirname -- \"$0\")/_/husky.sh\"\n\n" + hook
+	}
+	return hook
+}
+
+// addPreCommitRepo adds a local gatekeeper hook as the last entry of the
+// repos list of a .pre-commit-config.yaml.
+func addPreCommitRepo(content string) (string, error) {
+	lines := strings.Split(content, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.TrimRight(line, " ") == "repos:" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", errors.New("no block-style repos: list found; add the gatekeeper hook by hand")
+	}
+	end, indent := yamlBlockEnd(lines, start, 0)
+	if indent < 0 {
+		// An empty list: "repos:" followed by nothing.
+		indent = 0
+	}
+	pad := strings.Repeat(" ", indent)
+	block := []string{
+		pad + blockBegin,
+		pad + "- repo: local",
+		pad + "  hooks:",
+		pad + "    - id: gatekeeper",
+		pad + "      name: gatekeeper",
+		pad + "      entry: gatekeeper run",
+		pad + "      language: system",
+		pad + "      pass_filenames: false",
+		pad + "      always_run: true",
+		pad + "      stages: [pre-commit]",
+		pad + blockEnd,
+	}
+	return insertLines(lines, end, block), nil
+}
+
+// addLefthookCommand adds a gatekeeper command to the pre-commit hook of a
+// lefthook config, adding the hook if it has none.
+func addLefthookCommand(content string) (string, error) {
+	lines := strings.Split(content, "\n")
+	hook := -1
+	for i, line := range lines {
+		if strings.TrimRight(line, " ") == "pre-commit:" {
+			hook = i
+			break
+		}
+	}
+	if hook < 0 {
+		block := []string{blockBegin, "pre-commit:", "  commands:", "    gatekeeper:", "      run: gatekeeper run", blockEnd}
+		return appendBlock(content, block), nil
+	}
+
+	hookEnd, step := yamlBlockEnd(lines, hook, 0)
+	if step <= 0 {
+		step = 2
+	}
+	commands := -1
+	for i := hook + 1; i < hookEnd; i++ {
+		if lines[i] == strings.Repeat(" ", step)+"commands:" {
+			commands = i
+			break
+		}
+	}
+	pad := strings.Repeat(" ", step)
+	if commands < 0 {
+		block := []string{pad + blockBegin, pad + "commands:", pad + pad + "gatekeeper:", pad + pad + pad + "run: gatekeeper run", pad + blockEnd}
+		return insertLines(lines, hookEnd, block), nil
+	}
+	end, indent := yamlBlockEnd(lines, commands, step)
+	if indent <= step {
+		indent = 2 * step
+	}
+	pad = strings.Repeat(" ", indent)
+	block := []string{pad + blockBegin, pad + "gatekeeper:", pad + strings.Repeat(" ", step) + "run: gatekeeper run", pad + blockEnd}
+	return insertLines(lines, end, block), nil
+}
+
+// yamlBlockEnd returns the index after the last line of the YAML block started by
+// the key at lines[start], whose own indentation is keyIndent, and the
+// indentation of the block's first entry (-1 if it has none). A list may sit
+// at the key's indentation, as pre-commit's sample config does.
+func yamlBlockEnd(lines []string, start, keyIndent int) (end, indent int) {
+	end, indent = start+1, -1
+	for i := start + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if n < keyIndent || (n == keyIndent && !strings.HasPrefix(trimmed, "-")) {
+			break
+		}
+		if indent < 0 {
+			indent = n
+		}
+		end = i + 1
+	}
+	return end, indent
+}
+
+// insertLines inserts block before lines[at] and joins the lines.
+func insertLines(lines []string, at int, block []string) string {
+	out := make([]string, 0, len(lines)+len(block))
+	out = append(out, lines[:at]...)
+	out = append(out, block...)
+	out = append(out, lines[at:]...)
+	return strings.Join(out, "\n")
+}
+
+// hasBlock reports whether content has lines added by gatekeeper.
+func hasBlock(content string) bool {
+	return hasLine(content, blockBegin) || hasLine(content, hookMarker)
+}
+
+// hasLine reports whether content has a line that is marker, ignoring indentation.
+func hasLine(content, marker string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// removeBlock deletes the lines from blockBegin through blockEnd.
+func removeBlock(content string) string {
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines))
+	inBlock := false
+	for _, line := range lines {
+		switch strings.TrimSpace(line) {
+		case blockBegin:
+			inBlock = true
+			continue
+		case blockEnd:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// appendBlock appends the lines of block to content. A content without a
+// final newline keeps it missing, so removeBlock restores it exactly.
+func appendBlock(content string, block []string) string {
+	joined := strings.Join(block, "\n")
+	switch {
+	case content == "":
+		return joined + "\n"
+	case strings.HasSuffix(content, "\n"):
+		return content + joined + "\n"
+	default:
+		return content + "\n" + joined
+	}
+}
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// roundTrip installs m in dir, checks the result with check, and checks that
+// removing it restores the original file.
+func roundTrip(t *testing.T, dir string, m HookManager, check func(string)) {
+	t.Helper()
+	path := filepath.Join(dir, m.Config)
+	original, _ := os.ReadFile(path)
+
+	added, err := m.Install(dir)
+	if err != nil || !added {
+		t.Fatalf("Install() = %v, %v", added, err)
+	}
+	data, _ := os.ReadFile(path)
+	check(string(data))
+	if added, err := m.Install(dir); err != nil || added {
+		t.Errorf("second Install() = %v, %v; want no change", added, err)
+	}
+
+	removed, err := m.Remove(dir)
+	if err != nil || !removed {
+		t.Fatalf("Remove() = %v, %v", removed, err)
+	}
+	restored, err := os.ReadFile(path)
+	if original == nil {
+		if !os.IsNotExist(err) {
+			t.Errorf("expected the created file to be removed, got %q", restored)
+		}
+		return
+	}
+	if string(restored) != string(original) {
+		t.Errorf("Remove() did not restore the file:\n%s\nwant:\n%s", restored, original)
+	}
+}
+
+func TestDetectHookManagers(t *testing.T) {
+	dir := t.TempDir()
+	if got := DetectHookManagers(dir); len(got) != 0 {
+		t.Errorf("expected no managers, got %+v", got)
+	}
+	writeFile(t, filepath.Join(dir, ".husky", "_", "h"), "")
+	writeFile(t, filepath.Join(dir, ".pre-commit-config.yaml"), "repos: []\n")
+	writeFile(t, filepath.Join(dir, ".lefthook.yml"), "")
+	var names []string
+	for _, m := range DetectHookManagers(dir) {
+		names = append(names, m.Name+":"+m.Config)
+	}
+	if got := strings.Join(names, ","); got != "husky:.husky/pre-commit,pre-commit:.pre-commit-config.yaml,lefthook:.lefthook.yml" {
+		t.Errorf("DetectHookManagers() = %s", got)
+	}
+}
+
+func TestHookManager_Husky(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".husky", "_", "h"), "")
+	m := DetectHookManagers(dir)[0]
+
+	roundTrip(t, dir, m, func(hook string) {
+		if !strings.Contains(hook, "gatekeeper run\n") || strings.Contains(hook, "husky.sh") {
+			t.Errorf("unexpected new hook:\n%s", hook)
+		}
+	})
+
+	writeFile(t, filepath.Join(dir, ".husky", "pre-commit"), "npx lint-staged")
+	roundTrip(t, dir, m, func(hook string) {
+		if hook != "npx lint-staged\n# >>> gatekeeper\ngatekeeper run\n# <<< gatekeeper" {
+			t.Errorf("unexpected hook:\n%s", hook)
+		}
+	})
+}
+
+func TestHookManager_HuskyV8(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".husky", "_", "husky.sh"), "")
+	roundTrip(t, dir, DetectHookManagers(dir)[0], func(hook string) {
+		if !strings.HasPrefix(hook, "#!/usr/bin/env sh\n. \"$(dirname -- \"$0\")/_/husky.sh\"\n") {
+			t.Errorf("v8 hooks must source husky.sh:\n%s", hook)
+		}
+	})
+}
+
+func TestHookManager_PreCommit(t *testing.T) {
+	for name, config := range map[string]string{
+		"sample style": "repos:\n-   repo: https://github.com/pre-commit/pre-commit-hooks\n    rev: v4.6.0\n    hooks:\n    -   id: trailing-whitespace\n\nci:\n  autofix_prs: false\n",
+		"indented":     "# hooks\nrepos:\n  - repo: https://github.com/psf/black\n    rev: 24.4.2\n    hooks:\n      - id: black\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, ".pre-commit-config.yaml"), config)
+			roundTrip(t, dir, DetectHookManagers(dir)[0], func(got string) {
+				if !strings.Contains(got, "- repo: local\n") || !strings.Contains(got, "entry: gatekeeper run\n") {
+					t.Errorf("gatekeeper hook not added:\n%s", got)
+				}
+				if name == "sample style" && !strings.Contains(got, "# <<< gatekeeper\n\nci:\n") {
+					t.Errorf("hook must be added at the end of repos:\n%s", got)
+				}
+				if name == "indented" && !strings.Contains(got, "      - id: black\n  # >>> gatekeeper\n  - repo: local\n") {
+					t.Errorf("hook must match the list indentation:\n%s", got)
+				}
+			})
+		})
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, ".pre-commit-config.yaml"), "repos: []\n")
+	if _, err := DetectHookManagers(dir)[0].Install(dir); err == nil {
+		t.Error("expected an error for a flow-style repos list")
+	}
+}
+
+func TestHookManager_Lefthook(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		want   string
+	}{
+		"no pre-commit hook": {
+			config: "pre-push:\n  commands:\n    test:\n      run: go test ./...\n",
+			want:   "pre-push:\n  commands:\n    test:\n      run: go test ./...\n# >>> gatekeeper\npre-commit:\n  commands:\n    gatekeeper:\n      run: gatekeeper run\n# <<< gatekeeper\n",
+		},
+		"existing commands": {
+			config: "pre-commit:\n  parallel: true\n  commands:\n    lint:\n      run: make lint\npre-push:\n  commands: {}\n",
+			want:   "pre-commit:\n  parallel: true\n  commands:\n    lint:\n      run: make lint\n    # >>> gatekeeper\n    gatekeeper:\n      run: gatekeeper run\n    # <<< gatekeeper\npre-push:\n  commands: {}\n",
+		},
+		"no commands": {
+			config: "pre-commit:\n    parallel: true\n",
+			want:   "pre-commit:\n    parallel: true\n    # >>> gatekeeper\n    commands:\n        gatekeeper:\n            run: gatekeeper run\n    # <<< gatekeeper\n",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "lefthook.yml"), tt.config)
+			roundTrip(t, dir, DetectHookManagers(dir)[0], func(got string) {
+				if got != tt.want {
+					t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+				}
+			})
+		})
+	}
+}
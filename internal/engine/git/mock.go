@@ -2,20 +2,53 @@ package git
 
 import (
 	"context"
+	"fmt"
 )
 
 // MockService is a test double for git.Service.
 type MockService struct {
-	Diffs       []FileDiff
-	DiffErr     error
-	Files       []string
-	FilesErr    error
+	Diffs    []FileDiff
+	DiffErr  error
+	Files    []string
+	FilesErr error
+	// Contents maps paths to their staged content; other paths return an error.
+	Contents    map[string]string
+	Branch      string
+	BranchErr   error
+	Tree        string
+	TreeErr     error
 	HookInstErr error
 	HookRemErr  error
-	StashDone   bool
-	StashErr    error
-	PopErr      error
-	CleanErr    error
+	// Chained is returned by InstallChainedHook.
+	Chained bool
+	// SummaryHook records whether InstallSummaryHook was called.
+	SummaryHook bool
+	// MessageHook records whether InstallMessageHook was called.
+	MessageHook bool
+	// StashID is returned by Stash.
+	StashID  string
+	StashErr error
+	PopErr   error
+	// StashList is returned by Stashes.
+	StashList []StashEntry
+	CleanErr  error
+	// Restaged is returned by RestageWritableFiles.
+	Restaged   []string
+	RestageErr error
+	// SnapshotDir is returned by SnapshotIndex.
+	SnapshotDir string
+	SnapshotErr error
+	// OverlayDir is returned by WritableOverlay.
+	OverlayDir string
+	OverlayErr error
+	// Applied is returned by ApplyOverlay.
+	Applied []string
+	// Submodules is returned by StagedSubmodules, and SubmoduleChanges maps
+	// their paths to the files returned by SubmoduleFiles.
+	Submodules       []SubmoduleChange
+	SubmoduleChanges map[string][]string
+	// LFSObjects maps OIDs to the content returned by LFSObject.
+	LFSObjects map[string]string
 }
 
 // StagedDiff returns the configured diffs.
@@ -28,27 +61,132 @@ func (m *MockService) StagedFiles(_ context.Context) ([]string, error) {
 	return m.Files, m.FilesErr
 }
 
+// StagedFileContent returns the configured content of path.
+func (m *MockService) StagedFileContent(_ context.Context, path string) (string, error) {
+	content, ok := m.Contents[path]
+	if !ok {
+		return "", fmt.Errorf("no staged content for %s", path)
+	}
+	return content, nil
+}
+
+// CurrentBranch returns the configured branch.
+func (m *MockService) CurrentBranch(_ context.Context) (string, error) {
+	return m.Branch, m.BranchErr
+}
+
+// IndexTree returns the configured tree.
+func (m *MockService) IndexTree(_ context.Context) (string, error) {
+	return m.Tree, m.TreeErr
+}
+
 // InstallHook returns the configured error.
 func (m *MockService) InstallHook(_ context.Context) error {
 	return m.HookInstErr
 }
 
+// InstallChainedHook returns the configured chain result and install error.
+func (m *MockService) InstallChainedHook(_ context.Context) (bool, error) {
+	return m.Chained, m.HookInstErr
+}
+
 // RemoveHook returns the configured error.
 func (m *MockService) RemoveHook(_ context.Context) error {
 	return m.HookRemErr
 }
 
-// Stash returns the configured stash result.
-func (m *MockService) Stash(_ context.Context) (bool, error) {
-	return m.StashDone, m.StashErr
+// InstallSummaryHook records the call and returns the configured install error.
+func (m *MockService) InstallSummaryHook(_ context.Context) error {
+	m.SummaryHook = true
+	return m.HookInstErr
+}
+
+// RemoveSummaryHook returns the configured remove error.
+func (m *MockService) RemoveSummaryHook(_ context.Context) error {
+	return m.HookRemErr
+}
+
+// InstallMessageHook records the call and returns the configured install error.
+func (m *MockService) InstallMessageHook(_ context.Context) error {
+	m.MessageHook = true
+	return m.HookInstErr
+}
+
+// RemoveMessageHook returns the configured remove error.
+func (m *MockService) RemoveMessageHook(_ context.Context) error {
+	return m.HookRemErr
+}
+
+// Stash returns the configured stash ID.
+func (m *MockService) Stash(_ context.Context) (string, error) {
+	return m.StashID, m.StashErr
 }
 
 // StashPop returns the configured error.
-func (m *MockService) StashPop(_ context.Context) error {
+func (m *MockService) StashPop(_ context.Context, _ string) error {
 	return m.PopErr
 }
 
+// Stashes returns the configured stash list.
+func (m *MockService) Stashes(_ context.Context) ([]StashEntry, error) {
+	return m.StashList, nil
+}
+
+// WorktreeState returns an empty state.
+func (m *MockService) WorktreeState(_ context.Context) (*WorktreeState, error) {
+	return &WorktreeState{}, nil
+}
+
 // CleanWritableFiles returns the configured error.
-func (m *MockService) CleanWritableFiles(_ context.Context) error {
+func (m *MockService) CleanWritableFiles(_ context.Context, _ *WorktreeState) error {
 	return m.CleanErr
 }
+
+// RestageWritableFiles returns the configured re-staged paths.
+func (m *MockService) RestageWritableFiles(_ context.Context, _ []string) ([]string, error) {
+	return m.Restaged, m.RestageErr
+}
+
+// StagedSubmodules returns the configured submodule changes.
+func (m *MockService) StagedSubmodules(_ context.Context) ([]SubmoduleChange, error) {
+	return m.Submodules, nil
+}
+
+// SubmoduleFiles returns the configured files of the submodule.
+func (m *MockService) SubmoduleFiles(_ context.Context, c SubmoduleChange) ([]string, error) {
+	return m.SubmoduleChanges[c.Path], nil
+}
+
+// LFSObject returns the configured content of the pointer's object.
+func (m *MockService) LFSObject(_ context.Context, p LFSPointer) (string, error) {
+	content, ok := m.LFSObjects[p.OID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrLFSObjectMissing, p.OID)
+	}
+	return content, nil
+}
+
+// SnapshotIndex returns the configured snapshot directory.
+func (m *MockService) SnapshotIndex(_ context.Context) (string, error) {
+	return m.SnapshotDir, m.SnapshotErr
+}
+
+// CleanSnapshot does nothing.
+func (m *MockService) CleanSnapshot(_ context.Context) error {
+	return nil
+}
+
+// WritableOverlay returns the configured overlay directory.
+func (m *MockService) WritableOverlay(_ context.Context) (string, error) {
+	return m.OverlayDir, m.OverlayErr
+}
+
+// ApplyOverlay returns the configured applied paths.
+func (m *MockService) ApplyOverlay(_ context.Context, _ string, _ []string) ([]string, error) {
+	return m.Applied, nil
+}
+
+// CleanOverlay does nothing.
+func (m *MockService) CleanOverlay(_ context.Context) error {
+	return nil
+}
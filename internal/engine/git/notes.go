@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNoNote is returned by ReadNote when the object has no note.
+var ErrNoNote = errors.New("no note found")
+
+// IndexTree writes the staged index as a tree object and returns its id.
+// It is the tree the next commit will have, unless the index changes.
+func (s *ExecService) IndexTree(ctx context.Context) (string, error) {
+	out, err := s.runGit(ctx, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("writing index tree: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// TreeOf returns the id of the tree of a commit.
+func (s *ExecService) TreeOf(ctx context.Context, rev string) (string, error) {
+	out, err := s.runGit(ctx, "rev-parse", "--verify", "--end-of-options", rev+"^{tree}")
+	if err != nil {
+		return "", fmt.Errorf("resolving tree of %s: %w", rev, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// AddNote attaches content as the note of rev under the notes ref, replacing
+// any existing note.
+func (s *ExecService) AddNote(ctx context.Context, ref, rev, content string) error {
+	if _, err := s.runGit(ctx, "notes", "--ref="+ref, "add", "--force", "-m", content, "--end-of-options", rev); err != nil {
+		return fmt.Errorf("adding note to %s: %w", rev, err)
+	}
+	return nil
+}
+
+// ReadNote returns the note of rev under the notes ref. Returns ErrNoNote if
+// there is none.
+func (s *ExecService) ReadNote(ctx context.Context, ref, rev string) (string, error) {
+	// "notes list" exits with 1 for a missing note and 128 for a bad revision.
+	// ref and rev are passed as separate arguments.
+	if err := s.command(ctx, "notes", "--ref="+ref, "list", "--end-of-options", rev).Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("%w for %s in %s", ErrNoNote, rev, ref)
+		}
+		return "", fmt.Errorf("listing notes of %s: %w", rev, err)
+	}
+	out, err := s.runGit(ctx, "notes", "--ref="+ref, "show", "--end-of-options", rev)
+	if err != nil {
+		return "", fmt.Errorf("reading note of %s: %w", rev, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndexTree_MatchesCommittedTree(t *testing.T) {
+	dir := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+
+	ctx := context.Background()
+	svc := NewExecService(dir)
+	staged, err := svc.IndexTree(ctx)
+	if err != nil {
+		t.Fatalf("index tree: %v", err)
+	}
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	committed, err := svc.TreeOf(ctx, "HEAD")
+	if err != nil {
+		t.Fatalf("tree of HEAD: %v", err)
+	}
+	if staged == "" || staged != committed {
+		t.Errorf("index tree %q != committed tree %q", staged, committed)
+	}
+	if _, err := svc.TreeOf(ctx, "no-such-rev"); err == nil {
+		t.Error("expected an error for an unknown revision")
+	}
+}
+
+func TestNotes_AddAndRead(t *testing.T) {
+	dir := setupGitRepo(t)
+	run(t, dir, "git", "commit", "--allow-empty", "-m", "initial")
+
+	ctx := context.Background()
+	svc := NewExecService(dir)
+	const ref = "refs/notes/test"
+
+	if _, err := svc.ReadNote(ctx, ref, "HEAD"); !errors.Is(err, ErrNoNote) {
+		t.Fatalf("expected ErrNoNote, got %v", err)
+	}
+	for _, content := range []string{`{"v":1}`, `{"v":2}`} {
+		if err := svc.AddNote(ctx, ref, "HEAD", content); err != nil {
+			t.Fatalf("add note: %v", err)
+		}
+	}
+	note, err := svc.ReadNote(ctx, ref, "HEAD")
+	if err != nil {
+		t.Fatalf("read note: %v", err)
+	}
+	if strings.TrimSpace(note) != `{"v":2}` {
+		t.Errorf("note = %q, want the replaced content", note)
+	}
+	if _, err := svc.ReadNote(ctx, ref, "no-such-rev"); err == nil || errors.Is(err, ErrNoNote) {
+		t.Errorf("expected a resolution error for an unknown revision, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// overlayDir is the writable overlay directory, relative to the .git directory.
+const overlayDir = "gatekeeper/overlay"
+
+// WritableOverlay checks the staged index out into a directory inside .git
+// for writable gates to modify instead of the working tree, and returns its
+// absolute path. ApplyOverlay carries their modifications over.
+func (s *ExecService) WritableOverlay(ctx context.Context) (string, error) {
+	log := logger.FromContext(ctx)
+	log.Info("creating writable overlay")
+
+	dir, err := s.checkoutGitSubdir(ctx, overlayDir, "overlay")
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("writable overlay created", "dir", dir)
+	return dir, nil
+}
+
+// ApplyOverlay stages the modifications made in the overlay dir to paths, the
+// files staged for the commit, and writes them to the working tree. It returns
+// the paths that were modified. Changes to other files stay in the overlay.
+func (s *ExecService) ApplyOverlay(ctx context.Context, dir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	log := logger.FromContext(ctx)
+	log.Info("applying writable overlay modifications")
+
+	workTree := "--work-tree=" + filepath.ToSlash(dir)
+	out, err := s.runGit(ctx, append([]string{workTree, "diff", "--name-only", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("listing modified files: %w", err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, nil
+	}
+	modified := strings.Split(out, "\n")
+
+	if _, err := s.runGit(ctx, append([]string{workTree, "add", "--"}, modified...)...); err != nil {
+		return nil, fmt.Errorf("staging modified files: %w", err)
+	}
+	if _, err := s.runGit(ctx, append([]string{"checkout-index", "--force", "--"}, modified...)...); err != nil {
+		return modified, fmt.Errorf("writing modified files to the working tree: %w", err)
+	}
+
+	log.Info("writable overlay modifications applied", "count", len(modified))
+	return modified, nil
+}
+
+// CleanOverlay removes the contents of the writable overlay.
+func (s *ExecService) CleanOverlay(ctx context.Context) error {
+	return s.cleanGitSubdir(ctx, overlayDir, "overlay")
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWritableOverlay_ApplyOverlay(t *testing.T) {
+	dir := setupGitRepo(t)
+	for name, content := range map[string]string{"main.go": "package main\n", "util.go": "package main\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, dir, "git", "add", ".")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package  main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+	untracked := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(untracked, []byte("keep me\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	ctx := context.Background()
+	overlay, err := svc.WritableOverlay(ctx)
+	if err != nil {
+		t.Fatalf("overlay: %v", err)
+	}
+	if !filepath.IsAbs(overlay) || !strings.Contains(filepath.ToSlash(overlay), ".git/gatekeeper/overlay") {
+		t.Errorf("unexpected overlay path %q", overlay)
+	}
+
+	// A formatter fixes the staged file and touches an unstaged one, in the overlay only.
+	if err := os.WriteFile(filepath.Join(overlay, "main.go"), []byte("package main\n// fixed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(overlay, "util.go"), []byte("package main\n// fixed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(overlay, "generated.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(data) != "package  main\n" {
+		t.Fatalf("expected the working tree to be untouched by overlay changes, got %q", data)
+	}
+
+	fixed, err := svc.ApplyOverlay(ctx, overlay, []string{"main.go"})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if len(fixed) != 1 || fixed[0] != "main.go" {
+		t.Errorf("expected only main.go to be applied, got %v", fixed)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(data) != "package main\n// fixed\n" {
+		t.Errorf("expected the fix in the working tree, got %q", data)
+	}
+	if staged, err := svc.StagedFileContent(ctx, "main.go"); err != nil || staged != "package main\n// fixed\n" {
+		t.Errorf("expected the fix to be staged, got %q (%v)", staged, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "util.go")); string(data) != "package main\n" {
+		t.Errorf("expected unstaged files to keep their content, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "generated.go")); !os.IsNotExist(err) {
+		t.Error("expected files created in the overlay to stay there")
+	}
+	if data, _ := os.ReadFile(untracked); string(data) != "keep me\n" {
+		t.Errorf("expected untracked files to be kept, got %q", data)
+	}
+
+	if err := svc.CleanOverlay(ctx); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	entries, err := os.ReadDir(overlay)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected an empty overlay directory, got %v (%v)", entries, err)
+	}
+}
+
+func TestApplyOverlay_NoChanges(t *testing.T) {
+	dir := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+
+	svc := NewExecService(dir)
+	overlay, err := svc.WritableOverlay(context.Background())
+	if err != nil {
+		t.Fatalf("overlay: %v", err)
+	}
+	fixed, err := svc.ApplyOverlay(context.Background(), overlay, []string{"main.go"})
+	if err != nil || len(fixed) != 0 {
+		t.Errorf("expected nothing to apply, got %v, %v", fixed, err)
+	}
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// nullCommit matches the all-zero commit id CI systems pass as the base of a
+// branch's first push.
+var nullCommit = regexp.MustCompile(`^0+$`)
+
+// StageChangesSince stages the changes committed since base, so that gates
+// check them like a pending commit, as in CI. HEAD is soft-reset to the merge
+// base of base and HEAD: the index and working tree keep the current commit,
+// and the staged diff is every change made since the merge base. restore
+// resets HEAD back. An empty or all-zero base stands for the parent of HEAD.
+func (s *ExecService) StageChangesSince(ctx context.Context, base string) (restore func() error, err error) {
+	log := logger.FromContext(ctx)
+
+	if base == "" || nullCommit.MatchString(base) {
+		base = "HEAD^"
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base %q", base)
+	}
+
+	out, err := s.runGit(ctx, "rev-parse", "--verify", "HEAD^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	head := strings.TrimSpace(out)
+
+	out, err = s.runGit(ctx, "merge-base", base, head)
+	if err != nil {
+		return nil, fmt.Errorf("finding the merge base of %s and HEAD (is the clone shallow?): %w", base, err)
+	}
+	mergeBase := strings.TrimSpace(out)
+
+	if _, err := s.runGit(ctx, "reset", "--quiet", "--soft", mergeBase); err != nil {
+		return nil, fmt.Errorf("staging changes since %s: %w", base, err)
+	}
+	log.Info("staged changes since base", "base", base, "merge_base", mergeBase, "head", head)
+
+	return func() error {
+		if _, err := s.runGit(context.WithoutCancel(ctx), "reset", "--quiet", "--soft", head); err != nil {
+			return fmt.Errorf("restoring HEAD to %s: %w", head, err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// commitFile writes a file and commits it.
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", name)
+	run(t, dir, "git", "commit", "-m", "add "+name)
+}
+
+func headCommit(t *testing.T, svc *ExecService) string {
+	t.Helper()
+	out, err := svc.runGit(context.Background(), "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(out)
+}
+
+func TestStageChangesSince(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	ctx := context.Background()
+
+	commitFile(t, dir, "a.txt", "a\n")
+	base := headCommit(t, svc)
+	commitFile(t, dir, "b.txt", "b\n")
+	commitFile(t, dir, "c.txt", "c\n")
+	head := headCommit(t, svc)
+
+	restore, err := svc.StageChangesSince(ctx, base)
+	if err != nil {
+		t.Fatalf("StageChangesSince: %v", err)
+	}
+	files, err := svc.StagedFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(files, []string{"b.txt", "c.txt"}) {
+		t.Errorf("staged files = %v, want [b.txt c.txt]", files)
+	}
+
+	if err := restore(); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if got := headCommit(t, svc); got != head {
+		t.Errorf("HEAD = %s after restore, want %s", got, head)
+	}
+	if files, _ := svc.StagedFiles(ctx); len(files) != 0 {
+		t.Errorf("expected nothing staged after restore, got %v", files)
+	}
+}
+
+func TestStageChangesSince_DefaultsToParent(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	ctx := context.Background()
+
+	commitFile(t, dir, "a.txt", "a\n")
+	commitFile(t, dir, "b.txt", "b\n")
+
+	for _, base := range []string{"", "0000000000000000000000000000000000000000"} {
+		restore, err := svc.StageChangesSince(ctx, base)
+		if err != nil {
+			t.Fatalf("StageChangesSince(%q): %v", base, err)
+		}
+		if files, _ := svc.StagedFiles(ctx); !slices.Equal(files, []string{"b.txt"}) {
+			t.Errorf("base %q: staged files = %v, want [b.txt]", base, files)
+		}
+		if err := restore(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStageChangesSince_InvalidBase(t *testing.T) {
+	dir := setupGitRepo(t)
+	svc := NewExecService(dir)
+	commitFile(t, dir, "a.txt", "a\n")
+
+	if _, err := svc.StageChangesSince(context.Background(), "--output=x"); err == nil {
+		t.Error("expected an option-like base to be rejected")
+	}
+	if _, err := svc.StageChangesSince(context.Background(), "no-such-ref"); err == nil {
+		t.Error("expected an unknown base to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotIndex_ChecksOutStagedContent(t *testing.T) {
+	dir := setupGitRepo(t)
+
+	filePath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	// Partially stage: the index has one version, the working tree another.
+	staged := "package main\n// staged\n"
+	if err := os.WriteFile(filePath, []byte(staged), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+	unstaged := staged + "// unstaged\n"
+	if err := os.WriteFile(filePath, []byte(unstaged), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "untracked.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	snap, err := svc.SnapshotIndex(context.Background())
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if !filepath.IsAbs(snap) {
+		t.Errorf("expected an absolute snapshot path, got %q", snap)
+	}
+
+	data, err := os.ReadFile(filepath.Join(snap, "main.go"))
+	if err != nil {
+		t.Fatalf("reading snapshot: %v", err)
+	}
+	if string(data) != staged {
+		t.Errorf("expected staged content in snapshot, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(snap, "untracked.go")); !os.IsNotExist(err) {
+		t.Error("expected untracked files to be left out of the snapshot")
+	}
+
+	// The working tree is untouched.
+	data, err = os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != unstaged {
+		t.Errorf("expected working tree to keep unstaged changes, got:\n%s", data)
+	}
+
+	// A second snapshot reuses the directory and drops stale files.
+	if err := os.WriteFile(filepath.Join(snap, "stale.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snap2, err := svc.SnapshotIndex(context.Background())
+	if err != nil {
+		t.Fatalf("second snapshot: %v", err)
+	}
+	if snap2 != snap {
+		t.Errorf("expected the snapshot directory to be reused, got %q and %q", snap, snap2)
+	}
+	if _, err := os.Stat(filepath.Join(snap, "stale.txt")); !os.IsNotExist(err) {
+		t.Error("expected stale files to be removed")
+	}
+
+	if err := svc.CleanSnapshot(context.Background()); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	entries, err := os.ReadDir(snap)
+	if err != nil {
+		t.Fatalf("expected the snapshot directory to be kept: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected an empty snapshot directory, got %d entries", len(entries))
+	}
+}
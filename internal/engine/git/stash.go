@@ -2,18 +2,41 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
 )
 
+// stashMessage starts the message of the stashes gatekeeper creates.
 const stashMessage = "gatekeeper-stash"
 
+// ErrStashNotFound is returned by StashPop when the stash is no longer in the
+// stash list.
+var ErrStashNotFound = errors.New("gatekeeper stash not found")
+
+// StashEntry is a gatekeeper stash in the stash list.
+type StashEntry struct {
+	// Ref is the stash reference, such as stash@{0}. It changes as stashes
+	// are pushed and popped.
+	Ref string
+	// ID is the commit of the stash, which identifies it.
+	ID      string
+	Message string
+	At      time.Time
+}
+
 // Stash saves unstaged and untracked changes so only staged changes remain in the working tree.
-// Returns true if a stash was actually created (there was something to stash).
-// If there are no unstaged changes, no stash is created and false is returned.
-func (s *ExecService) Stash(ctx context.Context) (bool, error) {
+// It returns the ID of the stash for StashPop, or "" if there was nothing to
+// stash. The stash message names the process, so that the stashes of runs that
+// crashed can be told apart from the user's own.
+func (s *ExecService) Stash(ctx context.Context) (string, error) {
 	log := logger.FromContext(ctx)
 	log.Info("stashing unstaged changes")
 
@@ -23,76 +46,182 @@ func (s *ExecService) Stash(ctx context.Context) (bool, error) {
 		// No unstaged changes — check untracked files.
 		out, err := s.runGit(ctx, "ls-files", "--others", "--exclude-standard")
 		if err != nil {
-			return false, fmt.Errorf("checking untracked files: %w", err)
+			return "", fmt.Errorf("checking untracked files: %w", err)
 		}
 		if strings.TrimSpace(out) == "" {
 			log.Info("nothing to stash — working tree is clean relative to index")
-			return false, nil
+			return "", nil
 		}
 	}
 
 	// Stash everything except staged changes.
-	_, err = s.runGit(ctx, "stash", "push", "--keep-index", "--include-untracked", "-m", stashMessage)
+	message := fmt.Sprintf("%s pid %d at %s", stashMessage, os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+	_, err = s.runGit(ctx, "stash", "push", "--keep-index", "--include-untracked", "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("stashing changes: %w", err)
+	}
+	id, err := s.runGit(ctx, "rev-parse", "--verify", "refs/stash")
 	if err != nil {
-		return false, fmt.Errorf("stashing changes: %w", err)
+		return "", fmt.Errorf("reading stash commit: %w", err)
 	}
 
-	log.Info("changes stashed successfully")
-	return true, nil
+	log.Info("changes stashed successfully", "stash", strings.TrimSpace(id))
+	return strings.TrimSpace(id), nil
 }
 
-// StashPop restores previously stashed changes.
-// It looks for the gatekeeper stash by message and pops it.
-func (s *ExecService) StashPop(ctx context.Context) error {
+// StashPop restores the stash with the given ID, wherever it is in the stash
+// list. It returns ErrStashNotFound if the stash is gone.
+func (s *ExecService) StashPop(ctx context.Context, id string) error {
 	log := logger.FromContext(ctx)
-	log.Info("restoring stashed changes")
+	log.Info("restoring stashed changes", "stash", id)
+
+	stashes, err := s.Stashes(ctx)
+	if err != nil {
+		return err
+	}
+	for _, st := range stashes {
+		if st.ID != id {
+			continue
+		}
+		if _, err := s.runGit(ctx, "stash", "pop", st.Ref); err != nil {
+			return fmt.Errorf("popping stash %s: %w", st.Ref, err)
+		}
+		log.Info("stash restored successfully")
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrStashNotFound, id)
+}
 
-	// Find the gatekeeper stash entry.
-	out, err := s.runGit(ctx, "stash", "list")
+// Stashes lists the stashes gatekeeper created, newest first. Outside of a
+// run, they are left by runs that crashed before restoring them.
+func (s *ExecService) Stashes(ctx context.Context) ([]StashEntry, error) {
+	out, err := s.runGit(ctx, "stash", "list", "--format=%gd%x00%H%x00%ct%x00%gs")
 	if err != nil {
-		return fmt.Errorf("listing stashes: %w", err)
+		return nil, fmt.Errorf("listing stashes: %w", err)
 	}
 
-	// Search for our stash.
+	var stashes []StashEntry
 	for _, line := range strings.Split(out, "\n") {
-		if strings.Contains(line, stashMessage) {
-			// Extract stash reference (e.g., "stash@{0}")
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 0 {
-				continue
-			}
-			ref := strings.TrimSpace(parts[0])
-
-			_, err := s.runGit(ctx, "stash", "pop", ref)
-			if err != nil {
-				return fmt.Errorf("popping stash: %w", err)
-			}
-
-			log.Info("stash restored successfully")
-			return nil
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 || !strings.Contains(fields[3], stashMessage) {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing stash time %q: %w", fields[2], err)
 		}
+		stashes = append(stashes, StashEntry{Ref: fields[0], ID: fields[1], Message: fields[3], At: time.Unix(unix, 0)})
 	}
+	return stashes, nil
+}
 
-	log.Info("no gatekeeper stash found to restore")
-	return nil
+// WorktreeState records the files in the working tree that differ from the
+// index and the untracked files, before writable gates run on the host, so
+// that CleanWritableFiles reverts only what the gates touched.
+type WorktreeState struct {
+	modified  map[string]bool
+	untracked map[string]bool
+}
+
+// WorktreeState lists the tracked files with unstaged changes and the
+// untracked files that are not ignored.
+func (s *ExecService) WorktreeState(ctx context.Context) (*WorktreeState, error) {
+	modified, err := s.lsFiles(ctx, "--modified")
+	if err != nil {
+		return nil, fmt.Errorf("listing modified files: %w", err)
+	}
+	untracked, err := s.lsFiles(ctx, "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("listing untracked files: %w", err)
+	}
+	return &WorktreeState{modified: pathSet(modified), untracked: pathSet(untracked)}, nil
 }
 
-// CleanWritableFiles reverts modifications made by writable gates.
-// This runs `git checkout -- .` and `git clean -fd` to restore the working tree.
-func (s *ExecService) CleanWritableFiles(ctx context.Context) error {
+// CleanWritableFiles reverts the modifications made by writable gates since
+// before was recorded: tracked files they changed are checked out from the
+// index, and untracked files they created are removed. Files that already had
+// unstaged changes and untracked files that already existed are kept, as are
+// ignored files.
+func (s *ExecService) CleanWritableFiles(ctx context.Context, before *WorktreeState) error {
 	log := logger.FromContext(ctx)
 	log.Info("cleaning writable file modifications")
 
-	// Restore tracked files.
-	if _, err := s.runGit(ctx, "checkout", "--", "."); err != nil {
-		return fmt.Errorf("reverting tracked files: %w", err)
+	after, err := s.WorktreeState(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Remove untracked files created by writable gates.
-	if _, err := s.runGit(ctx, "clean", "-fd"); err != nil {
-		return fmt.Errorf("cleaning untracked files: %w", err)
+	var reverted []string
+	for path := range after.modified {
+		if !before.modified[path] {
+			reverted = append(reverted, path)
+		}
+	}
+	if len(reverted) > 0 {
+		slices.Sort(reverted)
+		if _, err := s.runGit(ctx, append([]string{"checkout", "--"}, reverted...)...); err != nil {
+			return fmt.Errorf("reverting tracked files: %w", err)
+		}
+	}
+
+	removed := 0
+	for path := range after.untracked {
+		if before.untracked[path] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.WorkDir, filepath.FromSlash(path))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing untracked file %s: %w", path, err)
+		}
+		removed++
 	}
 
-	log.Info("working tree cleaned")
+	log.Info("working tree cleaned", "reverted", len(reverted), "removed", removed)
 	return nil
 }
+
+// lsFiles runs git ls-files with args and returns the listed paths.
+func (s *ExecService) lsFiles(ctx context.Context, args ...string) ([]string, error) {
+	out, err := s.runGit(ctx, append([]string{"ls-files", "-z"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(out, func(r rune) bool { return r == 0 }), nil
+}
+
+// pathSet returns paths as a set.
+func pathSet(paths []string) map[string]bool {
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+	return set
+}
+
+// RestageWritableFiles stages the working-tree modifications of paths, the files
+// staged for the commit, so that fixes made by writable gates are committed.
+// It returns the paths that were modified. Changes to other files are left for
+// CleanWritableFiles to revert.
+func (s *ExecService) RestageWritableFiles(ctx context.Context, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	log := logger.FromContext(ctx)
+	log.Info("re-staging writable file modifications")
+
+	out, err := s.runGit(ctx, append([]string{"diff", "--name-only", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("listing modified files: %w", err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, nil
+	}
+	modified := strings.Split(out, "\n")
+
+	if _, err := s.runGit(ctx, append([]string{"add", "--"}, modified...)...); err != nil {
+		return nil, fmt.Errorf("staging modified files: %w", err)
+	}
+
+	log.Info("writable file modifications re-staged", "count", len(modified))
+	return modified, nil
+}
@@ -2,8 +2,10 @@ package git
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -30,11 +32,11 @@ func TestStash_WithUnstagedChanges(t *testing.T) {
 	}
 
 	svc := NewExecService(dir)
-	stashed, err := svc.Stash(context.Background())
+	id, err := svc.Stash(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected stash error: %v", err)
 	}
-	if !stashed {
+	if id == "" {
 		t.Error("expected stash to be created")
 	}
 }
@@ -51,11 +53,11 @@ func TestStash_NothingToStash(t *testing.T) {
 	run(t, dir, "git", "commit", "-m", "initial")
 
 	svc := NewExecService(dir)
-	stashed, err := svc.Stash(context.Background())
+	id, err := svc.Stash(context.Background())
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if stashed {
+	if id != "" {
 		t.Error("expected no stash for clean working tree")
 	}
 }
@@ -84,11 +86,11 @@ func TestStashPop_RestoresChanges(t *testing.T) {
 	}
 
 	svc := NewExecService(dir)
-	stashed, err := svc.Stash(context.Background())
+	id, err := svc.Stash(context.Background())
 	if err != nil {
 		t.Fatalf("stash: %v", err)
 	}
-	if !stashed {
+	if id == "" {
 		t.Fatal("expected stash to be created")
 	}
 
@@ -98,7 +100,7 @@ func TestStashPop_RestoresChanges(t *testing.T) {
 	}
 
 	// Pop stash.
-	if err := svc.StashPop(context.Background()); err != nil {
+	if err := svc.StashPop(context.Background(), id); err != nil {
 		t.Fatalf("stash pop: %v", err)
 	}
 
@@ -120,9 +122,63 @@ func TestStashPop_NoStash(t *testing.T) {
 	run(t, dir, "git", "commit", "-m", "initial")
 
 	svc := NewExecService(dir)
-	// Should not error when no stash exists.
-	if err := svc.StashPop(context.Background()); err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if err := svc.StashPop(context.Background(), "0123456789abcdef0123456789abcdef01234567"); !errors.Is(err, ErrStashNotFound) {
+		t.Fatalf("expected ErrStashNotFound, got %v", err)
+	}
+}
+
+func TestStashPop_LeavesUserStashes(t *testing.T) {
+	dir := setupGitRepo(t)
+	filePath := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	// The user's own stash, pushed before the run.
+	if err := os.WriteFile(filePath, []byte("package main\n// user stash\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "stash", "push", "-m", "my work")
+
+	untrackedPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(untrackedPath, []byte("notes\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewExecService(dir)
+	ctx := context.Background()
+	id, err := svc.Stash(ctx)
+	if err != nil || id == "" {
+		t.Fatalf("stash: %q, %v", id, err)
+	}
+
+	// Another stash pushed during the run moves the gatekeeper stash down.
+	if err := os.WriteFile(filePath, []byte("package main\n// pushed during the run\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "stash", "push", "-m", "meanwhile")
+
+	stashes, err := svc.Stashes(ctx)
+	if err != nil {
+		t.Fatalf("stashes: %v", err)
+	}
+	if len(stashes) != 1 || stashes[0].ID != id || stashes[0].Ref != "stash@{1}" || !strings.Contains(stashes[0].Message, "gatekeeper-stash pid") {
+		t.Fatalf("expected only the gatekeeper stash at stash@{1}, got %+v", stashes)
+	}
+
+	if err := svc.StashPop(ctx, id); err != nil {
+		t.Fatalf("stash pop: %v", err)
+	}
+	if _, err := os.Stat(untrackedPath); err != nil {
+		t.Errorf("expected the gatekeeper stash to be restored: %v", err)
+	}
+	out, err := svc.runGit(ctx, "stash", "list", "--format=%gs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "my work") || !strings.Contains(out, "meanwhile") {
+		t.Errorf("expected the user's stashes to be kept, got:\n%s", out)
 	}
 }
 
@@ -138,6 +194,12 @@ func TestCleanWritableFiles_RevertsModifications(t *testing.T) {
 	run(t, dir, "git", "add", "app.go")
 	run(t, dir, "git", "commit", "-m", "initial")
 
+	svc := NewExecService(dir)
+	before, err := svc.WorktreeState(context.Background())
+	if err != nil {
+		t.Fatalf("worktree state: %v", err)
+	}
+
 	// Modify the tracked file (simulating writable gate).
 	if err := os.WriteFile(filePath, []byte("package app\n// modified by gate\n"), 0o644); err != nil {
 		t.Fatal(err)
@@ -149,8 +211,7 @@ func TestCleanWritableFiles_RevertsModifications(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	svc := NewExecService(dir)
-	if err := svc.CleanWritableFiles(context.Background()); err != nil {
+	if err := svc.CleanWritableFiles(context.Background(), before); err != nil {
 		t.Fatalf("clean: %v", err)
 	}
 
@@ -168,3 +229,137 @@ func TestCleanWritableFiles_RevertsModifications(t *testing.T) {
 		t.Error("expected untracked file to be removed after clean")
 	}
 }
+
+func TestCleanWritableFiles_KeepsUnrelatedChanges(t *testing.T) {
+	dir := setupGitRepo(t)
+	for _, name := range []string{"app.go", "draft.go", "removed.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package app\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, dir, "git", "add", ".")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	// Changes that exist before the gates run.
+	draftPath := filepath.Join(dir, "draft.go")
+	if err := os.WriteFile(draftPath, []byte("package app\n// work in progress\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	notesPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notesPath, []byte("keep me\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewExecService(dir)
+	before, err := svc.WorktreeState(context.Background())
+	if err != nil {
+		t.Fatalf("worktree state: %v", err)
+	}
+
+	// The gate modifies and deletes tracked files and creates one.
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte("package app\n// formatted\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "removed.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "out"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "out", "report.txt"), []byte("output"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.CleanWritableFiles(context.Background(), before); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+
+	for name, want := range map[string]string{"app.go": "package app\n", "removed.go": "package app\n", "draft.go": "package app\n// work in progress\n", "notes.txt": "keep me\n"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("reading %s: %v", name, err)
+			continue
+		}
+		if string(data) != want {
+			t.Errorf("%s: expected %q, got %q", name, want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "report.txt")); !os.IsNotExist(err) {
+		t.Error("expected the file created by the gate to be removed")
+	}
+}
+
+func TestRestageWritableFiles_StagesOnlyStagedPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+
+	for _, name := range []string{"app.go", "other.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package app\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, dir, "git", "add", "app.go", "other.go")
+	run(t, dir, "git", "commit", "-m", "initial")
+
+	// Stage a change to app.go, then let a "formatter" modify both files.
+	appPath := filepath.Join(dir, "app.go")
+	if err := os.WriteFile(appPath, []byte("package app\nfunc  F() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "app.go")
+	svc := NewExecService(dir)
+	before, err := svc.WorktreeState(context.Background())
+	if err != nil {
+		t.Fatalf("worktree state: %v", err)
+	}
+	fixed := "package app\n\nfunc F() {}\n"
+	if err := os.WriteFile(appPath, []byte(fixed), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	otherPath := filepath.Join(dir, "other.go")
+	if err := os.WriteFile(otherPath, []byte("package app\n// reformatted\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	restaged, err := svc.RestageWritableFiles(context.Background(), []string{"app.go"})
+	if err != nil {
+		t.Fatalf("restage: %v", err)
+	}
+	if len(restaged) != 1 || restaged[0] != "app.go" {
+		t.Errorf("expected app.go to be re-staged, got %v", restaged)
+	}
+
+	// The fix is in the index; the unrelated change is reverted by the clean.
+	if err := svc.CleanWritableFiles(context.Background(), before); err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	staged, err := svc.StagedFileContent(context.Background(), "app.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staged != fixed {
+		t.Errorf("expected fixed content staged, got:\n%s", staged)
+	}
+	data, err := os.ReadFile(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "package app\n" {
+		t.Errorf("expected unstaged file to be reverted, got:\n%s", data)
+	}
+}
+
+func TestRestageWritableFiles_NothingModified(t *testing.T) {
+	dir := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "app.go"), []byte("package app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "app.go")
+
+	restaged, err := NewExecService(dir).RestageWritableFiles(context.Background(), []string{"app.go"})
+	if err != nil {
+		t.Fatalf("restage: %v", err)
+	}
+	if len(restaged) != 0 {
+		t.Errorf("expected nothing re-staged, got %v", restaged)
+	}
+}
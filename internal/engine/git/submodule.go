@@ -0,0 +1,77 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// gitlinkMode is the file mode git records for a submodule in a tree.
+const gitlinkMode = "160000"
+
+// nullOID is the object ID git shows for the missing side of an added or
+// removed entry.
+const nullOID = "0000000000000000000000000000000000000000"
+
+// SubmoduleChange is a staged change of the commit a submodule points to.
+type SubmoduleChange struct {
+	Path string
+	// Old and New are the commits the submodule pointed to before and after
+	// the change; Old is empty for an added submodule and New for a removed one.
+	Old, New string
+}
+
+// StagedSubmodules returns the submodules whose commit is changed by the
+// staged changes.
+func (s *ExecService) StagedSubmodules(ctx context.Context) ([]SubmoduleChange, error) {
+	out, err := s.runGit(ctx, "diff", "--cached", "--raw", "-z", "--no-renames", "--no-abbrev")
+	if err != nil {
+		return nil, fmt.Errorf("listing staged submodules: %w", err)
+	}
+
+	// Each entry is ":<old mode> <new mode> <old oid> <new oid> <status>" then
+	// the path, NUL-separated.
+	var changes []SubmoduleChange
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) < 4 || (meta[0] != gitlinkMode && meta[1] != gitlinkMode) {
+			continue
+		}
+		c := SubmoduleChange{Path: fields[i+1]}
+		if meta[0] == gitlinkMode && meta[2] != nullOID {
+			c.Old = meta[2]
+		}
+		if meta[1] == gitlinkMode && meta[3] != nullOID {
+			c.New = meta[3]
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// SubmoduleFiles returns the files, relative to the project root, that the
+// new commit of a submodule changes compared to the old one, or all of its
+// files for an added submodule. The submodule must be checked out.
+func (s *ExecService) SubmoduleFiles(ctx context.Context, c SubmoduleChange) ([]string, error) {
+	if c.New == "" {
+		return nil, nil
+	}
+	sub := &ExecService{WorkDir: filepath.Join(s.WorkDir, filepath.FromSlash(c.Path))}
+	args := []string{"diff", "--name-only", "-z", "--no-renames", c.Old, c.New}
+	if c.Old == "" {
+		args = []string{"ls-tree", "-r", "--name-only", "-z", c.New}
+	}
+	out, err := sub.runGit(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing changes in submodule %s: %w", c.Path, err)
+	}
+
+	var files []string
+	for _, f := range strings.FieldsFunc(out, func(r rune) bool { return r == 0 }) {
+		files = append(files, path.Join(c.Path, f))
+	}
+	return files, nil
+}
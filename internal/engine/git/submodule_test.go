@@ -0,0 +1,82 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStagedSubmodules(t *testing.T) {
+	lib := setupGitRepo(t)
+	for name, content := range map[string]string{"lib.go": "package lib\n", "util.go": "package lib\n"} {
+		if err := os.WriteFile(filepath.Join(lib, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, lib, "git", "add", ".")
+	run(t, lib, "git", "commit", "-m", "initial")
+
+	dir := setupGitRepo(t)
+	run(t, dir, "git", "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "libs/lib")
+	run(t, dir, "git", "commit", "-m", "add submodule")
+
+	// Move the submodule to a new commit that changes one file.
+	sub := filepath.Join(dir, "libs", "lib")
+	run(t, sub, "git", "config", "user.email", "test@test.com")
+	run(t, sub, "git", "config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(sub, "util.go"), []byte("package lib\n\nfunc Util() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, sub, "git", "commit", "-am", "util")
+	run(t, dir, "git", "add", "libs/lib")
+
+	svc := NewExecService(dir)
+	ctx := context.Background()
+	changes, err := svc.StagedSubmodules(ctx)
+	if err != nil {
+		t.Fatalf("staged submodules: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "libs/lib" || changes[0].Old == "" || changes[0].New == "" {
+		t.Fatalf("expected one change of libs/lib, got %+v", changes)
+	}
+
+	files, err := svc.SubmoduleFiles(ctx, changes[0])
+	if err != nil {
+		t.Fatalf("submodule files: %v", err)
+	}
+	if !slices.Equal(files, []string{"libs/lib/util.go"}) {
+		t.Errorf("expected libs/lib/util.go, got %v", files)
+	}
+
+	// An added submodule changes all of its files.
+	added, err := svc.SubmoduleFiles(ctx, SubmoduleChange{Path: "libs/lib", New: changes[0].New})
+	if err != nil {
+		t.Fatalf("submodule files: %v", err)
+	}
+	if !slices.Equal(added, []string{"libs/lib/lib.go", "libs/lib/util.go"}) {
+		t.Errorf("expected every file of an added submodule, got %v", added)
+	}
+
+	diffs, err := svc.StagedDiff(ctx)
+	if err != nil {
+		t.Fatalf("staged diff: %v", err)
+	}
+	if len(diffs) != 1 || !diffs[0].Submodule || diffs[0].Path != "libs/lib" {
+		t.Errorf("expected a submodule diff, got %+v", diffs)
+	}
+}
+
+func TestStagedSubmodules_None(t *testing.T) {
+	dir := setupGitRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "git", "add", "main.go")
+
+	changes, err := NewExecService(dir).StagedSubmodules(context.Background())
+	if err != nil || len(changes) != 0 {
+		t.Errorf("expected no submodule changes, got %+v, %v", changes, err)
+	}
+}
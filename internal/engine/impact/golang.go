@@ -0,0 +1,230 @@
+package impact
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// goFullRunFiles are files whose change can affect every package in the module.
+var goFullRunFiles = map[string]bool{
+	"go.mod":      true,
+	"go.sum":      true,
+	"go.work":     true,
+	"go.work.sum": true,
+}
+
+// goListFields are the fields of `go list -json` the analysis reads.
+const goListFields = "ImportPath,Dir,Module,Deps,EmbedFiles,TestEmbedFiles,XTestEmbedFiles," +
+	"CgoFiles,CFiles,CXXFiles,MFiles,HFiles,FFiles,SFiles,SwigFiles,SwigCXXFiles,SysoFiles"
+
+// goPackage is a package reported by `go list -deps -test -json`. Test
+// variants of a package ("p [p.test]" and "p_test [p.test]") are listed
+// separately, with the dependencies of its test files.
+type goPackage struct {
+	ImportPath string
+	Dir        string
+	Module     *struct{ Main bool }
+	// Deps are the transitive dependencies of the package.
+	Deps []string
+
+	// Non-Go files the build reads, relative to Dir.
+	EmbedFiles, TestEmbedFiles, XTestEmbedFiles                []string
+	CgoFiles, CFiles, CXXFiles, MFiles, HFiles, FFiles, SFiles []string
+	SwigFiles, SwigCXXFiles, SysoFiles                         []string
+}
+
+// buildInputs returns the non-Go files of p that its build reads.
+func (p goPackage) buildInputs() []string {
+	return slices.Concat(p.EmbedFiles, p.TestEmbedFiles, p.XTestEmbedFiles,
+		p.CgoFiles, p.CFiles, p.CXXFiles, p.MFiles, p.HFiles, p.FFiles, p.SFiles,
+		p.SwigFiles, p.SwigCXXFiles, p.SysoFiles)
+}
+
+// goAffectedPackages returns the relative package directories ("./pkg/a", or "."
+// for the module root) that must be tested for the given changed files: the
+// packages containing changed Go files plus every package that transitively
+// depends on them (including via test files), as reported by `go list -deps
+// -test` in dir, the directory of fsys.
+//
+// full is true when the change set cannot be narrowed safely and the whole
+// module should be tested: go.mod or go.sum changed, a changed non-Go file is
+// read by the build (embedded files, cgo and assembly sources, testdata), the
+// project is not a single Go module, or go list cannot run.
+func goAffectedPackages(ctx context.Context, fsys fs.FS, dir string, changed []string) (pkgs []string, full bool, err error) {
+	log := logger.FromContext(ctx)
+
+	modData, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil || dir == "" {
+		return nil, true, nil
+	}
+	modulePath := parseModulePath(modData)
+	if modulePath == "" {
+		return nil, true, nil
+	}
+
+	changedDirs := make(map[string]bool)
+	var otherFiles []string
+	for _, f := range changed {
+		f = filepath.ToSlash(f)
+		switch {
+		case goFullRunFiles[path.Base(f)]:
+			return nil, true, nil
+		case strings.HasSuffix(f, ".go"):
+			changedDirs[path.Dir(f)] = true
+		default:
+			otherFiles = append(otherFiles, f)
+		}
+	}
+	if len(changedDirs) == 0 {
+		// Without Go changes, the command is left to run in full.
+		return nil, false, nil
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	listed, err := goList(ctx, root)
+	if err != nil {
+		log.Debug("test impact: go list failed, running full go test", "dir", dir, "error", err)
+		return nil, true, nil
+	}
+
+	// The packages of the main module, by directory relative to root, and the
+	// non-Go files their builds read.
+	var packages []goPackage
+	pkgDirs := make(map[string]string)
+	inputs := make(map[string]bool)
+	for _, p := range listed {
+		if p.Module == nil || !p.Module.Main {
+			continue
+		}
+		rel, err := filepath.Rel(root, p.Dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		packages = append(packages, p)
+		pkgDirs[p.Dir] = rel
+		for _, f := range p.buildInputs() {
+			inputs[path.Join(rel, filepath.ToSlash(f))] = true
+		}
+	}
+
+	for _, f := range otherFiles {
+		if inputs[f] || slices.Contains(strings.Split(f, "/"), "testdata") {
+			log.Debug("test impact: build input changed, running full go test", "file", f)
+			return nil, true, nil
+		}
+	}
+
+	changedImports := make(map[string]bool, len(changedDirs))
+	for d := range changedDirs {
+		changedImports[importPath(modulePath, d)] = true
+	}
+
+	affected := make(map[string]bool)
+	for _, p := range packages {
+		rel := pkgDirs[p.Dir]
+		if changedDirs[rel] || slices.ContainsFunc(p.Deps, func(dep string) bool { return changedImports[dep] }) {
+			affected[rel] = true
+		}
+	}
+
+	// Deleted packages are not listed; their importers are still tested.
+	for d := range affected {
+		pkgs = append(pkgs, relPackage(d))
+	}
+	sort.Strings(pkgs)
+	return pkgs, false, nil
+}
+
+// goList runs `go list -e -deps -test -json ./...` in dir. The go directive of
+// go.mod cannot switch toolchains, and modules are not downloaded: packages
+// outside the module do not change what it imports from itself.
+func goList(ctx context.Context, dir string) ([]goPackage, error) {
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-deps", "-test", "-json="+goListFields, "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local", "GOPROXY=off")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var pkgs []goPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p goPackage
+		if err := dec.Decode(&p); errors.Is(err, io.EOF) {
+			return pkgs, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("parsing go list output: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+// parseModulePath extracts the module path from go.mod contents.
+func parseModulePath(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// importPath returns the import path of the package in dir.
+func importPath(modulePath, dir string) string {
+	if dir == "." {
+		return modulePath
+	}
+	return modulePath + "/" + dir
+}
+
+// relPackage returns the go-tool relative package pattern for dir.
+func relPackage(dir string) string {
+	if dir == "." {
+		return "."
+	}
+	return "./" + dir
+}
+
+// rewriteGoTest replaces the "./..." pattern in a go test command with pkgs.
+// Returns false if the command is not a go test over "./...".
+func rewriteGoTest(command string, pkgs []string) (string, bool) {
+	if !isGoTestCommand(command) || !strings.Contains(command, "./...") {
+		return command, false
+	}
+	return strings.Replace(command, "./...", strings.Join(pkgs, " "), 1), true
+}
+
+// isGoTestCommand reports whether command invokes "go test".
+func isGoTestCommand(command string) bool {
+	fields := strings.Fields(command)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "go" && fields[i+1] == "test" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Package impact narrows test gates to the code affected by staged changes.
+package impact
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// Analyzer rewrites the commands of gates marked affected_only so they test only
+// what the staged changes can affect. Supported: "go test ./..." (the package
+// graph of go list) and jest/vitest (related-test selection). Gates it cannot
+// narrow safely are left unchanged.
+type Analyzer struct {
+	fsys fs.FS
+	// root is the project directory go list runs in; "" if fsys is not on disk.
+	root string
+}
+
+// NewAnalyzer creates an Analyzer for the project rooted at projectDir.
+func NewAnalyzer(projectDir string) *Analyzer {
+	return &Analyzer{fsys: os.DirFS(projectDir), root: projectDir}
+}
+
+// NewAnalyzerFS creates an Analyzer over the given file system (for
+// testability). Go tests are not narrowed, since go list needs a directory.
+func NewAnalyzerFS(fsys fs.FS) *Analyzer {
+	return &Analyzer{fsys: fsys}
+}
+
+// goImpact is the result of a Go impact analysis of one directory.
+type goImpact struct {
+	pkgs []string
+	full bool
+}
+
+// Rewrite returns a copy of gates with affected_only test commands narrowed to the
+// packages affected by stagedFiles. Gates that run in a subdirectory (a
+// workdir or nested config) are analyzed within it. The input slice is not modified.
+func (a *Analyzer) Rewrite(ctx context.Context, gates []config.Gate, stagedFiles []string) ([]config.Gate, error) {
+	log := logger.FromContext(ctx)
+
+	result := make([]config.Gate, len(gates))
+	copy(result, gates)
+
+	goByDir := make(map[string]goImpact)
+
+	for i := range result {
+		g := &result[i]
+		dir := g.ExecDir()
+		changed := config.FilesUnder(dir, stagedFiles)
+		if !g.AffectedOnly || len(changed) == 0 {
+			continue
+		}
+		fsys, err := a.scope(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if isGoTestCommand(g.Command) {
+			impact, done := goByDir[dir]
+			if !done {
+				impact.pkgs, impact.full, err = goAffectedPackages(ctx, fsys, a.dir(dir), changed)
+				if err != nil {
+					return nil, err
+				}
+				goByDir[dir] = impact
+			}
+			if impact.full || len(impact.pkgs) == 0 {
+				log.Debug("test impact: running full go test", "gate", g.Name, "full", impact.full)
+				continue
+			}
+			if cmd, ok := rewriteGoTest(g.Command, impact.pkgs); ok {
+				log.Info("test impact: narrowed go test", "gate", g.Name, "packages", len(impact.pkgs))
+				g.Command = cmd
+			}
+			continue
+		}
+
+		if runner := detectNodeRunner(g.Command); runner != runnerNone {
+			files, full := nodeRelatedFiles(fsys, changed)
+			if full || len(files) == 0 {
+				log.Debug("test impact: running full test suite", "gate", g.Name, "full", full)
+				continue
+			}
+			if cmd, ok := rewriteNodeTest(g.Command, runner, files); ok {
+				log.Info("test impact: selected related tests", "gate", g.Name, "files", len(files))
+				g.Command = cmd
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// dir returns the directory of dir, relative to the project root, on disk, or
+// "" if the project is not on disk.
+func (a *Analyzer) dir(dir string) string {
+	if a.root == "" {
+		return ""
+	}
+	return filepath.Join(a.root, filepath.FromSlash(dir))
+}
+
+// scope returns the file system of dir, relative to the project root.
+func (a *Analyzer) scope(dir string) (fs.FS, error) {
+	if dir == "" {
+		return a.fsys, nil
+	}
+	sub, err := fs.Sub(a.fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("scoping test impact to %s: %w", dir, err)
+	}
+	return sub, nil
+}
@@ -0,0 +1,189 @@
+package impact
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+// goModuleFiles is a small module: cmd imports api, api imports store, util is
+// standalone and embeds a template, and web has an external test importing store.
+func goModuleFiles() map[string]string {
+	return map[string]string{
+		"go.mod":                "module example.com/app\n\ngo 1.21\n",
+		"main.go":               "package main\nimport _ \"example.com/app/cmd\"\nfunc main() {}\n",
+		"cmd/cmd.go":            "package cmd\nimport \"example.com/app/api\"\nvar _ = api.X\n",
+		"api/api.go":            "package api\nimport (\n\"fmt\"\n\"example.com/app/store\"\n)\nvar X = store.Y\nvar _ = fmt.Sprint\n",
+		"store/store.go":        "package store\nvar Y = 1\n",
+		"util/util.go":          "package util\nimport _ \"embed\"\n//go:embed util.tmpl\nvar T string\n",
+		"util/util.tmpl":        "hello\n",
+		"web/web.go":            "package web\n",
+		"web/web_test.go":       "package web_test\nimport _ \"example.com/app/store\"\n",
+		"tools/go.mod":          "module example.com/tools\n",
+		"tools/tool.go":         "package tools\nimport _ \"example.com/app/store\"\n",
+		"store/testdata/bad.go": "package bad\nimport _ \"example.com/app/store\"\n",
+	}
+}
+
+// writeModule writes files into a temporary directory and returns it.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// goAffected runs goAffectedPackages on a module written from files.
+func goAffected(t *testing.T, files map[string]string, changed ...string) ([]string, bool) {
+	t.Helper()
+	dir := writeModule(t, files)
+	pkgs, full, err := goAffectedPackages(context.Background(), os.DirFS(dir), dir, changed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return pkgs, full
+}
+
+func TestGoAffectedPackages_TransitiveImporters(t *testing.T) {
+	pkgs, full := goAffected(t, goModuleFiles(), "store/store.go")
+	if full {
+		t.Fatal("expected narrowed run")
+	}
+	want := []string{".", "./api", "./cmd", "./store", "./web"}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Errorf("expected %v, got %v", want, pkgs)
+	}
+}
+
+func TestGoAffectedPackages_Leaf(t *testing.T) {
+	pkgs, _ := goAffected(t, goModuleFiles(), "util/util.go", "README.md")
+	if !reflect.DeepEqual(pkgs, []string{"./util"}) {
+		t.Errorf("expected only ./util, got %v", pkgs)
+	}
+}
+
+func TestGoAffectedPackages_FullRun(t *testing.T) {
+	tests := map[string][]string{
+		"go.sum changed":        {"go.sum"},
+		"embedded file changed": {"api/api.go", "util/util.tmpl"},
+		"testdata changed":      {"api/api.go", "store/testdata/golden.json"},
+	}
+	for name, changed := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, full := goAffected(t, goModuleFiles(), changed...); !full {
+				t.Errorf("expected full run for %v", changed)
+			}
+		})
+	}
+}
+
+func TestGoAffectedPackages_NoModule(t *testing.T) {
+	if _, full := goAffected(t, map[string]string{"a.go": "package a\n"}, "a.go"); !full {
+		t.Error("expected full run without go.mod")
+	}
+}
+
+func TestGoAffectedPackages_DeletedPackage(t *testing.T) {
+	files := goModuleFiles()
+	delete(files, "store/store.go")
+
+	pkgs, full := goAffected(t, files, "store/store.go")
+	if full {
+		t.Fatal("expected narrowed run")
+	}
+	for _, p := range pkgs {
+		if p == "./store" {
+			t.Error("deleted package should not be listed")
+		}
+	}
+	if !slices.Contains(pkgs, "./api") {
+		t.Errorf("expected importers of the deleted package to be tested, got %v", pkgs)
+	}
+}
+
+func TestRewriteGoTest(t *testing.T) {
+	cmd, ok := rewriteGoTest("go test -race ./...", []string{"./a", "./b"})
+	if !ok || cmd != "go test -race ./a ./b" {
+		t.Errorf("unexpected rewrite: %q %v", cmd, ok)
+	}
+
+	if _, ok := rewriteGoTest("go vet ./...", []string{"./a"}); ok {
+		t.Error("expected non-test command to be left alone")
+	}
+	if _, ok := rewriteGoTest("go test ./pkg/...", []string{"./a"}); ok {
+		t.Error("expected command without ./... to be left alone")
+	}
+}
+
+func TestParseModulePath(t *testing.T) {
+	if got := parseModulePath([]byte("// comment\nmodule \"example.com/x\"\n")); got != "example.com/x" {
+		t.Errorf("unexpected module path %q", got)
+	}
+	if got := parseModulePath([]byte("go 1.23\n")); got != "" {
+		t.Errorf("expected empty module path, got %q", got)
+	}
+}
+
+func TestAnalyzer_Rewrite(t *testing.T) {
+	gates := []config.Gate{
+		{Name: "go-test", Type: config.GateTypeExec, Command: "go test ./...", AffectedOnly: true},
+		{Name: "go-test-all", Type: config.GateTypeExec, Command: "go test ./..."},
+	}
+
+	a := NewAnalyzer(writeModule(t, goModuleFiles()))
+	out, err := a.Rewrite(context.Background(), gates, []string{"util/util.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if out[0].Command != "go test ./util" {
+		t.Errorf("expected narrowed command, got %q", out[0].Command)
+	}
+	if out[1].Command != "go test ./..." {
+		t.Errorf("gate without affected_only should be unchanged, got %q", out[1].Command)
+	}
+	if gates[0].Command != "go test ./..." {
+		t.Error("input gates must not be modified")
+	}
+}
+
+func TestAnalyzer_Rewrite_NoGoChanges(t *testing.T) {
+	gates := []config.Gate{{Name: "go-test", Command: "go test ./...", AffectedOnly: true}}
+
+	out, err := NewAnalyzer(writeModule(t, goModuleFiles())).Rewrite(context.Background(), gates, []string{"docs/readme.md"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Command != "go test ./..." {
+		t.Errorf("expected unchanged command, got %q", out[0].Command)
+	}
+}
+
+func TestAnalyzer_Rewrite_NestedConfig(t *testing.T) {
+	files := make(map[string]string)
+	for name, content := range goModuleFiles() {
+		files["services/app/"+name] = content
+	}
+	gates := []config.Gate{{Name: "services/app:go-test", Command: "go test ./...", AffectedOnly: true, Dir: "services/app"}}
+
+	out, err := NewAnalyzer(writeModule(t, files)).Rewrite(context.Background(), gates, []string{"README.md", "services/app/util/util.go"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Command != "go test ./util" {
+		t.Errorf("expected command narrowed within the gate's directory, got %q", out[0].Command)
+	}
+}
@@ -0,0 +1,126 @@
+package impact
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// nodeSourceExts are the file extensions considered by jest/vitest related-test selection.
+var nodeSourceExts = map[string]bool{
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+	".ts": true, ".tsx": true, ".mts": true, ".cts": true,
+	".vue": true, ".svelte": true,
+}
+
+// nodeFullRunFiles are files whose change can affect every test in the project.
+var nodeFullRunFiles = map[string]bool{
+	"package.json":      true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"jest.config.js":    true,
+	"jest.config.ts":    true,
+	"vitest.config.js":  true,
+	"vitest.config.ts":  true,
+	"vite.config.js":    true,
+	"vite.config.ts":    true,
+	"tsconfig.json":     true,
+	"babel.config.js":   true,
+}
+
+// testRunner identifies a JavaScript test runner in a gate command.
+type testRunner int
+
+const (
+	runnerNone testRunner = iota
+	runnerJest
+	runnerVitest
+)
+
+// detectNodeRunner reports which JavaScript test runner a command invokes.
+func detectNodeRunner(command string) testRunner {
+	for _, f := range strings.Fields(command) {
+		switch path.Base(f) {
+		case "jest":
+			return runnerJest
+		case "vitest":
+			return runnerVitest
+		}
+	}
+	return runnerNone
+}
+
+// nodeRelatedFiles returns the staged source files that still exist, for passing to
+// the test runner's related-test selection. full is true when a config or lockfile
+// change means every test must run.
+func nodeRelatedFiles(fsys fs.FS, changed []string) (files []string, full bool) {
+	for _, f := range changed {
+		f = filepath.ToSlash(f)
+		if nodeFullRunFiles[path.Base(f)] {
+			return nil, true
+		}
+		if !nodeSourceExts[path.Ext(f)] {
+			continue
+		}
+		// Deleted files cannot be resolved by the runner; their dependents fail to import anyway.
+		if _, err := fs.Stat(fsys, f); err != nil {
+			continue
+		}
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, false
+}
+
+// rewriteNodeTest adds related-test selection for files to a jest or vitest command.
+//   - jest:   appends "--findRelatedTests <files>"
+//   - vitest: switches to "vitest related --run <files>"
+//
+// The files are appended at the end of the command, so compound commands
+// (e.g., "npx jest && echo ok" or "npx jest | tee log") are left as is.
+func rewriteNodeTest(command string, runner testRunner, files []string) (string, bool) {
+	if compoundCommand(command) {
+		return command, false
+	}
+	quoted := make([]string, len(files))
+	for i, f := range files {
+		quoted[i] = shellQuote(f)
+	}
+	list := strings.Join(quoted, " ")
+
+	switch runner {
+	case runnerJest:
+		return command + " --findRelatedTests " + list, true
+	case runnerVitest:
+		fields := strings.Fields(command)
+		for i, f := range fields {
+			if path.Base(f) != "vitest" {
+				continue
+			}
+			rest := fields[i+1:]
+			if len(rest) > 0 && (rest[0] == "run" || rest[0] == "related") {
+				rest = rest[1:]
+			}
+			out := append([]string{}, fields[:i+1]...)
+			out = append(out, "related", "--run")
+			out = append(out, rest...)
+			return strings.Join(out, " ") + " " + list, true
+		}
+	}
+	return command, false
+}
+
+// compoundCommand reports whether command has shell operators that run other
+// commands: lists (&&, ||, ;, &), pipes, and command substitution. Quoted
+// operators count too, which errs towards the full run.
+func compoundCommand(command string) bool {
+	return strings.ContainsAny(command, "&|;\n`") || strings.Contains(command, "$(")
+}
+
+// shellQuote wraps s in single quotes for safe use in a shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
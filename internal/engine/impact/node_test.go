@@ -0,0 +1,122 @@
+package impact
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+)
+
+func nodeProjectFS() fstest.MapFS {
+	return fstest.MapFS{
+		"package.json":      {Data: []byte("{}")},
+		"src/app.ts":        {Data: []byte("export const a = 1")},
+		"src/it's.tsx":      {Data: []byte("export const b = 2")},
+		"src/app.test.ts":   {Data: []byte("import { a } from './app'")},
+		"docs/readme.md":    {Data: []byte("# docs")},
+		"scripts/build.mjs": {Data: []byte("")},
+	}
+}
+
+func TestDetectNodeRunner(t *testing.T) {
+	tests := map[string]testRunner{
+		"npx jest":                     runnerJest,
+		"node_modules/.bin/jest --ci":  runnerJest,
+		"npx vitest run":               runnerVitest,
+		"pnpm exec vitest --coverage":  runnerVitest,
+		"npm test":                     runnerNone,
+		"go test ./...":                runnerNone,
+		"npx eslint --ext .js jest.js": runnerNone,
+	}
+	for cmd, want := range tests {
+		if got := detectNodeRunner(cmd); got != want {
+			t.Errorf("detectNodeRunner(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
+
+func TestNodeRelatedFiles(t *testing.T) {
+	files, full := nodeRelatedFiles(nodeProjectFS(), []string{"src/app.ts", "docs/readme.md", "src/deleted.ts"})
+	if full {
+		t.Fatal("expected narrowed selection")
+	}
+	if !reflect.DeepEqual(files, []string{"src/app.ts"}) {
+		t.Errorf("unexpected files: %v", files)
+	}
+
+	if _, full := nodeRelatedFiles(nodeProjectFS(), []string{"src/app.ts", "package.json"}); !full {
+		t.Error("expected full run when package.json changes")
+	}
+}
+
+func TestRewriteNodeTest(t *testing.T) {
+	tests := []struct {
+		cmd    string
+		runner testRunner
+		want   string
+	}{
+		{"npx jest --ci", runnerJest, "npx jest --ci --findRelatedTests 'src/a.ts' 'src/it'\\''s.tsx'"},
+		{"npx vitest run", runnerVitest, "npx vitest related --run 'src/a.ts' 'src/it'\\''s.tsx'"},
+		{"npx vitest --coverage", runnerVitest, "npx vitest related --run --coverage 'src/a.ts' 'src/it'\\''s.tsx'"},
+	}
+	for _, tt := range tests {
+		got, ok := rewriteNodeTest(tt.cmd, tt.runner, []string{"src/a.ts", "src/it's.tsx"})
+		if !ok || got != tt.want {
+			t.Errorf("rewriteNodeTest(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteNodeTest_CompoundCommand(t *testing.T) {
+	for _, cmd := range []string{
+		"npx jest && echo ok",
+		"npx jest || true",
+		"npx jest --ci | tee jest.log",
+		"npm run build; npx jest",
+		"npx vitest run && echo ok",
+		"npx jest $(cat jest.args)",
+	} {
+		runner := detectNodeRunner(cmd)
+		if got, ok := rewriteNodeTest(cmd, runner, []string{"src/a.ts"}); ok || got != cmd {
+			t.Errorf("rewriteNodeTest(%q) = %q, %v; want the command unchanged", cmd, got, ok)
+		}
+	}
+}
+
+func TestAnalyzer_Rewrite_JestCompound(t *testing.T) {
+	gates := []config.Gate{{Name: "jest", Command: "npx jest && echo ok", AffectedOnly: true}}
+
+	out, err := NewAnalyzerFS(nodeProjectFS()).Rewrite(context.Background(), gates, []string{"src/app.ts"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Command != "npx jest && echo ok" {
+		t.Errorf("expected the full command, got %q", out[0].Command)
+	}
+}
+
+func TestAnalyzer_Rewrite_Jest(t *testing.T) {
+	gates := []config.Gate{{Name: "jest", Command: "npx jest", AffectedOnly: true}}
+
+	out, err := NewAnalyzerFS(nodeProjectFS()).Rewrite(context.Background(), gates, []string{"src/app.ts"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Command != "npx jest --findRelatedTests 'src/app.ts'" {
+		t.Errorf("unexpected command: %q", out[0].Command)
+	}
+}
+
+func TestAnalyzer_Rewrite_VitestNoSources(t *testing.T) {
+	gates := []config.Gate{{Name: "vitest", Command: "npx vitest run", AffectedOnly: true}}
+
+	out, err := NewAnalyzerFS(nodeProjectFS()).Rewrite(context.Background(), gates, []string{"docs/readme.md"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Command != "npx vitest run" {
+		t.Errorf("expected unchanged command, got %q", out[0].Command)
+	}
+}
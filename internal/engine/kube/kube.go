@@ -0,0 +1,429 @@
+// Package kube runs container gates as Kubernetes pods instead of local Docker
+// containers, for CI fleets without a Docker daemon. It drives the cluster
+// through kubectl, so the kubeconfig, contexts, and auth plugins users already
+// have keep working.
+//
+// Each gate execution is one pod: an init container receives a tarball of the
+// workspace into a shared emptyDir volume, then the gate container runs the
+// command in it. Logs are streamed back and the exit code is read from the pod
+// status. The pod is deleted afterwards. Pods run as an unprivileged user with
+// a read-only root file system and bounded CPU and memory.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// DefaultInitImage is the image of the init container that unpacks the workspace.
+const DefaultInitImage = "busybox:1.36"
+
+// Default resources of a gate pod's containers, as Kubernetes quantities.
+const (
+	DefaultCPU    = "1"
+	DefaultMemory = "2Gi"
+)
+
+const (
+	// workspacePath is where the workspace is mounted, as with Docker.
+	workspacePath = "/workspace"
+	// readyFile is created once the workspace is unpacked, releasing the init container.
+	readyFile = workspacePath + "/.gatekeeper-ready"
+	// gateContainer is the name of the container that runs the gate.
+	gateContainer = "gate"
+	// initContainer is the name of the init container that receives the workspace.
+	initContainer = "workspace"
+	// labelManaged marks gate pods, like gatekeeper's Docker containers.
+	labelManaged = "gatekeeper.managed"
+	// pollInterval is how often pod status is polled.
+	pollInterval = time.Second
+	// startTimeout bounds how long a pod may take to be scheduled and pull images.
+	startTimeout = 5 * time.Minute
+	// podUser is the unprivileged user and group gate pods run as, the
+	// "nonroot" user of distroless images.
+	podUser = 65532
+	// tmpPath is a writable scratch directory, also HOME, since the root file
+	// system is read-only.
+	tmpPath = "/tmp"
+)
+
+// Config selects the cluster and namespace gates run in.
+type Config struct {
+	// Kubeconfig is the kubeconfig file (default: kubectl's own resolution).
+	Kubeconfig string
+	// Context is the kubeconfig context (default: the current context).
+	Context string
+	// Namespace is where gate pods are created (default: the context's namespace).
+	Namespace string
+	// ServiceAccount is the service account of gate pods.
+	ServiceAccount string
+	// InitImage is the image that unpacks the workspace (default DefaultInitImage).
+	InitImage string
+	// CPU is the CPU request and limit of gate containers (default DefaultCPU).
+	CPU string
+	// Memory is the memory request and limit of gate containers (default DefaultMemory).
+	Memory string
+}
+
+// kubectlFunc runs kubectl with stdin and stdout attached.
+type kubectlFunc func(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error
+
+// Backend runs gates as pods. It implements the pool and executor roles of
+// container gates: GetOrCreate only records what to run, and Run creates the pod.
+type Backend struct {
+	cfg     Config
+	kubectl kubectlFunc
+
+	mu    sync.Mutex
+	slots map[string]slot
+	next  int
+}
+
+// slot is a gate's image and workspace, as requested from GetOrCreate.
+type slot struct {
+	image     string
+	workspace string
+}
+
+// NewBackend creates a Backend for cfg.
+func NewBackend(cfg Config) *Backend {
+	if cfg.InitImage == "" {
+		cfg.InitImage = DefaultInitImage
+	}
+	if cfg.CPU == "" {
+		cfg.CPU = DefaultCPU
+	}
+	if cfg.Memory == "" {
+		cfg.Memory = DefaultMemory
+	}
+	return &Backend{cfg: cfg, kubectl: runKubectl, slots: make(map[string]slot)}
+}
+
+// Check verifies that kubectl can reach the cluster and create pods.
+func (b *Backend) Check(ctx context.Context) error {
+	var out bytes.Buffer
+	if err := b.run(ctx, nil, &out, "auth", "can-i", "create", "pods"); err != nil {
+		return fmt.Errorf("❌ cannot reach the Kubernetes cluster: %w", err)
+	}
+	if strings.TrimSpace(out.String()) != "yes" {
+		return errors.New("❌ not allowed to create pods in the Kubernetes namespace")
+	}
+	return nil
+}
+
+// ErrWritable is returned for writable gates: their changes would stay in the
+// pod, so fixes would silently be lost.
+var ErrWritable = errors.New("writable gates are not supported by the kubernetes runtime")
+
+// GetOrCreate records the image and workspace of a gate and returns an id for
+// Run. The slot is forgotten by Release. Writable gates are refused with
+// ErrWritable.
+func (b *Backend) GetOrCreate(_ context.Context, img, projectPath string, writable bool) (string, error) {
+	if writable {
+		return "", ErrWritable
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.next++
+	id := "k8s-" + strconv.Itoa(b.next)
+	b.slots[id] = slot{image: img, workspace: projectPath}
+	return id, nil
+}
+
+// CreateEphemeral is GetOrCreate: every run gets a new pod anyway. Setup
+// commands are not supported.
+func (b *Backend) CreateEphemeral(ctx context.Context, spec pool.ContainerSpec, projectPath string, writable bool) (string, error) {
+	if spec.Setup != "" {
+		return "", errors.New("setup is not supported by the kubernetes runtime")
+	}
+	return b.GetOrCreate(ctx, spec.Image, projectPath, writable)
+}
+
+// RemoveEphemeral forgets the slot id; its pod was deleted by Run.
+func (b *Backend) RemoveEphemeral(ctx context.Context, id string) error {
+	return b.Release(ctx, id)
+}
+
+// Release forgets the slot id once its gate has run; its pod was deleted by Run.
+func (b *Backend) Release(_ context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.slots, id)
+	return nil
+}
+
+// Run executes command in a new pod for the slot id and deletes the pod
+// afterwards. The pod's stdout and stderr are both returned as Stdout, since
+// Kubernetes logs interleave them.
+func (b *Backend) Run(ctx context.Context, id, command string, timeout time.Duration) (*pool.ExecResult, error) {
+	b.mu.Lock()
+	s, ok := b.slots[id]
+	b.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown gate slot %q", id)
+	}
+
+	log := logger.FromContext(ctx)
+	start := time.Now()
+	name, err := podName()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := podManifest(name, s.image, command, timeout, b.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("creating gate pod", "pod", name, "image", s.image)
+	if err := b.run(ctx, bytes.NewReader(manifest), io.Discard, "create", "-f", "-"); err != nil {
+		return nil, fmt.Errorf("creating pod: %w", err)
+	}
+	defer func() {
+		// Delete even if the run was cancelled.
+		delCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := b.run(delCtx, nil, io.Discard, "delete", "pod", name, "--wait=false", "--ignore-not-found"); err != nil {
+			log.Warn("could not delete gate pod", "pod", name, "error", err)
+		}
+	}()
+
+	if err := b.upload(ctx, name, s.workspace); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := b.waitFor(runCtx, name, gateStatePath(gateContainer, "running"), gateStatePath(gateContainer, "terminated")); err != nil {
+		return nil, err
+	}
+	var logs bytes.Buffer
+	if err := b.run(runCtx, nil, &logs, "logs", "--follow", name, "-c", gateContainer); err != nil {
+		if runCtx.Err() != nil {
+			return nil, runCtx.Err()
+		}
+		return nil, fmt.Errorf("streaming logs: %w", err)
+	}
+	exitCode, err := b.exitCode(runCtx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pool.ExecResult{Stdout: logs.Bytes(), ExitCode: exitCode, Duration: time.Since(start)}
+	log.Info("gate pod completed", "pod", name, "exit_code", exitCode, "duration", result.Duration)
+	return result, nil
+}
+
+// upload waits for the init container and streams the workspace into it.
+func (b *Backend) upload(ctx context.Context, name, workspace string) error {
+	startCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	if err := b.waitFor(startCtx, name, `{.status.initContainerStatuses[0].state.running}`); err != nil {
+		return fmt.Errorf("waiting for pod %s to start: %w", name, err)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTar(pw, workspace))
+	}()
+	err := b.run(ctx, pr, io.Discard, "exec", "-i", name, "-c", initContainer, "--",
+		"sh", "-c", "tar -x -C "+workspacePath+" && touch "+readyFile)
+	_ = pr.Close()
+	if err != nil {
+		return fmt.Errorf("uploading workspace: %w", err)
+	}
+	return nil
+}
+
+// waitFor polls the pod until any of the JSONPath expressions is non-empty.
+func (b *Backend) waitFor(ctx context.Context, name string, paths ...string) error {
+	for {
+		for _, path := range paths {
+			var out bytes.Buffer
+			if err := b.run(ctx, nil, &out, "get", "pod", name, "-o", "jsonpath="+path); err != nil {
+				return err
+			}
+			if strings.TrimSpace(out.String()) != "" {
+				return nil
+			}
+		}
+		if failed, err := b.podFailed(ctx, name); err != nil || failed != "" {
+			if err == nil {
+				err = fmt.Errorf("pod %s failed: %s", name, failed)
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// podFailed returns the reason a pod cannot run (e.g., ErrImagePull), if any.
+func (b *Backend) podFailed(ctx context.Context, name string) (string, error) {
+	var out bytes.Buffer
+	path := `{.status.phase} {.status.initContainerStatuses[*].state.waiting.reason} {.status.containerStatuses[*].state.waiting.reason}`
+	if err := b.run(ctx, nil, &out, "get", "pod", name, "-o", "jsonpath="+path); err != nil {
+		return "", err
+	}
+	for _, word := range strings.Fields(out.String()) {
+		switch word {
+		case "Failed", "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CreateContainerConfigError":
+			return word, nil
+		}
+	}
+	return "", nil
+}
+
+// exitCode waits for the gate container to terminate and returns its exit code.
+func (b *Backend) exitCode(ctx context.Context, name string) (int, error) {
+	path := gateStatePath(gateContainer, "terminated.exitCode")
+	for {
+		var out bytes.Buffer
+		if err := b.run(ctx, nil, &out, "get", "pod", name, "-o", "jsonpath="+path); err != nil {
+			return 0, err
+		}
+		if s := strings.TrimSpace(out.String()); s != "" {
+			code, err := strconv.Atoi(s)
+			if err != nil {
+				return 0, fmt.Errorf("parsing exit code %q: %w", s, err)
+			}
+			return code, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// gateStatePath is the JSONPath of a state field of the named container.
+func gateStatePath(container, field string) string {
+	return `{.status.containerStatuses[?(@.name=="` + container + `")].state.` + field + `}`
+}
+
+// podManifest returns the JSON manifest of a gate pod.
+func podManifest(name, image, command string, timeout time.Duration, cfg Config) ([]byte, error) {
+	mounts := []map[string]any{
+		{"name": "workspace", "mountPath": workspacePath},
+		{"name": "tmp", "mountPath": tmpPath},
+	}
+	resources := map[string]any{
+		"cpu":    cfg.CPU,
+		"memory": cfg.Memory,
+	}
+	// [SEC] Gates run untrusted project code: no root, no privilege
+	// escalation, and nothing written outside the mounted volumes.
+	containerSecurity := map[string]any{
+		"allowPrivilegeEscalation": false,
+		"readOnlyRootFilesystem":   true,
+		"capabilities":             map[string]any{"drop": []string{"ALL"}},
+	}
+	env := []map[string]any{{"name": "HOME", "value": tmpPath}}
+	spec := map[string]any{
+		"restartPolicy": "Never",
+		// The cluster kills pods that outlive the run, e.g. if gatekeeper is killed.
+		"activeDeadlineSeconds": int64((startTimeout + timeout).Seconds()),
+		"securityContext": map[string]any{
+			"runAsNonRoot": true,
+			"runAsUser":    podUser,
+			"runAsGroup":   podUser,
+			"fsGroup":      podUser,
+		},
+		"volumes": []map[string]any{
+			{"name": "workspace", "emptyDir": map[string]any{}},
+			{"name": "tmp", "emptyDir": map[string]any{}},
+		},
+		"initContainers": []map[string]any{{
+			"name":            initContainer,
+			"image":           cfg.InitImage,
+			"command":         []string{"sh", "-c", "until [ -f " + readyFile + " ]; do sleep 1; done; rm " + readyFile},
+			"volumeMounts":    mounts,
+			"securityContext": containerSecurity,
+			"resources":       map[string]any{"requests": resources, "limits": resources},
+		}},
+		"containers": []map[string]any{{
+			"name":            gateContainer,
+			"image":           image,
+			"workingDir":      workspacePath,
+			"command":         []string{"sh", "-c", command},
+			"env":             env,
+			"volumeMounts":    mounts,
+			"securityContext": containerSecurity,
+			"resources":       map[string]any{"requests": resources, "limits": resources},
+		}},
+	}
+	if cfg.ServiceAccount != "" {
+		spec["serviceAccountName"] = cfg.ServiceAccount
+	}
+	manifest := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]any{
+			"name":   name,
+			"labels": map[string]string{labelManaged: "true"},
+		},
+		"spec": spec,
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("encoding pod manifest: %w", err)
+	}
+	return data, nil
+}
+
+// podName returns a unique gate pod name.
+func podName() (string, error) {
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("naming pod: %w", err)
+	}
+	return "gatekeeper-" + hex.EncodeToString(suffix), nil
+}
+
+// run invokes kubectl with the configured kubeconfig, context, and namespace.
+func (b *Backend) run(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	var global []string
+	if b.cfg.Kubeconfig != "" {
+		global = append(global, "--kubeconfig", b.cfg.Kubeconfig)
+	}
+	if b.cfg.Context != "" {
+		global = append(global, "--context", b.cfg.Context)
+	}
+	if b.cfg.Namespace != "" {
+		global = append(global, "--namespace", b.cfg.Namespace)
+	}
+	return b.kubectl(ctx, stdin, stdout, append(global, args...)...)
+}
+
+// runKubectl runs the kubectl CLI, including its stderr in errors.
+func runKubectl(ctx context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	cmd := exec.CommandContext(ctx, "kubectl", args...) // #nosec G204 -- arguments are built by gatekeeper
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return errors.New("the kubernetes runtime needs kubectl on PATH")
+		}
+		return fmt.Errorf("kubectl %s: %w (%s)", args[len(args)-1], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
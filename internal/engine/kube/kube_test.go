@@ -0,0 +1,248 @@
+package kube
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+// fakeKubectl answers kubectl calls for one gate pod.
+type fakeKubectl struct {
+	mu       sync.Mutex
+	calls    [][]string
+	manifest map[string]any
+	uploaded map[string]string
+	exitCode string
+	reason   string
+}
+
+func (f *fakeKubectl) run(_ context.Context, stdin io.Reader, stdout io.Writer, args ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, args)
+
+	cmd := args[:]
+	for len(cmd) > 0 && strings.HasPrefix(cmd[0], "--") {
+		cmd = cmd[2:]
+	}
+	switch cmd[0] {
+	case "auth":
+		_, _ = io.WriteString(stdout, "yes\n")
+	case "create":
+		return json.NewDecoder(stdin).Decode(&f.manifest)
+	case "exec":
+		f.uploaded = map[string]string{}
+		tr := tar.NewReader(stdin)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			data, _ := io.ReadAll(tr)
+			f.uploaded[hdr.Name] = string(data) + hdr.Linkname
+		}
+	case "logs":
+		_, _ = io.WriteString(stdout, "main.go:1: lint error\n")
+	case "get":
+		path := strings.TrimPrefix(cmd[len(cmd)-1], "jsonpath=")
+		switch {
+		case strings.HasPrefix(path, "{.status.phase}"):
+			_, _ = io.WriteString(stdout, "Pending "+f.reason)
+		case strings.Contains(path, "exitCode"):
+			_, _ = io.WriteString(stdout, f.exitCode)
+		case strings.Contains(path, "terminated"), f.reason != "":
+		default:
+			_, _ = io.WriteString(stdout, "map[startedAt:now]")
+		}
+	}
+	return nil
+}
+
+func TestBackend_Run(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"main.go": "package main\n", "pkg/a.go": "package pkg\n", ".git/HEAD": "ref"} {
+		path := filepath.Join(dir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0o750)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("main.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeKubectl{exitCode: "3"}
+	b := NewBackend(Config{Namespace: "ci", ServiceAccount: "gates"})
+	b.kubectl = fake.run
+
+	id, err := b.GetOrCreate(context.Background(), "golang:1.25", dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := b.Run(context.Background(), id, "go vet ./...", time.Minute)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.ExitCode != 3 || string(result.Stdout) != "main.go:1: lint error\n" {
+		t.Errorf("result = %d %q", result.ExitCode, result.Stdout)
+	}
+
+	want := map[string]string{"main.go": "package main\n", "pkg": "", "pkg/a.go": "package pkg\n", "link.go": "main.go"}
+	if len(fake.uploaded) != len(want) {
+		t.Errorf("uploaded %v, want %v", fake.uploaded, want)
+	}
+	for name, content := range want {
+		if fake.uploaded[name] != content {
+			t.Errorf("uploaded %s = %q, want %q", name, fake.uploaded[name], content)
+		}
+	}
+
+	spec := fake.manifest["spec"].(map[string]any)
+	gate := spec["containers"].([]any)[0].(map[string]any)
+	if gate["image"] != "golang:1.25" || gate["workingDir"] != "/workspace" {
+		t.Errorf("unexpected gate container %v", gate)
+	}
+	if cmd := gate["command"].([]any); cmd[2] != "go vet ./..." {
+		t.Errorf("command = %v", cmd)
+	}
+	if spec["serviceAccountName"] != "gates" || spec["restartPolicy"] != "Never" {
+		t.Errorf("unexpected spec %v", spec)
+	}
+	if init := spec["initContainers"].([]any)[0].(map[string]any); init["image"] != DefaultInitImage {
+		t.Errorf("init image = %v", init["image"])
+	}
+	if pod := spec["securityContext"].(map[string]any); pod["runAsNonRoot"] != true || pod["runAsUser"] != float64(podUser) {
+		t.Errorf("pod security context = %v", pod)
+	}
+	if sec := gate["securityContext"].(map[string]any); sec["allowPrivilegeEscalation"] != false || sec["readOnlyRootFilesystem"] != true {
+		t.Errorf("gate security context = %v", sec)
+	}
+	limits := gate["resources"].(map[string]any)["limits"].(map[string]any)
+	if limits["cpu"] != DefaultCPU || limits["memory"] != DefaultMemory {
+		t.Errorf("limits = %v, want the defaults", limits)
+	}
+
+	for _, call := range fake.calls {
+		if call[0] != "--namespace" || call[1] != "ci" {
+			t.Fatalf("call %v does not select the namespace", call)
+		}
+	}
+	if last := fake.calls[len(fake.calls)-1]; last[2] != "delete" {
+		t.Errorf("the pod was not deleted: %v", last)
+	}
+}
+
+func TestPodManifest_Resources(t *testing.T) {
+	data, err := podManifest("gatekeeper-test", "alpine", "true", time.Minute, Config{CPU: "500m", Memory: "1Gi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Spec struct {
+			Containers []struct {
+				Resources struct {
+					Requests map[string]string `json:"requests"`
+					Limits   map[string]string `json:"limits"`
+				} `json:"resources"`
+			} `json:"containers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	res := manifest.Spec.Containers[0].Resources
+	if res.Limits["cpu"] != "500m" || res.Limits["memory"] != "1Gi" || res.Requests["memory"] != "1Gi" {
+		t.Errorf("resources = %+v, want the configured ones", res)
+	}
+}
+
+func TestBackend_RunImagePullFailure(t *testing.T) {
+	fake := &fakeKubectl{reason: "ErrImagePull"}
+	b := NewBackend(Config{})
+	b.kubectl = fake.run
+
+	id, _ := b.GetOrCreate(context.Background(), "missing:latest", t.TempDir(), false)
+	_, err := b.Run(context.Background(), id, "true", time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "ErrImagePull") {
+		t.Fatalf("err = %v, want the pull failure", err)
+	}
+	if last := fake.calls[len(fake.calls)-1]; last[0] != "delete" {
+		t.Errorf("the pod was not deleted: %v", last)
+	}
+}
+
+func TestBackend_Check(t *testing.T) {
+	fake := &fakeKubectl{}
+	b := NewBackend(Config{Kubeconfig: "/tmp/kubeconfig", Context: "ci"})
+	b.kubectl = fake.run
+	if err := b.Check(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(fake.calls[0], " ")
+	if got != "--kubeconfig /tmp/kubeconfig --context ci auth can-i create pods" {
+		t.Errorf("call = %q", got)
+	}
+}
+
+func TestBackend_RunUnknownSlot(t *testing.T) {
+	if _, err := NewBackend(Config{}).Run(context.Background(), "k8s-9", "true", time.Second); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestBackend_Release(t *testing.T) {
+	b := NewBackend(Config{})
+	id, err := b.GetOrCreate(context.Background(), "alpine", "/proj", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.Release(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b.slots) != 0 {
+		t.Errorf("expected the slot to be evicted, got %v", b.slots)
+	}
+}
+
+func TestBackend_RejectsWritable(t *testing.T) {
+	b := NewBackend(Config{})
+	if _, err := b.GetOrCreate(context.Background(), "alpine", "/proj", true); !errors.Is(err, ErrWritable) {
+		t.Errorf("expected ErrWritable, got %v", err)
+	}
+	if _, err := b.CreateEphemeral(context.Background(), pool.ContainerSpec{Image: "alpine"}, "/proj", true); !errors.Is(err, ErrWritable) {
+		t.Errorf("expected ErrWritable for ephemeral gates, got %v", err)
+	}
+}
+
+func TestBackend_Ephemeral(t *testing.T) {
+	b := NewBackend(Config{})
+	if _, err := b.CreateEphemeral(context.Background(), pool.ContainerSpec{Image: "alpine", Setup: "apk add git"}, "/proj", false); err == nil {
+		t.Error("expected setup to be rejected")
+	}
+	id, err := b.CreateEphemeral(context.Background(), pool.ContainerSpec{Image: "alpine"}, "/proj", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := b.RemoveEphemeral(context.Background(), id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := b.Run(context.Background(), id, "true", time.Second); err == nil {
+		t.Error("expected the removed slot to be unknown")
+	}
+}
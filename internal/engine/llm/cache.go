@@ -0,0 +1,185 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// Cache stores LLM review results on disk, keyed by a hash of provider, model, and prompt.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCache creates a Cache in dir whose entries expire after ttl.
+func NewCache(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+// DefaultCacheDir returns the user-level LLM cache directory (~/.cache/gatekeeper/llm on Linux).
+func DefaultCacheDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating user cache directory: %w", err)
+	}
+	return filepath.Join(base, "gatekeeper", "llm"), nil
+}
+
+// cacheEntry is the on-disk format of a cached review.
+type cacheEntry struct {
+	CreatedAt time.Time                `json:"created_at"`
+	Errors    []parser.StructuredError `json:"errors"`
+	Summary   string                   `json:"summary,omitempty"`
+}
+
+// cacheKey returns the content address of a review request.
+func cacheKey(provider, model, prompt string) string {
+	h := sha256.New()
+	for _, part := range []string{provider, model, prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached result for key. ok is false if there is no fresh entry.
+func (c *Cache) Get(key string) (errs []parser.StructuredError, ok bool, err error) {
+	entry, err := c.get(key)
+	if entry == nil {
+		return nil, false, err
+	}
+	return entry.Errors, true, nil
+}
+
+// GetSummary returns the cached summary for key. ok is false if there is no fresh entry.
+func (c *Cache) GetSummary(key string) (summary string, ok bool, err error) {
+	entry, err := c.get(key)
+	if entry == nil {
+		return "", false, err
+	}
+	return entry.Summary, true, nil
+}
+
+// get reads the entry for key, or returns nil if there is no fresh entry.
+func (c *Cache) get(key string) (*cacheEntry, error) {
+	path := filepath.Join(c.dir, key+".json")
+	data, err := os.ReadFile(path) // #nosec G304 -- key is a hex digest inside the cache directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if c.now().Sub(entry.CreatedAt) > c.ttl {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// Put stores a result under key.
+func (c *Cache) Put(key string, errs []parser.StructuredError) error {
+	return c.put(key, cacheEntry{CreatedAt: c.now(), Errors: errs})
+}
+
+// PutSummary stores a summary under key.
+func (c *Cache) PutSummary(key, summary string) error {
+	return c.put(key, cacheEntry{CreatedAt: c.now(), Summary: summary})
+}
+
+// put writes entry under key.
+func (c *Cache) put(key string, entry cacheEntry) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("creating LLM cache directory: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %w", err)
+	}
+	path := filepath.Join(c.dir, key+".json")
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// CachingClient wraps a Client and reuses review results for identical prompts.
+// The cache is best-effort: read and write failures are logged, never returned.
+type CachingClient struct {
+	inner    Client
+	cache    *Cache
+	provider string
+	model    string
+}
+
+// NewCachingClient wraps inner with cache. provider and model are part of the cache key.
+func NewCachingClient(inner Client, cache *Cache, provider, model string) *CachingClient {
+	return &CachingClient{inner: inner, cache: cache, provider: provider, model: model}
+}
+
+// Review returns the cached result for prompt if it is fresh, otherwise calls the
+// wrapped client and caches a successful result.
+func (c *CachingClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	log := logger.FromContext(ctx)
+	key := cacheKey(c.provider, c.model, prompt)
+
+	errs, ok, err := c.cache.Get(key)
+	if err != nil {
+		log.Warn("reading LLM cache failed", "error", err)
+	}
+	if ok {
+		log.Info("LLM review served from cache", "provider", c.provider, "model", c.model)
+		return errs, nil
+	}
+
+	errs, err = c.inner.Review(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cache.Put(key, errs); err != nil {
+		log.Warn("writing LLM cache failed", "error", err)
+	}
+	return errs, nil
+}
+
+// Summarize returns the cached summary for prompt if it is fresh, otherwise calls
+// the wrapped client and caches a successful summary.
+func (c *CachingClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	log := logger.FromContext(ctx)
+	// Summaries are keyed apart from reviews of the same prompt.
+	key := cacheKey(c.provider, c.model, "summary\x00"+prompt)
+
+	summary, ok, err := c.cache.GetSummary(key)
+	if err != nil {
+		log.Warn("reading LLM cache failed", "error", err)
+	}
+	if ok {
+		log.Info("LLM summary served from cache", "provider", c.provider, "model", c.model)
+		return summary, nil
+	}
+
+	summary, err = c.inner.Summarize(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	if err := c.cache.PutSummary(key, summary); err != nil {
+		log.Warn("writing LLM cache failed", "error", err)
+	}
+	return summary, nil
+}
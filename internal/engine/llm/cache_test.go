@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// countingClient counts Review and Summarize calls.
+type countingClient struct {
+	MockClient
+	calls int
+}
+
+func (c *countingClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	c.calls++
+	return c.MockClient.Review(ctx, prompt)
+}
+
+func (c *countingClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	c.calls++
+	return c.MockClient.Summarize(ctx, prompt)
+}
+
+func TestCachingClient_ReusesResult(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Result: []parser.StructuredError{{Message: "hardcoded key", Severity: "error"}}}}
+	cache := NewCache(t.TempDir(), time.Hour)
+	client := NewCachingClient(inner, cache, "gemini", DefaultGeminiModel)
+
+	for i := 0; i < 2; i++ {
+		errs, err := client.Review(context.Background(), "same diff")
+		if err != nil {
+			t.Fatalf("review %d: %v", i, err)
+		}
+		if len(errs) != 1 || errs[0].Message != "hardcoded key" {
+			t.Errorf("review %d: unexpected result %+v", i, errs)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", inner.calls)
+	}
+
+	if _, err := client.Review(context.Background(), "different diff"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCachingClient(inner, cache, "gemini", "other-model").Review(context.Background(), "same diff"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected new prompt and model to miss the cache, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_CachesSummariesApartFromReviews(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Summary: "Renames Load to LoadConfig."}}
+	client := NewCachingClient(inner, NewCache(t.TempDir(), time.Hour), "gemini", DefaultGeminiModel)
+
+	for i := 0; i < 2; i++ {
+		summary, err := client.Summarize(context.Background(), "same diff")
+		if err != nil {
+			t.Fatalf("summary %d: %v", i, err)
+		}
+		if summary != "Renames Load to LoadConfig." {
+			t.Errorf("summary %d: got %q", i, summary)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 provider call, got %d", inner.calls)
+	}
+
+	if _, err := client.Review(context.Background(), "same diff"); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected a review of the same prompt to miss the summary cache, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_Expiry(t *testing.T) {
+	inner := &countingClient{}
+	cache := NewCache(t.TempDir(), time.Hour)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	client := NewCachingClient(inner, cache, "gemini", DefaultGeminiModel)
+
+	_, _ = client.Review(context.Background(), "diff")
+	now = now.Add(2 * time.Hour)
+	_, _ = client.Review(context.Background(), "diff")
+
+	if inner.calls != 2 {
+		t.Errorf("expected expired entry to be refreshed, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_ServesCacheOverBudget(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Result: []parser.StructuredError{{Message: "issue"}}, Usage: Usage{PromptTokens: 90}}}
+	client := NewCachingClient(NewBudgetClient(inner, NewBudget(100)), NewCache(t.TempDir(), time.Hour), "gemini", DefaultGeminiModel)
+
+	if _, err := client.Review(context.Background(), "diff"); err != nil {
+		t.Fatal(err)
+	}
+	// The budget no longer fits a review, but the cached one is still served.
+	errs, err := client.Review(context.Background(), "diff")
+	if err != nil || len(errs) != 1 {
+		t.Fatalf("expected the cached review, got %v, %v", errs, err)
+	}
+	if _, err := client.Review(context.Background(), string(make([]byte, 400))); !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Errorf("expected uncached reviews to be refused, got %v", err)
+	}
+}
+
+func TestCachingClient_ErrorsNotCached(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Err: errors.New("quota exceeded")}}
+	client := NewCachingClient(inner, NewCache(t.TempDir(), time.Hour), "gemini", DefaultGeminiModel)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Review(context.Background(), "diff"); err == nil {
+			t.Fatal("expected provider error")
+		}
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected failed reviews to be retried, got %d calls", inner.calls)
+	}
+}
+
+func TestCachingClient_CorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	inner := &countingClient{}
+	client := NewCachingClient(inner, NewCache(dir, time.Hour), "gemini", DefaultGeminiModel)
+
+	key := cacheKey("gemini", DefaultGeminiModel, "diff")
+	if err := os.WriteFile(filepath.Join(dir, key+".json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Review(context.Background(), "diff"); err != nil {
+		t.Fatalf("expected corrupt entry to be ignored, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected provider call, got %d", inner.calls)
+	}
+}
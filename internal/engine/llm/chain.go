@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// ChainLink is a provider of a ChainClient.
+type ChainLink struct {
+	// Name is the provider as configured, such as "gemini-3-pro" or "ollama/llama3".
+	Name   string
+	Client Client
+}
+
+// ChainClient implements Client with a list of providers tried in order: when
+// one fails, for example because it is rate limited or down, the next one is
+// asked. The Tool field of findings records the provider that produced them.
+type ChainClient struct {
+	links []ChainLink
+}
+
+// NewChainClient creates a ChainClient trying links in order.
+func NewChainClient(links ...ChainLink) *ChainClient {
+	return &ChainClient{links: links}
+}
+
+// Review asks each provider in turn until one succeeds.
+func (c *ChainClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	var result []parser.StructuredError
+	name, err := c.try(ctx, func(client Client) (err error) {
+		result, err = client.Review(ctx, prompt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		result[i].Tool = name
+	}
+	return result, nil
+}
+
+// Summarize asks each provider in turn until one succeeds.
+func (c *ChainClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	var summary string
+	_, err := c.try(ctx, func(client Client) (err error) {
+		summary, err = client.Summarize(ctx, prompt)
+		return err
+	})
+	return summary, err
+}
+
+// try calls call with each provider until one succeeds and returns its name.
+// A cancelled run or an exhausted token budget stops the chain: the next
+// provider would fail the same way.
+func (c *ChainClient) try(ctx context.Context, call func(Client) error) (string, error) {
+	log := logger.FromContext(ctx)
+	var errs []error
+	for i, link := range c.links {
+		err := call(link.Client)
+		if err == nil {
+			if i > 0 {
+				log.Info("LLM fallback provider succeeded", "provider", link.Name, "failed", i)
+			}
+			return link.Name, nil
+		}
+		if ctx.Err() != nil || errors.Is(err, ErrTokenBudgetExceeded) {
+			return "", err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", link.Name, err))
+		if i+1 < len(c.links) {
+			log.Warn("LLM provider failed, trying the next one", "provider", link.Name, "next", c.links[i+1].Name, "error", err)
+		}
+	}
+	if len(errs) == 1 {
+		return "", errors.Unwrap(errs[0])
+	}
+	return "", fmt.Errorf("all %d LLM providers failed: %w", len(errs), errors.Join(errs...))
+}
@@ -0,0 +1,57 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+func TestChainClient_FallsBack(t *testing.T) {
+	chain := NewChainClient(
+		ChainLink{Name: "gemini-3-pro", Client: &MockClient{Err: fmt.Errorf("gemini: %w", errRateLimited)}},
+		ChainLink{Name: "gpt-4o-mini", Client: &MockClient{Result: []parser.StructuredError{{File: "a.go", Message: "m", Tool: "gpt-4o-mini-2024"}}, Summary: "ok"}},
+		ChainLink{Name: "ollama/llama3", Client: &MockClient{Err: errors.New("not reached")}},
+	)
+
+	findings, err := chain.Review(context.Background(), "p")
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Tool != "gpt-4o-mini" {
+		t.Errorf("expected the finding of the fallback provider, got %+v", findings)
+	}
+	if summary, err := chain.Summarize(context.Background(), "p"); err != nil || summary != "ok" {
+		t.Errorf("Summarize = %q, %v", summary, err)
+	}
+}
+
+func TestChainClient_AllFail(t *testing.T) {
+	chain := NewChainClient(
+		ChainLink{Name: "gemini-3-pro", Client: &MockClient{Err: errors.New("down")}},
+		ChainLink{Name: "ollama/llama3", Client: &MockClient{Err: errors.New("connection refused")}},
+	)
+	_, err := chain.Review(context.Background(), "p")
+	if err == nil || !strings.Contains(err.Error(), "all 2 LLM providers failed") || !strings.Contains(err.Error(), "ollama/llama3: connection refused") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	single := NewChainClient(ChainLink{Name: "gemini", Client: &MockClient{Err: errors.New("down")}})
+	if _, err := single.Review(context.Background(), "p"); err == nil || err.Error() != "down" {
+		t.Errorf("expected the provider's error unchanged, got %v", err)
+	}
+}
+
+func TestChainClient_StopsOnBudget(t *testing.T) {
+	next := &MockClient{}
+	chain := NewChainClient(
+		ChainLink{Name: "gemini", Client: &MockClient{Err: fmt.Errorf("%w: over", ErrTokenBudgetExceeded)}},
+		ChainLink{Name: "gpt-4o-mini", Client: next},
+	)
+	if _, err := chain.Review(context.Background(), "p"); !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Errorf("expected the budget error, got %v", err)
+	}
+}
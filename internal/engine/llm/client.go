@@ -11,4 +11,6 @@ import (
 type Client interface {
 	// Review sends a prompt to the LLM and returns structured errors.
 	Review(ctx context.Context, prompt string) ([]parser.StructuredError, error)
+	// Summarize sends a prompt to the LLM and returns its plain-text answer.
+	Summarize(ctx context.Context, prompt string) (string, error)
 }
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// Dismissal is an LLM finding a developer dismissed. Identical findings on the
+// same code are dropped from later reviews.
+type Dismissal struct {
+	Key       string    `json:"key"`
+	Gate      string    `json:"gate"`
+	File      string    `json:"file,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Message   string    `json:"message"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Dismissals is the set of dismissed LLM findings of a working copy. Unlike triage
+// suppressions, dismissals are personal and kept out of git.
+type Dismissals struct {
+	Version    int         `json:"version"`
+	Dismissals []Dismissal `json:"dismissals"`
+}
+
+// DismissalsPath returns the dismissals file for a project, inside the git-ignored results directory.
+func DismissalsPath(projectDir string) string {
+	return filepath.Join(projectDir, ".gatekeeper", "results", "dismissed.json")
+}
+
+// LoadDismissals reads the dismissals file at path. A missing file yields an empty set.
+func LoadDismissals(path string) (*Dismissals, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Dismissals{Version: 1}, nil
+		}
+		return nil, fmt.Errorf("reading dismissals: %w", err)
+	}
+
+	var d Dismissals
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("parsing dismissals: %w", err)
+	}
+	if d.Version == 0 {
+		d.Version = 1
+	}
+	return &d, nil
+}
+
+// Save writes the dismissals to path.
+func (d *Dismissals) Save(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding dismissals: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating dismissals directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing dismissals: %w", err)
+	}
+	return nil
+}
+
+// Add inserts or replaces the dismissal with the same key.
+func (d *Dismissals) Add(dis Dismissal) {
+	for i := range d.Dismissals {
+		if d.Dismissals[i].Key == dis.Key {
+			d.Dismissals[i] = dis
+			return
+		}
+	}
+	d.Dismissals = append(d.Dismissals, dis)
+}
+
+// Contains reports whether a finding with key was dismissed.
+func (d *Dismissals) Contains(key string) bool {
+	for _, dis := range d.Dismissals {
+		if dis.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// DismissalKey identifies a finding of a gate on a line of code. It covers the
+// finding's file, rule, and message, and the flagged line's content with
+// whitespace collapsed, so the key survives the line moving but not the code
+// changing.
+func DismissalKey(gate string, e parser.StructuredError, code string) string {
+	h := sha256.New()
+	for _, part := range []string{gate, e.Rule, filepath.ToSlash(e.File), strings.TrimSpace(e.Message), strings.Join(strings.Fields(code), " ")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LineOf returns line n (1-based) of content, or "" if it has no such line.
+func LineOf(content string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	lines := strings.Split(content, "\n")
+	if n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
+
+// FilterDismissed splits findings of gate into those to report and those
+// dismissed. code returns the content of a file's line.
+func FilterDismissed(errors []parser.StructuredError, gate string, d *Dismissals, code func(file string, line int) string) (kept, dismissed []parser.StructuredError) {
+	if d == nil || len(d.Dismissals) == 0 {
+		return errors, nil
+	}
+	for _, e := range errors {
+		if d.Contains(DismissalKey(gate, e, code(e.File, e.Line))) {
+			dismissed = append(dismissed, e)
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept, dismissed
+}
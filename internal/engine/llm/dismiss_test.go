@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+func TestDismissalKey(t *testing.T) {
+	e := parser.StructuredError{File: "main.go", Line: 4, Severity: "error", Message: "SQL built by concatenation"}
+	key := DismissalKey("review", e, `	q := "SELECT " + id`)
+
+	moved := e
+	moved.Line = 40
+	moved.Tool = "gemini"
+	if DismissalKey("review", moved, `q := "SELECT "   + id`) != key {
+		t.Error("expected key to ignore line number, tool, and whitespace")
+	}
+	if DismissalKey("review", e, `q := "SELECT " + quote(id)`) == key {
+		t.Error("expected key to change with the code")
+	}
+	if DismissalKey("security", e, `q := "SELECT " + id`) == key {
+		t.Error("expected key to change with the gate")
+	}
+}
+
+func TestFilterDismissed(t *testing.T) {
+	code := map[string]string{"main.go": "package main\n\nvar password = \"x\"\n"}
+	lookup := func(file string, line int) string { return LineOf(code[file], line) }
+
+	dismissedErr := parser.StructuredError{File: "main.go", Line: 3, Severity: "error", Message: "hardcoded secret"}
+	other := parser.StructuredError{File: "main.go", Line: 1, Severity: "warning", Message: "package comment"}
+	d := &Dismissals{}
+	d.Add(Dismissal{Key: DismissalKey("review", dismissedErr, `var password = "x"`)})
+
+	kept, dismissed := FilterDismissed([]parser.StructuredError{dismissedErr, other}, "review", d, lookup)
+	if len(kept) != 1 || kept[0].Message != "package comment" {
+		t.Errorf("expected other finding kept, got %+v", kept)
+	}
+	if len(dismissed) != 1 || dismissed[0].Message != "hardcoded secret" {
+		t.Errorf("expected dismissed finding, got %+v", dismissed)
+	}
+
+	code["main.go"] = "package main\n\nvar password = \"y\"\n"
+	if kept, _ := FilterDismissed([]parser.StructuredError{dismissedErr}, "review", d, lookup); len(kept) != 1 {
+		t.Error("expected finding reported again after the code changed")
+	}
+}
+
+func TestDismissals_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results", "dismissed.json")
+
+	empty, err := LoadDismissals(path)
+	if err != nil || len(empty.Dismissals) != 0 {
+		t.Fatalf("expected empty dismissals for missing file, got %+v, %v", empty, err)
+	}
+
+	d := &Dismissals{Version: 1}
+	d.Add(Dismissal{Key: "k1", Gate: "review", Message: "first", CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)})
+	d.Add(Dismissal{Key: "k1", Gate: "review", Message: "replaced"})
+	if err := d.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadDismissals(path)
+	if err != nil {
+		t.Fatalf("LoadDismissals: %v", err)
+	}
+	if len(loaded.Dismissals) != 1 || loaded.Dismissals[0].Message != "replaced" || !loaded.Contains("k1") {
+		t.Errorf("unexpected dismissals: %+v", loaded.Dismissals)
+	}
+}
+
+func TestLineOf(t *testing.T) {
+	content := "one\ntwo\nthree"
+	if LineOf(content, 2) != "two" || LineOf(content, 0) != "" || LineOf(content, 4) != "" {
+		t.Error("unexpected LineOf result")
+	}
+}
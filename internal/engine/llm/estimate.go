@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// Assumed response sizes for cost estimates; actual responses vary with the
+// number of findings.
+const (
+	estimatedReviewTokens  = 400
+	estimatedSummaryTokens = 200
+)
+
+// EstimatingClient implements Client without calling a provider: it records the
+// estimated usage of each prompt and reports no findings. Dry runs use it to
+// price LLM gates before they are turned on.
+type EstimatingClient struct {
+	model string
+}
+
+// NewEstimatingClient creates an EstimatingClient priced as model.
+func NewEstimatingClient(model string) *EstimatingClient {
+	if model == "" {
+		model = DefaultGeminiModel
+	}
+	return &EstimatingClient{model: model}
+}
+
+// Review records the estimated usage of prompt and returns no findings.
+func (c *EstimatingClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	c.record(ctx, prompt, estimatedReviewTokens)
+	return nil, nil
+}
+
+// Summarize records the estimated usage of prompt and returns an empty summary.
+func (c *EstimatingClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	c.record(ctx, prompt, estimatedSummaryTokens)
+	return "", nil
+}
+
+func (c *EstimatingClient) record(ctx context.Context, prompt string, responseTokens int) {
+	recordUsage(ctx, Usage{
+		Model:          c.model,
+		PromptTokens:   EstimateTokens(prompt),
+		ResponseTokens: responseTokens,
+		Estimated:      true,
+	})
+}
@@ -5,10 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
@@ -43,6 +48,9 @@ func DefaultClientFactory(ctx context.Context, apiKey string) (GenerativeClient,
 	return &genaiClient{inner: c}, nil
 }
 
+// DefaultGeminiModel is the model used when none is configured.
+const DefaultGeminiModel = "gemini-3-pro"
+
 // GeminiClient implements Client using the Google Gemini API.
 type GeminiClient struct {
 	apiKey  string
@@ -55,7 +63,7 @@ type GeminiClient struct {
 // The factory creates the underlying generative client; use DefaultClientFactory for production.
 func NewGeminiClient(apiKey, model string, factory ClientFactory) *GeminiClient {
 	if model == "" {
-		model = "gemini-3-pro"
+		model = DefaultGeminiModel
 	}
 	if factory == nil {
 		factory = DefaultClientFactory
@@ -81,15 +89,75 @@ func (c *GeminiClient) Review(ctx context.Context, prompt string) ([]parser.Stru
 	log.Info("starting LLM review", "model", c.model)
 	start := time.Now()
 
-	client, err := c.factory(ctx, c.apiKey)
+	schema, err := structuredErrorSchema()
 	if err != nil {
-		return nil, fmt.Errorf("creating Gemini client: %w", err)
+		return nil, err
 	}
-
-	config := &genai.GenerateContentConfig{
+	text, err := c.generate(ctx, prompt, &genai.GenerateContentConfig{
 		Temperature:      genai.Ptr(float32(0)),
 		ResponseMIMEType: "application/json",
-		ResponseSchema:   structuredErrorSchema(),
+		ResponseSchema:   schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse structured output
+	var result []parser.StructuredError
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("parsing LLM response: %w", err)
+	}
+
+	// Set the Tool field on all entries
+	for i := range result {
+		result[i].Tool = c.model
+	}
+
+	log.Info("LLM review complete",
+		"model", c.model,
+		"issues", len(result),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return result, nil
+}
+
+// Summarize sends a prompt to Gemini and returns its plain-text answer.
+// It retries like Review.
+func (c *GeminiClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	log := logger.FromContext(ctx)
+	log.Info("starting LLM summary", "model", c.model)
+	start := time.Now()
+
+	text, err := c.generate(ctx, prompt, &genai.GenerateContentConfig{
+		Temperature: genai.Ptr(float32(0)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Info("LLM summary complete", "model", c.model, "duration_ms", time.Since(start).Milliseconds())
+	return text, nil
+}
+
+// generate sends one prompt with retries and returns the response text,
+// recording the reported token usage on ctx.
+func (c *GeminiClient) generate(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (string, error) {
+	ctx, span := telemetry.Start(ctx, "llm.generate",
+		attribute.String("llm.provider", "gemini"),
+		attribute.String("llm.model", c.model),
+	)
+	text, err := c.generateWithRetry(ctx, prompt, config)
+	telemetry.End(span, err)
+	return text, err
+}
+
+// generateWithRetry sends one prompt, retrying failed requests with backoff.
+func (c *GeminiClient) generateWithRetry(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (string, error) {
+	log := logger.FromContext(ctx)
+
+	client, err := c.factory(ctx, c.apiKey)
+	if err != nil {
+		return "", fmt.Errorf("creating Gemini client: %w", err)
 	}
 
 	var lastErr error
@@ -112,41 +180,35 @@ func (c *GeminiClient) Review(ctx context.Context, prompt string) ([]parser.Stru
 
 			select {
 			case <-ctx.Done():
-				return nil, fmt.Errorf("LLM review cancelled: %w", ctx.Err())
+				return "", fmt.Errorf("LLM review cancelled: %w", ctx.Err())
 			case <-time.After(backoff):
 			}
 			backoff *= 2
 			continue
 		}
 
+		if md := resp.UsageMetadata; md != nil {
+			trace.SpanFromContext(ctx).SetAttributes(
+				attribute.Int("llm.attempts", attempt+1),
+				attribute.Int("llm.prompt_tokens", int(md.PromptTokenCount)),
+				attribute.Int("llm.response_tokens", int(md.CandidatesTokenCount+md.ThoughtsTokenCount)),
+			)
+			recordUsage(ctx, Usage{
+				Model:          c.model,
+				PromptTokens:   int(md.PromptTokenCount),
+				ResponseTokens: int(md.CandidatesTokenCount + md.ThoughtsTokenCount),
+			})
+		}
+
 		// Extract text from response
 		text, err := extractText(resp)
 		if err != nil {
-			return nil, fmt.Errorf("extracting response text: %w", err)
+			return "", fmt.Errorf("extracting response text: %w", err)
 		}
-
-		// Parse structured output
-		var result []parser.StructuredError
-		if err := json.Unmarshal([]byte(text), &result); err != nil {
-			return nil, fmt.Errorf("parsing LLM response: %w", err)
-		}
-
-		// Set the Tool field on all entries
-		for i := range result {
-			result[i].Tool = c.model
-		}
-
-		duration := time.Since(start)
-		log.Info("LLM review complete",
-			"model", c.model,
-			"issues", len(result),
-			"duration_ms", duration.Milliseconds(),
-		)
-
-		return result, nil
+		return text, nil
 	}
 
-	return nil, fmt.Errorf("LLM review failed after %d attempts: %w", maxRetries, lastErr)
+	return "", fmt.Errorf("LLM review failed after %d attempts: %w", maxRetries, lastErr)
 }
 
 // extractText pulls the text content from a Gemini response.
@@ -166,20 +228,19 @@ func extractText(resp *genai.GenerateContentResponse) (string, error) {
 }
 
 // structuredErrorSchema returns the JSON schema for []StructuredError
-// used with Gemini's structured output mode.
-func structuredErrorSchema() *genai.Schema {
-	return &genai.Schema{
-		Type: genai.TypeArray,
-		Items: &genai.Schema{
-			Type: genai.TypeObject,
-			Properties: map[string]*genai.Schema{
-				"file":     {Type: genai.TypeString, Description: "File path relative to project root"},
-				"line":     {Type: genai.TypeInteger, Description: "Line number (1-based)"},
-				"severity": {Type: genai.TypeString, Enum: []string{"error", "warning", "info"}},
-				"message":  {Type: genai.TypeString, Description: "Issue description"},
-				"hint":     {Type: genai.TypeString, Description: "Actionable fix suggestion"},
-			},
-			Required: []string{"file", "line", "severity", "message"},
-		},
+// used with Gemini's structured output mode, loaded on first use.
+var structuredErrorSchema = sync.OnceValues(loadStructuredErrorSchema)
+
+// loadStructuredErrorSchema reads the built-in schemas/findings.json asset; it
+// is not overridable because parsing depends on it.
+func loadStructuredErrorSchema() (*genai.Schema, error) {
+	data, err := assets.Default("schemas/findings.json")
+	if err != nil {
+		return nil, err
+	}
+	var schema genai.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parsing built-in findings schema: %w", err)
 	}
+	return &schema, nil
 }
@@ -220,3 +220,24 @@ func TestNewGeminiClient_CustomModel(t *testing.T) {
 		t.Errorf("expected model 'custom-model', got %q", client.model)
 	}
 }
+
+func TestGeminiClient_Summarize(t *testing.T) {
+	mock := &mockGenerativeClient{
+		errs:      []error{errors.New("503 unavailable")},
+		responses: []*genai.GenerateContentResponse{nil, makeResponse("Renames Get to Load.")},
+	}
+	factory := func(_ context.Context, _ string) (GenerativeClient, error) {
+		return mock, nil
+	}
+
+	summary, err := NewGeminiClient("fake-key", "test-model", factory).Summarize(context.Background(), "summarize this")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "Renames Get to Load." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if mock.callCount != 2 {
+		t.Errorf("expected a retry, got %d calls", mock.callCount)
+	}
+}
@@ -0,0 +1,253 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"google.golang.org/genai"
+)
+
+// DefaultBreakerThreshold is the number of consecutive rate-limited or failed
+// calls after which a provider's circuit opens.
+const DefaultBreakerThreshold = 2
+
+// ErrCircuitOpen is returned, wrapped in a CircuitOpenError, for calls to a
+// provider whose circuit is open.
+var ErrCircuitOpen = errors.New("LLM provider circuit open")
+
+// statusError is an error response of an HTTP LLM API.
+type statusError struct {
+	provider string
+	status   string
+	code     int
+	msg      string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned %s: %s", e.provider, e.status, e.msg)
+}
+
+// Unwrap makes a 429 response errRateLimited.
+func (e *statusError) Unwrap() error {
+	if e.code == http.StatusTooManyRequests {
+		return errRateLimited
+	}
+	return nil
+}
+
+// overloaded reports whether err is a rate-limit or server error of a
+// provider, which is likely to repeat for the next call.
+func overloaded(err error) bool {
+	var status *statusError
+	var apiErr genai.APIError
+	switch {
+	case errors.Is(err, errRateLimited):
+		return true
+	case errors.As(err, &status):
+		return status.code >= 500
+	case errors.As(err, &apiErr):
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= 500
+	default:
+		return false
+	}
+}
+
+// Limiter paces the calls of parallel LLM gates to a provider: at most
+// concurrency at a time, started at most requestsPerMinute a minute. It is
+// safe for concurrent use.
+type Limiter struct {
+	slots    chan struct{}
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewLimiter creates a Limiter. A zero requestsPerMinute or concurrency
+// leaves that limit off.
+func NewLimiter(requestsPerMinute, concurrency int) *Limiter {
+	l := &Limiter{}
+	if concurrency > 0 {
+		l.slots = make(chan struct{}, concurrency)
+	}
+	if requestsPerMinute > 0 {
+		l.interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	return l
+}
+
+// acquire waits for a free slot and the next start time, and returns the
+// function that frees the slot.
+func (l *Limiter) acquire(ctx context.Context) (release func(), err error) {
+	release = func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			release = func() { <-l.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if l.interval == 0 {
+		return release, nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
+
+// Breaker stops calling a provider for the rest of a run once it has been
+// rate limited or failed with server errors DefaultBreakerThreshold calls in a
+// row, so parallel gates do not all keep retrying. It is safe for concurrent use.
+type Breaker struct {
+	provider  string
+	threshold int
+
+	mu       sync.Mutex
+	failures int
+	last     error
+	open     bool
+	reported bool
+}
+
+// NewBreaker creates a Breaker for provider that opens after threshold
+// consecutive overloaded calls.
+func NewBreaker(provider string, threshold int) *Breaker {
+	return &Breaker{provider: provider, threshold: threshold}
+}
+
+// CircuitOpenError is returned for calls to a provider whose circuit is open.
+// It wraps ErrCircuitOpen.
+type CircuitOpenError struct {
+	Provider string
+	Failures int
+	// Last is the error of the call that opened the circuit.
+	Last    error
+	breaker *Breaker
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s unavailable after %d rate-limited or failed calls in a row, not calling it again this run: %v", e.Provider, e.Failures, e.Last)
+}
+
+func (e *CircuitOpenError) Unwrap() error { return ErrCircuitOpen }
+
+// FirstReport reports whether this is the first time the open circuit is
+// reported in the run, so only one gate reports it as an error.
+func (e *CircuitOpenError) FirstReport() bool {
+	e.breaker.mu.Lock()
+	defer e.breaker.mu.Unlock()
+	first := !e.breaker.reported
+	e.breaker.reported = true
+	return first
+}
+
+// allow returns a CircuitOpenError if the circuit is open.
+func (b *Breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.open {
+		return b.openError()
+	}
+	return nil
+}
+
+// record counts the outcome of a call and returns a CircuitOpenError in
+// place of err if the call opened the circuit.
+func (b *Breaker) record(err error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !overloaded(err) {
+		b.failures = 0
+		return err
+	}
+	b.failures++
+	b.last = err
+	if b.failures >= b.threshold {
+		b.open = true
+	}
+	if b.open {
+		return b.openError()
+	}
+	return err
+}
+
+func (b *Breaker) openError() *CircuitOpenError {
+	return &CircuitOpenError{Provider: b.provider, Failures: b.failures, Last: b.last, breaker: b}
+}
+
+// LimitedClient wraps a Client with a Limiter and a Breaker shared by the
+// gates of a run. Either may be nil.
+type LimitedClient struct {
+	inner   Client
+	limiter *Limiter
+	breaker *Breaker
+}
+
+// NewLimitedClient wraps inner with limiter and breaker.
+func NewLimitedClient(inner Client, limiter *Limiter, breaker *Breaker) *LimitedClient {
+	return &LimitedClient{inner: inner, limiter: limiter, breaker: breaker}
+}
+
+// Review runs the wrapped review when the limiter and breaker allow it.
+func (c *LimitedClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	var result []parser.StructuredError
+	err := c.call(ctx, func() (err error) {
+		result, err = c.inner.Review(ctx, prompt)
+		return err
+	})
+	return result, err
+}
+
+// Summarize runs the wrapped summary when the limiter and breaker allow it.
+func (c *LimitedClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	var summary string
+	err := c.call(ctx, func() (err error) {
+		summary, err = c.inner.Summarize(ctx, prompt)
+		return err
+	})
+	return summary, err
+}
+
+func (c *LimitedClient) call(ctx context.Context, fn func() error) error {
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+	}
+	if c.limiter != nil {
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+	err := fn()
+	if c.breaker != nil && ctx.Err() == nil {
+		err = c.breaker.record(err)
+	}
+	return err
+}
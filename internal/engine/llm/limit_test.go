@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestOverloaded(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"429", &statusError{provider: "openai", code: http.StatusTooManyRequests}, true},
+		{"503", fmt.Errorf("attempt 3: %w", &statusError{provider: "openai", code: http.StatusServiceUnavailable}), true},
+		{"400", &statusError{provider: "openai", code: http.StatusBadRequest}, false},
+		{"gemini 429", genai.APIError{Code: http.StatusTooManyRequests}, true},
+		{"gemini 500", fmt.Errorf("wrapped: %w", genai.APIError{Code: http.StatusInternalServerError}), true},
+		{"gemini 403", genai.APIError{Code: http.StatusForbidden}, false},
+		{"other", errors.New("parsing LLM response"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overloaded(tt.err); got != tt.want {
+				t.Errorf("overloaded(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter_Concurrency(t *testing.T) {
+	limiter := NewLimiter(0, 2)
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background())
+			if err != nil {
+				t.Errorf("acquire: %v", err)
+				return
+			}
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+	if got := peak.Load(); got > 2 {
+		t.Errorf("expected at most 2 calls at once, got %d", got)
+	}
+}
+
+func TestLimiter_Pacing(t *testing.T) {
+	limiter := NewLimiter(1200, 0) // one start every 50ms
+	start := time.Now()
+	for range 3 {
+		release, err := limiter.acquire(context.Background())
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected the third call to start after 100ms, took %v", elapsed)
+	}
+}
+
+func TestLimiter_Cancelled(t *testing.T) {
+	limiter := NewLimiter(0, 1)
+	release, err := limiter.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the context error while the slot is taken, got %v", err)
+	}
+}
+
+func TestLimitedClient_BreakerOpens(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Err: &statusError{provider: "openai", status: "429 Too Many Requests", code: http.StatusTooManyRequests}}}
+	client := NewLimitedClient(inner, nil, NewBreaker("openai/gpt-4o-mini", 2))
+	ctx := context.Background()
+
+	if _, err := client.Review(ctx, "p"); !errors.Is(err, errRateLimited) || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("first call: expected the rate-limit error, got %v", err)
+	}
+	_, err := client.Review(ctx, "p")
+	var circuitErr *CircuitOpenError
+	if !errors.As(err, &circuitErr) {
+		t.Fatalf("second call: expected a CircuitOpenError, got %v", err)
+	}
+	if circuitErr.Provider != "openai/gpt-4o-mini" || circuitErr.Failures != 2 {
+		t.Errorf("unexpected circuit error: %+v", circuitErr)
+	}
+
+	if _, err := client.Summarize(ctx, "p"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected calls to fail fast once open, got %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("expected the provider not to be called once open, got %d calls", inner.calls)
+	}
+
+	if !circuitErr.FirstReport() {
+		t.Error("expected the first report")
+	}
+	_, err = client.Review(ctx, "p")
+	if !errors.As(err, &circuitErr) || circuitErr.FirstReport() {
+		t.Error("expected the open circuit to be reported once per breaker")
+	}
+}
+
+func TestLimitedClient_SuccessResetsBreaker(t *testing.T) {
+	inner := &countingClient{MockClient: MockClient{Err: genai.APIError{Code: http.StatusServiceUnavailable}}}
+	client := NewLimitedClient(inner, nil, NewBreaker("gemini/gemini-3-pro", 2))
+	ctx := context.Background()
+
+	_, _ = client.Review(ctx, "p")
+	inner.Err = nil
+	if _, err := client.Review(ctx, "p"); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	inner.Err = genai.APIError{Code: http.StatusServiceUnavailable}
+	if _, err := client.Review(ctx, "p"); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected a success to reset the failure count, got %v", err)
+	}
+
+	inner.Err = errors.New("invalid prompt")
+	for range 3 {
+		if _, err := client.Review(ctx, "p"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected other errors not to open the circuit, got %v", err)
+		}
+	}
+}
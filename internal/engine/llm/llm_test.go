@@ -2,18 +2,22 @@ package llm
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
 	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
 	"google.golang.org/genai"
 )
 
 // --- Prompt Tests ---
 
 func TestBuildPrompt_ContainsUserPrompt(t *testing.T) {
-	prompt := BuildPrompt("check for security issues", "go", []git.FileDiff{
+	prompt := mustBuildPrompt(t, "check for security issues", PromptVars{Language: "go"}, []git.FileDiff{
 		{Path: "main.go", Content: "+fmt.Println(\"hello\")"},
 	})
 
@@ -34,7 +38,7 @@ func TestBuildPrompt_MultipleDiffs(t *testing.T) {
 		{Path: "b.go", Content: "+line2"},
 	}
 
-	prompt := BuildPrompt("review", "go", diffs)
+	prompt := mustBuildPrompt(t, "review", PromptVars{Language: "go"}, diffs)
 
 	if !strings.Contains(prompt, "a.go") {
 		t.Errorf("expected prompt to contain 'a.go', got:\n%s", prompt)
@@ -50,8 +54,18 @@ func TestBuildPrompt_MultipleDiffs(t *testing.T) {
 	}
 }
 
+func TestBuildPrompt_NamesRenamedFrom(t *testing.T) {
+	prompt := mustBuildPrompt(t, "review", PromptVars{Language: "go"}, []git.FileDiff{
+		{Path: "pkg/b.go", OldPath: "pkg/a.go", Change: git.ChangeRenamed, Content: "+// moved"},
+	})
+
+	if !strings.Contains(prompt, "--- pkg/b.go (renamed from pkg/a.go) ---") {
+		t.Errorf("expected the header to name the old path, got:\n%s", prompt)
+	}
+}
+
 func TestBuildPrompt_EmptyLanguage(t *testing.T) {
-	prompt := BuildPrompt("review", "", []git.FileDiff{
+	prompt := mustBuildPrompt(t, "review", PromptVars{}, []git.FileDiff{
 		{Path: "f.txt", Content: "x"},
 	})
 
@@ -61,7 +75,7 @@ func TestBuildPrompt_EmptyLanguage(t *testing.T) {
 }
 
 func TestBuildPrompt_EmptyDiffs(t *testing.T) {
-	prompt := BuildPrompt("review", "go", nil)
+	prompt := mustBuildPrompt(t, "review", PromptVars{Language: "go"}, nil)
 
 	if !strings.Contains(prompt, "review") {
 		t.Errorf("expected prompt to contain 'review', got:\n%s", prompt)
@@ -72,6 +86,115 @@ func TestBuildPrompt_EmptyDiffs(t *testing.T) {
 	}
 }
 
+func TestBuildPrompt_RendersVariables(t *testing.T) {
+	vars := PromptVars{Language: "Go", Branch: "main"}
+	diffs := []git.FileDiff{{Path: "a.go", Content: "+x"}, {Path: "b.go", Content: "+y"}}
+
+	prompt := mustBuildPrompt(t, "Check {{.Language}} files {{join .Files \", \"}} on {{.Branch}}", vars, diffs)
+
+	if !strings.Contains(prompt, "Check Go files a.go, b.go on main") {
+		t.Errorf("expected rendered rules, got:\n%s", prompt)
+	}
+}
+
+func TestBuildPrompt_UnknownVariable(t *testing.T) {
+	if _, err := BuildPrompt("{{.Author}}", PromptVars{}, nil); err == nil {
+		t.Error("expected error for unknown variable")
+	}
+}
+
+func TestBuildPrompt_LiteralBraces(t *testing.T) {
+	rules := "Flag JSX that renders user input with {{ or dangerouslySetInnerHTML."
+	prompt, err := BuildPrompt(rules, PromptVars{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, rules) {
+		t.Errorf("expected the rules as written, got:\n%s", prompt)
+	}
+}
+
+func TestCheckPromptTemplate(t *testing.T) {
+	if err := CheckPromptTemplate("Review {{.Language}} on {{.Branch}}"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckPromptTemplate("Review {{.Language"); err != nil {
+		t.Errorf("rules that do not parse should be sent as written, got %v", err)
+	}
+	if err := CheckPromptTemplate("Review {{.Lang}}"); err == nil {
+		t.Error("expected error for unknown variable")
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		paths []string
+		want  string
+	}{
+		{[]string{"main.go", "util.go"}, "Go"},
+		{[]string{"web/app.tsx", "main.go", "README.md"}, "Go, TypeScript"},
+		{[]string{"README.md"}, ""},
+		{[]string{"deploy/Dockerfile", "Dockerfile.dev", "scripts/build.SH"}, "Dockerfile, Shell"},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.paths); got != tt.want {
+			t.Errorf("DetectLanguage(%v) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByLanguage(t *testing.T) {
+	diffs := []git.FileDiff{{Path: "a.go"}, {Path: "web/app.ts"}, {Path: "README.md"}, {Path: "b.go"}, {Path: "NOTES"}}
+
+	groups := GroupByLanguage(diffs)
+	var got [][]string
+	for _, g := range groups {
+		var paths []string
+		for _, d := range g {
+			paths = append(paths, d.Path)
+		}
+		got = append(got, paths)
+	}
+	want := [][]string{{"a.go", "b.go"}, {"web/app.ts"}, {"README.md", "NOTES"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByLanguage = %v, want %v", got, want)
+	}
+}
+
+func TestBuildPrompt_Override(t *testing.T) {
+	dir := t.TempDir()
+	assets.SetOverrideDir(dir)
+	t.Cleanup(func() { assets.SetOverrideDir("") })
+	if err := os.MkdirAll(filepath.Join(dir, "prompts"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := "House rules for {{.Branch}}: {{.Rules}}\n{{.Diff}}"
+	if err := os.WriteFile(filepath.Join(dir, "prompts", "review.tmpl"), []byte(tmpl), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := mustBuildPrompt(t, "no TODOs", PromptVars{Branch: "main"}, []git.FileDiff{{Path: "a.go", Content: "+x"}})
+	if !strings.HasPrefix(prompt, "House rules for main: no TODOs\n--- a.go ---") {
+		t.Errorf("expected overridden prompt, got:\n%s", prompt)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "prompts", "review.tmpl"), []byte("{{.Author}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BuildPrompt("review", PromptVars{}, nil); err == nil {
+		t.Error("expected error for unknown field in overridden prompt")
+	}
+}
+
+func mustBuildPrompt(t *testing.T, rules string, vars PromptVars, diffs []git.FileDiff) string {
+	t.Helper()
+	prompt, err := BuildPrompt(rules, vars, diffs)
+	if err != nil {
+		t.Fatalf("BuildPrompt: %v", err)
+	}
+	return prompt
+}
+
 // --- Validate Tests ---
 
 func TestValidateLineNumbers_ValidLines(t *testing.T) {
@@ -152,8 +275,132 @@ func TestValidateLineNumbers_EmptyInput(t *testing.T) {
 	}
 }
 
+func TestApplyConfidence(t *testing.T) {
+	low, high := 0.4, 0.9
+	errs := []parser.StructuredError{
+		{File: "a.go", Line: 1, Severity: "error", Message: "sure", Confidence: &high},
+		{File: "a.go", Line: 2, Severity: "error", Message: "guess", Confidence: &low},
+		{File: "a.go", Line: 3, Severity: "suggestion", Message: "nit", Confidence: &low},
+		{File: "a.go", Line: 4, Severity: "error", Message: "unscored"},
+	}
+
+	warned := ApplyConfidence(errs, 0.7, false)
+	if len(warned) != 4 {
+		t.Fatalf("expected all findings kept, got %d", len(warned))
+	}
+	if warned[1].Severity != "warning" || warned[1].Message != "guess (low confidence: 0.40)" {
+		t.Errorf("expected low-confidence error downgraded, got %+v", warned[1])
+	}
+	if warned[2].Severity != "suggestion" {
+		t.Errorf("expected suggestion kept as suggestion, got %q", warned[2].Severity)
+	}
+	if warned[0].Severity != "error" || warned[3].Severity != "error" {
+		t.Error("expected confident and unscored findings unchanged")
+	}
+	if errs[1].Severity != "error" {
+		t.Error("expected input findings not modified")
+	}
+
+	dropped := ApplyConfidence(errs, 0.7, true)
+	if len(dropped) != 2 || dropped[0].Message != "sure" || dropped[1].Message != "unscored" {
+		t.Errorf("expected low-confidence findings dropped, got %+v", dropped)
+	}
+
+	if got := ApplyConfidence(errs, 0, true); len(got) != 4 {
+		t.Errorf("expected no filtering without min_confidence, got %d", len(got))
+	}
+}
+
+func TestBuildExplainPrompt(t *testing.T) {
+	prompt, err := BuildExplainPrompt(Failure{
+		Gate:        "trivy",
+		Type:        "exec",
+		SystemError: "exit code 2",
+		Output:      strings.Repeat("x", maxExplainOutput) + "FATAL: db download failed",
+	})
+	if err != nil {
+		t.Fatalf("BuildExplainPrompt: %v", err)
+	}
+
+	if !strings.Contains(prompt, "Check: trivy (exec)") || !strings.Contains(prompt, "could not complete: exit code 2") {
+		t.Errorf("expected gate and system error in prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "[...]") || !strings.Contains(prompt, "FATAL: db download failed") {
+		t.Error("expected output truncated from the start, keeping its end")
+	}
+}
+
 // --- MockClient Tests ---
 
+func TestBuildContextPrompt(t *testing.T) {
+	files := []SourceFile{{Path: "api.go", Content: "package api\n\nfunc Load() {}\n"}}
+	diffs := []git.FileDiff{{Path: "api.go", Content: "@@ -3 +3 @@\n-func Get() {}\n+func Load() {}"}}
+
+	summaryPrompt, err := BuildSummaryPrompt(files, diffs)
+	if err != nil {
+		t.Fatalf("BuildSummaryPrompt: %v", err)
+	}
+	prompt, err := BuildContextPrompt("Check callers of {{join .Files \", \"}}", PromptVars{}, "  Renames Get to Load.\n", files, diffs)
+	if err != nil {
+		t.Fatalf("BuildContextPrompt: %v", err)
+	}
+	for _, p := range []string{summaryPrompt, prompt} {
+		if !strings.Contains(p, "--- api.go ---\npackage api") || !strings.Contains(p, "+func Load() {}") {
+			t.Errorf("expected full file and diff in prompt, got:\n%s", p)
+		}
+	}
+	if !strings.Contains(prompt, "Summary of the change:\nRenames Get to Load.\n") {
+		t.Errorf("expected summary in review prompt, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Check callers of api.go") || !strings.Contains(prompt, "auto-detect") {
+		t.Errorf("expected rules and language in review prompt, got:\n%s", prompt)
+	}
+}
+
+func TestBuildDocDriftPrompt(t *testing.T) {
+	files := []SourceFile{{Path: "api.go", Content: "package api\n\n// Load returns nil.\nfunc Load() error { return errFail }\n"}}
+	diffs := []git.FileDiff{{Path: "api.go", Content: "@@ -4 +4 @@\n-func Load() error { return nil }\n+func Load() error { return errFail }"}}
+
+	prompt, err := BuildDocDriftPrompt("", PromptVars{Language: "Go"}, files, diffs)
+	if err != nil {
+		t.Fatalf("BuildDocDriftPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "doc comment") || !strings.Contains(prompt, "// Load returns nil.") || !strings.Contains(prompt, "+func Load() error { return errFail }") {
+		t.Errorf("expected the doc drift instructions, full file, and diff, got:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "Additional rules") {
+		t.Errorf("expected no rules section without rules, got:\n%s", prompt)
+	}
+
+	prompt, err = BuildDocDriftPrompt("Ignore {{.Branch}} TODOs", PromptVars{Branch: "main"}, nil, diffs)
+	if err != nil {
+		t.Fatalf("BuildDocDriftPrompt: %v", err)
+	}
+	if !strings.Contains(prompt, "Additional rules: Ignore main TODOs") || strings.Contains(prompt, "Full files") {
+		t.Errorf("expected rules and the diff only, got:\n%s", prompt)
+	}
+}
+
+func TestValidateFileLines(t *testing.T) {
+	files := []SourceFile{{Path: "a.go", Content: "one\ntwo\nthree\n"}, {Path: "b.go", Content: "one\ntwo"}}
+	errs := []parser.StructuredError{
+		{File: "a.go", Line: 3, Message: "last line"},
+		{File: "a.go", Line: 4, Message: "past end"},
+		{File: "b.go", Line: 2, Message: "no trailing newline"},
+		{File: "c.go", Line: 1, Message: "not reviewed"},
+		{File: "b.go", Line: 0, Message: "file-level"},
+	}
+
+	got := ValidateFileLines(errs, files)
+	var messages []string
+	for _, e := range got {
+		messages = append(messages, e.Message)
+	}
+	if strings.Join(messages, ",") != "last line,no trailing newline,file-level" {
+		t.Errorf("unexpected validated findings: %v", messages)
+	}
+}
+
 func TestMockClient_ReturnsConfigured(t *testing.T) {
 	expected := []parser.StructuredError{
 		{File: "test.go", Line: 1, Severity: "error", Message: "mock issue"},
@@ -246,14 +493,20 @@ func TestExtractText_ValidResponse(t *testing.T) {
 // --- structuredErrorSchema Tests ---
 
 func TestStructuredErrorSchema_ArrayType(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if schema.Type != genai.TypeArray {
 		t.Errorf("expected schema type Array, got %v", schema.Type)
 	}
 }
 
 func TestStructuredErrorSchema_ItemType(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	if schema.Items == nil {
 		t.Fatal("expected non-nil Items schema")
 	}
@@ -263,7 +516,10 @@ func TestStructuredErrorSchema_ItemType(t *testing.T) {
 }
 
 func TestStructuredErrorSchema_Properties(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	expectedProps := []string{"file", "line", "severity", "message", "hint"}
 	for _, prop := range expectedProps {
 		if _, ok := schema.Items.Properties[prop]; !ok {
@@ -273,7 +529,10 @@ func TestStructuredErrorSchema_Properties(t *testing.T) {
 }
 
 func TestStructuredErrorSchema_PropertyTypes(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	props := schema.Items.Properties
 
 	if props["file"].Type != genai.TypeString {
@@ -291,14 +550,20 @@ func TestStructuredErrorSchema_PropertyTypes(t *testing.T) {
 	if props["hint"].Type != genai.TypeString {
 		t.Errorf("expected 'hint' type String, got %v", props["hint"].Type)
 	}
+	if props["confidence"].Type != genai.TypeNumber {
+		t.Errorf("expected 'confidence' type Number, got %v", props["confidence"].Type)
+	}
 }
 
 func TestStructuredErrorSchema_RequiredFields(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	required := schema.Items.Required
 
 	expectedRequired := map[string]bool{
-		"file": true, "line": true, "severity": true, "message": true,
+		"file": true, "line": true, "severity": true, "message": true, "confidence": true,
 	}
 
 	if len(required) != len(expectedRequired) {
@@ -313,10 +578,13 @@ func TestStructuredErrorSchema_RequiredFields(t *testing.T) {
 }
 
 func TestStructuredErrorSchema_SeverityEnum(t *testing.T) {
-	schema := structuredErrorSchema()
+	schema, err := structuredErrorSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
 	sevProp := schema.Items.Properties["severity"]
 
-	expectedEnums := map[string]bool{"error": true, "warning": true, "info": true}
+	expectedEnums := map[string]bool{"error": true, "warning": true, "info": true, "suggestion": true}
 	if len(sevProp.Enum) != len(expectedEnums) {
 		t.Fatalf("expected %d severity enums, got %d: %v", len(expectedEnums), len(sevProp.Enum), sevProp.Enum)
 	}
@@ -8,11 +8,21 @@ import (
 
 // MockClient is a test double for llm.Client.
 type MockClient struct {
-	Result []parser.StructuredError
-	Err    error
+	Result  []parser.StructuredError
+	Summary string
+	Err     error
+	// Usage is recorded on the context's UsageTracker, as a provider would.
+	Usage Usage
 }
 
 // Review returns the configured result and error.
-func (m *MockClient) Review(_ context.Context, _ string) ([]parser.StructuredError, error) {
+func (m *MockClient) Review(ctx context.Context, _ string) ([]parser.StructuredError, error) {
+	recordUsage(ctx, m.Usage)
 	return m.Result, m.Err
 }
+
+// Summarize returns the configured summary and error.
+func (m *MockClient) Summarize(ctx context.Context, _ string) (string, error) {
+	recordUsage(ctx, m.Usage)
+	return m.Summary, m.Err
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultOpenAIModel is the OpenAI model used when none is configured.
+const DefaultOpenAIModel = "gpt-4o-mini"
+
+// DefaultOpenAIURL is the base URL of the OpenAI API.
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// DefaultOllamaURL is where a local Ollama server listens.
+const DefaultOllamaURL = "http://localhost:11434"
+
+// errRateLimited is wrapped by the error of a rate-limited request. It is not
+// retried, so a ChainClient moves on to its next provider right away.
+var errRateLimited = errors.New("rate limited")
+
+// OpenAIClient implements Client with the OpenAI chat completions API, which
+// Ollama (at its /v1 endpoint) and many gateways also serve.
+type OpenAIClient struct {
+	provider string
+	baseURL  string
+	apiKey   string
+	model    string
+	http     *http.Client
+	headers  map[string]string
+}
+
+// NewOpenAIClient creates an OpenAIClient for the API at baseURL, such as
+// DefaultOpenAIURL. provider names it in logs and traces. apiKey may be empty
+// for servers without authentication, like Ollama. If httpClient is nil,
+// http.DefaultClient is used.
+func NewOpenAIClient(provider, baseURL, apiKey, model string, httpClient *http.Client, headers map[string]string) *OpenAIClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &OpenAIClient{
+		provider: provider,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		http:     httpClient,
+		headers:  headers,
+	}
+}
+
+// Review sends a prompt and returns structured errors. The response is
+// requested as a JSON object whose findings follow the built-in findings
+// schema. Failed requests are retried like GeminiClient's.
+func (c *OpenAIClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	log := logger.FromContext(ctx)
+	log.Info("starting LLM review", "provider", c.provider, "model", c.model)
+	start := time.Now()
+
+	schema, err := assets.Default("schemas/findings.json")
+	if err != nil {
+		return nil, err
+	}
+	system := "Respond with a JSON object of the form {\"findings\": [...]}, where each finding follows this JSON schema:\n" + string(schema)
+	text, err := c.generate(ctx, system, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Findings []parser.StructuredError `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		return nil, fmt.Errorf("parsing LLM response: %w", err)
+	}
+	for i := range result.Findings {
+		result.Findings[i].Tool = c.model
+	}
+
+	log.Info("LLM review complete", "provider", c.provider, "model", c.model, "issues", len(result.Findings), "duration_ms", time.Since(start).Milliseconds())
+	return result.Findings, nil
+}
+
+// Summarize sends a prompt and returns the plain-text answer.
+func (c *OpenAIClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	log := logger.FromContext(ctx)
+	log.Info("starting LLM summary", "provider", c.provider, "model", c.model)
+	start := time.Now()
+
+	text, err := c.generate(ctx, "", prompt, false)
+	if err != nil {
+		return "", err
+	}
+	log.Info("LLM summary complete", "provider", c.provider, "model", c.model, "duration_ms", time.Since(start).Milliseconds())
+	return text, nil
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string            `json:"model"`
+	Messages       []chatMessage     `json:"messages"`
+	Temperature    float64           `json:"temperature"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// generate sends one chat completion request with retries and returns the
+// response text, recording the reported token usage on ctx.
+func (c *OpenAIClient) generate(ctx context.Context, system, prompt string, jsonOutput bool) (string, error) {
+	ctx, span := telemetry.Start(ctx, "llm.generate",
+		attribute.String("llm.provider", c.provider),
+		attribute.String("llm.model", c.model),
+	)
+	text, err := c.generateWithRetry(ctx, system, prompt, jsonOutput)
+	telemetry.End(span, err)
+	return text, err
+}
+
+// generateWithRetry sends one request, retrying failed requests with backoff.
+// Rate-limited and rejected requests are not retried.
+func (c *OpenAIClient) generateWithRetry(ctx context.Context, system, prompt string, jsonOutput bool) (string, error) {
+	log := logger.FromContext(ctx)
+
+	req := chatRequest{Model: c.model}
+	if system != "" {
+		req.Messages = append(req.Messages, chatMessage{Role: "system", Content: system})
+	}
+	req.Messages = append(req.Messages, chatMessage{Role: "user", Content: prompt})
+	if jsonOutput {
+		req.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("encoding LLM request: %w", err)
+	}
+
+	var lastErr error
+	backoff := initialBackoff
+	for attempt := range maxRetries {
+		log.Debug("LLM request attempt", "attempt", attempt+1, "provider", c.provider, "model", c.model)
+
+		resp, retry, err := c.send(ctx, body)
+		if err == nil {
+			if resp.Usage != nil {
+				recordUsage(ctx, Usage{Model: c.model, PromptTokens: resp.Usage.PromptTokens, ResponseTokens: resp.Usage.CompletionTokens})
+			}
+			if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+				return "", fmt.Errorf("empty response from %s", c.provider)
+			}
+			return resp.Choices[0].Message.Content, nil
+		}
+		if !retry {
+			return "", err
+		}
+
+		lastErr = fmt.Errorf("attempt %d: %w", attempt+1, err)
+		log.Warn("LLM request failed, retrying", "attempt", attempt+1, "provider", c.provider, "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("LLM review cancelled: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return "", fmt.Errorf("LLM review failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// send posts one request. retry reports whether a failed request may succeed
+// if sent again.
+func (c *OpenAIClient) send(ctx context.Context, body []byte) (resp *chatResponse, retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("creating LLM request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	httpResp, err := c.http.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		err := &statusError{provider: c.provider, status: httpResp.Status, code: httpResp.StatusCode, msg: strings.TrimSpace(string(msg))}
+		return nil, httpResp.StatusCode >= 500, err
+	}
+	var out chatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&out); err != nil {
+		return nil, false, fmt.Errorf("decoding %s response: %w", c.provider, err)
+	}
+	return &out, false, nil
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIClient_Review(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" || r.Header.Get("X-Team") != "infra" {
+			http.Error(w, "bad request "+r.URL.Path, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"findings\":[{\"file\":\"main.go\",\"line\":3,\"severity\":\"error\",\"message\":\"bug\"}]}"}}],"usage":{"prompt_tokens":120,"completion_tokens":30}}`))
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(ProviderOpenAI, srv.URL+"/v1", "key", "gpt-4o-mini", srv.Client(), map[string]string{"X-Team": "infra"})
+	ctx, tracker := WithUsageTracking(context.Background())
+	findings, err := client.Review(ctx, "review this")
+	if err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+	if len(findings) != 1 || findings[0].File != "main.go" || findings[0].Line != 3 || findings[0].Tool != "gpt-4o-mini" {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+	if got.Model != "gpt-4o-mini" || got.ResponseFormat["type"] != "json_object" || len(got.Messages) != 2 || got.Messages[1].Content != "review this" {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if u := tracker.Usage(); u.PromptTokens != 120 || u.ResponseTokens != 30 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+}
+
+func TestOpenAIClient_RateLimitedNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(ProviderOllama, srv.URL, "", "llama3", srv.Client(), nil)
+	_, err := client.Summarize(context.Background(), "p")
+	if !errors.Is(err, errRateLimited) || calls != 1 {
+		t.Errorf("expected one rate-limited call, got %d calls and %v", calls, err)
+	}
+}
+
+func TestOpenAIClient_RejectedNotRetried(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(ProviderOllama, srv.URL, "", "llama3", srv.Client(), nil)
+	_, err := client.Review(context.Background(), "p")
+	if err == nil || !strings.Contains(err.Error(), "model not found") || calls != 1 {
+		t.Errorf("expected one rejected call, got %d calls and %v", calls, err)
+	}
+}
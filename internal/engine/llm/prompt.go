@@ -2,29 +2,335 @@ package llm
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/git"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
 )
 
-const promptTemplate = `You are a code reviewer for a pre-commit hook. Review the following diff and identify issues. Respond ONLY with a JSON array matching the required schema.
-If no issues, return: []
+// PromptVars are the variables available to review rules, which are Go templates:
+// {{.Language}}, {{.Files}}, and {{.Branch}}, plus a join function for lists,
+// e.g. {{join .Files ", "}}.
+type PromptVars struct {
+	// Language names the languages of the files under review ("auto-detect" if unknown).
+	Language string
+	// Files are the paths of the files under review.
+	Files []string
+	// Branch is the checked-out branch, or "" on a detached HEAD.
+	Branch string
+}
+
+// promptFuncs are the functions available to review rule templates.
+var promptFuncs = template.FuncMap{"join": strings.Join}
 
-Review rules: %s
-Language: %s
+// renderRules executes review rules as a template with vars. Rules that are
+// not a valid template, such as rules quoting a literal "{{", are returned
+// unchanged, as are rules without template actions.
+func renderRules(rules string, vars PromptVars) (string, error) {
+	if !strings.Contains(rules, "{{") {
+		return rules, nil
+	}
+	tmpl, err := template.New("prompt").Funcs(promptFuncs).Option("missingkey=error").Parse(rules)
+	if err != nil {
+		return rules, nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("rendering prompt template: %w", err)
+	}
+	return b.String(), nil
+}
 
-%s`
+// promptData is what the prompt assets (prompts/*.tmpl) are rendered with. Each
+// prompt uses the fields relevant to it.
+type promptData struct {
+	PromptVars
+	// Rules are the rendered review rules of the gate.
+	Rules string
+	// Diff is the staged diff of the files under review.
+	Diff string
+	// Summary is the first-pass summary of a full-context review.
+	Summary string
+	// Content is the full files followed by the diff, for full-context reviews.
+	Content string
+	// Gate, Type, and Details describe a failure to explain.
+	Gate    string
+	Type    string
+	Details string
+}
 
-// BuildPrompt constructs a review prompt from a user prompt and file diffs.
-func BuildPrompt(userPrompt, language string, diffs []git.FileDiff) string {
-	if language == "" {
-		language = "auto-detect"
+// renderPrompt executes the named prompt asset, which may be a user override.
+func renderPrompt(name string, data promptData) (string, error) {
+	text, err := assets.Read("prompts/" + name + ".tmpl")
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return "", fmt.Errorf("parsing %s prompt: %w", name, err)
 	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("rendering %s prompt: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+// CheckPromptTemplate reports template errors in review rules, such as unknown
+// variables, before any review runs. Rules that do not parse as a template are
+// sent as written and are not an error.
+func CheckPromptTemplate(rules string) error {
+	_, err := renderRules(rules, PromptVars{Language: "Go", Files: []string{"main.go"}, Branch: "main"})
+	return err
+}
 
-	var diffContent strings.Builder
+// BuildPrompt constructs a review prompt from review rules and file diffs.
+// The rules are rendered with vars; vars.Files defaults to the diffed paths.
+func BuildPrompt(rules string, vars PromptVars, diffs []git.FileDiff) (string, error) {
+	vars = withDefaults(vars, diffs)
+	rendered, err := renderRules(rules, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return renderPrompt("review", promptData{PromptVars: vars, Rules: rendered, Diff: diffContent(diffs)})
+}
+
+// withDefaults fills in the language and file list of vars from diffs.
+func withDefaults(vars PromptVars, diffs []git.FileDiff) PromptVars {
+	if vars.Files == nil {
+		for _, d := range diffs {
+			vars.Files = append(vars.Files, d.Path)
+		}
+	}
+	if vars.Language == "" {
+		vars.Language = "auto-detect"
+	}
+	return vars
+}
+
+// languages maps file extensions to language names for {{.Language}}.
+var languages = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".kts":    "Kotlin",
+	".scala":  "Scala",
+	".rb":     "Ruby",
+	".php":    "PHP",
+	".cs":     "C#",
+	".c":      "C",
+	".h":      "C",
+	".cpp":    "C++",
+	".cc":     "C++",
+	".hpp":    "C++",
+	".swift":  "Swift",
+	".m":      "Objective-C",
+	".dart":   "Dart",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".lua":    "Lua",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".ps1":    "PowerShell",
+	".sql":    "SQL",
+	".tf":     "Terraform",
+	".proto":  "Protocol Buffers",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "CSS",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+}
+
+// languageFiles maps file names without a telling extension to language names.
+var languageFiles = map[string]string{
+	"Dockerfile":  "Dockerfile",
+	"Makefile":    "Makefile",
+	"Jenkinsfile": "Groovy",
+	"Gemfile":     "Ruby",
+	"Rakefile":    "Ruby",
+}
+
+// LanguageOf names the language of path by its extension or file name, e.g.
+// "Go" for "cmd/main.go". Returns "" if it is not recognized.
+func LanguageOf(path string) string {
+	base := filepath.Base(path)
+	if name, ok := languageFiles[base]; ok {
+		return name
+	}
+	if strings.HasPrefix(base, "Dockerfile.") {
+		return "Dockerfile"
+	}
+	return languages[strings.ToLower(filepath.Ext(base))]
+}
+
+// DetectLanguage names the languages of paths, e.g. "Go, TypeScript".
+// Returns "" if none is recognized.
+func DetectLanguage(paths []string) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range paths {
+		name := LanguageOf(p)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// GroupByLanguage splits diffs into one group per language, in the order the
+// languages first appear, so each review prompt covers a single language.
+// Files of unrecognized languages are grouped together.
+func GroupByLanguage(diffs []git.FileDiff) [][]git.FileDiff {
+	index := make(map[string]int)
+	var groups [][]git.FileDiff
+	for _, d := range diffs {
+		lang := LanguageOf(d.Path)
+		i, ok := index[lang]
+		if !ok {
+			i = len(groups)
+			index[lang] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], d)
+	}
+	return groups
+}
+
+// SourceFile is the full staged content of a changed file.
+type SourceFile struct {
+	Path    string
+	Content string
+}
+
+// BuildSummaryPrompt constructs the first pass of a full-context review, which
+// asks for a summary of the change.
+func BuildSummaryPrompt(files []SourceFile, diffs []git.FileDiff) (string, error) {
+	return renderPrompt("summary", promptData{PromptVars: withDefaults(PromptVars{}, diffs), Content: contextContent(files, diffs)})
+}
+
+// BuildContextPrompt constructs the second pass of a full-context review, which
+// asks for issues given the summary from the first pass. Rules are rendered as in BuildPrompt.
+func BuildContextPrompt(rules string, vars PromptVars, summary string, files []SourceFile, diffs []git.FileDiff) (string, error) {
+	vars = withDefaults(vars, diffs)
+	rendered, err := renderRules(rules, vars)
+	if err != nil {
+		return "", err
+	}
+	return renderPrompt("context", promptData{
+		PromptVars: vars,
+		Rules:      rendered,
+		Summary:    strings.TrimSpace(summary),
+		Content:    contextContent(files, diffs),
+	})
+}
+
+// BuildDocDriftPrompt constructs a review for doc comments that no longer match
+// the changed code. files are the full staged content of the changed files, or
+// nil to send the diff only. Rules are optional and rendered as in BuildPrompt.
+func BuildDocDriftPrompt(rules string, vars PromptVars, files []SourceFile, diffs []git.FileDiff) (string, error) {
+	vars = withDefaults(vars, diffs)
+	rendered, err := renderRules(rules, vars)
+	if err != nil {
+		return "", err
+	}
+	content := diffContent(diffs)
+	if files != nil {
+		content = contextContent(files, diffs)
+	}
+	return renderPrompt("doc_drift", promptData{PromptVars: vars, Rules: strings.TrimSpace(rendered), Content: content})
+}
+
+// contextContent renders full files followed by the diff.
+func contextContent(files []SourceFile, diffs []git.FileDiff) string {
+	var b strings.Builder
+	b.WriteString("=== Full files ===\n\n")
+	for _, f := range files {
+		b.WriteString(fmt.Sprintf("--- %s ---\n%s\n\n", f.Path, f.Content))
+	}
+	b.WriteString("=== Diff ===\n\n")
+	b.WriteString(diffContent(diffs))
+	return b.String()
+}
+
+// diffContent renders the diff of each file under a header with its path,
+// and the path it was renamed from.
+func diffContent(diffs []git.FileDiff) string {
+	var b strings.Builder
 	for _, d := range diffs {
-		diffContent.WriteString(fmt.Sprintf("--- %s ---\n%s\n\n", d.Path, d.Content))
+		header := d.Path
+		if d.Change == git.ChangeRenamed {
+			header = fmt.Sprintf("%s (renamed from %s)", d.Path, d.OldPath)
+		}
+		b.WriteString(fmt.Sprintf("--- %s ---\n%s\n\n", header, d.Content))
 	}
+	return b.String()
+}
 
-	return fmt.Sprintf(promptTemplate, userPrompt, language, diffContent.String())
+// maxExplainOutput bounds the tool output included in an explain prompt; the end
+// of the output, where tools usually report failures, is kept.
+const maxExplainOutput = 8 * 1024
+
+// Failure is a failed gate of a recorded run, to be explained.
+type Failure struct {
+	Gate        string
+	Type        string
+	Errors      []parser.StructuredError
+	SystemError string
+	// Output is the raw tool output, if recorded.
+	Output string
+	// Guidance is the team's on_fail_message, if any.
+	Guidance string
+}
+
+// BuildExplainPrompt asks for a plain-language explanation of a failure with a suggested fix.
+func BuildExplainPrompt(f Failure) (string, error) {
+	var b strings.Builder
+	if f.SystemError != "" {
+		b.WriteString(fmt.Sprintf("\nThe check could not complete: %s\n", f.SystemError))
+	}
+	if len(f.Errors) > 0 {
+		b.WriteString("\nFindings:\n")
+		for _, e := range f.Errors {
+			loc := e.File
+			if e.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", e.File, e.Line)
+			}
+			rule := ""
+			if e.Rule != "" {
+				rule = " [" + e.Rule + "]"
+			}
+			b.WriteString(fmt.Sprintf("- %s %s%s: %s\n", e.Severity, loc, rule, e.Message))
+			if e.Hint != "" {
+				b.WriteString(fmt.Sprintf("  hint: %s\n", e.Hint))
+			}
+		}
+	}
+	if out := strings.TrimSpace(f.Output); out != "" {
+		if len(out) > maxExplainOutput {
+			out = "[...]\n" + out[len(out)-maxExplainOutput:]
+		}
+		b.WriteString(fmt.Sprintf("\nTool output:\n%s\n", out))
+	}
+	if f.Guidance != "" {
+		b.WriteString(fmt.Sprintf("\nThe team's guidance for this check:\n%s\n", f.Guidance))
+	}
+	return renderPrompt("explain", promptData{Gate: f.Gate, Type: f.Type, Details: b.String()})
 }
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LLM providers.
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama"
+)
+
+// ErrProviderUnavailable is returned for a provider that is not configured,
+// such as one without an API key.
+var ErrProviderUnavailable = errors.New("LLM provider not configured")
+
+// Provider is an LLM provider and the model to use with it.
+type Provider struct {
+	Name  string
+	Model string
+}
+
+// openAIPrefixes are the model name prefixes of OpenAI models.
+var openAIPrefixes = []string{"gpt-", "chatgpt-", "o1", "o3", "o4"}
+
+// ParseProvider parses the provider setting of an LLM gate. It is a provider
+// name ("gemini"), a model whose name implies its provider ("gemini-3-pro",
+// "gpt-4o-mini"), or "provider/model" ("ollama/llama3").
+func ParseProvider(spec string) (Provider, error) {
+	if name, model, ok := strings.Cut(spec, "/"); ok {
+		switch {
+		case name != ProviderGemini && name != ProviderOpenAI && name != ProviderOllama:
+			return Provider{}, fmt.Errorf("unknown LLM provider %q (valid: %s, %s, %s)", name, ProviderGemini, ProviderOpenAI, ProviderOllama)
+		case model == "":
+			return Provider{}, fmt.Errorf("provider %q: missing model after %q", spec, name+"/")
+		}
+		return Provider{Name: name, Model: model}, nil
+	}
+
+	switch {
+	case spec == ProviderGemini:
+		return Provider{Name: ProviderGemini, Model: DefaultGeminiModel}, nil
+	case spec == ProviderOpenAI:
+		return Provider{Name: ProviderOpenAI, Model: DefaultOpenAIModel}, nil
+	case spec == ProviderOllama:
+		return Provider{}, fmt.Errorf("provider %q needs a model, e.g. ollama/llama3", spec)
+	case strings.HasPrefix(spec, "gemini-"):
+		return Provider{Name: ProviderGemini, Model: spec}, nil
+	}
+	for _, prefix := range openAIPrefixes {
+		if strings.HasPrefix(spec, prefix) {
+			return Provider{Name: ProviderOpenAI, Model: spec}, nil
+		}
+	}
+	return Provider{}, fmt.Errorf("unknown LLM provider %q: use a model such as gemini-3-pro or gpt-4o-mini, or provider/model such as ollama/llama3", spec)
+}
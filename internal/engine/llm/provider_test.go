@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProvider(t *testing.T) {
+	tests := map[string]Provider{
+		"gemini":            {Name: ProviderGemini, Model: DefaultGeminiModel},
+		"gemini-2.5-flash":  {Name: ProviderGemini, Model: "gemini-2.5-flash"},
+		"gemini/custom":     {Name: ProviderGemini, Model: "custom"},
+		"openai":            {Name: ProviderOpenAI, Model: DefaultOpenAIModel},
+		"gpt-4o-mini":       {Name: ProviderOpenAI, Model: "gpt-4o-mini"},
+		"o3-mini":           {Name: ProviderOpenAI, Model: "o3-mini"},
+		"ollama/llama3":     {Name: ProviderOllama, Model: "llama3"},
+		"ollama/qwen2.5:7b": {Name: ProviderOllama, Model: "qwen2.5:7b"},
+	}
+	for spec, want := range tests {
+		got, err := ParseProvider(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseProvider(%q) = %+v, %v; want %+v", spec, got, err, want)
+		}
+	}
+
+	for spec, want := range map[string]string{
+		"claude-3":  "unknown LLM provider",
+		"mistral/x": "unknown LLM provider",
+		"ollama":    "needs a model",
+		"ollama/":   "missing model",
+	} {
+		if _, err := ParseProvider(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseProvider(%q): expected error containing %q, got %v", spec, want, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"google.golang.org/genai"
+)
+
+// TransportConfig customizes how LLM providers reach their API, e.g. through an
+// internal gateway that adds auth and logging. The zero value uses provider defaults.
+type TransportConfig struct {
+	// BaseURL overrides the provider endpoint.
+	BaseURL string
+	// Headers are added to every request.
+	Headers map[string]string
+	// ProxyURL is the HTTP(S) proxy. If empty, the standard proxy environment variables apply.
+	ProxyURL string
+	// CACertFile is a PEM file with additional trusted root CAs.
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are PEM files for mTLS.
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// IsZero reports whether no transport options are set.
+func (c TransportConfig) IsZero() bool {
+	return c.BaseURL == "" && len(c.Headers) == 0 && c.ProxyURL == "" &&
+		c.CACertFile == "" && c.ClientCertFile == "" && c.ClientKeyFile == ""
+}
+
+// NewHTTPClient builds an HTTP client with the proxy and TLS options of cfg.
+func NewHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if cfg.CACertFile != "" || cfg.ClientCertFile != "" {
+		tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if cfg.CACertFile != "" {
+			pem, err := os.ReadFile(cfg.CACertFile) // #nosec G304 -- path comes from the user's global config
+			if err != nil {
+				return nil, fmt.Errorf("reading CA certificate: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates found in %s", cfg.CACertFile)
+			}
+			tlsCfg.RootCAs = pool
+		}
+
+		if cfg.ClientCertFile != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading client certificate: %w", err)
+			}
+			tlsCfg.Certificates = []tls.Certificate{cert}
+		}
+
+		transport.TLSClientConfig = tlsCfg
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// NewClientFactory returns a ClientFactory that creates Gemini clients using cfg.
+// The HTTP client is built once, so certificate errors surface at startup.
+func NewClientFactory(cfg TransportConfig) (ClientFactory, error) {
+	if cfg.IsZero() {
+		return DefaultClientFactory, nil
+	}
+
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := http.Header{}
+	for name, value := range cfg.Headers {
+		headers.Set(name, value)
+	}
+
+	return func(ctx context.Context, apiKey string) (GenerativeClient, error) {
+		c, err := genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:     apiKey,
+			Backend:    genai.BackendGeminiAPI,
+			HTTPClient: httpClient,
+			HTTPOptions: genai.HTTPOptions{
+				BaseURL: cfg.BaseURL,
+				Headers: headers,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &genaiClient{inner: c}, nil
+	}, nil
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewClientFactory_ZeroConfigUsesDefault(t *testing.T) {
+	f, err := NewClientFactory(TransportConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f == nil {
+		t.Fatal("expected a factory")
+	}
+}
+
+func TestNewClientFactory_GatewayRequest(t *testing.T) {
+	var gotPath, gotHeader string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Get("X-Gateway-Token")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"[]"}]}}]}`))
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	factory, err := NewClientFactory(TransportConfig{
+		BaseURL:    srv.URL + "/gemini/",
+		Headers:    map[string]string{"X-Gateway-Token": "s3cret"},
+		CACertFile: caFile,
+	})
+	if err != nil {
+		t.Fatalf("NewClientFactory: %v", err)
+	}
+
+	client := NewGeminiClient("key", "test-model", factory)
+	if _, err := client.Review(context.Background(), "review this"); err != nil {
+		t.Fatalf("Review: %v", err)
+	}
+
+	if !strings.HasPrefix(gotPath, "/gemini/") || !strings.Contains(gotPath, "test-model") {
+		t.Errorf("expected request routed through the gateway base URL, got path %q", gotPath)
+	}
+	if gotHeader != "s3cret" {
+		t.Errorf("expected gateway header, got %q", gotHeader)
+	}
+}
+
+func TestNewHTTPClient_Errors(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "bad.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  TransportConfig
+		want string
+	}{
+		{"missing CA file", TransportConfig{CACertFile: filepath.Join(dir, "missing.pem")}, "reading CA certificate"},
+		{"CA file without certificates", TransportConfig{CACertFile: notPEM}, "no PEM certificates"},
+		{"bad client certificate", TransportConfig{ClientCertFile: notPEM, ClientKeyFile: notPEM}, "loading client certificate"},
+		{"bad proxy URL", TransportConfig{ProxyURL: "://bad"}, "parsing proxy URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPClient(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	c, err := NewHTTPClient(TransportConfig{ProxyURL: "http://proxy.internal:3128"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://generativelanguage.googleapis.com", nil)
+	proxy, err := c.Transport.(*http.Transport).Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.internal:3128" {
+		t.Errorf("expected configured proxy, got %v (%v)", proxy, err)
+	}
+}
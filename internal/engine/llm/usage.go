@@ -0,0 +1,193 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// ErrTokenBudgetExceeded is returned when a review would exceed the run's token budget.
+var ErrTokenBudgetExceeded = errors.New("LLM token budget exceeded")
+
+// Usage is the token consumption of LLM reviews.
+type Usage struct {
+	Model          string
+	PromptTokens   int
+	ResponseTokens int
+	// Estimated is set if the counts were estimated from prompt sizes rather than
+	// reported by the provider (see EstimatingClient).
+	Estimated bool
+}
+
+// Total returns prompt plus response tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.ResponseTokens
+}
+
+// price is the list price of a model in USD per million tokens.
+type price struct {
+	input, output float64
+}
+
+// modelPrices are approximate list prices used for cost estimates. Unknown models
+// have no estimate.
+var modelPrices = map[string]price{
+	"gemini-3-pro":          {input: 2.00, output: 12.00},
+	"gemini-2.5-pro":        {input: 1.25, output: 10.00},
+	"gemini-2.5-flash":      {input: 0.30, output: 2.50},
+	"gemini-2.5-flash-lite": {input: 0.10, output: 0.40},
+}
+
+// EstimateCost returns the estimated cost of u in USD. ok is false for models without a known price.
+func EstimateCost(u Usage) (usd float64, ok bool) {
+	p, ok := modelPrices[u.Model]
+	if !ok {
+		return 0, false
+	}
+	return (float64(u.PromptTokens)*p.input + float64(u.ResponseTokens)*p.output) / 1e6, true
+}
+
+// EstimateTokens approximates the token count of a prompt (about 4 characters per token).
+func EstimateTokens(prompt string) int {
+	return (len(prompt) + 3) / 4
+}
+
+// UsageTracker accumulates the usage reported by clients during a review.
+type UsageTracker struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// Usage returns the usage recorded so far.
+func (t *UsageTracker) Usage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.usage
+}
+
+// add records the usage of one provider response.
+func (t *UsageTracker) add(u Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if u.Model != "" {
+		t.usage.Model = u.Model
+	}
+	t.usage.PromptTokens += u.PromptTokens
+	t.usage.ResponseTokens += u.ResponseTokens
+	t.usage.Estimated = t.usage.Estimated || u.Estimated
+}
+
+type usageKey struct{}
+
+// WithUsageTracking returns a context whose LLM calls record their token usage in the returned tracker.
+func WithUsageTracking(ctx context.Context) (context.Context, *UsageTracker) {
+	t := &UsageTracker{}
+	return context.WithValue(ctx, usageKey{}, t), t
+}
+
+// recordUsage adds u to the tracker of ctx, if any.
+func recordUsage(ctx context.Context, u Usage) {
+	if t, ok := ctx.Value(usageKey{}).(*UsageTracker); ok {
+		t.add(u)
+	}
+}
+
+// Budget limits the tokens spent by all LLM reviews of a run. It is safe for
+// concurrent use by parallel gates.
+type Budget struct {
+	mu   sync.Mutex
+	max  int
+	used int
+}
+
+// NewBudget creates a Budget of maxTokens per run.
+func NewBudget(maxTokens int) *Budget {
+	return &Budget{max: maxTokens}
+}
+
+// reserve claims n tokens. It returns false if that would exceed the budget.
+func (b *Budget) reserve(n int) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used+n > b.max {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+// settle replaces a reservation with the tokens actually used.
+func (b *Budget) settle(reserved, actual int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used += actual - reserved
+}
+
+// Used returns the tokens spent or reserved so far.
+func (b *Budget) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// BudgetClient wraps a Client and refuses reviews that would exceed a Budget.
+// The prompt size is estimated up front; the reservation is corrected with the
+// reported usage once the review completes. A review cache belongs outside it,
+// since cached reviews spend no tokens.
+type BudgetClient struct {
+	inner  Client
+	budget *Budget
+}
+
+// NewBudgetClient wraps inner with budget.
+func NewBudgetClient(inner Client, budget *Budget) *BudgetClient {
+	return &BudgetClient{inner: inner, budget: budget}
+}
+
+// Review runs the wrapped review if the estimated prompt fits in the remaining
+// budget, otherwise it returns ErrTokenBudgetExceeded.
+func (c *BudgetClient) Review(ctx context.Context, prompt string) ([]parser.StructuredError, error) {
+	var errs []parser.StructuredError
+	err := c.spend(ctx, prompt, func(ctx context.Context) (err error) {
+		errs, err = c.inner.Review(ctx, prompt)
+		return err
+	})
+	return errs, err
+}
+
+// Summarize runs the wrapped summary under the same budget as Review.
+func (c *BudgetClient) Summarize(ctx context.Context, prompt string) (string, error) {
+	var summary string
+	err := c.spend(ctx, prompt, func(ctx context.Context) (err error) {
+		summary, err = c.inner.Summarize(ctx, prompt)
+		return err
+	})
+	return summary, err
+}
+
+// spend reserves the estimated tokens of prompt, runs call, and settles the
+// reservation with the usage call reported. A response without usage metadata
+// is charged the estimate, so unreported usage cannot exceed the budget.
+func (c *BudgetClient) spend(ctx context.Context, prompt string, call func(ctx context.Context) error) error {
+	estimate := EstimateTokens(prompt)
+	if !c.budget.reserve(estimate) {
+		logger.FromContext(ctx).Warn("skipping LLM review over token budget", "estimate", estimate, "used", c.budget.Used(), "max", c.budget.max)
+		return fmt.Errorf("%w: ~%d prompt tokens, %d of %d already used", ErrTokenBudgetExceeded, estimate, c.budget.Used(), c.budget.max)
+	}
+
+	callCtx, tracker := WithUsageTracking(ctx)
+	err := call(callCtx)
+
+	usage := tracker.Usage()
+	recordUsage(ctx, usage) // propagate to the caller's tracker
+	actual := usage.Total()
+	if actual == 0 && err == nil {
+		actual = estimate
+	}
+	c.budget.settle(estimate, actual)
+	return err
+}
@@ -0,0 +1,125 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"google.golang.org/genai"
+)
+
+func TestEstimateCost(t *testing.T) {
+	usd, ok := EstimateCost(Usage{Model: "gemini-2.5-pro", PromptTokens: 1_000_000, ResponseTokens: 100_000})
+	if !ok {
+		t.Fatal("expected a price for gemini-2.5-pro")
+	}
+	if math.Abs(usd-2.25) > 1e-9 {
+		t.Errorf("expected $2.25, got $%f", usd)
+	}
+
+	if _, ok := EstimateCost(Usage{Model: "unknown-model", PromptTokens: 10}); ok {
+		t.Error("expected no price for an unknown model")
+	}
+}
+
+func TestEstimatingClient(t *testing.T) {
+	client := NewEstimatingClient("gemini-2.5-pro")
+	ctx, tracker := WithUsageTracking(context.Background())
+
+	errs, err := client.Review(ctx, string(make([]byte, 4000)))
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("expected no findings and no error, got %v, %v", errs, err)
+	}
+	if _, err := client.Summarize(ctx, string(make([]byte, 400))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Usage{Model: "gemini-2.5-pro", PromptTokens: 1100, ResponseTokens: estimatedReviewTokens + estimatedSummaryTokens, Estimated: true}
+	if got := tracker.Usage(); got != want {
+		t.Errorf("expected usage %+v, got %+v", want, got)
+	}
+}
+
+func TestGeminiClient_Review_RecordsUsage(t *testing.T) {
+	resp := makeResponse(`[]`)
+	resp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     120,
+		CandidatesTokenCount: 30,
+		ThoughtsTokenCount:   10,
+	}
+	mock := &mockGenerativeClient{responses: []*genai.GenerateContentResponse{resp}}
+	factory := func(_ context.Context, _ string) (GenerativeClient, error) {
+		return mock, nil
+	}
+
+	ctx, tracker := WithUsageTracking(context.Background())
+	if _, err := NewGeminiClient("fake-key", "test-model", factory).Review(ctx, "review this"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Usage{Model: "test-model", PromptTokens: 120, ResponseTokens: 40}
+	if got := tracker.Usage(); got != want {
+		t.Errorf("expected usage %+v, got %+v", want, got)
+	}
+}
+
+func TestBudgetClient_SettlesActualUsage(t *testing.T) {
+	inner := &MockClient{
+		Result: []parser.StructuredError{{Message: "issue"}},
+		Usage:  Usage{Model: "test-model", PromptTokens: 50, ResponseTokens: 10},
+	}
+	budget := NewBudget(1000)
+	client := NewBudgetClient(inner, budget)
+
+	ctx, tracker := WithUsageTracking(context.Background())
+	errs, err := client.Review(ctx, "short prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected inner result, got %v", errs)
+	}
+	if budget.Used() != 60 {
+		t.Errorf("expected 60 tokens used, got %d", budget.Used())
+	}
+	if tracker.Usage().Total() != 60 {
+		t.Errorf("expected usage to reach the caller's tracker, got %+v", tracker.Usage())
+	}
+}
+
+func TestBudgetClient_ChargesEstimateWithoutUsage(t *testing.T) {
+	budget := NewBudget(1000)
+	client := NewBudgetClient(&MockClient{}, budget)
+
+	// 400 characters ≈ 100 tokens.
+	if _, err := client.Review(context.Background(), string(make([]byte, 400))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if budget.Used() != 100 {
+		t.Errorf("expected the estimate to be charged, got %d tokens used", budget.Used())
+	}
+
+	// A failed request is not charged.
+	failing := NewBudgetClient(&MockClient{Err: errors.New("unavailable")}, budget)
+	_, _ = failing.Review(context.Background(), "prompt")
+	if budget.Used() != 100 {
+		t.Errorf("expected the failed review's reservation to be released, got %d tokens used", budget.Used())
+	}
+}
+
+func TestBudgetClient_RefusesOverBudget(t *testing.T) {
+	inner := &MockClient{Usage: Usage{PromptTokens: 90}}
+	client := NewBudgetClient(inner, NewBudget(100))
+
+	if _, err := client.Review(context.Background(), "first"); err != nil {
+		t.Fatalf("first review: unexpected error: %v", err)
+	}
+	// 400 characters ≈ 100 tokens, which no longer fits.
+	prompt := string(make([]byte, 400))
+	_, err := client.Review(context.Background(), prompt)
+	if !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Fatalf("expected ErrTokenBudgetExceeded, got %v", err)
+	}
+}
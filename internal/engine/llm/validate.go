@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -31,6 +32,57 @@ func ValidateLineNumbers(errors []parser.StructuredError, diffs []git.FileDiff)
 	return validated
 }
 
+// ValidateFileLines filters out StructuredError entries that do not point at a line
+// of one of the given files. It is used for full-context reviews, where findings
+// may reference unchanged lines of changed files.
+func ValidateFileLines(errors []parser.StructuredError, files []SourceFile) []parser.StructuredError {
+	lineCounts := make(map[string]int, len(files))
+	for _, f := range files {
+		lineCounts[f.Path] = strings.Count(f.Content, "\n")
+		if !strings.HasSuffix(f.Content, "\n") {
+			lineCounts[f.Path]++
+		}
+	}
+
+	var validated []parser.StructuredError
+	for _, e := range errors {
+		lines, ok := lineCounts[e.File]
+		if !ok || e.Line > lines {
+			// File not reviewed or line beyond its end — hallucination; discard.
+			continue
+		}
+		validated = append(validated, e)
+	}
+	return validated
+}
+
+// ApplyConfidence handles findings reported with a confidence below minConfidence:
+// they are dropped if drop is set, else downgraded to warnings with the confidence
+// noted in the message. Findings without a confidence are kept as reported.
+// A minConfidence of 0 keeps every finding.
+func ApplyConfidence(errors []parser.StructuredError, minConfidence float64, drop bool) []parser.StructuredError {
+	if minConfidence <= 0 {
+		return errors
+	}
+
+	var kept []parser.StructuredError
+	for _, e := range errors {
+		if e.Confidence == nil || *e.Confidence >= minConfidence {
+			kept = append(kept, e)
+			continue
+		}
+		if drop {
+			continue
+		}
+		if e.Severity == "error" {
+			e.Severity = "warning"
+		}
+		e.Message = fmt.Sprintf("%s (low confidence: %.2f)", e.Message, *e.Confidence)
+		kept = append(kept, e)
+	}
+	return kept
+}
+
 // buildDiffLineMap extracts the maximum line number referenced in each
 // file's diff hunk headers. This gives a rough upper bound for validation.
 func buildDiffLineMap(diffs []git.FileDiff) map[string]int {
@@ -0,0 +1,174 @@
+// Package local runs container gates on the host instead of in Docker, for
+// environments that cannot run containers (locked-down laptops, CI runners
+// that are already containers). Commands run through sh in the workspace
+// with a restricted environment, and their output feeds the same parsers.
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// waitDelay bounds how long output is read after a timed-out command is
+// killed, in case programs it started keep the pipes open.
+const waitDelay = 5 * time.Second
+
+// baseEnv lists the host variables every local gate gets. Anything else, such
+// as API keys and tokens, must be passed explicitly.
+var baseEnv = []string{
+	"HOME", "USER", "LOGNAME", "TMPDIR", "TMP", "TEMP", "LANG", "LC_ALL",
+	// Windows needs these to start processes and find the user profile.
+	"SYSTEMROOT", "USERPROFILE", "APPDATA", "LOCALAPPDATA",
+}
+
+// Config restricts the environment of local gates.
+type Config struct {
+	// Path lists the directories programs may run from. If empty, the host PATH is used.
+	Path []string
+	// Env lists additional host environment variables to pass through.
+	Env []string
+	// Nix runs commands in the project's Nix development shell: nix develop
+	// for a flake.nix, or nix-shell for a shell.nix or default.nix.
+	Nix bool
+}
+
+// nixEnv lists the host variables Nix needs, passed to Nix gates.
+var nixEnv = []string{"NIX_PATH", "NIX_CONFIG", "NIX_REMOTE", "NIX_SSL_CERT_FILE", "XDG_CACHE_HOME", "XDG_CONFIG_HOME"}
+
+// Runner runs gate commands on the host. It implements the pool and executor
+// roles of container gates: the "container" of a gate is its workspace directory.
+type Runner struct {
+	cfg    Config
+	getenv func(string) string
+}
+
+// NewRunner creates a Runner for cfg.
+func NewRunner(cfg Config) *Runner {
+	return &Runner{cfg: cfg, getenv: os.Getenv}
+}
+
+// GetOrCreate returns the workspace directory as the id to Run in. There is
+// no container to start; writable only matters to containers, since local
+// gates always see the working tree.
+func (r *Runner) GetOrCreate(_ context.Context, _, projectPath string, _ bool) (string, error) {
+	return projectPath, nil
+}
+
+// Run executes command with sh in dir and returns its output. The command is
+// killed when timeout elapses.
+func (r *Runner) Run(ctx context.Context, dir, command string, timeout time.Duration) (*pool.ExecResult, error) {
+	return r.RunWithStdin(ctx, dir, command, nil, timeout)
+}
+
+// RunWithStdin is Run with stdin as the command's standard input. A nil stdin
+// gives it none, as with Run.
+func (r *Runner) RunWithStdin(ctx context.Context, dir, command string, stdin []byte, timeout time.Duration) (*pool.ExecResult, error) {
+	ctx, span := telemetry.Start(ctx, "executor.run", attribute.String("container.id", "local"))
+	result, err := r.run(ctx, dir, command, stdin, timeout)
+	if result != nil {
+		span.SetAttributes(attribute.Int("process.exit_code", result.ExitCode))
+	}
+	telemetry.End(span, err)
+	return result, err
+}
+
+func (r *Runner) run(ctx context.Context, dir, command string, stdin []byte, timeout time.Duration) (*pool.ExecResult, error) {
+	log := logger.FromContext(ctx)
+	log.Info("local run started", "dir", dir, "command", command, "timeout", timeout)
+	start := time.Now()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args, err := r.shell(dir)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, command)
+	cmd := exec.CommandContext(runCtx, args[0], args[1:]...) // #nosec G204 -- gate commands are configured by the repository owner
+	cmd.Dir = dir
+	cmd.Env = r.environ()
+	cmd.WaitDelay = waitDelay
+	killGroup(cmd)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	err = cmd.Run()
+	if runCtx.Err() != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// The process group was killed with the context.
+		return nil, pool.ErrKilled
+	}
+	exitCode := 0
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitCode()
+	case err != nil:
+		return nil, fmt.Errorf("running command: %w", err)
+	}
+
+	result := &pool.ExecResult{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: exitCode,
+		Duration: time.Since(start),
+	}
+	log.Info("local run completed", "dir", dir, "exit_code", exitCode, "duration", result.Duration)
+	return result, nil
+}
+
+// shell returns the command line that runs a gate command in dir, without the command.
+func (r *Runner) shell(dir string) ([]string, error) {
+	if !r.cfg.Nix {
+		return []string{"sh", "-c"}, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "flake.nix")); err == nil {
+		return []string{"nix", "develop", "--command", "sh", "-c"}, nil
+	}
+	for _, name := range []string{"shell.nix", "default.nix"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return []string{"nix-shell", "--run"}, nil
+		}
+	}
+	return nil, errors.New("nix gates need a flake.nix, shell.nix, or default.nix in the project root")
+}
+
+// environ returns the environment of local gates: PATH per the allowlist, the
+// base variables, and the configured pass-through variables.
+func (r *Runner) environ() []string {
+	path := r.getenv("PATH")
+	if len(r.cfg.Path) > 0 {
+		path = strings.Join(r.cfg.Path, string(filepath.ListSeparator))
+	}
+	env := []string{"PATH=" + path}
+	names := slices.Concat(baseEnv, r.cfg.Env)
+	if r.cfg.Nix {
+		names = append(names, nixEnv...)
+	}
+	for _, name := range names {
+		if v := r.getenv(name); v != "" {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
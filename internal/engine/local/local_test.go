@@ -0,0 +1,98 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(Config{})
+
+	id, err := r.GetOrCreate(context.Background(), "ignored:latest", dir, false)
+	if err != nil || id != dir {
+		t.Fatalf("GetOrCreate = %q, %v; want the workspace", id, err)
+	}
+	result, err := r.Run(context.Background(), id, "cat main.go; echo oops >&2; exit 3", time.Minute)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if string(result.Stdout) != "package main\n" || string(result.Stderr) != "oops\n" || result.ExitCode != 3 {
+		t.Errorf("result = %q %q %d", result.Stdout, result.Stderr, result.ExitCode)
+	}
+}
+
+func TestRunner_RunWithStdin(t *testing.T) {
+	r := NewRunner(Config{})
+	result, err := r.RunWithStdin(context.Background(), t.TempDir(), "wc -l | tr -d ' '", []byte("a.go\nb.go\n"), time.Minute)
+	if err != nil {
+		t.Fatalf("RunWithStdin: %v", err)
+	}
+	if string(result.Stdout) != "2\n" {
+		t.Errorf("expected the command to read 2 lines, got %q", result.Stdout)
+	}
+}
+
+func TestRunner_RestrictedEnvironment(t *testing.T) {
+	env := map[string]string{
+		"PATH":           "/host/bin",
+		"HOME":           "/home/dev",
+		"GATEKEEPER_KEY": "secret",
+		"GOFLAGS":        "-mod=mod",
+	}
+	r := NewRunner(Config{Path: []string{"/usr/bin", "/bin"}, Env: []string{"GOFLAGS"}})
+	r.getenv = func(name string) string { return env[name] }
+
+	got := strings.Join(r.environ(), " ")
+	want := "PATH=/usr/bin" + string(filepath.ListSeparator) + "/bin HOME=/home/dev GOFLAGS=-mod=mod"
+	if got != want {
+		t.Errorf("environ = %q, want %q", got, want)
+	}
+
+	r = NewRunner(Config{})
+	r.getenv = func(name string) string { return env[name] }
+	if got := r.environ()[0]; got != "PATH=/host/bin" {
+		t.Errorf("expected the host PATH without an allowlist, got %q", got)
+	}
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	r := NewRunner(Config{})
+	start := time.Now()
+	_, err := r.Run(context.Background(), t.TempDir(), "sleep 10", 100*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timed-out command was not killed (took %s)", elapsed)
+	}
+}
+
+func TestRunner_NixShell(t *testing.T) {
+	dir := t.TempDir()
+	r := NewRunner(Config{Nix: true})
+	if _, err := r.shell(dir); err == nil {
+		t.Error("expected an error without a Nix shell")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "shell.nix"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if args, _ := r.shell(dir); strings.Join(args, " ") != "nix-shell --run" {
+		t.Errorf("shell.nix: args = %v", args)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "flake.nix"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if args, _ := r.shell(dir); strings.Join(args, " ") != "nix develop --command sh -c" {
+		t.Errorf("flake.nix: args = %v", args)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package local
+
+import "os/exec"
+
+// killGroup leaves cmd as is: without process groups, only the shell is
+// killed on cancellation, and waitDelay stops waiting for its children.
+func killGroup(*exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+package local
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killGroup runs cmd in its own process group and kills the whole group on
+// cancellation, so programs started by the shell do not outlive a timeout.
+func killGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,171 @@
+// Package metrics reports per-gate run durations, broken down by phase, as a
+// table after a run and as Prometheus metrics pushed to a Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/platform/promtext"
+)
+
+// DefaultJob is the Pushgateway job label used when none is configured.
+const DefaultJob = "gatekeeper"
+
+// pushTimeout bounds a Pushgateway request so a slow gateway cannot stall a commit.
+const pushTimeout = 5 * time.Second
+
+// WriteTimings prints a per-gate breakdown of the run's durations. Container
+// phases are shown for container gates and the provider time for LLM gates;
+// phases that do not apply show "-".
+func WriteTimings(w io.Writer, result formatter.RunResult) {
+	fmt.Fprintf(w, "\n⏱️  Timings\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "   gate\tpull\tacquire\texec\tparse\tllm\ttotal\n")
+	for _, g := range result.Gates {
+		if g.Skipped || g.Deferred {
+			continue
+		}
+		pull, acquire, exec, parse, llm := "-", "-", "-", "-", "-"
+		switch t := g.Timings; {
+		case t == nil:
+		case g.Type == "llm":
+			llm = formatter.DurationString(t.LLMMs)
+		default:
+			pull, acquire, exec, parse = formatter.DurationString(t.ImagePullMs), formatter.DurationString(t.AcquireMs), formatter.DurationString(t.ExecMs), formatter.DurationString(t.ParseMs)
+		}
+		fmt.Fprintf(tw, "   %s\t%s\t%s\t%s\t%s\t%s\t%s\n", g.Name, pull, acquire, exec, parse, llm, formatter.DurationString(g.DurationMs))
+	}
+	fmt.Fprintf(tw, "   run\t\t\t\t\t\t%s\n", formatter.DurationString(result.DurationMs))
+	_ = tw.Flush()
+}
+
+// WritePrometheus writes the run's durations and outcomes in the Prometheus
+// text exposition format.
+func WritePrometheus(w io.Writer, result formatter.RunResult) error {
+	pw := promtext.NewWriter(w)
+
+	pw.Header("gatekeeper_run_duration_seconds", "gauge", "Wall-clock duration of the run.")
+	pw.Printf("gatekeeper_run_duration_seconds %g\n", seconds(result.DurationMs))
+
+	pw.Header("gatekeeper_run_passed", "gauge", "Whether the run passed (1) or failed (0).")
+	pw.Printf("gatekeeper_run_passed %d\n", boolValue(result.Passed))
+
+	pw.Header("gatekeeper_gate_duration_seconds", "gauge", "Duration of a gate, by phase. Phase \"total\" covers the whole gate.")
+	for _, g := range result.Gates {
+		if g.Skipped || g.Deferred {
+			continue
+		}
+		name := promtext.EscapeLabel(g.Name)
+		switch t := g.Timings; {
+		case t == nil:
+		case g.Type == "llm":
+			pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"llm\"} %g\n", name, seconds(t.LLMMs))
+		default:
+			pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"image_pull\"} %g\n", name, seconds(t.ImagePullMs))
+			pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"acquire\"} %g\n", name, seconds(t.AcquireMs))
+			pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"exec\"} %g\n", name, seconds(t.ExecMs))
+			pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"parse\"} %g\n", name, seconds(t.ParseMs))
+		}
+		pw.Printf("gatekeeper_gate_duration_seconds{gate=\"%s\",phase=\"total\"} %g\n", name, seconds(g.DurationMs))
+	}
+
+	pw.Header("gatekeeper_gate_passed", "gauge", "Whether a gate passed (1) or failed (0).")
+	for _, g := range result.Gates {
+		if g.Skipped || g.Deferred {
+			continue
+		}
+		pw.Printf("gatekeeper_gate_passed{gate=\"%s\"} %d\n", promtext.EscapeLabel(g.Name), boolValue(g.Passed))
+	}
+
+	return pw.Err()
+}
+
+// Pushgateway pushes run metrics to a Prometheus Pushgateway.
+type Pushgateway struct {
+	// URL is the Pushgateway base URL, e.g. http://pushgateway:9091.
+	URL string
+	// Job is the job label of the pushed group. If empty, DefaultJob is used.
+	Job string
+	// Project is added to the grouping key so each project keeps its own series.
+	Project string
+	// Client sends the request. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// Push replaces the metrics of this job and project with the run's metrics.
+func (p *Pushgateway) Push(ctx context.Context, result formatter.RunResult) error {
+	var body bytes.Buffer
+	if err := WritePrometheus(&body, result); err != nil {
+		return fmt.Errorf("encoding metrics: %w", err)
+	}
+
+	endpoint, err := p.endpoint()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("creating pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", promtext.ContentType)
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing metrics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pushing metrics: pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// endpoint builds the grouping-key URL: <base>/metrics/job/<job>[/project/<project>].
+func (p *Pushgateway) endpoint() (string, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid pushgateway URL %q: expected a URL such as http://localhost:9091", p.URL)
+	}
+	job := p.Job
+	if job == "" {
+		job = DefaultJob
+	}
+	base := strings.TrimRight(u.EscapedPath(), "/")
+	rawPath := base + "/metrics/job/" + url.PathEscape(job)
+	if p.Project != "" {
+		rawPath += "/project/" + url.PathEscape(p.Project)
+	}
+	if u.Path, err = url.PathUnescape(rawPath); err != nil {
+		return "", fmt.Errorf("invalid pushgateway URL %q: %w", p.URL, err)
+	}
+	u.RawPath = rawPath
+	return u.String(), nil
+}
+
+func seconds(ms int64) float64 {
+	return float64(ms) / 1000
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
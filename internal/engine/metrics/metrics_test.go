@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+func testResult() formatter.RunResult {
+	return formatter.RunResult{
+		Passed:     false,
+		DurationMs: 4500,
+		Gates: []formatter.GateResult{
+			{Name: "go-test", Passed: true, DurationMs: 4300, Timings: &formatter.PhaseTimings{AcquireMs: 120, ExecMs: 4170, ParseMs: 3}},
+			{Name: "node-build", Passed: true, DurationMs: 9000, Timings: &formatter.PhaseTimings{ImagePullMs: 7200, AcquireMs: 800, ExecMs: 950, ParseMs: 2}},
+			{Name: "review", Type: "llm", Passed: false, DurationMs: 2100},
+			{Name: "summary", Type: "llm", Passed: true, DurationMs: 1300, Timings: &formatter.PhaseTimings{LLMMs: 1250}},
+			{Name: "lint", Skipped: true},
+		},
+	}
+}
+
+func TestWriteTimings(t *testing.T) {
+	var buf bytes.Buffer
+	WriteTimings(&buf, testResult())
+	out := buf.String()
+
+	for _, want := range []string{"Timings", "pull", "acquire", "llm", "go-test", "120ms", "4.2s", "3ms", "4.3s", "node-build", "7.2s", "review", "2.1s", "summary", "1.2s", "run", "4.5s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "lint") {
+		t.Errorf("skipped gate should not be listed:\n%s", out)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, testResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"gatekeeper_run_duration_seconds 4.5",
+		"gatekeeper_run_passed 0",
+		`gatekeeper_gate_duration_seconds{gate="go-test",phase="acquire"} 0.12`,
+		`gatekeeper_gate_duration_seconds{gate="go-test",phase="exec"} 4.17`,
+		`gatekeeper_gate_duration_seconds{gate="go-test",phase="parse"} 0.003`,
+		`gatekeeper_gate_duration_seconds{gate="node-build",phase="image_pull"} 7.2`,
+		`gatekeeper_gate_duration_seconds{gate="summary",phase="llm"} 1.25`,
+		`gatekeeper_gate_duration_seconds{gate="review",phase="total"} 2.1`,
+		`gatekeeper_gate_passed{gate="go-test"} 1`,
+		`gatekeeper_gate_passed{gate="review"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `gate="review",phase="acquire"`) {
+		t.Errorf("gates without timings should only report total:\n%s", out)
+	}
+	if strings.Contains(out, `gate="lint"`) {
+		t.Errorf("skipped gate should not be reported:\n%s", out)
+	}
+}
+
+func TestPushgateway_Push(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := &Pushgateway{URL: srv.URL, Project: "my app", Client: srv.Client()}
+	if err := p.Push(context.Background(), testResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", method)
+	}
+	if path != "/metrics/job/gatekeeper/project/my app" {
+		t.Errorf("path = %q", path)
+	}
+	if !strings.Contains(body, "gatekeeper_run_passed 0") {
+		t.Errorf("expected metrics in body:\n%s", body)
+	}
+}
+
+func TestPushgateway_Push_CustomJobAndBasePath(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := &Pushgateway{URL: srv.URL + "/pgw/", Job: "ci"}
+	if err := p.Push(context.Background(), testResult()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "/pgw/metrics/job/ci" {
+		t.Errorf("path = %q", path)
+	}
+}
+
+func TestPushgateway_Push_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "bad metric", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p := &Pushgateway{URL: srv.URL}
+	err := p.Push(context.Background(), testResult())
+	if err == nil || !strings.Contains(err.Error(), "bad metric") {
+		t.Fatalf("expected error with gateway message, got %v", err)
+	}
+}
+
+func TestPushgateway_Push_InvalidURL(t *testing.T) {
+	p := &Pushgateway{URL: "localhost:9091"}
+	if err := p.Push(context.Background(), testResult()); err == nil {
+		t.Fatal("expected error for URL without scheme")
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BufParser parses `buf lint` and `buf breaking` output with
+// --error-format json (one JSON object per line).
+type BufParser struct{}
+
+// NewBufParser creates a new BufParser.
+func NewBufParser() *BufParser {
+	return &BufParser{}
+}
+
+// bufAnnotation is a single finding of buf's JSON error format.
+type bufAnnotation struct {
+	Path        string `json:"path"`
+	StartLine   int    `json:"start_line"`
+	StartColumn int    `json:"start_column"`
+	Type        string `json:"type"`
+	Message     string `json:"message"`
+}
+
+// Parse implements the Parser interface for buf JSON output.
+// buf has no severities, so every finding is an error and fails the gate, as
+// does a non-zero exit code. A failure without annotations, such as a broken
+// buf.yaml, falls back to the generic parser.
+func (p *BufParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var annotations []bufAnnotation
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var a bufAnnotation
+		if err := dec.Decode(&a); err != nil {
+			return err
+		}
+		annotations = append(annotations, a)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing buf JSON output: %w", err)
+	}
+
+	errs := make([]StructuredError, 0, len(annotations))
+	for _, a := range annotations {
+		errs = append(errs, StructuredError{
+			File:     a.Path,
+			Line:     a.StartLine,
+			Column:   a.StartColumn,
+			Severity: "error",
+			Rule:     a.Type,
+			Message:  a.Message,
+			Tool:     "buf",
+		})
+	}
+
+	failed := exitCode != 0 || len(errs) > 0
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+const bufOutput = `{"path":"acme/v1/user.proto","start_line":8,"start_column":3,"end_line":8,"end_column":20,"type":"FIELD_LOWER_SNAKE_CASE","message":"Field name \"userID\" should be lower_snake_case, such as \"user_id\"."}
+{"path":"acme/v1/order.proto","start_line":12,"start_column":1,"end_line":12,"end_column":1,"type":"FIELD_NO_DELETE","message":"Previously present field \"3\" with name \"note\" on message \"Order\" was deleted."}
+`
+
+func TestBufParser_Findings(t *testing.T) {
+	res, err := NewBufParser().Parse(context.Background(), []byte(bufOutput), nil, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	e := res.Errors[1]
+	if e.File != "acme/v1/order.proto" || e.Line != 12 || e.Column != 1 || e.Rule != "FIELD_NO_DELETE" || e.Severity != "error" || e.Tool != "buf" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestBufParser_FailureWithoutFindings(t *testing.T) {
+	res, err := NewBufParser().Parse(context.Background(), nil, []byte("Failure: .git: repository does not exist"), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) == 0 {
+		t.Errorf("expected a failure with the raw output, got %+v", res)
+	}
+}
+
+func TestBufParser_EmptyAndInvalid(t *testing.T) {
+	res, err := NewBufParser().Parse(context.Background(), nil, nil, 0)
+	if err != nil || !res.Passed {
+		t.Errorf("expected clean pass, got %+v, %v", res, err)
+	}
+	if _, err := NewBufParser().Parse(context.Background(), []byte("not json"), nil, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
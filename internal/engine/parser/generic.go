@@ -6,7 +6,9 @@ import (
 )
 
 // GenericParser is a fallback parser for tools without structured output.
-// It relies solely on the exit code.
+// It relies solely on the exit code. Structured parsers also fall back to it
+// when a tool fails without any finding they can parse, such as a crash or a
+// configuration error, so that its raw output is still reported.
 type GenericParser struct{}
 
 // NewGenericParser creates a new GenericParser.
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// HadolintParser parses `hadolint -f json` output (a JSON array of findings).
+type HadolintParser struct{}
+
+// NewHadolintParser creates a new HadolintParser.
+func NewHadolintParser() *HadolintParser {
+	return &HadolintParser{}
+}
+
+// hadolintFinding is a single entry of `hadolint -f json`.
+type hadolintFinding struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Code    string `json:"code"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// Parse implements the Parser interface for hadolint JSON output.
+// The gate fails on a non-zero exit code (hadolint's --failure-threshold decides)
+// or any error-level finding. A failure without findings, such as a Dockerfile
+// hadolint cannot read, falls back to the generic parser.
+func (p *HadolintParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var findings []hadolintFinding
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var batch []hadolintFinding
+		if err := dec.Decode(&batch); err != nil {
+			return err
+		}
+		findings = append(findings, batch...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing hadolint JSON output: %w", err)
+	}
+
+	failed := exitCode != 0
+	errs := make([]StructuredError, 0, len(findings))
+	for _, f := range findings {
+		e := StructuredError{
+			File:     f.File,
+			Line:     f.Line,
+			Column:   f.Column,
+			Severity: lintLevelSeverity(f.Level),
+			Rule:     f.Code,
+			Message:  f.Message,
+			Tool:     "hadolint",
+		}
+		if e.Severity == "error" {
+			failed = true
+		}
+		errs = append(errs, e)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// decodeJSONStream calls decode for each top-level JSON value in data, so output
+// concatenated from several tool invocations (e.g., "find -exec ... +") is accepted.
+// Empty output decodes to nothing.
+func decodeJSONStream(data []byte, decode func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		if err := decode(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lintLevelSeverity maps hadolint/shellcheck/tflint/squawk levels (error, warning, info, style)
+// to StructuredError severities.
+func lintLevelSeverity(level string) string {
+	switch level {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "info"
+	}
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+const hadolintOutput = `[
+  {"code":"DL3008","column":1,"file":"Dockerfile","level":"warning","line":4,"message":"Pin versions in apt get install."},
+  {"code":"DL3000","column":1,"file":"Dockerfile","level":"error","line":6,"message":"Use absolute WORKDIR"}
+]`
+
+func TestHadolintParser_Findings(t *testing.T) {
+	res, err := NewHadolintParser().Parse(context.Background(), []byte(hadolintOutput), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	e := res.Errors[1]
+	if e.File != "Dockerfile" || e.Line != 6 || e.Rule != "DL3000" || e.Severity != "error" || e.Tool != "hadolint" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestHadolintParser_WarningsBelowThreshold(t *testing.T) {
+	out := `[{"code":"DL3008","column":1,"file":"Dockerfile","level":"warning","line":4,"message":"Pin versions"}]`
+	res, err := NewHadolintParser().Parse(context.Background(), []byte(out), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 1 {
+		t.Errorf("expected pass with one warning, got %+v", res)
+	}
+}
+
+func TestHadolintParser_EmptyAndInvalid(t *testing.T) {
+	res, err := NewHadolintParser().Parse(context.Background(), []byte("[]"), nil, 0)
+	if err != nil || !res.Passed {
+		t.Errorf("expected clean pass, got %+v, %v", res, err)
+	}
+	if _, err := NewHadolintParser().Parse(context.Background(), []byte("not json"), nil, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
@@ -1,19 +1,42 @@
 package parser
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/assets"
 )
 
+func enrich(t *testing.T, errors []StructuredError) []StructuredError {
+	t.Helper()
+	result, err := EnrichHints(errors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func hint(t *testing.T, rule string) string {
+	t.Helper()
+	db, err := hints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db[rule]
+}
+
 func TestEnrichHints_KnownRule(t *testing.T) {
 	errors := []StructuredError{
 		{Rule: "G101", Message: "hardcoded credential"},
 	}
-	result := EnrichHints(errors)
+	result := enrich(t, errors)
 	if result[0].Hint == "" {
 		t.Error("expected hint to be populated for known rule G101")
 	}
-	if result[0].Hint != hintDatabase["G101"] {
-		t.Errorf("expected hint %q, got %q", hintDatabase["G101"], result[0].Hint)
+	if result[0].Hint != hint(t, "G101") {
+		t.Errorf("expected hint %q, got %q", hint(t, "G101"), result[0].Hint)
 	}
 }
 
@@ -21,7 +44,7 @@ func TestEnrichHints_UnknownRule(t *testing.T) {
 	errors := []StructuredError{
 		{Rule: "UNKNOWN_RULE_XYZ", Message: "something"},
 	}
-	result := EnrichHints(errors)
+	result := enrich(t, errors)
 	if result[0].Hint != "" {
 		t.Errorf("expected empty hint for unknown rule, got %q", result[0].Hint)
 	}
@@ -32,14 +55,14 @@ func TestEnrichHints_PreservesExistingHint(t *testing.T) {
 	errors := []StructuredError{
 		{Rule: "G101", Hint: existing},
 	}
-	result := EnrichHints(errors)
+	result := enrich(t, errors)
 	if result[0].Hint != existing {
 		t.Errorf("expected existing hint %q to be preserved, got %q", existing, result[0].Hint)
 	}
 }
 
 func TestEnrichHints_EmptySlice(t *testing.T) {
-	result := EnrichHints(nil)
+	result := enrich(t, nil)
 	if result != nil {
 		t.Errorf("expected nil result for nil input, got %v", result)
 	}
@@ -52,12 +75,12 @@ func TestEnrichHints_MixedRules(t *testing.T) {
 		{Rule: "NONEXISTENT", Message: "unknown"},
 		{Rule: "B608", Hint: "already has hint"},
 	}
-	result := EnrichHints(errors)
+	result := enrich(t, errors)
 
-	if result[0].Hint != hintDatabase["G201"] {
+	if result[0].Hint != hint(t, "G201") {
 		t.Errorf("G201: expected hint, got %q", result[0].Hint)
 	}
-	if result[1].Hint != hintDatabase["no-unused-vars"] {
+	if result[1].Hint != hint(t, "no-unused-vars") {
 		t.Errorf("no-unused-vars: expected hint, got %q", result[1].Hint)
 	}
 	if result[2].Hint != "" {
@@ -67,3 +90,40 @@ func TestEnrichHints_MixedRules(t *testing.T) {
 		t.Errorf("B608: expected preserved hint, got %q", result[3].Hint)
 	}
 }
+
+func TestLoadHints_Override(t *testing.T) {
+	dir := t.TempDir()
+	assets.SetOverrideDir(dir)
+	t.Cleanup(func() { assets.SetOverrideDir("") })
+	writeHintsOverride(t, dir, "G101: Use the vault.\n")
+
+	db, err := loadHints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db["G101"] != "Use the vault." || db["G201"] == "" {
+		t.Errorf("expected the override merged over the built-in hints, got G101=%q G201=%q", db["G101"], db["G201"])
+	}
+}
+
+func TestLoadHints_MalformedOverride(t *testing.T) {
+	dir := t.TempDir()
+	assets.SetOverrideDir(dir)
+	t.Cleanup(func() { assets.SetOverrideDir("") })
+	writeHintsOverride(t, dir, "- not\n- a map\n")
+
+	db, err := loadHints()
+	if err == nil || !strings.Contains(err.Error(), "hints.yaml override") {
+		t.Fatalf("expected the malformed override to be reported, got %v", err)
+	}
+	if db["G101"] == "" {
+		t.Error("expected the built-in hints to remain usable")
+	}
+}
+
+func writeHintsOverride(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "hints.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
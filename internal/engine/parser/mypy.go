@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// MypyParser parses `mypy --output json` output (one JSON object per line).
+type MypyParser struct{}
+
+// NewMypyParser creates a new MypyParser.
+func NewMypyParser() *MypyParser {
+	return &MypyParser{}
+}
+
+// mypyDiagnostic is a single line of `mypy --output json`.
+type mypyDiagnostic struct {
+	File     string  `json:"file"`
+	Line     int     `json:"line"`
+	Column   int     `json:"column"`
+	Message  string  `json:"message"`
+	Hint     *string `json:"hint"`
+	Code     *string `json:"code"`
+	Severity string  `json:"severity"`
+}
+
+// Parse implements the Parser interface for mypy JSON output.
+// Non-JSON lines (e.g., summaries or crash banners) are ignored. The gate fails
+// on a non-zero exit code or any error diagnostic; a failure with only such
+// non-JSON lines falls back to the generic parser.
+func (p *MypyParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var errs []StructuredError
+	failed := exitCode != 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		var d mypyDiagnostic
+		if err := json.Unmarshal(line, &d); err != nil {
+			return nil, fmt.Errorf("parsing mypy JSON output: %w", err)
+		}
+
+		e := StructuredError{
+			File:     d.File,
+			Line:     d.Line,
+			Severity: mypySeverity(d.Severity),
+			Message:  d.Message,
+			Tool:     "mypy",
+		}
+		// mypy reports 0-based columns; -1 means unknown.
+		if d.Column >= 0 {
+			e.Column = d.Column + 1
+		}
+		if d.Code != nil {
+			e.Rule = *d.Code
+		}
+		if d.Hint != nil {
+			e.Hint = *d.Hint
+		}
+		if e.Severity == "error" {
+			failed = true
+		}
+		errs = append(errs, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning mypy output: %w", err)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// mypySeverity maps mypy severities to StructuredError severities.
+func mypySeverity(s string) string {
+	switch s {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "info" // "note"
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMypyParser_Diagnostics(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "mypy.jsonl"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	res, err := NewMypyParser().Parse(context.Background(), data, nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %d", len(res.Errors))
+	}
+
+	first := res.Errors[0]
+	if first.File != "app/models.py" || first.Line != 12 || first.Column != 5 || first.Rule != "assignment" || first.Tool != "mypy" {
+		t.Errorf("unexpected first error: %+v", first)
+	}
+	if res.Errors[1].Hint == "" {
+		t.Error("expected mypy hint to be carried over")
+	}
+	if note := res.Errors[2]; note.Severity != "info" || note.Column != 0 || note.Rule != "" {
+		t.Errorf("unexpected note: %+v", note)
+	}
+}
+
+func TestMypyParser_Clean(t *testing.T) {
+	res, err := NewMypyParser().Parse(context.Background(), nil, nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 0 {
+		t.Errorf("expected clean pass, got %+v", res)
+	}
+}
+
+func TestMypyParser_CrashFallsBack(t *testing.T) {
+	res, err := NewMypyParser().Parse(context.Background(), []byte("mypy: error: unrecognized arguments\n"), nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) == 0 {
+		t.Errorf("expected fail-closed result, got %+v", res)
+	}
+}
+
+func TestMypyParser_MalformedJSON(t *testing.T) {
+	if _, err := NewMypyParser().Parse(context.Background(), []byte("{not json\n"), nil, 1); err == nil {
+		t.Error("expected error for malformed JSON line")
+	}
+}
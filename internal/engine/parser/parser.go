@@ -16,11 +16,13 @@ type StructuredError struct {
 	File     string `json:"file"`
 	Line     int    `json:"line"`
 	Column   int    `json:"column,omitempty"`
-	Severity string `json:"severity"`       // error, warning, info
+	Severity string `json:"severity"`       // error, warning, info, suggestion
 	Rule     string `json:"rule,omitempty"` // e.g., "gosec:G101"
 	Message  string `json:"message"`
 	Hint     string `json:"hint,omitempty"`
 	Tool     string `json:"tool"`
+	// Confidence is the reviewer's certainty from 0 to 1, reported by LLM gates only.
+	Confidence *float64 `json:"confidence,omitempty"`
 }
 
 // Parser parses raw tool output into structured results.
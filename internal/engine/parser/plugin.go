@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// PluginPrefix marks a parser reference as an external program, e.g. "exec:./tools/parse.sh".
+const PluginPrefix = "exec:"
+
+// pluginTimeout bounds how long an external parser may run.
+const pluginTimeout = 30 * time.Second
+
+// IsPlugin reports whether a parser name refers to an external parser program.
+func IsPlugin(name string) bool {
+	return strings.HasPrefix(name, PluginPrefix)
+}
+
+// pluginInput is the JSON document written to an external parser's stdin.
+type pluginInput struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// pluginOutput is the JSON document an external parser may print.
+// A bare array of StructuredError is also accepted.
+type pluginOutput struct {
+	Passed *bool             `json:"passed"`
+	Errors []StructuredError `json:"errors"`
+}
+
+// ExecParser delegates parsing to an external program on the host.
+//
+// The program receives the tool's stdout, stderr, and exit code as a JSON object
+// on stdin and must print either {"passed": bool, "errors": [...]} or a bare
+// array of StructuredError. Any failure of the program is treated as a parser
+// error (fail-closed), never as a pass.
+type ExecParser struct {
+	path string
+	dir  string
+}
+
+// NewExecParser creates a parser for the given plugin reference ("exec:<path>").
+// Relative paths are resolved against projectPath, which is also the working directory.
+func NewExecParser(ref, projectPath string) *ExecParser {
+	path := strings.TrimSpace(strings.TrimPrefix(ref, PluginPrefix))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	return &ExecParser{path: path, dir: projectPath}
+}
+
+// Parse implements the Parser interface by running the external program.
+func (p *ExecParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	log := logger.FromContext(ctx)
+	log.Debug("running parser plugin", "path", p.path)
+
+	input, err := json.Marshal(pluginInput{
+		Stdout:   string(stdout),
+		Stderr:   string(stderr),
+		ExitCode: exitCode,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding plugin input: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.path) // #nosec G204 -- plugin path comes from the project's own gates.yaml
+	cmd.Dir = p.dir
+	cmd.Stdin = bytes.NewReader(input)
+
+	var out, errOut bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errOut
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("parser plugin %s: %w (stderr: %s)", p.path, err, strings.TrimSpace(errOut.String()))
+	}
+
+	result, err := decodePluginOutput(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("parser plugin %s: %w", p.path, err)
+	}
+
+	log.Debug("parser plugin completed", "path", p.path, "passed", result.Passed, "issues", len(result.Errors))
+	return result, nil
+}
+
+// decodePluginOutput accepts either the object form or a bare array.
+// Without an explicit "passed" field, the result fails if any entry has error severity.
+func decodePluginOutput(data []byte) (*ParseResult, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	var out pluginOutput
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &out.Errors); err != nil {
+			return nil, fmt.Errorf("decoding output: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("decoding output: %w", err)
+	}
+
+	passed := true
+	if out.Passed != nil {
+		passed = *out.Passed
+	} else {
+		for _, e := range out.Errors {
+			if e.Severity == "error" {
+				passed = false
+				break
+			}
+		}
+	}
+
+	return &ParseResult{Passed: passed, Errors: out.Errors}, nil
+}
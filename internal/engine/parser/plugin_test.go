@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePlugin creates an executable shell script in dir and returns its name.
+func writePlugin(t *testing.T, dir, body string) string {
+	t.Helper()
+	name := "parse.sh"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+body), 0o700); err != nil { // #nosec G306 -- test script must be executable
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestIsPlugin(t *testing.T) {
+	if !IsPlugin("exec:./parse.sh") {
+		t.Error("expected exec: prefix to be a plugin")
+	}
+	if IsPlugin("sarif") {
+		t.Error("expected sarif not to be a plugin")
+	}
+}
+
+func TestExecParser_ObjectOutput(t *testing.T) {
+	dir := t.TempDir()
+	name := writePlugin(t, dir, `cat >/dev/null
+echo '{"passed": false, "errors": [{"file": "a.go", "line": 3, "severity": "warning", "message": "bad", "tool": "custom"}]}'
+`)
+
+	p := NewExecParser("exec:./"+name, dir)
+	res, err := p.Parse(context.Background(), []byte("out"), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected explicit passed=false to be honored")
+	}
+	if len(res.Errors) != 1 || res.Errors[0].File != "a.go" || res.Errors[0].Line != 3 {
+		t.Errorf("unexpected errors: %+v", res.Errors)
+	}
+}
+
+func TestExecParser_ArrayOutput(t *testing.T) {
+	dir := t.TempDir()
+	name := writePlugin(t, dir, `cat >/dev/null
+echo '[{"file": "a.go", "severity": "error", "message": "boom", "tool": "custom"}]'
+`)
+
+	p := NewExecParser("exec:"+name, dir)
+	res, err := p.Parse(context.Background(), nil, nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected error-severity entry to fail the result")
+	}
+}
+
+func TestExecParser_ReceivesInput(t *testing.T) {
+	dir := t.TempDir()
+	// Echo the input back as the message so the test can inspect it.
+	name := writePlugin(t, dir, `input=$(cat | tr -d '"')
+echo "[{\"severity\": \"info\", \"message\": \"$input\", \"tool\": \"custom\"}]"
+`)
+
+	p := NewExecParser("exec:"+name, dir)
+	res, err := p.Parse(context.Background(), []byte("hello"), []byte("oops"), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed {
+		t.Error("expected info-only result to pass")
+	}
+	msg := res.Errors[0].Message
+	for _, want := range []string{"stdout:hello", "stderr:oops", "exit_code:2"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("expected plugin input to contain %q, got %q", want, msg)
+		}
+	}
+}
+
+func TestExecParser_PluginFails(t *testing.T) {
+	dir := t.TempDir()
+	name := writePlugin(t, dir, "echo nope >&2\nexit 3\n")
+
+	p := NewExecParser("exec:"+name, dir)
+	_, err := p.Parse(context.Background(), nil, nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected plugin failure with stderr, got %v", err)
+	}
+}
+
+func TestExecParser_InvalidOutput(t *testing.T) {
+	dir := t.TempDir()
+	name := writePlugin(t, dir, "echo not-json\n")
+
+	p := NewExecParser("exec:"+name, dir)
+	if _, err := p.Parse(context.Background(), nil, nil, 0); err == nil {
+		t.Fatal("expected error for invalid plugin output")
+	}
+}
+
+func TestExecParser_EmptyOutput(t *testing.T) {
+	dir := t.TempDir()
+	name := writePlugin(t, dir, "true\n")
+
+	p := NewExecParser("exec:"+name, dir)
+	if _, err := p.Parse(context.Background(), nil, nil, 0); err == nil {
+		t.Fatal("expected error for empty plugin output (fail-closed)")
+	}
+}
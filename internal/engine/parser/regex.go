@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RegexConfig describes how to extract StructuredErrors from line-oriented output.
+// Group fields are 1-based capture group indexes; 0 means "not captured".
+type RegexConfig struct {
+	Pattern       string
+	FileGroup     int
+	LineGroup     int
+	ColumnGroup   int
+	SeverityGroup int
+	RuleGroup     int
+	MessageGroup  int
+	// Severity is the fallback severity when no group captures one (default "error").
+	Severity string
+}
+
+// RegexParser converts arbitrary line-oriented tool output (e.g., "file:line: message")
+// into StructuredErrors using a user-supplied regular expression.
+type RegexParser struct {
+	cfg RegexConfig
+	re  *regexp.Regexp
+}
+
+// NewRegexParser compiles the pattern and returns a RegexParser.
+func NewRegexParser(cfg RegexConfig) (*RegexParser, error) {
+	re, err := regexp.Compile(cfg.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compiling regex parser pattern: %w", err)
+	}
+	if cfg.Severity == "" {
+		cfg.Severity = "error"
+	}
+	return &RegexParser{cfg: cfg, re: re}, nil
+}
+
+// Parse implements the Parser interface.
+// Every line of stdout and stderr matching the pattern becomes a StructuredError.
+// The gate passes only if the exit code is 0 and no error-severity match was found.
+// A non-zero exit code with no matches fails closed with the raw output as message.
+func (p *RegexParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var errs []StructuredError
+	failed := exitCode != 0
+
+	for _, stream := range [][]byte{stdout, stderr} {
+		scanner := bufio.NewScanner(bytes.NewReader(stream))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			m := p.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			e := p.toError(line, m)
+			if e.Severity == "error" {
+				failed = true
+			}
+			errs = append(errs, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("scanning tool output: %w", err)
+		}
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// toError maps regex submatches to a StructuredError.
+// Without a message group, the whole matched line is used as the message.
+func (p *RegexParser) toError(line string, m []string) StructuredError {
+	group := func(i int) string {
+		if i <= 0 || i >= len(m) {
+			return ""
+		}
+		return strings.TrimSpace(m[i])
+	}
+
+	e := StructuredError{
+		File:     group(p.cfg.FileGroup),
+		Rule:     group(p.cfg.RuleGroup),
+		Message:  group(p.cfg.MessageGroup),
+		Severity: normalizeSeverity(group(p.cfg.SeverityGroup), p.cfg.Severity),
+		Tool:     "regex",
+	}
+	e.Line, _ = strconv.Atoi(group(p.cfg.LineGroup))
+	e.Column, _ = strconv.Atoi(group(p.cfg.ColumnGroup))
+	if e.Message == "" {
+		e.Message = strings.TrimSpace(line)
+	}
+	return e
+}
+
+// normalizeSeverity maps common tool severity words onto error/warning/info.
+func normalizeSeverity(s, fallback string) string {
+	switch strings.ToLower(s) {
+	case "error", "err", "e", "fatal", "critical", "high":
+		return "error"
+	case "warning", "warn", "w", "medium":
+		return "warning"
+	case "info", "note", "i", "low", "hint", "style":
+		return "info"
+	default:
+		return fallback
+	}
+}
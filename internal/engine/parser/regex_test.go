@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestRegexParser(t *testing.T) *RegexParser {
+	t.Helper()
+	p, err := NewRegexParser(RegexConfig{
+		Pattern:       `^(.+?):(\d+):(\d+): (\w+): (.*)$`,
+		FileGroup:     1,
+		LineGroup:     2,
+		ColumnGroup:   3,
+		SeverityGroup: 4,
+		MessageGroup:  5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestRegexParser_Matches(t *testing.T) {
+	p := newTestRegexParser(t)
+	stdout := []byte("checking...\nmain.go:10:4: error: undefined: foo\nutil.go:3:1: warning: unused var\n")
+
+	res, err := p.Parse(context.Background(), stdout, nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+
+	e := res.Errors[0]
+	if e.File != "main.go" || e.Line != 10 || e.Column != 4 || e.Severity != "error" || e.Message != "undefined: foo" {
+		t.Errorf("unexpected first error: %+v", e)
+	}
+	if res.Errors[1].Severity != "warning" {
+		t.Errorf("expected warning severity, got %q", res.Errors[1].Severity)
+	}
+}
+
+func TestRegexParser_WarningsOnlyPass(t *testing.T) {
+	p := newTestRegexParser(t)
+	res, err := p.Parse(context.Background(), []byte("a.go:1:1: warning: style\n"), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed {
+		t.Error("expected pass when only warnings match and exit code is 0")
+	}
+}
+
+func TestRegexParser_StderrScanned(t *testing.T) {
+	p := newTestRegexParser(t)
+	res, err := p.Parse(context.Background(), nil, []byte("a.go:2:5: error: boom\n"), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) != 1 {
+		t.Errorf("expected a single error from stderr, got %+v", res)
+	}
+}
+
+func TestRegexParser_NoMatchNonZeroExit(t *testing.T) {
+	p := newTestRegexParser(t)
+	res, err := p.Parse(context.Background(), []byte("segfault"), nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected fail-closed on non-zero exit with no matches")
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Message != "segfault" {
+		t.Errorf("expected raw output as message, got %+v", res.Errors)
+	}
+}
+
+func TestRegexParser_DefaultSeverityAndMessage(t *testing.T) {
+	p, err := NewRegexParser(RegexConfig{Pattern: `^(\S+):(\d+) `, FileGroup: 1, LineGroup: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err := p.Parse(context.Background(), []byte("x.sh:7 something broke\n"), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := res.Errors[0]
+	if e.Severity != "error" {
+		t.Errorf("expected default severity error, got %q", e.Severity)
+	}
+	if e.Message != "x.sh:7 something broke" {
+		t.Errorf("expected whole line as message, got %q", e.Message)
+	}
+}
+
+func TestNewRegexParser_InvalidPattern(t *testing.T) {
+	if _, err := NewRegexParser(RegexConfig{Pattern: "("}); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
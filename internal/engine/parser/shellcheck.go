@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ShellCheckParser parses `shellcheck -f json` (array) and `-f json1`
+// ({"comments": [...]}) output.
+type ShellCheckParser struct{}
+
+// NewShellCheckParser creates a new ShellCheckParser.
+func NewShellCheckParser() *ShellCheckParser {
+	return &ShellCheckParser{}
+}
+
+// shellCheckComment is a single ShellCheck finding.
+type shellCheckComment struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Level   string `json:"level"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Parse implements the Parser interface for ShellCheck JSON output.
+// The gate fails on a non-zero exit code (ShellCheck's --severity decides) or any
+// error-level finding. A failure without findings falls back to the generic parser.
+func (p *ShellCheckParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var comments []shellCheckComment
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		batch, err := decodeShellCheck(raw)
+		if err != nil {
+			return err
+		}
+		comments = append(comments, batch...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing shellcheck JSON output: %w", err)
+	}
+
+	failed := exitCode != 0
+	errs := make([]StructuredError, 0, len(comments))
+	for _, c := range comments {
+		e := StructuredError{
+			File:     c.File,
+			Line:     c.Line,
+			Column:   c.Column,
+			Severity: lintLevelSeverity(c.Level),
+			Rule:     fmt.Sprintf("SC%d", c.Code),
+			Message:  c.Message,
+			Tool:     "shellcheck",
+		}
+		if e.Severity == "error" {
+			failed = true
+		}
+		errs = append(errs, e)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// decodeShellCheck decodes one JSON value in either the json or json1 format.
+func decodeShellCheck(raw json.RawMessage) ([]shellCheckComment, error) {
+	var list []shellCheckComment
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+
+	var wrapped struct {
+		Comments []shellCheckComment `json:"comments"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.Comments, nil
+}
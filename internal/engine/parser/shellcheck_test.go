@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestShellCheckParser_JSONArray(t *testing.T) {
+	out := `[{"file":"build.sh","line":3,"endLine":3,"column":8,"endColumn":12,"level":"warning","code":2086,"message":"Double quote to prevent globbing and word splitting.","fix":null}]`
+
+	res, err := NewShellCheckParser().Parse(context.Background(), []byte(out), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed on non-zero exit code")
+	}
+	if len(res.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(res.Errors))
+	}
+	e := res.Errors[0]
+	if e.File != "build.sh" || e.Line != 3 || e.Column != 8 || e.Rule != "SC2086" || e.Severity != "warning" || e.Tool != "shellcheck" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestShellCheckParser_JSON1Concatenated(t *testing.T) {
+	out := `{"comments":[{"file":"a.sh","line":1,"column":1,"level":"error","code":1073,"message":"Couldn't parse this"}]}
+{"comments":[{"file":"b.sh","line":2,"column":1,"level":"style","code":2006,"message":"Use $(...)"}]}`
+
+	res, err := NewShellCheckParser().Parse(context.Background(), []byte(out), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	if res.Errors[0].Severity != "error" || res.Errors[1].Severity != "info" {
+		t.Errorf("unexpected severities: %+v", res.Errors)
+	}
+}
+
+func TestShellCheckParser_CleanAndCrash(t *testing.T) {
+	res, err := NewShellCheckParser().Parse(context.Background(), nil, nil, 0)
+	if err != nil || !res.Passed {
+		t.Errorf("expected clean pass, got %+v, %v", res, err)
+	}
+
+	res, err = NewShellCheckParser().Parse(context.Background(), nil, []byte("shellcheck: not found"), 127)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) == 0 {
+		t.Errorf("expected fail-closed result, got %+v", res)
+	}
+}
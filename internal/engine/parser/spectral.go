@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SpectralParser parses `spectral lint --format json` output: a JSON array
+// of rule violations in OpenAPI and AsyncAPI documents.
+type SpectralParser struct{}
+
+// NewSpectralParser creates a new SpectralParser.
+func NewSpectralParser() *SpectralParser {
+	return &SpectralParser{}
+}
+
+// spectralResult is a single entry of `spectral lint --format json`. Lines
+// and characters are 0-based.
+type spectralResult struct {
+	Code     any    `json:"code"`
+	Message  string `json:"message"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Range    struct {
+		Start struct {
+			Line      int `json:"line"`
+			Character int `json:"character"`
+		} `json:"start"`
+	} `json:"range"`
+}
+
+// Parse implements the Parser interface for Spectral JSON output.
+// The gate fails on a non-zero exit code (Spectral's --fail-severity decides)
+// or any error-severity result. Spectral prints a plain-text notice instead
+// of JSON when there are no results, which passes on exit code 0. A failure
+// without results falls back to the generic parser.
+func (p *SpectralParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	if trimmed := bytes.TrimSpace(stdout); len(trimmed) > 0 && trimmed[0] != '[' {
+		if exitCode == 0 {
+			return &ParseResult{Passed: true}, nil
+		}
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	var results []spectralResult
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var batch []spectralResult
+		if err := dec.Decode(&batch); err != nil {
+			return err
+		}
+		results = append(results, batch...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing spectral JSON output: %w", err)
+	}
+
+	failed := exitCode != 0
+	errs := make([]StructuredError, 0, len(results))
+	for _, r := range results {
+		e := StructuredError{
+			File:     r.Source,
+			Line:     r.Range.Start.Line + 1,
+			Column:   r.Range.Start.Character + 1,
+			Severity: spectralSeverity(r.Severity),
+			Message:  r.Message,
+			Tool:     "spectral",
+		}
+		if r.Code != nil {
+			e.Rule = fmt.Sprint(r.Code)
+		}
+		if e.Severity == "error" {
+			failed = true
+		}
+		errs = append(errs, e)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// spectralSeverity maps Spectral's numeric severities (0 error, 1 warn,
+// 2 info, 3 hint) to StructuredError severities.
+func spectralSeverity(severity int) string {
+	switch severity {
+	case 0:
+		return "error"
+	case 1:
+		return "warning"
+	default:
+		return "info"
+	}
+}
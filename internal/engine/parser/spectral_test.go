@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+const spectralOutput = `[
+  {"code":"operation-description","path":["paths","/users","get"],"message":"Operation \"description\" must be present and non-empty string.","severity":1,"range":{"start":{"line":9,"character":8},"end":{"line":14,"character":20}},"source":"/workspace/api/openapi.yaml"},
+  {"code":"oas3-schema","path":["paths","/users","get","responses"],"message":"\"responses\" property must not have fewer than 1 properties.","severity":0,"range":{"start":{"line":15,"character":16},"end":{"line":15,"character":18}},"source":"/workspace/api/openapi.yaml"}
+]`
+
+func TestSpectralParser_Results(t *testing.T) {
+	res, err := NewSpectralParser().Parse(context.Background(), []byte(spectralOutput), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	w := res.Errors[0]
+	if w.File != "/workspace/api/openapi.yaml" || w.Line != 10 || w.Column != 9 || w.Severity != "warning" || w.Rule != "operation-description" || w.Tool != "spectral" {
+		t.Errorf("unexpected warning: %+v", w)
+	}
+	if res.Errors[1].Severity != "error" {
+		t.Errorf("expected an error, got %+v", res.Errors[1])
+	}
+}
+
+func TestSpectralParser_WarningsOnly(t *testing.T) {
+	out := `[{"code":"info-contact","message":"Info object must have \"contact\" object.","severity":1,"range":{"start":{"line":1,"character":2}},"source":"openapi.yaml"}]`
+	res, err := NewSpectralParser().Parse(context.Background(), []byte(out), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 1 {
+		t.Errorf("expected pass with one warning, got %+v", res)
+	}
+}
+
+func TestSpectralParser_NoResults(t *testing.T) {
+	for _, out := range []string{"[]", "No results with a severity of 'error' found!\n", ""} {
+		res, err := NewSpectralParser().Parse(context.Background(), []byte(out), nil, 0)
+		if err != nil || !res.Passed {
+			t.Errorf("output %q: expected clean pass, got %+v, %v", out, res, err)
+		}
+	}
+}
+
+func TestSpectralParser_Failure(t *testing.T) {
+	res, err := NewSpectralParser().Parse(context.Background(), []byte("No ruleset has been found."), nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) == 0 {
+		t.Errorf("expected a failure with the raw output, got %+v", res)
+	}
+	if _, err := NewSpectralParser().Parse(context.Background(), []byte("[not json"), nil, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
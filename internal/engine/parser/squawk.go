@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SquawkParser parses `squawk --reporter json` output: a JSON array of
+// violations of its Postgres migration rules.
+type SquawkParser struct{}
+
+// NewSquawkParser creates a new SquawkParser.
+func NewSquawkParser() *SquawkParser {
+	return &SquawkParser{}
+}
+
+// squawkViolation is a single entry of `squawk --reporter json`.
+type squawkViolation struct {
+	File     string `json:"file"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Level    string `json:"level"`
+	RuleName string `json:"rule_name"`
+	// Messages are {"Note": "..."} or {"Help": "..."} objects.
+	Messages []map[string]string `json:"messages"`
+}
+
+// Parse implements the Parser interface for squawk JSON output.
+// The gate fails on a non-zero exit code (squawk exits 1 on any violation
+// unless told otherwise) or an error-level violation. A failure without
+// violations falls back to the generic parser.
+func (p *SquawkParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var violations []squawkViolation
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var batch []squawkViolation
+		if err := dec.Decode(&batch); err != nil {
+			return err
+		}
+		violations = append(violations, batch...)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing squawk JSON output: %w", err)
+	}
+
+	failed := exitCode != 0
+	errs := make([]StructuredError, 0, len(violations))
+	for _, v := range violations {
+		e := StructuredError{
+			File:     v.File,
+			Line:     v.Line,
+			Column:   v.Column,
+			Severity: lintLevelSeverity(strings.ToLower(v.Level)),
+			Rule:     v.RuleName,
+			Tool:     "squawk",
+		}
+		if e.File == "" {
+			e.File = v.Filename
+		}
+		var notes []string
+		for _, m := range v.Messages {
+			if note := m["Note"]; note != "" {
+				notes = append(notes, note)
+			}
+			if help := m["Help"]; help != "" && e.Hint == "" {
+				e.Hint = help
+			}
+		}
+		e.Message = strings.Join(notes, " ")
+		if e.Message == "" {
+			e.Message = v.RuleName
+		}
+		if e.Severity == "error" {
+			failed = true
+		}
+		errs = append(errs, e)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
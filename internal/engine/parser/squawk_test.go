@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+const squawkOutput = `[
+  {"file":"migrations/0002_add_email.up.sql","line":1,"column":0,"level":"Warning","messages":[{"Note":"Adding a NOT NULL field requires exclusive locks and table rewrites."},{"Help":"Make the field nullable."}],"rule_name":"adding-not-nullable-field"},
+  {"file":"migrations/0002_add_email.up.sql","line":3,"column":0,"level":"Error","messages":[{"Note":"Creating an index blocks writes."},{"Help":"Create the index CONCURRENTLY."}],"rule_name":"require-concurrent-index-creation"}
+]`
+
+func TestSquawkParser_Violations(t *testing.T) {
+	res, err := NewSquawkParser().Parse(context.Background(), []byte(squawkOutput), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	w := res.Errors[0]
+	if w.File != "migrations/0002_add_email.up.sql" || w.Line != 1 || w.Severity != "warning" || w.Rule != "adding-not-nullable-field" || w.Tool != "squawk" {
+		t.Errorf("unexpected warning: %+v", w)
+	}
+	if w.Message != "Adding a NOT NULL field requires exclusive locks and table rewrites." || w.Hint != "Make the field nullable." {
+		t.Errorf("unexpected message or hint: %+v", w)
+	}
+	if res.Errors[1].Severity != "error" {
+		t.Errorf("expected an error, got %+v", res.Errors[1])
+	}
+}
+
+func TestSquawkParser_WarningsOnly(t *testing.T) {
+	out := `[{"filename":"migrations/1.sql","line":2,"column":0,"level":"Warning","messages":[],"rule_name":"prefer-text-field"}]`
+	res, err := NewSquawkParser().Parse(context.Background(), []byte(out), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 1 || res.Errors[0].File != "migrations/1.sql" || res.Errors[0].Message != "prefer-text-field" {
+		t.Errorf("expected pass with one warning, got %+v", res)
+	}
+}
+
+func TestSquawkParser_EmptyAndInvalid(t *testing.T) {
+	res, err := NewSquawkParser().Parse(context.Background(), []byte("[]"), nil, 0)
+	if err != nil || !res.Passed {
+		t.Errorf("expected clean pass, got %+v, %v", res, err)
+	}
+	if _, err := NewSquawkParser().Parse(context.Background(), []byte("not json"), nil, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// TFLintParser parses `tflint --format json` output: rule issues plus the
+// errors TFLint hit while loading the configuration.
+type TFLintParser struct{}
+
+// NewTFLintParser creates a new TFLintParser.
+func NewTFLintParser() *TFLintParser {
+	return &TFLintParser{}
+}
+
+// tflintOutput is the document printed by `tflint --format json`.
+type tflintOutput struct {
+	Issues []struct {
+		Rule struct {
+			Name     string `json:"name"`
+			Severity string `json:"severity"`
+			Link     string `json:"link"`
+		} `json:"rule"`
+		Message string       `json:"message"`
+		Range   *tflintRange `json:"range"`
+	} `json:"issues"`
+	Errors []struct {
+		Summary string       `json:"summary"`
+		Message string       `json:"message"`
+		Range   *tflintRange `json:"range"`
+	} `json:"errors"`
+}
+
+// tflintRange is the source range of a TFLint issue or error.
+type tflintRange struct {
+	Filename string `json:"filename"`
+	Start    struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"start"`
+}
+
+// Parse implements the Parser interface for TFLint JSON output.
+// The gate fails on a non-zero exit code (TFLint's --minimum-failure-severity
+// decides), an error-severity issue, or a configuration error. A failure
+// without findings falls back to the generic parser.
+func (p *TFLintParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var outputs []tflintOutput
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		var out tflintOutput
+		if err := dec.Decode(&out); err != nil {
+			return err
+		}
+		outputs = append(outputs, out)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("parsing tflint JSON output: %w", err)
+	}
+
+	failed := exitCode != 0
+	var errs []StructuredError
+	for _, out := range outputs {
+		for _, issue := range out.Issues {
+			e := StructuredError{
+				Severity: lintLevelSeverity(issue.Rule.Severity),
+				Rule:     issue.Rule.Name,
+				Message:  issue.Message,
+				Tool:     "tflint",
+			}
+			if issue.Rule.Link != "" {
+				e.Hint = "See " + issue.Rule.Link
+			}
+			issue.Range.locate(&e)
+			if e.Severity == "error" {
+				failed = true
+			}
+			errs = append(errs, e)
+		}
+		for _, te := range out.Errors {
+			e := StructuredError{Severity: "error", Message: te.Message, Tool: "tflint"}
+			if te.Summary != "" && te.Summary != te.Message {
+				e.Message = te.Summary + ": " + te.Message
+			}
+			te.Range.locate(&e)
+			failed = true
+			errs = append(errs, e)
+		}
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// locate sets the file, line, and column of e from the range, if there is one.
+func (r *tflintRange) locate(e *StructuredError) {
+	if r == nil {
+		return
+	}
+	e.File = r.Filename
+	e.Line = r.Start.Line
+	e.Column = r.Start.Column
+}
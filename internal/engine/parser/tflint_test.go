@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+const tflintSample = `{
+  "issues": [
+    {
+      "rule": {"name": "terraform_unused_declarations", "severity": "warning", "link": "https://github.com/terraform-linters/tflint-ruleset-terraform/blob/main/docs/rules/terraform_unused_declarations.md"},
+      "message": "variable \"region\" is declared but not used",
+      "range": {"filename": "modules/vpc/variables.tf", "start": {"line": 3, "column": 1}, "end": {"line": 3, "column": 19}},
+      "callers": []
+    },
+    {
+      "rule": {"name": "aws_instance_invalid_type", "severity": "error", "link": ""},
+      "message": "\"t1.2xlarge\" is an invalid value as instance_type",
+      "range": {"filename": "main.tf", "start": {"line": 12, "column": 19}, "end": {"line": 12, "column": 31}},
+      "callers": []
+    }
+  ],
+  "errors": []
+}`
+
+func TestTFLintParser_Issues(t *testing.T) {
+	res, err := NewTFLintParser().Parse(context.Background(), []byte(tflintSample), nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(res.Errors))
+	}
+	w := res.Errors[0]
+	if w.File != "modules/vpc/variables.tf" || w.Line != 3 || w.Column != 1 || w.Severity != "warning" || w.Rule != "terraform_unused_declarations" || w.Tool != "tflint" {
+		t.Errorf("unexpected warning: %+v", w)
+	}
+	if w.Hint == "" {
+		t.Error("expected the rule link as hint")
+	}
+	if e := res.Errors[1]; e.Severity != "error" || e.Hint != "" {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestTFLintParser_WarningsOnly(t *testing.T) {
+	out := `{"issues":[{"rule":{"name":"terraform_naming_convention","severity":"notice"},"message":"use snake_case","range":{"filename":"main.tf","start":{"line":1,"column":1}}}],"errors":[]}`
+	res, err := NewTFLintParser().Parse(context.Background(), []byte(out), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 1 || res.Errors[0].Severity != "info" {
+		t.Errorf("expected pass with one info finding, got %+v", res)
+	}
+}
+
+func TestTFLintParser_ConfigErrors(t *testing.T) {
+	out := `{"issues":[],"errors":[{"summary":"Unsupported argument","message":"An argument named \"foo\" is not expected here.","severity":"error","range":{"filename":"main.tf","start":{"line":4,"column":3}}}]}`
+	res, err := NewTFLintParser().Parse(context.Background(), []byte(out), nil, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) != 1 {
+		t.Fatalf("expected one failing error, got %+v", res)
+	}
+	e := res.Errors[0]
+	if e.File != "main.tf" || e.Line != 4 || e.Message != `Unsupported argument: An argument named "foo" is not expected here.` {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestTFLintParser_EmptyAndInvalid(t *testing.T) {
+	res, err := NewTFLintParser().Parse(context.Background(), []byte(`{"issues":[],"errors":[]}`), nil, 0)
+	if err != nil || !res.Passed {
+		t.Errorf("expected clean pass, got %+v, %v", res, err)
+	}
+	if _, err := NewTFLintParser().Parse(context.Background(), []byte("not json"), nil, 1); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
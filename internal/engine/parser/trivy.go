@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// TrivySeverities lists Trivy severities from lowest to highest. They are the
+// valid values of parser_config.fail_on.
+var TrivySeverities = []string{"UNKNOWN", "LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// DefaultTrivyFailOn is the threshold used when a gate does not set fail_on.
+const DefaultTrivyFailOn = "HIGH"
+
+// TrivyParser parses `trivy fs --format json` reports. Findings at or above the
+// fail_on severity are errors and fail the gate; the rest are reported as
+// warnings (MEDIUM and above) or info (LOW and UNKNOWN).
+type TrivyParser struct {
+	failOn int
+}
+
+// NewTrivyParser creates a TrivyParser failing on findings of severity failOn or
+// higher (case-insensitive). An empty failOn uses DefaultTrivyFailOn.
+func NewTrivyParser(failOn string) (*TrivyParser, error) {
+	if failOn == "" {
+		failOn = DefaultTrivyFailOn
+	}
+	rank := trivyRank(failOn)
+	if rank < 0 {
+		return nil, fmt.Errorf("unknown trivy severity %q (valid: %s)", failOn, strings.Join(TrivySeverities, ", "))
+	}
+	return &TrivyParser{failOn: rank}, nil
+}
+
+// trivyReport is the subset of the Trivy JSON report used by the parser.
+type trivyReport struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+		} `json:"Vulnerabilities"`
+		Misconfigurations []struct {
+			ID            string `json:"ID"`
+			Title         string `json:"Title"`
+			Message       string `json:"Message"`
+			Resolution    string `json:"Resolution"`
+			Severity      string `json:"Severity"`
+			CauseMetadata struct {
+				StartLine int `json:"StartLine"`
+			} `json:"CauseMetadata"`
+		} `json:"Misconfigurations"`
+	} `json:"Results"`
+}
+
+// Parse implements the Parser interface for Trivy JSON output.
+// A non-zero exit code without a report falls back to the generic parser.
+func (p *TrivyParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var report trivyReport
+	found := false
+	if err := decodeJSONStream(stdout, func(dec *json.Decoder) error {
+		found = true
+		return dec.Decode(&report)
+	}); err != nil {
+		return nil, fmt.Errorf("parsing trivy JSON output: %w", err)
+	}
+	if !found && exitCode != 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	var errs []StructuredError
+	for _, r := range report.Results {
+		for _, v := range r.Vulnerabilities {
+			e := StructuredError{
+				File:     r.Target,
+				Severity: p.severity(v.Severity),
+				Rule:     v.VulnerabilityID,
+				Message:  fmt.Sprintf("%s %s: %s (%s)", v.PkgName, v.InstalledVersion, v.Title, v.Severity),
+				Tool:     "trivy",
+			}
+			if v.FixedVersion != "" {
+				e.Hint = fmt.Sprintf("Upgrade %s to %s", v.PkgName, v.FixedVersion)
+			}
+			errs = append(errs, e)
+		}
+		for _, m := range r.Misconfigurations {
+			errs = append(errs, StructuredError{
+				File:     r.Target,
+				Line:     m.CauseMetadata.StartLine,
+				Severity: p.severity(m.Severity),
+				Rule:     m.ID,
+				Message:  fmt.Sprintf("%s: %s (%s)", m.Title, m.Message, m.Severity),
+				Hint:     m.Resolution,
+				Tool:     "trivy",
+			})
+		}
+	}
+
+	passed := true
+	for _, e := range errs {
+		if e.Severity == "error" {
+			passed = false
+			break
+		}
+	}
+	return &ParseResult{Passed: passed, Errors: errs}, nil
+}
+
+// severity maps a Trivy severity to a StructuredError severity using the threshold.
+func (p *TrivyParser) severity(s string) string {
+	rank := trivyRank(s)
+	switch {
+	case rank >= p.failOn:
+		return "error"
+	case rank >= trivyRank("MEDIUM"):
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// trivyRank returns the position of s in TrivySeverities, or -1 if unknown.
+func trivyRank(s string) int {
+	for i, sev := range TrivySeverities {
+		if strings.EqualFold(s, sev) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestTrivyParser_Findings(t *testing.T) {
+	data, err := os.ReadFile("testdata/trivy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewTrivyParser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := p.Parse(context.Background(), data, nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed on CRITICAL vulnerability with default fail_on HIGH")
+	}
+	if len(res.Errors) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(res.Errors))
+	}
+
+	medium, critical, misconfig := res.Errors[0], res.Errors[1], res.Errors[2]
+	if medium.Severity != "warning" || medium.File != "go.mod" || medium.Rule != "CVE-2023-45288" || medium.Hint != "Upgrade golang.org/x/net to 0.23.0" {
+		t.Errorf("unexpected medium finding: %+v", medium)
+	}
+	if critical.Severity != "error" || critical.Tool != "trivy" {
+		t.Errorf("unexpected critical finding: %+v", critical)
+	}
+	if misconfig.File != "Dockerfile" || misconfig.Line != 1 || misconfig.Rule != "DS002" || misconfig.Severity != "error" {
+		t.Errorf("unexpected misconfiguration: %+v", misconfig)
+	}
+}
+
+func TestTrivyParser_Threshold(t *testing.T) {
+	data, err := os.ReadFile("testdata/trivy.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		failOn string
+		passed bool
+		errors int
+	}{
+		{"critical", false, 1},
+		{"MEDIUM", false, 3},
+		{"LOW", false, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failOn, func(t *testing.T) {
+			p, err := NewTrivyParser(tt.failOn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := p.Parse(context.Background(), data, nil, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := 0
+			for _, e := range res.Errors {
+				if e.Severity == "error" {
+					n++
+				}
+			}
+			if res.Passed != tt.passed || n != tt.errors {
+				t.Errorf("fail_on %s: expected passed=%v with %d errors, got passed=%v with %d", tt.failOn, tt.passed, tt.errors, res.Passed, n)
+			}
+		})
+	}
+}
+
+func TestTrivyParser_Severity(t *testing.T) {
+	p, err := NewTrivyParser("HIGH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sev, want := range map[string]string{
+		"CRITICAL": "error",
+		"HIGH":     "error",
+		"MEDIUM":   "warning",
+		"LOW":      "info",
+		"UNKNOWN":  "info",
+	} {
+		if got := p.severity(sev); got != want {
+			t.Errorf("severity(%s) = %q, want %q", sev, got, want)
+		}
+	}
+}
+
+func TestTrivyParser_Clean(t *testing.T) {
+	p, _ := NewTrivyParser("HIGH")
+	res, err := p.Parse(context.Background(), []byte(`{"SchemaVersion":2,"Results":[{"Target":"go.mod"}]}`), nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed || len(res.Errors) != 0 {
+		t.Errorf("expected clean pass, got %+v", res)
+	}
+}
+
+func TestTrivyParser_FailureWithoutReport(t *testing.T) {
+	p, _ := NewTrivyParser("HIGH")
+	res, err := p.Parse(context.Background(), nil, []byte("FATAL: scan error"), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failure to fall back to generic parser")
+	}
+}
+
+func TestTrivyParser_InvalidInput(t *testing.T) {
+	if _, err := NewTrivyParser("SEVERE"); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+	p, _ := NewTrivyParser("")
+	if _, err := p.Parse(context.Background(), []byte(`{"Results": [`), nil, 0); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
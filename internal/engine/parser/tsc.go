@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tscDiagnostic matches `tsc --pretty false` lines such as
+//
+//	src/app.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'.
+//	error TS5023: Unknown compiler option 'foo'.
+var tscDiagnostic = regexp.MustCompile(`^(?:(.+)\((\d+),(\d+)\): )?(error|warning|message) (TS\d+): (.*)$`)
+
+// TscParser parses TypeScript compiler diagnostics from `tsc --pretty false`.
+type TscParser struct{}
+
+// NewTscParser creates a new TscParser.
+func NewTscParser() *TscParser {
+	return &TscParser{}
+}
+
+// Parse implements the Parser interface for tsc output.
+// Indented continuation lines are appended to the preceding diagnostic's message.
+// The gate fails on a non-zero exit code or any error diagnostic; a failure
+// without diagnostics falls back to the generic parser.
+func (p *TscParser) Parse(ctx context.Context, stdout, stderr []byte, exitCode int) (*ParseResult, error) {
+	var errs []StructuredError
+	failed := exitCode != 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if m := tscDiagnostic.FindStringSubmatch(line); m != nil {
+			e := StructuredError{
+				File:     m[1],
+				Severity: tscSeverity(m[4]),
+				Rule:     m[5],
+				Message:  m[6],
+				Tool:     "tsc",
+			}
+			e.Line, _ = strconv.Atoi(m[2])
+			e.Column, _ = strconv.Atoi(m[3])
+			if e.Severity == "error" {
+				failed = true
+			}
+			errs = append(errs, e)
+			continue
+		}
+
+		// Elaborations ("  Type 'x' is missing ...") belong to the previous diagnostic.
+		if len(errs) > 0 && strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" {
+			last := &errs[len(errs)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scanning tsc output: %w", err)
+	}
+
+	if failed && len(errs) == 0 {
+		return NewGenericParser().Parse(ctx, stdout, stderr, exitCode)
+	}
+
+	return &ParseResult{Passed: !failed, Errors: errs}, nil
+}
+
+// tscSeverity maps tsc diagnostic categories to StructuredError severities.
+func tscSeverity(category string) string {
+	switch category {
+	case "error":
+		return "error"
+	case "warning":
+		return "warning"
+	default:
+		return "info"
+	}
+}
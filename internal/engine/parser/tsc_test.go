@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTscParser_Diagnostics(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "tsc.txt"))
+	if err != nil {
+		t.Fatalf("reading fixture: %v", err)
+	}
+
+	res, err := NewTscParser().Parse(context.Background(), data, nil, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed {
+		t.Error("expected failed")
+	}
+	if len(res.Errors) != 3 {
+		t.Fatalf("expected 3 errors, got %d", len(res.Errors))
+	}
+
+	first := res.Errors[0]
+	if first.File != "src/app.ts" || first.Line != 12 || first.Column != 5 || first.Rule != "TS2322" || first.Tool != "tsc" {
+		t.Errorf("unexpected first error: %+v", first)
+	}
+	if !strings.Contains(res.Errors[1].Message, "Property 'b' is missing") {
+		t.Errorf("expected continuation line in message, got %q", res.Errors[1].Message)
+	}
+	if global := res.Errors[2]; global.File != "" || global.Rule != "TS5023" {
+		t.Errorf("unexpected global diagnostic: %+v", global)
+	}
+}
+
+func TestTscParser_Clean(t *testing.T) {
+	res, err := NewTscParser().Parse(context.Background(), nil, nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Passed {
+		t.Error("expected pass")
+	}
+}
+
+func TestTscParser_FailureWithoutDiagnostics(t *testing.T) {
+	res, err := NewTscParser().Parse(context.Background(), []byte("sh: tsc: not found\n"), nil, 127)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Passed || len(res.Errors) == 0 {
+		t.Errorf("expected fail-closed result, got %+v", res)
+	}
+}
@@ -0,0 +1,136 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// ContainerInfo describes a container of the pool.
+type ContainerInfo struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Image    string    `json:"image"`
+	Project  string    `json:"project"`
+	Writable bool      `json:"writable"`
+	State    string    `json:"state"`
+	Status   string    `json:"status"`
+	Created  time.Time `json:"created"`
+	// LastUsed is zero if the container has no valid last-used label.
+	LastUsed time.Time `json:"last_used,omitzero"`
+	// SizeRw is the size of the files the container wrote; SizeRootFs
+	// includes its image.
+	SizeRw     int64    `json:"size_rw"`
+	SizeRootFs int64    `json:"size_root_fs"`
+	PoolKey    string   `json:"pool_key"`
+	Mounts     []string `json:"mounts"`
+}
+
+// List returns the containers of the pool, running or not, oldest first.
+func (p *Pool) List(ctx context.Context) ([]ContainerInfo, error) {
+	opts := container.ListOptions{
+		All:  true,
+		Size: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=true", labelManaged)),
+		),
+	}
+	containers, err := p.runtime.ContainerList(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+
+	infos := make([]ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		info := containerInfo(c)
+		info.LastUsed, _ = p.lastUsed(c)
+		infos = append(infos, info)
+	}
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].Created.Before(infos[j].Created) })
+	return infos, nil
+}
+
+// Find returns the container of the pool with the given ID, unique ID prefix,
+// or name.
+func (p *Pool) Find(ctx context.Context, ref string) (ContainerInfo, error) {
+	infos, err := p.List(ctx)
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	var matches []ContainerInfo
+	for _, info := range infos {
+		if info.ID == ref || info.Name == strings.TrimPrefix(ref, "/") {
+			return info, nil
+		}
+		if strings.HasPrefix(info.ID, ref) {
+			matches = append(matches, info)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return ContainerInfo{}, fmt.Errorf("no gatekeeper container matches %q", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return ContainerInfo{}, fmt.Errorf("%q matches %d gatekeeper containers; use a longer ID", ref, len(matches))
+	}
+}
+
+// Remove force-removes the container of the pool with the given ID, unique
+// ID prefix, or name. Containers not managed by gatekeeper are never removed.
+func (p *Pool) Remove(ctx context.Context, ref string) (ContainerInfo, error) {
+	info, err := p.Find(ctx, ref)
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	if err := p.runtime.ContainerRemove(ctx, info.ID, container.RemoveOptions{Force: true}); err != nil {
+		return ContainerInfo{}, fmt.Errorf("removing container %s: %w", ShortID(info.ID), err)
+	}
+	p.untouch(info.ID)
+	logger.FromContext(ctx).Info("container removed", "container_id", info.ID)
+	return info, nil
+}
+
+// containerInfo describes c from its labels, except for its last use.
+func containerInfo(c container.Summary) ContainerInfo {
+	info := ContainerInfo{
+		ID:         c.ID,
+		Image:      c.Labels[labelImage],
+		Project:    c.Labels[labelProject],
+		Writable:   c.Labels[labelWritable] == "true",
+		State:      string(c.State),
+		Status:     c.Status,
+		Created:    time.Unix(c.Created, 0),
+		SizeRw:     c.SizeRw,
+		SizeRootFs: c.SizeRootFs,
+		PoolKey:    c.Labels[labelPoolKey],
+	}
+	if len(c.Names) > 0 {
+		info.Name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	if info.Image == "" {
+		info.Image = c.Image
+	}
+	for _, m := range c.Mounts {
+		mode := "ro"
+		if m.RW {
+			mode = "rw"
+		}
+		info.Mounts = append(info.Mounts, fmt.Sprintf("%s:%s (%s, %s)", m.Source, m.Destination, m.Type, mode))
+	}
+	return info
+}
+
+// ShortID returns the 12-character form of a container ID, as docker ps shows it.
+func ShortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,108 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func managedContainers() []container.Summary {
+	return []container.Summary{
+		{
+			ID:      "bbbb2222cccc3333",
+			Names:   []string{"/eager_lint"},
+			Created: 200,
+			State:   container.StateRunning,
+			SizeRw:  2048,
+			Labels: map[string]string{
+				labelManaged:  "true",
+				labelImage:    "golang:1.25",
+				labelProject:  "/proj",
+				labelWritable: "true",
+				labelLastUsed: "2026-01-02T03:04:05Z",
+			},
+			Mounts: []container.MountPoint{{Type: "bind", Source: "/proj", Destination: "/workspace", RW: true}},
+		},
+		{
+			ID:      "aaaa1111bbbb2222",
+			Names:   []string{"/quiet_vet"},
+			Created: 100,
+			State:   container.StateExited,
+			Image:   "node:22",
+			Labels:  map[string]string{labelManaged: "true", labelLastUsed: "invalid"},
+		},
+	}
+}
+
+func TestPool_List(t *testing.T) {
+	p := NewPool(&MockRuntime{ListResp: managedContainers()})
+	infos, err := p.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(infos) != 2 || infos[0].ID != "aaaa1111bbbb2222" {
+		t.Fatalf("expected the oldest container first, got %+v", infos)
+	}
+	got := infos[1]
+	if got.Name != "eager_lint" || got.Image != "golang:1.25" || got.Project != "/proj" || !got.Writable || got.SizeRw != 2048 {
+		t.Errorf("unexpected info %+v", got)
+	}
+	if !got.LastUsed.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("LastUsed = %v", got.LastUsed)
+	}
+	if len(got.Mounts) != 1 || got.Mounts[0] != "/proj:/workspace (bind, rw)" {
+		t.Errorf("Mounts = %q", got.Mounts)
+	}
+	if infos[0].Image != "node:22" || !infos[0].LastUsed.IsZero() {
+		t.Errorf("expected the image fallback and no last-used time, got %+v", infos[0])
+	}
+}
+
+func TestPool_Find(t *testing.T) {
+	p := NewPool(&MockRuntime{ListResp: managedContainers()})
+	tests := []struct {
+		ref, wantID, wantErr string
+	}{
+		{ref: "aaaa", wantID: "aaaa1111bbbb2222"},
+		{ref: "eager_lint", wantID: "bbbb2222cccc3333"},
+		{ref: "bbbb2222cccc3333", wantID: "bbbb2222cccc3333"},
+		{ref: "ffff", wantErr: "no gatekeeper container"},
+	}
+	for _, tc := range tests {
+		info, err := p.Find(context.Background(), tc.ref)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Find(%q): expected error %q, got %v", tc.ref, tc.wantErr, err)
+			}
+			continue
+		}
+		if err != nil || info.ID != tc.wantID {
+			t.Errorf("Find(%q) = %s, %v; want %s", tc.ref, info.ID, err, tc.wantID)
+		}
+	}
+
+	// A prefix of several IDs is ambiguous.
+	ambiguous := managedContainers()
+	ambiguous[1].ID = "bbbb9999"
+	p = NewPool(&MockRuntime{ListResp: ambiguous})
+	if _, err := p.Find(context.Background(), "bbbb"); err == nil || !strings.Contains(err.Error(), "matches 2") {
+		t.Errorf("expected an ambiguous prefix error, got %v", err)
+	}
+}
+
+func TestPool_Remove(t *testing.T) {
+	p := NewPool(&MockRuntime{ListResp: managedContainers()})
+	info, err := p.Remove(context.Background(), "eager_lint")
+	if err != nil || info.ID != "bbbb2222cccc3333" {
+		t.Fatalf("Remove = %+v, %v", info, err)
+	}
+
+	p = NewPool(&MockRuntime{ListResp: managedContainers(), RemoveErr: errors.New("in use")})
+	if _, err := p.Remove(context.Background(), "aaaa"); err == nil || !strings.Contains(err.Error(), "aaaa1111bbbb") {
+		t.Errorf("expected a removal error naming the container, got %v", err)
+	}
+}
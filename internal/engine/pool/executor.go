@@ -3,12 +3,18 @@ package pool
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ExecResult holds the result of a container execution.
@@ -19,6 +25,22 @@ type ExecResult struct {
 	Duration time.Duration
 }
 
+// ErrKilled is returned by Executor.Run when the command ran past its timeout
+// and was killed. It wraps context.DeadlineExceeded.
+var ErrKilled = fmt.Errorf("killed after timeout: %w", context.DeadlineExceeded)
+
+const (
+	// execMarkerEnv is set on every exec to a unique value, so the processes
+	// of a timed-out command (and their children, which inherit it) can be
+	// found and killed.
+	execMarkerEnv = "GATEKEEPER_EXEC"
+	// killPollInterval is how often a killed command is checked.
+	killPollInterval = 100 * time.Millisecond
+)
+
+// killGrace bounds how long a killed command may take to exit. A variable for tests.
+var killGrace = 5 * time.Second
+
 // Executor runs commands inside containers.
 type Executor struct {
 	runtime ContainerRuntime
@@ -35,6 +57,23 @@ func NewExecutor(runtime ContainerRuntime) *Executor {
 // Command is wrapped in sh -c to support shell features.
 // Timeout is enforced via the context.
 func (e *Executor) Run(ctx context.Context, containerID, command string, timeout time.Duration) (*ExecResult, error) {
+	return e.RunWithStdin(ctx, containerID, command, nil, timeout)
+}
+
+// RunWithStdin is Run with stdin attached to the command and streamed into
+// it; the command sees end of input after the last byte. A nil stdin attaches
+// none, as with Run.
+func (e *Executor) RunWithStdin(ctx context.Context, containerID, command string, stdin []byte, timeout time.Duration) (*ExecResult, error) {
+	ctx, span := telemetry.Start(ctx, "executor.run", attribute.String("container.id", containerID))
+	result, err := e.run(ctx, containerID, command, stdin, timeout)
+	if result != nil {
+		span.SetAttributes(attribute.Int("process.exit_code", result.ExitCode))
+	}
+	telemetry.End(span, err)
+	return result, err
+}
+
+func (e *Executor) run(ctx context.Context, containerID, command string, stdin []byte, timeout time.Duration) (*ExecResult, error) {
 	log := logger.FromContext(ctx)
 	log.Info("Executor.Run started", "container_id", containerID, "command", command, "timeout", timeout)
 	start := time.Now()
@@ -42,8 +81,14 @@ func (e *Executor) Run(ctx context.Context, containerID, command string, timeout
 	// 1. Create Exec Config
 	// We wrap in sh -c to support pipes, redirects, etc.
 	// Tty must be false for stdcopy to work correctly (to separate stdout/stderr).
+	marker, err := execMarker()
+	if err != nil {
+		return nil, err
+	}
 	execConfig := container.ExecOptions{
 		Cmd:          []string{"sh", "-c", command},
+		Env:          []string{marker},
+		AttachStdin:  stdin != nil,
 		AttachStdout: true,
 		AttachStderr: true,
 		Tty:          false,
@@ -61,10 +106,30 @@ func (e *Executor) Run(ctx context.Context, containerID, command string, timeout
 	}
 	resp, err := e.runtime.ContainerExecAttach(ctx, execID, attachConfig)
 	if err != nil {
+		if ctx.Err() != nil {
+			// The attach may have started the command before it was cancelled.
+			e.stop(ctx, containerID, execID, marker)
+		}
 		return nil, fmt.Errorf("attaching to exec: %w", err)
 	}
 	defer resp.Close()
 
+	// Stream the input while the output is read, so a command that writes
+	// before it has read everything does not block; closing the write side
+	// ends the input.
+	if stdin != nil {
+		go func() {
+			// A command that exits without reading all of it fails the write.
+			if _, err := resp.Conn.Write(stdin); err != nil {
+				log.Debug("command did not read all its input", "container_id", containerID, "error", err)
+				return
+			}
+			if err := resp.CloseWrite(); err != nil {
+				log.Debug("failed to close command input", "container_id", containerID, "error", err)
+			}
+		}()
+	}
+
 	// 3. Capture Output
 	// Use stdcopy to demultiplex stdout and stderr.
 	var stdoutBuf, stderrBuf bytes.Buffer
@@ -80,17 +145,21 @@ func (e *Executor) Run(ctx context.Context, containerID, command string, timeout
 	// Actually, since we attached, reading from resp.Reader until EOF should be sufficient
 	// IF the process exits. But if it hangs, we need to enforce timeout.
 
+	// Leaving the exec running would keep burning CPU in the warm container,
+	// so a timed-out or cancelled command is killed.
 	select {
 	case err := <-outputDone:
 		if err != nil {
 			return nil, fmt.Errorf("reading output: %w", err)
 		}
 	case <-ctx.Done():
+		e.stop(ctx, containerID, execID, marker)
 		return nil, ctx.Err()
 	case <-time.After(timeout):
-		// Context timeout usually handles this if passed to Create/Attach,
-		// but explicit timeout arg is good too.
-		return nil, context.DeadlineExceeded
+		if err := e.kill(ctx, containerID, execID, marker); err != nil {
+			return nil, fmt.Errorf("%w (%w)", context.DeadlineExceeded, err)
+		}
+		return nil, ErrKilled
 	}
 
 	// 5. Inspect for Exit Code
@@ -108,3 +177,76 @@ func (e *Executor) Run(ctx context.Context, containerID, command string, timeout
 	log.Info("Executor.Run completed", "container_id", containerID, "exit_code", result.ExitCode, "duration", result.Duration)
 	return result, nil
 }
+
+// stop kills the command of a cancelled run, e.g. a gate cancelled by
+// fail-fast, so it does not keep running in the warm container.
+func (e *Executor) stop(ctx context.Context, containerID, execID, marker string) {
+	if err := e.kill(context.WithoutCancel(ctx), containerID, execID, marker); err != nil {
+		logger.FromContext(ctx).Warn("failed to kill cancelled command", "container_id", containerID, "exec_id", execID, "error", err)
+	}
+}
+
+// kill kills the processes of the exec execID, found by their marker
+// environment variable, and waits up to killGrace for the exec to stop running.
+func (e *Executor) kill(ctx context.Context, containerID, execID, marker string) error {
+	log := logger.FromContext(ctx)
+	log.Warn("killing command", "container_id", containerID, "exec_id", execID)
+	ctx, cancel := context.WithTimeout(ctx, killGrace)
+	defer cancel()
+
+	// The marker is in the environment of the command's processes but not of
+	// this one, so it does not kill itself.
+	script := fmt.Sprintf(`for p in /proc/[0-9]*; do grep -qs %s "$p/environ" && kill -KILL "${p#/proc/}"; done; true`, marker)
+	killResp, err := e.runtime.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          []string{"sh", "-c", script},
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return fmt.Errorf("creating kill exec: %w", err)
+	}
+	// Attaching starts the exec; its output ends when it exits.
+	resp, err := e.runtime.ContainerExecAttach(ctx, killResp.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return fmt.Errorf("attaching to kill exec: %w", err)
+	}
+	defer resp.Close()
+	killed := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(io.Discard, io.Discard, resp.Reader)
+		killed <- err
+	}()
+	select {
+	case err := <-killed:
+		if err != nil {
+			return fmt.Errorf("reading kill exec output: %w", err)
+		}
+	case <-ctx.Done():
+		return errors.New("kill exec did not finish")
+	}
+
+	for {
+		inspect, err := e.runtime.ContainerExecInspect(ctx, execID)
+		if err != nil {
+			return fmt.Errorf("inspecting killed exec: %w", err)
+		}
+		if !inspect.Running {
+			log.Info("command killed", "container_id", containerID, "exec_id", execID)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.New("command still running after kill")
+		case <-time.After(killPollInterval):
+		}
+	}
+}
+
+// execMarker returns a unique marker environment variable for an exec.
+func execMarker() (string, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating exec marker: %w", err)
+	}
+	return execMarkerEnv + "=" + hex.EncodeToString(nonce), nil
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"strings"
 	"testing"
@@ -66,6 +68,56 @@ func TestExecutorRun(t *testing.T) {
 	}
 }
 
+// stdinRuntime echoes the input of its exec back as stdout.
+type stdinRuntime struct {
+	MockRuntime
+	exec  container.ExecOptions
+	input int
+}
+
+func (s *stdinRuntime) ContainerExecCreate(_ context.Context, _ string, config container.ExecOptions) (container.ExecCreateResponse, error) {
+	s.exec = config
+	return container.ExecCreateResponse{ID: "exec-id"}, nil
+}
+
+func (s *stdinRuntime) ContainerExecAttach(_ context.Context, _ string, _ container.ExecAttachOptions) (types.HijackedResponse, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		in := make([]byte, s.input)
+		if _, err := io.ReadFull(server, in); err != nil {
+			return
+		}
+		_, _ = server.Write(mockStream(1, string(in)))
+	}()
+	return types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}, nil
+}
+
+func TestExecutorRunWithStdin(t *testing.T) {
+	input := "a.go\nb.go\n"
+	rt := &stdinRuntime{input: len(input)}
+
+	res, err := NewExecutor(rt).RunWithStdin(context.Background(), "id", "xargs gofmt -l", []byte(input), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rt.exec.AttachStdin {
+		t.Error("expected stdin to be attached")
+	}
+	if string(res.Stdout) != input {
+		t.Errorf("expected the input on stdout, got %q", res.Stdout)
+	}
+
+	// Run attaches no stdin.
+	rt = &stdinRuntime{}
+	if _, err := NewExecutor(rt).Run(context.Background(), "id", "gofmt -l .", time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rt.exec.AttachStdin {
+		t.Error("expected no stdin to be attached")
+	}
+}
+
 func TestExecutorRun_Timeout(t *testing.T) {
 	client, server := net.Pipe()
 	defer client.Close()
@@ -96,6 +148,71 @@ func TestExecutorRun_Timeout(t *testing.T) {
 	}
 }
 
+// killRuntime records the execs it creates.
+type killRuntime struct {
+	MockRuntime
+	execs []container.ExecOptions
+}
+
+func (k *killRuntime) ContainerExecCreate(_ context.Context, _ string, config container.ExecOptions) (container.ExecCreateResponse, error) {
+	k.execs = append(k.execs, config)
+	return container.ExecCreateResponse{ID: fmt.Sprintf("exec-%d", len(k.execs))}, nil
+}
+
+func (k *killRuntime) ContainerExecAttach(_ context.Context, execID string, _ container.ExecAttachOptions) (types.HijackedResponse, error) {
+	client, server := net.Pipe()
+	if execID != "exec-1" {
+		// The kill exec finishes at once; the command never does.
+		server.Close()
+	}
+	return types.HijackedResponse{Conn: client, Reader: bufio.NewReader(client)}, nil
+}
+
+func TestExecutorRun_TimeoutKills(t *testing.T) {
+	rt := &killRuntime{}
+	_, err := NewExecutor(rt).Run(context.Background(), "id", "sleep 60", 50*time.Millisecond)
+	if !errors.Is(err, ErrKilled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected ErrKilled, got %v", err)
+	}
+	if len(rt.execs) != 2 {
+		t.Fatalf("expected a kill exec, got %d execs", len(rt.execs))
+	}
+	marker := rt.execs[0].Env[0]
+	if !strings.HasPrefix(marker, execMarkerEnv+"=") {
+		t.Errorf("expected the command to carry a marker, got %q", marker)
+	}
+	if kill := strings.Join(rt.execs[1].Cmd, " "); !strings.Contains(kill, marker) || !strings.Contains(kill, "kill -KILL") {
+		t.Errorf("unexpected kill command %q", kill)
+	}
+
+	// A command that survives the kill is reported.
+	defer func(grace time.Duration) { killGrace = grace }(killGrace)
+	killGrace = 200 * time.Millisecond
+	rt = &killRuntime{MockRuntime: MockRuntime{ExecInspectResp: container.ExecInspect{Running: true}}}
+	_, err = NewExecutor(rt).Run(context.Background(), "id", "sleep 60", 50*time.Millisecond)
+	if errors.Is(err, ErrKilled) || !strings.Contains(fmt.Sprint(err), "still running after kill") {
+		t.Errorf("expected a failed kill, got %v", err)
+	}
+}
+
+func TestExecutorRun_CancelKills(t *testing.T) {
+	rt := &killRuntime{}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// A cancelled run, e.g. by fail-fast, stops the command too.
+	_, err := NewExecutor(rt).Run(ctx, "id", "go test ./...", time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if len(rt.execs) != 2 || !strings.Contains(strings.Join(rt.execs[1].Cmd, " "), rt.execs[0].Env[0]) {
+		t.Errorf("expected the command to be killed, got execs %+v", rt.execs)
+	}
+}
+
 func TestExecutorRun_CreateError(t *testing.T) {
 	mock := &MockRuntime{
 		ExecCreateErr: errors.New("create failed"),
@@ -191,6 +308,9 @@ func TestExecutorRun_ContextCancel(t *testing.T) {
 	go func() {
 		time.Sleep(10 * time.Millisecond)
 		cancel()
+		// Ends the output of the kill exec.
+		time.Sleep(10 * time.Millisecond)
+		server.Close()
 	}()
 
 	_, err := exec.Run(ctx, "id", "cmd", 1*time.Minute)
@@ -0,0 +1,174 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/promtext"
+)
+
+// creationLatencyBuckets are the upper bounds (in seconds) of the container
+// creation latency histogram. Creation includes the image pull, so the range is wide.
+var creationLatencyBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// Metrics collects container pool statistics.
+// It is safe for concurrent use and is exposed in Prometheus text format.
+type Metrics struct {
+	mu sync.Mutex
+
+	hits   int64
+	misses int64
+
+	creations       int64
+	creationErrors  int64
+	creationSum     float64
+	creationBuckets []int64
+
+	staleRemoved int64
+	allRemoved   int64
+
+	imageUsage map[string]int64
+}
+
+// NewMetrics creates an empty Metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		creationBuckets: make([]int64, len(creationLatencyBuckets)),
+		imageUsage:      make(map[string]int64),
+	}
+}
+
+// recordHit records a GetOrCreate call served by a warm container.
+func (m *Metrics) recordHit(img string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hits++
+	m.imageUsage[img]++
+}
+
+// recordMiss records a GetOrCreate call that had to create a container.
+// A nil err records a successful creation and its latency.
+func (m *Metrics) recordMiss(img string, dur time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses++
+	m.imageUsage[img]++
+	if err != nil {
+		m.creationErrors++
+		return
+	}
+
+	m.creations++
+	secs := dur.Seconds()
+	m.creationSum += secs
+	for i, le := range creationLatencyBuckets {
+		if secs <= le {
+			m.creationBuckets[i]++
+		}
+	}
+}
+
+// recordCleanup records containers removed by CleanupStale or CleanupAll.
+func (m *Metrics) recordCleanup(stale bool, removed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if stale {
+		m.staleRemoved += int64(removed)
+	} else {
+		m.allRemoved += int64(removed)
+	}
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format.
+// poolSize is the current number of running managed containers, or -1 if unknown.
+func (m *Metrics) WritePrometheus(w io.Writer, poolSize int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pw := promtext.NewWriter(w)
+
+	if poolSize >= 0 {
+		pw.Header("gatekeeper_pool_containers", "gauge", "Number of running gatekeeper-managed containers.")
+		pw.Printf("gatekeeper_pool_containers %d\n", poolSize)
+	}
+
+	pw.Header("gatekeeper_pool_requests_total", "counter", "GetOrCreate calls by outcome.")
+	pw.Printf("gatekeeper_pool_requests_total{result=\"hit\"} %d\n", m.hits)
+	pw.Printf("gatekeeper_pool_requests_total{result=\"miss\"} %d\n", m.misses)
+
+	pw.Header("gatekeeper_pool_creation_errors_total", "counter", "Container creations that failed.")
+	pw.Printf("gatekeeper_pool_creation_errors_total %d\n", m.creationErrors)
+
+	pw.Header("gatekeeper_pool_creation_seconds", "histogram", "Latency of container creation, including image pull.")
+	for i, le := range creationLatencyBuckets {
+		pw.Printf("gatekeeper_pool_creation_seconds_bucket{le=\"%g\"} %d\n", le, m.creationBuckets[i])
+	}
+	pw.Printf("gatekeeper_pool_creation_seconds_bucket{le=\"+Inf\"} %d\n", m.creations)
+	pw.Printf("gatekeeper_pool_creation_seconds_sum %g\n", m.creationSum)
+	pw.Printf("gatekeeper_pool_creation_seconds_count %d\n", m.creations)
+
+	pw.Header("gatekeeper_pool_cleanup_removed_total", "counter", "Containers removed by cleanup.")
+	pw.Printf("gatekeeper_pool_cleanup_removed_total{kind=\"stale\"} %d\n", m.staleRemoved)
+	pw.Printf("gatekeeper_pool_cleanup_removed_total{kind=\"all\"} %d\n", m.allRemoved)
+
+	pw.Header("gatekeeper_pool_image_requests_total", "counter", "GetOrCreate calls per image.")
+	images := make([]string, 0, len(m.imageUsage))
+	for img := range m.imageUsage {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+	for _, img := range images {
+		pw.Printf("gatekeeper_pool_image_requests_total{image=\"%s\"} %d\n", promtext.EscapeLabel(img), m.imageUsage[img])
+	}
+
+	return pw.Err()
+}
+
+// Metrics returns the pool's metrics collector.
+func (p *Pool) Metrics() *Metrics {
+	return p.metrics
+}
+
+// Size returns the number of running gatekeeper-managed containers.
+func (p *Pool) Size(ctx context.Context) (int, error) {
+	opts := container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", fmt.Sprintf("%s=true", labelManaged)),
+			filters.Arg("status", "running"),
+		),
+	}
+
+	containers, err := p.runtime.ContainerList(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	return len(containers), nil
+}
+
+// MetricsHandler returns an http.Handler serving the pool metrics in Prometheus format.
+// The server command mounts it at /metrics.
+func MetricsHandler(p *Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.FromContext(r.Context())
+
+		size, err := p.Size(r.Context())
+		if err != nil {
+			// Still serve counters; the gauge is omitted rather than reported as zero.
+			log.Warn("failed to determine pool size for metrics", "error", err)
+			size = -1
+		}
+
+		w.Header().Set("Content-Type", promtext.ContentType+"; charset=utf-8")
+		if err := p.metrics.WritePrometheus(w, size); err != nil {
+			log.Error("failed to write metrics", "error", err)
+		}
+	})
+}
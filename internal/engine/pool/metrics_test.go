@@ -0,0 +1,144 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestMetrics_HitsAndMisses(t *testing.T) {
+	mock := &MockRuntime{
+		ListResp:        []container.Summary{},
+		ImagePullReader: io.NopCloser(strings.NewReader("")),
+		CreateResp:      container.CreateResponse{ID: "new-id"},
+	}
+	p := NewPool(mock)
+
+	if _, err := p.GetOrCreate(context.Background(), "alpine", "/proj", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ListResp = []container.Summary{{ID: "new-id"}}
+	if _, err := p.GetOrCreate(context.Background(), "alpine", "/proj", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := p.Metrics().WritePrometheus(&buf, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"gatekeeper_pool_containers 1",
+		`gatekeeper_pool_requests_total{result="hit"} 1`,
+		`gatekeeper_pool_requests_total{result="miss"} 1`,
+		"gatekeeper_pool_creation_seconds_count 1",
+		`gatekeeper_pool_image_requests_total{image="alpine"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_CreationError(t *testing.T) {
+	m := NewMetrics()
+	m.recordMiss("alpine", time.Second, errors.New("pull failed"))
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf, -1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "gatekeeper_pool_creation_errors_total 1") {
+		t.Errorf("expected creation error to be counted, got:\n%s", out)
+	}
+	if !strings.Contains(out, "gatekeeper_pool_creation_seconds_count 0") {
+		t.Errorf("failed creations should not be observed in latency, got:\n%s", out)
+	}
+	if strings.Contains(out, "gatekeeper_pool_containers ") {
+		t.Errorf("pool size gauge should be omitted when unknown, got:\n%s", out)
+	}
+}
+
+func TestMetrics_LatencyBuckets(t *testing.T) {
+	m := NewMetrics()
+	m.recordMiss("alpine", 3*time.Second, nil)
+
+	var buf bytes.Buffer
+	if err := m.WritePrometheus(&buf, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, `gatekeeper_pool_creation_seconds_bucket{le="2.5"} 0`) {
+		t.Errorf("expected 2.5s bucket to be empty, got:\n%s", out)
+	}
+	if !strings.Contains(out, `gatekeeper_pool_creation_seconds_bucket{le="5"} 1`) {
+		t.Errorf("expected 5s bucket to contain observation, got:\n%s", out)
+	}
+}
+
+func TestMetrics_Cleanup(t *testing.T) {
+	mock := &MockRuntime{
+		ListResp: []container.Summary{{ID: "c1"}, {ID: "c2"}},
+	}
+	p := NewPool(mock)
+
+	if _, err := p.CleanupAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := p.Metrics().WritePrometheus(&buf, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `gatekeeper_pool_cleanup_removed_total{kind="all"} 2`) {
+		t.Errorf("expected cleanup count, got:\n%s", buf.String())
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	mock := &MockRuntime{
+		ListResp: []container.Summary{{ID: "c1"}, {ID: "c2"}},
+	}
+	p := NewPool(mock)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text/plain content type, got %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "gatekeeper_pool_containers 2") {
+		t.Errorf("expected pool size gauge, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestMetricsHandler_ListError(t *testing.T) {
+	mock := &MockRuntime{ListErr: errors.New("docker down")}
+	p := NewPool(mock)
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "gatekeeper_pool_containers ") {
+		t.Errorf("expected pool size gauge to be omitted, got:\n%s", rec.Body.String())
+	}
+}
@@ -17,6 +17,8 @@ type MockRuntime struct {
 	PingErr         error
 	ImagePullErr    error
 	ImagePullReader io.ReadCloser
+	// CreateConfig is the config of the last ContainerCreate call.
+	CreateConfig    *container.Config
 	CreateResp      container.CreateResponse
 	CreateErr       error
 	StartErr        error
@@ -41,7 +43,8 @@ func (m *MockRuntime) ImagePull(_ context.Context, _ string, _ image.PullOptions
 	return m.ImagePullReader, m.ImagePullErr
 }
 
-func (m *MockRuntime) ContainerCreate(_ context.Context, _ *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ *v1.Platform, _ string) (container.CreateResponse, error) {
+func (m *MockRuntime) ContainerCreate(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ *v1.Platform, _ string) (container.CreateResponse, error) {
+	m.CreateConfig = config
 	return m.CreateResp, m.CreateErr
 }
 
@@ -99,6 +102,8 @@ type MockExecutor struct {
 	Result      *ExecResult
 	Err         error
 	LastTimeout time.Duration
+	// LastStdin records the input given to RunWithStdin.
+	LastStdin []byte
 }
 
 func (m *MockExecutor) Run(_ context.Context, _, _ string, timeout time.Duration) (*ExecResult, error) {
@@ -108,3 +113,12 @@ func (m *MockExecutor) Run(_ context.Context, _, _ string, timeout time.Duration
 	}
 	return m.Result, nil
 }
+
+func (m *MockExecutor) RunWithStdin(_ context.Context, _, _ string, stdin []byte, timeout time.Duration) (*ExecResult, error) {
+	m.LastTimeout = timeout
+	m.LastStdin = stdin
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return m.Result, nil
+}
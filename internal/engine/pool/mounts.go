@@ -3,17 +3,27 @@ package pool
 import (
 	"fmt"
 	"os/user"
+	"path"
+	"runtime"
+	"strings"
 
 	"github.com/docker/docker/api/types/mount"
 )
 
+// DefaultDrivePrefix is where a Linux Docker daemon sees Windows drives, as in
+// WSL2 (C:\ is /mnt/c).
+const DefaultDrivePrefix = "/mnt"
+
+// goos is a variable to allow testing Windows code paths on any platform.
+var goos = runtime.GOOS
+
 // projectMount returns a mount configuration for the project root.
 // writable determines if the mount is read-write or read-only.
 // If writable is true, the container user is mapped to the host user to avoid permission issues.
-func projectMount(path string, writable bool) mount.Mount {
+func projectMount(source string, writable bool) mount.Mount {
 	m := mount.Mount{
 		Type:   mount.TypeBind,
-		Source: path,
+		Source: source,
 		Target: "/workspace",
 	}
 
@@ -30,11 +40,49 @@ func tmpMount() mount.Mount {
 	}
 }
 
+// mountSource translates a host path into a bind-mount source for a Linux
+// Docker daemon. Paths on other platforms than Windows are used as is. On
+// Windows, drive paths map under drivePrefix (C:\src\app with "/mnt" is
+// /mnt/c/src/app) and WSL paths (\\wsl$\Ubuntu\home\me or
+// \\wsl.localhost\Ubuntu\home\me) map to the path inside the distribution.
+func mountSource(hostPath, platform, drivePrefix string) string {
+	if platform != "windows" {
+		return hostPath
+	}
+	p := strings.ReplaceAll(hostPath, `\`, "/")
+
+	// WSL UNC path: //wsl$/<distro>/<path> or //wsl.localhost/<distro>/<path>.
+	if rest, ok := strings.CutPrefix(p, "//"); ok {
+		host, rest, _ := strings.Cut(rest, "/")
+		if strings.EqualFold(host, "wsl$") || strings.EqualFold(host, "wsl.localhost") {
+			_, inside, _ := strings.Cut(rest, "/")
+			return path.Clean("/" + inside)
+		}
+		return p
+	}
+
+	// Drive path: C:/<path>.
+	if len(p) >= 2 && p[1] == ':' && isDriveLetter(p[0]) {
+		drive := strings.ToLower(p[:1])
+		return path.Join("/", drivePrefix, drive, p[2:])
+	}
+	return p
+}
+
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
 // userCurrent is a variable to allow mocking in tests.
 var userCurrent = user.Current
 
-// getUserString returns the "uid:gid" string for the current user.
+// getUserString returns the "uid:gid" string for the current user. On Windows,
+// where users have SIDs rather than numeric IDs, it returns "" and the
+// container runs as its default user; Docker Desktop maps file ownership.
 func getUserString() (string, error) {
+	if goos == "windows" {
+		return "", nil
+	}
 	u, err := userCurrent()
 	if err != nil {
 		return "", err
@@ -43,3 +43,44 @@ func TestGetUserString_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestMountSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		platform string
+		prefix   string
+		want     string
+	}{
+		{name: "linux unchanged", path: "/home/me/app", platform: "linux", prefix: "/mnt", want: "/home/me/app"},
+		{name: "darwin unchanged", path: "/Users/me/app", platform: "darwin", prefix: "/mnt", want: "/Users/me/app"},
+		{name: "drive path", path: `C:\Users\me\app`, platform: "windows", prefix: "/mnt", want: "/mnt/c/Users/me/app"},
+		{name: "drive path with forward slashes", path: "D:/src/app", platform: "windows", prefix: "/mnt", want: "/mnt/d/src/app"},
+		{name: "docker desktop prefix", path: `C:\src\app`, platform: "windows", prefix: "/", want: "/c/src/app"},
+		{name: "drive root", path: `E:\`, platform: "windows", prefix: "/mnt", want: "/mnt/e"},
+		{name: "wsl$ path", path: `\\wsl$\Ubuntu\home\me\app`, platform: "windows", prefix: "/mnt", want: "/home/me/app"},
+		{name: "wsl.localhost path", path: `\\wsl.localhost\Ubuntu-22.04\home\me\app`, platform: "windows", prefix: "/mnt", want: "/home/me/app"},
+		{name: "other UNC path", path: `\\server\share\app`, platform: "windows", prefix: "/mnt", want: "//server/share/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mountSource(tt.path, tt.platform, tt.prefix); got != tt.want {
+				t.Errorf("mountSource(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserString_Windows(t *testing.T) {
+	original := goos
+	defer func() { goos = original }()
+	goos = "windows"
+
+	got, err := getUserString()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "" {
+		t.Errorf("expected no user mapping on Windows, got %q", got)
+	}
+}
@@ -2,10 +2,12 @@ package pool
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -27,28 +32,75 @@ const (
 
 // Pool manages a set of warm Docker containers.
 type Pool struct {
-	runtime ContainerRuntime
-	mu      sync.Mutex
+	runtime     ContainerRuntime
+	metrics     *Metrics
+	drivePrefix string
+	mu          sync.Mutex
+	// pulled holds the images pulled by PullImages that no container was
+	// created from yet, so creating one skips the pull. Guarded by mu.
+	pulled map[string]bool
+	// usageDir records the last use of containers; see SetUsageDir.
+	usageDir string
 }
 
 // NewPool creates a new Pool with the given runtime.
 func NewPool(runtime ContainerRuntime) *Pool {
 	return &Pool{
-		runtime: runtime,
+		runtime:     runtime,
+		metrics:     NewMetrics(),
+		drivePrefix: DefaultDrivePrefix,
+		pulled:      make(map[string]bool),
 	}
 }
 
+// SetDrivePrefix sets where the Docker daemon sees Windows drives, e.g. "/mnt"
+// for a daemon in WSL2 (the default) or "/" for Docker Desktop's /c/... paths.
+// It has no effect on other platforms.
+func (p *Pool) SetDrivePrefix(prefix string) {
+	p.drivePrefix = prefix
+}
+
+// ContainerSpec describes how to start a pool container beyond its image, as
+// for a project's dev container.
+type ContainerSpec struct {
+	Image string
+	// Env holds KEY=VALUE environment variables of the container.
+	Env []string
+	// User is the container user. Writable containers run as the host user instead.
+	User string
+	// Setup is a shell command run once after the container starts.
+	Setup string
+}
+
+// setupTimeout bounds the setup command of a container.
+const setupTimeout = 10 * time.Minute
+
 // GetOrCreate returns a container ID for the given image and project path.
 // If a matching warm container exists, it is returned.
 // Otherwise, a new container is created and started.
 func (p *Pool) GetOrCreate(ctx context.Context, img, projectPath string, writable bool) (string, error) {
+	return p.GetOrCreateSpec(ctx, ContainerSpec{Image: img}, projectPath, writable)
+}
+
+// GetOrCreateSpec is GetOrCreate for a container described by spec. A warm
+// container is reused only if it was started from the same spec.
+func (p *Pool) GetOrCreateSpec(ctx context.Context, spec ContainerSpec, projectPath string, writable bool) (string, error) {
+	ctx, span := telemetry.Start(ctx, "pool.get_or_create", attribute.String("container.image", spec.Image))
+	id, err := p.getOrCreate(ctx, spec, projectPath, writable)
+	span.SetAttributes(attribute.String("container.id", id))
+	telemetry.End(span, err)
+	return id, err
+}
+
+func (p *Pool) getOrCreate(ctx context.Context, spec ContainerSpec, projectPath string, writable bool) (string, error) {
+	img := spec.Image
 	log := logger.FromContext(ctx)
 	log.Info("GetOrCreate started", "image", img, "project", projectPath, "writable", writable)
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	key := computePoolKey(img, projectPath, writable)
+	key := computeSpecKey(spec, projectPath, writable)
 
 	// Check for existing container
 	existingID, err := p.findExistingContainer(ctx, key)
@@ -56,19 +108,63 @@ func (p *Pool) GetOrCreate(ctx context.Context, img, projectPath string, writabl
 		return "", fmt.Errorf("finding existing container: %w", err)
 	}
 	if existingID != "" {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("pool.warm", true))
+		p.metrics.recordHit(img)
+		p.touch(ctx, existingID)
 		log.Info("GetOrCreate reused existing container", "container_id", existingID)
 		return existingID, nil
 	}
 
 	// Create new container
-	id, err := p.createContainer(ctx, img, projectPath, writable, key)
+	createStart := time.Now()
+	id, err := p.createContainer(ctx, spec, projectPath, writable, key)
+	p.metrics.recordMiss(img, time.Since(createStart), err)
 	if err != nil {
 		return "", err
 	}
+	p.touch(ctx, id)
 	log.Info("GetOrCreate created new container", "container_id", id)
 	return id, nil
 }
 
+// CreateEphemeral creates and starts a container described by spec that is
+// never reused: its pool key is unique. Remove it with RemoveEphemeral after use.
+func (p *Pool) CreateEphemeral(ctx context.Context, spec ContainerSpec, projectPath string, writable bool) (string, error) {
+	ctx, span := telemetry.Start(ctx, "pool.create_ephemeral", attribute.String("container.image", spec.Image))
+	id, err := p.createEphemeral(ctx, spec, projectPath, writable)
+	span.SetAttributes(attribute.String("container.id", id))
+	telemetry.End(span, err)
+	return id, err
+}
+
+func (p *Pool) createEphemeral(ctx context.Context, spec ContainerSpec, projectPath string, writable bool) (string, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generating container key: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	createStart := time.Now()
+	id, err := p.createContainer(ctx, spec, projectPath, writable, "ephemeral-"+hex.EncodeToString(nonce))
+	p.metrics.recordMiss(spec.Image, time.Since(createStart), err)
+	if err != nil {
+		return "", err
+	}
+	logger.FromContext(ctx).Info("CreateEphemeral created container", "container_id", id)
+	return id, nil
+}
+
+// RemoveEphemeral force-removes a container created by CreateEphemeral.
+func (p *Pool) RemoveEphemeral(ctx context.Context, containerID string) error {
+	if err := p.runtime.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil {
+		return fmt.Errorf("removing container %s: %w", ShortID(containerID), err)
+	}
+	logger.FromContext(ctx).Info("ephemeral container removed", "container_id", containerID)
+	return nil
+}
+
 // findExistingContainer searches for a running container with the matching pool key.
 func (p *Pool) findExistingContainer(ctx context.Context, key string) (string, error) {
 	opts := container.ListOptions{
@@ -92,27 +188,14 @@ func (p *Pool) findExistingContainer(ctx context.Context, key string) (string, e
 }
 
 // createContainer pulls the image (if needed), creates, and starts a new container.
-func (p *Pool) createContainer(ctx context.Context, img, projectPath string, writable bool, key string) (string, error) {
-	// 1. Pull Image (lazy)
+func (p *Pool) createContainer(ctx context.Context, spec ContainerSpec, projectPath string, writable bool, key string) (string, error) {
+	img := spec.Image
+	// 1. Pull Image (lazy), unless PullImages just did.
 	// We use ImagePull to ensure it exists.
-	logger.FromContext(ctx).Debug("pulling image", "image", img)
-	reader, err := p.runtime.ImagePull(ctx, img, image.PullOptions{})
-	if err != nil {
-		return "", fmt.Errorf("pulling image %q: %w", img, err)
-	}
-	if reader != nil {
-		// [SEC] Verify that the image pull actually succeeded by reading the response.
-		// Docker sends progress (or errors) in that stream.
-		// If we don't read it, we might assume success on a failed pull.
-		if _, err := io.Copy(io.Discard, reader); err != nil {
-			if closeErr := reader.Close(); closeErr != nil {
-				logger.FromContext(ctx).Error("failed to close image pull reader", "error", closeErr)
-			}
-			return "", fmt.Errorf("reading image pull response: %w", err)
-		}
-		if err := reader.Close(); err != nil {
-			return "", fmt.Errorf("closing image pull reader: %w", err)
-		}
+	if p.pulled[img] {
+		delete(p.pulled, img)
+	} else if err := p.pull(ctx, img); err != nil {
+		return "", err
 	}
 
 	// 2. Create Container
@@ -128,6 +211,8 @@ func (p *Pool) createContainer(ctx context.Context, img, projectPath string, wri
 			labelLastUsed: time.Now().Format(time.RFC3339),
 		},
 		WorkingDir: "/workspace",
+		Env:        spec.Env,
+		User:       spec.User,
 	}
 
 	if writable {
@@ -136,12 +221,14 @@ func (p *Pool) createContainer(ctx context.Context, img, projectPath string, wri
 		if err != nil {
 			return "", fmt.Errorf("getting current user for writable mount: %w", err)
 		}
-		config.User = uidGid
+		if uidGid != "" {
+			config.User = uidGid
+		}
 	}
 
 	hostConfig := &container.HostConfig{
 		Mounts: []mount.Mount{
-			projectMount(projectPath, writable),
+			projectMount(mountSource(projectPath, goos, p.drivePrefix), writable),
 			tmpMount(),
 		},
 	}
@@ -153,16 +240,102 @@ func (p *Pool) createContainer(ctx context.Context, img, projectPath string, wri
 	logger.FromContext(ctx).Debug("container created", "container_id", resp.ID)
 
 	// 3. Start Container
+	// A container whose start or setup failed is removed, even if the run was
+	// cancelled (e.g. by fail-fast) meanwhile.
+	cleanupCtx := context.WithoutCancel(ctx)
 	if err := p.runtime.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-		// Try to remove if start fails
-		_ = p.runtime.ContainerRemove(ctx, resp.ID, container.RemoveOptions{Force: true})
+		_ = p.runtime.ContainerRemove(cleanupCtx, resp.ID, container.RemoveOptions{Force: true})
 		return "", fmt.Errorf("starting container: %w", err)
 	}
 	logger.FromContext(ctx).Info("container started", "container_id", resp.ID, "image", img, "project", projectPath)
 
+	// 4. Run the setup command; a container whose setup failed is not reused.
+	if spec.Setup != "" {
+		if err := p.setup(ctx, resp.ID, spec.Setup); err != nil {
+			_ = p.runtime.ContainerRemove(cleanupCtx, resp.ID, container.RemoveOptions{Force: true})
+			return "", err
+		}
+	}
+
 	return resp.ID, nil
 }
 
+// pullTimeKey is the context key of the duration set by WithPullTime.
+type pullTimeKey struct{}
+
+// WithPullTime returns a context in which image pulls made by the pool add
+// their duration to *d, so callers can tell pulling an image apart from
+// starting its container. Read *d only after the pool call returns.
+func WithPullTime(ctx context.Context, d *time.Duration) context.Context {
+	return context.WithValue(ctx, pullTimeKey{}, d)
+}
+
+// pull pulls img, reading the response to the end.
+func (p *Pool) pull(ctx context.Context, img string) error {
+	logger.FromContext(ctx).Debug("pulling image", "image", img)
+	if d, ok := ctx.Value(pullTimeKey{}).(*time.Duration); ok {
+		defer func(start time.Time) { *d += time.Since(start) }(time.Now())
+	}
+	reader, err := p.runtime.ImagePull(ctx, img, image.PullOptions{})
+	if err != nil {
+		return fmt.Errorf("pulling image %q: %w", img, err)
+	}
+	if reader == nil {
+		return nil
+	}
+	// [SEC] Verify that the image pull actually succeeded by reading the response.
+	// Docker sends progress (or errors) in that stream.
+	// If we don't read it, we might assume success on a failed pull.
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		if closeErr := reader.Close(); closeErr != nil {
+			logger.FromContext(ctx).Error("failed to close image pull reader", "error", closeErr)
+		}
+		return fmt.Errorf("reading image pull response: %w", err)
+	}
+	if err := reader.Close(); err != nil {
+		return fmt.Errorf("closing image pull reader: %w", err)
+	}
+	return nil
+}
+
+// PullImages pulls images in parallel, reporting progress as PullImages does.
+// The next container created from a pulled image does not pull it again.
+func (p *Pool) PullImages(ctx context.Context, images []string, report func(PullProgress)) error {
+	return PullImages(ctx, p.runtime, images, func(progress PullProgress) {
+		if progress.Done && progress.Err == nil {
+			p.mu.Lock()
+			p.pulled[progress.Image] = true
+			p.mu.Unlock()
+		}
+		if report != nil {
+			report(progress)
+		}
+	})
+}
+
+// IsWarm reports whether a running container matches spec, so GetOrCreateSpec
+// would reuse it.
+func (p *Pool) IsWarm(ctx context.Context, spec ContainerSpec, projectPath string, writable bool) (bool, error) {
+	id, err := p.findExistingContainer(ctx, computeSpecKey(spec, projectPath, writable))
+	if err != nil {
+		return false, fmt.Errorf("finding existing container: %w", err)
+	}
+	return id != "", nil
+}
+
+// setup runs the setup command of a new container.
+func (p *Pool) setup(ctx context.Context, containerID, command string) error {
+	logger.FromContext(ctx).Info("running container setup", "container_id", containerID)
+	result, err := NewExecutor(p.runtime).Run(ctx, containerID, command, setupTimeout)
+	if err != nil {
+		return fmt.Errorf("running container setup: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("container setup exited with code %d: %s", result.ExitCode, strings.TrimSpace(string(result.Stderr)))
+	}
+	return nil
+}
+
 // CleanupStale removes containers that haven't been used for the given TTL.
 func (p *Pool) CleanupStale(ctx context.Context, ttl time.Duration) (int, error) {
 	log := logger.FromContext(ctx)
@@ -185,30 +358,31 @@ func (p *Pool) CleanupStale(ctx context.Context, ttl time.Duration) (int, error)
 
 	count := 0
 	threshold := time.Now().Add(-ttl)
+	var kept []container.Summary
 
 	for _, c := range containers {
-		lastUsedStr, ok := c.Labels[labelLastUsed]
+		lastUsed, ok := p.lastUsed(c)
 		if !ok {
-			continue
-		}
-
-		lastUsed, err := time.Parse(time.RFC3339, lastUsedStr)
-		if err != nil {
-			continue // Skip containers with invalid timestamps
+			kept = append(kept, c)
+			continue // Skip containers without a valid timestamp
 		}
 
 		if lastUsed.Before(threshold) {
-			if err := p.runtime.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true}); err == nil {
+			err := p.runtime.ContainerRemove(ctx, c.ID, container.RemoveOptions{Force: true})
+			if err == nil {
 				count++
-			} else {
-				log.Error("failed to remove stale container",
-					"container_id", c.ID,
-					"error", err,
-				)
+				continue
 			}
+			log.Error("failed to remove stale container",
+				"container_id", c.ID,
+				"error", err,
+			)
 		}
+		kept = append(kept, c)
 	}
 
+	p.forget(kept)
+	p.metrics.recordCleanup(true, count)
 	log.Info("CleanupStale completed", "removed_count", count)
 	return count, nil
 }
@@ -245,10 +419,21 @@ func (p *Pool) CleanupAll(ctx context.Context) (int, error) {
 		}
 	}
 
+	p.metrics.recordCleanup(false, count)
 	log.Info("CleanupAll completed", "removed_count", count)
 	return count, nil
 }
 
+// computeSpecKey is the pool key of spec. A plain image has the key of
+// computePoolKey, so containers stay warm across versions.
+func computeSpecKey(spec ContainerSpec, projectPath string, writable bool) string {
+	img := spec.Image
+	if len(spec.Env) > 0 || spec.User != "" || spec.Setup != "" {
+		img = fmt.Sprintf("%s|%q|%q|%q", img, spec.Env, spec.User, spec.Setup)
+	}
+	return computePoolKey(img, projectPath, writable)
+}
+
 func computePoolKey(image, projectPath string, writable bool) string {
 	data := fmt.Sprintf("%s|%s|%t", image, projectPath, writable)
 	hash := sha256.Sum256([]byte(data))
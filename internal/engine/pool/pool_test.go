@@ -49,6 +49,55 @@ func TestGetOrCreate_New(t *testing.T) {
 	}
 }
 
+func TestGetOrCreate_PullTime(t *testing.T) {
+	mock := &MockRuntime{
+		ImagePullReader: io.NopCloser(strings.NewReader("pulling...")),
+		CreateResp:      container.CreateResponse{ID: "new-id"},
+	}
+	p := NewPool(mock)
+
+	pull := time.Duration(-1)
+	if _, err := p.GetOrCreate(WithPullTime(context.Background(), &pull), "alpine", "/proj", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pull < 0 {
+		t.Error("expected the pull to be timed")
+	}
+}
+
+// cancellingRuntime cancels the run as the container starts and records
+// whether the failed container is removed with a live context.
+type cancellingRuntime struct {
+	MockRuntime
+	cancel    context.CancelFunc
+	removeErr error
+}
+
+func (c *cancellingRuntime) ContainerStart(ctx context.Context, _ string, _ container.StartOptions) error {
+	c.cancel()
+	return ctx.Err()
+}
+
+func (c *cancellingRuntime) ContainerRemove(ctx context.Context, _ string, _ container.RemoveOptions) error {
+	c.removeErr = ctx.Err()
+	return nil
+}
+
+func TestGetOrCreate_CancelledStartRemovesContainer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := &cancellingRuntime{
+		MockRuntime: MockRuntime{CreateResp: container.CreateResponse{ID: "new-id"}},
+		cancel:      cancel,
+		removeErr:   errors.New("not removed"),
+	}
+	if _, err := NewPool(rt).GetOrCreate(ctx, "alpine", "/proj", false); err == nil {
+		t.Fatal("expected a start error")
+	}
+	if rt.removeErr != nil {
+		t.Errorf("expected the container to be removed despite the cancellation, got %v", rt.removeErr)
+	}
+}
+
 func TestCleanupStale(t *testing.T) {
 	now := time.Now()
 
@@ -440,3 +489,73 @@ func TestCleanupStale_NoLastUsedLabel(t *testing.T) {
 		t.Errorf("expected 0 removed, got %d", count)
 	}
 }
+
+func TestGetOrCreateSpec(t *testing.T) {
+	mock := &MockRuntime{
+		ImagePullReader: io.NopCloser(strings.NewReader("pulling...")),
+		CreateResp:      container.CreateResponse{ID: "dev-id"},
+	}
+	p := NewPool(mock)
+
+	spec := ContainerSpec{Image: "mcr.microsoft.com/devcontainers/go:1", Env: []string{"CGO_ENABLED=0"}, User: "vscode"}
+	if _, err := p.GetOrCreateSpec(context.Background(), spec, "/proj", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := mock.CreateConfig
+	if cfg.User != "vscode" || len(cfg.Env) != 1 || cfg.Env[0] != "CGO_ENABLED=0" {
+		t.Errorf("unexpected container config: user %q, env %v", cfg.User, cfg.Env)
+	}
+	if cfg.Labels[labelPoolKey] == computePoolKey(spec.Image, "/proj", false) {
+		t.Error("expected a spec to have its own pool key")
+	}
+	if computeSpecKey(ContainerSpec{Image: "alpine"}, "/proj", false) != computePoolKey("alpine", "/proj", false) {
+		t.Error("expected a plain image to keep its pool key")
+	}
+}
+
+func TestCreateEphemeral(t *testing.T) {
+	mock := &MockRuntime{
+		ListResp:        []container.Summary{{ID: "warm-id"}},
+		ImagePullReader: io.NopCloser(strings.NewReader("pulling...")),
+		CreateResp:      container.CreateResponse{ID: "fresh-id"},
+	}
+	p := NewPool(mock)
+
+	// A warm container of the image is never reused.
+	id, err := p.CreateEphemeral(context.Background(), ContainerSpec{Image: "alpine"}, "/proj", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "fresh-id" {
+		t.Errorf("expected a new container, got %q", id)
+	}
+	first := mock.CreateConfig.Labels[labelPoolKey]
+	if _, err := p.CreateEphemeral(context.Background(), ContainerSpec{Image: "alpine"}, "/proj", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key := mock.CreateConfig.Labels[labelPoolKey]; key == first || key == computePoolKey("alpine", "/proj", false) {
+		t.Errorf("expected a unique pool key per ephemeral container, got %q", key)
+	}
+
+	if err := p.RemoveEphemeral(context.Background(), id); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	mock.RemoveErr = errors.New("remove failed")
+	if err := p.RemoveEphemeral(context.Background(), id); err == nil {
+		t.Error("expected the remove error")
+	}
+}
+
+func TestGetOrCreateSpec_SetupError(t *testing.T) {
+	mock := &MockRuntime{
+		ImagePullReader: io.NopCloser(strings.NewReader("pulling...")),
+		CreateResp:      container.CreateResponse{ID: "dev-id"},
+		ExecCreateErr:   errors.New("exec failed"),
+	}
+	p := NewPool(mock)
+
+	_, err := p.GetOrCreateSpec(context.Background(), ContainerSpec{Image: "alpine", Setup: "make tools"}, "/proj", false)
+	if err == nil || !strings.Contains(err.Error(), "container setup") {
+		t.Fatalf("expected a setup error, got %v", err)
+	}
+}
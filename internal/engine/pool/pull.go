@@ -0,0 +1,122 @@
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// PullProgress reports how far the pull of an image has come.
+type PullProgress struct {
+	Image string
+	// Current and Total are the bytes downloaded of the layers seen so far.
+	// Total is 0 until the size of a layer is known.
+	Current int64
+	Total   int64
+	// Status is Docker's latest status message, e.g. "Downloading".
+	Status string
+	// Done is set on the last report of an image; Err is set if the pull failed.
+	Done bool
+	Err  error
+}
+
+// pullMessage is a message of Docker's image pull stream.
+type pullMessage struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// PullImages pulls images in parallel. report, if not nil, is called from the
+// pulling goroutines as each image progresses, so it must be safe for
+// concurrent use. The errors of all failed pulls are returned.
+func PullImages(ctx context.Context, runtime ContainerRuntime, images []string, report func(PullProgress)) error {
+	if report == nil {
+		report = func(PullProgress) {}
+	}
+	errs := make([]error, len(images))
+	var wg sync.WaitGroup
+	for i, img := range images {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := pullImage(ctx, runtime, img, report)
+			if err != nil {
+				errs[i] = fmt.Errorf("pulling image %q: %w", img, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// pullImage pulls img, reporting the progress of its layers.
+func pullImage(ctx context.Context, runtime ContainerRuntime, img string, report func(PullProgress)) (err error) {
+	progress := PullProgress{Image: img}
+	defer func() {
+		progress.Done, progress.Err = true, err
+		report(progress)
+	}()
+
+	logger.FromContext(ctx).Debug("pulling image", "image", img)
+	reader, err := runtime.ImagePull(ctx, img, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	if reader == nil {
+		return nil
+	}
+	defer func() {
+		if closeErr := reader.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("closing image pull reader: %w", closeErr)
+		}
+	}()
+
+	// [SEC] Read the stream to the end: a failed pull is only reported in it.
+	type layer struct{ current, total int64 }
+	layers := make(map[string]layer)
+	dec := json.NewDecoder(reader)
+	for {
+		var msg pullMessage
+		if err := dec.Decode(&msg); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("reading image pull response: %w", err)
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+
+		if msg.ID != "" && msg.ProgressDetail.Total > 0 {
+			l := layers[msg.ID]
+			// Extraction reports progress against the same total; keep the download's.
+			if msg.Status == "Downloading" || l.total == 0 {
+				l = layer{current: msg.ProgressDetail.Current, total: msg.ProgressDetail.Total}
+			}
+			layers[msg.ID] = l
+		}
+		if msg.Status == "Download complete" || msg.Status == "Pull complete" {
+			if l, ok := layers[msg.ID]; ok {
+				l.current = l.total
+				layers[msg.ID] = l
+			}
+		}
+		progress.Current, progress.Total = 0, 0
+		for _, l := range layers {
+			progress.Current += l.current
+			progress.Total += l.total
+		}
+		progress.Status = msg.Status
+		report(progress)
+	}
+}
@@ -0,0 +1,107 @@
+package pool
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+)
+
+// pullRuntime serves a recorded pull stream per image.
+type pullRuntime struct {
+	MockRuntime
+	streams map[string]string
+	mu      sync.Mutex
+	pulls   int
+}
+
+func (r *pullRuntime) ImagePull(_ context.Context, ref string, _ image.PullOptions) (io.ReadCloser, error) {
+	r.mu.Lock()
+	r.pulls++
+	r.mu.Unlock()
+	return io.NopCloser(strings.NewReader(r.streams[ref])), nil
+}
+
+func TestPullImages(t *testing.T) {
+	runtime := &pullRuntime{streams: map[string]string{
+		"golang:1.25": `{"status":"Pulling from library/golang","id":"1.25"}
+{"status":"Downloading","progressDetail":{"current":50,"total":200},"id":"a"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"b"}
+{"status":"Download complete","id":"a"}
+{"status":"Extracting","progressDetail":{"current":5,"total":200},"id":"a"}
+{"status":"Download complete","id":"b"}
+{"status":"Status: Downloaded newer image for golang:1.25"}
+`,
+		"missing:latest": `{"status":"Pulling from library/missing"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`,
+	}}
+
+	var mu sync.Mutex
+	last := make(map[string]PullProgress)
+	err := PullImages(context.Background(), runtime, []string{"golang:1.25", "missing:latest"}, func(p PullProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if last[p.Image].Done {
+			t.Errorf("%s: progress reported after Done", p.Image)
+		}
+		last[p.Image] = p
+	})
+
+	if err == nil || !strings.Contains(err.Error(), `pulling image "missing:latest": manifest unknown`) {
+		t.Errorf("expected the failed pull to be returned, got %v", err)
+	}
+	if got := last["golang:1.25"]; !got.Done || got.Err != nil || got.Current != 300 || got.Total != 300 {
+		t.Errorf("golang progress = %+v, want 300/300 done", got)
+	}
+	if got := last["missing:latest"]; !got.Done || got.Err == nil {
+		t.Errorf("missing progress = %+v, want a failed pull", got)
+	}
+}
+
+func TestPullImages_InvalidStream(t *testing.T) {
+	runtime := &pullRuntime{streams: map[string]string{"alpine": "not json"}}
+	if err := PullImages(context.Background(), runtime, []string{"alpine"}, nil); err == nil {
+		t.Error("expected an error for an unreadable pull stream")
+	}
+}
+
+func TestPool_PullImagesSkipsPullOnCreate(t *testing.T) {
+	runtime := &pullRuntime{streams: map[string]string{"alpine": `{"status":"Pull complete","id":"a"}`}}
+	runtime.CreateResp.ID = "new-id"
+	p := NewPool(runtime)
+
+	if err := p.PullImages(context.Background(), []string{"alpine"}, nil); err != nil {
+		t.Fatalf("PullImages: %v", err)
+	}
+	if _, err := p.GetOrCreate(context.Background(), "alpine", "/proj", false); err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if runtime.pulls != 1 {
+		t.Errorf("expected 1 pull, got %d", runtime.pulls)
+	}
+
+	// The next container pulls again, so tags stay current.
+	if _, err := p.GetOrCreate(context.Background(), "alpine", "/other", false); err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if runtime.pulls != 2 {
+		t.Errorf("expected 2 pulls, got %d", runtime.pulls)
+	}
+}
+
+func TestPool_IsWarm(t *testing.T) {
+	mock := &MockRuntime{}
+	p := NewPool(mock)
+	if warm, err := p.IsWarm(context.Background(), ContainerSpec{Image: "alpine"}, "/proj", false); err != nil || warm {
+		t.Errorf("IsWarm = %v, %v; want cold", warm, err)
+	}
+	mock.ListResp = []container.Summary{{ID: "warm-id"}}
+	if warm, err := p.IsWarm(context.Background(), ContainerSpec{Image: "alpine"}, "/proj", false); err != nil || !warm {
+		t.Errorf("IsWarm = %v, %v; want warm", warm, err)
+	}
+}
@@ -0,0 +1,169 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// maxTraceLine bounds one trace line; exec output streams are stored inline.
+const maxTraceLine = 64 << 20
+
+// ReplayRuntime is a ContainerRuntime that serves responses from a trace
+// written by RecordingRuntime, without a Docker daemon. Calls are matched by
+// operation and key (image, container ID, exec ID, or command), and repeated
+// calls get the recorded responses in order, so gates running in parallel
+// replay deterministically.
+type ReplayRuntime struct {
+	mu      sync.Mutex
+	entries map[string][]traceEntry
+}
+
+// NewReplayRuntime reads a JSONL trace from r.
+func NewReplayRuntime(r io.Reader) (*ReplayRuntime, error) {
+	rt := &ReplayRuntime{entries: make(map[string][]traceEntry)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTraceLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var e traceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("parsing trace line %d: %w", line, err)
+		}
+		id := replayID(e.Op, e.Key)
+		rt.entries[id] = append(rt.entries[id], e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading trace: %w", err)
+	}
+	return rt, nil
+}
+
+// Remaining returns the number of recorded calls that have not been replayed.
+func (r *ReplayRuntime) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, q := range r.entries {
+		n += len(q)
+	}
+	return n
+}
+
+// next pops the next recorded call for op and key, decoding its response into
+// resp (if not nil). It returns the recorded call error, if any.
+func (r *ReplayRuntime) next(op, key string, resp any) (traceEntry, error) {
+	r.mu.Lock()
+	id := replayID(op, key)
+	q := r.entries[id]
+	if len(q) == 0 {
+		r.mu.Unlock()
+		return traceEntry{}, fmt.Errorf("replay: no recorded %s call for %q", op, key)
+	}
+	e := q[0]
+	r.entries[id] = q[1:]
+	r.mu.Unlock()
+
+	if resp != nil && len(e.Response) > 0 {
+		if err := json.Unmarshal(e.Response, resp); err != nil {
+			return e, fmt.Errorf("replay: decoding recorded %s response: %w", op, err)
+		}
+	}
+	if e.Error != "" {
+		return e, errors.New(e.Error)
+	}
+	return e, nil
+}
+
+func replayID(op, key string) string {
+	return op + "\x00" + key
+}
+
+func (r *ReplayRuntime) Ping(_ context.Context) error {
+	_, err := r.next(opPing, "", nil)
+	return err
+}
+
+func (r *ReplayRuntime) ImagePull(_ context.Context, ref string, _ image.PullOptions) (io.ReadCloser, error) {
+	e, err := r.next(opImagePull, ref, nil)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(e.Stream)), nil
+}
+
+func (r *ReplayRuntime) ContainerCreate(_ context.Context, config *container.Config, _ *container.HostConfig, _ *network.NetworkingConfig, _ *v1.Platform, _ string) (container.CreateResponse, error) {
+	var resp container.CreateResponse
+	_, err := r.next(opContainerCreate, createKey(config), &resp)
+	return resp, err
+}
+
+func (r *ReplayRuntime) ContainerStart(_ context.Context, containerID string, _ container.StartOptions) error {
+	_, err := r.next(opContainerStart, containerID, nil)
+	return err
+}
+
+func (r *ReplayRuntime) ContainerInspect(_ context.Context, containerID string) (container.InspectResponse, error) {
+	var resp container.InspectResponse
+	_, err := r.next(opContainerInspect, containerID, &resp)
+	return resp, err
+}
+
+func (r *ReplayRuntime) ContainerList(_ context.Context, options container.ListOptions) ([]container.Summary, error) {
+	var resp []container.Summary
+	_, err := r.next(opContainerList, listKey(options), &resp)
+	return resp, err
+}
+
+func (r *ReplayRuntime) ContainerRemove(_ context.Context, containerID string, _ container.RemoveOptions) error {
+	_, err := r.next(opContainerRemove, containerID, nil)
+	return err
+}
+
+func (r *ReplayRuntime) ContainerExecCreate(_ context.Context, containerID string, config container.ExecOptions) (container.ExecCreateResponse, error) {
+	var resp container.ExecCreateResponse
+	_, err := r.next(opExecCreate, execCreateKey(containerID, config), &resp)
+	return resp, err
+}
+
+func (r *ReplayRuntime) ContainerExecAttach(_ context.Context, execID string, _ container.ExecAttachOptions) (types.HijackedResponse, error) {
+	e, err := r.next(opExecAttach, execID, nil)
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	return types.HijackedResponse{
+		Conn:   replayConn{},
+		Reader: bufio.NewReader(bytes.NewReader(e.Stream)),
+	}, nil
+}
+
+func (r *ReplayRuntime) ContainerExecInspect(_ context.Context, execID string) (container.ExecInspect, error) {
+	var resp container.ExecInspect
+	_, err := r.next(opExecInspect, execID, &resp)
+	return resp, err
+}
+
+// replayConn stands in for the hijacked connection of a replayed exec; only
+// Close is used once the recorded output has been read.
+type replayConn struct {
+	net.Conn
+}
+
+func (replayConn) Close() error { return nil }
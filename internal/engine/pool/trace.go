@@ -0,0 +1,264 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Trace operations, one per ContainerRuntime method.
+const (
+	opPing             = "ping"
+	opImagePull        = "image_pull"
+	opContainerCreate  = "container_create"
+	opContainerStart   = "container_start"
+	opContainerInspect = "container_inspect"
+	opContainerList    = "container_list"
+	opContainerRemove  = "container_remove"
+	opExecCreate       = "exec_create"
+	opExecAttach       = "exec_attach"
+	opExecInspect      = "exec_inspect"
+)
+
+// traceEntry is one line of a JSONL runtime trace. Key identifies the call for
+// replay (an image, container ID, exec ID, or command); Stream holds the raw
+// bytes of an image pull or exec output stream.
+type traceEntry struct {
+	Op         string          `json:"op"`
+	Key        string          `json:"key,omitempty"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Stream     []byte          `json:"stream,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+// RecordingRuntime is a ContainerRuntime decorator that writes every call,
+// its response, and any streamed output to a JSONL trace. The trace can be
+// served back by ReplayRuntime.
+type RecordingRuntime struct {
+	inner ContainerRuntime
+
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// NewRecordingRuntime creates a RecordingRuntime that forwards calls to inner
+// and writes the trace to w.
+func NewRecordingRuntime(inner ContainerRuntime, w io.Writer) *RecordingRuntime {
+	return &RecordingRuntime{inner: inner, enc: json.NewEncoder(w)}
+}
+
+// Err returns the first error encountered writing the trace, if any. Trace
+// errors never fail the calls being recorded.
+func (r *RecordingRuntime) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// record writes one trace entry. Streams are recorded when they are closed.
+func (r *RecordingRuntime) record(op, key string, request, response any, stream []byte, callErr error, start time.Time) {
+	e := traceEntry{Op: op, Key: key, Stream: stream, DurationMs: time.Since(start).Milliseconds()}
+	if callErr != nil {
+		e.Error = callErr.Error()
+	}
+
+	var err error
+	if request != nil {
+		e.Request, err = json.Marshal(request)
+	}
+	if err == nil && response != nil {
+		e.Response, err = json.Marshal(response)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		err = r.enc.Encode(e)
+	}
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("recording %s: %w", op, err)
+	}
+}
+
+func (r *RecordingRuntime) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := r.inner.Ping(ctx)
+	r.record(opPing, "", nil, nil, nil, err, start)
+	return err
+}
+
+func (r *RecordingRuntime) ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error) {
+	// Pull options are not recorded: they carry registry credentials.
+	start := time.Now()
+	reader, err := r.inner.ImagePull(ctx, ref, options)
+	if err != nil || reader == nil {
+		r.record(opImagePull, ref, nil, nil, nil, err, start)
+		return reader, err
+	}
+	buf := &lockedBuffer{}
+	return &recordedReadCloser{
+		Reader: io.TeeReader(reader, buf),
+		closer: reader,
+		onClose: func() {
+			r.record(opImagePull, ref, nil, nil, buf.Bytes(), nil, start)
+		},
+	}, nil
+}
+
+func (r *RecordingRuntime) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *v1.Platform, name string) (container.CreateResponse, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerCreate(ctx, config, hostConfig, networkingConfig, platform, name)
+	request := map[string]any{"config": config, "host_config": hostConfig, "name": name}
+	r.record(opContainerCreate, createKey(config), request, resp, nil, err, start)
+	return resp, err
+}
+
+func (r *RecordingRuntime) ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error {
+	start := time.Now()
+	err := r.inner.ContainerStart(ctx, containerID, options)
+	r.record(opContainerStart, containerID, options, nil, nil, err, start)
+	return err
+}
+
+func (r *RecordingRuntime) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerInspect(ctx, containerID)
+	r.record(opContainerInspect, containerID, nil, resp, nil, err, start)
+	return resp, err
+}
+
+func (r *RecordingRuntime) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerList(ctx, options)
+	r.record(opContainerList, listKey(options), options, resp, nil, err, start)
+	return resp, err
+}
+
+func (r *RecordingRuntime) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
+	start := time.Now()
+	err := r.inner.ContainerRemove(ctx, containerID, options)
+	r.record(opContainerRemove, containerID, options, nil, nil, err, start)
+	return err
+}
+
+func (r *RecordingRuntime) ContainerExecCreate(ctx context.Context, containerID string, config container.ExecOptions) (container.ExecCreateResponse, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerExecCreate(ctx, containerID, config)
+	r.record(opExecCreate, execCreateKey(containerID, config), config, resp, nil, err, start)
+	return resp, err
+}
+
+func (r *RecordingRuntime) ContainerExecAttach(ctx context.Context, execID string, config container.ExecAttachOptions) (types.HijackedResponse, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerExecAttach(ctx, execID, config)
+	if err != nil {
+		r.record(opExecAttach, execID, config, nil, nil, err, start)
+		return resp, err
+	}
+	buf := &lockedBuffer{}
+	if resp.Reader != nil {
+		resp.Reader = bufio.NewReader(io.TeeReader(resp.Reader, buf))
+	}
+	resp.Conn = &recordedConn{
+		Conn: resp.Conn,
+		onClose: func() {
+			r.record(opExecAttach, execID, config, nil, buf.Bytes(), nil, start)
+		},
+	}
+	return resp, nil
+}
+
+func (r *RecordingRuntime) ContainerExecInspect(ctx context.Context, execID string) (container.ExecInspect, error) {
+	start := time.Now()
+	resp, err := r.inner.ContainerExecInspect(ctx, execID)
+	r.record(opExecInspect, execID, nil, resp, nil, err, start)
+	return resp, err
+}
+
+// createKey identifies a container create by image and pool key; other
+// labels, such as the last-used time, differ between runs.
+func createKey(config *container.Config) string {
+	if config == nil {
+		return ""
+	}
+	return config.Image + " " + config.Labels[labelPoolKey]
+}
+
+// listKey identifies a container list by its options (filters are encoded in sorted order).
+func listKey(options container.ListOptions) string {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// execCreateKey identifies an exec by container and command.
+func execCreateKey(containerID string, config container.ExecOptions) string {
+	return containerID + " " + strings.Join(config.Cmd, " ")
+}
+
+// lockedBuffer is a bytes.Buffer safe for a reader goroutine and a concurrent Close.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// Bytes returns a copy of the buffered bytes.
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// recordedReadCloser records a stream once it is closed.
+type recordedReadCloser struct {
+	io.Reader
+	closer  io.Closer
+	once    sync.Once
+	onClose func()
+}
+
+func (rc *recordedReadCloser) Close() error {
+	err := rc.closer.Close()
+	rc.once.Do(rc.onClose)
+	return err
+}
+
+// recordedConn records an exec output stream once its connection is closed.
+type recordedConn struct {
+	net.Conn
+	once    sync.Once
+	onClose func()
+}
+
+func (c *recordedConn) Close() error {
+	var err error
+	if c.Conn != nil {
+		err = c.Conn.Close()
+	}
+	c.once.Do(c.onClose)
+	return err
+}
@@ -0,0 +1,161 @@
+package pool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// recordSession runs a cold GetOrCreate and one exec against mock, recording to a trace.
+func recordSession(t *testing.T, mock *MockRuntime) []byte {
+	t.Helper()
+	var trace bytes.Buffer
+	rec := NewRecordingRuntime(mock, &trace)
+
+	id, err := NewPool(rec).GetOrCreate(context.Background(), "alpine", "/proj", false)
+	if err != nil {
+		t.Fatalf("recording GetOrCreate: %v", err)
+	}
+	if _, err := NewExecutor(rec).Run(context.Background(), id, "go vet ./...", time.Second); err != nil {
+		t.Fatalf("recording Run: %v", err)
+	}
+	if err := rec.Err(); err != nil {
+		t.Fatalf("unexpected trace error: %v", err)
+	}
+	return trace.Bytes()
+}
+
+func execMock(t *testing.T) *MockRuntime {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = server.Close() })
+
+	var stream bytes.Buffer
+	stream.Write(mockStream(1, "vet output"))
+	stream.Write(mockStream(2, "warning"))
+
+	return &MockRuntime{
+		ListResp:        []container.Summary{},
+		ImagePullReader: io.NopCloser(strings.NewReader(`{"status":"Pulled"}`)),
+		CreateResp:      container.CreateResponse{ID: "new-id"},
+		ExecCreateResp:  container.ExecCreateResponse{ID: "exec-1"},
+		ExecAttachResp:  types.HijackedResponse{Conn: client, Reader: bufio.NewReader(&stream)},
+		ExecInspectResp: container.ExecInspect{ExitCode: 3},
+	}
+}
+
+func TestRecordingRuntime_WritesTrace(t *testing.T) {
+	trace := recordSession(t, execMock(t))
+
+	var ops []string
+	var attach traceEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(trace)), "\n") {
+		var e traceEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid trace line %q: %v", line, err)
+		}
+		ops = append(ops, e.Op)
+		if e.Op == opExecAttach {
+			attach = e
+		}
+	}
+
+	for _, want := range []string{opContainerList, opImagePull, opContainerCreate, opContainerStart, opExecCreate, opExecAttach, opExecInspect} {
+		found := false
+		for _, op := range ops {
+			found = found || op == want
+		}
+		if !found {
+			t.Errorf("expected %s in trace, got %v", want, ops)
+		}
+	}
+	if attach.Key != "exec-1" || !bytes.Contains(attach.Stream, []byte("vet output")) {
+		t.Errorf("expected exec output recorded for exec-1, got %+v", attach)
+	}
+}
+
+func TestReplayRuntime_ReplaysRecording(t *testing.T) {
+	trace := recordSession(t, execMock(t))
+
+	replay, err := NewReplayRuntime(bytes.NewReader(trace))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	id, err := NewPool(replay).GetOrCreate(context.Background(), "alpine", "/proj", false)
+	if err != nil {
+		t.Fatalf("replaying GetOrCreate: %v", err)
+	}
+	if id != "new-id" {
+		t.Errorf("expected recorded container ID, got %q", id)
+	}
+
+	res, err := NewExecutor(replay).Run(context.Background(), id, "go vet ./...", time.Second)
+	if err != nil {
+		t.Fatalf("replaying Run: %v", err)
+	}
+	if string(res.Stdout) != "vet output" || string(res.Stderr) != "warning" || res.ExitCode != 3 {
+		t.Errorf("unexpected replayed result: stdout=%q stderr=%q exit=%d", res.Stdout, res.Stderr, res.ExitCode)
+	}
+	if n := replay.Remaining(); n != 0 {
+		t.Errorf("expected every recorded call to be replayed, %d left", n)
+	}
+}
+
+func TestReplayRuntime_RecordedError(t *testing.T) {
+	var trace bytes.Buffer
+	rec := NewRecordingRuntime(&MockRuntime{PingErr: errors.New("daemon unreachable")}, &trace)
+	if err := rec.Ping(context.Background()); err == nil {
+		t.Fatal("expected ping error to pass through")
+	}
+
+	replay, err := NewReplayRuntime(&trace)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = replay.Ping(context.Background())
+	if err == nil || err.Error() != "daemon unreachable" {
+		t.Errorf("expected recorded error, got %v", err)
+	}
+}
+
+func TestReplayRuntime_UnrecordedCall(t *testing.T) {
+	replay, err := NewReplayRuntime(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = replay.ContainerExecCreate(context.Background(), "c1", container.ExecOptions{Cmd: []string{"sh", "-c", "ls"}})
+	if err == nil || !strings.Contains(err.Error(), "no recorded exec_create call") {
+		t.Errorf("expected unrecorded call error, got %v", err)
+	}
+}
+
+func TestReplayRuntime_InvalidTrace(t *testing.T) {
+	if _, err := NewReplayRuntime(strings.NewReader("{\"op\":\"ping\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected parse error for line 2, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestRecordingRuntime_WriteErrorDoesNotFailCalls(t *testing.T) {
+	rec := NewRecordingRuntime(&MockRuntime{}, failingWriter{})
+	if err := rec.Ping(context.Background()); err != nil {
+		t.Fatalf("expected ping to succeed, got %v", err)
+	}
+	if err := rec.Err(); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected trace write error, got %v", err)
+	}
+}
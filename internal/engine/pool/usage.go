@@ -0,0 +1,78 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// SetUsageDir records when containers are used in dir, as one empty file per
+// container whose modification time is its last use. Container labels cannot
+// change after creation, so without it a container's last use is its creation.
+func (p *Pool) SetUsageDir(dir string) {
+	p.usageDir = dir
+}
+
+// touch records that the container id was just used. It is best-effort.
+func (p *Pool) touch(ctx context.Context, id string) {
+	if p.usageDir == "" || id == "" {
+		return
+	}
+	path := filepath.Join(p.usageDir, filepath.Base(id))
+	now := time.Now()
+	err := os.Chtimes(path, now, now)
+	if errors.Is(err, os.ErrNotExist) {
+		if err = os.MkdirAll(p.usageDir, 0o750); err == nil {
+			err = os.WriteFile(path, nil, 0o600)
+		}
+	}
+	if err != nil {
+		logger.FromContext(ctx).Debug("could not record container use", "container_id", id, "error", err)
+	}
+}
+
+// untouch removes the usage file of the container id.
+func (p *Pool) untouch(id string) {
+	if p.usageDir != "" {
+		_ = os.Remove(filepath.Join(p.usageDir, filepath.Base(id)))
+	}
+}
+
+// lastUsed returns when c was last used: the later of its last-used label and
+// its usage file. ok is false if neither is known.
+func (p *Pool) lastUsed(c container.Summary) (last time.Time, ok bool) {
+	if t, err := time.Parse(time.RFC3339, c.Labels[labelLastUsed]); err == nil {
+		last, ok = t, true
+	}
+	if p.usageDir != "" {
+		if info, err := os.Stat(filepath.Join(p.usageDir, filepath.Base(c.ID))); err == nil && info.ModTime().After(last) {
+			last, ok = info.ModTime(), true
+		}
+	}
+	return last, ok
+}
+
+// forget removes the usage files of containers that are not in containers.
+func (p *Pool) forget(containers []container.Summary) {
+	if p.usageDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(p.usageDir)
+	if err != nil {
+		return
+	}
+	live := make(map[string]bool, len(containers))
+	for _, c := range containers {
+		live[c.ID] = true
+	}
+	for _, e := range entries {
+		if !live[e.Name()] {
+			_ = os.Remove(filepath.Join(p.usageDir, e.Name()))
+		}
+	}
+}
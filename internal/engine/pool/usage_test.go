@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestPool_UsageDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "containers")
+	created := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+	mock := &MockRuntime{ListResp: []container.Summary{
+		{ID: "c1", Labels: map[string]string{labelManaged: "true", labelLastUsed: created}},
+	}}
+	p := NewPool(mock)
+	p.SetUsageDir(dir)
+
+	// Reusing the container records its use.
+	if _, err := p.GetOrCreate(context.Background(), "alpine", "/proj", false); err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c1")); err != nil {
+		t.Fatalf("expected a usage file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "gone"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := p.CleanupStale(context.Background(), time.Hour)
+	if err != nil || count != 0 {
+		t.Fatalf("CleanupStale = %d, %v; want a recently used container kept", count, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gone")); !os.IsNotExist(err) {
+		t.Error("expected the usage file of a removed container to be deleted")
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "c1"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	count, err = p.CleanupStale(context.Background(), time.Hour)
+	if err != nil || count != 1 {
+		t.Fatalf("CleanupStale = %d, %v; want the idle container removed", count, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c1")); !os.IsNotExist(err) {
+		t.Error("expected the usage file of the pruned container to be deleted")
+	}
+}
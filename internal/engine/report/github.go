@@ -0,0 +1,267 @@
+package report
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPI is the base URL of the GitHub REST API.
+const DefaultGitHubAPI = "https://api.github.com"
+
+// githubTimeout bounds each GitHub API request.
+const githubTimeout = 30 * time.Second
+
+// actionsLogin is the author of comments made with the GITHUB_TOKEN of a
+// GitHub Actions workflow, whose installation token cannot read /user.
+const actionsLogin = "github-actions[bot]"
+
+// findingMarkerPrefix starts the hidden marker of a review comment, followed
+// by the fingerprint of its finding, so re-runs do not post it twice.
+const findingMarkerPrefix = "<!-- gatekeeper-finding:"
+
+// apiError is an error response of the GitHub API.
+type apiError struct {
+	method, path string
+	status       string
+	code         int
+	msg          string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s %s: GitHub returned %s: %s", e.method, e.path, e.status, e.msg)
+}
+
+// GitHub posts reports to a pull request through the GitHub REST API.
+type GitHub struct {
+	// API is the REST API base URL. If empty, DefaultGitHubAPI is used.
+	API string
+	// Repo is the repository as "owner/name".
+	Repo string
+	// Token authenticates the requests.
+	Token string
+	// Client sends the requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// CommentResult describes what PostPR did.
+type CommentResult struct {
+	// CommentURL is the URL of the summary comment.
+	CommentURL string
+	// Updated is set when an existing summary comment was edited.
+	Updated bool
+	// Inline is the number of review comments posted; Existing were posted
+	// by an earlier run, and OutsideDiff are on lines the PR does not change.
+	Inline, Existing, OutsideDiff int
+}
+
+type githubComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// PostPR creates or updates the summary comment of pr, identified by Marker,
+// with body, and posts a review comment on the line of each open finding
+// that has one. Findings already commented on by an earlier run are skipped.
+// Only comments written by the authenticated user are edited or count as
+// posted, so markers copied into other users' comments are ignored.
+func (g *GitHub) PostPR(ctx context.Context, pr int, body string, findings []Finding) (CommentResult, error) {
+	var res CommentResult
+	self, err := g.login(ctx)
+	if err != nil {
+		return res, err
+	}
+	if res.CommentURL, res.Updated, err = g.upsertComment(ctx, pr, self, body); err != nil {
+		return res, err
+	}
+
+	var inline []Finding
+	for _, f := range findings {
+		if f.Status != StatusResolved && f.File != "" && f.Line > 0 {
+			inline = append(inline, f)
+		}
+	}
+	if len(inline) == 0 {
+		return res, nil
+	}
+
+	var pull struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := g.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", g.Repo, pr), nil, &pull); err != nil {
+		return res, err
+	}
+	var existing []githubComment
+	if err := g.list(ctx, fmt.Sprintf("/repos/%s/pulls/%d/comments", g.Repo, pr), &existing); err != nil {
+		return res, err
+	}
+	posted := map[string]bool{}
+	for _, c := range existing {
+		if c.User.Login != self {
+			continue
+		}
+		if i := strings.Index(c.Body, findingMarkerPrefix); i >= 0 {
+			fp, _, _ := strings.Cut(c.Body[i+len(findingMarkerPrefix):], " -->")
+			posted[fp] = true
+		}
+	}
+
+	for _, f := range inline {
+		if posted[f.Fingerprint] {
+			res.Existing++
+			continue
+		}
+		comment := map[string]any{
+			"body":      reviewComment(f),
+			"commit_id": pull.Head.SHA,
+			"path":      f.File,
+			"line":      f.Line,
+			"side":      "RIGHT",
+		}
+		err := g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/comments", g.Repo, pr), comment, nil)
+		var apiErr *apiError
+		switch {
+		case errors.As(err, &apiErr) && apiErr.code == http.StatusUnprocessableEntity:
+			// GitHub rejects review comments on lines the PR does not change.
+			res.OutsideDiff++
+		case err != nil:
+			return res, err
+		default:
+			res.Inline++
+			posted[f.Fingerprint] = true
+		}
+	}
+	return res, nil
+}
+
+// upsertComment edits the comment of pr by self that contains Marker, or
+// creates one.
+func (g *GitHub) upsertComment(ctx context.Context, pr int, self, body string) (url string, updated bool, err error) {
+	var comments []githubComment
+	if err := g.list(ctx, fmt.Sprintf("/repos/%s/issues/%d/comments", g.Repo, pr), &comments); err != nil {
+		return "", false, err
+	}
+	payload := map[string]string{"body": body}
+	var c githubComment
+	for _, existing := range comments {
+		if existing.User.Login == self && strings.Contains(existing.Body, Marker) {
+			err := g.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", g.Repo, existing.ID), payload, &c)
+			return c.HTMLURL, true, err
+		}
+	}
+	err = g.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", g.Repo, pr), payload, &c)
+	return c.HTMLURL, false, err
+}
+
+// login returns the login of the authenticated user. Installation tokens,
+// such as the GITHUB_TOKEN of GitHub Actions, are refused by /user and
+// comment as actionsLogin.
+func (g *GitHub) login(ctx context.Context) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	err := g.do(ctx, http.MethodGet, "/user", nil, &user)
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.code == http.StatusForbidden:
+		return actionsLogin, nil
+	case err != nil:
+		return "", err
+	case user.Login == "":
+		return "", errors.New("GET /user: GitHub returned no login")
+	}
+	return user.Login, nil
+}
+
+// reviewComment renders a finding as a review comment.
+func reviewComment(f Finding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**", strings.ToUpper(cmp.Or(f.Severity, "info")))
+	if f.Rule != "" {
+		fmt.Fprintf(&b, " `%s`", f.Rule)
+	}
+	fmt.Fprintf(&b, " %s\n", f.Message)
+	if f.Hint != "" {
+		fmt.Fprintf(&b, "\n💡 %s\n", f.Hint)
+	}
+	fmt.Fprintf(&b, "\n<sub>gatekeeper · %s</sub>\n%s%s -->", f.Gate, findingMarkerPrefix, f.Fingerprint)
+	return b.String()
+}
+
+// list GETs all pages of a list endpoint into out.
+func (g *GitHub) list(ctx context.Context, path string, out *[]githubComment) error {
+	for page := 1; ; page++ {
+		var batch []githubComment
+		if err := g.do(ctx, http.MethodGet, fmt.Sprintf("%s?per_page=100&page=%d", path, page), nil, &batch); err != nil {
+			return err
+		}
+		*out = append(*out, batch...)
+		if len(batch) < 100 {
+			return nil
+		}
+	}
+}
+
+// do sends a JSON request to the API and decodes the response into out, if
+// not nil.
+func (g *GitHub) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encoding GitHub request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, githubTimeout)
+	defer cancel()
+	api := g.API
+	if api == "" {
+		api = DefaultGitHubAPI
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(api, "/")+path, body)
+	if err != nil {
+		return fmt.Errorf("creating GitHub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+g.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &apiError{method: method, path: path, status: resp.Status, code: resp.StatusCode, msg: strings.TrimSpace(string(msg))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding GitHub response to %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// fakeGitHub serves the pull request endpoints PostPR uses.
+type fakeGitHub struct {
+	mu       sync.Mutex
+	comments []githubComment // issue comments
+	reviews  []githubComment // review comments
+	auth     string
+	// login is the authenticated user; empty answers /user with 403, as for
+	// an installation token.
+	login string
+}
+
+// comment returns a comment by login.
+func comment(id int64, login, body string) githubComment {
+	c := githubComment{ID: id, Body: body, HTMLURL: fmt.Sprintf("https://github.test/o/r/pull/7#c%d", id)}
+	c.User.Login = login
+	return c
+}
+
+// self returns the login comments are posted as.
+func (f *fakeGitHub) self() string {
+	if f.login == "" {
+		return actionsLogin
+	}
+	return f.login
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	var in struct {
+		Body string `json:"body"`
+		Line int    `json:"line"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&in)
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/user":
+		if f.login == "" {
+			http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"login":%q}`, f.login)
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/issues/7/comments":
+		_ = json.NewEncoder(w).Encode(f.comments)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues/7/comments":
+		c := comment(int64(len(f.comments)+1), f.self(), in.Body)
+		f.comments = append(f.comments, c)
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/o/r/issues/comments/"):
+		id, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/repos/o/r/issues/comments/"), 10, 64)
+		c := &f.comments[id-1]
+		c.Body = in.Body
+		_ = json.NewEncoder(w).Encode(c)
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7":
+		fmt.Fprint(w, `{"head":{"sha":"abc123"}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7/comments":
+		_ = json.NewEncoder(w).Encode(f.reviews)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/pulls/7/comments":
+		if in.Line > 100 {
+			http.Error(w, `{"message":"line must be part of the diff"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		f.reviews = append(f.reviews, comment(int64(len(f.reviews)+1), f.self(), in.Body))
+		fmt.Fprint(w, `{}`)
+	default:
+		http.Error(w, "unexpected "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+func TestGitHub_PostPR(t *testing.T) {
+	fake := &fakeGitHub{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	gh := &GitHub{API: srv.URL, Repo: "o/r", Token: "t0ken"}
+
+	findings := Thread(nil, runWith(
+		parser.StructuredError{File: "a.go", Line: 3, Severity: "error", Rule: "G101", Message: "hardcoded credential", Hint: "Use env vars."},
+		parser.StructuredError{File: "b.go", Line: 300, Severity: "warning", Message: "unchanged line"},
+		parser.StructuredError{Severity: "warning", Message: "no location"},
+	))
+
+	res, err := gh.PostPR(context.Background(), 7, Marker+"\nfirst", findings)
+	if err != nil {
+		t.Fatalf("PostPR: %v", err)
+	}
+	if res.Updated || res.CommentURL == "" || res.Inline != 1 || res.OutsideDiff != 1 || res.Existing != 0 {
+		t.Errorf("unexpected first result %+v", res)
+	}
+	if fake.auth != "Bearer t0ken" {
+		t.Errorf("expected the token to be sent, got %q", fake.auth)
+	}
+	if len(fake.reviews) != 1 || !strings.Contains(fake.reviews[0].Body, "**ERROR** `G101` hardcoded credential") {
+		t.Errorf("unexpected review comments %+v", fake.reviews)
+	}
+
+	res, err = gh.PostPR(context.Background(), 7, Marker+"\nsecond", findings)
+	if err != nil {
+		t.Fatalf("PostPR again: %v", err)
+	}
+	if !res.Updated || res.Inline != 0 || res.Existing != 1 {
+		t.Errorf("expected the comment to be updated and the finding not reposted, got %+v", res)
+	}
+	if len(fake.comments) != 1 || fake.comments[0].Body != Marker+"\nsecond" {
+		t.Errorf("expected one updated summary comment, got %+v", fake.comments)
+	}
+}
+
+func TestGitHub_PostPR_IgnoresOtherAuthors(t *testing.T) {
+	fake := &fakeGitHub{
+		login:    "gatekeeper-bot",
+		comments: []githubComment{comment(1, "mallory", Marker+"\nspoofed")},
+		reviews:  []githubComment{comment(1, "mallory", findingMarkerPrefix+"x -->")},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	gh := &GitHub{API: srv.URL, Repo: "o/r"}
+
+	findings := Thread(nil, runWith(parser.StructuredError{File: "a.go", Line: 3, Severity: "error", Message: "issue"}))
+	for i := range findings {
+		findings[i].Fingerprint = "x"
+	}
+	res, err := gh.PostPR(context.Background(), 7, Marker+"\nreport", findings)
+	if err != nil {
+		t.Fatalf("PostPR: %v", err)
+	}
+	if res.Updated || res.Inline != 1 || res.Existing != 0 {
+		t.Errorf("expected other users' markers to be ignored, got %+v", res)
+	}
+	if fake.comments[0].Body != Marker+"\nspoofed" || len(fake.comments) != 2 || fake.comments[1].User.Login != "gatekeeper-bot" {
+		t.Errorf("expected a new comment and the other user's left alone, got %+v", fake.comments)
+	}
+
+	// A re-run edits the comment it posted.
+	if res, err = gh.PostPR(context.Background(), 7, Marker+"\nagain", nil); err != nil || !res.Updated {
+		t.Fatalf("expected the own comment to be updated, got %+v, %v", res, err)
+	}
+	if fake.comments[1].Body != Marker+"\nagain" {
+		t.Errorf("expected the own comment edited, got %+v", fake.comments)
+	}
+}
+
+func TestGitHub_PostPRError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := (&GitHub{API: srv.URL, Repo: "o/r"}).PostPR(context.Background(), 7, Marker, nil)
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("expected the API error, got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+// Marker is embedded in every report so CI scripts can find and update the
+// previously posted comment instead of adding a new one. It is the marker of
+// "--format markdown" output, so either can update the other's comment.
+const Marker = formatter.MarkdownMarker
+
+// Markdown renders a run and its threaded findings as a PR comment.
+func Markdown(result formatter.RunResult, findings []Finding) string {
+	var b strings.Builder
+	b.WriteString(Marker + "\n")
+
+	if result.Passed {
+		fmt.Fprintf(&b, "## ✅ Gatekeeper passed in %dms\n\n", result.DurationMs)
+	} else {
+		fmt.Fprintf(&b, "## ❌ Gatekeeper failed in %dms\n\n", result.DurationMs)
+	}
+
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Status]++
+	}
+	fmt.Fprintf(&b, "🆕 %d new · 🔁 %d unchanged · ✔️ %d resolved since the last report\n\n",
+		counts[StatusNew], counts[StatusUnchanged], counts[StatusResolved])
+
+	b.WriteString("| Gate | Result | Duration |\n|---|---|---|\n")
+	for _, g := range result.Gates {
+		fmt.Fprintf(&b, "| %s | %s | %dms |\n", formatter.MarkdownCell(g.Name), formatter.MarkdownStatus(g), g.DurationMs)
+	}
+
+	writeFindings(&b, "Findings", findings, false)
+	writeFindings(&b, "Resolved", findings, true)
+	return b.String()
+}
+
+// writeFindings writes a table of open (resolved=false) or resolved findings.
+func writeFindings(b *strings.Builder, title string, findings []Finding, resolved bool) {
+	var rows []Finding
+	for _, f := range findings {
+		if (f.Status == StatusResolved) == resolved {
+			rows = append(rows, f)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s (%d)\n\n| | Gate | Location | Message |\n|---|---|---|---|\n", title, len(rows))
+	for _, f := range rows {
+		msg := formatter.MarkdownCell(f.Message)
+		if f.Rule != "" {
+			msg = fmt.Sprintf("`%s` %s", formatter.MarkdownCell(f.Rule), msg)
+		}
+		if resolved {
+			msg = "~~" + msg + "~~"
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", statusIcon(f.Status), formatter.MarkdownCell(f.Gate), location(f), msg)
+	}
+}
+
+// statusIcon renders a finding's thread status.
+func statusIcon(status string) string {
+	switch status {
+	case StatusNew:
+		return "🆕 new"
+	case StatusResolved:
+		return "✔️ resolved"
+	default:
+		return "🔁 unchanged"
+	}
+}
+
+// location renders "`file:line`" for a finding, or "" if it has no file.
+func location(f Finding) string {
+	if f.File == "" {
+		return ""
+	}
+	if f.Line > 0 {
+		return fmt.Sprintf("`%s:%d`", f.File, f.Line)
+	}
+	return "`" + f.File + "`"
+}
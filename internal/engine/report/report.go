@@ -0,0 +1,134 @@
+// Package report renders run results as markdown for PR comments and threads
+// findings across repeated reports for the same branch.
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/triage"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// Status of a finding relative to the previously posted report.
+const (
+	StatusNew       = "new"
+	StatusUnchanged = "unchanged"
+	StatusResolved  = "resolved"
+)
+
+// Finding is a finding of a report with its thread status.
+type Finding struct {
+	Fingerprint string `json:"fingerprint"`
+	Gate        string `json:"gate"`
+	parser.StructuredError
+	Status string `json:"status,omitempty"`
+}
+
+// State records the findings of the last report posted for a branch.
+type State struct {
+	Branch   string    `json:"branch"`
+	PostedAt time.Time `json:"posted_at"`
+	Findings []Finding `json:"findings"`
+}
+
+// unsafeBranchChars matches characters replaced when deriving a file name from a branch.
+var unsafeBranchChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// StatePath returns the report state file for a branch inside the results directory.
+func StatePath(resultsDir, branch string) string {
+	name := unsafeBranchChars.ReplaceAllString(branch, "_")
+	if name == "" {
+		name = "detached"
+	}
+	return filepath.Join(resultsDir, "report-"+name+".json")
+}
+
+// LoadState reads the report state at path. A missing file yields nil (first report).
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the state to path.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding report state: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Thread compares the active findings of result with the previously posted report.
+// Findings are returned new first, then unchanged, then resolved (present in prev
+// but gone now). prev may be nil for the first report of a branch.
+func Thread(prev *State, result formatter.RunResult) []Finding {
+	seen := map[string]bool{}
+	if prev != nil {
+		for _, f := range prev.Findings {
+			seen[f.Fingerprint] = true
+		}
+	}
+
+	var current []Finding
+	open := map[string]bool{}
+	for _, g := range result.Gates {
+		for _, e := range g.Errors {
+			f := Finding{Fingerprint: triage.Fingerprint(g.Name, e), Gate: g.Name, StructuredError: e, Status: StatusNew}
+			if seen[f.Fingerprint] {
+				f.Status = StatusUnchanged
+			}
+			open[f.Fingerprint] = true
+			current = append(current, f)
+		}
+	}
+
+	if prev != nil {
+		for _, f := range prev.Findings {
+			if !open[f.Fingerprint] {
+				f.Status = StatusResolved
+				current = append(current, f)
+				open[f.Fingerprint] = true // report duplicates once
+			}
+		}
+	}
+
+	order := map[string]int{StatusNew: 0, StatusUnchanged: 1, StatusResolved: 2}
+	sort.SliceStable(current, func(i, j int) bool { return order[current[i].Status] < order[current[j].Status] })
+	return current
+}
+
+// NextState returns the state to save after posting findings: only findings that
+// are still open are kept, so resolved ones are reported once.
+func NextState(branch string, findings []Finding, now time.Time) *State {
+	s := &State{Branch: branch, PostedAt: now, Findings: []Finding{}}
+	for _, f := range findings {
+		if f.Status != StatusResolved {
+			f.Status = ""
+			s.Findings = append(s.Findings, f)
+		}
+	}
+	return s
+}
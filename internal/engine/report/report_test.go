@@ -0,0 +1,100 @@
+package report
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+func runWith(errs ...parser.StructuredError) formatter.RunResult {
+	return formatter.RunResult{
+		Passed: len(errs) == 0,
+		Gates:  []formatter.GateResult{{Name: "lint", Passed: len(errs) == 0, Blocking: true, Errors: errs}},
+	}
+}
+
+func TestThread(t *testing.T) {
+	kept := parser.StructuredError{File: "a.go", Line: 3, Message: "unused variable", Severity: "error"}
+	fixed := parser.StructuredError{File: "b.go", Line: 9, Message: "shadowed err", Severity: "error"}
+	added := parser.StructuredError{File: "c.go", Line: 1, Message: "missing doc", Severity: "warning"}
+
+	first := Thread(nil, runWith(kept, fixed))
+	for _, f := range first {
+		if f.Status != StatusNew {
+			t.Errorf("expected all findings new on the first report, got %+v", f)
+		}
+	}
+	prev := NextState("feature/x", first, time.Now())
+
+	// kept moved to another line: still unchanged.
+	kept.Line = 5
+	got := Thread(prev, runWith(kept, added))
+	want := []struct{ file, status string }{{"c.go", StatusNew}, {"a.go", StatusUnchanged}, {"b.go", StatusResolved}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].File != w.file || got[i].Status != w.status {
+			t.Errorf("finding %d: expected %s %s, got %s %s", i, w.file, w.status, got[i].File, got[i].Status)
+		}
+	}
+
+	// Resolved findings are reported once.
+	next := NextState("feature/x", got, time.Now())
+	if len(next.Findings) != 2 {
+		t.Errorf("expected resolved finding dropped from state, got %+v", next.Findings)
+	}
+	if again := Thread(next, runWith(kept, added)); len(again) != 2 || again[0].Status != StatusUnchanged {
+		t.Errorf("expected only unchanged findings, got %+v", again)
+	}
+}
+
+func TestState_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := StatePath(dir, "feature/login fix")
+	if filepath.Base(path) != "report-feature_login_fix.json" {
+		t.Errorf("unexpected state path %q", path)
+	}
+
+	s, err := LoadState(path)
+	if err != nil || s != nil {
+		t.Fatalf("expected no state, got %+v, %v", s, err)
+	}
+
+	in := NextState("feature/login fix", Thread(nil, runWith(parser.StructuredError{File: "a.go", Message: "m"})), time.Now())
+	if err := in.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	s, err = LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if s.Branch != "feature/login fix" || len(s.Findings) != 1 || s.Findings[0].File != "a.go" {
+		t.Errorf("unexpected state: %+v", s)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	result := runWith(parser.StructuredError{File: "a.go", Line: 3, Rule: "U1000", Message: "a | b", Severity: "error"})
+	findings := Thread(&State{Findings: []Finding{{Fingerprint: "gone", Gate: "lint", StructuredError: parser.StructuredError{File: "old.go", Message: "fixed"}}}}, result)
+
+	md := Markdown(result, findings)
+	for _, want := range []string{
+		Marker,
+		"## ❌ Gatekeeper failed",
+		"🆕 1 new · 🔁 0 unchanged · ✔️ 1 resolved",
+		"| lint | ❌ failed |",
+		"### Findings (1)",
+		"| 🆕 new | lint | `a.go:3` | `U1000` a \\| b |",
+		"### Resolved (1)",
+		"| ✔️ resolved | lint | `old.go` | ~~fixed~~ |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+}
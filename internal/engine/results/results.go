@@ -0,0 +1,450 @@
+// Package results persists run results under .gatekeeper/results/.
+package results
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/coverage"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// ErrNoResults is returned when no run has been recorded yet.
+var ErrNoResults = errors.New("no recorded run found (run 'gatekeeper run' first)")
+
+// lastFile is the name of the file holding the most recent run.
+const lastFile = "last.json"
+
+// historyDir holds a copy of each recent run, named by when it was recorded.
+const historyDir = "history"
+
+// historyTimeFormat names history files; it sorts chronologically as a string.
+const historyTimeFormat = "20060102T150405.000000000Z"
+
+// ErrRunNotFound is returned when no recorded run matches an ID.
+var ErrRunNotFound = errors.New("no recorded run with that ID (see 'gatekeeper history list')")
+
+// Retention limits the runs kept in history. Zero values leave a limit off.
+type Retention struct {
+	// MaxRuns is the number of runs kept; older ones are removed.
+	MaxRuns int
+	// MaxAge is how long a run is kept.
+	MaxAge time.Duration
+}
+
+// DefaultRetention keeps the last 100 runs of the last 30 days.
+var DefaultRetention = Retention{MaxRuns: 100, MaxAge: 30 * 24 * time.Hour}
+
+// Store reads and writes run results in a directory.
+// The directory ignores itself in git so results never end up in a stash or commit.
+type Store struct {
+	dir       string
+	now       func() time.Time
+	retention Retention
+}
+
+// NewStore creates a Store rooted at dir (usually .gatekeeper/results) that
+// keeps runs for DefaultRetention.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, now: time.Now, retention: DefaultRetention}
+}
+
+// SetRetention sets the limits history is pruned to after each recorded run.
+func (s *Store) SetRetention(r Retention) {
+	s.retention = r
+}
+
+// Run is a recorded run and when it was recorded.
+type Run struct {
+	// ID names the run in history; it is the UTC time it was recorded.
+	ID     string
+	At     time.Time
+	Result formatter.RunResult
+}
+
+// DefaultDir returns the results directory for a project.
+func DefaultDir(projectDir string) string {
+	return filepath.Join(projectDir, ".gatekeeper", "results")
+}
+
+// SaveLast records result as the most recent run.
+func (s *Store) SaveLast(result formatter.RunResult) error {
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding run result: %w", err)
+	}
+
+	path := filepath.Join(s.dir, lastFile)
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return s.appendHistory(data)
+}
+
+// appendHistory records an encoded run in history and prunes the runs the
+// retention limits no longer keep.
+func (s *Store) appendHistory(data []byte) error {
+	dir := filepath.Join(s.dir, historyDir)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("creating history directory: %w", err)
+	}
+	path := filepath.Join(dir, s.now().UTC().Format(historyTimeFormat)+".json")
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	_, err := s.Prune()
+	return err
+}
+
+// Prune removes the runs beyond the retention limits: the oldest runs beyond
+// MaxRuns and the runs older than MaxAge. The most recent run is always kept.
+// It returns the number of runs removed.
+func (s *Store) Prune() (int, error) {
+	names, err := s.historyNames()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Time{}
+	if s.retention.MaxAge > 0 {
+		cutoff = s.now().Add(-s.retention.MaxAge)
+	}
+
+	removed := 0
+	for i, name := range names {
+		if i == len(names)-1 {
+			break
+		}
+		tooMany := s.retention.MaxRuns > 0 && len(names)-i > s.retention.MaxRuns
+		at, err := time.Parse(historyTimeFormat, strings.TrimSuffix(name, ".json"))
+		tooOld := err == nil && at.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, historyDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("pruning history: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// historyNames returns the history file names, oldest first.
+func (s *Store) historyNames() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, historyDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// History returns up to limit recorded runs, newest first (all of them if limit
+// is 0). Unreadable entries are skipped.
+func (s *Store) History(limit int) ([]Run, error) {
+	names, err := s.historyNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+	for i := len(names) - 1; i >= 0 && (limit == 0 || len(runs) < limit); i-- {
+		run, err := s.readRun(names[i])
+		if err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Run returns the recorded run whose ID starts with id. It returns
+// ErrRunNotFound if no run matches, and an error if several do.
+func (s *Store) Run(id string) (Run, error) {
+	names, err := s.historyNames()
+	if err != nil {
+		return Run{}, err
+	}
+	var matches []string
+	for _, name := range names {
+		if id != "" && strings.HasPrefix(name, id) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Run{}, fmt.Errorf("%w: %q", ErrRunNotFound, id)
+	case 1:
+		return s.readRun(matches[0])
+	default:
+		return Run{}, fmt.Errorf("run ID %q is ambiguous: it matches %d runs", id, len(matches))
+	}
+}
+
+// readRun reads the history file called name.
+func (s *Store) readRun(name string) (Run, error) {
+	id := strings.TrimSuffix(name, ".json")
+	at, err := time.Parse(historyTimeFormat, id)
+	if err != nil {
+		return Run{}, fmt.Errorf("history file %s: %w", name, err)
+	}
+	path := filepath.Join(s.dir, historyDir, name)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		return Run{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var result formatter.RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return Run{}, fmt.Errorf("%s is unreadable: %w", path, err)
+	}
+	return Run{ID: id, At: at, Result: result}, nil
+}
+
+// LoadLast returns the most recent run. Returns ErrNoResults if none is recorded.
+func (s *Store) LoadLast() (*formatter.RunResult, error) {
+	path := filepath.Join(s.dir, lastFile)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoResults
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var result formatter.RunResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("%w: %s is unreadable (%v) and was moved to %s", ErrNoResults, path, err, quarantine(path))
+	}
+	return &result, nil
+}
+
+// LastRunAt returns when the most recent run was recorded. Returns ErrNoResults if none is recorded.
+func (s *Store) LastRunAt() (time.Time, error) {
+	path := filepath.Join(s.dir, lastFile)
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, ErrNoResults
+		}
+		return time.Time{}, fmt.Errorf("checking %s: %w", path, err)
+	}
+	return info.ModTime(), nil
+}
+
+// ensureDir creates the results directory with a catch-all .gitignore, so
+// "git stash -u" and "git clean" leave recorded results alone.
+func (s *Store) ensureDir() error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("creating results directory: %w", err)
+	}
+	ignore := filepath.Join(s.dir, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0o644); err != nil { // #nosec G306 -- gitignore, not sensitive
+			return fmt.Errorf("writing results .gitignore: %w", err)
+		}
+	}
+	return nil
+}
+
+// latencyFile holds recent LLM provider latencies.
+const latencyFile = "latency.json"
+
+// latencySamples is the number of recent samples kept per provider.
+const latencySamples = 20
+
+// minLatencySamples is the number of samples needed before a median is reported.
+const minLatencySamples = 3
+
+// RecordLatency appends a latency sample for an LLM provider, keeping the most recent ones.
+func (s *Store) RecordLatency(provider string, d time.Duration) error {
+	samples, err := s.loadLatencies()
+	if err != nil {
+		return err
+	}
+
+	list := append(samples[provider], d.Milliseconds())
+	if len(list) > latencySamples {
+		list = list[len(list)-latencySamples:]
+	}
+	samples[provider] = list
+
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(samples, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding latencies: %w", err)
+	}
+	path := filepath.Join(s.dir, latencyFile)
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// MedianLatency returns the median recorded latency of an LLM provider.
+// ok is false if fewer than minLatencySamples are recorded or history is unreadable.
+func (s *Store) MedianLatency(provider string) (time.Duration, bool) {
+	samples, err := s.loadLatencies()
+	if err != nil || len(samples[provider]) < minLatencySamples {
+		return 0, false
+	}
+
+	list := append([]int64(nil), samples[provider]...)
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	mid := len(list) / 2
+	median := list[mid]
+	if len(list)%2 == 0 {
+		median = (list[mid-1] + list[mid]) / 2
+	}
+	return time.Duration(median) * time.Millisecond, true
+}
+
+// loadLatencies reads latency samples (milliseconds) keyed by provider.
+func (s *Store) loadLatencies() (map[string][]int64, error) {
+	path := filepath.Join(s.dir, latencyFile)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string][]int64{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	samples := map[string][]int64{}
+	if err := json.Unmarshal(data, &samples); err != nil {
+		// Start a fresh history rather than failing every run.
+		quarantine(path)
+		return map[string][]int64{}, nil
+	}
+	return samples, nil
+}
+
+// coverageFile holds the coverage baselines of coverage gates.
+const coverageFile = "coverage.json"
+
+// LoadCoverageBaseline returns the coverage baseline of a coverage gate, or
+// nil if it has none yet.
+func (s *Store) LoadCoverageBaseline(gate string) (coverage.Baseline, error) {
+	baselines, err := s.loadCoverageBaselines()
+	if err != nil {
+		return nil, err
+	}
+	return baselines[gate], nil
+}
+
+// SaveCoverageBaseline records the coverage baseline of a coverage gate.
+func (s *Store) SaveCoverageBaseline(gate string, b coverage.Baseline) error {
+	baselines, err := s.loadCoverageBaselines()
+	if err != nil {
+		return err
+	}
+	baselines[gate] = b
+
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(baselines, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding coverage baselines: %w", err)
+	}
+	path := filepath.Join(s.dir, coverageFile)
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadCoverageBaselines reads the coverage baselines keyed by gate.
+func (s *Store) loadCoverageBaselines() (map[string]coverage.Baseline, error) {
+	path := filepath.Join(s.dir, coverageFile)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]coverage.Baseline{}, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	baselines := map[string]coverage.Baseline{}
+	if err := json.Unmarshal(data, &baselines); err != nil {
+		// Start fresh baselines rather than failing every run.
+		quarantine(path)
+		return map[string]coverage.Baseline{}, nil
+	}
+	return baselines, nil
+}
+
+// configSnapshotFile holds the gates of the last run, for change summaries.
+const configSnapshotFile = "config.json"
+
+// ConfigSnapshot is the gate configuration seen by the last run.
+type ConfigSnapshot struct {
+	Hash  string        `json:"hash"`
+	Gates []config.Gate `json:"gates"`
+}
+
+// LoadConfigSnapshot returns the snapshot saved by the last run, or nil if there is none.
+func (s *Store) LoadConfigSnapshot() (*ConfigSnapshot, error) {
+	path := filepath.Join(s.dir, configSnapshotFile)
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var snap ConfigSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		// Treat an unreadable snapshot like a first run.
+		quarantine(path)
+		return nil, nil
+	}
+	return &snap, nil
+}
+
+// quarantine moves an unreadable file (e.g., torn by a crash before writes were
+// atomic) to <path>.corrupt for inspection, so the next write starts fresh.
+// It returns the new path.
+func quarantine(path string) string {
+	dest := path + ".corrupt"
+	_ = os.Rename(path, dest)
+	return dest
+}
+
+// SaveConfigSnapshot records the gate configuration of the current run.
+func (s *Store) SaveConfigSnapshot(snap ConfigSnapshot) error {
+	if err := s.ensureDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding config snapshot: %w", err)
+	}
+	path := filepath.Join(s.dir, configSnapshotFile)
+	if err := fileutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
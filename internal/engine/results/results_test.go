@@ -0,0 +1,262 @@
+package results
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/config"
+	"github.com/irahardianto/gatekeeper/internal/engine/coverage"
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+func TestStore_SaveAndLoadLast(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	s := NewStore(dir)
+
+	in := formatter.RunResult{Passed: true, DurationMs: 42, Gates: []formatter.GateResult{{Name: "lint", Passed: true}}}
+	if err := s.SaveLast(in); err != nil {
+		t.Fatalf("SaveLast: %v", err)
+	}
+
+	out, err := s.LoadLast()
+	if err != nil {
+		t.Fatalf("LoadLast: %v", err)
+	}
+	if out.DurationMs != 42 || len(out.Gates) != 1 || out.Gates[0].Name != "lint" {
+		t.Errorf("unexpected result: %+v", out)
+	}
+
+	if at, err := s.LastRunAt(); err != nil || time.Since(at) > time.Minute {
+		t.Errorf("expected recent LastRunAt, got %v (%v)", at, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil || string(data) != "*\n" {
+		t.Errorf("expected self-ignoring results dir, got %q (%v)", data, err)
+	}
+}
+
+func TestStore_LoadLast_NoResults(t *testing.T) {
+	_, err := NewStore(t.TempDir()).LoadLast()
+	if !errors.Is(err, ErrNoResults) {
+		t.Fatalf("expected ErrNoResults, got %v", err)
+	}
+	if _, err := NewStore(t.TempDir()).LastRunAt(); !errors.Is(err, ErrNoResults) {
+		t.Errorf("expected ErrNoResults from LastRunAt, got %v", err)
+	}
+}
+
+func TestStore_MedianLatency(t *testing.T) {
+	s := NewStore(t.TempDir())
+
+	for _, ms := range []int{100, 300} {
+		if err := s.RecordLatency("gemini", time.Duration(ms)*time.Millisecond); err != nil {
+			t.Fatalf("RecordLatency: %v", err)
+		}
+	}
+	if _, ok := s.MedianLatency("gemini"); ok {
+		t.Error("expected no median with too few samples")
+	}
+
+	if err := s.RecordLatency("gemini", 200*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := s.MedianLatency("gemini"); !ok || m != 200*time.Millisecond {
+		t.Errorf("expected 200ms median, got %v (%v)", m, ok)
+	}
+
+	if err := s.RecordLatency("gemini", 1000*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := s.MedianLatency("gemini"); m != 250*time.Millisecond {
+		t.Errorf("expected 250ms median for even sample count, got %v", m)
+	}
+	if _, ok := s.MedianLatency("other"); ok {
+		t.Error("expected no median for unknown provider")
+	}
+}
+
+func TestStore_RecordLatency_KeepsRecentSamples(t *testing.T) {
+	s := NewStore(t.TempDir())
+	for i := 0; i < latencySamples+5; i++ {
+		if err := s.RecordLatency("gemini", time.Duration(i)*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+	samples, err := s.loadLatencies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples["gemini"]) != latencySamples || samples["gemini"][0] != 5 {
+		t.Errorf("expected the %d most recent samples, got %v", latencySamples, samples["gemini"])
+	}
+}
+
+func TestStore_ConfigSnapshot(t *testing.T) {
+	s := NewStore(t.TempDir())
+
+	snap, err := s.LoadConfigSnapshot()
+	if err != nil || snap != nil {
+		t.Fatalf("expected no snapshot, got %+v, %v", snap, err)
+	}
+
+	in := ConfigSnapshot{Hash: "abc", Gates: []config.Gate{{Name: "lint", Timeout: time.Minute}}}
+	if err := s.SaveConfigSnapshot(in); err != nil {
+		t.Fatalf("SaveConfigSnapshot: %v", err)
+	}
+	snap, err = s.LoadConfigSnapshot()
+	if err != nil {
+		t.Fatalf("LoadConfigSnapshot: %v", err)
+	}
+	if snap.Hash != "abc" || len(snap.Gates) != 1 || snap.Gates[0].Timeout != time.Minute {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestStore_RecoversTornFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStore(dir)
+	for _, name := range []string{lastFile, latencyFile, configSnapshotFile} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(`{"passed": tr`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.LoadLast(); !errors.Is(err, ErrNoResults) {
+		t.Errorf("expected torn last run to read as ErrNoResults, got %v", err)
+	}
+	if err := s.RecordLatency("gemini", time.Second); err != nil {
+		t.Errorf("expected torn latency history to be replaced, got %v", err)
+	}
+	if snap, err := s.LoadConfigSnapshot(); snap != nil || err != nil {
+		t.Errorf("expected torn snapshot to read as a first run, got %+v (%v)", snap, err)
+	}
+
+	for _, name := range []string{lastFile, latencyFile, configSnapshotFile} {
+		if _, err := os.Stat(filepath.Join(dir, name+".corrupt")); err != nil {
+			t.Errorf("expected %s to be kept as %s.corrupt: %v", name, name, err)
+		}
+	}
+	if err := s.SaveLast(formatter.RunResult{Passed: true}); err != nil {
+		t.Fatal(err)
+	}
+	if last, err := s.LoadLast(); err != nil || !last.Passed {
+		t.Errorf("expected a fresh run to be readable, got %+v (%v)", last, err)
+	}
+}
+
+func TestStore_History(t *testing.T) {
+	const maxHistory = 20
+	s := NewStore(filepath.Join(t.TempDir(), "results"))
+	s.SetRetention(Retention{MaxRuns: maxHistory})
+	if runs, err := s.History(0); err != nil || len(runs) != 0 {
+		t.Fatalf("expected empty history, got %v (%v)", runs, err)
+	}
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < maxHistory+3; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		s.now = func() time.Time { return at }
+		if err := s.SaveLast(formatter.RunResult{Passed: i%2 == 0, DurationMs: int64(i)}); err != nil {
+			t.Fatalf("SaveLast: %v", err)
+		}
+	}
+
+	runs, err := s.History(0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(runs) != maxHistory {
+		t.Fatalf("expected %d runs kept, got %d", maxHistory, len(runs))
+	}
+	newest := start.Add(time.Duration(maxHistory+2) * time.Minute)
+	if !runs[0].At.Equal(newest) || runs[0].Result.DurationMs != maxHistory+2 {
+		t.Errorf("expected newest run first, got %v %+v", runs[0].At, runs[0].Result)
+	}
+	if runs[len(runs)-1].Result.DurationMs != 3 {
+		t.Errorf("expected oldest runs pruned, oldest kept is %d", runs[len(runs)-1].Result.DurationMs)
+	}
+
+	if runs, _ := s.History(3); len(runs) != 3 {
+		t.Errorf("expected limit to apply, got %d runs", len(runs))
+	}
+}
+
+func TestStore_PruneByAge(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "results"))
+	s.SetRetention(Retention{MaxAge: 24 * time.Hour})
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{start, start.Add(12 * time.Hour), start.Add(30 * time.Hour)} {
+		s.now = func() time.Time { return at }
+		if err := s.SaveLast(formatter.RunResult{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, _ := s.History(0)
+	if len(runs) != 2 || !runs[1].At.Equal(start.Add(12*time.Hour)) {
+		t.Fatalf("expected the run older than a day pruned, got %d runs", len(runs))
+	}
+
+	// The most recent run is kept however old it is.
+	s.now = func() time.Time { return start.Add(30 * 24 * time.Hour) }
+	removed, err := s.Prune()
+	if err != nil || removed != 1 {
+		t.Fatalf("Prune = %d, %v; want 1, nil", removed, err)
+	}
+	if runs, _ := s.History(0); len(runs) != 1 {
+		t.Errorf("expected the newest run kept, got %d runs", len(runs))
+	}
+}
+
+func TestStore_Run(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "results"))
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, at := range []time.Time{start, start.Add(time.Second), start.Add(time.Hour)} {
+		s.now = func() time.Time { return at }
+		if err := s.SaveLast(formatter.RunResult{DurationMs: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, _ := s.History(0)
+	run, err := s.Run(runs[0].ID)
+	if err != nil || run.Result.DurationMs != 2 || !run.At.Equal(start.Add(time.Hour)) {
+		t.Fatalf("Run(%s) = %+v, %v", runs[0].ID, run, err)
+	}
+	if run, err := s.Run("20260301T10"); err != nil || run.Result.DurationMs != 2 {
+		t.Errorf("expected a unique prefix to match, got %+v, %v", run, err)
+	}
+	if _, err := s.Run("20260301T0900"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected an ambiguous prefix to fail, got %v", err)
+	}
+	for _, id := range []string{"2025", ""} {
+		if _, err := s.Run(id); !errors.Is(err, ErrRunNotFound) {
+			t.Errorf("Run(%q): expected ErrRunNotFound, got %v", id, err)
+		}
+	}
+}
+
+func TestStore_CoverageBaseline(t *testing.T) {
+	s := NewStore(t.TempDir())
+	if b, err := s.LoadCoverageBaseline("coverage"); err != nil || b != nil {
+		t.Fatalf("expected no baseline yet, got %v, %v", b, err)
+	}
+
+	want := coverage.Baseline{"api/api.go": {Covered: 8, Total: 10}}
+	if err := s.SaveCoverageBaseline("coverage", want); err != nil {
+		t.Fatalf("SaveCoverageBaseline: %v", err)
+	}
+	if err := s.SaveCoverageBaseline("web-coverage", coverage.Baseline{"web/app.ts": {Covered: 1, Total: 2}}); err != nil {
+		t.Fatalf("SaveCoverageBaseline: %v", err)
+	}
+	got, err := s.LoadCoverageBaseline("coverage")
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadCoverageBaseline = %v, %v; want %v", got, err, want)
+	}
+}
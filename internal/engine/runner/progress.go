@@ -12,6 +12,7 @@ import (
 type Progress struct {
 	w          io.Writer
 	suppressed bool
+	accessible bool
 	total      int
 	mu         sync.Mutex
 	completed  int
@@ -19,11 +20,13 @@ type Progress struct {
 }
 
 type gateStatus struct {
-	name     string
-	passed   bool
-	sysErr   bool
-	errMsg   string
-	duration time.Duration
+	name   string
+	passed bool
+	sysErr bool
+	// cancelled is set for gates stopped by fail-fast.
+	cancelled bool
+	errMsg    string
+	duration  time.Duration
 }
 
 // NewProgress creates a new progress tracker writing to w.
@@ -42,6 +45,14 @@ func NewProgress(w io.Writer, suppressed bool, totalGates int) *Progress {
 	return p
 }
 
+// SetAccessible pairs each progress icon with a status word, so status never
+// depends on telling emoji or colors apart.
+func (p *Progress) SetAccessible(on bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.accessible = on
+}
+
 // OnStart is called when a gate begins execution.
 func (p *Progress) OnStart(name string) {
 	if p.suppressed {
@@ -51,6 +62,10 @@ func (p *Progress) OnStart(name string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if p.accessible {
+		fmt.Fprintf(p.w, "  ⏳ started: %s\n", name)
+		return
+	}
 	fmt.Fprintf(p.w, "  ⏳ %s\n", name)
 }
 
@@ -72,17 +87,38 @@ func (p *Progress) OnComplete(name string, passed bool, sysErr bool, errMsg stri
 		duration: dur,
 	})
 
-	icon := "✅"
+	icon, word := "✅", "passed"
 	if sysErr {
-		icon = "💥"
+		icon, word = "💥", "error"
 	} else if !passed {
-		icon = "❌"
+		icon, word = "❌", "failed"
+	}
+	if p.accessible {
+		icon += " " + word + ":"
 	}
 
 	durStr := formatDuration(dur)
 	fmt.Fprintf(p.w, "  %s %s  %s\n", icon, name, durStr)
 }
 
+// OnCancel is called for a gate that fail-fast stopped or never started.
+func (p *Progress) OnCancel(name string) {
+	if p.suppressed {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.completed++
+	p.results = append(p.results, gateStatus{name: name, cancelled: true})
+	icon := "⏹️"
+	if p.accessible {
+		icon += " cancelled:"
+	}
+	fmt.Fprintf(p.w, "  %s %s\n", icon, name)
+}
+
 // Finish prints a summary line after all gates complete.
 func (p *Progress) Finish() {
 	if p.suppressed {
@@ -95,8 +131,11 @@ func (p *Progress) Finish() {
 	passed := 0
 	failed := 0
 	errors := 0
+	cancelled := 0
 	for _, r := range p.results {
 		switch {
+		case r.cancelled:
+			cancelled++
 		case r.sysErr:
 			errors++
 		case !r.passed:
@@ -110,7 +149,11 @@ func (p *Progress) Finish() {
 	if failed == 0 && errors == 0 {
 		fmt.Fprintf(p.w, "✅ All %d gate(s) passed\n", passed)
 	} else {
-		fmt.Fprintf(p.w, "Results: %d passed, %d failed, %d errors\n", passed, failed, errors)
+		summary := fmt.Sprintf("Results: %d passed, %d failed, %d errors", passed, failed, errors)
+		if cancelled > 0 {
+			summary += fmt.Sprintf(", %d cancelled", cancelled)
+		}
+		fmt.Fprintln(p.w, summary)
 	}
 }
 
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 )
@@ -89,3 +90,21 @@ func TestProgress_MultipleGates(t *testing.T) {
 		t.Error("expected progress output for multiple gates")
 	}
 }
+
+func TestProgress_Accessible(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewProgress(&buf, false, 3)
+	p.SetAccessible(true)
+
+	p.OnStart("lint")
+	p.OnComplete("lint", true, false, "", 800*time.Millisecond)
+	p.OnComplete("security", false, false, "", time.Second)
+	p.OnComplete("docker", false, true, "daemon down", time.Second)
+
+	out := buf.String()
+	for _, want := range []string{"⏳ started: lint", "✅ passed: lint", "❌ failed: security", "💥 error: docker"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
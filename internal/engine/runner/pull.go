@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+const (
+	// barWidth is the width of a pull progress bar in characters.
+	barWidth = 24
+	// redrawInterval limits how often bars are redrawn while layers download.
+	redrawInterval = 100 * time.Millisecond
+)
+
+// PullBars renders the progress of parallel image pulls. On a terminal each
+// image has a progress bar redrawn in place; elsewhere a line is printed as
+// each pull finishes, so logs stay readable.
+type PullBars struct {
+	w        io.Writer
+	tty      bool
+	images   []string
+	mu       sync.Mutex
+	state    map[string]pool.PullProgress
+	drawn    bool
+	lastDraw time.Time
+}
+
+// NewPullBars creates bars for images writing to w. tty selects in-place
+// redrawing, for w being a terminal.
+func NewPullBars(w io.Writer, images []string, tty bool) *PullBars {
+	b := &PullBars{w: w, tty: tty, images: images, state: make(map[string]pool.PullProgress)}
+	fmt.Fprintf(w, "⬇️  Pulling %d image(s)...\n", len(images))
+	return b
+}
+
+// Update records the progress of a pull. It is safe for concurrent use, so it
+// can be passed to pool.PullImages.
+func (b *PullBars) Update(p pool.PullProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state[p.Image] = p
+
+	if !b.tty {
+		if p.Done {
+			fmt.Fprintf(b.w, "  %s\n", pullLine(p))
+		}
+		return
+	}
+	if !p.Done && time.Since(b.lastDraw) < redrawInterval {
+		return
+	}
+	b.draw()
+}
+
+// Finish draws the final state of every bar.
+func (b *PullBars) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tty {
+		b.draw()
+	}
+}
+
+// draw rewrites the bars in place. The caller holds b.mu.
+func (b *PullBars) draw() {
+	var sb strings.Builder
+	if b.drawn {
+		fmt.Fprintf(&sb, "\x1b[%dA", len(b.images))
+	}
+	width := 0
+	for _, img := range b.images {
+		width = max(width, len(img))
+	}
+	for _, img := range b.images {
+		p := b.state[img]
+		p.Image = img
+		sb.WriteString("\x1b[2K  ")
+		if p.Done {
+			sb.WriteString(pullLine(p))
+		} else {
+			fmt.Fprintf(&sb, "⏳ %-*s %s", width, img, bar(p))
+		}
+		sb.WriteByte('\n')
+	}
+	_, _ = io.WriteString(b.w, sb.String())
+	b.drawn = true
+	b.lastDraw = time.Now()
+}
+
+// pullLine describes a finished pull.
+func pullLine(p pool.PullProgress) string {
+	if p.Err != nil {
+		return fmt.Sprintf("❌ %s: %v", p.Image, p.Err)
+	}
+	if p.Total == 0 {
+		return fmt.Sprintf("✅ %s (up to date)", p.Image)
+	}
+	return fmt.Sprintf("✅ %s (%s)", p.Image, formatBytes(p.Total))
+}
+
+// bar draws the progress bar of a running pull.
+func bar(p pool.PullProgress) string {
+	if p.Total == 0 {
+		return p.Status
+	}
+	filled := int(min(p.Current, p.Total) * barWidth / p.Total)
+	return fmt.Sprintf("[%s%s] %3d%% %s/%s",
+		strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled),
+		min(p.Current, p.Total)*100/p.Total, formatBytes(p.Current), formatBytes(p.Total))
+}
+
+// formatBytes formats a byte count for display.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
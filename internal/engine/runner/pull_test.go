@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/pool"
+)
+
+func TestPullBars_Lines(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewPullBars(&buf, []string{"golang:1.25", "node:22"}, false)
+
+	b.Update(pool.PullProgress{Image: "golang:1.25", Current: 10, Total: 100, Status: "Downloading"})
+	b.Update(pool.PullProgress{Image: "golang:1.25", Current: 3 << 20, Total: 3 << 20, Done: true})
+	b.Update(pool.PullProgress{Image: "node:22", Done: true, Err: errors.New("manifest unknown")})
+	b.Finish()
+
+	want := "⬇️  Pulling 2 image(s)...\n  ✅ golang:1.25 (3.0MB)\n  ❌ node:22: manifest unknown\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPullBars_Terminal(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewPullBars(&buf, []string{"golang:1.25", "node:22"}, true)
+
+	b.Update(pool.PullProgress{Image: "golang:1.25", Current: 50, Total: 100, Status: "Downloading"})
+	b.Update(pool.PullProgress{Image: "node:22", Done: true})
+	b.Finish()
+
+	out := buf.String()
+	if !strings.Contains(out, "[============            ]  50% 50B/100B") {
+		t.Errorf("expected a half-full bar, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[2A") {
+		t.Errorf("expected the bars to be redrawn in place, got %q", out)
+	}
+	if !strings.Contains(out, "✅ node:22 (up to date)") {
+		t.Errorf("expected the finished pull, got %q", out)
+	}
+}
@@ -3,18 +3,46 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
 	"github.com/irahardianto/gatekeeper/internal/engine/gate"
 	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+	"github.com/irahardianto/gatekeeper/internal/platform/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+// ResultMiddleware post-processes the result of each gate as soon as it completes,
+// before fail-fast and the run verdict see it (e.g., deduplication or redaction).
+// Placeholder results of gates that returned an error or were cancelled before
+// they started go through it too. It may modify r in place; it must return a
+// non-nil result. Middleware runs concurrently for different gates.
+type ResultMiddleware func(ctx context.Context, r *formatter.GateResult) *formatter.GateResult
+
+// RunObserver is notified of run progress (e.g., to send webhooks). Callbacks for
+// different gates may be called concurrently.
+type RunObserver interface {
+	// OnGateStart is called before a gate executes.
+	OnGateStart(ctx context.Context, name string)
+	// OnGateComplete is called with a gate's result after middleware.
+	OnGateComplete(ctx context.Context, result formatter.GateResult, duration time.Duration)
+	// OnRunComplete is called with the result of the run.
+	OnRunComplete(ctx context.Context, result formatter.RunResult)
+}
+
 // Engine orchestrates parallel gate execution.
 type Engine struct {
 	// Progress is an optional progress tracker. If nil, no progress output is produced.
 	Progress *Progress
+
+	// Sequential runs gates one at a time in configuration order, for debugging.
+	Sequential bool
+
+	middleware []ResultMiddleware
+	observers  []RunObserver
 }
 
 // NewEngine creates a new execution engine.
@@ -27,21 +55,99 @@ func NewEngineWithProgress(p *Progress) *Engine {
 	return &Engine{Progress: p}
 }
 
-// RunAll executes all gates in parallel and collects results.
-// If failFast is true, remaining gates are cancelled when a blocking gate fails.
+// Use appends middleware applied to every gate result, in the order added.
+func (e *Engine) Use(mw ...ResultMiddleware) {
+	e.middleware = append(e.middleware, mw...)
+}
+
+// Observe registers observers notified of every run.
+func (e *Engine) Observe(obs ...RunObserver) {
+	e.observers = append(e.observers, obs...)
+}
+
+// execute runs one gate in its own span and applies the result middleware.
+func (e *Engine) execute(ctx context.Context, g gate.Gate, name string) (*formatter.GateResult, error) {
+	gateCtx, span := telemetry.Start(ctx, "gate.execute", attribute.String("gate.name", name))
+	result, err := g.Execute(gateCtx)
+	if result != nil {
+		result = e.apply(gateCtx, result)
+		span.SetAttributes(
+			attribute.Bool("gate.passed", result.Passed),
+			attribute.Bool("gate.blocking", result.Blocking),
+			attribute.Int("gate.findings", len(result.Errors)),
+		)
+		if result.SystemError != "" && err == nil {
+			span.SetStatus(codes.Error, result.SystemError)
+		}
+	}
+	telemetry.End(span, err)
+	return result, err
+}
+
+// apply runs the result middleware on r, in the order added.
+func (e *Engine) apply(ctx context.Context, r *formatter.GateResult) *formatter.GateResult {
+	for _, mw := range e.middleware {
+		r = mw(ctx, r)
+	}
+	return r
+}
+
+// cancelled turns r into the placeholder result of a cancelled gate.
+func cancelled(r *formatter.GateResult, reason string) *formatter.GateResult {
+	r.Skipped = true
+	r.Cancelled = true
+	r.SkipReason = reason
+	r.Passed = false
+	r.SystemError = ""
+	r.Errors = nil
+	return r
+}
+
+// gateName returns the display name of the gate at idx, or "" if names are missing.
+func gateName(names []string, idx int) string {
+	if idx < len(names) {
+		return names[idx]
+	}
+	return ""
+}
+
+// RunAll executes all gates in parallel (or one at a time if Sequential) and
+// collects results in the order of gates.
+// If failFast is true, remaining gates are cancelled when a blocking gate fails;
+// they get placeholder results marked Cancelled.
 // gateNames provides human-readable names for progress tracking (must match gates length).
 func (e *Engine) RunAll(ctx context.Context, gates []gate.Gate, failFast bool, gateNames []string) (*formatter.RunResult, error) {
 	log := logger.FromContext(ctx)
-	log.Info("Engine.RunAll started", "gates", len(gates), "fail_fast", failFast)
+	log.Info("Engine.RunAll started", "gates", len(gates), "fail_fast", failFast, "sequential", e.Sequential)
 	start := time.Now()
 
 	if len(gates) == 0 {
-		return &formatter.RunResult{Passed: true, DurationMs: 0}, nil
+		runResult := &formatter.RunResult{Passed: true, DurationMs: 0}
+		for _, o := range e.observers {
+			o.OnRunComplete(ctx, *runResult)
+		}
+		return runResult, nil
 	}
 
 	// Create a cancellable context for fail-fast support.
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	// failedGate is the gate that triggered fail-fast, if any.
+	var (
+		failMu     sync.Mutex
+		failedGate string
+	)
+	stoppedBy := func() string {
+		failMu.Lock()
+		defer failMu.Unlock()
+		return failedGate
+	}
+	cancelledReason := func() string {
+		if name := stoppedBy(); name != "" {
+			return fmt.Sprintf("cancelled after %s failed (fail-fast)", name)
+		}
+		return "cancelled"
+	}
 
 	// Fan-out: run each gate in its own goroutine.
 	type indexedResult struct {
@@ -51,46 +157,81 @@ func (e *Engine) RunAll(ctx context.Context, gates []gate.Gate, failFast bool, g
 	}
 
 	resultsCh := make(chan indexedResult, len(gates))
-	var wg sync.WaitGroup
-
-	for i, g := range gates {
-		wg.Add(1)
-		go func(idx int, g gate.Gate) {
-			defer wg.Done()
-
-			// Check if context is already cancelled before starting.
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
+	run := func(idx int, g gate.Gate) {
+		// Check if context is already cancelled before starting.
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
 
-			if e.Progress != nil && idx < len(gateNames) {
+		if idx < len(gateNames) {
+			if e.Progress != nil {
 				e.Progress.OnStart(gateNames[idx])
 			}
+			for _, o := range e.observers {
+				o.OnGateStart(ctx, gateNames[idx])
+			}
+		}
 
-			gateStart := time.Now()
-			result, err := g.Execute(ctx)
-			gateDur := time.Since(gateStart)
-			resultsCh <- indexedResult{idx: idx, result: result, err: err}
+		gateStart := time.Now()
+		result, err := e.execute(ctx, g, gateName(gateNames, idx))
+		gateDur := time.Since(gateStart)
+		if (err != nil || (result != nil && result.SystemError != "")) && ctx.Err() != nil && stoppedBy() != "" {
+			// Stopped by fail-fast mid-run: not an error of this gate.
+			if result == nil {
+				result = &formatter.GateResult{Name: gateName(gateNames, idx)}
+			}
+			result, err = cancelled(result, cancelledReason()), nil
+		}
+		resultsCh <- indexedResult{idx: idx, result: result, err: err}
 
-			if e.Progress != nil && result != nil {
-				e.Progress.OnComplete(result.Name, result.Passed, result.SystemError != "", result.SystemError, gateDur)
+		if result != nil {
+			if e.Progress != nil {
+				if result.Cancelled {
+					e.Progress.OnCancel(result.Name)
+				} else {
+					e.Progress.OnComplete(result.Name, result.Passed, result.SystemError != "", result.SystemError, gateDur)
+				}
+			}
+			for _, o := range e.observers {
+				o.OnGateComplete(ctx, *result, gateDur)
 			}
+		}
 
-			// Fail-fast: cancel remaining gates if a blocking gate failed.
-			if failFast && result != nil && !result.Passed && result.Blocking {
+		// Fail-fast: cancel remaining gates if a blocking gate failed.
+		if failFast && result != nil && result.Blocks() {
+			failMu.Lock()
+			if failedGate == "" {
 				log.Info("fail-fast: cancelling remaining gates", "failed_gate", result.Name)
+				failedGate = result.Name
 				cancel()
 			}
-		}(i, g)
+			failMu.Unlock()
+		}
 	}
 
-	// Close channel when all goroutines complete.
-	go func() {
-		wg.Wait()
+	if e.Sequential {
+		for _, i := range sequentialOrder(gates) {
+			run(i, gates[i])
+		}
 		close(resultsCh)
-	}()
+	} else {
+		var wg sync.WaitGroup
+		for i, g := range gates {
+			wg.Add(1)
+			go func(idx int, g gate.Gate) {
+				defer wg.Done()
+				run(idx, g)
+			}(i, g)
+		}
+
+		// Close channel when all goroutines complete.
+		go func() {
+			wg.Wait()
+			close(resultsCh)
+		}()
+	}
 
 	// Collect results in order.
 	collected := make([]*formatter.GateResult, len(gates))
@@ -99,8 +240,19 @@ func (e *Engine) RunAll(ctx context.Context, gates []gate.Gate, failFast bool, g
 			collected[ir.idx] = ir.result
 		} else if ir.err != nil {
 			// System error — create a placeholder result.
-			collected[ir.idx] = &formatter.GateResult{
+			collected[ir.idx] = e.apply(ctx, &formatter.GateResult{
+				Name:        gateName(gateNames, ir.idx),
 				SystemError: ir.err.Error(),
+			})
+		}
+	}
+
+	// Gates fail-fast cancelled before they started keep their place.
+	for i, r := range collected {
+		if r == nil {
+			collected[i] = e.apply(ctx, cancelled(&formatter.GateResult{Name: gateName(gateNames, i)}, cancelledReason()))
+			if e.Progress != nil {
+				e.Progress.OnCancel(collected[i].Name)
 			}
 		}
 	}
@@ -112,23 +264,40 @@ func (e *Engine) RunAll(ctx context.Context, gates []gate.Gate, failFast bool, g
 	}
 
 	for _, r := range collected {
-		if r == nil {
-			// Gate was cancelled before completion (fail-fast).
-			continue
-		}
 		runResult.Gates = append(runResult.Gates, *r)
 
 		// A run fails if any blocking gate failed or had a system error with block policy.
-		if r.Blocking && (!r.Passed || r.SystemError != "") {
+		if r.Blocks() {
 			runResult.Passed = false
 		}
 	}
 
+	runResult.Usage = formatter.SumUsage(runResult.Gates)
+
 	// Print progress summary.
 	if e.Progress != nil {
 		e.Progress.Finish()
 	}
+	for _, o := range e.observers {
+		o.OnRunComplete(ctx, *runResult)
+	}
 
 	log.Info("Engine.RunAll completed", "passed", runResult.Passed, "duration_ms", runResult.DurationMs, "gates_run", len(runResult.Gates))
 	return runResult, nil
 }
+
+// sequentialOrder returns the indexes of gates in the order a sequential run
+// starts them: in list order, except that gates which wait for the others,
+// such as LLM gates deferred by run time, come last. Results keep list order.
+func sequentialOrder(gates []gate.Gate) []int {
+	order := make([]int, 0, len(gates))
+	var last []int
+	for i, g := range gates {
+		if lg, ok := g.(gate.LastGate); ok && lg.RunsLast() {
+			last = append(last, i)
+			continue
+		}
+		order = append(order, i)
+	}
+	return append(order, last...)
+}
@@ -0,0 +1,243 @@
+// Package server runs gates for Git servers and CI over HTTP. A pre-receive
+// hook posts the pushed tree (or the server clones a ref), the server runs its
+// configured gates on it in pooled containers, and the RunResult is returned.
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/platform/logger"
+)
+
+// TokenEnv is the environment variable holding the bearer token clients must send.
+const TokenEnv = "GATEKEEPER_SERVER_TOKEN"
+
+// DefaultMaxUpload bounds the size of an uploaded archive.
+const DefaultMaxUpload = 256 << 20
+
+// maxExpansion bounds the extracted size of an upload as a multiple of
+// MaxUpload, since a small compressed archive can expand without limit.
+const maxExpansion = 10
+
+// maxJSONRequest bounds the size of a JSON check request.
+const maxJSONRequest = 64 << 10
+
+// Check is a prepared workspace to run the gates of.
+type Check struct {
+	// Dir is the workspace: a git repository whose index holds the changes.
+	Dir string
+	// Changed lists the changed files of an archive upload. If nil, the
+	// changes are read from the git index of Dir.
+	Changed []string
+	// Archive is set for uploads, which carry no history: LLM gates, which
+	// review the diff, cannot run.
+	Archive bool
+}
+
+// RunFunc runs the gates of a workspace. The human-readable report is written
+// to out. Failing gates are reported in the result, not as an error.
+type RunFunc func(ctx context.Context, check Check, out io.Writer) (*formatter.RunResult, error)
+
+// Options configures a Server.
+type Options struct {
+	// Token is the bearer token clients must send. If empty, requests are not
+	// authenticated; the server command refuses that without --insecure.
+	Token string
+	// AllowedRepos are prefixes of the repositories the server checks: the
+	// URLs it may clone and the names of uploads. Requests are refused if it
+	// is empty.
+	AllowedRepos []string
+	// MaxUpload bounds the size of an uploaded archive (default DefaultMaxUpload).
+	MaxUpload int64
+	// Metrics serves the Prometheus metrics at GET /metrics, behind the
+	// token. If nil, /metrics is not served.
+	Metrics http.Handler
+}
+
+// Server serves check requests. Each repository has a workspace directory
+// that is reused across requests, so warm containers stay mounted; checks of
+// the same repository run one at a time.
+type Server struct {
+	root string
+	run  RunFunc
+	opts Options
+	git  func(ctx context.Context, dir string, args ...string) error
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// New creates a Server that keeps workspaces under root.
+func New(root string, run RunFunc, opts Options) *Server {
+	if opts.MaxUpload <= 0 {
+		opts.MaxUpload = DefaultMaxUpload
+	}
+	return &Server{root: root, run: run, opts: opts, git: runGit, locks: make(map[string]*sync.Mutex)}
+}
+
+// CheckRequest asks the server to clone Repo and check the changes from Base
+// to Ref. If Base is empty (or all zeros, as for a new branch), every file of
+// Ref is checked.
+type CheckRequest struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+	Base string `json:"base,omitempty"`
+}
+
+// Handler returns the HTTP handler of the server:
+//
+//	POST /v1/check  run the gates (JSON clone request or multipart archive upload)
+//	GET  /healthz   liveness probe
+//	GET  /metrics   Prometheus metrics, if Options.Metrics is set
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/check", s.handleCheck)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	if s.opts.Metrics != nil {
+		mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+			if !s.authorized(r) {
+				httpError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+				return
+			}
+			s.opts.Metrics.ServeHTTP(w, r)
+		})
+	}
+	return mux
+}
+
+// handleCheck prepares the workspace, runs the gates, and writes the result:
+// 200 if the gates passed, 422 if they failed. Clients that accept text/plain
+// get the CLI report instead of the JSON RunResult.
+func (s *Server) handleCheck(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.FromContext(ctx)
+
+	if !s.authorized(r) {
+		httpError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	var (
+		check  Check
+		unlock func()
+		err    error
+	)
+	switch mediaType {
+	case "application/json":
+		check, unlock, err = s.prepareClone(ctx, r)
+	case "multipart/form-data":
+		check, unlock, err = s.prepareUpload(ctx, w, r)
+	default:
+		err = badRequest("unsupported content type %q (use application/json or multipart/form-data)", mediaType)
+	}
+	if err != nil {
+		status := http.StatusInternalServerError
+		var bad *requestError
+		if errors.As(err, &bad) {
+			status = bad.status
+		}
+		log.Warn("check request rejected", "status", status, "error", err)
+		httpError(w, status, err)
+		return
+	}
+	defer unlock()
+
+	start := time.Now()
+	var report bytes.Buffer
+	result, err := s.run(ctx, check, &report)
+	if err != nil {
+		log.Error("check failed", "error", err)
+		httpError(w, http.StatusInternalServerError, err)
+		return
+	}
+	log.Info("check completed", "passed", result.Passed, "gates", len(result.Gates), "duration", time.Since(start))
+
+	status := http.StatusOK
+	if !result.Passed {
+		status = http.StatusUnprocessableEntity
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/plain") {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(status)
+		_, _ = w.Write(report.Bytes())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// authorized reports whether the request carries the configured token.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	// [SEC] Constant-time comparison, so the token cannot be guessed byte by byte.
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+// workspace locks and returns the workspace directory of a repository. The
+// returned function releases the lock.
+func (s *Server) workspace(repo string) (string, func(), error) {
+	sum := sha256.Sum256([]byte(repo))
+	key := hex.EncodeToString(sum[:8])
+
+	s.mu.Lock()
+	lock, ok := s.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[key] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	dir := filepath.Join(s.root, key)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		lock.Unlock()
+		return "", nil, fmt.Errorf("creating workspace: %w", err)
+	}
+	return dir, lock.Unlock, nil
+}
+
+// requestError is an error caused by the request, reported with its status.
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func tooLarge(format string, args ...any) error {
+	return &requestError{status: http.StatusRequestEntityTooLarge, msg: fmt.Sprintf(format, args...)}
+}
+
+// httpError writes err as a JSON error response.
+func httpError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
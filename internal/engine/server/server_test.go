@@ -0,0 +1,360 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+)
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@test.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitFile writes and commits a file, returning the commit id.
+func commitFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitOutput(t, dir, "add", name)
+	gitOutput(t, dir, "commit", "--quiet", "-m", name)
+	return gitOutput(t, dir, "rev-parse", "HEAD")
+}
+
+func TestCheck_Clone(t *testing.T) {
+	src := t.TempDir()
+	gitOutput(t, src, "init", "--quiet")
+	base := commitFile(t, src, "a.go", "package a\n")
+	ref := commitFile(t, src, "b.go", "package b\n")
+
+	var staged []string
+	run := func(ctx context.Context, check Check, out io.Writer) (*formatter.RunResult, error) {
+		staged = strings.Fields(gitOutput(t, check.Dir, "diff", "--cached", "--name-only"))
+		if check.Changed != nil || check.Archive {
+			t.Errorf("unexpected check: %+v", check)
+		}
+		return &formatter.RunResult{Passed: false, Gates: []formatter.GateResult{{Name: "lint"}}}, nil
+	}
+	srv := New(t.TempDir(), run, Options{Token: "s3cret", AllowedRepos: []string{src}})
+
+	post := func(body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/check", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(`{"repo":"`+src+`","ref":"`+ref+`"}`, "wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+
+	rec := post(`{"repo":"`+src+`","ref":"`+ref+`","base":"`+base+`"}`, "s3cret")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body.String())
+	}
+	var result formatter.RunResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil || len(result.Gates) != 1 {
+		t.Errorf("unexpected body %v: %v", result, err)
+	}
+	if strings.Join(staged, ",") != "b.go" {
+		t.Errorf("staged = %v, want the changes since base", staged)
+	}
+
+	// A new branch (zero base) checks every file.
+	rec = post(`{"repo":"`+src+`","ref":"`+ref+`","base":"0000000000000000000000000000000000000000"}`, "s3cret")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Join(staged, ",") != "a.go,b.go" {
+		t.Errorf("staged = %v, want all files", staged)
+	}
+}
+
+func TestCheck_CloneRejected(t *testing.T) {
+	srv := New(t.TempDir(), nil, Options{AllowedRepos: []string{"https://git.example.com/", "https://git.corp/team"}})
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not allowed", `{"repo":"https://evil.example.com/x.git","ref":"main"}`},
+		{"past the prefix", `{"repo":"https://git.corp/team-evil/x.git","ref":"main"}`},
+		{"option ref", `{"repo":"https://git.example.com/x.git","ref":"--upload-pack=evil"}`},
+		{"no ref", `{"repo":"https://git.example.com/x.git"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/check", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	tests := []struct {
+		repo, prefix string
+		want         bool
+	}{
+		{"https://git.corp/team/x.git", "https://git.corp/team", true},
+		{"https://git.corp/team", "https://git.corp/team", true},
+		{"https://git.corp/team/x.git", "https://git.corp/", true},
+		{"https://git.corp/team-evil/x.git", "https://git.corp/team", false},
+		{"https://git.corp/te", "https://git.corp/team", false},
+	}
+	for _, tt := range tests {
+		if got := hasPathPrefix(tt.repo, tt.prefix); got != tt.want {
+			t.Errorf("hasPathPrefix(%q, %q) = %v, want %v", tt.repo, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestHandler_Metrics(t *testing.T) {
+	get := func(srv *Server, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get(New(t.TempDir(), nil, Options{}), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without a metrics handler", rec.Code)
+	}
+
+	metrics := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "gatekeeper_pool_containers 2\n")
+	})
+	srv := New(t.TempDir(), nil, Options{Token: "s3cret", Metrics: metrics})
+	if rec := get(srv, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	rec := get(srv, "s3cret")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "gatekeeper_pool_containers 2") {
+		t.Errorf("unexpected response %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// tarball builds a tar archive from header/content pairs.
+func tarball(t *testing.T, entries []*tar.Header, contents []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for i, hdr := range entries {
+		hdr.Size = int64(len(contents[i]))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheck_Upload(t *testing.T) {
+	archive := tarball(t, []*tar.Header{
+		{Name: "cmd/main.go", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "run.sh", Typeflag: tar.TypeReg, Mode: 0o755},
+		{Name: "link.go", Typeflag: tar.TypeSymlink, Linkname: "cmd/main.go"},
+	}, []string{"package main\n", "#!/bin/sh\n", ""})
+
+	var got Check
+	run := func(_ context.Context, check Check, out io.Writer) (*formatter.RunResult, error) {
+		got = check
+		data, err := os.ReadFile(filepath.Join(check.Dir, "link.go"))
+		if err != nil || string(data) != "package main\n" {
+			t.Errorf("link.go = %q, %v", data, err)
+		}
+		if info, err := os.Stat(filepath.Join(check.Dir, "run.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+			t.Errorf("run.sh lost its executable bit: %v", err)
+		}
+		if untracked := gitOutput(t, check.Dir, "ls-files", "--others"); untracked != "" {
+			t.Errorf("expected every file staged, untracked: %s", untracked)
+		}
+		_, _ = io.WriteString(out, "gatekeeper: 1 passed\n")
+		return &formatter.RunResult{Passed: true}, nil
+	}
+	srv := New(t.TempDir(), run, Options{AllowedRepos: []string{"org/"}})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("repo", "org/app")
+	_ = mw.WriteField("changed", "cmd/main.go\n\nrun.sh\n")
+	fw, _ := mw.CreateFormFile("archive", "tree.tar")
+	_, _ = fw.Write(archive)
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/check", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != "gatekeeper: 1 passed\n" {
+		t.Errorf("body = %q, want the text report", rec.Body.String())
+	}
+	if !got.Archive || strings.Join(got.Changed, ",") != "cmd/main.go,run.sh" {
+		t.Errorf("unexpected check: %+v", got)
+	}
+}
+
+func TestCheck_UploadRejected(t *testing.T) {
+	srv := New(t.TempDir(), nil, Options{AllowedRepos: []string{"org/"}})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("repo", "other/app")
+	fw, _ := mw.CreateFormFile("archive", "tree.tar")
+	_, _ = fw.Write(tarball(t, []*tar.Header{{Name: "main.go", Typeflag: tar.TypeReg}}, []string{"package main\n"}))
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/check", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "not allowed") {
+		t.Errorf("status = %d, want 400 for a repository that is not allowed: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCheck_UploadExpandsTooFar(t *testing.T) {
+	srv := New(t.TempDir(), nil, Options{AllowedRepos: []string{"org/"}, MaxUpload: 64 << 10})
+
+	// A megabyte of zeros compresses to a few kilobytes.
+	archive := tarball(t, []*tar.Header{{Name: "zeros", Typeflag: tar.TypeReg}}, []string{strings.Repeat("\x00", 1<<20)})
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("repo", "org/app")
+	fw, _ := mw.CreateFormFile("archive", "tree.tar.gz")
+	gz := gzip.NewWriter(fw)
+	_, _ = gz.Write(archive)
+	_ = gz.Close()
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/check", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413 for an archive that expands past the limit: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestExtractTar_RejectsEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		hdr  *tar.Header
+	}{
+		{"parent path", &tar.Header{Name: "../evil", Typeflag: tar.TypeReg}},
+		{"absolute path", &tar.Header{Name: "/etc/evil", Typeflag: tar.TypeReg}},
+		{"escaping symlink", &tar.Header{Name: "a/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc/passwd"}},
+		{"absolute symlink", &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := tarball(t, []*tar.Header{tt.hdr}, []string{""})
+			if err := extractTar(t.TempDir(), bytes.NewReader(archive), DefaultMaxUpload); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestExtractTar_RejectsGitMetadata(t *testing.T) {
+	for _, name := range []string{".git/config", ".GIT/config", "./.git/hooks/pre-commit", ".git"} {
+		t.Run(name, func(t *testing.T) {
+			archive := tarball(t, []*tar.Header{{Name: name, Typeflag: tar.TypeReg}}, []string{"[core]\n\tfsmonitor = touch pwned\n"})
+			if err := extractTar(t.TempDir(), bytes.NewReader(archive), DefaultMaxUpload); err == nil || !strings.Contains(err.Error(), ".git") {
+				t.Errorf("expected the entry to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunGit_IgnoresFsmonitor(t *testing.T) {
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "--quiet")
+	marker := filepath.Join(t.TempDir(), "pwned")
+	gitOutput(t, dir, "config", "core.fsmonitor", "touch "+marker+"; false")
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runGit(context.Background(), dir, "add", "--all"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the fsmonitor command of the workspace ran")
+	}
+}
+
+func TestExtractTar_RejectsSymlinkChains(t *testing.T) {
+	tests := []struct {
+		name  string
+		links [][2]string
+	}{
+		// e is created through d, at the root, and points above it.
+		{"link through a link", [][2]string{{"d", "."}, {"d/e", ".."}}},
+		// s points at the root, so s/.. is above it.
+		{"parent of a link", [][2]string{{"a/s", ".."}, {"a/t", "s/.."}}},
+		{"dangling", [][2]string{{"a/s", ".."}, {"a/t", "s/../missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hdrs []*tar.Header
+			var contents []string
+			for _, l := range tt.links {
+				hdrs = append(hdrs, &tar.Header{Name: l[0], Typeflag: tar.TypeSymlink, Linkname: l[1]})
+				contents = append(contents, "")
+			}
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "ws")
+			if err := os.Mkdir(dir, 0o750); err != nil {
+				t.Fatal(err)
+			}
+			if err := extractTar(dir, bytes.NewReader(tarball(t, hdrs, contents)), DefaultMaxUpload); err == nil {
+				t.Error("expected an error")
+			}
+			if entries, _ := os.ReadDir(parent); len(entries) != 1 {
+				t.Errorf("expected nothing created outside the workspace, got %d entries", len(entries))
+			}
+		})
+	}
+}
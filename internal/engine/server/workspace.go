@@ -0,0 +1,426 @@
+package server
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/git"
+)
+
+// validRef matches commit ids and ref names; it rules out options and revision expressions.
+var validRef = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// zeroID is the null object id git hooks pass for a branch that did not exist.
+var zeroID = regexp.MustCompile(`^0+$`)
+
+// Local refs the requested revisions are fetched into.
+const (
+	refHead = "refs/gatekeeper/ref"
+	refBase = "refs/gatekeeper/base"
+	// unbornBranch is checked out without a commit, so every file of the index is a change.
+	unbornBranch = "refs/heads/gatekeeper-unborn"
+)
+
+// prepareClone fetches the requested revisions into the repository's workspace
+// and stages the changes from base to ref: HEAD is base, the index and working
+// tree are ref.
+func (s *Server) prepareClone(ctx context.Context, r *http.Request) (Check, func(), error) {
+	var req CheckRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxJSONRequest)).Decode(&req); err != nil {
+		return Check{}, nil, badRequest("decoding request: %v", err)
+	}
+	if err := s.checkRepo(req.Repo); err != nil {
+		return Check{}, nil, err
+	}
+	if zeroID.MatchString(req.Base) {
+		req.Base = ""
+	}
+	for _, ref := range []string{req.Ref, req.Base} {
+		if ref != "" && !validRef.MatchString(ref) {
+			return Check{}, nil, badRequest("invalid ref %q", ref)
+		}
+	}
+	if req.Ref == "" {
+		return Check{}, nil, badRequest("ref is required")
+	}
+
+	dir, unlock, err := s.workspace(req.Repo)
+	if err != nil {
+		return Check{}, nil, err
+	}
+	if err := s.checkout(ctx, dir, req); err != nil {
+		unlock()
+		return Check{}, nil, err
+	}
+	return Check{Dir: dir}, unlock, nil
+}
+
+// checkRepo checks that the server may check repo, a URL to clone or the name
+// of an upload.
+func (s *Server) checkRepo(repo string) error {
+	if repo == "" {
+		return badRequest("repo is required")
+	}
+	// [SEC] Only clone allowlisted repositories: a client must not make the
+	// server fetch arbitrary URLs or read local paths.
+	for _, prefix := range s.opts.AllowedRepos {
+		if hasPathPrefix(repo, prefix) {
+			return nil
+		}
+	}
+	return badRequest("repository %q is not allowed (see --allow-repo)", repo)
+}
+
+// hasPathPrefix reports whether repo is prefix or lies below it, so that the
+// prefix https://git.example.com/team does not allow .../team-evil.
+func hasPathPrefix(repo, prefix string) bool {
+	rest, ok := strings.CutPrefix(repo, prefix)
+	return ok && (rest == "" || strings.HasSuffix(prefix, "/") || strings.HasPrefix(rest, "/"))
+}
+
+// checkout fetches req into the workspace repository in dir.
+func (s *Server) checkout(ctx context.Context, dir string, req CheckRequest) error {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := s.git(ctx, dir, "init", "--quiet"); err != nil {
+			return err
+		}
+		if err := s.git(ctx, dir, "remote", "add", "origin", req.Repo); err != nil {
+			return err
+		}
+	}
+
+	refspecs := []string{"+" + req.Ref + ":" + refHead}
+	if req.Base != "" {
+		refspecs = append(refspecs, "+"+req.Base+":"+refBase)
+	}
+	if err := s.git(ctx, dir, append([]string{"fetch", "--quiet", "--no-tags", "origin"}, refspecs...)...); err != nil {
+		return err
+	}
+	if err := s.git(ctx, dir, "checkout", "--quiet", "--force", "--detach", refHead); err != nil {
+		return err
+	}
+	if err := s.git(ctx, dir, "clean", "--quiet", "-ffdx"); err != nil {
+		return err
+	}
+	if req.Base != "" {
+		return s.git(ctx, dir, "reset", "--quiet", "--soft", refBase)
+	}
+	return s.git(ctx, dir, "symbolic-ref", "HEAD", unbornBranch)
+}
+
+// prepareUpload extracts an uploaded archive into the repository's workspace
+// and stages all of it. The multipart form has the fields "repo" (a name for
+// the workspace, which must match AllowedRepos), "changed" (newline-separated changed paths), and "archive"
+// (a tar or tar.gz of the tree, e.g. from git archive), in that order.
+func (s *Server) prepareUpload(ctx context.Context, w http.ResponseWriter, r *http.Request) (Check, func(), error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.opts.MaxUpload)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return Check{}, nil, badRequest("reading form: %v", err)
+	}
+
+	var (
+		repo    string
+		changed []string
+		dir     string
+		unlock  = func() {}
+	)
+	fail := func(err error) (Check, func(), error) {
+		unlock()
+		return Check{}, nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fail(badRequest("reading form: %v", err))
+		}
+		switch part.FormName() {
+		case "repo":
+			if repo, err = readField(part); err == nil {
+				err = s.checkRepo(repo)
+			}
+		case "changed":
+			var list string
+			list, err = readField(part)
+			changed = splitLines(list)
+		case "archive":
+			if repo == "" {
+				return fail(badRequest(`the "repo" field must come before "archive"`))
+			}
+			if dir != "" {
+				return fail(badRequest("more than one archive"))
+			}
+			if dir, unlock, err = s.workspace("upload:" + repo); err != nil {
+				return fail(err)
+			}
+			err = s.extract(ctx, dir, part)
+		}
+		if err != nil {
+			return fail(err)
+		}
+	}
+	if dir == "" {
+		return fail(badRequest(`missing "archive" field`))
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	return Check{Dir: dir, Changed: changed, Archive: true}, unlock, nil
+}
+
+// readField reads a small form field.
+func readField(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxJSONRequest+1))
+	if err != nil {
+		return "", badRequest("reading form: %v", err)
+	}
+	if len(data) > maxJSONRequest {
+		return "", badRequest("form field too large")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// splitLines returns the non-blank lines of s.
+func splitLines(s string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, filepath.ToSlash(line))
+		}
+	}
+	return lines
+}
+
+// extract replaces the contents of dir with the archive and stages all files
+// in a fresh git repository, so gates see them as the changes to check.
+func (s *Server) extract(ctx context.Context, dir string, archive io.Reader) error {
+	if err := clearDir(dir); err != nil {
+		return fmt.Errorf("clearing workspace: %w", err)
+	}
+	if err := extractTar(dir, archive, s.opts.MaxUpload*maxExpansion); err != nil {
+		// Leave nothing of a rejected archive, such as a link it created.
+		_ = clearDir(dir)
+		return err
+	}
+	if err := s.git(ctx, dir, "init", "--quiet"); err != nil {
+		return err
+	}
+	return s.git(ctx, dir, "add", "--all")
+}
+
+// extractTar extracts a tar or tar.gz stream into dir. Only regular files,
+// directories, and symlinks that stay inside dir are extracted. Symlinks are
+// created last, so no file is written through one, and never inside another
+// symlink. Once all exist, each must resolve to a path inside dir: links can
+// chain, so their text alone does not tell where they point. At most limit
+// bytes of tar are read, after decompression.
+func extractTar(dir string, archive io.Reader, limit int64) error {
+	br := bufio.NewReader(archive)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return badRequest("reading archive: %v", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+	// [SEC] Bound the extracted size: the request limit only bounds the
+	// compressed bytes.
+	r = &sizeLimiter{r: r, limit: limit, n: limit}
+
+	type link struct{ path, target string }
+	var links []link
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var bad *requestError
+		if errors.As(err, &bad) {
+			return err
+		}
+		if err != nil {
+			return badRequest("reading archive: %v", err)
+		}
+		// [SEC] Reject entries that would escape the workspace.
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if !filepath.IsLocal(name) {
+			return badRequest("archive entry %q is outside the workspace", hdr.Name)
+		}
+		// [SEC] Reject git metadata: a .git/config survives the git init that
+		// follows, and settings such as core.fsmonitor run commands on the host.
+		if first, _, _ := strings.Cut(name, string(filepath.Separator)); strings.EqualFold(first, ".git") {
+			return badRequest("archive entry %q is inside .git", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0o750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, hdr.FileInfo().Mode()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			target := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(target) || !filepath.IsLocal(filepath.Join(filepath.Dir(name), target)) {
+				return badRequest("archive symlink %q points outside the workspace", hdr.Name)
+			}
+			links = append(links, link{path: path, target: target})
+		}
+	}
+	for _, l := range links {
+		// [SEC] Creating a link inside a linked directory would create it
+		// wherever that directory points.
+		if err := checkNoLinkParent(dir, l.path); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+			return err
+		}
+		if err := os.Symlink(l.target, l.path); err != nil {
+			return fmt.Errorf("extracting archive: %w", err)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		// [SEC] Resolve every link as the file system does: a chain such as
+		// d -> . and d/e -> .. escapes although each text looks local.
+		real, err := filepath.EvalSymlinks(l.path)
+		if err != nil {
+			return badRequest("archive symlink %q does not resolve inside the workspace", relName(dir, l.path))
+		}
+		if rel, err := filepath.Rel(root, real); err != nil || !filepath.IsLocal(rel) && rel != "." {
+			return badRequest("archive symlink %q points outside the workspace", relName(dir, l.path))
+		}
+	}
+	return nil
+}
+
+// checkNoLinkParent checks that no existing directory between dir and path is
+// a symlink.
+func checkNoLinkParent(dir, path string) error {
+	rel, err := filepath.Rel(dir, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return err
+	}
+	parent := dir
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		parent = filepath.Join(parent, part)
+		info, err := os.Lstat(parent)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return badRequest("archive symlink %q is inside another symlink", relName(dir, path))
+		}
+	}
+	return nil
+}
+
+// relName returns path relative to dir, slash-separated, for messages.
+func relName(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writeFile writes an archive entry, keeping only its executable bit.
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	perm := os.FileMode(0o644)
+	if mode&0o111 != 0 {
+		perm = 0o755
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm) // #nosec G304 -- path was checked to be inside the workspace
+	if err != nil {
+		return fmt.Errorf("extracting archive: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil { // #nosec G110 -- extractTar bounds the extracted size
+		_ = f.Close()
+		return fmt.Errorf("extracting archive: %w", err)
+	}
+	return f.Close()
+}
+
+// sizeLimiter reads at most limit bytes from r, then fails with a 413
+// request error.
+type sizeLimiter struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, tooLarge("extracted archive exceeds %d bytes", l.limit)
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
+
+// clearDir removes the contents of dir but keeps dir itself, which warm
+// containers have bind-mounted.
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit runs a git command in dir, without hooks or an fsmonitor command.
+func runGit(ctx context.Context, dir string, args ...string) error {
+	// [SEC] The workspace holds a client's tree: never run commands it configures.
+	full := append(git.UntrustedArgs(), args...)
+	cmd := exec.CommandContext(ctx, "git", full...) // #nosec G204 -- refs are validated and the repository is allowlisted
+	cmd.Dir = dir
+	// Never prompt for credentials on the server.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %w (%s)", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
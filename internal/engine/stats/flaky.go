@@ -0,0 +1,129 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+)
+
+// FlakyGate is a gate that both passed and failed on the same input: the same
+// staged changes and gate configuration.
+type FlakyGate struct {
+	Name string `json:"name"`
+	// Inputs is the number of inputs the gate both passed and failed on.
+	Inputs int `json:"inputs"`
+	// Passed and Failed count the runs of the gate on those inputs.
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// LastAt is when the gate last contradicted an earlier outcome on the same input.
+	LastAt time.Time `json:"last_at"`
+	// RunsSince is the number of runs recorded after that run.
+	RunsSince int `json:"runs_since"`
+}
+
+// flakyKey identifies the runs of a gate on one input.
+type flakyKey struct {
+	gate, input string
+}
+
+// flakyInput counts the outcomes of a gate on one input.
+type flakyInput struct {
+	passed, failed int
+}
+
+// Flaky returns the gates that passed and failed on identical input in runs,
+// which are newest first as History returns them; the most recently flaky
+// gates come first. Gates without an input hash, and skipped and deferred
+// gates, are not considered.
+func Flaky(runs []results.Run) []FlakyGate {
+	inputs := map[flakyKey]*flakyInput{}
+	last := map[string]int{}
+	lastAt := map[string]time.Time{}
+
+	for i := len(runs) - 1; i >= 0; i-- {
+		for _, g := range runs[i].Result.Gates {
+			if g.InputHash == "" || g.Skipped || g.Deferred {
+				continue
+			}
+			key := flakyKey{g.Name, g.InputHash}
+			in := inputs[key]
+			if in == nil {
+				in = &flakyInput{}
+				inputs[key] = in
+			}
+			failed := gateFailed(g)
+			if (failed && in.passed > 0) || (!failed && in.failed > 0) {
+				last[g.Name] = i
+				lastAt[g.Name] = runs[i].At
+			}
+			if failed {
+				in.failed++
+			} else {
+				in.passed++
+			}
+		}
+	}
+
+	flaky := map[string]*FlakyGate{}
+	for key, in := range inputs {
+		if in.passed == 0 || in.failed == 0 {
+			continue
+		}
+		f := flaky[key.gate]
+		if f == nil {
+			f = &FlakyGate{Name: key.gate, LastAt: lastAt[key.gate], RunsSince: last[key.gate]}
+			flaky[key.gate] = f
+		}
+		f.Inputs++
+		f.Passed += in.passed
+		f.Failed += in.failed
+	}
+
+	gates := []FlakyGate{}
+	for _, f := range flaky {
+		gates = append(gates, *f)
+	}
+	slices.SortFunc(gates, func(a, b FlakyGate) int {
+		return cmp.Or(cmp.Compare(a.RunsSince, b.RunsSince), cmp.Compare(a.Name, b.Name))
+	})
+	return gates
+}
+
+// gateFailed reports whether a gate failed its checks or could not run.
+func gateFailed(g formatter.GateResult) bool {
+	return !g.Passed || g.SystemError != ""
+}
+
+// WriteFlakyTable prints the flaky gates as a table.
+func WriteFlakyTable(w io.Writer, gates []FlakyGate) {
+	if len(gates) == 0 {
+		fmt.Fprintln(w, "No flaky gates: no gate both passed and failed on the same changes in the recorded runs")
+		return
+	}
+	fmt.Fprintf(w, "\n🎲 Gates that passed and failed on the same changes:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "   gate\tinputs\tpassed\tfailed\tlast flaky\n")
+	for _, g := range gates {
+		fmt.Fprintf(tw, "   %s\t%d\t%d\t%d\t%s (%s)\n", g.Name, g.Inputs, g.Passed, g.Failed, g.LastAt.Local().Format("2006-01-02 15:04"), runsAgo(g.RunsSince))
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// runsAgo renders a number of runs since an event.
+func runsAgo(n int) string {
+	switch n {
+	case 0:
+		return "latest run"
+	case 1:
+		return "1 run ago"
+	default:
+		return fmt.Sprintf("%d runs ago", n)
+	}
+}
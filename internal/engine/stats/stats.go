@@ -0,0 +1,183 @@
+// Package stats aggregates the recorded run history of a project into usage
+// statistics: run durations, slow gates, common failing rules, LLM token
+// spend, and skip frequency. Nothing leaves the machine.
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+)
+
+// maxRules is the number of failing rules a report lists.
+const maxRules = 10
+
+// Report is the aggregate of a set of recorded runs.
+type Report struct {
+	Runs   int       `json:"runs"`
+	Passed int       `json:"passed"`
+	Failed int       `json:"failed"`
+	From   time.Time `json:"from,omitzero"`
+	To     time.Time `json:"to,omitzero"`
+	// AvgDurationMs and MaxDurationMs are the wall-clock durations of the runs.
+	AvgDurationMs int64 `json:"avg_duration_ms"`
+	MaxDurationMs int64 `json:"max_duration_ms"`
+	// RunsWithSkips is the number of runs that skipped at least one gate.
+	RunsWithSkips int `json:"runs_with_skips"`
+	// Gates are the gates that appear in the runs, slowest first.
+	Gates []GateStats `json:"gates"`
+	// Rules are the most common rules of failing gates' findings.
+	Rules []RuleStats `json:"rules"`
+	LLM   LLMStats    `json:"llm"`
+}
+
+// GateStats aggregates the results of one gate.
+type GateStats struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Runs counts the runs the gate executed in; skipped runs are counted in Skipped.
+	Runs          int   `json:"runs"`
+	Failed        int   `json:"failed"`
+	Skipped       int   `json:"skipped"`
+	AvgDurationMs int64 `json:"avg_duration_ms"`
+	MaxDurationMs int64 `json:"max_duration_ms"`
+	totalMs       int64
+}
+
+// RuleStats counts the findings of a rule in failing gates.
+type RuleStats struct {
+	Rule  string `json:"rule"`
+	Gate  string `json:"gate"`
+	Count int    `json:"count"`
+}
+
+// LLMStats totals the token spend of LLM gates.
+type LLMStats struct {
+	PromptTokens   int     `json:"prompt_tokens"`
+	ResponseTokens int     `json:"response_tokens"`
+	CostUSD        float64 `json:"cost_usd"`
+}
+
+// Compute aggregates runs. Dry runs' estimated token usage is not counted as spend.
+func Compute(runs []results.Run) Report {
+	r := Report{Runs: len(runs), Gates: []GateStats{}, Rules: []RuleStats{}}
+	gates := map[string]*GateStats{}
+	rules := map[[2]string]int{}
+	var totalMs int64
+
+	for _, run := range runs {
+		res := run.Result
+		if res.Passed {
+			r.Passed++
+		} else {
+			r.Failed++
+		}
+		if r.From.IsZero() || run.At.Before(r.From) {
+			r.From = run.At
+		}
+		if run.At.After(r.To) {
+			r.To = run.At
+		}
+		totalMs += res.DurationMs
+		r.MaxDurationMs = max(r.MaxDurationMs, res.DurationMs)
+
+		skipped := false
+		for _, g := range res.Gates {
+			gs := gates[g.Name]
+			if gs == nil {
+				gs = &GateStats{Name: g.Name, Type: g.Type}
+				gates[g.Name] = gs
+			}
+			if g.Skipped || g.Deferred {
+				gs.Skipped++
+				skipped = true
+				continue
+			}
+			gs.Runs++
+			gs.totalMs += g.DurationMs
+			gs.MaxDurationMs = max(gs.MaxDurationMs, g.DurationMs)
+			if !g.Passed {
+				gs.Failed++
+				for _, e := range g.Errors {
+					rule := e.Rule
+					if rule == "" {
+						rule = e.Tool
+					}
+					rules[[2]string{rule, g.Name}]++
+				}
+			}
+			if u := g.Usage; u != nil && !u.Estimated {
+				r.LLM.PromptTokens += u.PromptTokens
+				r.LLM.ResponseTokens += u.ResponseTokens
+				r.LLM.CostUSD += u.CostUSD
+			}
+		}
+		if skipped {
+			r.RunsWithSkips++
+		}
+	}
+
+	if r.Runs > 0 {
+		r.AvgDurationMs = totalMs / int64(r.Runs)
+	}
+	for _, gs := range gates {
+		if gs.Runs > 0 {
+			gs.AvgDurationMs = gs.totalMs / int64(gs.Runs)
+		}
+		r.Gates = append(r.Gates, *gs)
+	}
+	slices.SortFunc(r.Gates, func(a, b GateStats) int {
+		return cmp.Or(cmp.Compare(b.AvgDurationMs, a.AvgDurationMs), cmp.Compare(a.Name, b.Name))
+	})
+	for key, n := range rules {
+		r.Rules = append(r.Rules, RuleStats{Rule: key[0], Gate: key[1], Count: n})
+	}
+	slices.SortFunc(r.Rules, func(a, b RuleStats) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.Gate, b.Gate))
+	})
+	if len(r.Rules) > maxRules {
+		r.Rules = r.Rules[:maxRules]
+	}
+	return r
+}
+
+// WriteTable prints the report as a summary followed by tables of gates and
+// failing rules.
+func WriteTable(w io.Writer, r Report) {
+	if r.Runs == 0 {
+		fmt.Fprintln(w, "No recorded runs (run 'gatekeeper run' first)")
+		return
+	}
+	fmt.Fprintf(w, "\n📊 %d runs from %s to %s\n", r.Runs, r.From.Local().Format("2006-01-02"), r.To.Local().Format("2006-01-02"))
+	fmt.Fprintf(w, "   Outcome:   %d passed, %d failed\n", r.Passed, r.Failed)
+	fmt.Fprintf(w, "   Duration:  avg %s, max %s\n", formatter.DurationString(r.AvgDurationMs), formatter.DurationString(r.MaxDurationMs))
+	fmt.Fprintf(w, "   Skips:     %d of %d runs skipped a gate\n", r.RunsWithSkips, r.Runs)
+	if tokens := r.LLM.PromptTokens + r.LLM.ResponseTokens; tokens > 0 {
+		fmt.Fprintf(w, "   LLM:       %d tokens (%d prompt, %d response), $%.2f\n", tokens, r.LLM.PromptTokens, r.LLM.ResponseTokens, r.LLM.CostUSD)
+	}
+
+	fmt.Fprintf(w, "\nGates, slowest first:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "   gate\ttype\truns\tavg\tmax\tfailed\tskipped\n")
+	for _, g := range r.Gates {
+		fmt.Fprintf(tw, "   %s\t%s\t%d\t%s\t%s\t%d\t%d\n", g.Name, g.Type, g.Runs, formatter.DurationString(g.AvgDurationMs), formatter.DurationString(g.MaxDurationMs), g.Failed, g.Skipped)
+	}
+	_ = tw.Flush()
+
+	if len(r.Rules) > 0 {
+		fmt.Fprintf(w, "\nMost common failing rules:\n")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "   count\trule\tgate\n")
+		for _, rule := range r.Rules {
+			fmt.Fprintf(tw, "   %d\t%s\t%s\n", rule.Count, rule.Rule, rule.Gate)
+		}
+		_ = tw.Flush()
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,163 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/engine/results"
+)
+
+func testRuns() []results.Run {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	gosecFail := formatter.GateResult{Name: "gosec", Type: "exec", Blocking: true, DurationMs: 3000, Errors: []parser.StructuredError{
+		{Rule: "gosec:G101", Tool: "gosec"}, {Rule: "gosec:G304", Tool: "gosec"}, {Rule: "gosec:G101", Tool: "gosec"},
+	}}
+	return []results.Run{
+		{At: at.Add(2 * time.Hour), Result: formatter.RunResult{Passed: false, DurationMs: 5000, Gates: []formatter.GateResult{
+			{Name: "lint", Type: "exec", Passed: true, DurationMs: 1000},
+			gosecFail,
+			{Name: "review", Type: "llm", Passed: true, DurationMs: 4000, Usage: &formatter.TokenUsage{PromptTokens: 1000, ResponseTokens: 200, CostUSD: 0.01}},
+		}}},
+		{At: at.Add(time.Hour), Result: formatter.RunResult{Passed: true, DurationMs: 1000, Gates: []formatter.GateResult{
+			{Name: "lint", Type: "exec", Passed: true, DurationMs: 500},
+			{Name: "gosec", Type: "exec", Passed: true, DurationMs: 1000},
+			{Name: "review", Type: "llm", Skipped: true, SkipReason: "--skip-llm"},
+		}}},
+		{At: at, Result: formatter.RunResult{Passed: false, DurationMs: 3000, Gates: []formatter.GateResult{
+			{Name: "lint", Type: "exec", Errors: []parser.StructuredError{{Tool: "golangci-lint"}}, DurationMs: 900},
+			{Name: "review", Type: "llm", Passed: true, Usage: &formatter.TokenUsage{PromptTokens: 500, Estimated: true}},
+		}}},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	r := Compute(testRuns())
+
+	if r.Runs != 3 || r.Passed != 1 || r.Failed != 2 {
+		t.Errorf("runs = %d (%d passed, %d failed), want 3 (1, 2)", r.Runs, r.Passed, r.Failed)
+	}
+	if r.AvgDurationMs != 3000 || r.MaxDurationMs != 5000 {
+		t.Errorf("durations = avg %d max %d, want 3000, 5000", r.AvgDurationMs, r.MaxDurationMs)
+	}
+	if r.RunsWithSkips != 1 {
+		t.Errorf("runs with skips = %d, want 1", r.RunsWithSkips)
+	}
+	if !r.From.Equal(testRuns()[2].At) || !r.To.Equal(testRuns()[0].At) {
+		t.Errorf("range = %v..%v", r.From, r.To)
+	}
+
+	var names []string
+	for _, g := range r.Gates {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, ",") != "gosec,review,lint" {
+		t.Errorf("gates not slowest first: %v", names)
+	}
+	gosec, review, lint := r.Gates[0], r.Gates[1], r.Gates[2]
+	if gosec.Runs != 2 || gosec.Failed != 1 || gosec.AvgDurationMs != 2000 || gosec.MaxDurationMs != 3000 {
+		t.Errorf("unexpected gosec stats: %+v", gosec)
+	}
+	if review.Runs != 2 || review.Skipped != 1 {
+		t.Errorf("unexpected review stats: %+v", review)
+	}
+	if lint.Runs != 3 || lint.Failed != 1 || lint.AvgDurationMs != 800 {
+		t.Errorf("unexpected lint stats: %+v", lint)
+	}
+
+	want := []RuleStats{{"gosec:G101", "gosec", 2}, {"golangci-lint", "lint", 1}, {"gosec:G304", "gosec", 1}}
+	if len(r.Rules) != len(want) {
+		t.Fatalf("rules = %+v, want %+v", r.Rules, want)
+	}
+	for i := range want {
+		if r.Rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, r.Rules[i], want[i])
+		}
+	}
+
+	// Estimated usage of dry runs is not spend.
+	if r.LLM.PromptTokens != 1000 || r.LLM.ResponseTokens != 200 || r.LLM.CostUSD != 0.01 {
+		t.Errorf("unexpected LLM stats: %+v", r.LLM)
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	r := Compute(nil)
+	if r.Runs != 0 || r.AvgDurationMs != 0 || r.Gates == nil || r.Rules == nil {
+		t.Errorf("unexpected empty report: %+v", r)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	WriteTable(out, Compute(testRuns()))
+
+	for _, want := range []string{
+		"3 runs from 2026-03-01 to 2026-03-01",
+		"1 passed, 2 failed",
+		"avg 3.0s, max 5.0s",
+		"1 of 3 runs skipped a gate",
+		"1200 tokens (1000 prompt, 200 response), $0.01",
+		"Gates, slowest first:",
+		"Most common failing rules:",
+		"gosec:G101",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	WriteTable(out, Compute(nil))
+	if !strings.Contains(out.String(), "No recorded runs") {
+		t.Errorf("unexpected empty output: %q", out.String())
+	}
+}
+
+func TestFlaky(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	run := func(hours int, gates ...formatter.GateResult) results.Run {
+		return results.Run{At: at.Add(time.Duration(hours) * time.Hour), Result: formatter.RunResult{Gates: gates}}
+	}
+	// Newest first: test flips on input a, then passes on b twice; lint fails
+	// on c and passes on d, which is a fix, not a flake.
+	runs := []results.Run{
+		run(4, formatter.GateResult{Name: "test", Passed: true, InputHash: "b"}),
+		run(3, formatter.GateResult{Name: "test", Passed: true, InputHash: "b"}, formatter.GateResult{Name: "lint", Passed: true, InputHash: "d"}),
+		run(2, formatter.GateResult{Name: "test", Passed: true, InputHash: "a"}, formatter.GateResult{Name: "lint", InputHash: "c"}),
+		run(1, formatter.GateResult{Name: "test", InputHash: "a"}, formatter.GateResult{Name: "review", Skipped: true, InputHash: "e"}),
+		run(0, formatter.GateResult{Name: "test", Passed: true, InputHash: "a"}, formatter.GateResult{Name: "review", Passed: true, InputHash: "e"}),
+	}
+
+	got := Flaky(runs)
+	if len(got) != 1 {
+		t.Fatalf("expected only test to be flaky, got %+v", got)
+	}
+	f := got[0]
+	if f.Name != "test" || f.Inputs != 1 || f.Passed != 2 || f.Failed != 1 {
+		t.Errorf("unexpected flaky gate: %+v", f)
+	}
+	if f.RunsSince != 2 || !f.LastAt.Equal(runs[2].At) {
+		t.Errorf("last flake = %v (%d runs since), want %v (2)", f.LastAt, f.RunsSince, runs[2].At)
+	}
+
+	out := &bytes.Buffer{}
+	WriteFlakyTable(out, got)
+	if !strings.Contains(out.String(), "test") || !strings.Contains(out.String(), "2 runs ago") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+}
+
+func TestFlaky_NoHashes(t *testing.T) {
+	if got := Flaky(testRuns()); len(got) != 0 {
+		t.Errorf("expected no flaky gates without input hashes, got %+v", got)
+	}
+	out := &bytes.Buffer{}
+	WriteFlakyTable(out, nil)
+	if !strings.Contains(out.String(), "No flaky gates") {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
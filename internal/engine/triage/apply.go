@@ -0,0 +1,60 @@
+package triage
+
+import (
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+// Apply moves suppressed findings of result into each gate's Suppressed list.
+// A failed gate whose only error-severity findings were suppressed is marked as
+// passed, and the overall verdict is recomputed. Gates with a system error are
+// never flipped.
+func (s *Store) Apply(result *formatter.RunResult) {
+	if len(s.Suppressions) == 0 {
+		return
+	}
+
+	for i := range result.Gates {
+		s.ApplyGate(&result.Gates[i])
+	}
+
+	result.Passed = true
+	for _, g := range result.Gates {
+		if g.Blocks() {
+			result.Passed = false
+			break
+		}
+	}
+}
+
+// ApplyGate moves the suppressed findings of a single gate result into its
+// Suppressed list, like Apply. It is safe for concurrent use on different results.
+func (s *Store) ApplyGate(g *formatter.GateResult) {
+	var kept []parser.StructuredError
+	suppressed := 0
+	for _, e := range g.Errors {
+		if _, ok := s.Lookup(Fingerprint(g.Name, e)); ok {
+			g.Suppressed = append(g.Suppressed, e)
+			suppressed++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if suppressed == 0 {
+		return
+	}
+	g.Errors = kept
+
+	if !g.Passed && g.SystemError == "" && !hasErrorSeverity(kept) {
+		g.Passed = true
+	}
+}
+
+func hasErrorSeverity(errs []parser.StructuredError) bool {
+	for _, e := range errs {
+		if e.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
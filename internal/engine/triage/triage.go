@@ -0,0 +1,156 @@
+// Package triage records findings marked as false positives or won't-fix and
+// filters them out of future runs.
+package triage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// Status values for a suppression.
+const (
+	StatusFalsePositive = "false-positive"
+	StatusWontFix       = "wont-fix"
+)
+
+// fingerprintLen is the number of hex characters kept from the finding hash.
+const fingerprintLen = 12
+
+var (
+	// ErrNotFound is returned when no suppression matches a fingerprint.
+	ErrNotFound = errors.New("suppression not found")
+
+	// ErrAmbiguous is returned when a fingerprint prefix matches several entries.
+	ErrAmbiguous = errors.New("fingerprint prefix is ambiguous")
+)
+
+// ValidStatus reports whether s is a known suppression status.
+func ValidStatus(s string) bool {
+	return s == StatusFalsePositive || s == StatusWontFix
+}
+
+// Fingerprint returns a stable identifier for a finding of a gate. The line and
+// column are excluded so the fingerprint survives unrelated edits to the file.
+func Fingerprint(gate string, e parser.StructuredError) string {
+	h := sha256.New()
+	for _, part := range []string{gate, e.Tool, e.Rule, filepath.ToSlash(e.File), strings.TrimSpace(e.Message)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:fingerprintLen]
+}
+
+// Suppression is a finding marked as not requiring a fix.
+type Suppression struct {
+	Fingerprint string    `json:"fingerprint"`
+	Gate        string    `json:"gate"`
+	File        string    `json:"file,omitempty"`
+	Rule        string    `json:"rule,omitempty"`
+	Message     string    `json:"message"`
+	Status      string    `json:"status"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Store is the set of suppressions for a project, persisted as JSON so it can be
+// committed and reviewed alongside gates.yaml.
+type Store struct {
+	Version      int           `json:"version"`
+	Suppressions []Suppression `json:"suppressions"`
+}
+
+// DefaultPath returns the suppressions file for a project.
+func DefaultPath(projectDir string) string {
+	return filepath.Join(projectDir, ".gatekeeper", "suppressions.json")
+}
+
+// Load reads the suppressions file at path. A missing file yields an empty store.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the project directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Store{Version: 1}, nil
+		}
+		return nil, fmt.Errorf("reading suppressions: %w", err)
+	}
+
+	var s Store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing suppressions: %w", err)
+	}
+	if s.Version == 0 {
+		s.Version = 1
+	}
+	return &s, nil
+}
+
+// Save writes the store to path, sorted by fingerprint for stable diffs.
+func (s *Store) Save(path string) error {
+	sort.Slice(s.Suppressions, func(i, j int) bool {
+		return s.Suppressions[i].Fingerprint < s.Suppressions[j].Fingerprint
+	})
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding suppressions: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("creating suppressions directory: %w", err)
+	}
+	if err := fileutil.WriteFileAtomic(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing suppressions: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the suppression with the exact fingerprint.
+func (s *Store) Lookup(fingerprint string) (Suppression, bool) {
+	for _, sup := range s.Suppressions {
+		if sup.Fingerprint == fingerprint {
+			return sup, true
+		}
+	}
+	return Suppression{}, false
+}
+
+// Add inserts or replaces the suppression with the same fingerprint.
+func (s *Store) Add(sup Suppression) {
+	for i := range s.Suppressions {
+		if s.Suppressions[i].Fingerprint == sup.Fingerprint {
+			s.Suppressions[i] = sup
+			return
+		}
+	}
+	s.Suppressions = append(s.Suppressions, sup)
+}
+
+// Remove deletes the suppression matching a fingerprint or unique prefix of one.
+func (s *Store) Remove(prefix string) (Suppression, error) {
+	idx := -1
+	for i, sup := range s.Suppressions {
+		if !strings.HasPrefix(sup.Fingerprint, prefix) {
+			continue
+		}
+		if idx >= 0 {
+			return Suppression{}, fmt.Errorf("%w: %q", ErrAmbiguous, prefix)
+		}
+		idx = i
+	}
+	if idx < 0 {
+		return Suppression{}, fmt.Errorf("%w: %q", ErrNotFound, prefix)
+	}
+	removed := s.Suppressions[idx]
+	s.Suppressions = append(s.Suppressions[:idx], s.Suppressions[idx+1:]...)
+	return removed, nil
+}
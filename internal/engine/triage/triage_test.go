@@ -0,0 +1,110 @@
+package triage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/irahardianto/gatekeeper/internal/engine/formatter"
+	"github.com/irahardianto/gatekeeper/internal/engine/parser"
+)
+
+var finding = parser.StructuredError{File: "main.go", Line: 42, Severity: "error", Rule: "G101", Message: "hardcoded credential", Tool: "gosec"}
+
+func TestFingerprint_IgnoresLine(t *testing.T) {
+	moved := finding
+	moved.Line = 99
+	moved.Column = 4
+
+	if Fingerprint("security", finding) != Fingerprint("security", moved) {
+		t.Error("expected fingerprint to be stable across line changes")
+	}
+	if Fingerprint("security", finding) == Fingerprint("other", finding) {
+		t.Error("expected gate name to be part of the fingerprint")
+	}
+	if len(Fingerprint("security", finding)) != fingerprintLen {
+		t.Errorf("unexpected fingerprint length")
+	}
+}
+
+func TestStore_SaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gatekeeper", "suppressions.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load missing file: %v", err)
+	}
+	s.Add(Suppression{Fingerprint: "bbb", Status: StatusWontFix, Reason: "legacy"})
+	s.Add(Suppression{Fingerprint: "aaa", Status: StatusFalsePositive, Reason: "test data"})
+	s.Add(Suppression{Fingerprint: "aaa", Status: StatusFalsePositive, Reason: "updated"})
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Suppressions) != 2 || loaded.Suppressions[0].Fingerprint != "aaa" {
+		t.Fatalf("unexpected suppressions: %+v", loaded.Suppressions)
+	}
+	if sup, ok := loaded.Lookup("aaa"); !ok || sup.Reason != "updated" {
+		t.Errorf("expected replaced suppression, got %+v", sup)
+	}
+}
+
+func TestStore_Remove(t *testing.T) {
+	s := &Store{Suppressions: []Suppression{{Fingerprint: "abc123"}, {Fingerprint: "abd456"}}}
+
+	if _, err := s.Remove("ab"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("expected ErrAmbiguous, got %v", err)
+	}
+	if _, err := s.Remove("zzz"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := s.Remove("abc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.Suppressions) != 1 {
+		t.Errorf("expected one suppression left, got %d", len(s.Suppressions))
+	}
+}
+
+func TestStore_Apply(t *testing.T) {
+	warning := parser.StructuredError{File: "a.go", Severity: "error", Message: "real problem", Tool: "gosec"}
+	result := &formatter.RunResult{
+		Passed: false,
+		Gates: []formatter.GateResult{
+			{Name: "security", Blocking: true, Errors: []parser.StructuredError{finding}},
+			{Name: "lint", Blocking: true, Errors: []parser.StructuredError{finding, warning}},
+			{Name: "crash", Blocking: false, SystemError: "boom", Errors: []parser.StructuredError{finding}},
+		},
+	}
+	s := &Store{Suppressions: []Suppression{
+		{Fingerprint: Fingerprint("security", finding)},
+		{Fingerprint: Fingerprint("lint", finding)},
+		{Fingerprint: Fingerprint("crash", finding)},
+	}}
+
+	s.Apply(result)
+
+	if !result.Gates[0].Passed || len(result.Gates[0].Errors) != 0 || len(result.Gates[0].Suppressed) != 1 {
+		t.Errorf("expected security gate to pass with its finding suppressed: %+v", result.Gates[0])
+	}
+	if result.Gates[1].Passed || len(result.Gates[1].Errors) != 1 {
+		t.Errorf("expected lint gate to keep failing on the unsuppressed finding: %+v", result.Gates[1])
+	}
+	if result.Gates[2].Passed {
+		t.Error("gates with a system error must not be flipped")
+	}
+	if result.Passed {
+		t.Error("expected run to fail while a blocking gate still fails")
+	}
+
+	result.Gates[1].Errors = nil
+	result.Gates[1].Passed = true
+	s.Apply(result)
+	if !result.Passed {
+		t.Error("expected run to pass once all blocking gates pass")
+	}
+}
@@ -0,0 +1,124 @@
+// Package assets provides the default gate templates, fix hints, LLM prompts, and
+// schemas built into the binary. Any of them can be overridden without rebuilding
+// by a file of the same name under the override directory
+// (~/.config/gatekeeper/templates/ by default).
+package assets
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed defaults
+var defaults embed.FS
+
+var (
+	mu          sync.RWMutex
+	overrideDir string
+	resolved    bool
+)
+
+// SetOverrideDir sets the directory searched before the built-in defaults.
+// An empty dir disables overrides.
+func SetOverrideDir(dir string) {
+	mu.Lock()
+	defer mu.Unlock()
+	overrideDir, resolved = dir, true
+}
+
+// OverrideDir returns the override directory: the one set with SetOverrideDir,
+// else ~/.config/gatekeeper/templates ("" if the home directory is unknown).
+func OverrideDir() string {
+	mu.RLock()
+	dir, ok := overrideDir, resolved
+	mu.RUnlock()
+	if ok {
+		return dir
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(home, ".config", "gatekeeper", "templates")
+	}
+	SetOverrideDir(dir)
+	return dir
+}
+
+// Default returns the built-in version of the named asset, a slash-separated
+// path such as "prompts/review.tmpl".
+func Default(name string) ([]byte, error) {
+	data, err := defaults.ReadFile(path.Join("defaults", name))
+	if err != nil {
+		return nil, fmt.Errorf("reading built-in asset %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// Override returns the user's version of the named asset. ok is false if there is none.
+func Override(name string) (data []byte, ok bool, err error) {
+	dir := OverrideDir()
+	if dir == "" {
+		return nil, false, nil
+	}
+	data, err = os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))) // #nosec G304 -- name is a built-in asset path under the user's config directory
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading asset override %s: %w", name, err)
+	}
+	return data, true, nil
+}
+
+// Read returns the named asset: the override if there is one, else the built-in default.
+func Read(name string) ([]byte, error) {
+	data, ok, err := Override(name)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return data, nil
+	}
+	return Default(name)
+}
+
+// List returns the names of the assets in dir with the given extension, built-in
+// and overrides, sorted and without the extension.
+func List(dir, ext string) ([]string, error) {
+	seen := make(map[string]bool)
+	entries, err := defaults.ReadDir(path.Join("defaults", dir))
+	if err != nil {
+		return nil, fmt.Errorf("listing built-in assets %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+			seen[strings.TrimSuffix(e.Name(), ext)] = true
+		}
+	}
+
+	if root := OverrideDir(); root != "" {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("listing asset overrides %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ext) {
+				seen[strings.TrimSuffix(e.Name(), ext)] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,120 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useOverrideDir points overrides at a fresh temp directory for the test.
+func useOverrideDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetOverrideDir(dir)
+	t.Cleanup(func() { SetOverrideDir("") })
+	return dir
+}
+
+func writeOverride(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRead_PrefersOverride(t *testing.T) {
+	dir := useOverrideDir(t)
+
+	data, err := Read("prompts/review.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "{{.Diff}}") {
+		t.Errorf("expected built-in review prompt, got %q", data)
+	}
+
+	writeOverride(t, dir, "prompts/review.tmpl", "custom")
+	data, err = Read("prompts/review.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "custom" {
+		t.Errorf("expected override, got %q", data)
+	}
+}
+
+func TestRead_Missing(t *testing.T) {
+	useOverrideDir(t)
+	if _, err := Read("catalog/no-such-template.yaml"); err == nil {
+		t.Error("expected error for missing asset")
+	}
+}
+
+func TestRead_UnreadableOverride(t *testing.T) {
+	dir := useOverrideDir(t)
+	// A directory in place of the file cannot be read as one.
+	if err := os.MkdirAll(filepath.Join(dir, "init", "header.yaml"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read("init/header.yaml"); err == nil || !strings.Contains(err.Error(), "asset override") {
+		t.Errorf("expected the unreadable override to be reported, got %v", err)
+	}
+}
+
+func TestList_MergesOverrides(t *testing.T) {
+	dir := useOverrideDir(t)
+	writeOverride(t, dir, "catalog/internal-audit.yaml", "# Audit\n")
+	writeOverride(t, dir, "catalog/gosec.yaml", "# Custom gosec\n")
+	writeOverride(t, dir, "catalog/notes.txt", "ignored")
+
+	names, err := List("catalog", ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := 0
+	for _, n := range names {
+		if n == "gosec" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected gosec listed once, got %d in %v", count, names)
+	}
+	if !contains(names, "internal-audit") || contains(names, "notes") {
+		t.Errorf("expected override templates merged by extension, got %v", names)
+	}
+}
+
+func TestOverrideDir_Disabled(t *testing.T) {
+	SetOverrideDir("")
+	t.Cleanup(func() { SetOverrideDir("") })
+
+	_, ok, err := Override("hints.yaml")
+	if err != nil || ok {
+		t.Errorf("expected no override, got ok=%v err=%v", ok, err)
+	}
+	names, err := List("init", ".yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docker", "fallback", "go", "header", "migrations", "node", "openapi", "protobuf", "python", "shell", "terraform"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected built-in init assets %v, got %v", want, names)
+	}
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// Package fileutil provides crash-safe file writes and lock files.
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to path so that readers see either the old or the
+// new content, never a partial write. The data is written to a temporary file in
+// the same directory, synced, and renamed over path; the directory is then
+// synced so the rename survives a crash. The parent directory must exist.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file for %s: %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("writing temporary file for %s: %w", path, err)
+	}
+	// CreateTemp uses 0600; apply the requested mode before the file becomes visible.
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("setting mode of %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("syncing temporary file for %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing temporary file for %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing %s: %w", path, err)
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes a directory entry update to disk. It is best-effort: some
+// platforms (e.g., Windows) cannot sync directories, and the rename has already
+// happened.
+func syncDir(dir string) {
+	d, err := os.Open(dir) // #nosec G304 -- directory of a path chosen by the caller
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
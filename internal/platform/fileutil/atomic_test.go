@@ -0,0 +1,77 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic_CreatesAndReplaces(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	if err := WriteFileAtomic(path, []byte(`{"v":1}`), 0o600); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte(`{"v":2}`), 0o644); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"v":2}` {
+		t.Errorf("expected replaced content, got %q", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected no leftover temporary files, got %v", entries)
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "state.json")
+	if err := WriteFileAtomic(path, []byte("x"), 0o600); err == nil {
+		t.Fatal("expected error when the parent directory does not exist")
+	}
+}
+
+func TestWriteFileAtomic_KeepsOldContentOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := WriteFileAtomic(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in place of the target makes the rename fail after the temp file is written.
+	target := filepath.Join(dir, "blocked")
+	if err := os.MkdirAll(filepath.Join(target, "child"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(target, []byte("new"), 0o600); err == nil {
+		t.Fatal("expected rename over a non-empty directory to fail")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected temporary file to be cleaned up, got %v", entries)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("expected untouched file, got %q", data)
+	}
+}
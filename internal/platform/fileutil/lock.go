@@ -0,0 +1,41 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// TryLock takes the lock file at path without waiting. It returns false if
+// another process holds the lock. A lock older than staleAfter is assumed to
+// be left by a crashed process and is taken over. The parent directory must
+// exist; call release to drop the lock.
+func TryLock(path string, staleAfter time.Duration) (release func(), ok bool, err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304 -- lock path is chosen by the caller
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, true, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, false, fmt.Errorf("creating lock file %s: %w", path, err)
+		}
+
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Released in the meantime.
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("checking lock file %s: %w", path, err)
+		}
+		if time.Since(info.ModTime()) < staleAfter {
+			return nil, false, nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, false, fmt.Errorf("removing stale lock file %s: %w", path, err)
+		}
+	}
+	return nil, false, nil
+}
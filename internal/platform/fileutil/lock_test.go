@@ -0,0 +1,44 @@
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prune.lock")
+
+	release, ok, err := TryLock(path, time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v; want the lock", ok, err)
+	}
+	if _, ok, _ := TryLock(path, time.Hour); ok {
+		t.Error("expected a held lock to be refused")
+	}
+
+	release()
+	release, ok, err = TryLock(path, time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("expected the released lock to be taken, got %v, %v", ok, err)
+	}
+	release()
+}
+
+func TestTryLock_Stale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prune.lock")
+	if err := os.WriteFile(path, []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	release, ok, err := TryLock(path, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected a stale lock to be taken over, got %v, %v", ok, err)
+	}
+	release()
+}
@@ -0,0 +1,50 @@
+// Package promtext writes metrics in the Prometheus text exposition format.
+package promtext
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ContentType is the Content-Type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4"
+
+// Writer writes an exposition and remembers the first write error, so callers
+// can write the metrics linearly and check Err once.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Header writes the HELP and TYPE lines of a metric family.
+func (w *Writer) Header(name, typ, help string) {
+	w.Printf("# HELP %s %s\n", name, help)
+	w.Printf("# TYPE %s %s\n", name, typ)
+}
+
+// Printf writes a formatted sample line.
+func (w *Writer) Printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+// Err returns the first write error, if any.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// EscapeLabel escapes a label value.
+func EscapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
@@ -0,0 +1,45 @@
+package promtext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var b strings.Builder
+	w := NewWriter(&b)
+	w.Header("jobs_total", "counter", "Jobs run.")
+	w.Printf("jobs_total{job=\"%s\"} %d\n", EscapeLabel(`a"b`), 3)
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# HELP jobs_total Jobs run.\n# TYPE jobs_total counter\njobs_total{job=\"a\\\"b\"} 3\n"
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+type failWriter struct{ calls int }
+
+func (f *failWriter) Write([]byte) (int, error) {
+	f.calls++
+	return 0, errors.New("broken pipe")
+}
+
+func TestWriter_KeepsFirstError(t *testing.T) {
+	fw := &failWriter{}
+	w := NewWriter(fw)
+	w.Printf("a 1\n")
+	w.Printf("b 2\n")
+	if w.Err() == nil || fw.calls != 1 {
+		t.Errorf("expected one failed write to be remembered, got err=%v calls=%d", w.Err(), fw.calls)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := EscapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("EscapeLabel = %q", got)
+	}
+}
@@ -0,0 +1,9 @@
+package secrets
+
+import "context"
+
+// keychainLookup reads a generic password from the macOS Keychain, as stored by
+// "security add-generic-password -s <service> -a <account> -w".
+func keychainLookup(ctx context.Context, service, account string) (string, error) {
+	return lookupTool(ctx, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+}
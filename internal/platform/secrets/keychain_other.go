@@ -0,0 +1,12 @@
+//go:build !darwin && !windows
+
+package secrets
+
+import "context"
+
+// keychainLookup reads a secret from the Secret Service (GNOME Keyring,
+// KWallet) with secret-tool, as stored by
+// "secret-tool store --label=<service> service <service> account <account>".
+func keychainLookup(ctx context.Context, service, account string) (string, error) {
+	return lookupTool(ctx, "secret-tool", "lookup", "service", service, "account", account)
+}
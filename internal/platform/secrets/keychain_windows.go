@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+var (
+	advapi32     = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric = 1
+	errorNotFound   = syscall.Errno(1168)
+)
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keychainLookup reads a generic credential named "<service>:<account>" from
+// the Windows Credential Manager, as stored by
+// "cmdkey /generic:<service>:<account> /user:<service> /pass".
+func keychainLookup(_ context.Context, service, account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(service + ":" + account)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, errorNotFound) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer func() { _, _, _ = procCredFree.Call(uintptr(unsafe.Pointer(cred))) }()
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	// cmdkey and the Credential Manager UI store the password as UTF-16.
+	if len(blob)%2 == 0 {
+		u := make([]uint16, len(blob)/2)
+		for i := range u {
+			u[i] = uint16(blob[2*i]) | uint16(blob[2*i+1])<<8
+		}
+		return string(utf16.Decode(u)), nil
+	}
+	return string(blob), nil
+}
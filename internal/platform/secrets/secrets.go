@@ -0,0 +1,134 @@
+// Package secrets reads secrets such as API keys from the OS credential store
+// or from a command, so they need not be stored in plaintext config files.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Secret sources, as set in a "*_from" setting of the user config.
+const (
+	// SourceKeychain is the OS credential store: the macOS Keychain, the
+	// Secret Service (GNOME Keyring, KWallet) on Linux, or the Windows
+	// Credential Manager.
+	SourceKeychain = "keychain"
+	// CommandPrefix starts a source that runs a command, such as
+	// "command: op read op://vault/gemini/key", and uses its output.
+	CommandPrefix = "command:"
+)
+
+// Service is the service name of gatekeeper entries in the credential store.
+// Each entry's account is the name of the setting, such as gemini_api_key.
+const Service = "gatekeeper"
+
+// commandTimeout bounds a secret command, which may wait for the user to
+// unlock a password manager.
+const commandTimeout = time.Minute
+
+// ErrNotFound is returned when the credential store has no entry for a secret.
+var ErrNotFound = errors.New("secret not found in the credential store")
+
+// Resolver reads secrets from their sources.
+type Resolver struct {
+	// Keychain reads the entry of account from the credential store.
+	Keychain func(ctx context.Context, service, account string) (string, error)
+	// Command runs a shell command and returns its standard output.
+	Command func(ctx context.Context, command string) (string, error)
+}
+
+// NewResolver returns a Resolver that uses the credential store of this
+// platform and the system shell.
+func NewResolver() *Resolver {
+	return &Resolver{Keychain: keychainLookup, Command: runCommand}
+}
+
+// Validate checks that source is a known secret source.
+func Validate(source string) error {
+	if source == SourceKeychain {
+		return nil
+	}
+	if cmd, ok := strings.CutPrefix(source, CommandPrefix); ok {
+		if strings.TrimSpace(cmd) == "" {
+			return errors.New("command: needs a command to run")
+		}
+		return nil
+	}
+	return fmt.Errorf("must be %q or %q followed by a command, got %q", SourceKeychain, CommandPrefix, source)
+}
+
+// Resolve reads the secret named name, such as gemini_api_key, from source.
+func (r *Resolver) Resolve(ctx context.Context, source, name string) (string, error) {
+	if err := Validate(source); err != nil {
+		return "", err
+	}
+	var value string
+	var err error
+	if cmd, ok := strings.CutPrefix(source, CommandPrefix); ok {
+		value, err = r.Command(ctx, strings.TrimSpace(cmd))
+	} else {
+		value, err = r.Keychain(ctx, Service, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s from %s: %w", name, sourceName(source), err)
+	}
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("reading %s from %s: the secret is empty", name, sourceName(source))
+	}
+	return value, nil
+}
+
+// sourceName describes a source in errors without the command line.
+func sourceName(source string) string {
+	if source == SourceKeychain {
+		return "the credential store"
+	}
+	return "its command"
+}
+
+// runCommand runs command with the system shell.
+func runCommand(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	name, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		name, flag = "cmd", "/C"
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, flag, command) // #nosec G204 -- the command is configured by the user
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// lookupTool runs a credential store command line tool and returns its output.
+// A failed lookup with no error output means the entry does not exist.
+func lookupTool(ctx context.Context, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not installed: %w", name, err)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 -- fixed tool, arguments are not shell-interpreted
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && (strings.TrimSpace(stderr.String()) == "" || strings.Contains(stderr.String(), "could not be found")) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,63 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	r := &Resolver{
+		Keychain: func(_ context.Context, service, account string) (string, error) {
+			return service + "/" + account, nil
+		},
+		Command: func(_ context.Context, command string) (string, error) {
+			return "out of " + command + "\n", nil
+		},
+	}
+	ctx := context.Background()
+
+	if got, err := r.Resolve(ctx, SourceKeychain, "gemini_api_key"); err != nil || got != "gatekeeper/gemini_api_key" {
+		t.Errorf("keychain: got %q, %v", got, err)
+	}
+	if got, err := r.Resolve(ctx, "command: op read op://vault/key", "gemini_api_key"); err != nil || got != "out of op read op://vault/key" {
+		t.Errorf("command: got %q, %v", got, err)
+	}
+
+	r.Keychain = func(context.Context, string, string) (string, error) { return "", ErrNotFound }
+	if _, err := r.Resolve(ctx, SourceKeychain, "gemini_api_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	r.Command = func(context.Context, string) (string, error) { return "\n", nil }
+	if _, err := r.Resolve(ctx, "command:true", "gemini_api_key"); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("expected an empty secret error, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, source := range []string{"keychain", "command: pass show gemini", "command:op read x"} {
+		if err := Validate(source); err != nil {
+			t.Errorf("Validate(%q): %v", source, err)
+		}
+	}
+	for _, source := range []string{"", "vault", "command:", "command:  "} {
+		if err := Validate(source); err == nil {
+			t.Errorf("Validate(%q): expected an error", source)
+		}
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	out, err := runCommand(context.Background(), "printf 'se''cret'")
+	if err != nil || out != "secret" {
+		t.Errorf("got %q, %v", out, err)
+	}
+	if _, err := runCommand(context.Background(), "echo denied >&2; exit 3"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected the command's error output, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package telemetry traces gatekeeper runs with OpenTelemetry. Spans are
+// exported via OTLP/HTTP when GATEKEEPER_OTEL_ENDPOINT is set; otherwise
+// tracing is a no-op and costs nothing.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EndpointEnv names the environment variable holding the OTLP/HTTP collector URL.
+const EndpointEnv = "GATEKEEPER_OTEL_ENDPOINT"
+
+// tracerName identifies gatekeeper spans.
+const tracerName = "github.com/irahardianto/gatekeeper"
+
+// tracesPath is the OTLP/HTTP path for traces, used when the endpoint has none.
+const tracesPath = "/v1/traces"
+
+// Setup installs a tracer provider exporting to endpoint, a collector URL such as
+// http://localhost:4318. The returned function flushes pending spans and must be
+// called before the process exits. With an empty endpoint, tracing stays a no-op.
+func Setup(ctx context.Context, endpoint, version string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid %s %q: expected a URL such as http://localhost:4318", EndpointEnv, endpoint)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = tracesPath
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(u.String()))
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "gatekeeper"),
+			attribute.String("service.version", version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("flushing traces: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if not nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
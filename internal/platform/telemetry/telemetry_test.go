@@ -0,0 +1,77 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSetup_DisabledWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("expected no-op shutdown, got %v", err)
+	}
+}
+
+func TestSetup_InvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"localhost:4318", "://bad"} {
+		if _, err := Setup(context.Background(), endpoint, "test"); err == nil {
+			t.Errorf("expected error for endpoint %q", endpoint)
+		}
+	}
+}
+
+func TestSetup_ExportsSpans(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/v1/traces" {
+			posts.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	shutdown, err := Setup(context.Background(), srv.URL, "test")
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	_, span := Start(context.Background(), "pipeline.execute")
+	End(span, nil)
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if posts.Load() == 0 {
+		t.Error("expected spans exported to /v1/traces")
+	}
+}
+
+func TestEnd_RecordsError(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	_, span := Start(context.Background(), "executor.run")
+	End(span, errors.New("exec failed"))
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status().Code != codes.Error || spans[0].Status().Description != "exec failed" {
+		t.Errorf("expected error status, got %+v", spans[0].Status())
+	}
+}
@@ -0,0 +1,72 @@
+package update
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/irahardianto/gatekeeper/internal/platform/fileutil"
+)
+
+// NoticeInterval is how often the latest release is checked, and how often a
+// newer one is announced after runs.
+const NoticeInterval = 7 * 24 * time.Hour
+
+// State records the update checks, so they run once a week rather than on
+// every commit.
+type State struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Latest     string    `json:"latest,omitempty"`
+	NotifiedAt time.Time `json:"notified_at"`
+}
+
+// StatePath returns where the update state is kept:
+// ~/.cache/gatekeeper/update.json, or the OS cache directory.
+func StatePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gatekeeper", "update.json"), nil
+}
+
+// LoadState reads the state at path. A missing or unreadable file is an empty
+// state, so the next run checks again.
+func LoadState(path string) State {
+	var s State
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return State{}
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return State{}
+	}
+	return s
+}
+
+// Save writes the state to path.
+func (s State) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFileAtomic(path, data, 0o600)
+}
+
+// CheckDue reports whether the latest release should be checked again.
+func (s State) CheckDue(now time.Time) bool {
+	return now.Sub(s.CheckedAt) >= NoticeInterval
+}
+
+// Notice returns the release to announce to a user of version current, or ""
+// if it is not newer or was announced within the week.
+func (s State) Notice(current string, now time.Time) string {
+	if !Newer(s.Latest, current) || now.Sub(s.NotifiedAt) < NoticeInterval {
+		return ""
+	}
+	return s.Latest
+}
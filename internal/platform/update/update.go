@@ -0,0 +1,322 @@
+// Package update finds newer gatekeeper releases on GitHub and replaces the
+// running binary with a verified download.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPIURL is the GitHub API endpoint of the latest gatekeeper release.
+const DefaultAPIURL = "https://api.github.com/repos/irahardianto/gatekeeper/releases/latest"
+
+// Release assets besides the binaries.
+const (
+	// ChecksumsAsset lists the SHA-256 of each binary, as "<hex>  <name>" lines.
+	ChecksumsAsset = "checksums.txt"
+	// SignatureAsset is the base64 ed25519 signature of ChecksumsAsset.
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// maxBinarySize bounds a download, so a broken release cannot fill the disk.
+const maxBinarySize = 200 << 20
+
+// ErrNoAsset is returned when a release has no binary for the platform.
+var ErrNoAsset = errors.New("release has no binary for this platform")
+
+// ErrNoPublicKey is returned by Download when the client has no key to verify
+// the release signature with.
+var ErrNoPublicKey = errors.New("no release signing key to verify the download with")
+
+// Release is a published gatekeeper release.
+type Release struct {
+	// Version is the release tag, e.g. "v1.4.0".
+	Version string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the URL of the named asset, or "" if the release has none.
+func (r *Release) asset(name string) string {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL
+		}
+	}
+	return ""
+}
+
+// AssetName is the name of the release binary for a platform, e.g.
+// "gatekeeper_linux_amd64" or "gatekeeper_windows_amd64.exe".
+func AssetName(goos, goarch string) string {
+	name := "gatekeeper_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Client looks up and downloads releases.
+type Client struct {
+	// APIURL is the latest-release endpoint. If empty, DefaultAPIURL is used.
+	APIURL string
+	// HTTP sends the requests. If nil, http.DefaultClient is used.
+	HTTP *http.Client
+	// PublicKey verifies the signature of a release's checksums. Download
+	// refuses to run without it.
+	PublicKey ed25519.PublicKey
+}
+
+// Latest returns the latest release.
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	url := c.APIURL
+	if url == "" {
+		url = DefaultAPIURL
+	}
+	data, err := c.get(ctx, url, 1<<20, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("checking the latest release: %w", err)
+	}
+	var r Release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing the latest release: %w", err)
+	}
+	if r.Version == "" {
+		return nil, errors.New("parsing the latest release: no tag_name")
+	}
+	return &r, nil
+}
+
+// Download fetches the release binary for a platform and verifies it against
+// the release checksums, and the checksums against their signature. A
+// checksum match alone is not trusted: the checksums come from the same place
+// as the binary.
+func (c *Client) Download(ctx context.Context, r *Release, goos, goarch string) ([]byte, error) {
+	if c.PublicKey == nil {
+		return nil, ErrNoPublicKey
+	}
+	name := AssetName(goos, goarch)
+	binaryURL := r.asset(name)
+	if binaryURL == "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoAsset, r.Version, name)
+	}
+	checksumsURL := r.asset(ChecksumsAsset)
+	if checksumsURL == "" {
+		return nil, fmt.Errorf("release %s has no %s, cannot verify the download", r.Version, ChecksumsAsset)
+	}
+
+	checksums, err := c.get(ctx, checksumsURL, 1<<20, "")
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", ChecksumsAsset, err)
+	}
+	sigURL := r.asset(SignatureAsset)
+	if sigURL == "" {
+		return nil, fmt.Errorf("release %s has no %s, cannot verify its checksums", r.Version, SignatureAsset)
+	}
+	sig, err := c.get(ctx, sigURL, 4<<10, "")
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", SignatureAsset, err)
+	}
+	if err := VerifySignature(c.PublicKey, checksums, sig); err != nil {
+		return nil, err
+	}
+	want, err := Checksum(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := c.get(ctx, binaryURL, maxBinarySize, "")
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", name, err)
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return binary, nil
+}
+
+// get fetches url, reading at most limit bytes of the body.
+func (c *Client) get(ctx context.Context, url string, limit int64, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// Checksum returns the SHA-256 listed for name in a checksums file.
+func Checksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s lists no checksum for %s", ChecksumsAsset, name)
+}
+
+// VerifySignature checks sig, a base64 ed25519 signature, of data.
+func VerifySignature(pub ed25519.PublicKey, data, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", SignatureAsset, err)
+	}
+	if !ed25519.Verify(pub, data, raw) {
+		return fmt.Errorf("%s does not match the release signing key", SignatureAsset)
+	}
+	return nil
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release public key %q", s)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Replace atomically replaces the executable at path with binary: the new
+// binary is written next to it and renamed over it, so an interrupted upgrade
+// leaves the old binary in place. On Windows, where a running executable
+// cannot be replaced, the old one is first moved aside to path + ".old".
+func Replace(path string, binary []byte, windows bool) (err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return fmt.Errorf("cannot write next to %s (try again with sudo?): %w", path, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := tmp.Write(binary); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	if windows {
+		old := path + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			_ = os.Rename(old, path)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Newer reports whether version a is newer than b. Versions are compared as
+// "v1.2.3" semantic versions; a pre-release is older than its release. A
+// version that does not parse, such as a development build, is never newer.
+func Newer(a, b string) bool {
+	va, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	vb, ok := parseVersion(b)
+	if !ok {
+		return true
+	}
+	for i := range 3 {
+		if va.parts[i] != vb.parts[i] {
+			return va.parts[i] > vb.parts[i]
+		}
+	}
+	switch {
+	case va.pre == vb.pre:
+		return false
+	case va.pre == "":
+		return true
+	case vb.pre == "":
+		return false
+	default:
+		return va.pre > vb.pre
+	}
+}
+
+type semver struct {
+	parts [3]int
+	pre   string
+}
+
+// parseVersion parses "v1.2.3", "1.2.3", or "v1.2.3-rc.1".
+func parseVersion(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, v.pre, _ = strings.Cut(s, "-")
+	fields := strings.Split(s, ".")
+	if len(fields) != 3 {
+		return v, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,186 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// releaseServer serves a release of binary for linux/amd64, with checksums
+// signed by priv, and returns its client.
+func releaseServer(t *testing.T, binary []byte, priv ed25519.PrivateKey) *Client {
+	t.Helper()
+	sum := sha256.Sum256(binary)
+	checksums := fmt.Sprintf("%s  %s\n%s  gatekeeper_darwin_arm64\n", hex.EncodeToString(sum[:]), AssetName("linux", "amd64"), strings.Repeat("0", 64))
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(checksums)))
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"tag_name":"v1.2.0","assets":[
+			{"name":"gatekeeper_linux_amd64","browser_download_url":"%[1]s/bin"},
+			{"name":"checksums.txt","browser_download_url":"%[1]s/sums"},
+			{"name":"checksums.txt.sig","browser_download_url":"%[1]s/sig"}]}`, srv.URL)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(checksums)) })
+	mux.HandleFunc("/sig", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write([]byte(sig)) })
+	return &Client{APIURL: srv.URL + "/latest", HTTP: srv.Client()}
+}
+
+func TestClient_LatestAndDownload(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	client := releaseServer(t, []byte("new binary"), priv)
+	client.PublicKey = pub
+	ctx := context.Background()
+
+	r, err := client.Latest(ctx)
+	if err != nil {
+		t.Fatalf("Latest() error: %v", err)
+	}
+	if r.Version != "v1.2.0" {
+		t.Errorf("Version = %q", r.Version)
+	}
+	binary, err := client.Download(ctx, r, "linux", "amd64")
+	if err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+	if string(binary) != "new binary" {
+		t.Errorf("binary = %q", binary)
+	}
+
+	if _, err := client.Download(ctx, r, "windows", "amd64"); !errors.Is(err, ErrNoAsset) {
+		t.Errorf("expected ErrNoAsset, got %v", err)
+	}
+}
+
+func TestClient_DownloadRejectsTampering(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	client := releaseServer(t, []byte("new binary"), priv)
+	ctx := context.Background()
+	r, err := client.Latest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Download(ctx, r, "linux", "amd64"); !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("expected a checksum-only download to be refused, got %v", err)
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	client.PublicKey = otherPub
+	if _, err := client.Download(ctx, r, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "signing key") {
+		t.Errorf("expected a signature error, got %v", err)
+	}
+
+	client.PublicKey = priv.Public().(ed25519.PublicKey)
+	r.Assets = r.Assets[:2]
+	if _, err := client.Download(ctx, r, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "has no checksums.txt.sig") {
+		t.Errorf("expected an unsigned release to be refused, got %v", err)
+	}
+
+	r, _ = client.Latest(ctx)
+	r.Assets[0].URL = r.Assets[2].URL // serve the signature as the binary
+	if _, err := client.Download(ctx, r, "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	sums := []byte("abc123  gatekeeper_linux_amd64\nDEF456 *gatekeeper_windows_amd64.exe\n")
+	if got, err := Checksum(sums, "gatekeeper_windows_amd64.exe"); err != nil || got != "def456" {
+		t.Errorf("Checksum() = %q, %v", got, err)
+	}
+	if _, err := Checksum(sums, "gatekeeper_darwin_arm64"); err == nil {
+		t.Error("expected an error for a missing entry")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gatekeeper")
+	if err := os.WriteFile(path, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(path, []byte("new"), false); err != nil {
+		t.Fatalf("Replace() error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+	if string(data) != "new" || info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("replaced binary = %q, mode %v", data, info.Mode())
+	}
+
+	if err := Replace(path, []byte("newer"), true); err != nil {
+		t.Fatalf("Replace() on windows error: %v", err)
+	}
+	if old, _ := os.ReadFile(path + ".old"); string(old) != "new" {
+		t.Errorf("old binary = %q, want it moved aside", old)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 2 {
+		t.Errorf("expected no leftover temp files, got %d entries", len(entries))
+	}
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "1.2.0-rc.1", true},
+		{"v1.2.0-rc.2", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.1", "v1.2.0", false},
+		{"v1.2.0", "(devel)", true},
+		{"dev", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gatekeeper", "update.json")
+	now := time.Now()
+	if s := LoadState(path); !s.CheckDue(now) {
+		t.Error("a missing state should be due for a check")
+	}
+
+	s := State{CheckedAt: now, Latest: "v1.2.0"}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	s = LoadState(path)
+	if s.CheckDue(now) || s.Latest != "v1.2.0" {
+		t.Errorf("unexpected state: %+v", s)
+	}
+	if got := s.Notice("v1.1.0", now); got != "v1.2.0" {
+		t.Errorf("Notice() = %q, want v1.2.0", got)
+	}
+	if got := s.Notice("v1.2.0", now); got != "" {
+		t.Errorf("Notice() for the latest version = %q", got)
+	}
+	s.NotifiedAt = now.Add(-time.Hour)
+	if got := s.Notice("v1.1.0", now); got != "" {
+		t.Errorf("Notice() within a week of the last = %q", got)
+	}
+	if got := s.Notice("v1.1.0", now.Add(NoticeInterval)); got != "v1.2.0" {
+		t.Errorf("Notice() a week later = %q", got)
+	}
+}